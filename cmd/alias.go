@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage short aliases for configuration names",
+	Long: `Add, remove and list aliases for configurations.
+
+An alias can be used instead of the configuration name in use, view, edit, rm,
+cp, mv, test and which. Aliases are stored alongside the configuration (not
+inside settings.json), follow it when it is renamed and are removed with it.
+
+An alias cannot have the same name as an existing configuration, and a new
+configuration cannot be created with the name of an alias.
+
+Examples:
+  cc-switch alias add w work-anthropic-proxy-v2
+  cc-switch use w
+  cc-switch alias list
+  cc-switch alias rm w`,
+}
+
+var aliasAddCmd = &cobra.Command{
+	Use:               "add <alias> <name>",
+	Short:             "Add an alias for a configuration",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeAliasTarget,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cm, err := aliasConfigManager()
+		if err != nil {
+			return err
+		}
+
+		name := cm.ResolveProfileName(args[1])
+		added, err := cm.AddProfileAlias(args[0], name)
+		if err != nil {
+			return err
+		}
+		if !added {
+			fmt.Printf("'%s' is already an alias for '%s'\n", args[0], name)
+			return nil
+		}
+		color.Green("✓ '%s' is now an alias for '%s'", args[0], name)
+		return nil
+	},
+}
+
+var aliasRmCmd = &cobra.Command{
+	Use:               "rm <alias...>",
+	Short:             "Remove aliases",
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeAliases,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cm, err := aliasConfigManager()
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, alias := range args {
+			name, err := cm.RemoveProfileAlias(alias)
+			if err != nil {
+				color.Red("✗ %v", err)
+				failed++
+				continue
+			}
+			color.Green("✓ Removed alias '%s' (was '%s')", alias, name)
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d alias(es) could not be removed", failed)
+		}
+		return nil
+	},
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:               "list [name]",
+	Short:             "List aliases, optionally only those of one configuration",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeProfileNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cm, err := aliasConfigManager()
+		if err != nil {
+			return err
+		}
+
+		aliases, err := cm.ProfileAliases()
+		if err != nil {
+			return err
+		}
+
+		filter := ""
+		if len(args) == 1 {
+			filter = cm.ResolveProfileName(args[0])
+			if !cm.ProfileExists(filter) {
+				return config.NotFoundf("configuration '%s' does not exist", args[0])
+			}
+		}
+
+		names := make([]string, 0, len(aliases))
+		for alias, name := range aliases {
+			if filter == "" || name == filter {
+				names = append(names, alias)
+			}
+		}
+		sort.Strings(names)
+
+		if len(names) == 0 {
+			if filter == "" {
+				fmt.Println("No aliases defined. Use 'cc-switch alias add <alias> <name>' to add one.")
+			}
+			return nil
+		}
+		for _, alias := range names {
+			fmt.Printf("%-12s → %s\n", alias, aliases[alias])
+		}
+		return nil
+	},
+}
+
+// aliasConfigManager initializes the config manager for the alias subcommands
+func aliasConfigManager() (*config.ConfigManager, error) {
+	if err := checkClaudeConfig(); err != nil {
+		return nil, err
+	}
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+	return cm, nil
+}
+
+// resolveAliasArg replaces an alias in the first argument with the configuration it
+// refers to. Later arguments are left alone: for cp and mv they name a new configuration.
+func resolveAliasArg(cm *config.ConfigManager, args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+	return append([]string{cm.ResolveProfileName(args[0])}, args[1:]...)
+}
+
+// completeProfileNames completes the first argument with configuration names and
+// aliases, or with template names when -t/--template is set
+func completeProfileNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cm, err := config.NewConfigManagerNoInit()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	if flag := cmd.Flags().Lookup("template"); flag != nil && flag.Value.String() == "true" {
+		templates, _ := cm.ListTemplates()
+		return filterCompletions(templates, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+
+	profiles, err := cm.ListProfiles()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var candidates []string
+	for _, profile := range profiles {
+		candidates = append(candidates, profile.Name)
+		candidates = append(candidates, profile.Aliases...)
+	}
+	return filterCompletions(candidates, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTemplateNames completes template names for flags such as new -t and edit --from
+func completeTemplateNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cm, err := config.NewConfigManagerNoInit()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	templates, _ := cm.ListTemplates()
+	return filterCompletions(templates, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeAliasTarget completes the configuration argument of 'alias add'
+func completeAliasTarget(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 1 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeProfileNames(cmd, nil, toComplete)
+}
+
+// completeAliases completes existing alias names
+func completeAliases(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cm, err := config.NewConfigManagerNoInit()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	aliases, err := cm.ProfileAliases()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	candidates := make([]string, 0, len(aliases))
+	for alias := range aliases {
+		candidates = append(candidates, alias)
+	}
+	sort.Strings(candidates)
+	return filterCompletions(candidates, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// filterCompletions keeps the candidates that start with the text being completed
+func filterCompletions(candidates []string, toComplete string) []string {
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, toComplete) {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
+
+func init() {
+	aliasCmd.AddCommand(aliasAddCmd, aliasRmCmd, aliasListCmd)
+
+	for _, cmd := range []*cobra.Command{useCmd, viewCmd, editCmd, rmCmd, cpCmd, mvCmd, testCmd, whichCmd} {
+		cmd.ValidArgsFunction = completeProfileNames
+	}
+}
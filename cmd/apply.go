@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/ui"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply <state.json>",
+	Short: "Create, update and prune configurations to match a state file",
+	Long: `Reconcile configurations with a desired-state file, for keeping a team's
+configurations in version control. The file lists configurations with an optional
+source template, env values and tags:
+
+  {
+    "profiles": [
+      {"name": "work", "template": "company", "env": {"ANTHROPIC_BASE_URL": "https://llm.example.com"}, "tags": ["team"]},
+      {"name": "personal", "env": {"ANTHROPIC_MODEL": "claude-sonnet-4-5"}}
+    ]
+  }
+
+Missing configurations are created from their template (the default template when
+none is given). For an existing configuration with a template, the whole content
+must match the template plus env; without one, only the listed env values are
+checked. Tags are replaced when given and left alone when omitted. With --prune,
+configurations not in the file are removed (read-only and snapshot configurations
+are never removed).
+
+The plan is always printed first; --dry-run stops there. Each applied change is
+recorded in the activity log ('cc-switch log').
+
+Not supported: the state file must be JSON (convert YAML with e.g. 'yq -o json'),
+and it has no lock field since cc-switch configurations cannot be locked; read-only
+system profiles are the only protected configurations.`,
+	Example: `  cc-switch apply team.json --dry-run
+  cc-switch apply team.json -y
+  cc-switch apply team.json --prune`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		prune, _ := cmd.Flags().GetBool("prune")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		if err := checkFlagRules(cmd, applyFlagRules); err != nil {
+			return err
+		}
+
+		state, err := config.LoadDesiredState(args[0])
+		if err != nil {
+			return err
+		}
+
+		cm, err := config.NewConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		changes, err := cm.PlanApply(state, prune)
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			color.Green("✓ Configurations already match %s", args[0])
+			return nil
+		}
+
+		counts := printApplyPlan(changes)
+		summary := fmt.Sprintf("%d to create, %d to update, %d to delete",
+			counts[config.ApplyCreate], counts[config.ApplyUpdate], counts[config.ApplyDelete])
+		fmt.Println()
+
+		if dryRun {
+			fmt.Printf("Plan: %s (dry run)\n", summary)
+			return nil
+		}
+		if !yes && !ui.NewCLIUI().ConfirmAction(fmt.Sprintf("Apply %d change(s): %s?", len(changes), summary), false) {
+			fmt.Println(ui.Text("common.cancelled"))
+			return nil
+		}
+
+		failed := 0
+		for _, change := range changes {
+			if err := cm.Apply(change); err != nil {
+				failed++
+				color.Red("  ✗ %s %s: %v", change.Action, change.Profile, err)
+				continue
+			}
+			color.Green("  ✓ %s %s", change.Action, change.Profile)
+		}
+		if failed > 0 {
+			return errSilentFailure
+		}
+		return nil
+	},
+}
+
+// printApplyPlan prints one line per change and returns the number of changes per action.
+// Only field paths are shown, never values, since env values are often credentials.
+func printApplyPlan(changes []config.ApplyChange) map[string]int {
+	counts := make(map[string]int)
+	for _, change := range changes {
+		counts[change.Action]++
+		switch change.Action {
+		case config.ApplyCreate:
+			fmt.Printf("%s %s (template: %s)\n", color.GreenString("+"), change.Profile, change.Template)
+		case config.ApplyUpdate:
+			fmt.Printf("%s %s: %s\n", color.YellowString("~"), change.Profile, strings.Join(change.Fields, ", "))
+		case config.ApplyDelete:
+			fmt.Printf("%s %s\n", color.RedString("-"), change.Profile)
+		}
+	}
+	return counts
+}
+
+// applyFlagRules declares the flag combinations apply rejects
+var applyFlagRules = [][]flagRule{
+	conflictsWith("dry-run", "yes"),
+}
+
+func init() {
+	applyCmd.Flags().Bool("prune", false, "Remove configurations that are not in the state file")
+	applyCmd.Flags().Bool("dry-run", false, "Only print the plan")
+	applyCmd.Flags().BoolP("yes", "y", false, "Apply without asking for confirmation")
+}
@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/export"
+	importpkg "github.com/HoBeedzc/cc-switch/internal/import"
+	"github.com/HoBeedzc/cc-switch/internal/ui"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	backupEncrypt    bool
+	backupOutput     string
+	backupKeep       int
+	backupKeepDays   int
+	restoreOverwrite bool
+	restoreYes       bool
+
+	restoreKeepCurrent bool
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up all configurations and templates",
+	Long: `Export every configuration and template to a timestamped .ccx file in the
+backup directory (~/cc-switch-backups by default, or "backup": {"dir": "..."}
+in ~/.claude/profiles/.config.json).
+
+This is a shortcut for 'cc-switch export --all' that also includes templates.
+With --encrypt you are asked for a password, and secrets referenced with
+@secret: are included as well. Restore a backup with 'cc-switch restore'.
+
+After a successful backup, --keep N and --keep-days D remove older backups in
+the same directory beyond the N newest or older than D days. Only files named
+cc-switch-backup-*.ccx are removed, and the newest backup is always kept.`,
+	Example: `  cc-switch backup
+  cc-switch backup --encrypt
+  cc-switch backup -o ~/Dropbox/cc-switch
+  cc-switch backup --keep 10 --keep-days 30`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if backupKeep < 0 || backupKeepDays < 0 {
+			return fmt.Errorf("--keep and --keep-days must not be negative")
+		}
+
+		if err := checkClaudeConfig(); err != nil {
+			return err
+		}
+
+		cm, err := config.NewConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		outputPath, err := cm.NewBackupPath()
+		if err != nil {
+			return err
+		}
+		if backupOutput != "" {
+			outputPath = filepath.Join(backupOutput, filepath.Base(outputPath))
+		}
+
+		password := ""
+		if backupEncrypt {
+			password, err = promptForPassword("Enter password for encryption: ")
+			if err != nil {
+				return fmt.Errorf("failed to read password: %w", err)
+			}
+			if password == "" {
+				return fmt.Errorf("--encrypt requires a password")
+			}
+		}
+
+		exporter := export.NewExporter(cm)
+		exporter.SetIncludeTemplates(true)
+		exporter.SetIncludeDefaultTemplate(true)
+		exporter.SetIncludeSecrets(password != "" && cm.SecretsStoreExists())
+
+		exported, skipped, err := exporter.ExportReadable(password, outputPath)
+		if err != nil {
+			return fmt.Errorf("backup failed: %w", err)
+		}
+		if len(exported) == 0 {
+			return fmt.Errorf("no readable configurations to back up")
+		}
+
+		templates, _ := cm.ListTemplates()
+		if err := cm.RecordBackup(config.BackupRecord{
+			Path:      outputPath,
+			CreatedAt: time.Now(),
+			Profiles:  len(exported),
+			Templates: len(templates),
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		cm.LogActivity(config.ActivityEntry{
+			Action: config.ActivityBackup,
+			Detail: fmt.Sprintf("%s: %d configuration(s), %d template(s)", filepath.Base(outputPath), len(exported), len(templates)),
+		})
+
+		color.Green("💾 Backed up %d configuration(s) and %d template(s) to:", len(exported), len(templates))
+		color.New(color.Bold).Printf("   %s\n", outputPath)
+		if len(skipped) > 0 {
+			color.Yellow("   ⚠ %d unreadable configuration(s) not included: %v", len(skipped), skipped)
+		}
+		if password == "" {
+			color.Yellow("   ⚠ The backup is not encrypted and contains your API tokens; use --encrypt to protect it")
+		}
+		fmt.Println("   Restore it with: cc-switch restore " + outputPath)
+
+		removed, err := cm.PruneBackups(filepath.Dir(outputPath), config.RetentionPolicy{Keep: backupKeep, KeepDays: backupKeepDays})
+		if len(removed) > 0 {
+			fmt.Printf("   Removed %d old backup(s)\n", len(removed))
+		}
+		if err != nil {
+			color.Yellow("   ⚠ %v", err)
+		}
+		return nil
+	},
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore [file]",
+	Short: "Restore configurations and templates from a backup",
+	Long: `Restore a backup made with 'cc-switch backup' (or any .ccx export). Without a
+file, the newest backup in the backup directory is used.
+
+The backup contents are shown and you are asked to confirm. Existing
+configurations and templates with the same name are kept unless --overwrite
+is given. Afterwards the configuration that was active when the backup was
+made is activated again, unless --keep-current is given.`,
+	Example: `  cc-switch restore
+  cc-switch restore ~/cc-switch-backups/cc-switch-backup-20250101-120000.ccx
+  cc-switch restore backup.ccx --overwrite -y`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkClaudeConfig(); err != nil {
+			return err
+		}
+
+		cm, err := config.NewConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		var inputFile string
+		if len(args) == 1 {
+			inputFile = args[0]
+		} else {
+			backups, err := cm.ListBackups()
+			if err != nil {
+				return err
+			}
+			if len(backups) == 0 {
+				dir, _ := cm.BackupDir()
+				return fmt.Errorf("no backups found in %s; specify a backup file", dir)
+			}
+			inputFile = backups[0]
+		}
+
+		importer := importpkg.NewImporter(cm)
+		metadata, err := importer.ValidateFile(inputFile)
+		if err != nil {
+			return fmt.Errorf("invalid backup file: %w", err)
+		}
+
+		fmt.Printf("Backup: %s\n", inputFile)
+		showFileInfo(metadata)
+
+		password := ""
+		if metadata.Encryption != "" && metadata.Encryption != "none" {
+			password, err = promptForDecryptionPassword()
+			if err != nil {
+				return fmt.Errorf("failed to read password: %w", err)
+			}
+		}
+
+		conflictMode := "skip"
+		prompt := "Restore this backup? Existing configurations and templates with the same name are kept."
+		if restoreOverwrite {
+			conflictMode = "overwrite"
+			prompt = "Restore this backup? Existing configurations and templates with the same name are OVERWRITTEN."
+		}
+		if !restoreYes && !ui.NewCLIUI().ConfirmAction(prompt, false) {
+			color.Yellow("Restore cancelled")
+			return nil
+		}
+
+		result, err := importer.Import(inputFile, password, importpkg.ImportOptions{ConflictMode: conflictMode, RestoreCurrent: !restoreKeepCurrent})
+		if err != nil {
+			return fmt.Errorf("restore failed: %w", err)
+		}
+
+		showImportResults(result, false)
+		if result.Summary.ErrorCount > 0 {
+			return errSilentFailure
+		}
+		return nil
+	},
+}
+
+// printLastBackup shows when the configurations were last backed up, nudging
+// users who never did
+func printLastBackup(cm *config.ConfigManager) {
+	record, err := cm.LastBackup()
+	if err != nil {
+		return
+	}
+	if record == nil {
+		color.Yellow("\nNo backup yet. Run 'cc-switch backup' to save your configurations.")
+		return
+	}
+
+	age := time.Since(record.CreatedAt)
+	line := fmt.Sprintf("\nLast backup: %s (%s ago), %s", record.CreatedAt.Local().Format("2006-01-02 15:04"), formatAge(age), record.Path)
+	if age > 30*24*time.Hour {
+		color.Yellow("%s - consider running 'cc-switch backup'", line)
+		return
+	}
+	fmt.Println(line)
+}
+
+// formatAge renders a duration in the largest whole unit (minutes, hours or days)
+func formatAge(age time.Duration) string {
+	switch {
+	case age < time.Hour:
+		return fmt.Sprintf("%d min", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%d h", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%d days", int(age.Hours()/24))
+	}
+}
+
+func init() {
+	backupCmd.Flags().BoolVar(&backupEncrypt, "encrypt", false, "Encrypt the backup with a password (also includes secrets)")
+	backupCmd.Flags().StringVarP(&backupOutput, "output-dir", "o", "", "Write the backup to this directory instead of the backup directory")
+	backupCmd.Flags().IntVar(&backupKeep, "keep", 0, "After backing up, keep only the N newest backups in the directory")
+	backupCmd.Flags().IntVar(&backupKeepDays, "keep-days", 0, "After backing up, remove backups older than D days")
+
+	restoreCmd.Flags().BoolVar(&restoreOverwrite, "overwrite", false, "Overwrite existing configurations and templates with the same name")
+	restoreCmd.Flags().BoolVarP(&restoreYes, "yes", "y", false, "Skip the confirmation prompt")
+	restoreCmd.Flags().BoolVar(&restoreKeepCurrent, "keep-current", false, "Stay on the active configuration instead of switching to the one active at backup time")
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackupKeepRemovesOldBackups(t *testing.T) {
+	setupHome(t)
+	cm := newTestManager(t)
+	if err := cm.CreateProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	for i, name := range []string{"cc-switch-backup-20200101-000000.ccx", "cc-switch-backup-20200102-000000.ccx", "team.ccx"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("old"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		modTime := time.Now().AddDate(0, 0, -10+i)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := runCommand(t, "backup", "-o", dir, "--keep", "2"); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+
+	backups, err := filepath.Glob(filepath.Join(dir, "cc-switch-backup-*.ccx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Errorf("backups = %v, want the new one and the newest old one", backups)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cc-switch-backup-20200101-000000.ccx")); !os.IsNotExist(err) {
+		t.Error("the oldest backup was kept")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "team.ccx")); err != nil {
+		t.Errorf("a file not made by backup was removed: %v", err)
+	}
+}
+
+func TestBackupRejectsNegativeRetention(t *testing.T) {
+	setupHome(t)
+	for _, flag := range []string{"--keep", "--keep-days"} {
+		if err := runCommand(t, "backup", flag, "-1"); err == nil {
+			t.Errorf("backup %s -1 succeeded, want an error", flag)
+		}
+	}
+}
@@ -3,8 +3,8 @@ package cmd
 import (
 	"fmt"
 
-	"cc-switch/internal/config"
-	"cc-switch/internal/ui"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/ui"
 )
 
 // handleCurrentConfigError 处理获取当前配置时的特殊错误
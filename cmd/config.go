@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// settingKey is a cc-switch setting stored in ~/.claude/profiles/.config.json
+type settingKey struct {
+	description string
+	// defaultValue is the effective value while the setting is unset
+	defaultValue string
+	get          func(cfg *config.GlobalConfig) string
+	// set applies value to cfg; an empty value restores the default
+	set func(cm *config.ConfigManager, cfg *config.GlobalConfig, value string) error
+}
+
+var settingKeys = map[string]settingKey{
+	"default_template": {
+		description:  "template 'cc-switch new' uses without --template",
+		defaultValue: "default",
+		get: func(cfg *config.GlobalConfig) string {
+			return cfg.DefaultTemplate
+		},
+		set: func(cm *config.ConfigManager, cfg *config.GlobalConfig, value string) error {
+			if value != "" && value != "default" && !cm.TemplateExists(value) {
+				return config.NotFoundf("template '%s' does not exist", value)
+			}
+			if value == "default" {
+				value = ""
+			}
+			cfg.DefaultTemplate = value
+			return nil
+		},
+	},
+	"backup.dir": {
+		description:  "directory 'cc-switch backup' writes to",
+		defaultValue: "~/cc-switch-backups",
+		get: func(cfg *config.GlobalConfig) string {
+			return cfg.Backup.Dir
+		},
+		set: func(cm *config.ConfigManager, cfg *config.GlobalConfig, value string) error {
+			cfg.Backup.Dir = value
+			return nil
+		},
+	},
+	"durable_writes": {
+		description:  "fsync configurations, templates and history on every write (settings.json is always synced)",
+		defaultValue: "false",
+		get: func(cfg *config.GlobalConfig) string {
+			if cfg.DurableWrites {
+				return "true"
+			}
+			return ""
+		},
+		set: func(cm *config.ConfigManager, cfg *config.GlobalConfig, value string) error {
+			if value == "" {
+				cfg.DurableWrites = false
+				return nil
+			}
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("durable_writes must be true or false, got '%s'", value)
+			}
+			cfg.DurableWrites = enabled
+			return nil
+		},
+	},
+	"verify_switch": {
+		description:  "validate settings.json after every switch and roll back if it has errors",
+		defaultValue: "false",
+		get: func(cfg *config.GlobalConfig) string {
+			if cfg.VerifySwitch {
+				return "true"
+			}
+			return ""
+		},
+		set: func(cm *config.ConfigManager, cfg *config.GlobalConfig, value string) error {
+			if value == "" {
+				cfg.VerifySwitch = false
+				return nil
+			}
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("verify_switch must be true or false, got '%s'", value)
+			}
+			cfg.VerifySwitch = enabled
+			return nil
+		},
+	},
+	"coalesce_window": {
+		description:  "switches closer together than this are coalesced in the history (0 disables)",
+		defaultValue: config.DefaultCoalesceWindow.String(),
+		get: func(cfg *config.GlobalConfig) string {
+			return cfg.CoalesceWindow
+		},
+		set: func(cm *config.ConfigManager, cfg *config.GlobalConfig, value string) error {
+			if _, err := config.ParseCoalesceWindow(value); err != nil {
+				return err
+			}
+			cfg.CoalesceWindow = value
+			return nil
+		},
+	},
+	"auth.token_keys": {
+		description:  "comma-separated env keys holding the API token",
+		defaultValue: strings.Join(config.DefaultTokenKeys, ","),
+		get: func(cfg *config.GlobalConfig) string {
+			return strings.Join(cfg.Auth.TokenKeys, ",")
+		},
+		set: func(cm *config.ConfigManager, cfg *config.GlobalConfig, value string) error {
+			var keys []string
+			for _, key := range strings.Split(value, ",") {
+				if key = strings.TrimSpace(key); key != "" {
+					keys = append(keys, key)
+				}
+			}
+			cfg.Auth.TokenKeys = keys
+			return nil
+		},
+	},
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "View and change cc-switch settings",
+	Long: `View and change cc-switch settings stored in ~/.claude/profiles/.config.json.
+
+Settings:
+  default_template   Template 'cc-switch new' uses without --template
+  backup.dir         Directory 'cc-switch backup' writes to
+  auth.token_keys    Comma-separated env keys holding the API token
+  durable_writes     Also fsync configurations and history (settings.json always is)
+  verify_switch      Validate settings.json after every switch and roll back on errors
+  coalesce_window    Record only the first and last of switches this close together (0 disables)
+
+Unset settings use their default. 'get' prints the effective value, and 'list'
+marks defaults. Settings this version does not recognize (for example written by a
+newer cc-switch) are ignored but kept when the file is saved.
+
+Examples:
+  cc-switch config set default_template team
+  cc-switch config get default_template
+  cc-switch config unset default_template
+  cc-switch config list`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a setting",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cm, key, err := loadSettingKey(args[0])
+		if err != nil {
+			return err
+		}
+		cfg, err := cm.LoadGlobalConfig()
+		if err != nil {
+			return err
+		}
+		if value := key.get(cfg); value != "" {
+			fmt.Println(value)
+		} else {
+			fmt.Println(key.defaultValue)
+		}
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigSet(args[0], strings.TrimSpace(args[1]))
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Restore a setting to its default",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigSet(args[0], "")
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all settings",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkClaudeConfig(); err != nil {
+			return err
+		}
+		cm, err := config.NewConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+		cfg, err := cm.LoadGlobalConfig()
+		if err != nil {
+			return err
+		}
+
+		names := make([]string, 0, len(settingKeys))
+		for name := range settingKeys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			key := settingKeys[name]
+			if value := key.get(cfg); value != "" {
+				fmt.Printf("%s = %s\n", name, value)
+			} else {
+				color.New(color.FgHiBlack).Printf("%s = %s (default) - %s\n", name, key.defaultValue, key.description)
+			}
+		}
+		for _, name := range cfg.UnknownKeys() {
+			color.Yellow("%s (not recognized by this version, ignored)", name)
+		}
+		return nil
+	},
+}
+
+// loadSettingKey initializes the config manager and looks up a setting by name
+func loadSettingKey(name string) (*config.ConfigManager, settingKey, error) {
+	key, ok := settingKeys[name]
+	if !ok {
+		names := make([]string, 0, len(settingKeys))
+		for known := range settingKeys {
+			names = append(names, known)
+		}
+		sort.Strings(names)
+		return nil, settingKey{}, fmt.Errorf("unknown setting '%s', valid settings: %s", name, strings.Join(names, ", "))
+	}
+
+	if err := checkClaudeConfig(); err != nil {
+		return nil, settingKey{}, err
+	}
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return nil, settingKey{}, fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+	return cm, key, nil
+}
+
+// runConfigSet changes a setting; an empty value restores its default
+func runConfigSet(name, value string) error {
+	cm, key, err := loadSettingKey(name)
+	if err != nil {
+		return err
+	}
+	cfg, err := cm.LoadGlobalConfig()
+	if err != nil {
+		return err
+	}
+	if err := key.set(cm, cfg, value); err != nil {
+		return err
+	}
+	if err := cm.SaveGlobalConfig(cfg); err != nil {
+		return err
+	}
+
+	if value := key.get(cfg); value != "" {
+		color.Green("✓ %s = %s", name, value)
+	} else {
+		color.Green("✓ %s restored to its default (%s)", name, key.defaultValue)
+	}
+	return nil
+}
+
+// makeDefaultTemplate makes 'cc-switch new' use the template when no --template is given
+func makeDefaultTemplate(configHandler handler.ConfigHandler, name string) error {
+	if err := configHandler.SetDefaultTemplate(name); err != nil {
+		return fmt.Errorf("failed to make '%s' the default template: %w", name, err)
+	}
+	color.Green("✓ Template '%s' is now the default for 'cc-switch new'", name)
+	return nil
+}
+
+func init() {
+	configCmd.AddCommand(configGetCmd, configSetCmd, configUnsetCmd, configListCmd)
+}
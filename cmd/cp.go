@@ -3,9 +3,9 @@ package cmd
 import (
 	"fmt"
 
-	"cc-switch/internal/config"
-	"cc-switch/internal/handler"
-	"cc-switch/internal/ui"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
+	"github.com/HoBeedzc/cc-switch/internal/ui"
 
 	"github.com/spf13/cobra"
 )
@@ -24,6 +24,7 @@ Template Modes:
 - Interactive: cc-switch cp -t (no arguments) or cc-switch cp -t -i
 - CLI: cc-switch cp -t <source-template> <destination-template>
 - Create from template: cc-switch cp -t <template> <config-name> --to-config
+- Copy and make it the default for new: cc-switch cp -t <source-template> <destination-template> --make-default
 
 The interactive mode allows you to browse and select configurations/templates with arrow keys.`,
 	Args: cobra.MaximumNArgs(2),
@@ -42,10 +43,11 @@ The interactive mode allows you to browse and select configurations/templates wi
 		interactiveFlag, _ := cmd.Flags().GetBool("interactive")
 		templateFlag, _ := cmd.Flags().GetBool("template")
 		toConfigFlag, _ := cmd.Flags().GetBool("to-config")
+		makeDefaultFlag, _ := cmd.Flags().GetBool("make-default")
 
 		// Validate flag combinations
-		if toConfigFlag && !templateFlag {
-			return fmt.Errorf("--to-config can only be used with --template (-t)")
+		if err := checkFlagRules(cmd, cpFlagRules); err != nil {
+			return err
 		}
 
 		// Create UI provider based on mode
@@ -58,11 +60,11 @@ The interactive mode allows you to browse and select configurations/templates wi
 
 		// Execute copy operation based on mode
 		if templateFlag {
-			return executeCopyTemplate(configHandler, uiProvider, args, toConfigFlag)
+			return executeCopyTemplate(configHandler, uiProvider, args, toConfigFlag, makeDefaultFlag)
 		}
 
 		// Execute regular copy operation
-		return executeCopy(configHandler, uiProvider, args)
+		return executeCopy(configHandler, uiProvider, resolveAliasArg(cm, args))
 	},
 }
 
@@ -131,7 +133,7 @@ func executeCopy(configHandler handler.ConfigHandler, uiProvider ui.UIProvider,
 }
 
 // executeCopyTemplate handles template copy operations
-func executeCopyTemplate(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, args []string, toConfig bool) error {
+func executeCopyTemplate(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, args []string, toConfig, makeDefault bool) error {
 	// Get all templates
 	templates, err := configHandler.ListTemplates()
 	if err != nil {
@@ -149,21 +151,11 @@ func executeCopyTemplate(configHandler handler.ConfigHandler, uiProvider ui.UIPr
 	// Determine execution mode
 	if len(args) == 0 {
 		// Interactive mode - select source template
-		fmt.Println("Available templates:")
-		for i, template := range templates {
-			if template == "default" {
-				fmt.Printf("  %d) %s (system default)\n", i+1, template)
-			} else {
-				fmt.Printf("  %d) %s\n", i+1, template)
-			}
-		}
-
-		fmt.Printf("Select template to copy (1-%d): ", len(templates))
-		var selection int
-		if _, err := fmt.Scanln(&selection); err != nil || selection < 1 || selection > len(templates) {
-			return fmt.Errorf("invalid selection")
+		selection, err := ui.SelectFromMenu("Available templates:", ui.TemplateMenuItems(templates), "Select template to copy")
+		if err != nil {
+			return fmt.Errorf("selection cancelled: %w", err)
 		}
-		sourceName = templates[selection-1]
+		sourceName = templates[selection]
 
 		// Get destination name interactively
 		if toConfig {
@@ -207,7 +199,7 @@ func executeCopyTemplate(configHandler handler.ConfigHandler, uiProvider ui.UIPr
 
 	// Validate source template exists
 	if err := configHandler.ValidateTemplateExists(sourceName); err != nil {
-		return fmt.Errorf("source template '%s' does not exist", sourceName)
+		return config.NotFoundf("source template '%s' does not exist", sourceName)
 	}
 
 	// Execute operation based on mode
@@ -225,13 +217,24 @@ func executeCopyTemplate(configHandler handler.ConfigHandler, uiProvider ui.UIPr
 			return err
 		}
 		uiProvider.ShowSuccess("Template copied from '%s' to '%s' successfully", sourceName, destName)
+		if makeDefault {
+			return makeDefaultTemplate(configHandler, destName)
+		}
 	}
 
 	return nil
 }
 
+// cpFlagRules declares the flag combinations cp rejects
+var cpFlagRules = [][]flagRule{
+	requiresFlag("to-config", "template"),
+	requiresFlag("make-default", "template"),
+	conflictsWith("make-default", "to-config"),
+}
+
 func init() {
 	cpCmd.Flags().BoolP("interactive", "i", false, "Enter interactive mode")
 	cpCmd.Flags().BoolP("template", "t", false, "Copy template instead of configuration")
 	cpCmd.Flags().Bool("to-config", false, "Create configuration from template (use with -t)")
+	cpCmd.Flags().Bool("make-default", false, "Make the copied template the default for 'cc-switch new' (use with -t)")
 }
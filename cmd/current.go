@@ -2,9 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
-	"cc-switch/internal/config"
-	"cc-switch/internal/handler"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -51,8 +52,39 @@ var currentCmd = &cobra.Command{
 			color.Yellow("No current configuration set")
 		} else {
 			color.Green("Current configuration: %s", current)
+			warnLocalOverrides(configHandler, current)
 		}
 
 		return nil
 	},
 }
+
+// maxLocalOverridesShown limits how many overridden fields a warning lists
+const maxLocalOverridesShown = 5
+
+// warnLocalOverrides warns when ~/.claude/settings.local.json overrides settings of
+// the named configuration, since Claude Code applies it on top of every switch
+func warnLocalOverrides(configHandler handler.ConfigHandler, name string) bool {
+	overrides, err := configHandler.LocalSettingsOverrides(name)
+	if err != nil {
+		color.Yellow("Warning: failed to read ~/.claude/settings.local.json: %v", err)
+		return true
+	}
+	if len(overrides) == 0 {
+		return false
+	}
+
+	paths := make([]string, 0, maxLocalOverridesShown)
+	for i, override := range overrides {
+		if i == maxLocalOverridesShown {
+			paths = append(paths, fmt.Sprintf("and %d more", len(overrides)-maxLocalOverridesShown))
+			break
+		}
+		paths = append(paths, override.Path)
+	}
+
+	color.Yellow("Warning: ~/.claude/settings.local.json overrides %d setting(s) of '%s': %s", len(overrides), name, strings.Join(paths, ", "))
+	fmt.Println("  Claude Code applies that file on top of settings.json, so these values win after switching.")
+	fmt.Println("  Move them into the configurations (or delete the file) if you want cc-switch to control them.")
+	return true
+}
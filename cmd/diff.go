@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <left> [right]",
+	Short: "Compare configurations, templates or the live settings",
+	Long: `Show the differences between two of: a stored configuration, a template, or the
+live settings.json that Claude Code is currently using.
+
+Each side is "profile:<name>", "template:<name>" or "settings"; a bare name is a
+configuration.
+
+Examples:
+  cc-switch diff work personal                  Compare two configurations
+  cc-switch diff work --against-current         Does 'work' match what is active right now?
+  cc-switch diff work template:team             What does 'work' lack compared to the team template?
+  cc-switch diff template:default template:team Compare two templates
+  cc-switch diff settings profile:work          What would switching to 'work' change?
+
+Lines starting with '-' exist only on the left, '+' only on the right, and '~' changed.
+Empty strings in a template are placeholders: they do not differ from a real value,
+and are listed with '?' when the other side does not set the field at all. Secret
+values (tokens, keys) are masked unless --show-secrets is given. In empty mode there
+are no live settings, so every key is reported as removed.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkClaudeConfig(); err != nil {
+			return err
+		}
+
+		againstCurrent, _ := cmd.Flags().GetBool("against-current")
+		showSecrets, _ := cmd.Flags().GetBool("show-secrets")
+
+		if againstCurrent && len(args) != 1 {
+			return fmt.Errorf("--against-current takes exactly one configuration name")
+		}
+		if !againstCurrent && len(args) != 2 {
+			return fmt.Errorf("specify two configurations to compare, or use --against-current")
+		}
+
+		left, err := config.ParseDiffOperand(args[0])
+		if err != nil {
+			return err
+		}
+		right := config.DiffOperand{Kind: config.OperandSettings}
+		if !againstCurrent {
+			if right, err = config.ParseDiffOperand(args[1]); err != nil {
+				return err
+			}
+		}
+
+		cm, err := config.NewConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		configHandler := handler.NewConfigHandler(cm)
+
+		diffs, err := configHandler.DiffOperands(left, right)
+		if err != nil {
+			return err
+		}
+
+		leftLabel := describeDiffOperand(configHandler, left)
+		rightLabel := describeDiffOperand(configHandler, right)
+		if len(diffs) == 0 {
+			color.Green("✓ %s matches %s", leftLabel, rightLabel)
+			return nil
+		}
+
+		fmt.Printf("Comparing %s with %s:\n", leftLabel, rightLabel)
+		for _, diff := range diffs {
+			printDiffEntry(diff, showSecrets)
+		}
+
+		summary := config.SummarizeDiff(diffs)
+		var counts []string
+		for _, kind := range config.DiffKinds {
+			if summary[kind] > 0 {
+				counts = append(counts, fmt.Sprintf("%d %s", summary[kind], kind))
+			}
+		}
+		fmt.Printf("\n%d difference(s): %s\n", len(diffs), strings.Join(counts, ", "))
+		return nil
+	},
+}
+
+// describeDiffOperand names one side of a comparison for the diff header
+func describeDiffOperand(configHandler handler.ConfigHandler, operand config.DiffOperand) string {
+	switch operand.Kind {
+	case config.OperandTemplate:
+		return fmt.Sprintf("template '%s'", operand.Name)
+	case config.OperandSettings:
+		if configHandler.IsEmptyMode() {
+			return "live settings.json (empty mode)"
+		}
+		return "live settings.json"
+	default:
+		return fmt.Sprintf("'%s'", operand.Name)
+	}
+}
+
+// printDiffEntry prints a single difference with a +/-/~/? marker
+func printDiffEntry(diff config.DiffEntry, showSecrets bool) {
+	switch diff.Kind {
+	case config.DiffRemoved:
+		color.Red("  - %s = %s", diff.Path, formatDiffValue(diff.Path, diff.Old, showSecrets))
+	case config.DiffAdded:
+		color.Green("  + %s = %s", diff.Path, formatDiffValue(diff.Path, diff.New, showSecrets))
+	case config.DiffUnset:
+		color.Cyan("  ? %s (template placeholder, not set)", diff.Path)
+	default:
+		color.Yellow("  ~ %s: %s → %s", diff.Path, formatDiffValue(diff.Path, diff.Old, showSecrets), formatDiffValue(diff.Path, diff.New, showSecrets))
+	}
+}
+
+// formatDiffValue renders a value as compact JSON, masking secret strings
+func formatDiffValue(path string, value interface{}, showSecrets bool) string {
+	if s, ok := value.(string); ok && !showSecrets && s != "" && config.IsSecretKey(lastPathSegment(path)) {
+		return `"********"`
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// lastPathSegment returns the key name at the end of a dotted field path
+func lastPathSegment(path string) string {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i] == '.' {
+			return path[i+1:]
+		}
+	}
+	return path
+}
+
+func init() {
+	diffCmd.Flags().Bool("against-current", false, "Compare the configuration (or template) with the live settings.json")
+	diffCmd.Flags().Bool("show-secrets", false, "Show token and key values instead of masking them")
+}
@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/HoBeedzc/cc-switch/internal/common"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check configurations for problems",
+	Long: `Validate all configurations and report problems such as malformed fields
+or missing credentials.
+
+Doctor also lists configurations last written by a different cc-switch version.
+Configurations written by a newer version are not rewritten automatically, since
+an older binary may not understand their layout.
+
+Every issue carries a stable ID shown in brackets. Use --explain <id> for a
+longer description of the problem and how to fix it.
+
+Doctor also lists configuration files that cc-switch has no metadata for,
+usually copies made by hand (cp work.json work-backup.json). They work with
+every command, but tags and the source template do not follow the copy.
+Use --fix to adopt them; add --inherit-tags to copy the tags of the file
+they were copied from.
+
+Symlinks in the profiles and templates directories are listed too. A symlink
+pointing outside its directory is reported as an error: cc-switch refuses to
+read or write through it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkFlagRules(cmd, doctorFlagRules); err != nil {
+			return err
+		}
+
+		if explain, _ := cmd.Flags().GetString("explain"); explain != "" {
+			return explainIssue(explain)
+		}
+
+		if err := checkClaudeConfig(); err != nil {
+			return err
+		}
+
+		cm, err := config.NewConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		configHandler := handler.NewConfigHandler(cm)
+
+		diagnoses, err := configHandler.DiagnoseProfiles()
+		if err != nil {
+			return err
+		}
+
+		if len(diagnoses) == 0 {
+			fmt.Println("No configurations found. Use 'cc-switch new <name>' to create your first configuration.")
+			return nil
+		}
+
+		errorCount := 0
+		warningCount := 0
+		var otherVersions []handler.ProfileDiagnosis
+
+		fmt.Printf("Checking %d configuration(s)...\n\n", len(diagnoses))
+		for _, diagnosis := range diagnoses {
+			if diagnosis.OtherVersion {
+				otherVersions = append(otherVersions, diagnosis)
+			}
+
+			if len(diagnosis.Issues) == 0 {
+				color.Green("  ✓ %s", diagnosis.Name)
+				continue
+			}
+
+			fmt.Printf("  %s\n", diagnosis.Name)
+			for _, issue := range diagnosis.Issues {
+				if issue.Severity == config.SeverityError {
+					errorCount++
+				} else {
+					warningCount++
+				}
+				printValidationIssue("    ", diagnosis.Name, issue)
+			}
+		}
+
+		if len(otherVersions) > 0 {
+			fmt.Printf("\nWritten by other cc-switch versions (this is %s):\n", common.Version)
+			for _, diagnosis := range otherVersions {
+				if diagnosis.NewerVersion {
+					color.Yellow("  ⚠ %s: %s (newer - not rewritten automatically)", diagnosis.Name, diagnosis.WrittenBy)
+				} else {
+					fmt.Printf("    %s: %s\n", diagnosis.Name, diagnosis.WrittenBy)
+				}
+			}
+		}
+
+		fix, _ := cmd.Flags().GetBool("fix")
+		inheritTags, _ := cmd.Flags().GetBool("inherit-tags")
+		if err := reportUntrackedProfiles(configHandler, fix, inheritTags); err != nil {
+			return err
+		}
+
+		symlinkErrors, err := reportSymlinkedFiles(cm)
+		if err != nil {
+			return err
+		}
+		errorCount += symlinkErrors
+
+		if current, _ := cm.GetCurrentProfile(); current != "" && !configHandler.IsEmptyMode() {
+			fmt.Println()
+			if warnLocalOverrides(configHandler, current) {
+				warningCount++
+			}
+		}
+
+		printLastBackup(cm)
+
+		fmt.Printf("\nSummary: %d error(s), %d warning(s)\n", errorCount, warningCount)
+		if errorCount > 0 {
+			return config.Invalidf("%d configuration error(s) found", errorCount)
+		}
+
+		return nil
+	},
+}
+
+// printValidationIssue prints one issue with its ID and a one-line fix hint,
+// filling in the profile name when it is known
+func printValidationIssue(indent, profileName string, issue config.ValidationIssue) {
+	location := issue.Path
+	if location == "" {
+		location = "(root)"
+	}
+	suffix := ""
+	if issue.ID != "" {
+		suffix = fmt.Sprintf(" [%s]", issue.ID)
+	}
+	if issue.Severity == config.SeverityError {
+		color.Red("%s✗ %s: %s%s", indent, location, issue.Message, suffix)
+	} else {
+		color.Yellow("%s⚠ %s: %s%s", indent, location, issue.Message, suffix)
+	}
+	if issue.Remediation != "" {
+		remediation := issue.Remediation
+		if profileName != "" {
+			remediation = strings.ReplaceAll(remediation, "<name>", profileName)
+		}
+		color.New(color.Faint).Printf("%s  fix: %s\n", indent, remediation)
+	}
+}
+
+// reportSymlinkedFiles lists symlinks in the profiles and templates directories and
+// returns how many point outside their directory (cc-switch refuses to use those)
+func reportSymlinkedFiles(cm *config.ConfigManager) (int, error) {
+	files, err := cm.FindSymlinkedFiles()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check for symlinks: %w", err)
+	}
+	if len(files) == 0 {
+		return 0, nil
+	}
+
+	outside := 0
+	fmt.Println("\nSymlinked files:")
+	for _, file := range files {
+		if file.Outside {
+			outside++
+			color.Red("  ✗ %s -> %s (outside its directory, refused by every command)", file.Path, file.Target)
+		} else {
+			fmt.Printf("    %s -> %s\n", file.Path, file.Target)
+		}
+	}
+	if outside > 0 {
+		fmt.Println("  Replace them with regular files (cp --remove-destination <target> <link>) or delete them.")
+	}
+	return outside, nil
+}
+
+// doctorFlagRules declares the flag combinations doctor rejects
+var doctorFlagRules = [][]flagRule{
+	requiresFlag("inherit-tags", "fix"),
+	conflictsWith("explain", "fix"),
+}
+
+// reportUntrackedProfiles lists profile files without metadata and adopts them when fix is set
+func reportUntrackedProfiles(configHandler handler.ConfigHandler, fix, inheritTags bool) error {
+	untracked, err := configHandler.FindUntrackedProfiles()
+	if err != nil {
+		return err
+	}
+	if len(untracked) == 0 {
+		return nil
+	}
+
+	fmt.Println("\nNot tracked by cc-switch (e.g. copied by hand):")
+	for _, profile := range untracked {
+		detail := ""
+		switch {
+		case profile.MetadataOf != "":
+			detail = fmt.Sprintf(" (metadata belongs to '%s')", profile.MetadataOf)
+		case profile.Origin != "":
+			detail = fmt.Sprintf(" (copy of '%s')", profile.Origin)
+		}
+
+		if !fix {
+			color.Yellow("  ⚠ %s%s", profile.Name, detail)
+			continue
+		}
+		if err := configHandler.AdoptProfile(profile.Name, profile.Origin, inheritTags); err != nil {
+			color.Red("  ✗ %s%s: %v", profile.Name, detail, err)
+			continue
+		}
+		color.Green("  ✓ %s%s adopted", profile.Name, detail)
+	}
+
+	if !fix {
+		fmt.Println("  Run 'cc-switch doctor --fix' to adopt them (add --inherit-tags to copy tags from the original).")
+	}
+	return nil
+}
+
+// explainIssue prints the long-form description of a validation issue
+func explainIssue(id string) error {
+	info, ok := config.LookupIssue(strings.ToLower(strings.TrimSpace(id)))
+	if !ok {
+		var ids []string
+		for _, known := range config.IssueCatalog() {
+			ids = append(ids, known.ID)
+		}
+		return fmt.Errorf("unknown issue ID '%s' (known IDs: %s)", id, strings.Join(ids, ", "))
+	}
+
+	color.New(color.Bold).Printf("%s [%s]\n\n", info.Title, info.ID)
+	fmt.Println(info.Explanation)
+	fmt.Println()
+	fmt.Println("How to fix:")
+	fmt.Printf("  %s\n", info.Remediation)
+	return nil
+}
+
+func init() {
+	doctorCmd.Flags().String("explain", "", "Describe an issue ID and how to fix it, without running the checks")
+	doctorCmd.Flags().Bool("fix", false, "Adopt configuration files that were copied by hand")
+	doctorCmd.Flags().Bool("inherit-tags", false, "With --fix, copy tags from the configuration a file was copied from")
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+)
+
+func TestExplainIssue(t *testing.T) {
+	for _, id := range []string{config.IssueInvalidJSON, " ENV-MISSING "} {
+		if err := explainIssue(id); err != nil {
+			t.Errorf("explainIssue(%q): %v", id, err)
+		}
+	}
+
+	err := explainIssue("no-such-issue")
+	if err == nil {
+		t.Fatal("explainIssue accepted an unknown ID")
+	}
+	if !strings.Contains(err.Error(), config.IssueInvalidJSON) {
+		t.Errorf("err = %q, want the known IDs listed", err)
+	}
+}
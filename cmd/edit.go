@@ -2,20 +2,16 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
-	"cc-switch/internal/config"
-	"cc-switch/internal/handler"
-	"cc-switch/internal/ui"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
+	"github.com/HoBeedzc/cc-switch/internal/ui"
 
 	"github.com/spf13/cobra"
 )
 
-var (
-	editField    string
-	useNano      bool
-	templateName string
-)
-
 var editCmd = &cobra.Command{
 	Use:   "edit [name]",
 	Short: "Edit configuration or template content",
@@ -28,6 +24,30 @@ Configuration Mode:
 
 Template Mode:
 - Edit template: cc-switch edit -t <template-name> or cc-switch edit --template <template-name>
+- Pick the template from a list: cc-switch edit -t (or cc-switch edit -t -i)
+- Make it the default for 'cc-switch new': cc-switch edit -t <template-name> --make-default
+- List templates and the default for new: cc-switch edit -t --list
+- Editing the template 'cc-switch new' uses by default asks for confirmation first; use -y/--yes to skip it.
+
+Patch Mode (non-interactive, for automation):
+- cc-switch edit <name> --json-patch '[{"op":"replace","path":"/env/ANTHROPIC_BASE_URL","value":"https://proxy"}]'
+- cc-switch edit <name> --json-patch-file patch.json
+- Works with -t/--template too. Supports the RFC 6902 add, replace, remove and test operations;
+  the whole patch is rejected if any operation (including a test) fails.
+
+Reset Mode (recover a mangled configuration):
+- cc-switch edit <name> --reset                 Restore content from the template it was created from
+- cc-switch edit <name> --reset --from <tmpl>   Restore content from another template
+- cc-switch edit <name> --reset --keep-secrets  Keep existing token/key values
+- Asks for confirmation before overwriting; use -y/--yes to skip it.
+
+Display Name (shown in selectors, view and the web UI; commands keep using <name>):
+- cc-switch edit <name> --display-name "Work (US-East gateway)"
+- cc-switch edit <name> --display-name ""       Clear the display name
+
+Project Directory (use --launch starts Claude Code in it):
+- cc-switch edit <name> --project ~/src/billing
+- cc-switch edit <name> --project ""            Clear the project directory
 
 The interactive mode allows you to browse and select configurations with arrow keys.
 The --current flag edits the currently active configuration.
@@ -51,14 +71,57 @@ Changes are validated for JSON syntax before saving.`,
 		}
 
 		configHandler := handler.NewConfigHandler(cm)
-		templateName, _ := cmd.Flags().GetString("template")
+
+		// Validate flag combinations
+		if err := checkFlagRules(cmd, editFlagRules); err != nil {
+			return err
+		}
+
+		templateFlag, _ := cmd.Flags().GetBool("template")
 		field, _ := cmd.Flags().GetString("field")
 		nano, _ := cmd.Flags().GetBool("nano")
 		current, _ := cmd.Flags().GetBool("current")
 
+		patch, err := readJSONPatchFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		reset, _ := cmd.Flags().GetBool("reset")
+		keepSecrets, _ := cmd.Flags().GetBool("keep-secrets")
+		from, _ := cmd.Flags().GetString("from")
+		yes, _ := cmd.Flags().GetBool("yes")
+		makeDefault, _ := cmd.Flags().GetBool("make-default")
+
+		if listTemplates, _ := cmd.Flags().GetBool("list"); listTemplates {
+			return executeListTemplates(configHandler)
+		}
+
+		if !templateFlag {
+			args = resolveAliasArg(cm, args)
+		}
+
+		if cmd.Flags().Changed("display-name") {
+			displayName, _ := cmd.Flags().GetString("display-name")
+			return executeSetDisplayName(configHandler, ui.NewCLIUI(), args, current, displayName)
+		}
+
+		if cmd.Flags().Changed("project") {
+			project, _ := cmd.Flags().GetString("project")
+			return executeSetProject(configHandler, ui.NewCLIUI(), args, current, project)
+		}
+
+		if reset {
+			return executeReset(configHandler, ui.NewCLIUI(), args, current, from, keepSecrets, yes)
+		}
+
 		// Template mode handling
-		if templateName != "" {
-			return executeEditTemplate(configHandler, templateName, field, nano)
+		if templateFlag {
+			return executeTemplateMode(configHandler, ui.NewCLIUI(), args, field, nano, patch, makeDefault, yes)
+		}
+
+		if patch != nil {
+			return executePatch(configHandler, args, current, patch)
 		}
 
 		// Regular configuration editing mode
@@ -135,6 +198,203 @@ func executeEdit(configHandler handler.ConfigHandler, uiProvider ui.UIProvider,
 	return nil
 }
 
+// readJSONPatchFlags returns the patch given via --json-patch or --json-patch-file, or nil if neither is set
+func readJSONPatchFlags(cmd *cobra.Command) ([]byte, error) {
+	patchFlag, _ := cmd.Flags().GetString("json-patch")
+	patchFile, _ := cmd.Flags().GetString("json-patch-file")
+
+	if patchFile != "" {
+		data, err := os.ReadFile(patchFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read patch file: %w", err)
+		}
+		return data, nil
+	}
+
+	if patchFlag != "" {
+		return []byte(patchFlag), nil
+	}
+
+	return nil, nil
+}
+
+// executePatch applies a JSON patch to a named or the current configuration
+func executePatch(configHandler handler.ConfigHandler, args []string, useCurrent bool, patch []byte) error {
+	uiProvider := ui.NewCLIUI()
+
+	var targetName string
+	if len(args) > 0 {
+		targetName = args[0]
+	} else if useCurrent {
+		currentProfile, err := configHandler.GetCurrentConfigurationForOperation()
+		if err != nil {
+			return handleCurrentConfigError(err, uiProvider)
+		}
+		targetName = currentProfile
+	} else {
+		return fmt.Errorf("configuration name or --current is required with --json-patch")
+	}
+
+	if err := configHandler.PatchConfig(targetName, patch); err != nil {
+		return err
+	}
+
+	uiProvider.ShowSuccess("Configuration '%s' patched successfully", targetName)
+	return nil
+}
+
+// executeReset restores a named or the current configuration to its originating template
+// executeSetDisplayName sets or clears the display name of a configuration
+func executeSetDisplayName(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, args []string, useCurrent bool, displayName string) error {
+	var targetName string
+	if len(args) > 0 {
+		targetName = args[0]
+	} else if useCurrent {
+		currentProfile, err := configHandler.GetCurrentConfigurationForOperation()
+		if err != nil {
+			return handleCurrentConfigError(err, uiProvider)
+		}
+		targetName = currentProfile
+	} else {
+		return fmt.Errorf("configuration name or --current is required with --display-name")
+	}
+
+	if err := configHandler.SetConfigDisplayName(targetName, displayName); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(displayName) == "" {
+		uiProvider.ShowSuccess("Display name of '%s' cleared", targetName)
+	} else {
+		uiProvider.ShowSuccess("Display name of '%s' set to \"%s\"", targetName, strings.TrimSpace(displayName))
+	}
+	return nil
+}
+
+// executeSetProject sets or clears the project directory of a configuration
+func executeSetProject(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, args []string, useCurrent bool, project string) error {
+	var targetName string
+	if len(args) > 0 {
+		targetName = args[0]
+	} else if useCurrent {
+		currentProfile, err := configHandler.GetCurrentConfigurationForOperation()
+		if err != nil {
+			return handleCurrentConfigError(err, uiProvider)
+		}
+		targetName = currentProfile
+	} else {
+		return fmt.Errorf("configuration name or --current is required with --project")
+	}
+
+	dir, err := configHandler.SetConfigProject(targetName, project)
+	if err != nil {
+		return err
+	}
+
+	if dir == "" {
+		uiProvider.ShowSuccess("Project directory of '%s' cleared", targetName)
+	} else {
+		uiProvider.ShowSuccess("Project directory of '%s' set to %s", targetName, dir)
+	}
+	return nil
+}
+
+func executeReset(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, args []string, useCurrent bool, from string, keepSecrets, skipConfirm bool) error {
+	var targetName string
+	if len(args) > 0 {
+		targetName = args[0]
+	} else if useCurrent {
+		currentProfile, err := configHandler.GetCurrentConfigurationForOperation()
+		if err != nil {
+			return handleCurrentConfigError(err, uiProvider)
+		}
+		targetName = currentProfile
+	} else {
+		return fmt.Errorf("configuration name or --current is required with --reset")
+	}
+
+	if err := configHandler.ValidateConfigExists(targetName); err != nil {
+		return err
+	}
+
+	sourceTemplate := from
+	if sourceTemplate == "" {
+		recorded, err := configHandler.GetConfigTemplate(targetName)
+		if err != nil {
+			return fmt.Errorf("%w; use --from <template> to choose one", err)
+		}
+		sourceTemplate = recorded
+	}
+
+	if !skipConfirm {
+		confirmMsg := fmt.Sprintf("Reset configuration '%s' to template '%s'? Its current content will be overwritten", targetName, sourceTemplate)
+		if keepSecrets {
+			confirmMsg = fmt.Sprintf("Reset configuration '%s' to template '%s'? Its current content will be overwritten (secrets are kept)", targetName, sourceTemplate)
+		}
+		if !uiProvider.ConfirmAction(confirmMsg, false) {
+			uiProvider.ShowInfo("Operation cancelled")
+			return nil
+		}
+	}
+
+	if err := configHandler.ResetConfig(targetName, sourceTemplate, keepSecrets); err != nil {
+		return err
+	}
+
+	uiProvider.ShowSuccess("Configuration '%s' reset to template '%s'", targetName, sourceTemplate)
+	return nil
+}
+
+// executeTemplateMode edits or patches the named template, or one picked from a menu when no name is given
+func executeTemplateMode(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, args []string, field string, useNano bool, patch []byte, makeDefault, skipConfirm bool) error {
+	var templateName string
+	if len(args) > 0 {
+		templateName = args[0]
+	} else {
+		if patch != nil {
+			return fmt.Errorf("template name is required with --json-patch")
+		}
+		templates, err := configHandler.ListTemplates()
+		if err != nil {
+			return fmt.Errorf("failed to list templates: %w", err)
+		}
+		if len(templates) == 0 {
+			uiProvider.ShowWarning("No templates found.")
+			fmt.Println("Use 'cc-switch edit -t <template-name>' to create your first template.")
+			return nil
+		}
+		selection, err := ui.SelectFromMenu("Available templates:", ui.TemplateMenuItems(templates), "Select template to edit")
+		if err != nil {
+			return fmt.Errorf("selection cancelled: %w", err)
+		}
+		templateName = templates[selection]
+	}
+
+	if patch != nil {
+		if err := configHandler.PatchTemplate(templateName, patch); err != nil {
+			return err
+		}
+		fmt.Printf("Template '%s' patched successfully\n", templateName)
+	} else {
+		// The default template seeds every new configuration, so make sure it is the one meant
+		if !skipConfirm && templateName == configHandler.DefaultTemplateName() && configHandler.ValidateTemplateExists(templateName) == nil {
+			confirmMsg := fmt.Sprintf("Template '%s' is used by 'cc-switch new' by default; changes apply to every configuration created from now on. Edit it?", templateName)
+			if !uiProvider.ConfirmAction(confirmMsg, false) {
+				uiProvider.ShowInfo("Operation cancelled")
+				return nil
+			}
+		}
+		if err := executeEditTemplate(configHandler, templateName, field, useNano); err != nil {
+			return err
+		}
+	}
+
+	if makeDefault {
+		return makeDefaultTemplate(configHandler, templateName)
+	}
+	return nil
+}
+
 // executeEditTemplate handles template editing
 func executeEditTemplate(configHandler handler.ConfigHandler, templateName string, field string, useNano bool) error {
 	if templateName == "" {
@@ -174,10 +434,36 @@ func executeEditTemplate(configHandler handler.ConfigHandler, templateName strin
 	return nil
 }
 
+// editFlagRules declares the flag combinations edit rejects. --current selects the
+// configuration itself, so it cannot be combined with a template or interactive selection.
+var editFlagRules = [][]flagRule{
+	exclusiveFlags("json-patch", "json-patch-file"),
+	exclusiveFlags("current", "template"),
+	exclusiveFlags("current", "interactive"),
+	conflictsWith("display-name", "template", "field", "reset", "json-patch", "json-patch-file", "nano"),
+	conflictsWith("project", "display-name", "template", "field", "reset", "json-patch", "json-patch-file", "nano"),
+	conflictsWith("reset", "template", "field", "json-patch", "json-patch-file", "nano", "interactive"),
+	requiresFlag("keep-secrets", "reset"),
+	requiresFlag("from", "reset"),
+	requiresFlag("make-default", "template"),
+	conflictsWith("list", "current", "interactive", "field", "reset", "json-patch", "json-patch-file", "display-name", "project", "make-default"),
+}
+
 func init() {
-	editCmd.Flags().StringVar(&editField, "field", "", "Edit a specific field (e.g., 'env.ANTHROPIC_API_KEY')")
-	editCmd.Flags().BoolVar(&useNano, "nano", false, "Use nano editor instead of default")
+	editCmd.Flags().String("field", "", "Edit a specific field (e.g., 'env.ANTHROPIC_API_KEY')")
+	editCmd.Flags().Bool("nano", false, "Use nano editor instead of default")
 	editCmd.Flags().BoolP("interactive", "i", false, "Enter interactive mode")
-	editCmd.Flags().StringVarP(&templateName, "template", "t", "", "Edit template instead of configuration")
+	editCmd.Flags().BoolP("template", "t", false, "Edit template instead of configuration")
 	editCmd.Flags().BoolP("current", "c", false, "Edit current active configuration")
+	editCmd.Flags().String("json-patch", "", "Apply an RFC 6902 JSON patch (add, replace, remove, test)")
+	editCmd.Flags().String("json-patch-file", "", "Apply an RFC 6902 JSON patch read from a file")
+	editCmd.Flags().Bool("reset", false, "Restore the configuration to the template it was created from")
+	editCmd.Flags().Bool("keep-secrets", false, "Keep existing token and key values when resetting")
+	editCmd.Flags().String("from", "", "Template to reset from instead of the recorded one")
+	editCmd.Flags().BoolP("yes", "y", false, "Skip the confirmation prompt when resetting or editing the default template")
+	editCmd.Flags().Bool("make-default", false, "Make the template the default for 'cc-switch new' (use with -t)")
+	editCmd.Flags().Bool("list", false, "List templates, marking the one 'cc-switch new' uses by default")
+	editCmd.Flags().String("display-name", "", "Set a friendly name shown in selectors and the web UI (empty to clear)")
+	editCmd.Flags().String("project", "", "Set the project directory 'use --launch' starts Claude Code in (empty to clear)")
+	editCmd.RegisterFlagCompletionFunc("from", completeTemplateNames)
 }
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var emptyShowSecrets bool
+
+var emptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Inspect the settings backup kept while in empty mode",
+	Long: `Inspect and export the settings.json backup that cc-switch keeps while empty mode
+is active ('cc-switch use --empty').
+
+Examples:
+  cc-switch empty status          # when empty mode was enabled, previous profile, backup file
+  cc-switch empty show            # backed-up settings with secrets masked
+  cc-switch empty export rescued  # save the backup as configuration 'rescued'
+
+These commands do not leave empty mode; use 'cc-switch use --restore' for that.`,
+}
+
+var emptyStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show empty mode status and the backup file",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		backup, err := loadEmptyModeBackup()
+		if err != nil {
+			return err
+		}
+
+		color.Yellow("Empty mode: enabled")
+		fmt.Printf("Enabled at:       %s\n", backup.Timestamp)
+		if backup.PreviousProfile != "" {
+			fmt.Printf("Previous profile: %s\n", backup.PreviousProfile)
+		} else {
+			fmt.Printf("Previous profile: (none)\n")
+		}
+		fmt.Printf("Backup file:      %s (%s)\n", backup.BackupPath, formatFileSize(backup.Size))
+
+		env, _ := backup.Content["env"].(map[string]interface{})
+		if len(env) > 0 {
+			keys := make([]string, 0, len(env))
+			for key := range env {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			fmt.Println("Env keys:")
+			for _, key := range keys {
+				fmt.Printf("  %s = %s\n", key, maskEnvValue(key, env[key]))
+			}
+		}
+
+		if backup.CanRestore {
+			fmt.Println("\nUse 'cc-switch use --restore' to leave empty mode and restore this backup.")
+		}
+		return nil
+	},
+}
+
+var emptyShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Display the backed-up settings",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		backup, err := loadEmptyModeBackup()
+		if err != nil {
+			return err
+		}
+
+		content := backup.Content
+		if !emptyShowSecrets {
+			content = maskSecretContent(content)
+		}
+
+		jsonData, err := json.MarshalIndent(content, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to format JSON: %w", err)
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	},
+}
+
+var emptyExportCmd = &cobra.Command{
+	Use:   "export <name>",
+	Short: "Save the backup as a new configuration without leaving empty mode",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configHandler, err := newEmptyModeHandler()
+		if err != nil {
+			return err
+		}
+
+		if err := configHandler.ExportEmptyModeBackup(args[0]); err != nil {
+			return err
+		}
+
+		color.Green("✓ Saved the empty mode backup as configuration '%s'", args[0])
+		fmt.Println("Empty mode is still active. Use 'cc-switch use <name>' to switch to it.")
+		return nil
+	},
+}
+
+// newEmptyModeHandler creates a config handler and refuses to continue outside empty mode
+func newEmptyModeHandler() (handler.ConfigHandler, error) {
+	if err := checkClaudeConfig(); err != nil {
+		return nil, err
+	}
+
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	configHandler := handler.NewConfigHandler(cm)
+	if !configHandler.IsEmptyMode() {
+		return nil, fmt.Errorf("not in empty mode. Use 'cc-switch use --empty' to enable it")
+	}
+
+	return configHandler, nil
+}
+
+// loadEmptyModeBackup reads the empty mode status and backed-up settings
+func loadEmptyModeBackup() (*handler.EmptyModeBackup, error) {
+	configHandler, err := newEmptyModeHandler()
+	if err != nil {
+		return nil, err
+	}
+	return configHandler.GetEmptyModeBackup()
+}
+
+// maskEnvValue renders an env value for display, masking secrets
+func maskEnvValue(key string, value interface{}) string {
+	s, ok := value.(string)
+	if !ok {
+		data, _ := json.Marshal(value)
+		return string(data)
+	}
+	if config.IsSecretKey(key) {
+		return maskSecretValue(s)
+	}
+	return s
+}
+
+// maskSecretValue hides all but the last four characters of a secret value
+func maskSecretValue(value string) string {
+	if len(value) <= 8 {
+		return strings.Repeat("*", len(value))
+	}
+	return strings.Repeat("*", 8) + value[len(value)-4:]
+}
+
+// maskSecretContent returns a copy of the configuration with secret values masked
+func maskSecretContent(content map[string]interface{}) map[string]interface{} {
+	masked := make(map[string]interface{}, len(content))
+	for key, value := range content {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			masked[key] = maskSecretContent(v)
+		case string:
+			if config.IsSecretKey(key) {
+				masked[key] = maskSecretValue(v)
+			} else {
+				masked[key] = v
+			}
+		default:
+			masked[key] = v
+		}
+	}
+	return masked
+}
+
+func init() {
+	emptyShowCmd.Flags().BoolVar(&emptyShowSecrets, "show-secrets", false, "Show token and key values instead of masking them")
+	emptyCmd.AddCommand(emptyStatusCmd, emptyShowCmd, emptyExportCmd)
+}
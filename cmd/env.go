@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Inspect environment variables that affect Claude Code",
+}
+
+var envDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare the shell environment with the active configuration's env",
+	Long: `Compare the env section of the active configuration with the variables set in
+the current shell. Variables exported in the shell take precedence over
+settings.json, so a stale ANTHROPIC_AUTH_TOKEN in your shell profile can make
+Claude Code keep using an old key after a switch.
+
+Values are never printed, only whether they differ.
+
+  ≠  set in both, but the shell value differs from the configuration
+  +  set in the shell only (ANTHROPIC_* and configured token keys)
+
+Use --all to also list variables that match or are only set by the configuration.
+
+Exits with status 1 when the shell overrides the configuration.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+
+		if err := checkClaudeConfig(); err != nil {
+			return err
+		}
+		cm, err := config.NewConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+		if cm.IsEmptyMode() {
+			return fmt.Errorf("no active configuration (empty mode)")
+		}
+		current, err := cm.GetCurrentProfile()
+		if err != nil || current == "" {
+			return fmt.Errorf("no current configuration set")
+		}
+
+		// settings.json holds the resolved values Claude Code actually reads
+		content, err := cm.GetSettingsContent()
+		if err != nil {
+			return err
+		}
+
+		comparisons := config.CompareShellEnv(content, os.Environ())
+		overrides := 0
+		for _, c := range comparisons {
+			switch c.Status {
+			case config.EnvDiffers:
+				overrides++
+				color.Red("≠ %s  shell value differs from '%s'", c.Key, current)
+			case config.EnvShellOnly:
+				overrides++
+				color.Yellow("+ %s  set in the shell only", c.Key)
+			case config.EnvMatches:
+				if all {
+					color.Green("= %s", c.Key)
+				}
+			case config.EnvProfileOnly:
+				if all {
+					color.New(color.FgHiBlack).Printf("  %s  not set in the shell\n", c.Key)
+				}
+			}
+		}
+
+		if overrides == 0 {
+			color.Green("✓ Shell environment does not override '%s'", current)
+			return nil
+		}
+		fmt.Printf("\n%d variable(s) in the shell override '%s'; unset them or start a new shell\n", overrides, current)
+		return errSilentFailure
+	},
+}
+
+func init() {
+	envDiffCmd.Flags().BoolP("all", "a", false, "Also list matching variables and those only set by the configuration")
+	envCmd.AddCommand(envDiffCmd)
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+)
+
+// exitCode is a process exit status and the error category it reports
+type exitCode struct {
+	Code        int
+	Name        string
+	Description string
+	kind        error // matched with errors.Is; nil for codes without a category
+}
+
+// exitCodes lists the exit statuses cc-switch uses. Scripts rely on them, so
+// existing codes must never change meaning; add new ones at the end.
+var exitCodes = []exitCode{
+	{0, "ok", "The command succeeded.", nil},
+	{1, "error", "The command failed for any other reason: invalid flags, I/O errors, a failed connectivity test or import, or a cancelled prompt.", nil},
+	{2, "not-found", "A configuration, template or secret does not exist, or there is no current configuration.", config.ErrNotFound},
+	{3, "conflict", "The target name already exists, or a template is still used by configurations.", config.ErrConflict},
+	{4, "validation", "A name, field value, JSON content, patch or manifest is invalid, or 'doctor' found configuration errors.", config.ErrInvalid},
+	{5, "locked", "The secrets store is locked (set CC_SWITCH_SECRETS_PASSPHRASE) or the configuration is read-only (a system profile or the original-settings snapshot).", config.ErrLocked},
+}
+
+// ExitCode returns the process exit status for an error returned by Execute
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	for _, code := range exitCodes {
+		if code.kind != nil && errors.Is(err, code.kind) {
+			return code.Code
+		}
+	}
+	return 1
+}
+
+// explainExitCode prints what an exit code means, or every code for "all"
+func explainExitCode(arg string) error {
+	if arg == "all" {
+		for _, code := range exitCodes {
+			fmt.Printf("%d  %-10s  %s\n", code.Code, code.Name, code.Description)
+		}
+		return nil
+	}
+
+	for _, code := range exitCodes {
+		if arg == strconv.Itoa(code.Code) || arg == code.Name {
+			fmt.Printf("Exit code %d (%s)\n\n%s\n", code.Code, code.Name, code.Description)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown exit code '%s'; use --explain all to list them", arg)
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", err: nil, want: 0},
+		{name: "plain error", err: errors.New("boom"), want: 1},
+		{name: "silent failure", err: errSilentFailure, want: 1},
+		{name: "not found", err: config.NotFoundf("profile '%s' does not exist", "work"), want: 2},
+		{name: "wrapped not found", err: fmt.Errorf("failed to switch: %w", config.NotFoundf("missing")), want: 2},
+		{name: "conflict", err: config.Conflictf("profile '%s' already exists", "work"), want: 3},
+		{name: "template in use", err: &config.TemplateInUseError{Template: "team", Dependents: []string{"work"}}, want: 3},
+		{name: "validation", err: config.Invalidf("invalid name"), want: 4},
+		{name: "locked", err: config.Lockedf("read-only"), want: 5},
+		{name: "config check", err: &ClaudeConfigError{State: ConfigNotInitialized}, want: 1},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("%s: ExitCode(%v) = %d, want %d", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestExitCodesAreStable(t *testing.T) {
+	for i, code := range exitCodes {
+		if code.Code != i {
+			t.Errorf("exitCodes[%d] has code %d; codes must stay in order without gaps", i, code.Code)
+		}
+		if code.Name == "" || code.Description == "" {
+			t.Errorf("exit code %d has no name or description", code.Code)
+		}
+	}
+}
+
+func TestExplainExitCode(t *testing.T) {
+	for _, arg := range []string{"2", "not-found", "all"} {
+		if err := explainExitCode(arg); err != nil {
+			t.Errorf("explainExitCode(%q): %v", arg, err)
+		}
+	}
+	if err := explainExitCode("42"); err == nil {
+		t.Error("explainExitCode accepted an unknown code")
+	}
+}
+
+func TestCommandExitCodes(t *testing.T) {
+	home := setupHome(t)
+	cm := newTestManager(t)
+	for _, name := range []string{"work", "home"} {
+		if err := cm.CreateProfileWithContent(name, map[string]interface{}{"model": name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cm.UseProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A read-only system profile
+	systemDir := filepath.Join(home, "system")
+	if err := os.MkdirAll(systemDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(systemDir, "shared.json"), []byte(`{"model": "shared"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.SystemProfilesDirEnv, systemDir)
+
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{args: []string{"view", "work"}, want: 0},
+		{args: []string{"use", "missing"}, want: 2},
+		{args: []string{"view", "missing"}, want: 2},
+		{args: []string{"rm", "-y", "missing"}, want: 2},
+		{args: []string{"test", "missing"}, want: 2},
+		{args: []string{"cp", "work", "home"}, want: 3},
+		{args: []string{"mv", "home", "work"}, want: 3},
+		{args: []string{"cp", "work", "empty_mode"}, want: 4},
+		{args: []string{"edit", "home", "--json-patch", `[{"op": "replace", "path": "/missing", "value": 1}]`}, want: 4},
+		{args: []string{"edit", "shared", "--json-patch", `[{"op": "add", "path": "/model", "value": "x"}]`}, want: 5},
+		{args: []string{"rm", "-y", "shared"}, want: 5},
+		{args: []string{"use", "-p", "-e"}, want: 1},
+	}
+
+	for _, tt := range tests {
+		name := strings.Join(tt.args, " ")
+		if got := ExitCode(runCommand(t, tt.args...)); got != tt.want {
+			t.Errorf("cc-switch %s: exit code %d, want %d", name, got, tt.want)
+		}
+	}
+}
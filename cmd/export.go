@@ -6,8 +6,9 @@ import (
 	"strings"
 	"syscall"
 
-	"cc-switch/internal/config"
-	"cc-switch/internal/export"
+	"github.com/HoBeedzc/cc-switch/internal/common"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/export"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -19,6 +20,14 @@ var (
 	exportPassword string
 	exportAll      bool
 	exportCurrent  bool
+	exportProfiles []string
+	exportUpload   string
+	exportInsecure bool
+	exportSecrets  bool
+
+	exportTemplates      []string
+	exportAllTemplates   bool
+	exportIncludeDefault bool
 )
 
 var exportCmd = &cobra.Command{
@@ -37,6 +46,23 @@ Examples:
   cc-switch export --current -o current-config.ccx
   cc-switch export -c -o current-config.ccx
 
+  # Export several profiles into one file
+  cc-switch export --profiles work,personal -o team.ccx
+
+  # Upload the export to a web server with HTTP PUT
+  # (bearer token read from $CC_SWITCH_REMOTE_TOKEN if set)
+  cc-switch export --profiles work,personal --upload https://internal/backups/team.ccx
+
+  # Export only templates (the default template needs --include-default)
+  cc-switch export --templates team,proxy -o templates.ccx
+  cc-switch export --all-templates -o templates.ccx
+
+  # Export profiles together with templates
+  cc-switch export --all --all-templates -o everything.ccx
+
+  # Embed secrets referenced with @secret: (requires a password)
+  cc-switch export --all --include-secrets -o all-configs.ccx -p mypassword
+
   # Interactive password input (recommended for security)
   cc-switch export default -o backup.ccx`,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -45,6 +71,12 @@ Examples:
 		}
 
 		// Validate flags
+		if err := checkFlagRules(cmd, exportFlagRules); err != nil {
+			return err
+		}
+		if err := checkNameWithFlags(cmd, args, "all", "current", "profiles"); err != nil {
+			return err
+		}
 		if err := validateExportFlags(args); err != nil {
 			return err
 		}
@@ -57,6 +89,14 @@ Examples:
 
 		// Create exporter
 		exporter := export.NewExporter(cm)
+		exporter.SetIncludeSecrets(exportSecrets)
+		exporter.SetProgress(showExportProgress)
+
+		templateCount, err := selectExportTemplates(cm, exporter)
+		if err != nil {
+			return err
+		}
+		templatesOnly := templateCount > 0 && !exportAll && !exportCurrent && len(exportProfiles) == 0 && len(args) == 0
 
 		// Get password if not provided
 		password := exportPassword
@@ -67,33 +107,71 @@ Examples:
 			}
 		}
 
+		if exportSecrets && password == "" {
+			return fmt.Errorf("--include-secrets requires an encryption password")
+		}
+
 		// Show security recommendations if using password
 		if password != "" {
 			showSecurityRecommendations()
 		}
 
-		// Ensure output file has .ccx extension
-		outputPath := ensureCCXExtension(exportOutput)
+		// Ensure output file has .ccx extension; uploads without -o go through a temporary file
+		var outputPath string
+		if exportOutput != "" {
+			outputPath = ensureCCXExtension(exportOutput)
+		} else {
+			tempFile, err := os.CreateTemp("", "cc-switch-export-*.ccx")
+			if err != nil {
+				return fmt.Errorf("failed to create temporary file: %w", err)
+			}
+			tempFile.Close()
+			outputPath = tempFile.Name()
+			defer os.Remove(outputPath)
+		}
 
 		var exportErr error
 		profileCount := 0
+		skippedCount := 0
 
 		color.Cyan("📦 Preparing export...")
 
-		if exportAll {
+		if templatesOnly {
+			// Export only templates
+			color.Cyan("📦 Exporting %d template(s)...", templateCount)
+			var exported []string
+			exported, exportErr = exporter.ExportTemplates(password, outputPath)
+			templateCount = len(exported)
+		} else if exportAll {
 			// Export all profiles
 			profiles, err := cm.ListProfiles()
 			if err != nil {
 				return fmt.Errorf("failed to list profiles: %w", err)
 			}
-			profileCount = len(profiles)
-
-			if profileCount == 0 {
+			if len(profiles) == 0 {
 				return fmt.Errorf("no profiles found to export")
 			}
 
-			color.Cyan("📦 Collecting profiles... (%d found)", profileCount)
+			for _, profile := range profiles {
+				if profile.Error != "" {
+					skippedCount++
+				}
+			}
+			profileCount = len(profiles) - skippedCount
+
+			color.Cyan("📦 Collecting profiles... (%d found)", len(profiles))
 			exportErr = exporter.ExportAll(password, outputPath)
+		} else if len(exportProfiles) > 0 {
+			// Export selected profiles
+			for _, name := range exportProfiles {
+				if !cm.ProfileExists(name) {
+					return config.NotFoundf("profile '%s' does not exist", name)
+				}
+			}
+
+			profileCount = len(exportProfiles)
+			color.Cyan("📦 Exporting %d profiles...", profileCount)
+			exportErr = exporter.ExportProfiles(exportProfiles, password, outputPath)
 		} else if exportCurrent {
 			// Export current profile
 			current, err := cm.GetCurrentProfile()
@@ -111,7 +189,7 @@ Examples:
 			// Export specific profile
 			profileName := args[0]
 			if !cm.ProfileExists(profileName) {
-				return fmt.Errorf("profile '%s' does not exist", profileName)
+				return config.NotFoundf("profile '%s' does not exist", profileName)
 			}
 
 			profileCount = 1
@@ -120,23 +198,46 @@ Examples:
 		}
 
 		if exportErr != nil {
+			common.FinishProgress("export", 0, profileCount+skippedCount, 0, common.ResultFailed)
 			return fmt.Errorf("export failed: %w", exportErr)
 		}
 
+		// Upload the export if requested
+		if exportUpload != "" {
+			color.Cyan("📤 Uploading to %s...", exportUpload)
+			uploaded, err := common.UploadFile(exportUpload, outputPath, common.RemoteOptions{Insecure: exportInsecure})
+			if err != nil {
+				common.FinishProgress("export", profileCount, profileCount+skippedCount, 0, common.ResultFailed)
+				return fmt.Errorf("upload failed: %w", err)
+			}
+			color.Green("✅ Export uploaded (%s, %s)", describeExportCounts(profileCount, templateCount), formatFileSize(uploaded))
+			color.Blue("🌐 Uploaded to: %s", exportUpload)
+			if exportOutput != "" {
+				color.Blue("📁 Local copy: %s", outputPath)
+			}
+		}
+
 		// Show success message with file size
 		fileInfo, err := os.Stat(outputPath)
-		if err == nil {
+		if exportUpload != "" {
+			// Summary already shown above
+		} else if err == nil {
 			size := formatFileSize(fileInfo.Size())
-			color.Green("✅ Export completed (%d profiles, %s)", profileCount, size)
+			color.Green("✅ Export completed (%s, %s)", describeExportCounts(profileCount, templateCount), size)
 			color.Blue("📁 Saved to: %s", outputPath)
 		} else {
-			color.Green("✅ Export completed (%d profiles)", profileCount)
+			color.Green("✅ Export completed (%s)", describeExportCounts(profileCount, templateCount))
+		}
+
+		if skippedCount > 0 {
+			color.Yellow("⚠️  %d unreadable profile(s) skipped", skippedCount)
 		}
 
 		if password != "" {
 			color.Yellow("🔒 File is encrypted and protected")
 		}
 
+		common.FinishProgress("export", profileCount, profileCount+skippedCount, 0, common.ResultOK)
 		return nil
 	},
 }
@@ -146,35 +247,113 @@ func init() {
 	exportCmd.Flags().StringVarP(&exportPassword, "password", "p", "", "Encryption password (prompt if not provided)")
 	exportCmd.Flags().BoolVar(&exportAll, "all", false, "Export all profiles")
 	exportCmd.Flags().BoolVarP(&exportCurrent, "current", "c", false, "Export current profile")
+	exportCmd.Flags().StringSliceVar(&exportProfiles, "profiles", nil, "Comma-separated list of profiles to export")
+	exportCmd.Flags().StringVar(&exportUpload, "upload", "", "Upload the export to this URL with HTTP PUT")
+	exportCmd.Flags().BoolVar(&exportInsecure, "insecure", false, "Skip TLS certificate verification for --upload")
+	exportCmd.Flags().BoolVar(&exportSecrets, "include-secrets", false, "Embed secrets referenced with @secret: in the (encrypted) export")
+	exportCmd.Flags().StringSliceVar(&exportTemplates, "templates", nil, "Comma-separated list of templates to export")
+	exportCmd.Flags().BoolVar(&exportAllTemplates, "all-templates", false, "Export all templates (except default unless --include-default)")
+	exportCmd.Flags().BoolVar(&exportIncludeDefault, "include-default", false, "Include the default template in a template export")
+}
 
-	exportCmd.MarkFlagRequired("output")
+// exportFlagRules declares the flag combinations export rejects
+var exportFlagRules = [][]flagRule{
+	exclusiveFlags("all", "current", "profiles"),
+	exclusiveFlags("templates", "all-templates"),
+	requiresFlag("insecure", "upload"),
+	requiresFlag("include-default", "templates", "all-templates"),
 }
 
 func validateExportFlags(args []string) error {
-	flagCount := 0
-	if exportAll {
-		flagCount++
+	if !exportAll && !exportCurrent && len(exportProfiles) == 0 && len(args) == 0 && len(exportTemplates) == 0 && !exportAllTemplates {
+		return fmt.Errorf("must specify either a profile name, --profiles, --all, --current, --templates or --all-templates")
 	}
-	if exportCurrent {
-		flagCount++
+
+	if exportOutput == "" && exportUpload == "" {
+		return fmt.Errorf("output file path is required (-o/--output) unless --upload is used")
 	}
-	if len(args) > 0 {
-		flagCount++
+
+	if exportUpload != "" && !common.IsRemoteURL(exportUpload) {
+		return fmt.Errorf("--upload must be an http:// or https:// URL")
 	}
 
-	if flagCount == 0 {
-		return fmt.Errorf("must specify either a profile name, --all, or --current")
+	return nil
+}
+
+// selectExportTemplates configures which templates the exporter embeds and
+// returns how many were selected
+func selectExportTemplates(cm *config.ConfigManager, exporter *export.ExporterImpl) (int, error) {
+	if len(exportTemplates) > 0 {
+		for _, name := range exportTemplates {
+			if !cm.TemplateExists(name) {
+				return 0, config.NotFoundf("template '%s' does not exist", name)
+			}
+			if name == "default" && !exportIncludeDefault {
+				return 0, config.Invalidf("the default template is only exported with --include-default")
+			}
+		}
+		exporter.SetTemplates(exportTemplates)
+		return len(exportTemplates), nil
 	}
 
-	if flagCount > 1 {
-		return fmt.Errorf("cannot use --all, --current, and profile name together")
+	if !exportAllTemplates {
+		return 0, nil
 	}
 
-	if exportOutput == "" {
-		return fmt.Errorf("output file path is required (-o/--output)")
+	templates, err := cm.ListTemplates()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list templates: %w", err)
+	}
+	count := 0
+	for _, name := range templates {
+		if name != "default" || exportIncludeDefault {
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, fmt.Errorf("no templates to export (the default template needs --include-default)")
 	}
 
-	return nil
+	exporter.SetIncludeTemplates(true)
+	exporter.SetIncludeDefaultTemplate(exportIncludeDefault)
+	return count, nil
+}
+
+// showExportProgress draws a progress bar on terminals while profiles are written.
+// Single-profile exports finish too quickly to need one. With --progress json it
+// emits an event per profile instead.
+func showExportProgress(progress export.Progress) {
+	if common.JSONProgress() {
+		common.EmitProgress(common.ProgressEvent{
+			Op: "export", Item: progress.Name, Done: progress.Profiles, Total: progress.Total,
+			Bytes: progress.Bytes, Status: common.ProgressRunning,
+		})
+		return
+	}
+	if progress.Total < 2 || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return
+	}
+
+	const width = 30
+	filled := width * progress.Profiles / progress.Total
+	fmt.Printf("\r   [%s%s] %3d%%  %d/%d profiles, %s ",
+		strings.Repeat("█", filled), strings.Repeat("░", width-filled),
+		progress.Percent, progress.Profiles, progress.Total, formatFileSize(progress.Bytes))
+	if progress.Profiles == progress.Total {
+		fmt.Println()
+	}
+}
+
+// describeExportCounts summarizes what an export contains, e.g. "2 profiles, 3 templates"
+func describeExportCounts(profileCount, templateCount int) string {
+	switch {
+	case templateCount == 0:
+		return fmt.Sprintf("%d profiles", profileCount)
+	case profileCount == 0:
+		return fmt.Sprintf("%d templates", templateCount)
+	default:
+		return fmt.Sprintf("%d profiles, %d templates", profileCount, templateCount)
+	}
 }
 
 func promptForPassword(prompt string) (string, error) {
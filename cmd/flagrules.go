@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// flagRule declares a constraint between the flags of one command.
+// Commands declare their rules as a list built with exclusiveFlags, conflictsWith
+// and requiresFlag, and check them with checkFlagRules before doing any work.
+type flagRule struct {
+	flags    []string // exclusive: at most one of these may be set; requires: the dependent flag
+	requires []string // at least one of these must be set when flags[0] is
+}
+
+// exclusiveFlags declares that at most one of the given flags may be set
+func exclusiveFlags(flags ...string) []flagRule {
+	return []flagRule{{flags: flags}}
+}
+
+// conflictsWith declares that flag cannot be combined with any of others
+// (the others may still be combined with each other)
+func conflictsWith(flag string, others ...string) []flagRule {
+	rules := make([]flagRule, 0, len(others))
+	for _, other := range others {
+		rules = append(rules, flagRule{flags: []string{flag, other}})
+	}
+	return rules
+}
+
+// requiresFlag declares that flag can only be used together with at least one of deps
+func requiresFlag(flag string, deps ...string) []flagRule {
+	return []flagRule{{flags: []string{flag}, requires: deps}}
+}
+
+// checkFlagRules returns an error naming the offending flags for the first violated rule
+func checkFlagRules(cmd *cobra.Command, groups [][]flagRule) error {
+	for _, rule := range slices.Concat(groups...) {
+		if rule.requires != nil {
+			flag := rule.flags[0]
+			if !cmd.Flags().Changed(flag) {
+				continue
+			}
+			satisfied := false
+			for _, dep := range rule.requires {
+				if cmd.Flags().Changed(dep) {
+					satisfied = true
+					break
+				}
+			}
+			if !satisfied {
+				deps := make([]string, len(rule.requires))
+				for i, dep := range rule.requires {
+					deps[i] = flagLabel(cmd, dep)
+				}
+				return fmt.Errorf("%s requires %s", flagLabel(cmd, flag), strings.Join(deps, " or "))
+			}
+			continue
+		}
+
+		var set []string
+		for _, flag := range rule.flags {
+			if cmd.Flags().Changed(flag) {
+				set = append(set, flag)
+			}
+		}
+		if len(set) > 1 {
+			return fmt.Errorf("cannot use %s and %s together", flagLabel(cmd, set[0]), flagLabel(cmd, set[1]))
+		}
+	}
+	return nil
+}
+
+// checkNameWithFlags rejects a positional name combined with any of the given flags
+func checkNameWithFlags(cmd *cobra.Command, args []string, flags ...string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	for _, flag := range flags {
+		if cmd.Flags().Changed(flag) {
+			return fmt.Errorf("cannot use a name argument and %s together", flagLabel(cmd, flag))
+		}
+	}
+	return nil
+}
+
+// flagLabel formats a flag for error messages, e.g. "-c/--current" or "--dry-run"
+func flagLabel(cmd *cobra.Command, name string) string {
+	if flag := cmd.Flags().Lookup(name); flag != nil && flag.Shorthand != "" {
+		return fmt.Sprintf("-%s/--%s", flag.Shorthand, name)
+	}
+	return "--" + name
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// commandFlagRules lists every command that validates its flags with checkFlagRules
+var commandFlagRules = []struct {
+	cmd   *cobra.Command
+	rules [][]flagRule
+}{
+	{cmd: useCmd, rules: useFlagRules},
+	{cmd: testCmd, rules: testFlagRules},
+	{cmd: rmCmd, rules: rmFlagRules},
+	{cmd: cpCmd, rules: cpFlagRules},
+	{cmd: editCmd, rules: editFlagRules},
+	{cmd: exportCmd, rules: exportFlagRules},
+	{cmd: importCmd, rules: importFlagRules},
+	{cmd: listCmd, rules: listFlagRules},
+	{cmd: applyCmd, rules: applyFlagRules},
+	{cmd: doctorCmd, rules: doctorFlagRules},
+	{cmd: migratePermissionsCmd, rules: migratePermissionsFlagRules},
+}
+
+// withFlagsSet returns a copy of cmd whose given flags are marked as set on the
+// command line; the flags of cmd itself are left untouched
+func withFlagsSet(cmd *cobra.Command, names ...string) *cobra.Command {
+	copied := &cobra.Command{Use: cmd.Use}
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		f := *flag
+		f.Changed = false
+		for _, name := range names {
+			if name == f.Name {
+				f.Changed = true
+			}
+		}
+		copied.Flags().AddFlag(&f)
+	})
+	return copied
+}
+
+func TestFlagRulesNameExistingFlags(t *testing.T) {
+	for _, c := range commandFlagRules {
+		for _, group := range c.rules {
+			for _, rule := range group {
+				for _, name := range append(append([]string{}, rule.flags...), rule.requires...) {
+					if c.cmd.Flags().Lookup(name) == nil {
+						t.Errorf("%s: rule names unknown flag --%s", c.cmd.Name(), name)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestFlagRulesRejectEveryConflict(t *testing.T) {
+	for _, c := range commandFlagRules {
+		for _, group := range c.rules {
+			for _, rule := range group {
+				if rule.requires != nil {
+					continue
+				}
+				for i, first := range rule.flags {
+					for _, second := range rule.flags[i+1:] {
+						err := checkFlagRules(withFlagsSet(c.cmd, first, second), c.rules)
+						if err == nil {
+							t.Errorf("%s --%s --%s: accepted", c.cmd.Name(), first, second)
+							continue
+						}
+						// The message names both flags unless another rule rejected the pair first
+						if strings.HasPrefix(err.Error(), "cannot use") &&
+							(!strings.Contains(err.Error(), flagLabel(c.cmd, first)) || !strings.Contains(err.Error(), flagLabel(c.cmd, second))) {
+							t.Errorf("%s --%s --%s: %q does not name both flags", c.cmd.Name(), first, second, err)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestFlagRulesRequirements(t *testing.T) {
+	for _, c := range commandFlagRules {
+		for _, group := range c.rules {
+			for _, rule := range group {
+				if rule.requires == nil {
+					continue
+				}
+				flag := rule.flags[0]
+				requires := flagLabel(c.cmd, flag) + " requires "
+
+				err := checkFlagRules(withFlagsSet(c.cmd, flag), c.rules)
+				if err == nil || !strings.HasPrefix(err.Error(), requires) {
+					t.Errorf("%s --%s alone: err = %v, want %q...", c.cmd.Name(), flag, err, requires)
+				}
+
+				for _, dep := range rule.requires {
+					err := checkFlagRules(withFlagsSet(c.cmd, flag, dep), c.rules)
+					if err != nil && strings.HasPrefix(err.Error(), requires) {
+						t.Errorf("%s --%s --%s: %v", c.cmd.Name(), flag, dep, err)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestCheckFlagRulesMessages(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags []string
+		want  string
+	}{
+		{name: "no flags", want: ""},
+		{name: "exclusive with shorthands", flags: []string{"previous", "empty"}, want: "cannot use -p/--previous and -e/--empty together"},
+		{name: "conflict without shorthand", flags: []string{"note", "refresh"}, want: "cannot use --note and -f/--refresh together"},
+		{name: "missing requirement", flags: []string{"test-before-launch"}, want: "--test-before-launch requires -l/--launch"},
+		{name: "requirement met", flags: []string{"test-before-launch", "launch"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkFlagRules(withFlagsSet(useCmd, tt.flags...), useFlagRules)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("err = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
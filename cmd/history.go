@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show configuration switch history",
+	Long: `Display recent configuration switches, newest first, together with any
+notes recorded with 'cc-switch use <name> --note "<text>"'.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkClaudeConfig(); err != nil {
+			return err
+		}
+
+		cm, err := config.NewConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		configHandler := handler.NewConfigHandler(cm)
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		entries, err := configHandler.GetHistory()
+		if err != nil {
+			return err
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("No switch history recorded yet. Use 'cc-switch use <name>' to switch configurations.")
+			return nil
+		}
+
+		if limit > 0 && len(entries) > limit {
+			entries = entries[:limit]
+		}
+
+		fmt.Println("Configuration switch history:")
+		for _, entry := range entries {
+			timestamp := entry.SwitchedAt.Local().Format("2006-01-02 15:04")
+			name := entry.Profile
+			if name == "empty_mode" {
+				name = "(empty mode)"
+			}
+
+			if entry.Note != "" {
+				fmt.Printf("  %s  %s  ", timestamp, name)
+				color.New(color.Faint).Printf("%s\n", entry.Note)
+			} else {
+				fmt.Printf("  %s  %s\n", timestamp, name)
+			}
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	historyCmd.Flags().IntP("limit", "n", 20, "Maximum number of entries to show (0 for all)")
+}
@@ -3,26 +3,38 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"slices"
+	"sort"
 	"strings"
 	"syscall"
 
-	"cc-switch/internal/config"
-	"cc-switch/internal/export"
-	importpkg "cc-switch/internal/import"
+	"github.com/HoBeedzc/cc-switch/internal/common"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/export"
+	importpkg "github.com/HoBeedzc/cc-switch/internal/import"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
 
+// importPasswordEnv names the environment variable holding the decryption password,
+// so scripts can import encrypted files without -p showing up in the process list
+const importPasswordEnv = "CC_SWITCH_IMPORT_PASSWORD"
+
 var (
 	importPassword string
 	importConflict string
 	importDryRun   bool
+	importShowDiff bool
+	importInsecure bool
+	importRestore  bool
+	importList     bool
+	importOnly     []string
 )
 
 var importCmd = &cobra.Command{
-	Use:   "import <file>",
+	Use:   "import <file|url>",
 	Short: "Import configurations from a backup file",
 	Long: `Import Claude Code configurations from an encrypted backup file.
 
@@ -42,6 +54,29 @@ Examples:
   # Dry run to see what would be imported
   cc-switch import backup.ccx --dry-run
 
+  # List what the file contains (profiles, conflicts, env key names) without importing
+  cc-switch import backup.ccx --list
+
+  # Import only some profiles (names or globs); combines with --list and --dry-run
+  cc-switch import backup.ccx --only 'work-*,personal'
+
+  # Review exactly what overwriting existing profiles would change
+  cc-switch import backup.ccx --conflict=overwrite --dry-run --show-diff
+
+  # Template-only archives (export --templates) only ever add templates;
+  # an incoming "default" template is always renamed
+  cc-switch import templates.ccx
+
+  # Import a backup and switch back to the profile that was active when it was made
+  cc-switch import backup.ccx --restore-current
+
+  # Import from a web server (bearer token read from $CC_SWITCH_REMOTE_TOKEN if set).
+  # The download honors HTTPS_PROXY, is limited in size and time, and must be a .ccx file
+  cc-switch import https://internal/backups/team.ccx
+
+  # Password from the environment instead of -p or a prompt
+  CC_SWITCH_IMPORT_PASSWORD=secret cc-switch import https://internal/backups/team.ccx
+
   # Interactive password input (recommended for security)
   cc-switch import backup.ccx`,
 	Args: cobra.ExactArgs(1),
@@ -52,6 +87,28 @@ Examples:
 
 		inputFile := args[0]
 
+		if err := checkFlagRules(cmd, importFlagRules); err != nil {
+			return err
+		}
+
+		if importInsecure && !common.IsRemoteURL(inputFile) {
+			return fmt.Errorf("--insecure can only be used when importing from a URL")
+		}
+
+		// Download remote backups to a temporary file, then use the normal import flow
+		if common.IsRemoteURL(inputFile) {
+			color.Cyan("🌐 Downloading %s...", inputFile)
+			downloaded, err := common.DownloadToTemp(inputFile, common.MaxRemoteDownloadSize, common.RemoteOptions{
+				Insecure: importInsecure,
+				Magic:    export.MagicNumber,
+			})
+			if err != nil {
+				return err
+			}
+			defer os.Remove(downloaded)
+			inputFile = downloaded
+		}
+
 		// Validate input file exists
 		if _, err := os.Stat(inputFile); os.IsNotExist(err) {
 			return fmt.Errorf("import file does not exist: %s", inputFile)
@@ -76,8 +133,11 @@ Examples:
 		// Show file information
 		showFileInfo(metadata)
 
-		// Get password if not provided
+		// Get password if not provided: flag, then environment, then prompt
 		password := importPassword
+		if password == "" {
+			password = os.Getenv(importPasswordEnv)
+		}
 		isEncrypted := strings.Contains(metadata.Encryption, "aes")
 
 		if isEncrypted && password == "" {
@@ -87,6 +147,15 @@ Examples:
 			}
 		}
 
+		if importList {
+			listing, err := importer.List(inputFile, password, importOnly)
+			if err != nil {
+				return err
+			}
+			printArchiveListing(listing)
+			return nil
+		}
+
 		// Validate conflict mode
 		conflictMode := importConflict
 		if conflictMode == "" {
@@ -103,6 +172,9 @@ Examples:
 			if err != nil {
 				return fmt.Errorf("failed to check conflicts: %w", err)
 			}
+			conflicts = slices.DeleteFunc(conflicts, func(conflict importpkg.ConflictInfo) bool {
+				return !conflict.Template && !importpkg.MatchesOnly(conflict.OriginalName, importOnly)
+			})
 
 			if len(conflicts) > 0 && conflictMode != "overwrite" {
 				showConflicts(conflicts, conflictMode)
@@ -117,8 +189,11 @@ Examples:
 
 		// Prepare import options
 		options := importpkg.ImportOptions{
-			ConflictMode: conflictMode,
-			DryRun:       importDryRun,
+			ConflictMode:   conflictMode,
+			DryRun:         importDryRun,
+			ShowDiff:       importShowDiff,
+			RestoreCurrent: importRestore,
+			Only:           importOnly,
 		}
 
 		// Perform import
@@ -128,22 +203,88 @@ Examples:
 			color.Cyan("📥 Importing configurations...")
 		}
 
-		result, err := importer.Import(inputFile, password, options)
+		result, err := importer.ImportWithProgress(inputFile, password, options, showImportProgress)
 		if err != nil {
+			common.FinishProgress("import", 0, 0, 0, common.ResultFailed)
 			return fmt.Errorf("import failed: %w", err)
 		}
+		finishImportProgress(result)
 
 		// Show results
 		showImportResults(result, importDryRun)
 
+		if importShowDiff {
+			showOverwriteDiffs(result, conflictMode)
+		}
+
 		return nil
 	},
 }
 
+// importFlagRules declares the flag combinations import rejects
+var importFlagRules = [][]flagRule{
+	requiresFlag("show-diff", "dry-run"),
+	conflictsWith("list", "dry-run", "conflict", "restore-current"),
+}
+
 func init() {
-	importCmd.Flags().StringVarP(&importPassword, "password", "p", "", "Decryption password (prompt if not provided)")
+	importCmd.Flags().StringVarP(&importPassword, "password", "p", "", "Decryption password (default $"+importPasswordEnv+", prompt if neither is set)")
 	importCmd.Flags().StringVar(&importConflict, "conflict", "both", "How to handle conflicts: skip, overwrite, both (default: both)")
 	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Show what would be imported without making changes")
+	importCmd.Flags().BoolVar(&importShowDiff, "show-diff", false, "With --dry-run, show the changes each overwrite would make")
+	importCmd.Flags().BoolVar(&importInsecure, "insecure", false, "Skip TLS certificate verification when importing from a URL")
+	importCmd.Flags().BoolVar(&importRestore, "restore-current", false, "Switch to the profile that was active when the file was exported")
+	importCmd.Flags().BoolVar(&importList, "list", false, "List the profiles and templates in the file without importing anything")
+	importCmd.Flags().StringSliceVar(&importOnly, "only", nil, "Only import profiles matching these comma-separated names or globs")
+}
+
+// printArchiveListing prints the contents of an import file as a table. Env values are never shown.
+func printArchiveListing(listing *importpkg.ArchiveListing) {
+	nameWidth := len("NAME")
+	for _, profile := range listing.Profiles {
+		nameWidth = max(nameWidth, len(profile.Name)+len(" (current)"))
+	}
+
+	fmt.Printf("%-*s  %-8s  %s\n", nameWidth, "NAME", "CONFLICT", "ENV KEYS")
+	for _, profile := range listing.Profiles {
+		name := profile.Name
+		if profile.IsCurrent {
+			name += " (current)"
+		}
+		conflict := "no"
+		if profile.Conflict {
+			conflict = "yes"
+		}
+		keys := strings.Join(profile.EnvKeys, ", ")
+		if keys == "" {
+			keys = "-"
+		}
+		fmt.Printf("%-*s  %-8s  %s\n", nameWidth, name, conflict, keys)
+	}
+
+	if len(listing.Templates) > 0 {
+		fmt.Println()
+		fmt.Println("Templates:")
+		for _, template := range listing.Templates {
+			if template.Conflict {
+				fmt.Printf("  %s (exists)\n", template.Name)
+			} else {
+				fmt.Printf("  %s\n", template.Name)
+			}
+		}
+	}
+	if listing.Secrets > 0 {
+		fmt.Printf("\nEmbedded secrets: %d\n", listing.Secrets)
+	}
+
+	conflicts := 0
+	for _, profile := range listing.Profiles {
+		if profile.Conflict {
+			conflicts++
+		}
+	}
+	fmt.Println()
+	color.Blue("%d profile(s), %d with the name of an existing profile. Nothing was imported.", len(listing.Profiles), conflicts)
 }
 
 func promptForDecryptionPassword() (string, error) {
@@ -162,6 +303,9 @@ func showFileInfo(metadata *export.CCXMetadata) {
 	color.Blue("   Exported: %s", metadata.ExportedAt)
 	color.Blue("   Tool: %s", metadata.ToolVersion)
 	color.Blue("   Profiles: %d", metadata.ProfilesCount)
+	if metadata.TemplatesCount > 0 {
+		color.Blue("   Templates: %d", metadata.TemplatesCount)
+	}
 	color.Blue("   Type: %s", metadata.ExportType)
 
 	if metadata.Encryption != "" {
@@ -176,7 +320,11 @@ func showFileInfo(metadata *export.CCXMetadata) {
 func showConflicts(conflicts []importpkg.ConflictInfo, conflictMode string) {
 	color.Yellow("⚠️  Naming conflicts detected:")
 	for _, conflict := range conflicts {
-		color.Yellow("   • '%s' already exists", conflict.ConflictName)
+		if conflict.Template {
+			color.Yellow("   • template '%s' already exists", conflict.ConflictName)
+		} else {
+			color.Yellow("   • '%s' already exists", conflict.ConflictName)
+		}
 	}
 	fmt.Println()
 
@@ -187,7 +335,11 @@ func showConflicts(conflicts []importpkg.ConflictInfo, conflictMode string) {
 		color.Yellow("Conflict mode: BOTH - Conflicting profiles will be renamed automatically")
 		color.Yellow("Example suggestions:")
 		for _, conflict := range conflicts {
-			color.Yellow("   • '%s' → '%s'", conflict.ConflictName, conflict.SuggestedName)
+			if conflict.Template {
+				color.Yellow("   • template '%s' → '%s'", conflict.ConflictName, conflict.SuggestedName)
+			} else {
+				color.Yellow("   • '%s' → '%s'", conflict.ConflictName, conflict.SuggestedName)
+			}
 		}
 	}
 	fmt.Println()
@@ -211,6 +363,39 @@ func confirmProceed(conflictMode string) bool {
 	return response == "y" || response == "yes"
 }
 
+// showImportProgress prints a live profile counter, overwriting the line on terminals.
+// With --progress json it emits an event per profile instead.
+func showImportProgress(progress importpkg.ProfileProgress) {
+	if common.JSONProgress() {
+		status := progress.Status
+		if status == importpkg.StatusError {
+			status = common.ProgressFailed
+		}
+		common.EmitProgress(common.ProgressEvent{Op: "import", Item: progress.Name, Done: progress.Index, Total: progress.Total, Status: status})
+		return
+	}
+
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		fmt.Printf("\r   %d/%d profiles processed", progress.Index, progress.Total)
+		if progress.Index == progress.Total {
+			fmt.Println()
+		}
+		return
+	}
+
+	fmt.Printf("   [%d/%d] %s: %s\n", progress.Index, progress.Total, progress.Name, progress.Status)
+}
+
+// finishImportProgress emits the finished event of an import
+func finishImportProgress(result *importpkg.ImportResult) {
+	summary := result.Summary
+	outcome := common.ResultOK
+	if summary.ErrorCount > 0 {
+		outcome = common.ResultFailed
+	}
+	common.FinishProgress("import", summary.TotalProfiles, summary.TotalProfiles, summary.ErrorCount, outcome)
+}
+
 func showImportResults(result *importpkg.ImportResult, isDryRun bool) {
 	summary := result.Summary
 
@@ -227,6 +412,9 @@ func showImportResults(result *importpkg.ImportResult, isDryRun bool) {
 	fmt.Println()
 	color.Blue("📊 Summary:")
 	color.Blue("   Total profiles: %d", summary.TotalProfiles)
+	if summary.TotalTemplates > 0 {
+		color.Blue("   Total templates: %d", summary.TotalTemplates)
+	}
 
 	if isDryRun {
 		color.Blue("   Would import: %d", summary.ImportedCount)
@@ -266,6 +454,36 @@ func showImportResults(result *importpkg.ImportResult, isDryRun bool) {
 		}
 	}
 
+	// Show imported templates (backups only)
+	if len(result.TemplatesImported) > 0 {
+		fmt.Println()
+		if isDryRun {
+			color.Cyan("Templates that would be imported:")
+		} else {
+			color.Green("Successfully imported templates:")
+		}
+		for _, template := range result.TemplatesImported {
+			if isDryRun {
+				color.Cyan("   • %s", template)
+			} else {
+				color.Green("   • %s", template)
+			}
+		}
+	}
+
+	// Show the restored active profile
+	if result.CurrentRestored != "" {
+		fmt.Println()
+		if isDryRun {
+			color.Cyan("Would switch to '%s' (active when the file was exported)", result.CurrentRestored)
+		} else {
+			color.Green("🔄 Switched to '%s' (active when the file was exported)", result.CurrentRestored)
+		}
+	} else if result.CurrentNotice != "" {
+		fmt.Println()
+		color.Yellow("Active profile not restored: %s", result.CurrentNotice)
+	}
+
 	// Show conflicts
 	if len(result.Conflicts) > 0 {
 		fmt.Println()
@@ -288,5 +506,40 @@ func showImportResults(result *importpkg.ImportResult, isDryRun bool) {
 		fmt.Println()
 		color.Blue("💡 Use 'cc-switch list' to see all available profiles")
 		color.Blue("💡 Use 'cc-switch use <profile>' to switch to an imported profile")
+	} else if !isDryRun && len(result.TemplatesImported) > 0 {
+		fmt.Println()
+		color.Blue("💡 Use 'cc-switch list -t' to see all available templates")
+	}
+}
+
+// showOverwriteDiffs prints the changes that overwriting existing profiles would make (secrets masked)
+func showOverwriteDiffs(result *importpkg.ImportResult, conflictMode string) {
+	fmt.Println()
+	if conflictMode != "overwrite" {
+		color.Yellow("No diffs to show: existing profiles are only overwritten with --conflict=overwrite")
+		return
+	}
+	if len(result.OverwriteDiffs) == 0 {
+		color.Cyan("No existing profiles would be overwritten")
+		return
+	}
+
+	names := make([]string, 0, len(result.OverwriteDiffs))
+	for name := range result.OverwriteDiffs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	color.Cyan("Changes that overwriting would make:")
+	for _, name := range names {
+		diffs := result.OverwriteDiffs[name]
+		if len(diffs) == 0 {
+			fmt.Printf("\n%s: no changes\n", name)
+			continue
+		}
+		fmt.Printf("\n%s: %d difference(s)\n", name, len(diffs))
+		for _, diff := range diffs {
+			printDiffEntry(diff, false)
+		}
 	}
 }
@@ -3,13 +3,12 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 
 	"github.com/spf13/cobra"
 
-	"cc-switch/internal/config"
-	"cc-switch/internal/handler"
-	"cc-switch/internal/ui"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
+	"github.com/HoBeedzc/cc-switch/internal/ui"
 )
 
 var initCmd = &cobra.Command{
@@ -58,9 +57,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 		// Only check for existing initialization if NOT in empty mode
 
 		// Check if already initialized (check for profiles directory)
-		homeDir, _ := os.UserHomeDir()
-		profilesDir := filepath.Join(homeDir, ".claude", "profiles")
-		if _, err := os.Stat(profilesDir); err == nil {
+		if _, err := os.Stat(configManager.GetProfilesDir()); err == nil {
 			// Profiles directory exists, which means cc-switch has been set up before
 			uiProvider.ShowAlreadyInitialized()
 			return nil
@@ -2,9 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
-	"cc-switch/internal/config"
-	"cc-switch/internal/handler"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
+	"github.com/HoBeedzc/cc-switch/internal/ui"
+	"github.com/HoBeedzc/cc-switch/pkg/ccswitch"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -19,9 +23,23 @@ var listCmd = &cobra.Command{
 Modes:
 - Configurations: cc-switch list (default)
 - Templates: cc-switch list -t or cc-switch list --template
+- With origins: cc-switch list -v shows where each configuration came from
+- Filtered: cc-switch list --filter 'work-*' or --filter 'tag:team'
+- With test status: cc-switch list --with-status shows the last recorded test
+  result of each configuration (✅ passed, ❌ failed, ❓ never tested) and its age,
+  read from the activity log without running a new test
 
-The current configuration is highlighted when listing configurations.`,
+The current configuration is highlighted when listing configurations.
+
+For scripts, --names-only prints one name per line and --paths one absolute
+file path per line, with no colors, markers or headers. Nothing is printed
+when there is nothing to list:
+
+  for p in $(cc-switch list --names-only); do cc-switch test "$p"; done`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkFlagRules(cmd, listFlagRules); err != nil {
+			return err
+		}
 		if err := checkClaudeConfig(); err != nil {
 			return err
 		}
@@ -35,34 +53,103 @@ The current configuration is highlighted when listing configurations.`,
 
 		// Check for template flag
 		template, _ := cmd.Flags().GetBool("template")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		namesOnly, _ := cmd.Flags().GetBool("names-only")
+		paths, _ := cmd.Flags().GetBool("paths")
+		filter, _ := cmd.Flags().GetString("filter")
+		withStatus, _ := cmd.Flags().GetBool("with-status")
 
 		// Handle template listing
 		if template {
+			if namesOnly || paths {
+				return printRawTemplates(cm, paths)
+			}
 			return executeListTemplates(configHandler)
 		}
 
-		// Check if in empty mode first
-		if configHandler.IsEmptyMode() {
-			color.Yellow("⚠️  Empty mode active (no configuration active)")
-			fmt.Println()
+		manager := ccswitch.FromConfigManager(cm)
+		var profiles []config.Profile
+		if filter != "" {
+			profiles, err = manager.ListFiltered(cmd.Context(), filter)
+		} else {
+			profiles, err = manager.List(cmd.Context())
 		}
-
-		profiles, err := cm.ListProfiles()
 		if err != nil {
 			return fmt.Errorf("failed to list profiles: %w", err)
 		}
 
+		if namesOnly || paths {
+			for _, profile := range profiles {
+				if paths {
+					fmt.Println(profile.Path)
+				} else {
+					fmt.Println(profile.Name)
+				}
+			}
+			return nil
+		}
+
+		if withStatus {
+			if err := cm.AttachLastTests(profiles); err != nil {
+				color.Yellow("Warning: %v", err)
+			}
+		}
+
+		// Check if in empty mode first
+		if configHandler.IsEmptyMode() {
+			color.Yellow("%s  Empty mode active (no configuration active)", ui.Sym().Warning)
+			fmt.Println()
+		}
+
 		if len(profiles) == 0 {
+			if filter != "" {
+				fmt.Printf("No configurations match filter '%s'.\n", filter)
+				return nil
+			}
 			fmt.Println("No configurations found. Use 'cc-switch new <name>' to create your first configuration.")
 			return nil
 		}
 
+		indexes, err := profileIndexes(cm, profiles, filter != "")
+		if err != nil {
+			return fmt.Errorf("failed to list profiles: %w", err)
+		}
+		indexWidth := len(fmt.Sprint(len(indexes)))
+
 		fmt.Println("Available configurations:")
 		for _, profile := range profiles {
-			if profile.IsCurrent && !configHandler.IsEmptyMode() {
-				color.Green("  * %s (current)", profile.Name)
+			index := fmt.Sprintf("%*d.", indexWidth, indexes[profile.Name])
+			suffix := ""
+			if profile.DisplayName != "" {
+				suffix = fmt.Sprintf("  — %s", profile.DisplayName)
+			}
+			if profile.ReadOnly {
+				suffix += " [system, read-only]"
+			}
+			if profile.Snapshot {
+				suffix += " [snapshot, read-only]"
+			}
+			if profile.Scratch {
+				suffix += " [scratch]"
+			}
+			if len(profile.Aliases) > 0 {
+				suffix += fmt.Sprintf(" [alias: %s]", strings.Join(profile.Aliases, ", "))
+			}
+			if profile.Project != "" {
+				suffix += fmt.Sprintf(" [project: %s]", profile.Project)
+			}
+			if withStatus {
+				suffix += "  " + formatLastTest(profile.LastTest)
+			}
+			if verbose {
+				suffix += fmt.Sprintf("  (origin: %s)", strings.Join(cm.ProfileOriginChain(profile.Name), " "+ui.Sym().LeftArrow+" "))
+			}
+			if profile.Error != "" {
+				color.New(color.Faint).Printf("    %s %s%s (unreadable: %s)\n", index, profile.Name, suffix, profile.Error)
+			} else if profile.IsCurrent && !configHandler.IsEmptyMode() {
+				color.Green("  * %s %s (current)%s", index, profile.Name, suffix)
 			} else {
-				fmt.Printf("    %s\n", profile.Name)
+				fmt.Printf("    %s %s%s\n", index, profile.Name, suffix)
 			}
 		}
 
@@ -75,6 +162,23 @@ The current configuration is highlighted when listing configurations.`,
 	},
 }
 
+// profileIndexes returns the 1-based position of every configuration in the unfiltered
+// list, which is what 'cc-switch use <index>' selects by
+func profileIndexes(cm *config.ConfigManager, profiles []config.Profile, filtered bool) (map[string]int, error) {
+	if filtered {
+		all, err := cm.ListProfiles()
+		if err != nil {
+			return nil, err
+		}
+		profiles = all
+	}
+	indexes := make(map[string]int, len(profiles))
+	for i, profile := range profiles {
+		indexes[profile.Name] = i + 1
+	}
+	return indexes, nil
+}
+
 // executeListTemplates handles listing templates
 func executeListTemplates(configHandler handler.ConfigHandler) error {
 	templates, err := configHandler.ListTemplates()
@@ -87,18 +191,75 @@ func executeListTemplates(configHandler handler.ConfigHandler) error {
 		return nil
 	}
 
+	// Mark the template "cc-switch new" uses when no --template is given
+	effective, warning := configHandler.ResolveTemplate("")
+
 	fmt.Println("Available templates:")
 	for _, template := range templates {
+		var notes []string
 		if template == "default" {
-			fmt.Printf("  %s (system default)\n", template)
+			notes = append(notes, "system default")
+		}
+		if template == effective {
+			notes = append(notes, "default for new")
+		}
+		if len(notes) > 0 {
+			fmt.Printf("  %s (%s)\n", template, strings.Join(notes, ", "))
 		} else {
 			fmt.Printf("  %s\n", template)
 		}
 	}
+	if warning != "" {
+		color.Yellow("Warning: %s", warning)
+	}
 
 	return nil
 }
 
+// printRawTemplates prints one template name (or absolute path) per line for scripts
+func printRawTemplates(cm *config.ConfigManager, paths bool) error {
+	templates, err := cm.ListTemplates()
+	if err != nil {
+		return fmt.Errorf("failed to list templates: %w", err)
+	}
+	for _, template := range templates {
+		if !paths {
+			fmt.Println(template)
+			continue
+		}
+		path, err := cm.TemplatePath(template)
+		if err != nil {
+			return err
+		}
+		fmt.Println(path)
+	}
+	return nil
+}
+
+// formatLastTest renders the last recorded test result as a badge, e.g. "✅ 2 h ago"
+func formatLastTest(lastTest *config.LastTest) string {
+	if lastTest == nil {
+		return ui.Sym().Unknown + " never tested"
+	}
+	symbol := ui.Sym().Passed
+	if lastTest.Result != "ok" {
+		symbol = ui.Sym().Failed
+	}
+	return fmt.Sprintf("%s %s ago", symbol, formatAge(time.Since(lastTest.TestedAt)))
+}
+
+// listFlagRules declares the flag combinations list rejects
+var listFlagRules = [][]flagRule{
+	exclusiveFlags("names-only", "paths", "verbose"),
+	conflictsWith("filter", "template"),
+	conflictsWith("with-status", "template", "names-only", "paths"),
+}
+
 func init() {
 	listCmd.Flags().BoolP("template", "t", false, "List templates instead of configurations")
+	listCmd.Flags().BoolP("verbose", "v", false, "Show where each configuration came from (template, import, copy, ...)")
+	listCmd.Flags().Bool("names-only", false, "Print only names, one per line, for scripts")
+	listCmd.Flags().Bool("paths", false, "Print only absolute file paths, one per line, for scripts")
+	listCmd.Flags().Bool("with-status", false, "Show each configuration's last recorded test result and its age")
+	listCmd.Flags().StringP("filter", "f", "", "Only list configurations matching a glob (e.g. 'work-*') or 'tag:<tag>'")
 }
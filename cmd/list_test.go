@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/ui"
+)
+
+func TestFormatLastTestSymbols(t *testing.T) {
+	t.Cleanup(func() { ui.SetASCII(false) })
+	recent := time.Now().Add(-2 * time.Hour)
+
+	tests := []struct {
+		name     string
+		lastTest *config.LastTest
+		ascii    bool
+		want     string
+	}{
+		{"never tested", nil, false, "❓ never tested"},
+		{"never tested ascii", nil, true, "[?] never tested"},
+		{"passed", &config.LastTest{Result: "ok", TestedAt: recent}, false, "✅ " + formatAge(time.Since(recent)) + " ago"},
+		{"passed ascii", &config.LastTest{Result: "ok", TestedAt: recent}, true, "[ok] " + formatAge(time.Since(recent)) + " ago"},
+		{"failed ascii", &config.LastTest{Result: "failed", TestedAt: recent}, true, "[x] " + formatAge(time.Since(recent)) + " ago"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ui.SetASCII(tt.ascii)
+			if got := formatLastTest(tt.lastTest); got != tt.want {
+				t.Errorf("formatLastTest = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var logCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show the activity log",
+	Long: `Display the activity log, newest first: configuration switches (with notes),
+empty mode transitions, imports and test runs.
+
+The log is stored as JSON lines in ~/.claude/profiles/.activity.log and rotated
+to .activity.log.1 when it grows large.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkClaudeConfig(); err != nil {
+			return err
+		}
+
+		cm, err := config.NewConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		entries, err := cm.ReadActivity(limit)
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			if entries == nil {
+				entries = []config.ActivityEntry{}
+			}
+			jsonData, err := json.MarshalIndent(entries, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to format JSON output: %w", err)
+			}
+			fmt.Println(string(jsonData))
+			return nil
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("No activity recorded yet.")
+			return nil
+		}
+
+		for _, entry := range entries {
+			timestamp := entry.Time.Local().Format("2006-01-02 15:04:05")
+			fmt.Printf("  %s  %-15s %s", timestamp, entry.Action, entry.Profile)
+
+			var extra []string
+			if entry.Result != "" {
+				extra = append(extra, entry.Result)
+			}
+			if entry.Detail != "" {
+				extra = append(extra, entry.Detail)
+			}
+			if entry.Note != "" {
+				extra = append(extra, fmt.Sprintf("%q", entry.Note))
+			}
+			if len(extra) > 0 {
+				fmt.Print("  ")
+				if entry.Result == "failed" {
+					color.New(color.FgRed).Print(strings.Join(extra, "  "))
+				} else {
+					color.New(color.Faint).Print(strings.Join(extra, "  "))
+				}
+			}
+			fmt.Println()
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	logCmd.Flags().IntP("limit", "n", 20, "Maximum number of entries to show (0 for all)")
+	logCmd.Flags().Bool("json", false, "Output entries as JSON")
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/ui"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var migratePermissionsCmd = &cobra.Command{
+	Use:   "migrate-permissions [name]",
+	Short: "Rewrite permission rules that use renamed Claude Code tools",
+	Long: `Scan permissions.allow and permissions.deny for rules that refer to tools
+Claude Code has since renamed (for example View is now Read). Such rules no
+longer match anything, so the permission they grant or deny is silently lost.
+
+The obsolete rules are listed and, after confirmation, rewritten with the new
+tool name. Each change is recorded in the activity log ('cc-switch log').
+'cc-switch doctor' reports the same rules as warnings.`,
+	Example: `  cc-switch migrate-permissions work
+  cc-switch migrate-permissions --all
+  cc-switch migrate-permissions --all --dry-run
+  cc-switch migrate-permissions --all -y`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		if err := checkFlagRules(cmd, migratePermissionsFlagRules); err != nil {
+			return err
+		}
+		if all == (len(args) == 1) {
+			return fmt.Errorf("specify a configuration name or --all")
+		}
+		if err := checkClaudeConfig(); err != nil {
+			return err
+		}
+
+		cm, err := config.NewConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		var names []string
+		if all {
+			profiles, err := cm.ListProfiles()
+			if err != nil {
+				return fmt.Errorf("failed to list profiles: %w", err)
+			}
+			for _, profile := range profiles {
+				// Read-only profiles cannot be rewritten; their owners have to update them
+				if profile.Error == "" && !profile.ReadOnly && !profile.Snapshot {
+					names = append(names, profile.Name)
+				}
+			}
+		} else {
+			if err := cm.CheckProfileModifiable(args[0]); err != nil {
+				return err
+			}
+			names = args
+		}
+
+		pending := make(map[string][]config.PermissionMigration)
+		var affected []string
+		for _, name := range names {
+			content, _, err := cm.GetProfileContent(name)
+			if err != nil {
+				return err
+			}
+			if migrations := config.FindObsoletePermissions(content); len(migrations) > 0 {
+				pending[name] = migrations
+				affected = append(affected, name)
+			}
+		}
+
+		if len(affected) == 0 {
+			color.Green("✓ No obsolete permission rules found")
+			return nil
+		}
+
+		total := 0
+		for _, name := range affected {
+			fmt.Printf("%s:\n", name)
+			for _, migration := range pending[name] {
+				total++
+				fmt.Printf("  %s: %s → %s\n", migration.Path, color.RedString(migration.Old), color.GreenString(migration.New))
+			}
+		}
+		fmt.Println()
+
+		if dryRun {
+			fmt.Printf("%d rule(s) in %d configuration(s) would be rewritten (dry run)\n", total, len(affected))
+			return nil
+		}
+		if !yes && !ui.NewCLIUI().ConfirmAction(fmt.Sprintf("Rewrite %d rule(s) in %d configuration(s)?", total, len(affected)), false) {
+			fmt.Println(ui.Text("common.cancelled"))
+			return nil
+		}
+
+		failed := 0
+		for _, name := range affected {
+			migrations, err := cm.MigrateProfilePermissions(name)
+			if err != nil {
+				failed++
+				color.Red("  ✗ %s: %v", name, err)
+				continue
+			}
+			color.Green("  ✓ %s: %d rule(s) rewritten", name, len(migrations))
+		}
+		if failed > 0 {
+			return errSilentFailure
+		}
+		return nil
+	},
+}
+
+// migratePermissionsFlagRules declares the flag combinations migrate-permissions rejects
+var migratePermissionsFlagRules = [][]flagRule{
+	conflictsWith("dry-run", "yes"),
+}
+
+func init() {
+	migratePermissionsCmd.Flags().BoolP("all", "a", false, "Check every configuration")
+	migratePermissionsCmd.Flags().Bool("dry-run", false, "Only list the rules that would be rewritten")
+	migratePermissionsCmd.Flags().BoolP("yes", "y", false, "Rewrite without asking for confirmation")
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMigratePermissionsCommand(t *testing.T) {
+	setupHome(t)
+	cm := newTestManager(t)
+	for _, name := range []string{"work", "home"} {
+		content := map[string]interface{}{"permissions": map[string]interface{}{"allow": []interface{}{"View"}}}
+		if err := cm.CreateProfileWithContent(name, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cm.UseProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	allow := func(name string) interface{} {
+		t.Helper()
+		content, _, err := cm.GetProfileContent(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return content["permissions"].(map[string]interface{})["allow"]
+	}
+
+	if err := runCommand(t, "migrate-permissions", "--all", "--dry-run"); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if got := allow("work"); !reflect.DeepEqual(got, []interface{}{"View"}) {
+		t.Errorf("work allow after dry run = %v, want it unchanged", got)
+	}
+
+	if err := runCommand(t, "migrate-permissions", "home", "-y"); err != nil {
+		t.Fatalf("migrate home: %v", err)
+	}
+	if got := allow("home"); !reflect.DeepEqual(got, []interface{}{"Read"}) {
+		t.Errorf("home allow = %v, want [Read]", got)
+	}
+	if got := allow("work"); !reflect.DeepEqual(got, []interface{}{"View"}) {
+		t.Errorf("work allow = %v, want only the named profile migrated", got)
+	}
+
+	if err := runCommand(t, "migrate-permissions", "--all", "-y"); err != nil {
+		t.Fatalf("migrate all: %v", err)
+	}
+	if got := allow("work"); !reflect.DeepEqual(got, []interface{}{"Read"}) {
+		t.Errorf("work allow = %v, want [Read]", got)
+	}
+
+	for _, args := range [][]string{{"migrate-permissions"}, {"migrate-permissions", "work", "--all"}} {
+		if err := runCommand(t, args...); err == nil {
+			t.Errorf("%v succeeded, want a name or --all required", args)
+		}
+	}
+}
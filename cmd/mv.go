@@ -3,9 +3,9 @@ package cmd
 import (
 	"fmt"
 
-	"cc-switch/internal/config"
-	"cc-switch/internal/handler"
-	"cc-switch/internal/ui"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
+	"github.com/HoBeedzc/cc-switch/internal/ui"
 
 	"github.com/spf13/cobra"
 )
@@ -53,7 +53,7 @@ The interactive mode allows you to browse and select configurations/templates wi
 			return executeMoveTemplate(configHandler, uiProvider, args)
 		}
 
-		return executeMove(configHandler, uiProvider, args)
+		return executeMove(configHandler, uiProvider, resolveAliasArg(cm, args))
 	},
 }
 
@@ -150,17 +150,11 @@ func executeMoveTemplate(configHandler handler.ConfigHandler, uiProvider ui.UIPr
 			return nil
 		}
 
-		fmt.Println("Available templates for renaming:")
-		for i, template := range movableTemplates {
-			fmt.Printf("  %d) %s\n", i+1, template)
-		}
-
-		fmt.Printf("Select template to rename (1-%d): ", len(movableTemplates))
-		var selection int
-		if _, err := fmt.Scanln(&selection); err != nil || selection < 1 || selection > len(movableTemplates) {
-			return fmt.Errorf("invalid selection")
+		selection, err := ui.SelectFromMenu("Available templates for renaming:", ui.TemplateMenuItems(movableTemplates), "Select template to rename")
+		if err != nil {
+			return fmt.Errorf("selection cancelled: %w", err)
 		}
-		oldName = movableTemplates[selection-1]
+		oldName = movableTemplates[selection]
 
 		// Get new name interactively
 		newName, err = uiProvider.GetInput(fmt.Sprintf("Enter new name for template '%s'", oldName), "")
@@ -198,7 +192,7 @@ func executeMoveTemplate(configHandler handler.ConfigHandler, uiProvider ui.UIPr
 
 	// Validate source template exists
 	if err := configHandler.ValidateTemplateExists(oldName); err != nil {
-		return fmt.Errorf("template '%s' does not exist", oldName)
+		return config.NotFoundf("template '%s' does not exist", oldName)
 	}
 
 	// Execute move
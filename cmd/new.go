@@ -2,9 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
-	"cc-switch/internal/config"
-	"cc-switch/internal/ui"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/ui"
+	"github.com/HoBeedzc/cc-switch/pkg/ccswitch"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -13,24 +15,52 @@ import (
 var (
 	newTemplate    string
 	newInteractive bool
+	newReviewAll   bool
 	newUse         bool
+	newManifest    string
+	newProvider    string
 )
 
 var newCmd = &cobra.Command{
-	Use:   "new <name>",
+	Use:   "new <name> | new --manifest <file>",
 	Short: "Create a new configuration",
 	Long: `Create a new configuration with template structure ready for customization.
 
 You can specify a template to use when creating the configuration:
-- Default template: cc-switch new <name>
+- Default template: cc-switch new <name> (the default_template setting, or "default")
 - Specific template: cc-switch new <name> -t <template> or cc-switch new <name> --template <template>
 - Interactive mode: cc-switch new <name> -i or cc-switch new <name> --interactive
 - Auto switch after creation: cc-switch new <name> -u or cc-switch new <name> --use
 
 In interactive mode, cc-switch will prompt you to fill in any empty fields in the template.
+With --interactive-all every string field is prompted, pre-filled with the template's
+value, so you can review or override the whole configuration; press Enter to keep a value.
 If the specified template does not exist, the default template will be used.
-Use --use to automatically switch to the newly created configuration after creation.`,
+Change the default with 'cc-switch config set default_template <template>'.
+Use --use to automatically switch to the newly created configuration after creation.
+
+Provider presets fill in the base URL and extra settings of common gateways:
+- cc-switch new <name> --provider litellm -i
+- List them with 'cc-switch providers list'
+The preset is merged over the template before fields are prompted for; without -i the
+keys you still need to fill in are listed. 'cc-switch test' sends the API key the way
+the provider expects.
+
+Batch creation from a manifest:
+- JSON: an array of {"name", "template", "values": {"env.ANTHROPIC_AUTH_TOKEN": "..."}} entries
+- CSV:  a header row with name,template,token,base_url (other columns are field paths)
+- cc-switch new --manifest team.json
+- cc-switch new --manifest team.csv --dry-run
+
+Each entry is created independently; failures are reported in the summary table
+without stopping the remaining entries. Duplicate names in the manifest are rejected upfront.`,
 	Args: func(cmd *cobra.Command, args []string) error {
+		if newManifest != "" {
+			if len(args) > 0 {
+				return fmt.Errorf("cannot use a configuration name together with --manifest")
+			}
+			return nil
+		}
 		if len(args) < 1 {
 			return fmt.Errorf("missing required argument: configuration name")
 		}
@@ -40,38 +70,52 @@ Use --use to automatically switch to the newly created configuration after creat
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		name := args[0]
-
 		if err := checkClaudeConfig(); err != nil {
 			return err
 		}
 
+		if newManifest != "" {
+			if newInteractive || newReviewAll || newUse || newTemplate != "" || newProvider != "" {
+				return fmt.Errorf("--manifest cannot be combined with --template, --provider, --interactive, --interactive-all or --use")
+			}
+			return runManifest(newManifest, config.DryRun())
+		}
+
+		name := args[0]
+
 		cm, err := config.NewConfigManager()
 		if err != nil {
 			return fmt.Errorf("failed to initialize config manager: %w", err)
 		}
+		manager := ccswitch.FromConfigManager(cm)
 
 		// 检查配置是否已存在
 		if cm.ProfileExists(name) {
-			return fmt.Errorf("configuration '%s' already exists", name)
+			return config.Conflictf("configuration '%s' already exists", name)
 		}
 
-		// 获取模板名称
+		// 获取模板名称：--template > default_template 设置 > default
 		templateName, _ := cmd.Flags().GetString("template")
-		if templateName == "" {
-			templateName = "default"
+		templateName, warning := cm.ResolveTemplate(templateName)
+		if warning != "" {
+			color.Yellow("Warning: %s", warning)
 		}
 
-		// 检查模板是否存在
-		if !cm.TemplateExists(templateName) {
-			if templateName != "default" {
-				color.Yellow("Warning: template '%s' not found, using default template", templateName)
-				templateName = "default"
-			}
+		// --interactive-all 隐含交互模式
+		if newReviewAll {
+			newInteractive = true
 		}
 
-		// 根据是否启用交互模式选择创建方法
-		if newInteractive {
+		// 根据是否指定提供商预设、是否启用交互模式选择创建方法
+		if newProvider != "" {
+			var uiProvider ui.UIProvider
+			if newInteractive {
+				uiProvider = ui.NewInteractiveUI()
+			}
+			if err := cm.CreateProfileFromProvider(name, templateName, newProvider, uiProvider, newReviewAll); err != nil {
+				return err
+			}
+		} else if newInteractive {
 			// 初始化UI提供者
 			var uiProvider ui.UIProvider
 			if isInteractiveMode() {
@@ -81,18 +125,29 @@ Use --use to automatically switch to the newly created configuration after creat
 			}
 
 			// 使用交互式创建
-			if err := cm.CreateProfileFromTemplateInteractive(name, templateName, uiProvider); err != nil {
+			create := cm.CreateProfileFromTemplateInteractive
+			if newReviewAll {
+				create = cm.CreateProfileFromTemplateInteractiveAll
+			}
+			if err := create(name, templateName, uiProvider); err != nil {
 				return err
 			}
 		} else {
 			// 使用传统创建方法
-			if err := cm.CreateProfileFromTemplate(name, templateName); err != nil {
+			if err := manager.Create(cmd.Context(), name, ccswitch.CreateOptions{Template: templateName}); err != nil {
 				return err
 			}
 		}
 
-		color.Green("✓ Configuration '%s' created successfully from template '%s'", name, templateName)
-		if newInteractive {
+		if newProvider != "" {
+			color.Green("✓ Configuration '%s' created successfully from template '%s' with provider '%s'", name, templateName, newProvider)
+		} else {
+			color.Green("✓ Configuration '%s' created successfully from template '%s'", name, templateName)
+		}
+		if missing := missingProviderKeys(cm, name, newProvider); len(missing) > 0 {
+			color.Yellow("Still to fill in: %s", strings.Join(missing, ", "))
+			fmt.Printf("Use 'cc-switch edit %s' to set them.\n", name)
+		} else if newInteractive {
 			fmt.Printf("All template fields have been filled with your input.\n")
 		} else {
 			fmt.Printf("Use 'cc-switch edit %s' to customize the configuration.\n", name)
@@ -100,7 +155,7 @@ Use --use to automatically switch to the newly created configuration after creat
 
 		// 如果指定了 --use，则创建后立即切换到新配置
 		if newUse {
-			if err := cm.UseProfile(name); err != nil {
+			if _, err := manager.Use(cmd.Context(), name, ccswitch.UseOptions{Verify: config.VerifySwitch()}); err != nil {
 				return fmt.Errorf("failed to switch to new configuration: %w", err)
 			}
 			color.Green("✓ Switched to configuration '%s'", name)
@@ -112,6 +167,76 @@ Use --use to automatically switch to the newly created configuration after creat
 	},
 }
 
+// runManifest creates every profile listed in a manifest file and prints a summary table
+func runManifest(path string, dryRun bool) error {
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	entries, err := config.LoadManifest(path)
+	if err != nil {
+		return err
+	}
+
+	results := cm.CreateProfilesFromManifest(entries, dryRun)
+
+	fmt.Printf("%-20s %-15s %s\n", "NAME", "TEMPLATE", "RESULT")
+	failed := 0
+	for _, result := range results {
+		switch {
+		case result.Error != "":
+			failed++
+			fmt.Printf("%-20s %-15s %s\n", result.Name, result.Template, color.RedString("✗ %s", result.Error))
+		case dryRun:
+			fmt.Printf("%-20s %-15s %s\n", result.Name, result.Template, color.CyanString("✓ ok (dry run)"))
+		default:
+			fmt.Printf("%-20s %-15s %s\n", result.Name, result.Template, color.GreenString("✓ created"))
+		}
+	}
+	fmt.Println()
+
+	succeeded := len(results) - failed
+	if dryRun {
+		if failed > 0 {
+			return fmt.Errorf("dry run: %d of %d entries would fail", failed, len(results))
+		}
+		color.Cyan("Dry run: all %d entries are valid, nothing was created", len(results))
+		return nil
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("created %d of %d configurations, %d failed", succeeded, len(results), failed)
+	}
+	color.Green("✓ Created %d configurations", succeeded)
+	return nil
+}
+
+// missingProviderKeys lists the env keys the provider preset requires that are still
+// empty in the new configuration
+func missingProviderKeys(cm *config.ConfigManager, name, provider string) []string {
+	if provider == "" {
+		return nil
+	}
+	preset, err := cm.GetProvider(provider)
+	if err != nil {
+		return nil
+	}
+	content, _, err := cm.GetProfileContent(name)
+	if err != nil {
+		return nil
+	}
+	env, _ := content["env"].(map[string]interface{})
+
+	var missing []string
+	for _, key := range preset.RequiredEnv {
+		if value, _ := env[key].(string); value == "" {
+			missing = append(missing, "env."+key)
+		}
+	}
+	return missing
+}
+
 // isInteractiveMode checks if we should use interactive UI
 func isInteractiveMode() bool {
 	// Check if we're in a TTY and interactive flag is set
@@ -119,7 +244,12 @@ func isInteractiveMode() bool {
 }
 
 func init() {
-	newCmd.Flags().StringVarP(&newTemplate, "template", "t", "", "Template to use for new configuration (default: default)")
+	newCmd.Flags().StringVarP(&newTemplate, "template", "t", "", "Template to use for new configuration (default: the default_template setting, or default)")
 	newCmd.Flags().BoolVarP(&newInteractive, "interactive", "i", false, "Interactive template field input mode")
+	newCmd.Flags().BoolVar(&newReviewAll, "interactive-all", false, "Prompt for every template field, pre-filled with its current value")
 	newCmd.Flags().BoolVarP(&newUse, "use", "u", false, "Switch to the new configuration after creation")
+	newCmd.Flags().StringVar(&newManifest, "manifest", "", "Create configurations in bulk from a JSON or CSV manifest")
+	newCmd.Flags().StringVar(&newProvider, "provider", "", "Merge a provider preset over the template (see 'cc-switch providers list')")
+	newCmd.RegisterFlagCompletionFunc("provider", completeProviders)
+	newCmd.RegisterFlagCompletionFunc("template", completeTemplateNames)
 }
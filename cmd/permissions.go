@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var permissionsFromTemplate string
+
+// permissionsActions are the actions accepted after the configuration name
+var permissionsActions = []string{"list", "add-allow", "add-deny", "remove"}
+
+var permissionsCmd = &cobra.Command{
+	Use:   "permissions <name> [list|add-allow|add-deny|remove] [rule...]",
+	Short: "List or change the allow/deny permission rules of a configuration",
+	Long: `List or change the permissions.allow and permissions.deny rules of a configuration
+without editing its JSON.
+
+Actions:
+- list (default): show the allow and deny rules
+- add-allow <rule...>: add rules to permissions.allow; rules already present are skipped
+- add-deny <rule...>: add rules to permissions.deny; rules already present are skipped
+- remove <rule...>: remove rules from both lists
+
+Rules are checked before they are saved: they cannot be empty, must start with a
+tool name and must have balanced parentheses. With --from-template the allow and
+deny lists are replaced by the template's. Changes to the current configuration
+are applied to settings.json immediately.
+
+Examples:
+  cc-switch permissions work
+  cc-switch permissions work add-allow "Bash(git *)" "Read(~/src/**)"
+  cc-switch permissions work add-deny "Bash(rm -rf *)"
+  cc-switch permissions work remove "Bash(git *)"
+  cc-switch permissions work --from-template team`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completePermissionsArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkClaudeConfig(); err != nil {
+			return err
+		}
+
+		cm, err := config.NewConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		name := cm.ResolveProfileName(args[0])
+		if !cm.ProfileExists(name) {
+			return config.NotFoundf("configuration '%s' does not exist", args[0])
+		}
+
+		action := "list"
+		if len(args) > 1 {
+			action = args[1]
+		}
+		rules := args[min(len(args), 2):]
+
+		if permissionsFromTemplate != "" {
+			if len(args) > 1 {
+				return fmt.Errorf("--from-template cannot be combined with an action")
+			}
+			perms, err := cm.CopyTemplatePermissions(name, permissionsFromTemplate)
+			if err != nil {
+				return err
+			}
+			color.Green("✓ Copied permissions from template '%s' to '%s' (%d allow, %d deny)", permissionsFromTemplate, name, len(perms.Allow), len(perms.Deny))
+			return nil
+		}
+
+		switch action {
+		case "list":
+			if len(rules) > 0 {
+				return fmt.Errorf("list does not take rules")
+			}
+			perms, err := cm.GetProfilePermissions(name)
+			if err != nil {
+				return err
+			}
+			printPermissionRules("allow", perms.Allow)
+			printPermissionRules("deny", perms.Deny)
+			return nil
+		case "add-allow", "add-deny":
+			list := strings.TrimPrefix(action, "add-")
+			return runPermissionChange(rules, func(rule string) error {
+				added, err := cm.AddProfilePermission(name, list, rule)
+				if err != nil {
+					return err
+				}
+				if added {
+					color.Green("✓ Added '%s' to %s", rule, list)
+				} else {
+					fmt.Printf("'%s' is already in %s\n", rule, list)
+				}
+				return nil
+			})
+		case "remove":
+			return runPermissionChange(rules, func(rule string) error {
+				lists, err := cm.RemoveProfilePermission(name, rule)
+				if err != nil {
+					return err
+				}
+				color.Green("✓ Removed '%s' from %s", rule, strings.Join(lists, " and "))
+				return nil
+			})
+		default:
+			return config.Invalidf("unknown action '%s' (use %s)", action, strings.Join(permissionsActions, ", "))
+		}
+	},
+}
+
+// runPermissionChange applies change to every rule, reporting failures and continuing with the rest
+func runPermissionChange(rules []string, change func(rule string) error) error {
+	if len(rules) == 0 {
+		return fmt.Errorf("specify at least one rule")
+	}
+
+	failed := 0
+	for _, rule := range rules {
+		if err := change(rule); err != nil {
+			color.Red("✗ %v", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return errSilentFailure
+	}
+	return nil
+}
+
+// printPermissionRules prints one permission list with a heading
+func printPermissionRules(list string, rules []string) {
+	fmt.Printf("%s:\n", list)
+	if len(rules) == 0 {
+		fmt.Println("  (none)")
+		return
+	}
+	for _, rule := range rules {
+		fmt.Printf("  %s\n", rule)
+	}
+}
+
+// completePermissionsArgs completes the configuration name and then the action
+func completePermissionsArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return completeProfileNames(cmd, args, toComplete)
+	case 1:
+		return filterCompletions(permissionsActions, toComplete), cobra.ShellCompDirectiveNoFileComp
+	default:
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func init() {
+	permissionsCmd.Flags().StringVar(&permissionsFromTemplate, "from-template", "", "Replace the allow and deny rules with those of a template")
+	permissionsCmd.RegisterFlagCompletionFunc("from-template", completeTemplateNames)
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var providersVerbose bool
+
+var providersCmd = &cobra.Command{
+	Use:   "providers",
+	Short: "List provider presets for common Anthropic-compatible gateways",
+	Long: `Provider presets hold the base URL, required keys and extra settings of common
+gateways. Use one when creating a configuration:
+
+  cc-switch new work --provider litellm -i
+
+Built-in presets can be overridden, and new ones added, in
+~/.claude/profiles/.providers.json, which maps preset names to objects with the
+fields description, base_url, required_env, env, headers and auth_style
+(bearer or x-api-key):
+
+  {
+    "company": {
+      "description": "Company gateway",
+      "base_url": "https://llm.internal.example.com",
+      "required_env": ["ANTHROPIC_AUTH_TOKEN"],
+      "headers": {"X-Team": "platform"}
+    }
+  }
+
+Examples:
+  cc-switch providers list
+  cc-switch providers list -v`,
+}
+
+var providersListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List provider presets",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cm, err := config.NewConfigManagerNoInit()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		presets, err := cm.ListProviders()
+		if err != nil {
+			return err
+		}
+
+		for _, preset := range presets {
+			source := ""
+			if preset.Source == config.ProviderUser {
+				source = color.CyanString(" [%s]", cm.ProvidersPath())
+			}
+			fmt.Printf("%-16s %s%s\n", preset.Name, preset.Description, source)
+			if !providersVerbose {
+				continue
+			}
+
+			if preset.BaseURL != "" {
+				fmt.Printf("%-16s base URL: %s\n", "", preset.BaseURL)
+			}
+			if len(preset.RequiredEnv) > 0 {
+				fmt.Printf("%-16s required: %s\n", "", strings.Join(preset.RequiredEnv, ", "))
+			}
+			if len(preset.Env) > 0 {
+				fmt.Printf("%-16s env: %s\n", "", strings.Join(sortedKeys(preset.Env), ", "))
+			}
+			if len(preset.Headers) > 0 {
+				fmt.Printf("%-16s headers: %s\n", "", strings.Join(sortedKeys(preset.Headers), ", "))
+			}
+			fmt.Printf("%-16s auth: %s\n", "", preset.AuthStyle)
+		}
+		return nil
+	},
+}
+
+// sortedKeys returns the keys of a string map in order
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// completeProviders completes provider preset names
+func completeProviders(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cm, err := config.NewConfigManagerNoInit()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	presets, err := cm.ListProviders()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names := make([]string, 0, len(presets))
+	for _, preset := range presets {
+		names = append(names, preset.Name)
+	}
+	return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	providersListCmd.Flags().BoolVarP(&providersVerbose, "verbose", "v", false, "Show base URL, required keys, extra settings and auth style")
+	providersCmd.AddCommand(providersListCmd)
+}
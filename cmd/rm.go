@@ -1,11 +1,14 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
-	"cc-switch/internal/config"
-	"cc-switch/internal/handler"
-	"cc-switch/internal/ui"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
+	"github.com/HoBeedzc/cc-switch/internal/ui"
+	"github.com/HoBeedzc/cc-switch/pkg/ccswitch"
 
 	"github.com/spf13/cobra"
 )
@@ -30,6 +33,7 @@ Enhanced Options:
 - -t, --template: Delete template instead of configuration
 - -y, --yes: Skip confirmation prompts (cannot use with --all)
 - -f, --force: Legacy force flag (same as --yes, cannot use with --all)
+- --detach: With -t, delete a template even if configurations were created from it
 
 The interactive mode allows you to browse and select configurations/templates with arrow keys.
 Note: The default template cannot be deleted for system safety.`,
@@ -54,15 +58,19 @@ Note: The default template cannot be deleted for system safety.`,
 		current, _ := cmd.Flags().GetBool("current")
 		yes, _ := cmd.Flags().GetBool("yes")
 		template, _ := cmd.Flags().GetBool("template")
+		detach, _ := cmd.Flags().GetBool("detach")
 
 		// Validate flag combinations
-		if err := validateRemoveFlags(all, force, yes, current, template, args); err != nil {
+		if err := checkFlagRules(cmd, rmFlagRules); err != nil {
+			return err
+		}
+		if err := checkNameWithFlags(cmd, args, "all"); err != nil {
 			return err
 		}
 
 		// Handle template operations
 		if template {
-			return executeTemplateOperations(configHandler, args, template, interactiveFlag, force || yes)
+			return executeTemplateOperations(configHandler, args, detach, interactiveFlag, force || yes)
 		}
 
 		// Create UI provider based on mode
@@ -74,32 +82,21 @@ Note: The default template cannot be deleted for system safety.`,
 		}
 
 		// Execute remove operation with enhanced logic
-		return executeEnhancedRemove(configHandler, uiProvider, args, all, current, force || yes)
+		return executeEnhancedRemove(cmd.Context(), ccswitch.FromConfigManager(cm), configHandler, uiProvider, resolveAliasArg(cm, args), all, current, force || yes)
 	},
 }
 
-// validateRemoveFlags validates flag combinations for the rm command
-func validateRemoveFlags(all, force, yes, current, template bool, args []string) error {
-	// Template operations cannot be combined with configuration operations
-	if template && (all || current) {
-		return fmt.Errorf("--template (-t) cannot be combined with --all (-a) or --current (-c) flags")
-	}
-
-	// --all cannot be combined with -f/--force or -y/--yes
-	if all && (force || yes) {
-		return fmt.Errorf("--all flag cannot be combined with --force (-f) or --yes (-y) flags")
-	}
-
-	// --all cannot have arguments
-	if all && len(args) > 0 {
-		return fmt.Errorf("--all flag cannot be used with specific configuration names")
-	}
-
-	return nil
+// rmFlagRules declares the flag combinations rm rejects. Template removal cannot be
+// combined with configuration operations, and --all always requires typed confirmation.
+var rmFlagRules = [][]flagRule{
+	conflictsWith("template", "all", "current"),
+	exclusiveFlags("all", "current"),
+	conflictsWith("all", "force", "yes"),
+	requiresFlag("detach", "template"),
 }
 
 // executeEnhancedRemove handles the enhanced remove operation with new flags
-func executeEnhancedRemove(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, args []string, all, current, skipConfirm bool) error {
+func executeEnhancedRemove(ctx context.Context, manager *ccswitch.Manager, configHandler handler.ConfigHandler, uiProvider ui.UIProvider, args []string, all, current, skipConfirm bool) error {
 	// Handle --all flag (delete all configurations)
 	if all {
 		return executeRemoveAll(configHandler, uiProvider)
@@ -111,7 +108,7 @@ func executeEnhancedRemove(configHandler handler.ConfigHandler, uiProvider ui.UI
 	}
 
 	// Fall back to original remove logic for specific configuration
-	return executeRemove(configHandler, uiProvider, args, skipConfirm)
+	return executeRemove(ctx, manager, uiProvider, args, skipConfirm)
 }
 
 // executeRemoveAll handles deleting all configurations
@@ -128,7 +125,7 @@ func executeRemoveAll(configHandler handler.ConfigHandler, uiProvider ui.UIProvi
 	}
 
 	// Show warning and require manual confirmation (cannot be bypassed)
-	uiProvider.ShowWarning("This will delete ALL %d configuration(s):", len(profiles))
+	uiProvider.ShowWarning(ui.Text("rm.delete_all"), len(profiles))
 	for _, profile := range profiles {
 		if profile.IsCurrent {
 			fmt.Printf("  - %s (current)\n", profile.Name)
@@ -139,13 +136,13 @@ func executeRemoveAll(configHandler handler.ConfigHandler, uiProvider ui.UIProvi
 	fmt.Println()
 
 	// Mandatory confirmation - cannot be bypassed with any flag
-	confirmMsg := "Type 'DELETE ALL' to confirm deletion of all configurations"
+	confirmMsg := ui.Text("rm.confirm_all")
 	fmt.Printf("%s: ", confirmMsg)
 	var confirmation string
 	fmt.Scanln(&confirmation)
 
 	if confirmation != "DELETE ALL" {
-		uiProvider.ShowInfo("Operation cancelled - confirmation text did not match")
+		uiProvider.ShowInfo(ui.Text("rm.confirm_mismatch"))
 		return nil
 	}
 
@@ -155,7 +152,7 @@ func executeRemoveAll(configHandler handler.ConfigHandler, uiProvider ui.UIProvi
 		return err
 	}
 
-	uiProvider.ShowSuccess("All configurations deleted successfully. Entering EMPTY MODE.")
+	uiProvider.ShowSuccess(ui.Text("rm.all_deleted"))
 	return nil
 }
 
@@ -175,9 +172,9 @@ func executeRemoveCurrent(configHandler handler.ConfigHandler, uiProvider ui.UIP
 
 	// Confirm deletion if not skipping
 	if !skipConfirm {
-		confirmMsg := fmt.Sprintf("Delete current configuration '%s' and enter EMPTY MODE?", currentName)
+		confirmMsg := fmt.Sprintf(ui.Text("rm.confirm_current"), currentName)
 		if !uiProvider.ConfirmAction(confirmMsg, false) {
-			uiProvider.ShowInfo("Operation cancelled")
+			uiProvider.ShowInfo(ui.Text("common.cancelled"))
 			return nil
 		}
 	}
@@ -188,21 +185,21 @@ func executeRemoveCurrent(configHandler handler.ConfigHandler, uiProvider ui.UIP
 		return err
 	}
 
-	uiProvider.ShowSuccess("Current configuration '%s' deleted. Entering EMPTY MODE.", currentName)
+	uiProvider.ShowSuccess(ui.Text("rm.current_deleted"), currentName)
 	return nil
 }
 
 // executeRemove handles the remove operation with the given dependencies
 // This function reuses the original logic for specific configuration removal
-func executeRemove(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, args []string, force bool) error {
+func executeRemove(ctx context.Context, manager *ccswitch.Manager, uiProvider ui.UIProvider, args []string, force bool) error {
 	// Get all configurations
-	profiles, err := configHandler.ListConfigs()
+	profiles, err := manager.List(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to list profiles: %w", err)
 	}
 
 	if len(profiles) == 0 {
-		uiProvider.ShowWarning("No configurations found.")
+		uiProvider.ShowWarning(ui.Text("rm.none_found"))
 		fmt.Println("Use 'cc-switch new <name>' to create your first configuration.")
 		return nil
 	}
@@ -238,25 +235,25 @@ func executeRemove(configHandler handler.ConfigHandler, uiProvider ui.UIProvider
 
 	// Confirm removal if not forced
 	if !force {
-		confirmMsg := fmt.Sprintf("Are you sure you want to remove configuration '%s'?", targetName)
+		confirmMsg := fmt.Sprintf(ui.Text("rm.confirm"), targetName)
 		if !uiProvider.ConfirmAction(confirmMsg, false) {
-			uiProvider.ShowInfo("Operation cancelled")
+			uiProvider.ShowInfo(ui.Text("common.cancelled"))
 			return nil
 		}
 	}
 
 	// Execute removal
-	if err := configHandler.DeleteConfig(targetName, force); err != nil {
+	if err := manager.Delete(ctx, targetName); err != nil {
 		uiProvider.ShowError(err)
 		return err
 	}
 
-	uiProvider.ShowSuccess("Configuration '%s' removed successfully", targetName)
+	uiProvider.ShowSuccess(ui.Text("rm.removed"), targetName)
 	return nil
 }
 
 // executeTemplateOperations handles template-related operations
-func executeTemplateOperations(configHandler handler.ConfigHandler, args []string, _ /* template */, _ /* interactive */, skipConfirm bool) error {
+func executeTemplateOperations(configHandler handler.ConfigHandler, args []string, detach, _ /* interactive */, skipConfirm bool) error {
 	// Template deletion logic
 	var targetTemplate string
 
@@ -286,18 +283,11 @@ func executeTemplateOperations(configHandler handler.ConfigHandler, args []strin
 			return nil
 		}
 
-		// Simple CLI selection for templates
-		fmt.Println("Available templates for deletion:")
-		for i, tmpl := range deletableTemplates {
-			fmt.Printf("  %d) %s\n", i+1, tmpl)
-		}
-
-		fmt.Printf("Select template to delete (1-%d): ", len(deletableTemplates))
-		var selection int
-		if _, err := fmt.Scanln(&selection); err != nil || selection < 1 || selection > len(deletableTemplates) {
-			return fmt.Errorf("invalid selection")
+		selection, err := ui.SelectFromMenu("Available templates for deletion:", ui.TemplateMenuItems(deletableTemplates), "Select template to delete")
+		if err != nil {
+			return fmt.Errorf("selection cancelled: %w", err)
 		}
-		targetTemplate = deletableTemplates[selection-1]
+		targetTemplate = deletableTemplates[selection]
 	} else {
 		// CLI mode with template name provided
 		targetTemplate = args[0]
@@ -310,7 +300,23 @@ func executeTemplateOperations(configHandler handler.ConfigHandler, args []strin
 
 	// Validate template exists
 	if err := configHandler.ValidateTemplateExists(targetTemplate); err != nil {
-		return fmt.Errorf("template '%s' does not exist", targetTemplate)
+		return config.NotFoundf("template '%s' does not exist", targetTemplate)
+	}
+
+	// Check for configurations created from this template
+	dependents, err := configHandler.TemplateInUse(targetTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to check template usage: %w", err)
+	}
+	if len(dependents) > 0 {
+		if !detach {
+			fmt.Printf("Template '%s' is still used by these configurations:\n", targetTemplate)
+			for _, name := range dependents {
+				fmt.Printf("  - %s\n", name)
+			}
+			return fmt.Errorf("template '%s' is in use; re-run with --detach to delete it anyway", targetTemplate)
+		}
+		fmt.Printf("Configurations created from '%s' will be detached: %s\n", targetTemplate, strings.Join(dependents, ", "))
 	}
 
 	// Confirm deletion if not skipping
@@ -326,7 +332,11 @@ func executeTemplateOperations(configHandler handler.ConfigHandler, args []strin
 	}
 
 	// Execute template deletion
-	if err := configHandler.DeleteTemplate(targetTemplate); err != nil {
+	deleteTemplate := configHandler.DeleteTemplate
+	if detach {
+		deleteTemplate = configHandler.DeleteTemplateDetached
+	}
+	if err := deleteTemplate(targetTemplate); err != nil {
 		return fmt.Errorf("failed to delete template: %w", err)
 	}
 
@@ -341,4 +351,5 @@ func init() {
 	rmCmd.Flags().BoolP("current", "c", false, "Delete current configuration and enter EMPTY MODE")
 	rmCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompts (cannot use with --all)")
 	rmCmd.Flags().BoolP("template", "t", false, "Delete template instead of configuration")
+	rmCmd.Flags().Bool("detach", false, "Delete a template even if configurations were created from it")
 }
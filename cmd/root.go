@@ -1,11 +1,20 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
 
-	"cc-switch/internal/common"
+	"github.com/HoBeedzc/cc-switch/internal/common"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/ui"
 
 	"github.com/spf13/cobra"
 )
@@ -23,23 +32,148 @@ This tool allows you to:
 - Copy configurations
 - Remove configurations
 - Export configurations to backup files
-- Import configurations from backup files`,
-	SilenceUsage: true,
-	Version:      common.Version,
+- Import configurations from backup files
+
+Global --dry-run prints every file a command would create, change or remove under
+~/.claude and leaves them untouched.
+
+Global --progress json writes progress of test --all, export, import and update as
+newline-delimited JSON events on stderr, for wrappers such as editor plugins.
+
+Exit codes (stable, for scripts; see --explain <code>):
+  0 ok, 1 error, 2 not found, 3 conflict, 4 validation, 5 locked`,
+	SilenceUsage:      true,
+	Version:           common.Version,
+	Args:              cobra.NoArgs,
+	PersistentPreRunE: applyGlobalFlags,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if explain, _ := cmd.Flags().GetString("explain"); explain != "" {
+			return explainExitCode(explain)
+		}
+		return cmd.Help()
+	},
 }
 
 // skipUpdateNotice determines if update notice should be skipped for certain commands
 var skipUpdateNotice bool
 
+// errSilentFailure makes a command exit with status 1 without printing an error,
+// for commands whose output already describes the failure (e.g. JSON for CI)
+var errSilentFailure = errors.New("command failed")
+
+// dryRunUnsupported lists commands whose main effect is writing files outside ~/.claude,
+// which the global --dry-run cannot intercept
+var dryRunUnsupported = map[string]bool{
+	"export": true,
+	"backup": true,
+	"update": true,
+}
+
+// dryRunUnsupportedFlags lists flags that make a command write a file outside ~/.claude,
+// keyed by the command path
+var dryRunUnsupportedFlags = map[string]string{
+	"cc-switch test":            "diagnostic-bundle",
+	"cc-switch template render": "output",
+}
+
+// applyGlobalFlags applies the global flags that change how every command runs
+func applyGlobalFlags(cmd *cobra.Command, args []string) error {
+	if err := applyProgress(cmd); err != nil {
+		return err
+	}
+	return applyDryRun(cmd, args)
+}
+
+// applyProgress enables machine-readable progress events on stderr for --progress json
+func applyProgress(cmd *cobra.Command) error {
+	switch mode, _ := cmd.Root().PersistentFlags().GetString("progress"); mode {
+	case "":
+	case "json":
+		common.SetProgressOutput(os.Stderr)
+	default:
+		return config.Invalidf("invalid --progress value '%s' (valid values: json)", mode)
+	}
+	return nil
+}
+
+// applyDryRun puts the configuration managers created by the command into read-only mode
+// when the global --dry-run is set. Commands with their own --dry-run flag (import, apply,
+// migrate-permissions, uninstall) shadow the global one and handle it themselves.
+func applyDryRun(cmd *cobra.Command, args []string) error {
+	enabled, _ := cmd.Root().PersistentFlags().GetBool("dry-run")
+	if !enabled {
+		return nil
+	}
+	if dryRunUnsupported[cmd.Name()] && cmd.Parent() == cmd.Root() {
+		return config.Invalidf("--dry-run is not supported by '%s' because it writes files outside ~/.claude", cmd.Name())
+	}
+	if flag, ok := dryRunUnsupportedFlags[cmd.CommandPath()]; ok && cmd.Flags().Changed(flag) {
+		return config.Invalidf("--dry-run cannot be combined with --%s because it writes a file outside ~/.claude", flag)
+	}
+	config.SetDryRun(true)
+	return nil
+}
+
+// interruptContext returns a context that is cancelled on Ctrl+C or SIGTERM, so long-running
+// operations can stop and report partial results. Call stop to restore default signal handling.
+func interruptContext(parent context.Context) (ctx context.Context, stop context.CancelFunc) {
+	return signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+}
+
+// isCompletionRequest reports whether the shell is asking for completions (or a
+// completion script), which must stay fast and print nothing but candidates
+func isCompletionRequest() bool {
+	if len(os.Args) < 2 {
+		return false
+	}
+	switch os.Args[1] {
+	case cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd, "completion":
+		return true
+	}
+	return false
+}
+
+// isDryRunRequest reports whether args contain --dry-run. The flag is parsed only once
+// the command runs, but the background update check starts before that and writes its cache.
+func isDryRunRequest(args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == "--dry-run" {
+			return true
+		}
+		if value, ok := strings.CutPrefix(arg, "--dry-run="); ok {
+			enabled, err := strconv.ParseBool(value)
+			return err == nil && enabled
+		}
+	}
+	return false
+}
+
 // Execute 执行根命令
 func Execute() error {
-	// Start background update check if needed
-	if common.ShouldCheckUpdate() {
+	ui.SetupTerminal()
+
+	// Completion requests skip the update check and notice
+	if isCompletionRequest() {
+		skipUpdateNotice = true
+	}
+
+	// Start background update check if needed; a dry run must not refresh the cache
+	if !skipUpdateNotice && !isDryRunRequest(os.Args[1:]) && common.ShouldCheckUpdate() {
 		common.CheckUpdateBackground(nil)
 	}
 
-	// Execute the command
+	// Execute the command; errors are printed here so errSilentFailure can stay quiet
+	rootCmd.SilenceErrors = true
 	err := rootCmd.Execute()
+	if err != nil && !errors.Is(err, errSilentFailure) {
+		rootCmd.PrintErrln(rootCmd.ErrPrefix(), err.Error())
+	}
+	if config.DryRun() {
+		fmt.Fprintln(os.Stderr, "Dry run: no files were changed.")
+	}
 
 	// Show update notice after command execution (if cached)
 	// Skip for update command (it handles its own update logic)
@@ -51,17 +185,40 @@ func Execute() error {
 }
 
 func init() {
+	rootCmd.Flags().String("explain", "", "Explain an exit code (e.g. --explain 2), or 'all' to list them")
+	rootCmd.PersistentFlags().Bool("dry-run", false, "Print the files that would be changed instead of changing them")
+	rootCmd.PersistentFlags().String("progress", "", "Write progress as JSON events on stderr ('json') for test --all, export, import and update")
+
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(newCmd)
 	rootCmd.AddCommand(useCmd)
+	rootCmd.AddCommand(scratchCmd)
 	rootCmd.AddCommand(mvCmd)
 	rootCmd.AddCommand(cpCmd)
 	rootCmd.AddCommand(rmCmd)
 	rootCmd.AddCommand(currentCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(logCmd)
+	rootCmd.AddCommand(emptyCmd)
+	rootCmd.AddCommand(tagCmd)
+	rootCmd.AddCommand(aliasCmd)
+	rootCmd.AddCommand(secretCmd)
+	rootCmd.AddCommand(whichCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(migratePermissionsCmd)
+	rootCmd.AddCommand(permissionsCmd)
+	rootCmd.AddCommand(templateCmd)
+	rootCmd.AddCommand(providersCmd)
 	rootCmd.AddCommand(viewCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(envCmd)
 	rootCmd.AddCommand(editCmd)
 	rootCmd.AddCommand(exportCmd)
 	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(applyCmd)
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(restoreCmd)
+	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(testCmd)
 	rootCmd.AddCommand(webCmd)
@@ -69,32 +226,118 @@ func init() {
 	rootCmd.AddCommand(uninstallCmd)
 }
 
+// ClaudeConfigState 描述 checkClaudeConfig 发现的阻止命令执行的状态
+type ClaudeConfigState int
+
+const (
+	// ConfigNotInitialized 既没有 settings.json 也没有 profiles 目录
+	ConfigNotInitialized ClaudeConfigState = iota
+	// ConfigPermissionDenied 配置目录或文件无法访问
+	ConfigPermissionDenied
+)
+
+// ClaudeConfigError Claude 配置检查错误
+type ClaudeConfigError struct {
+	State       ClaudeConfigState
+	Path        string
+	Message     string
+	Suggestions []string
+}
+
+func (e *ClaudeConfigError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return e.Message
+	}
+	return e.Message + "\n  " + strings.Join(e.Suggestions, "\n  ")
+}
+
 // 检查Claude配置是否存在的助手函数
+// 空配置模式以及 settings.json 缺失但已有配置的情况都不会阻止命令执行，
+// 因为列出、查看、切换和测试已保存的配置并不依赖 settings.json
 func checkClaudeConfig() error {
-	homeDir, err := os.UserHomeDir()
+	layout, err := common.DefaultLayout()
 	if err != nil {
 		return fmt.Errorf("failed to get home directory: %w", err)
 	}
 
-	claudeDir := filepath.Join(homeDir, ".claude")
-	settingsPath := filepath.Join(claudeDir, "settings.json")
-	profilesDir := filepath.Join(claudeDir, "profiles")
+	claudeDir := layout.ClaudeDir
+	settingsPath := layout.SettingsFile()
+	profilesDir := layout.ProfilesDir
+	// 尚未迁移到 XDG 目录时空配置模式标记仍在配置目录中
 	emptyModeFile := filepath.Join(profilesDir, ".empty_mode")
+	if layout.Migrated {
+		emptyModeFile = layout.DataFile(".empty_mode")
+	}
+
+	// 权限问题优先报告，否则会被误判为未初始化
+	for _, path := range []string{claudeDir, profilesDir, settingsPath} {
+		if _, err := os.Stat(path); err != nil && os.IsPermission(err) {
+			return permissionError(path)
+		}
+	}
+	if err := checkDirReadable(profilesDir); err != nil {
+		return err
+	}
+
+	_, profilesErr := os.Stat(profilesDir)
+	_, settingsErr := os.Stat(settingsPath)
 
 	// Check if in empty mode - if so, allow the operation
 	if _, err := os.Stat(emptyModeFile); err == nil {
 		return nil // Empty mode is valid
 	}
 
-	// Check for profiles directory (indicates cc-switch is initialized)
-	if _, err := os.Stat(profilesDir); os.IsNotExist(err) {
-		return fmt.Errorf("claude configuration not found at %s", settingsPath)
+	if os.IsNotExist(profilesErr) && os.IsNotExist(settingsErr) {
+		return &ClaudeConfigError{
+			State:   ConfigNotInitialized,
+			Path:    settingsPath,
+			Message: fmt.Sprintf("claude configuration not found at %s", settingsPath),
+			Suggestions: []string{
+				"Run 'cc-switch init' to create your first configuration",
+			},
+		}
 	}
 
-	// If not in empty mode, settings.json should exist
-	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
-		return fmt.Errorf("claude configuration not found at %s", settingsPath)
+	// settings.json 缺失但存在配置：没有激活的配置，与空配置模式类似
+	if os.IsNotExist(settingsErr) {
+		fmt.Fprintf(os.Stderr, "Warning: no active configuration (%s is missing). Use 'cc-switch use <name>' to activate one.\n", settingsPath)
 	}
 
 	return nil
 }
+
+// checkDirReadable 确认目录存在时可以列出内容
+func checkDirReadable(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		if os.IsPermission(err) {
+			return permissionError(dir)
+		}
+		return nil
+	}
+	defer f.Close()
+
+	if _, err := f.Readdirnames(1); err != nil && os.IsPermission(err) {
+		return permissionError(dir)
+	}
+	return nil
+}
+
+// permissionError 构造带修复建议的权限错误
+func permissionError(path string) *ClaudeConfigError {
+	suggestions := []string{
+		fmt.Sprintf("Check the permissions of %s (e.g. 'ls -ld %s')", path, path),
+	}
+	if runtime.GOOS != "windows" {
+		suggestions = append(suggestions,
+			fmt.Sprintf("Make it accessible to your user: chown -R \"$(whoami)\" %s && chmod -R u+rwX %s", path, path),
+		)
+	}
+
+	return &ClaudeConfigError{
+		State:       ConfigPermissionDenied,
+		Path:        path,
+		Message:     fmt.Sprintf("cannot access %s: permission denied", path),
+		Suggestions: suggestions,
+	}
+}
@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/HoBeedzc/cc-switch/internal/common"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/config/configtest"
+	"github.com/HoBeedzc/cc-switch/internal/testutil"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// setupHome points cc-switch at an empty temporary home directory and returns it
+func setupHome(t *testing.T) string {
+	t.Helper()
+	return testutil.SetupHome(t)
+}
+
+// newTestManager creates a configuration manager in the current test home
+func newTestManager(t *testing.T) *config.ConfigManager {
+	t.Helper()
+	return configtest.NewManagerInCurrentHome(t, config.Options{})
+}
+
+// resetFlags restores every flag of cmd and its subcommands to its default, since
+// cobra keeps parsed values between runs of the same command tree
+func resetFlags(cmd *cobra.Command) {
+	reset := func(flag *pflag.Flag) {
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			slice.Replace(nil)
+		} else {
+			flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+// runCommand runs cc-switch with the given arguments and returns the command error
+func runCommand(t *testing.T, args ...string) error {
+	t.Helper()
+	resetFlags(rootCmd)
+	rootCmd.SetArgs(args)
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+	t.Cleanup(func() {
+		rootCmd.SetArgs(nil)
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+	})
+	return rootCmd.Execute()
+}
+
+// configState returns the ClaudeConfigError state of err, or -1 when err is not one
+func configState(err error) ClaudeConfigState {
+	var configErr *ClaudeConfigError
+	if errors.As(err, &configErr) {
+		return configErr.State
+	}
+	return -1
+}
+
+func TestCheckClaudeConfigNotInitialized(t *testing.T) {
+	setupHome(t)
+
+	err := checkClaudeConfig()
+	if configState(err) != ConfigNotInitialized {
+		t.Fatalf("err = %v, want not initialized", err)
+	}
+	if !strings.Contains(err.Error(), "cc-switch init") {
+		t.Errorf("err = %q, want the init suggestion", err)
+	}
+
+	// Every command that needs profiles reports the same state
+	for _, args := range [][]string{{"list"}, {"view", "work"}, {"use", "work"}} {
+		if err := runCommand(t, args...); configState(err) != ConfigNotInitialized {
+			t.Errorf("%s: err = %v, want not initialized", strings.Join(args, " "), err)
+		}
+	}
+}
+
+func TestCommandsAllowedInEmptyMode(t *testing.T) {
+	setupHome(t)
+	cm := newTestManager(t)
+	for _, name := range []string{"work", "home"} {
+		if err := cm.CreateProfileWithContent(name, map[string]interface{}{"model": name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cm.UseProfile("home"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.EnableEmptyMode(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := checkClaudeConfig(); err != nil {
+		t.Fatalf("checkClaudeConfig in empty mode: %v", err)
+	}
+	for _, args := range [][]string{{"list"}, {"view", "work"}, {"use", "work"}} {
+		if err := runCommand(t, args...); err != nil {
+			t.Errorf("%s in empty mode: %v", strings.Join(args, " "), err)
+		}
+	}
+	if newTestManager(t).IsEmptyMode() {
+		t.Error("use did not leave empty mode")
+	}
+}
+
+func TestCommandsAllowedWithoutActiveConfig(t *testing.T) {
+	home := setupHome(t)
+	cm := newTestManager(t)
+	if err := cm.CreateProfileWithContent("work", map[string]interface{}{"model": "opus"}); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(filepath.Join(home, ".claude", "settings.json"))
+
+	if err := checkClaudeConfig(); err != nil {
+		t.Fatalf("checkClaudeConfig without settings.json: %v", err)
+	}
+	for _, args := range [][]string{{"list"}, {"view", "work"}} {
+		if err := runCommand(t, args...); err != nil {
+			t.Errorf("%s without settings.json: %v", strings.Join(args, " "), err)
+		}
+	}
+}
+
+func TestCheckClaudeConfigPermissionDenied(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("directory modes do not restrict access on Windows")
+	}
+	if os.Geteuid() == 0 {
+		t.Skip("root is not subject to directory permissions")
+	}
+
+	setupHome(t)
+	cm := newTestManager(t)
+	if err := cm.CreateProfileWithContent("work", map[string]interface{}{}); err != nil {
+		t.Fatal(err)
+	}
+	profilesDir := cm.GetProfilesDir()
+	if err := os.Chmod(profilesDir, 0000); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(profilesDir, 0755) })
+
+	err := checkClaudeConfig()
+	if configState(err) != ConfigPermissionDenied {
+		t.Fatalf("err = %v, want permission denied", err)
+	}
+	if strings.Contains(err.Error(), "cc-switch init") {
+		t.Errorf("err = %q, suggests init for a permission problem", err)
+	}
+	if !strings.Contains(err.Error(), "chmod") {
+		t.Errorf("err = %q, want a chmod hint", err)
+	}
+
+	if err := runCommand(t, "list"); configState(err) != ConfigPermissionDenied {
+		t.Errorf("list: err = %v, want permission denied", err)
+	}
+}
+
+func TestDryRunRejectsWritesOutsideClaudeDir(t *testing.T) {
+	home := setupHome(t)
+	cm := newTestManager(t)
+	if err := cm.CreateProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.UseProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { config.SetDryRun(false) })
+
+	output := filepath.Join(home, "out.json")
+	for _, args := range [][]string{
+		{"--dry-run", "test", "work", "--diagnostic-bundle", output},
+		{"--dry-run", "template", "render", "default", "-o", output},
+		{"--dry-run", "update"},
+	} {
+		err := runCommand(t, args...)
+		if !errors.Is(err, config.ErrInvalid) {
+			t.Errorf("%v: err = %v, want ErrInvalid", args, err)
+		}
+		if _, err := os.Stat(output); !os.IsNotExist(err) {
+			t.Errorf("%v wrote %s", args, output)
+		}
+		if config.DryRun() {
+			t.Errorf("%v left dry-run enabled", args)
+		}
+	}
+}
+
+func TestIsDryRunRequest(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"--dry-run", "update", "--check"}, true},
+		{[]string{"update", "--check", "--dry-run"}, true},
+		{[]string{"--dry-run=true", "use", "work"}, true},
+		{[]string{"--dry-run=false", "use", "work"}, false},
+		{[]string{"update", "--check"}, false},
+		{[]string{"env", "--", "--dry-run"}, false},
+	}
+	for _, tt := range tests {
+		if got := isDryRunRequest(tt.args); got != tt.want {
+			t.Errorf("isDryRunRequest(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestDryRunUseLeavesSettings(t *testing.T) {
+	setupHome(t)
+	cm := newTestManager(t)
+	for _, name := range []string{"work", "home"} {
+		if err := cm.CreateProfileWithContent(name, map[string]interface{}{"model": name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cm.UseProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(cm.GetSettingsFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { config.SetDryRun(false) })
+
+	if err := runCommand(t, "--dry-run", "use", "home"); err != nil {
+		t.Fatalf("use --dry-run: %v", err)
+	}
+	after, err := os.ReadFile(cm.GetSettingsFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Error("settings.json changed in a dry run")
+	}
+	if current, _ := cm.GetCurrentProfile(); current != "work" {
+		t.Errorf("current = %q, want work", current)
+	}
+}
+
+// captureStderr redirects os.Stderr while fn runs and returns what was written
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	file, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	stderr := os.Stderr
+	os.Stderr = file
+	defer func() { os.Stderr = stderr }()
+	fn()
+
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestProgressJSON(t *testing.T) {
+	home := setupHome(t)
+	cm := newTestManager(t)
+	for _, name := range []string{"work", "home"} {
+		if err := cm.CreateProfile(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() { common.SetProgressOutput(nil) })
+
+	output := filepath.Join(home, "all.ccx")
+	var runErr error
+	stderr := captureStderr(t, func() {
+		runErr = runCommand(t, "--progress", "json", "export", "--all", "-o", output, "-p", "secret")
+	})
+	if runErr != nil {
+		t.Fatalf("export: %v", runErr)
+	}
+
+	var events []common.ProgressEvent
+	for _, line := range strings.Split(strings.TrimSpace(stderr), "\n") {
+		if !strings.HasPrefix(line, "{") {
+			continue // warnings are still plain text
+		}
+		var event common.ProgressEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("line %q is not a progress event: %v", line, err)
+		}
+		events = append(events, event)
+	}
+	if len(events) != 3 {
+		t.Fatalf("events = %+v, want one per profile and a finished event", events)
+	}
+	items := []string{events[0].Item, events[1].Item}
+	sort.Strings(items)
+	if items[0] != "home" || items[1] != "work" {
+		t.Errorf("items = %v, want home and work", items)
+	}
+	last := events[2]
+	if last.Op != "export" || last.Status != common.ProgressFinished || last.Result != common.ResultOK || last.Done != 2 {
+		t.Errorf("finished event = %+v, want export ok with 2 done", last)
+	}
+}
+
+func TestProgressRejectsUnknownMode(t *testing.T) {
+	setupHome(t)
+	t.Cleanup(func() { common.SetProgressOutput(nil) })
+	if err := runCommand(t, "--progress", "bar", "list"); !errors.Is(err, config.ErrInvalid) {
+		t.Errorf("err = %v, want ErrInvalid", err)
+	}
+	if common.JSONProgress() {
+		t.Error("progress events were enabled")
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var scratchCmd = &cobra.Command{
+	Use:   "scratch",
+	Short: "Manage throwaway configurations created by 'use --scratch'",
+	Long: `Manage scratch configurations created by 'cc-switch use --scratch'.
+
+A scratch configuration is deleted automatically when you switch to another
+configuration. These subcommands clean up leftovers or keep one for good.
+
+Examples:
+  cc-switch scratch clear
+  cc-switch scratch keep scratch-20260101-120000`,
+}
+
+var scratchClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete all scratch configurations except the active one",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cm, err := scratchConfigManager()
+		if err != nil {
+			return err
+		}
+
+		removed, kept, err := cm.ClearScratchProfiles()
+		for _, name := range removed {
+			color.Green("✓ Deleted scratch configuration '%s'", name)
+		}
+		for _, name := range kept {
+			color.Yellow("Kept scratch configuration '%s' (active or the only configuration left)", name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(removed) == 0 && len(kept) == 0 {
+			fmt.Println("No scratch configurations found")
+		}
+		return nil
+	},
+}
+
+var scratchKeepCmd = &cobra.Command{
+	Use:   "keep <name>",
+	Short: "Keep a scratch configuration so it is no longer deleted automatically",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cm, err := scratchConfigManager()
+		if err != nil {
+			return err
+		}
+		if err := cm.KeepScratchProfile(args[0]); err != nil {
+			return err
+		}
+		color.Green("✓ Configuration '%s' will be kept (rename it with 'cc-switch mv %s <new-name>')", args[0], args[0])
+		return nil
+	},
+}
+
+// scratchConfigManager initializes the config manager for the scratch subcommands
+func scratchConfigManager() (*config.ConfigManager, error) {
+	if err := checkClaudeConfig(); err != nil {
+		return nil, err
+	}
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+	return cm, nil
+}
+
+func init() {
+	scratchCmd.AddCommand(scratchClearCmd, scratchKeepCmd)
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage secrets referenced from configurations",
+	Long: `Manage named secrets stored in an encrypted file (profiles/.secrets.enc).
+
+A configuration value of "@secret:<name>" is replaced with the secret when the
+configuration is switched to or tested; the stored configuration keeps the reference.
+The passphrase is read from $` + config.SecretsPassphraseEnv + ` or prompted for.
+
+Examples:
+  cc-switch secret set work-token            # prompt for the value
+  cc-switch secret set work-token sk-ant-... # value on the command line
+  cc-switch edit work -f env.ANTHROPIC_AUTH_TOKEN   # then enter @secret:work-token
+  cc-switch secret list
+  cc-switch secret get work-token
+  cc-switch secret rm work-token`,
+}
+
+var secretSetCmd = &cobra.Command{
+	Use:   "set <name> [value]",
+	Short: "Add or update a secret",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cm, err := newSecretsManager()
+		if err != nil {
+			return err
+		}
+
+		value := ""
+		if len(args) == 2 {
+			value = args[1]
+		} else if value, err = readSecretValue(args[0]); err != nil {
+			return err
+		}
+
+		if !cm.SecretsStoreExists() && os.Getenv(config.SecretsPassphraseEnv) == "" {
+			passphrase, err := promptForPassword("New secrets passphrase: ")
+			if err != nil {
+				return fmt.Errorf("failed to read passphrase: %w", err)
+			}
+			if passphrase == "" {
+				return fmt.Errorf("passphrase cannot be empty")
+			}
+			cm.SetSecretsPassphrase(passphrase)
+		}
+
+		if err := cm.SetSecret(args[0], value); err != nil {
+			return err
+		}
+
+		color.Green("✓ Secret '%s' saved", args[0])
+		fmt.Printf("Reference it in a configuration as \"%s%s\".\n", config.SecretRefPrefix, args[0])
+		return nil
+	},
+}
+
+var secretGetCmd = &cobra.Command{
+	Use:   "get <name>",
+	Short: "Print a secret value",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cm, err := newSecretsManager()
+		if err != nil {
+			return err
+		}
+
+		value, err := cm.GetSecret(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var secretListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List secret names",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cm, err := newSecretsManager()
+		if err != nil {
+			return err
+		}
+
+		if !cm.SecretsStoreExists() {
+			fmt.Println("No secrets stored. Use 'cc-switch secret set <name>' to add one.")
+			return nil
+		}
+
+		names, err := cm.ListSecrets()
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return nil
+	},
+}
+
+var secretRmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Remove a secret that is no longer referenced",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cm, err := newSecretsManager()
+		if err != nil {
+			return err
+		}
+
+		if err := cm.RemoveSecret(args[0]); err != nil {
+			return err
+		}
+
+		color.Green("✓ Secret '%s' removed", args[0])
+		return nil
+	},
+}
+
+// newSecretsManager checks the Claude config and creates a config manager
+func newSecretsManager() (*config.ConfigManager, error) {
+	if err := checkClaudeConfig(); err != nil {
+		return nil, err
+	}
+
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+	return cm, nil
+}
+
+// readSecretValue prompts for a secret without echo, or reads one line from piped stdin
+func readSecretValue(name string) (string, error) {
+	if !term.IsTerminal(int(syscall.Stdin)) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("failed to read secret value from stdin: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Printf("Value for '%s': ", name)
+	value, err := term.ReadPassword(int(syscall.Stdin))
+	fmt.Print("\n")
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret value: %w", err)
+	}
+	return string(value), nil
+}
+
+func init() {
+	secretCmd.AddCommand(secretSetCmd, secretGetCmd, secretListCmd, secretRmCmd)
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var tagFilter string
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Manage configuration tags",
+	Long: `Add, remove and list tags on configurations.
+
+Tags are stored alongside the configuration (not inside settings.json) and can be
+used to select configurations with --filter 'tag:<tag>'.
+
+Examples:
+  cc-switch tag add team work personal
+  cc-switch tag add team --filter 'work-*'
+  cc-switch tag rm team --filter 'tag:old'
+  cc-switch tag list`,
+}
+
+var tagAddCmd = &cobra.Command{
+	Use:   "add <tag> [name...]",
+	Short: "Add a tag to configurations",
+	Args:  tagTargetArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTagChange(args[0], args[1:], true)
+	},
+}
+
+var tagRmCmd = &cobra.Command{
+	Use:   "rm <tag> [name...]",
+	Short: "Remove a tag from configurations",
+	Args:  tagTargetArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTagChange(args[0], args[1:], false)
+	},
+}
+
+var tagListCmd = &cobra.Command{
+	Use:   "list [name]",
+	Short: "List tags of configurations",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkClaudeConfig(); err != nil {
+			return err
+		}
+
+		cm, err := config.NewConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		if len(args) == 1 {
+			if !cm.ProfileExists(args[0]) {
+				return config.NotFoundf("configuration '%s' does not exist", args[0])
+			}
+			tags, err := cm.GetProfileTags(args[0])
+			if err != nil {
+				return err
+			}
+			for _, tag := range tags {
+				fmt.Println(tag)
+			}
+			return nil
+		}
+
+		profiles, err := cm.ListProfilesFiltered("*")
+		if err != nil {
+			return err
+		}
+
+		tagged := 0
+		for _, profile := range profiles {
+			tags, err := cm.GetProfileTags(profile.Name)
+			if err != nil || len(tags) == 0 {
+				continue
+			}
+			tagged++
+			fmt.Printf("%-20s %s\n", profile.Name, strings.Join(tags, ", "))
+		}
+		if tagged == 0 {
+			fmt.Println("No configurations are tagged. Use 'cc-switch tag add <tag> <name>' to add one.")
+		}
+		return nil
+	},
+}
+
+// tagTargetArgs requires a tag plus either explicit names or --filter, but not both
+func tagTargetArgs(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("missing required argument: tag")
+	}
+	if tagFilter == "" && len(args) < 2 {
+		return fmt.Errorf("specify configuration names or --filter")
+	}
+	if tagFilter != "" && len(args) > 1 {
+		return fmt.Errorf("cannot use configuration names together with --filter")
+	}
+	return nil
+}
+
+// runTagChange adds or removes a tag on the named configurations or on every --filter match
+func runTagChange(tag string, names []string, add bool) error {
+	if err := checkClaudeConfig(); err != nil {
+		return err
+	}
+
+	tag, err := config.NormalizeTag(tag)
+	if err != nil {
+		return err
+	}
+
+	cm, err := config.NewConfigManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+
+	if tagFilter != "" {
+		profiles, err := cm.ListProfilesFiltered(tagFilter)
+		if err != nil {
+			return err
+		}
+		if len(profiles) == 0 {
+			return fmt.Errorf("no configurations match filter '%s'", tagFilter)
+		}
+		names = make([]string, 0, len(profiles))
+		for _, profile := range profiles {
+			names = append(names, profile.Name)
+		}
+	}
+
+	changed := 0
+	var failures []string
+	for _, name := range names {
+		var updated bool
+		if add {
+			updated, err = cm.AddProfileTag(name, tag)
+		} else {
+			updated, err = cm.RemoveProfileTag(name, tag)
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		if updated {
+			changed++
+		}
+	}
+
+	for _, failure := range failures {
+		color.Red("✗ %s", failure)
+	}
+
+	unchanged := len(names) - changed - len(failures)
+	if add {
+		color.Green("✓ Tagged %d configuration(s) with '%s'", changed, tag)
+		if unchanged > 0 {
+			fmt.Printf("%d configuration(s) already had this tag.\n", unchanged)
+		}
+	} else {
+		color.Green("✓ Removed tag '%s' from %d configuration(s)", tag, changed)
+		if unchanged > 0 {
+			fmt.Printf("%d configuration(s) did not have this tag.\n", unchanged)
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%d configuration(s) could not be updated", len(failures))
+	}
+	return nil
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{tagAddCmd, tagRmCmd} {
+		cmd.Flags().StringVarP(&tagFilter, "filter", "f", "", "Apply to every configuration matching a glob (e.g. 'work-*') or 'tag:<tag>'")
+	}
+	tagCmd.AddCommand(tagAddCmd, tagRmCmd, tagListCmd)
+}
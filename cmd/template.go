@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/ui"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Check and preview templates",
+}
+
+var templateLintCmd = &cobra.Command{
+	Use:   "lint [name...] | --all",
+	Short: "Check templates for leaked secrets and other mistakes",
+	Long: `Check templates for mistakes that are easy to make when a template is edited
+as if it were a configuration (or the other way around):
+
+- the file is not valid JSON
+- there is no env section
+- a token or key field holds a real value instead of being left empty
+  (templates are shared, so this leaks the secret); @secret: references are fine
+- a path listed in "_fields" does not exist in the template
+
+Without a name or --all, pick a template from a list.
+Exits with status 4 when an error is found.
+
+Examples:
+  cc-switch template lint team
+  cc-switch template lint --all`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+		if all && len(args) > 0 {
+			return fmt.Errorf("cannot use a name argument and -a/--all together")
+		}
+
+		if err := checkClaudeConfig(); err != nil {
+			return err
+		}
+		cm, err := config.NewConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		names := args
+		if len(names) == 0 {
+			templates, err := cm.ListTemplates()
+			if err != nil {
+				return err
+			}
+			if len(templates) == 0 {
+				fmt.Println("No templates found.")
+				return nil
+			}
+			if all {
+				names = templates
+			} else {
+				selection, err := ui.SelectFromMenu("Available templates:", ui.TemplateMenuItems(templates), "Select template to lint")
+				if err != nil {
+					return fmt.Errorf("selection cancelled: %w", err)
+				}
+				names = []string{templates[selection]}
+			}
+		}
+
+		errorCount := 0
+		warningCount := 0
+		for _, name := range names {
+			issues, err := cm.LintTemplate(name)
+			if err != nil {
+				return err
+			}
+			if len(issues) == 0 {
+				color.Green("  ✓ %s", name)
+				continue
+			}
+
+			fmt.Printf("  %s\n", name)
+			for _, issue := range issues {
+				if issue.Severity == config.SeverityError {
+					errorCount++
+				} else {
+					warningCount++
+				}
+				// Profile-oriented fixes edit the template instead
+				issue.Remediation = strings.ReplaceAll(issue.Remediation, "cc-switch edit <name>", "cc-switch edit -t <name>")
+				printValidationIssue("    ", name, issue)
+			}
+		}
+
+		fmt.Printf("\nSummary: %d error(s), %d warning(s)\n", errorCount, warningCount)
+		if errorCount > 0 {
+			return config.Invalidf("%d template error(s) found", errorCount)
+		}
+		return nil
+	},
+}
+
+var templateRenderCmd = &cobra.Command{
+	Use:   "render <template> [--set path=value ...]",
+	Short: "Preview the configuration a template would produce",
+	Long: `Show the configuration 'cc-switch new <name> -t <template>' would create after
+filling in the given fields, without creating anything. Fields are set by their
+path (e.g. env.ANTHROPIC_AUTH_TOKEN) exactly as the interactive prompts fill them.
+
+Secret values are masked. Required fields that are still empty are listed on
+stderr after the preview.
+
+Examples:
+  cc-switch template render team --set env.ANTHROPIC_AUTH_TOKEN=sk-ant-xxx
+  cc-switch template render team --set env.ANTHROPIC_BASE_URL=https://llm.example.com --json
+  cc-switch template render team --set env.ANTHROPIC_AUTH_TOKEN=sk-ant-xxx -o preview.json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeFirstTemplateName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sets, _ := cmd.Flags().GetStringArray("set")
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		output, _ := cmd.Flags().GetString("output")
+
+		inputs := make(map[string]string, len(sets))
+		for _, set := range sets {
+			path, value, ok := strings.Cut(set, "=")
+			if !ok {
+				return config.Invalidf("--set expects path=value, got '%s'", set)
+			}
+			inputs[strings.TrimSpace(path)] = value
+		}
+
+		if err := checkClaudeConfig(); err != nil {
+			return err
+		}
+		cm, err := config.NewConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		content, err := cm.RenderTemplate(args[0], inputs)
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(maskSecretContent(content), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal configuration: %w", err)
+		}
+
+		if output != "" {
+			if err := os.WriteFile(output, append(data, '\n'), 0600); err != nil {
+				return fmt.Errorf("failed to write %s: %w", output, err)
+			}
+			color.Green("✓ Wrote the rendered configuration to %s", output)
+		} else if jsonOutput {
+			fmt.Println(string(data))
+		} else {
+			fmt.Printf("Configuration rendered from template '%s' (nothing was created):\n\n", args[0])
+			fmt.Println(string(data))
+		}
+
+		var missing []string
+		for _, field := range cm.DetectEmptyFields(content) {
+			if field.Required {
+				missing = append(missing, field.Path)
+			}
+		}
+		if len(missing) > 0 {
+			color.New(color.FgYellow).Fprintf(os.Stderr, "\nRequired fields still empty: %s\n", strings.Join(missing, ", "))
+		}
+		return nil
+	},
+}
+
+// completeFirstTemplateName completes the first argument with template names
+func completeFirstTemplateName(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeTemplateNames(cmd, args, toComplete)
+}
+
+func init() {
+	templateLintCmd.Flags().BoolP("all", "a", false, "Check every template")
+	templateCmd.AddCommand(templateLintCmd)
+
+	templateRenderCmd.Flags().StringArray("set", nil, "Fill in a field as path=value (repeatable)")
+	templateRenderCmd.Flags().Bool("json", false, "Print only the rendered configuration as JSON")
+	templateRenderCmd.Flags().StringP("output", "o", "", "Write the rendered configuration to a file instead of printing it")
+	templateCmd.AddCommand(templateRenderCmd)
+}
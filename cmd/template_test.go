@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTemplateRender(t *testing.T) {
+	setupHome(t)
+	cm := newTestManager(t)
+	if err := cm.CreateProfileWithContent("work", map[string]interface{}{"model": "work"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.UseProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.CreateTemplate("team"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.UpdateTemplate("team", map[string]interface{}{
+		"env": map[string]interface{}{"ANTHROPIC_BASE_URL": "https://llm.example.com", "ANTHROPIC_AUTH_TOKEN": ""},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	render := func(args ...string) map[string]interface{} {
+		t.Helper()
+		output := filepath.Join(t.TempDir(), "preview.json")
+		args = append([]string{"template", "render", "team", "-o", output}, args...)
+		if err := runCommand(t, args...); err != nil {
+			t.Fatalf("cc-switch %v: %v", args, err)
+		}
+		data, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var content map[string]interface{}
+		if err := json.Unmarshal(data, &content); err != nil {
+			t.Fatalf("rendered output is not JSON: %v\n%s", err, data)
+		}
+		return content
+	}
+
+	env := render()["env"].(map[string]interface{})
+	if env["ANTHROPIC_BASE_URL"] != "https://llm.example.com" || env["ANTHROPIC_AUTH_TOKEN"] != "" {
+		t.Errorf("render without variables: env = %v, want the template unchanged", env)
+	}
+
+	content := render("--set", "env.ANTHROPIC_AUTH_TOKEN=sk-ant-render-secret", "--set", "model=opus")
+	if content["model"] != "opus" {
+		t.Errorf("model = %v, want opus", content["model"])
+	}
+	env = content["env"].(map[string]interface{})
+	if token := env["ANTHROPIC_AUTH_TOKEN"]; token == "" || token == "sk-ant-render-secret" {
+		t.Errorf("token = %v, want it set and masked", token)
+	}
+
+	if got := ExitCode(runCommand(t, "template", "render", "team", "--set", "model")); got != 4 {
+		t.Errorf("--set without '=': exit code %d, want 4", got)
+	}
+	if got := ExitCode(runCommand(t, "template", "render", "missing")); got != 2 {
+		t.Errorf("missing template: exit code %d, want 2", got)
+	}
+
+	if cm.ProfileExists("team") {
+		t.Error("rendering created a profile")
+	}
+}
@@ -1,15 +1,23 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"runtime"
+	"sort"
 	"strings"
 	"time"
 
-	"cc-switch/internal/config"
-	"cc-switch/internal/handler"
-	"cc-switch/internal/ui"
+	"github.com/HoBeedzc/cc-switch/internal/common"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
+	"github.com/HoBeedzc/cc-switch/internal/ui"
+	"github.com/HoBeedzc/cc-switch/pkg/ccswitch"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
@@ -24,6 +32,7 @@ Modes:
 - CLI: cc-switch test <profile-name>
 - Current: cc-switch test -c or cc-switch test --current
 - All: cc-switch test --all
+- File: cc-switch test --file <path>
 
 The interactive mode allows you to browse and select configurations to test.
 
@@ -33,26 +42,104 @@ Examples:
   cc-switch test -c                 # Test current configuration
   cc-switch test --all              # Test all configurations
   cc-switch test --quick            # Quick connectivity test only
+  cc-switch test work --endpoint-set no-chat  # Skip the Claude CLI chat test
   cc-switch test --verbose          # Show detailed request/response info
   cc-switch test -r -1              # Retry infinitely until success
   cc-switch test -r 0               # No retry (default)
   cc-switch test -r 5               # Retry up to 5 times on failure
-  cc-switch test -r 3 --retry-interval 5s  # Retry 3 times with 5s interval`,
+  cc-switch test -r 3 --retry-interval 5s  # Retry 3 times with 5s interval
+  cc-switch test --no-isolate       # Run the chat test against your real ~/.claude
+  cc-switch test --file ./settings.json  # Test a settings file before importing it
+  cc-switch test work --diagnostic-bundle out.json  # Redacted report to attach to bug reports
+  cc-switch test --all --json --fail-on-error  # CI: JSON on stdout, exit 1 if any configuration fails
+
+The chat test runs the Claude CLI in an isolated temporary HOME that only
+contains the profile under test, so it does not touch your real ~/.claude
+files. Use --no-isolate to run it against your real environment instead.
+
+With --file the given settings.json is tested directly, without looking up a
+profile; results are labeled with the file path. The file must pass the same
+schema validation as a profile.
+
+With --endpoint-set a named list of endpoints is tested. The built-in sets are
+minimal (basic), full (auth, models, chat) and no-chat (auth, models). More sets can
+be defined globally in ~/.claude/profiles/.endpoint-sets.json, for example
+{"myset": ["auth", "models"]}, or for one profile under "endpoint_sets" in
+~/.claude/profiles/.meta/<name>.json. Profile sets take precedence over global sets,
+which take precedence over built-in ones; profile sets apply when the profile is
+named or tested with -c. Use --list-endpoint-sets to see the available sets.
+
+With --diagnostic-bundle the full test suite is run against one configuration and a
+JSON report is written with the results, the configuration with secrets masked, the
+Claude CLI path and version, OS/arch and cc-switch version.
+
+With --fail-on-error the command exits with status 1 when any tested configuration
+is not functional. Combined with --json, the full JSON report (including a top-level
+"healthy" field) is still written to stdout and no error text is printed.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runTest,
 }
 
+// testFlagRules declares the flag combinations test rejects
+var testFlagRules = [][]flagRule{
+	exclusiveFlags("interactive", "current", "all", "file"),
+	exclusiveFlags("quick", "endpoint", "endpoint-set"),
+	exclusiveFlags("isolated", "no-isolate"),
+	conflictsWith("diagnostic-bundle", "interactive", "all", "file", "quick", "endpoint", "endpoint-set"),
+	conflictsWith("list-endpoint-sets", "all", "file", "interactive", "diagnostic-bundle"),
+}
+
+// testFailOnError makes test exit non-zero when a tested configuration is not functional
+var testFailOnError bool
+
 func init() {
 	testCmd.Flags().BoolP("all", "a", false, "Test all configurations")
 	testCmd.Flags().BoolP("current", "c", false, "Test current configuration")
 	testCmd.Flags().BoolP("interactive", "i", false, "Enter interactive mode")
+	testCmd.Flags().String("file", "", "Test a settings.json file instead of a stored profile")
 	testCmd.Flags().BoolP("verbose", "v", false, "Show detailed request/response information")
 	testCmd.Flags().BoolP("quick", "q", false, "Quick test (basic connectivity only)")
 	testCmd.Flags().String("endpoint", "", "Test specific endpoint (basic, auth, models, chat)")
+	testCmd.Flags().String("endpoint-set", "", "Test a named set of endpoints (built-in: minimal, full, no-chat)")
+	testCmd.Flags().Bool("list-endpoint-sets", false, "List the endpoint sets available for --endpoint-set")
 	testCmd.Flags().Duration("timeout", 30*time.Second, "Request timeout")
 	testCmd.Flags().Bool("json", false, "Output results in JSON format")
+	testCmd.Flags().Bool("fail-on-error", false, "Exit with status 1 if any tested configuration fails")
 	testCmd.Flags().IntP("retry", "r", 0, "Retry on failure (-1=infinite, 0=disabled, N=max retry count)")
 	testCmd.Flags().Duration("retry-interval", 2*time.Second, "Interval between retries")
+	testCmd.Flags().Bool("isolated", true, "Run the Claude CLI chat test with a temporary HOME")
+	testCmd.Flags().Bool("no-isolate", false, "Run the Claude CLI chat test against the real ~/.claude")
+	testCmd.Flags().Bool("allow-header-override", false, "Let ANTHROPIC_CUSTOM_HEADERS replace the Host, Authorization and x-api-key headers")
+	testCmd.Flags().String("diagnostic-bundle", "", "Write a redacted diagnostic report of the test to this JSON file")
+	testCmd.RegisterFlagCompletionFunc("endpoint", completeTestEndpoints)
+	testCmd.RegisterFlagCompletionFunc("endpoint-set", completeEndpointSets)
+}
+
+// completeTestEndpoints completes the endpoints --endpoint accepts
+func completeTestEndpoints(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return filterCompletions(config.TestEndpoints, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeEndpointSets completes endpoint set names, including those defined on the
+// configuration named on the command line
+func completeEndpointSets(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cm, err := config.NewConfigManagerNoInit()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	profileName := ""
+	if len(args) > 0 {
+		profileName = args[0]
+	}
+	sets, err := cm.ListEndpointSets(profileName)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names := make([]string, 0, len(sets))
+	for _, set := range sets {
+		names = append(names, set.Name)
+	}
+	return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
 }
 
 func runTest(cmd *cobra.Command, args []string) error {
@@ -67,36 +154,41 @@ func runTest(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to initialize config manager: %w", err)
 	}
 
+	manager := ccswitch.FromConfigManager(configManager)
 	configHandler := handler.NewConfigHandler(configManager)
 
+	// Ctrl+C stops the remaining tests; results gathered so far are still reported
+	ctx, stop := interruptContext(cmd.Context())
+	defer stop()
+
 	// Parse flags
 	interactiveFlag, _ := cmd.Flags().GetBool("interactive")
 	currentFlag, _ := cmd.Flags().GetBool("current")
 	allFlag, _ := cmd.Flags().GetBool("all")
+	filePath, _ := cmd.Flags().GetString("file")
+	bundlePath, _ := cmd.Flags().GetString("diagnostic-bundle")
 
 	// Validate flag combinations
-	flagCount := 0
-	if currentFlag {
-		flagCount++
-	}
-	if allFlag {
-		flagCount++
-	}
-	if len(args) > 0 {
-		flagCount++
+	if err := checkFlagRules(cmd, testFlagRules); err != nil {
+		return err
 	}
-
-	if flagCount > 1 {
-		return fmt.Errorf("cannot use multiple operation flags together")
+	if err := checkNameWithFlags(cmd, args, "current", "all", "file"); err != nil {
+		return err
 	}
+	args = resolveAliasArg(configManager, args)
 
-	if (currentFlag || allFlag) && interactiveFlag {
-		return fmt.Errorf("cannot use operation flags with -i/--interactive")
+	if listSets, _ := cmd.Flags().GetBool("list-endpoint-sets"); listSets {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		return listEndpointSets(configHandler, endpointSetProfile(configHandler, args, currentFlag), jsonOutput)
 	}
 
 	// Parse test options
 	retryCount, _ := cmd.Flags().GetInt("retry")
 	retryInterval, _ := cmd.Flags().GetDuration("retry-interval")
+	isolated, _ := cmd.Flags().GetBool("isolated")
+	noIsolate, _ := cmd.Flags().GetBool("no-isolate")
+	allowHeaderOverride, _ := cmd.Flags().GetBool("allow-header-override")
+	testFailOnError, _ = cmd.Flags().GetBool("fail-on-error")
 
 	options := handler.TestOptions{
 		Quick:         cmd.Flag("quick").Value.String() == "true",
@@ -106,21 +198,39 @@ func runTest(cmd *cobra.Command, args []string) error {
 		RetryEnabled:  retryCount != 0,
 		MaxRetries:    retryCount,
 		RetryInterval: retryInterval,
+		NoIsolate:     !isolated || noIsolate,
+
+		AllowHeaderOverride: allowHeaderOverride,
 	}
 
 	// Parse endpoint filter if provided (supports: basic, auth, models, chat)
-	if endpoint := strings.TrimSpace(strings.ToLower(cmd.Flag("endpoint").Value.String())); endpoint != "" {
-		switch endpoint {
-		case "basic", "auth", "models", "chat":
-			options.Endpoints = []string{endpoint}
-		default:
-			return fmt.Errorf("invalid endpoint '%s', valid values: basic, auth, models, chat", endpoint)
+	endpointSet, _ := cmd.Flags().GetString("endpoint-set")
+	if endpoint := cmd.Flag("endpoint").Value.String(); endpoint != "" {
+		normalized, err := config.NormalizeTestEndpoint(endpoint)
+		if err != nil {
+			return err
+		}
+		options.Endpoints = []string{normalized}
+	}
+
+	// Expand a named endpoint set; sets defined on a profile apply when that profile is named or current
+	if endpointSet != "" {
+		profileName := endpointSetProfile(configHandler, args, currentFlag)
+		if options.Endpoints, err = configHandler.ResolveEndpointSet(endpointSet, profileName); err != nil {
+			return err
 		}
 	}
 
+	if bundlePath != "" && len(args) == 0 && !currentFlag {
+		return fmt.Errorf("--diagnostic-bundle requires a profile name or -c/--current")
+	}
+	if bundlePath != "" && config.DryRun() {
+		return config.Invalidf("--diagnostic-bundle cannot be used with --dry-run because it writes a file outside ~/.claude")
+	}
+
 	// Create UI provider based on mode
 	var uiProvider ui.UIProvider
-	if !currentFlag && !allFlag && ui.NewInteractiveUI().DetectMode(interactiveFlag, args) == ui.Interactive {
+	if !currentFlag && !allFlag && filePath == "" && ui.NewInteractiveUI().DetectMode(interactiveFlag, args) == ui.Interactive {
 		uiProvider = ui.NewInteractiveUI()
 	} else {
 		uiProvider = ui.NewCLIUI()
@@ -128,21 +238,35 @@ func runTest(cmd *cobra.Command, args []string) error {
 
 	// Handle special operations
 	if allFlag {
-		return runTestAll(configHandler, uiProvider, options)
+		return runTestAll(ctx, manager, uiProvider, options)
+	}
+
+	if bundlePath != "" {
+		profileName := ""
+		if len(args) > 0 {
+			profileName = args[0]
+		} else if profileName, err = configHandler.GetCurrentConfigurationForOperation(); err != nil {
+			return err
+		}
+		return runTestBundle(ctx, configHandler, uiProvider, profileName, bundlePath, options)
 	}
 
 	if currentFlag {
-		return runTestCurrent(configHandler, uiProvider, options)
+		return runTestCurrent(ctx, manager, uiProvider, options)
+	}
+
+	if filePath != "" {
+		return runTestFile(ctx, configHandler, uiProvider, filePath, options)
 	}
 
 	// Execute normal test operation
-	return executeTest(configHandler, uiProvider, args, options)
+	return executeTest(ctx, manager, uiProvider, args, options)
 }
 
 // executeTest handles the test operation with the given dependencies
-func executeTest(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, args []string, options handler.TestOptions) error {
+func executeTest(ctx context.Context, manager *ccswitch.Manager, uiProvider ui.UIProvider, args []string, options handler.TestOptions) error {
 	// Get all configurations for interactive mode
-	profiles, err := configHandler.ListConfigs()
+	profiles, err := manager.List(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to list profiles: %w", err)
 	}
@@ -178,68 +302,219 @@ func executeTest(configHandler handler.ConfigHandler, uiProvider ui.UIProvider,
 		targetName = args[0]
 	}
 
-	return runTestSingle(configHandler, uiProvider, targetName, options)
+	return runTestSingle(ctx, manager, uiProvider, targetName, options)
 }
 
 // runTestCurrent tests the current configuration
-func runTestCurrent(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, options handler.TestOptions) error {
+func runTestCurrent(ctx context.Context, manager *ccswitch.Manager, uiProvider ui.UIProvider, options handler.TestOptions) error {
 	if !options.JSONOutput {
-		uiProvider.ShowInfo("Testing current configuration...")
+		uiProvider.ShowInfo(ui.Text("test.testing_current"))
 	}
 
-	result, err := withRetry(func() (*handler.APITestResult, error) {
-		return configHandler.TestCurrentConfiguration(options)
+	result, err := withRetry(ctx, func() (*handler.APITestResult, error) {
+		return manager.TestCurrent(ctx, options)
 	}, options, uiProvider)
 
 	if err != nil {
 		return fmt.Errorf("failed to test current configuration: %w", err)
 	}
 
-	return displaySingleResultWithUI(uiProvider, result, options)
+	if err := displaySingleResultWithUI(uiProvider, result, options); err != nil {
+		return err
+	}
+	return interruptedError(ctx)
 }
 
-func runTestSingle(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, profileName string, options handler.TestOptions) error {
+func runTestSingle(ctx context.Context, manager *ccswitch.Manager, uiProvider ui.UIProvider, profileName string, options handler.TestOptions) error {
 	if !options.JSONOutput {
-		uiProvider.ShowInfo("Testing configuration: %s", profileName)
+		uiProvider.ShowInfo(ui.Text("test.testing"), profileName)
 	}
 
-	result, err := withRetry(func() (*handler.APITestResult, error) {
-		return configHandler.TestAPIConnectivity(profileName, options)
+	result, err := withRetry(ctx, func() (*handler.APITestResult, error) {
+		return manager.Test(ctx, profileName, options)
 	}, options, uiProvider)
 
 	if err != nil {
 		return fmt.Errorf("failed to test configuration: %w", err)
 	}
 
-	return displaySingleResultWithUI(uiProvider, result, options)
+	if err := displaySingleResultWithUI(uiProvider, result, options); err != nil {
+		return err
+	}
+	return interruptedError(ctx)
 }
 
-func runTestAll(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, options handler.TestOptions) error {
+// runTestBundle tests one configuration and writes a redacted diagnostic bundle of the run
+func runTestBundle(ctx context.Context, configHandler handler.ConfigHandler, uiProvider ui.UIProvider, profileName, bundlePath string, options handler.TestOptions) error {
+	view, err := configHandler.ViewConfig(profileName, false)
+	if err != nil {
+		return err
+	}
+
 	if !options.JSONOutput {
-		uiProvider.ShowInfo("Testing all configurations...")
+		uiProvider.ShowInfo(ui.Text("test.testing"), profileName)
+	}
+
+	result, err := withRetry(ctx, func() (*handler.APITestResult, error) {
+		return configHandler.TestAPIConnectivity(ctx, profileName, options)
+	}, options, uiProvider)
+	if err != nil {
+		return fmt.Errorf("failed to test configuration: %w", err)
+	}
+
+	bundle := handler.DiagnosticBundle{
+		GeneratedAt:     time.Now(),
+		CCSwitchVersion: common.Version,
+		OS:              runtime.GOOS,
+		Arch:            runtime.GOARCH,
+		ClaudeCLI:       configHandler.GetClaudeCLIInfo(),
+		Profile:         profileName,
+		Content:         maskSecretContent(view.Content),
+		Result:          result,
+	}
+
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to format diagnostic bundle: %w", err)
+	}
+	if err := os.WriteFile(bundlePath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write diagnostic bundle: %w", err)
+	}
+
+	if err := displaySingleResultWithUI(uiProvider, result, options); err != nil {
+		return err
+	}
+	if !options.JSONOutput {
+		uiProvider.ShowInfo("Diagnostic bundle written to %s (secrets masked)", bundlePath)
+	}
+	return interruptedError(ctx)
+}
+
+// runTestFile tests a settings file that is not stored as a profile
+func runTestFile(ctx context.Context, configHandler handler.ConfigHandler, uiProvider ui.UIProvider, path string, options handler.TestOptions) error {
+	if !options.JSONOutput {
+		uiProvider.ShowInfo("Testing settings file: %s", path)
+	}
+
+	// Validate up front so a malformed file is reported once instead of being retried
+	var fileErr *handler.SettingsFileError
+	if _, err := handler.ReadSettingsFile(path); errors.As(err, &fileErr) {
+		return displaySettingsFileIssues(fileErr, options)
+	} else if err != nil {
+		return err
+	}
+
+	result, err := withRetry(ctx, func() (*handler.APITestResult, error) {
+		return configHandler.TestSettingsFile(ctx, path, options)
+	}, options, uiProvider)
+
+	if err != nil {
+		return fmt.Errorf("failed to test settings file: %w", err)
+	}
+
+	if err := displaySingleResultWithUI(uiProvider, result, options); err != nil {
+		return err
+	}
+	return interruptedError(ctx)
+}
+
+// endpointSetProfile returns the profile whose own endpoint sets apply to this run,
+// or "" when the run does not target a single known profile
+func endpointSetProfile(configHandler handler.ConfigHandler, args []string, current bool) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	if current {
+		if name, err := configHandler.GetCurrentConfigurationForOperation(); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// listEndpointSets prints the endpoint sets available for --endpoint-set
+func listEndpointSets(configHandler handler.ConfigHandler, profileName string, jsonOutput bool) error {
+	sets, err := configHandler.ListEndpointSets(profileName)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		jsonData, err := json.MarshalIndent(sets, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to format JSON output: %w", err)
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	for _, set := range sets {
+		fmt.Printf("  %-15s %-30s %s\n", set.Name, strings.Join(set.Endpoints, ", "), color.New(color.Faint).Sprint(set.Source))
+	}
+	return nil
+}
+
+// displaySettingsFileIssues reports the validation issues that prevented a settings file from being tested
+func displaySettingsFileIssues(fileErr *handler.SettingsFileError, options handler.TestOptions) error {
+	if options.JSONOutput {
+		jsonData, err := json.MarshalIndent(map[string]interface{}{
+			"file":    fileErr.Path,
+			"healthy": false,
+			"issues":  fileErr.Issues,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to format JSON output: %w", err)
+		}
+		fmt.Println(string(jsonData))
+		return errSilentFailure
+	}
+
+	for _, issue := range fileErr.Issues {
+		printValidationIssue("  ", "", issue)
+	}
+	return fileErr
+}
+
+func runTestAll(ctx context.Context, manager *ccswitch.Manager, uiProvider ui.UIProvider, options handler.TestOptions) error {
+	if !options.JSONOutput {
+		uiProvider.ShowInfo(ui.Text("test.testing_all"))
 		fmt.Println()
 	}
 
 	// If retry is not enabled, use the standard batch test method
 	if !options.RetryEnabled {
-		results, err := configHandler.TestAllConfigurations(options)
-		if err != nil {
+		results, err := manager.TestAll(ctx, options)
+		if err != nil && ctx.Err() == nil {
 			return fmt.Errorf("failed to test configurations: %w", err)
 		}
-		return displayAllResultsWithUI(uiProvider, results, options)
+		if err := displayAllResultsWithUI(uiProvider, results, options); err != nil {
+			return err
+		}
+		return interruptedError(ctx)
 	}
 
 	// With retry enabled, test each configuration individually with retry logic
-	profiles, err := configHandler.ListConfigs()
+	profiles, err := manager.List(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to list profiles: %w", err)
 	}
 
 	results := make([]handler.APITestResult, 0, len(profiles))
 
-	for _, profile := range profiles {
-		result, err := withRetry(func() (*handler.APITestResult, error) {
-			return configHandler.TestAPIConnectivity(profile.Name, options)
+	for i, profile := range profiles {
+		if ctx.Err() != nil {
+			break
+		}
+
+		if profile.Error != "" {
+			results = append(results, handler.SkippedTestResult(profile))
+			handler.EmitTestProgress(profile.Name, i+1, len(profiles), &results[len(results)-1])
+			continue
+		}
+
+		handler.EmitTestProgress(profile.Name, i, len(profiles), nil)
+		result, err := withRetry(ctx, func() (*handler.APITestResult, error) {
+			return manager.Test(ctx, profile.Name, options)
 		}, options, uiProvider)
 
 		if err != nil {
@@ -252,9 +527,22 @@ func runTestAll(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, o
 			}
 		}
 		results = append(results, *result)
+		handler.EmitTestProgress(profile.Name, i+1, len(profiles), result)
 	}
+	handler.FinishTestProgress(results, len(profiles), ctx.Err() != nil)
 
-	return displayAllResultsWithUI(uiProvider, results, options)
+	if err := displayAllResultsWithUI(uiProvider, results, options); err != nil {
+		return err
+	}
+	return interruptedError(ctx)
+}
+
+// interruptedError reports a test run that was cut short by Ctrl+C
+func interruptedError(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("test interrupted, results are partial")
+	}
+	return nil
 }
 
 func displayJSONResult(result *handler.APITestResult) error {
@@ -267,13 +555,27 @@ func displayJSONResult(result *handler.APITestResult) error {
 }
 
 func displayJSONResults(results []handler.APITestResult) error {
+	skippedCount := countSkippedResults(results)
+	testedCount := len(results) - skippedCount
+
+	// Per-profile pass/fail for dashboards; skipped (unreadable) profiles are omitted
+	profiles := make(map[string]bool, testedCount)
+	for _, result := range results {
+		if !result.Skipped {
+			profiles[result.ProfileName] = result.IsConnectable
+		}
+	}
+
 	output := map[string]interface{}{
 		"tested_at": time.Now(),
+		"healthy":   countValidResults(results) == testedCount,
+		"profiles":  profiles,
 		"results":   results,
 		"summary": map[string]interface{}{
-			"total_tested":  len(results),
+			"total_tested":  testedCount,
 			"valid_count":   countValidResults(results),
-			"invalid_count": len(results) - countValidResults(results),
+			"invalid_count": testedCount - countValidResults(results),
+			"skipped_count": skippedCount,
 		},
 	}
 
@@ -288,13 +590,13 @@ func displayJSONResults(results []handler.APITestResult) error {
 func getStatusSymbol(status string) string {
 	switch status {
 	case "success":
-		return "✅"
+		return ui.Sym().Passed
 	case "failed":
-		return "❌"
+		return ui.Sym().Failed
 	case "timeout":
-		return "⏱️"
+		return ui.Sym().Timeout
 	default:
-		return "❓"
+		return ui.Sym().Unknown
 	}
 }
 
@@ -353,10 +655,50 @@ func formatVerboseTestDetails(test handler.EndpointTest) string {
 	if test.Error != "" {
 		details = append(details, fmt.Sprintf("  Error: %s", test.Error))
 	}
+	if len(test.RequestHeaders) > 0 {
+		names := make([]string, 0, len(test.RequestHeaders))
+		for name := range test.RequestHeaders {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		details = append(details, "  Custom Request Headers:")
+		for _, name := range names {
+			details = append(details, fmt.Sprintf("    %s: %s", name, test.RequestHeaders[name]))
+		}
+	}
+	if len(test.Headers) > 0 {
+		names := make([]string, 0, len(test.Headers))
+		for name := range test.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		details = append(details, "  Response Headers:")
+		for _, name := range names {
+			details = append(details, fmt.Sprintf("    %s: %s", name, test.Headers[name]))
+		}
+	}
 
 	return strings.Join(details, "\n")
 }
 
+// formatRateLimit summarizes the quota reported by the API, e.g.
+// "42/50 remaining (anthropic-ratelimit-requests), resets in 12s"
+func formatRateLimit(rateLimit *handler.RateLimit) string {
+	msg := fmt.Sprintf("%d remaining", rateLimit.Remaining)
+	if rateLimit.Limit > 0 {
+		msg = fmt.Sprintf("%d/%d remaining", rateLimit.Remaining, rateLimit.Limit)
+	}
+	msg += fmt.Sprintf(" (%s)", rateLimit.Source)
+	if !rateLimit.Reset.IsZero() {
+		if wait := time.Until(rateLimit.Reset); wait > 0 {
+			msg += ", resets in " + wait.Round(time.Second).String()
+		} else {
+			msg += ", reset"
+		}
+	}
+	return msg
+}
+
 func formatDuration(d time.Duration) string {
 	if d < time.Millisecond {
 		return fmt.Sprintf("%.1fμs", float64(d)/float64(time.Microsecond))
@@ -377,6 +719,16 @@ func countValidResults(results []handler.APITestResult) int {
 	return count
 }
 
+func countSkippedResults(results []handler.APITestResult) int {
+	count := 0
+	for _, result := range results {
+		if result.Skipped {
+			count++
+		}
+	}
+	return count
+}
+
 func parseDuration(s string) time.Duration {
 	d, err := time.ParseDuration(s)
 	if err != nil {
@@ -388,13 +740,27 @@ func parseDuration(s string) time.Duration {
 // UI-based display functions
 func displaySingleResultWithUI(uiProvider ui.UIProvider, result *handler.APITestResult, options handler.TestOptions) error {
 	if options.JSONOutput {
-		return displayJSONResult(result)
+		if err := displayJSONResult(result); err != nil {
+			return err
+		}
+		if testFailOnError && !result.IsConnectable {
+			return errSilentFailure
+		}
+		return nil
 	}
 
+	displaySingleResult(uiProvider, result, options)
+	if testFailOnError && !result.IsConnectable {
+		return fmt.Errorf("configuration '%s' is not functional", result.ProfileName)
+	}
+	return nil
+}
+
+func displaySingleResult(uiProvider ui.UIProvider, result *handler.APITestResult, options handler.TestOptions) {
 	// Display header and handle error case
 	if result.Error != "" {
-		uiProvider.ShowError(fmt.Errorf("❌ %s", result.Error))
-		return nil
+		uiProvider.ShowError(fmt.Errorf("%s %s", ui.Sym().Failed, result.Error))
+		return
 	}
 
 	// Display test results
@@ -416,32 +782,60 @@ func displaySingleResultWithUI(uiProvider ui.UIProvider, result *handler.APITest
 
 	// Display summary
 	if result.IsConnectable {
-		uiProvider.ShowSuccess("✅ Result: Configuration is functional")
-		fmt.Printf("   Total response time: %s\n", formatDuration(result.ResponseTime))
+		uiProvider.ShowSuccess(ui.Text("test.functional"))
+		fmt.Printf(ui.Text("test.total_time")+"\n", formatDuration(result.ResponseTime))
 	} else {
-		uiProvider.ShowError(fmt.Errorf("❌ Result: Configuration has connectivity issues"))
+		uiProvider.ShowError(errors.New(ui.Text("test.not_functional")))
+	}
+	if result.RateLimit != nil {
+		fmt.Printf("Rate limit: %s\n", formatRateLimit(result.RateLimit))
+	}
+	if options.Verbose && result.Proxy != "" {
+		fmt.Printf("Proxy: %s\n", result.Proxy)
 	}
-
-	return nil
 }
 
 func displayAllResultsWithUI(uiProvider ui.UIProvider, results []handler.APITestResult, options handler.TestOptions) error {
+	failedCount := len(results) - countSkippedResults(results) - countValidResults(results)
+
 	if options.JSONOutput {
-		return displayJSONResults(results)
+		if err := displayJSONResults(results); err != nil {
+			return err
+		}
+		if testFailOnError && failedCount > 0 {
+			return errSilentFailure
+		}
+		return nil
 	}
 
+	displayAllResults(uiProvider, results, options)
+
+	if testFailOnError && failedCount > 0 {
+		return fmt.Errorf("%d configuration(s) failed the test", failedCount)
+	}
+	return nil
+}
+
+func displayAllResults(uiProvider ui.UIProvider, results []handler.APITestResult, options handler.TestOptions) {
+
 	validCount := 0
-	totalCount := len(results)
+	skippedCount := countSkippedResults(results)
+	totalCount := len(results) - skippedCount
 
 	for _, result := range results {
-		symbol := "❌"
+		if result.Skipped {
+			uiProvider.ShowWarning("%-20s %s  Skipped (%s)", result.ProfileName, ui.Sym().Skipped, result.Error)
+			continue
+		}
+
+		symbol := ui.Sym().Failed
 		status := "Invalid"
 		details := ""
 
 		if result.Error != "" {
 			details = fmt.Sprintf(" (%s)", result.Error)
 		} else if result.IsConnectable {
-			symbol = "✅"
+			symbol = ui.Sym().Passed
 			status = "Valid"
 			validCount++
 			if !options.Quick {
@@ -452,7 +846,7 @@ func displayAllResultsWithUI(uiProvider ui.UIProvider, results []handler.APITest
 					}
 				}
 				if successCount < len(result.Tests) {
-					symbol = "⚠️"
+					symbol = ui.Sym().Warning
 					status = fmt.Sprintf("Valid with warnings (%d/%d tests passed)", successCount, len(result.Tests))
 				}
 			}
@@ -487,24 +881,32 @@ func displayAllResultsWithUI(uiProvider ui.UIProvider, results []handler.APITest
 					uiProvider.ShowError(fmt.Errorf("%s", testMsg))
 				}
 			}
+			if result.RateLimit != nil {
+				fmt.Printf("  └─ Rate limit: %s\n", formatRateLimit(result.RateLimit))
+			}
+			if result.Proxy != "" {
+				fmt.Printf("  └─ Proxy: %s\n", result.Proxy)
+			}
 		}
 	}
 
 	// Display summary
-	summaryMsg := fmt.Sprintf("Summary: %d/%d configurations functional", validCount, totalCount)
+	summaryMsg := fmt.Sprintf(ui.Text("test.summary"), validCount, totalCount)
+	if skippedCount > 0 {
+		summaryMsg += fmt.Sprintf(ui.Text("test.summary_skipped"), skippedCount)
+	}
 	if validCount == totalCount {
-		uiProvider.ShowSuccess("✅ %s", summaryMsg)
+		uiProvider.ShowSuccess("%s %s", ui.Sym().Passed, summaryMsg)
 	} else if validCount > 0 {
-		uiProvider.ShowWarning("⚠️  %s", summaryMsg)
+		uiProvider.ShowWarning("%s  %s", ui.Sym().Warning, summaryMsg)
 	} else {
-		uiProvider.ShowError(fmt.Errorf("❌ %s", summaryMsg))
+		uiProvider.ShowError(fmt.Errorf("%s %s", ui.Sym().Failed, summaryMsg))
 	}
-
-	return nil
 }
 
 // withRetry wraps a test function with retry logic
 func withRetry(
+	ctx context.Context,
 	testFunc func() (*handler.APITestResult, error),
 	options handler.TestOptions,
 	uiProvider ui.UIProvider,
@@ -526,8 +928,8 @@ func withRetry(
 		// Check if test succeeded first
 		testSucceeded := err == nil && result != nil && result.IsConnectable
 
-		// Determine if we should retry
-		shouldRetry := isInfinite || attempt < maxRetries
+		// Determine if we should retry; a cancelled run is never retried
+		shouldRetry := (isInfinite || attempt < maxRetries) && ctx.Err() == nil
 
 		// In verbose mode, show detailed test results only for failed attempts that will retry
 		// (final result, whether success or failure, will be displayed by displaySingleResultWithUI)
@@ -549,13 +951,13 @@ func withRetry(
 		// Return if test succeeded
 		if testSucceeded {
 			if attempt > 1 && !options.JSONOutput {
-				uiProvider.ShowSuccess("✅ Test succeeded on attempt %d", attempt)
+				uiProvider.ShowSuccess("%s Test succeeded on attempt %d", ui.Sym().Passed, attempt)
 			}
 			return result, nil
 		}
 		if !shouldRetry {
-			if !options.JSONOutput {
-				uiProvider.ShowError(fmt.Errorf("❌ Test failed after %d attempts", attempt))
+			if !options.JSONOutput && ctx.Err() == nil {
+				uiProvider.ShowError(fmt.Errorf("%s Test failed after %d attempts", ui.Sym().Failed, attempt))
 			}
 			return result, err
 		}
@@ -563,13 +965,17 @@ func withRetry(
 		// Show retry message
 		if !options.JSONOutput {
 			if isInfinite {
-				uiProvider.ShowWarning("⚠️  Attempt %d failed, retrying in %s...", attempt, options.RetryInterval)
+				uiProvider.ShowWarning("%s  Attempt %d failed, retrying in %s...", ui.Sym().Warning, attempt, options.RetryInterval)
 			} else {
-				uiProvider.ShowWarning("⚠️  Attempt %d/%d failed, retrying in %s...", attempt, maxRetries, options.RetryInterval)
+				uiProvider.ShowWarning("%s  Attempt %d/%d failed, retrying in %s...", ui.Sym().Warning, attempt, maxRetries, options.RetryInterval)
 			}
 		}
 
 		// Wait before retry
-		time.Sleep(options.RetryInterval)
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(options.RetryInterval):
+		}
 	}
 }
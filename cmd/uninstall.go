@@ -2,20 +2,36 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/HoBeedzc/cc-switch/internal/common"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/export"
+
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
+// npmPackage is the npm package cc-switch is distributed as
+const npmPackage = "@hobeeliu/cc-switch"
+
 var (
-	uninstallFull   bool
-	uninstallYes    bool
-	uninstallDryRun bool
+	uninstallFull    bool
+	uninstallYes     bool
+	uninstallDryRun  bool
+	uninstallNoBak   bool
+	uninstallKeepNpm bool
 )
 
 var uninstallCmd = &cobra.Command{
@@ -28,9 +44,22 @@ By default, this command will:
   - Remove internal state files (.current, .history, etc.)
   - Remove templates directory
   - Keep your configuration profiles (~/.claude/profiles/*.json)
+  - Keep stored secrets, profile metadata and cc-switch settings
   - Keep current settings.json (Claude Code will continue working)
 
-Use --full to also remove all configuration profiles (but still keeps settings.json).`,
+Use --full to also remove all configuration profiles (but still keeps settings.json).
+Before anything is removed in full mode, every readable profile is exported to
+~/cc-switch-final-backup-<timestamp>.ccx (restore it with 'cc-switch import').
+You are asked for an optional password; with --yes or without a terminal the
+backup is written unencrypted. Stored secrets are only included in an encrypted
+backup, so when a profile references one (@secret:) the password is required and
+nothing is removed without it. Use --no-backup to skip the backup.
+
+For an npm installation, 'npm uninstall -g @hobeeliu/cc-switch' runs first and
+cc-switch's files are only cleaned up when it succeeds, so a failed npm step
+leaves everything as it was. --dry-run prints the exact npm command and the npm
+global prefix. Use --keep-npm to remove only cc-switch's data and leave the npm
+package installed.`,
 	RunE: runUninstall,
 }
 
@@ -38,6 +67,8 @@ func init() {
 	uninstallCmd.Flags().BoolVarP(&uninstallFull, "full", "f", false, "Remove all configurations (profiles)")
 	uninstallCmd.Flags().BoolVarP(&uninstallYes, "yes", "y", false, "Skip confirmation prompt")
 	uninstallCmd.Flags().BoolVar(&uninstallDryRun, "dry-run", false, "Preview what would be removed without actually removing anything")
+	uninstallCmd.Flags().BoolVar(&uninstallNoBak, "no-backup", false, "Do not export a final backup of all profiles before --full removal")
+	uninstallCmd.Flags().BoolVar(&uninstallKeepNpm, "keep-npm", false, "Skip 'npm uninstall' and only remove cc-switch's data")
 }
 
 func runUninstall(cmd *cobra.Command, args []string) error {
@@ -49,13 +80,22 @@ func runUninstall(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get home directory: %w", err)
 	}
 
-	claudeDir := filepath.Join(homeDir, ".claude")
+	layout, err := common.DefaultLayout()
+	if err != nil {
+		return fmt.Errorf("failed to get home directory: %w", err)
+	}
+	claudeDir := layout.ClaudeDir
 	ccSwitchBinDir := filepath.Join(claudeDir, "cc-switch")
-	profilesDir := filepath.Join(claudeDir, "profiles")
+	profilesDir := layout.ProfilesDir
 
 	// Detect installation method
 	installMethod := detectInstallMethod()
 
+	backupPath := ""
+	if uninstallFull && !uninstallNoBak {
+		backupPath = filepath.Join(homeDir, fmt.Sprintf("cc-switch-final-backup-%s.ccx", time.Now().Format("20060102-150405")))
+	}
+
 	// Show confirmation
 	if !uninstallYes || uninstallDryRun {
 		fmt.Println()
@@ -66,14 +106,27 @@ func runUninstall(cmd *cobra.Command, args []string) error {
 		}
 		fmt.Println()
 		fmt.Println("This will:")
-		fmt.Printf("  ✓ Remove cc-switch binary (%s)\n", ccSwitchBinDir)
+		if backupPath != "" {
+			fmt.Printf("  ✓ Export all readable profiles to a final backup (%s)\n", backupPath)
+		} else if uninstallFull {
+			fmt.Println("  ✗ Skip the final backup (--no-backup)")
+		}
+		if installMethod == "npm" && uninstallKeepNpm {
+			fmt.Println("  ✗ Keep the npm package installed (--keep-npm)")
+		} else {
+			fmt.Printf("  ✓ Remove cc-switch binary (%s)\n", ccSwitchBinDir)
+		}
 		fmt.Println("  ✓ Remove internal state files (.current, .history, etc.)")
 		fmt.Println("  ✓ Remove templates directory")
+		if layout.XDG && uninstallFull {
+			fmt.Printf("  ✓ Remove the XDG state and cache directories (%s, %s)\n", layout.DataDir, layout.CacheDir)
+		}
 
 		if uninstallFull {
 			fmt.Printf("  ✓ Remove all configuration profiles (%s/*.json)\n", profilesDir)
 		} else {
 			fmt.Printf("  ✗ Keep your configuration profiles (%s/*.json)\n", profilesDir)
+			fmt.Println("  ✗ Keep stored secrets, profile metadata and cc-switch settings")
 		}
 
 		fmt.Println("  ✗ Keep current settings.json (Claude Code will continue working)")
@@ -81,7 +134,17 @@ func runUninstall(cmd *cobra.Command, args []string) error {
 
 		if installMethod == "npm" {
 			fmt.Println("Detected: npm installation")
-			fmt.Println("Will run: npm uninstall -g @hobeeliu/cc-switch")
+			if prefix, err := npmGlobalPrefix(); err != nil {
+				fmt.Printf("Global prefix: unknown (%v)\n", err)
+			} else {
+				fmt.Printf("Global prefix: %s\n", prefix)
+			}
+			if uninstallKeepNpm {
+				fmt.Println("Will skip: npm uninstall (--keep-npm)")
+			} else {
+				fmt.Printf("Will run first: %s\n", strings.Join(npmCommand("uninstall", "-g", npmPackage).Args, " "))
+				fmt.Println("Files are only cleaned up if npm succeeds.")
+			}
 			fmt.Println()
 		}
 
@@ -106,27 +169,38 @@ func runUninstall(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Back up every readable profile before anything is deleted; abort if that fails
+	if backupPath != "" {
+		if err := writeFinalBackup(backupPath); err != nil {
+			return fmt.Errorf("final backup failed, nothing was removed: %w (use --no-backup to uninstall without a backup)", err)
+		}
+	}
+
 	fmt.Println()
 	fmt.Println("🧹 Cleaning up...")
 
-	// Step 1: Clean up configuration files
-	if err := cleanupConfigFiles(profilesDir, uninstallFull); err != nil {
-		fmt.Printf("  ⚠ Warning: %v\n", err)
-	}
-
-	// Step 2: Uninstall based on installation method
-	if installMethod == "npm" {
-		// For npm installation, run npm uninstall
-		// This will trigger preuninstall.js which handles binary cleanup
+	// Step 1: Remove the npm package first, so a failure leaves cc-switch's files untouched.
+	// This also triggers preuninstall.js, which removes the binary directory.
+	if installMethod == "npm" && !uninstallKeepNpm {
 		fmt.Println("  ✓ Detected npm installation")
-		fmt.Println("  → Running: npm uninstall -g @hobeeliu/cc-switch")
+		fmt.Printf("  → Running: npm uninstall -g %s\n", npmPackage)
 		fmt.Println()
 
 		if err := uninstallNpm(); err != nil {
-			return fmt.Errorf("npm uninstall failed: %w", err)
+			if backupPath != "" {
+				return fmt.Errorf("npm uninstall failed, no files were removed (the final backup is at %s): %w", backupPath, err)
+			}
+			return fmt.Errorf("npm uninstall failed, no files were removed: %w", err)
 		}
-	} else {
-		// For direct binary installation, delete the binary
+	}
+
+	// Step 2: Clean up configuration files
+	if err := cleanupConfigFiles(layout, uninstallFull); err != nil {
+		fmt.Printf("  ⚠ Warning: %v\n", err)
+	}
+
+	// Step 3: For a direct binary installation, delete the binary
+	if installMethod != "npm" {
 		if err := cleanupBinary(ccSwitchBinDir); err != nil {
 			fmt.Printf("  ⚠ Warning: %v\n", err)
 		}
@@ -166,6 +240,84 @@ func runUninstall(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// writeFinalBackup exports every readable profile to path before a full uninstall.
+// Profiles that cannot be read or parsed are skipped so a partially broken setup
+// can still be backed up.
+func writeFinalBackup(path string) error {
+	cm, err := config.NewConfigManagerNoInit()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(cm.GetProfilesDir()); os.IsNotExist(err) {
+		fmt.Println("  ✓ No profiles to back up")
+		return nil
+	}
+
+	password := ""
+	if !uninstallYes && term.IsTerminal(int(syscall.Stdin)) {
+		fmt.Println()
+		password, err = promptForPassword("Password for the final backup (leave empty for no encryption): ")
+		if err != nil {
+			return fmt.Errorf("failed to read password: %w", err)
+		}
+	}
+
+	// Stored secrets only go into an encrypted backup; without a password, profiles that
+	// reference them could not be restored once the secrets store is removed
+	if password == "" {
+		if names := profilesWithSecretReferences(cm); len(names) > 0 {
+			return config.Invalidf("%s reference stored secrets, which are only kept in a password-protected backup; run 'cc-switch uninstall --full' in a terminal without --yes and set a password", strings.Join(names, ", "))
+		}
+	}
+
+	exporter := export.NewExporter(cm)
+	// Secrets are removed with the profiles directory, so keep them in an encrypted backup
+	exporter.SetIncludeSecrets(password != "" && cm.SecretsStoreExists())
+
+	exported, skipped, err := exporter.ExportReadable(password, path)
+	if err != nil {
+		return err
+	}
+	if len(exported) == 0 {
+		fmt.Println("  ✓ No readable profiles to back up")
+		return nil
+	}
+
+	fmt.Println()
+	color.New(color.FgGreen, color.Bold).Printf("💾 Final backup of %d profile(s) saved to:\n", len(exported))
+	color.New(color.Bold).Printf("   %s\n", path)
+	fmt.Println("   Restore it later with: cc-switch import " + path)
+	if len(skipped) > 0 {
+		color.Yellow("   ⚠ %d unreadable profile(s) not included: %s", len(skipped), strings.Join(skipped, ", "))
+	}
+	if password == "" {
+		color.Yellow("   ⚠ The backup is NOT encrypted and contains your API tokens; keep it somewhere safe")
+	}
+	return nil
+}
+
+// profilesWithSecretReferences returns the readable profiles holding @secret: references
+func profilesWithSecretReferences(cm *config.ConfigManager) []string {
+	profiles, err := cm.ListProfiles()
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, profile := range profiles {
+		if profile.Error != "" {
+			continue
+		}
+		content, _, err := cm.GetProfileContent(profile.Name)
+		if err != nil {
+			continue
+		}
+		if len(config.FindSecretReferences(content)) > 0 {
+			names = append(names, profile.Name)
+		}
+	}
+	return names
+}
+
 // detectInstallMethod detects whether cc-switch was installed via npm or directly
 func detectInstallMethod() string {
 	// Method 1: Check current executable path
@@ -191,7 +343,17 @@ func detectInstallMethod() string {
 }
 
 // cleanupConfigFiles removes cc-switch internal files and optionally all profiles
-func cleanupConfigFiles(profilesDir string, full bool) error {
+func cleanupConfigFiles(layout common.Layout, full bool) error {
+	profilesDir := layout.ProfilesDir
+	if layout.XDG && full {
+		// With the XDG layout internal files live outside the profiles directory
+		for _, dir := range []string{layout.DataDir, layout.CacheDir} {
+			if err := os.RemoveAll(dir); err == nil {
+				fmt.Printf("  ✓ Removed: %s\n", dir)
+			}
+		}
+	}
+
 	if _, err := os.Stat(profilesDir); os.IsNotExist(err) {
 		fmt.Println("  ✓ No profiles directory found")
 		return nil
@@ -204,30 +366,36 @@ func cleanupConfigFiles(profilesDir string, full bool) error {
 		}
 		fmt.Printf("  ✓ Removed profiles directory: %s\n", profilesDir)
 	} else {
-		// Partial cleanup: only remove internal files
-		internalFiles := []string{
-			".current",
-			".history",
-			".empty_mode",
-			".empty_backup_settings.json",
-			".update_check",
-		}
-
-		for _, file := range internalFiles {
-			filePath := filepath.Join(profilesDir, file)
-			if err := os.Remove(filePath); err == nil {
+		// Partial cleanup: only remove transient state; secrets, metadata and the
+		// global config are user data and stay so a reinstall can pick them up
+		for _, file := range config.TransientStateEntries {
+			if err := os.Remove(layout.DataFile(file)); err == nil {
 				fmt.Printf("  ✓ Removed: %s\n", file)
 			}
 		}
+		if err := os.Remove(layout.CacheFile(common.UpdateCacheFileName)); err == nil {
+			fmt.Printf("  ✓ Removed: %s\n", common.UpdateCacheFileName)
+		}
 
 		// Remove templates directory
-		templatesDir := filepath.Join(profilesDir, "templates")
+		templatesDir := layout.TemplatesDir(common.TemplatesDirName())
 		if _, err := os.Stat(templatesDir); err == nil {
 			if err := os.RemoveAll(templatesDir); err == nil {
 				fmt.Println("  ✓ Removed templates directory")
 			}
 		}
 
+		// The XDG data directory and its pointer file go only when nothing is left in it,
+		// otherwise the kept files could no longer be found
+		if layout.XDG {
+			if err := os.Remove(layout.DataDir); err == nil {
+				fmt.Printf("  ✓ Removed: %s\n", layout.DataDir)
+				if err := os.Remove(layout.PointerFile()); err == nil {
+					fmt.Printf("  ✓ Removed: %s\n", common.XDGPointerFileName)
+				}
+			}
+		}
+
 		// Try to remove profiles directory if empty
 		entries, _ := os.ReadDir(profilesDir)
 		if len(entries) == 0 {
@@ -293,19 +461,50 @@ del "%%~f0"
 	return nil
 }
 
-// uninstallNpm runs npm uninstall -g @hobeeliu/cc-switch
-func uninstallNpm() error {
-	var cmd *exec.Cmd
-
+// npmCommand builds an npm invocation; on Windows npm is a batch file and runs through cmd
+func npmCommand(args ...string) *exec.Cmd {
 	if runtime.GOOS == "windows" {
-		cmd = exec.Command("cmd", "/c", "npm", "uninstall", "-g", "@hobeeliu/cc-switch")
-	} else {
-		cmd = exec.Command("npm", "uninstall", "-g", "@hobeeliu/cc-switch")
+		return exec.Command("cmd", append([]string{"/c", "npm"}, args...)...)
+	}
+	return exec.Command("npm", args...)
+}
+
+// npmGlobalPrefix returns the directory global npm packages are installed under
+func npmGlobalPrefix() (string, error) {
+	output, err := npmCommand("prefix", "-g").Output()
+	if err != nil {
+		return "", err
 	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// uninstallNpm runs npm uninstall -g @hobeeliu/cc-switch. npm's output is shown as it
+// runs; on failure the exit code and the end of its stderr are included in the error.
+func uninstallNpm() error {
+	cmd := npmCommand("uninstall", "-g", npmPackage)
 
+	var stderr bytes.Buffer
 	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
 
-	return cmd.Run()
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+
+	detail := strings.TrimSpace(stderr.String())
+	if lines := strings.Split(detail, "\n"); len(lines) > 5 {
+		detail = strings.Join(lines[len(lines)-5:], "\n")
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr) && detail != "":
+		return fmt.Errorf("'%s' exited with status %d:\n%s", strings.Join(cmd.Args, " "), exitErr.ExitCode(), detail)
+	case errors.As(err, &exitErr):
+		return fmt.Errorf("'%s' exited with status %d", strings.Join(cmd.Args, " "), exitErr.ExitCode())
+	default:
+		return fmt.Errorf("failed to run '%s': %w", strings.Join(cmd.Args, " "), err)
+	}
 }
 
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/HoBeedzc/cc-switch/internal/common"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+)
+
+func TestPartialUninstallKeepsUserData(t *testing.T) {
+	for _, xdg := range []bool{false, true} {
+		name := "default layout"
+		if xdg {
+			name = "xdg layout"
+		}
+		t.Run(name, func(t *testing.T) {
+			setupHome(t)
+			if xdg {
+				t.Setenv(common.UseXDGEnv, "1")
+			}
+			cm := newTestManager(t)
+			if err := cm.CreateProfileWithContent("work", map[string]interface{}{"model": "work"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := cm.UseProfile("work"); err != nil {
+				t.Fatal(err)
+			}
+			layout, err := common.DefaultLayout()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if layout.XDG != xdg {
+				t.Fatalf("layout.XDG = %v, want %v", layout.XDG, xdg)
+			}
+
+			kept := []string{".secrets.enc", ".config.json", filepath.Join(".meta", "work.json")}
+			for _, name := range append(kept, config.TransientStateEntries...) {
+				path := layout.DataFile(name)
+				if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte("{}"), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := cleanupConfigFiles(layout, false); err != nil {
+				t.Fatalf("cleanupConfigFiles: %v", err)
+			}
+
+			if _, err := os.Stat(filepath.Join(layout.ProfilesDir, "work.json")); err != nil {
+				t.Errorf("profile was removed: %v", err)
+			}
+			for _, name := range kept {
+				if _, err := os.Stat(layout.DataFile(name)); err != nil {
+					t.Errorf("%s was removed: %v", name, err)
+				}
+			}
+			for _, name := range config.TransientStateEntries {
+				if _, err := os.Stat(layout.DataFile(name)); !os.IsNotExist(err) {
+					t.Errorf("%s was kept, want it removed", name)
+				}
+			}
+			if xdg {
+				// Without the pointer the kept files in the data directory could not be found
+				if _, err := os.Stat(layout.PointerFile()); err != nil {
+					t.Errorf("pointer file was removed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestFinalBackupRequiresPasswordForSecrets(t *testing.T) {
+	uninstallYes = true
+	t.Cleanup(func() { uninstallYes = false })
+
+	setupHome(t)
+	cm := newTestManager(t)
+	if err := cm.CreateProfileWithContent("work", map[string]interface{}{
+		"env": map[string]interface{}{"ANTHROPIC_AUTH_TOKEN": "sk-work"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "final.ccx")
+	if err := writeFinalBackup(path); err != nil {
+		t.Fatalf("writeFinalBackup without secret references: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("backup was not written: %v", err)
+	}
+
+	cm.SetSecretsPassphrase("passphrase")
+	if err := cm.SetSecret("vault-token", "sk-vault"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.CreateProfileWithContent("vault", map[string]interface{}{
+		"env": map[string]interface{}{"ANTHROPIC_AUTH_TOKEN": config.SecretRefPrefix + "vault-token"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	path = filepath.Join(t.TempDir(), "final.ccx")
+	err := writeFinalBackup(path)
+	if !errors.Is(err, config.ErrInvalid) || !strings.Contains(err.Error(), "vault") {
+		t.Fatalf("writeFinalBackup with a secret reference: err = %v, want ErrInvalid naming vault", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("backup was written without the referenced secrets")
+	}
+}
@@ -14,7 +14,8 @@ import (
 	"runtime"
 	"strings"
 
-	"cc-switch/internal/common"
+	"github.com/HoBeedzc/cc-switch/internal/common"
+	"github.com/HoBeedzc/cc-switch/internal/config"
 
 	"github.com/spf13/cobra"
 )
@@ -79,7 +80,9 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 	latestVersion := strings.TrimPrefix(release.TagName, "v")
 
 	// Save to cache for future background checks
-	common.SaveUpdateCache(latestVersion)
+	if !config.DryRun() {
+		common.SaveUpdateCache(latestVersion)
+	}
 
 	// Display version info
 	fmt.Printf("\n📦 Current version: %s\n", currentVersion)
@@ -122,7 +125,12 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 	}
 
 	// Perform update
-	return performUpdate(release)
+	if err := performUpdate(release); err != nil {
+		common.FinishProgress("update", 0, 1, 1, common.ResultFailed)
+		return err
+	}
+	common.FinishProgress("update", 1, 1, 0, common.ResultOK)
+	return nil
 }
 
 func fetchLatestRelease() (*GitHubRelease, error) {
@@ -133,7 +141,7 @@ func fetchLatestRelease() (*GitHubRelease, error) {
 	}
 
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	req.Header.Set("User-Agent", "cc-switch/"+common.Version)
+	req.Header.Set("User-Agent", "github.com/HoBeedzc/cc-switch/"+common.Version)
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -293,7 +301,7 @@ func downloadFile(url, destPath string) error {
 	}
 	defer out.Close()
 
-	_, err = io.Copy(out, resp.Body)
+	_, err = io.Copy(out, common.NewProgressReader(resp.Body, "update", filepath.Base(destPath), resp.ContentLength))
 	return err
 }
 
@@ -1,18 +1,31 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 
-	"cc-switch/internal/config"
-	"cc-switch/internal/handler"
-	"cc-switch/internal/ui"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
+	"github.com/HoBeedzc/cc-switch/internal/ui"
+	"github.com/HoBeedzc/cc-switch/pkg/ccswitch"
 
 	"github.com/spf13/cobra"
 )
 
+// preflightTimeout bounds the connectivity check run by --test-before-launch
+const preflightTimeout = 10 * time.Second
+
+var (
+	useTestBeforeLaunch bool
+	useYes              bool
+)
+
 var useCmd = &cobra.Command{
 	Use:   "use [name]",
 	Short: "Switch to a configuration",
@@ -20,22 +33,35 @@ var useCmd = &cobra.Command{
 
 Modes:
 - Interactive: cc-switch use (no arguments) or cc-switch use -i
-- CLI: cc-switch use <name>
+- CLI: cc-switch use <name>, cc-switch use <index> (as numbered by 'cc-switch list')
+  or cc-switch use <prefix> (a prefix matching exactly one configuration)
 - Previous: cc-switch use -p or cc-switch use --previous
-- Empty Mode: cc-switch use -e or cc-switch use --empty
+- Empty Mode: cc-switch use -e or cc-switch use --empty (asks for confirmation; add -y/--yes to skip)
 - Restore: cc-switch use -r or cc-switch use --restore
 - Refresh: cc-switch use -f or cc-switch use --refresh
+- Scratch: cc-switch use --scratch (throwaway copy of the current configuration)
 
 Options:
 - Launch Claude Code: Add -l or --launch to automatically launch Claude Code CLI after switching
+- Pre-flight: Add --test-before-launch (with -l) to run a quick connectivity test first and
+  abort the launch if the API is not reachable
+- Note: Add --note "<text>" to record why you switched (shown in 'cc-switch history')
+- Verify: Add --verify to read back and validate the new settings.json; if it has errors
+  the previous settings.json is restored and the switch is not recorded. The
+  verify_switch setting ('cc-switch config set verify_switch true') turns it on for every switch
 - Pass commands to Claude: Use -- separator to pass additional arguments to Claude CLI
   Example: cc-switch use myconfig -l -- /analyze /build
 
 The interactive mode allows you to browse and select configurations with arrow keys.
-The previous mode switches to the last used configuration.
+The previous mode switches to the most recent configuration other than the current
+one. Entering empty mode and restoring from it does not count as a switch; if you
+left empty mode with 'use <name>', -p enters empty mode again.
 The empty mode temporarily removes all configurations.
 The restore mode restores from empty mode to the previous configuration.
-The refresh mode re-applies the current configuration (useful after manual edits).`,
+The refresh mode re-applies the current configuration (useful after manual edits).
+The scratch mode copies the current configuration (or the default template) into a
+scratch-<timestamp> configuration and switches to it; it is deleted automatically once
+you switch to another configuration. Keep it with 'cc-switch scratch keep <name>'.`,
 	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := checkClaudeConfig(); err != nil {
@@ -48,13 +74,19 @@ The refresh mode re-applies the current configuration (useful after manual edits
 			return fmt.Errorf("failed to initialize config manager: %w", err)
 		}
 
+		manager := ccswitch.FromConfigManager(cm)
 		configHandler := handler.NewConfigHandler(cm)
+		ctx := cmd.Context()
 		interactiveFlag, _ := cmd.Flags().GetBool("interactive")
 		previousFlag, _ := cmd.Flags().GetBool("previous")
 		emptyFlag, _ := cmd.Flags().GetBool("empty")
 		restoreFlag, _ := cmd.Flags().GetBool("restore")
 		refreshFlag, _ := cmd.Flags().GetBool("refresh")
+		scratchFlag, _ := cmd.Flags().GetBool("scratch")
 		launchFlag, _ := cmd.Flags().GetBool("launch")
+		note, _ := cmd.Flags().GetString("note")
+		verifyFlag, _ := cmd.Flags().GetBool("verify")
+		options := ccswitch.UseOptions{Note: note, Verify: verifyFlag || config.VerifySwitch()}
 
 		// Get arguments after -- separator for passing to Claude
 		var claudeArgs []string
@@ -68,36 +100,19 @@ The refresh mode re-applies the current configuration (useful after manual edits
 			// No -- separator, all args are main args
 			mainArgs = args
 		}
+		mainArgs = resolveAliasArg(cm, mainArgs)
 
 		// Validate flag combinations
-		flagCount := 0
-		if previousFlag {
-			flagCount++
-		}
-		if emptyFlag {
-			flagCount++
-		}
-		if restoreFlag {
-			flagCount++
-		}
-		if refreshFlag {
-			flagCount++
-		}
-		if len(mainArgs) > 0 {
-			flagCount++
-		}
-
-		if flagCount > 1 {
-			return fmt.Errorf("cannot use multiple operation flags together")
+		if err := checkFlagRules(cmd, useFlagRules); err != nil {
+			return err
 		}
-
-		if (previousFlag || emptyFlag || restoreFlag || refreshFlag) && interactiveFlag {
-			return fmt.Errorf("cannot use operation flags with -i/--interactive")
+		if err := checkNameWithFlags(cmd, mainArgs, "previous", "empty", "restore", "refresh", "scratch"); err != nil {
+			return err
 		}
 
 		// Create UI provider based on mode
 		var uiProvider ui.UIProvider
-		if !previousFlag && !emptyFlag && !restoreFlag && !refreshFlag && ui.NewInteractiveUI().DetectMode(interactiveFlag, mainArgs) == ui.Interactive {
+		if !previousFlag && !emptyFlag && !restoreFlag && !refreshFlag && !scratchFlag && ui.NewInteractiveUI().DetectMode(interactiveFlag, mainArgs) == ui.Interactive {
 			uiProvider = ui.NewInteractiveUI()
 		} else {
 			uiProvider = ui.NewCLIUI()
@@ -105,11 +120,11 @@ The refresh mode re-applies the current configuration (useful after manual edits
 
 		// Handle special operations
 		if emptyFlag {
-			return handleEmptyMode(configHandler, uiProvider)
+			return handleEmptyMode(ctx, manager, configHandler, uiProvider, useYes)
 		}
 
 		if restoreFlag {
-			return handleRestoreMode(configHandler, uiProvider, launchFlag, claudeArgs)
+			return handleRestoreMode(ctx, manager, configHandler, uiProvider, launchFlag, claudeArgs)
 		}
 
 		if refreshFlag {
@@ -117,34 +132,72 @@ The refresh mode re-applies the current configuration (useful after manual edits
 		}
 
 		if previousFlag {
-			return handlePreviousConfig(configHandler, uiProvider, launchFlag, claudeArgs)
+			return handlePreviousConfig(ctx, manager, configHandler, uiProvider, options, launchFlag, claudeArgs)
+		}
+
+		if scratchFlag {
+			return handleScratchMode(ctx, manager, configHandler, uiProvider, options, launchFlag, claudeArgs)
 		}
 
 		// Execute normal use operation
-		return executeUse(configHandler, uiProvider, mainArgs, launchFlag, claudeArgs)
+		return executeUse(ctx, manager, configHandler, uiProvider, mainArgs, options, launchFlag, claudeArgs)
 	},
 }
 
+// resolveUseTarget maps the argument of 'use' to a configuration name. An exact name
+// always wins, so a configuration named "3" is still reachable; otherwise a number
+// selects by its 1-based position in 'list', and anything else must be the prefix
+// of exactly one configuration. Unmatched arguments are returned unchanged so the
+// switch reports them as missing.
+func resolveUseTarget(profiles []config.Profile, arg string) (string, error) {
+	for _, profile := range profiles {
+		if profile.Name == arg {
+			return arg, nil
+		}
+	}
+
+	if index, err := strconv.Atoi(arg); err == nil {
+		if index < 1 || index > len(profiles) {
+			return "", config.NotFoundf("no configuration at index %d (there are %d)", index, len(profiles))
+		}
+		return profiles[index-1].Name, nil
+	}
+
+	var candidates []string
+	for _, profile := range profiles {
+		if strings.HasPrefix(profile.Name, arg) {
+			candidates = append(candidates, profile.Name)
+		}
+	}
+	if len(candidates) > 1 {
+		return "", config.Invalidf("'%s' matches several configurations: %s", arg, strings.Join(candidates, ", "))
+	}
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+	return arg, nil
+}
+
 // executeUse handles the use operation with the given dependencies
-func executeUse(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, args []string, launchCode bool, claudeArgs []string) error {
+func executeUse(ctx context.Context, manager *ccswitch.Manager, configHandler handler.ConfigHandler, uiProvider ui.UIProvider, args []string, options ccswitch.UseOptions, launchCode bool, claudeArgs []string) error {
 	// Check if currently in empty mode - if so, any use command should restore first
 	if configHandler.IsEmptyMode() {
-		uiProvider.ShowInfo("Currently in empty mode. Restoring settings first...")
-		if err := configHandler.RestoreFromEmptyMode(); err != nil {
+		uiProvider.ShowInfo(ui.Text("use.restoring_empty"))
+		if err := manager.RestoreFromEmptyMode(ctx); err != nil {
 			uiProvider.ShowError(fmt.Errorf("failed to restore from empty mode: %w", err))
 			return err
 		}
-		uiProvider.ShowInfo("Settings restored from empty mode.")
+		uiProvider.ShowInfo(ui.Text("use.restored_empty"))
 	}
 
 	// Get all configurations
-	profiles, err := configHandler.ListConfigs()
+	profiles, err := manager.List(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to list profiles: %w", err)
 	}
 
 	if len(profiles) == 0 {
-		uiProvider.ShowWarning("No configurations found. Use 'cc-switch new <name>' to create your first configuration.")
+		uiProvider.ShowWarning(ui.Text("use.no_configs"))
 		return nil
 	}
 
@@ -163,18 +216,16 @@ func executeUse(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, a
 			// Handle special selections
 			switch selection.Type {
 			case "empty_mode":
-				return handleEmptyMode(configHandler, uiProvider)
+				return handleEmptyMode(ctx, manager, configHandler, uiProvider, useYes)
 			case "restore":
-				return handleRestoreMode(configHandler, uiProvider, launchCode, claudeArgs)
+				return handleRestoreMode(ctx, manager, configHandler, uiProvider, launchCode, claudeArgs)
 			case "profile":
 				// Check if already current
 				if selection.Profile.IsCurrent && !configHandler.IsEmptyMode() {
-					uiProvider.ShowWarning("Configuration '%s' is already active", selection.Profile.Name)
+					uiProvider.ShowWarning(ui.Text("use.already_active"), selection.Profile.Name)
 					// Still allow launching Claude Code if requested
 					if launchCode {
-						if err := launchClaudeCode(uiProvider, claudeArgs); err != nil {
-							uiProvider.ShowWarning("Failed to launch Claude Code: %v. Launch manually with: claude", err)
-						}
+						return launchAfterSwitch(configHandler, uiProvider, claudeArgs)
 					}
 					return nil
 				}
@@ -191,12 +242,10 @@ func executeUse(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, a
 
 			// Check if already current
 			if selected.IsCurrent && !configHandler.IsEmptyMode() {
-				uiProvider.ShowWarning("Configuration '%s' is already active", selected.Name)
+				uiProvider.ShowWarning(ui.Text("use.already_active"), selected.Name)
 				// Still allow launching Claude Code if requested
 				if launchCode {
-					if err := launchClaudeCode(uiProvider, claudeArgs); err != nil {
-						uiProvider.ShowWarning("Failed to launch Claude Code: %v. Launch manually with: claude", err)
-					}
+					return launchAfterSwitch(configHandler, uiProvider, claudeArgs)
 				}
 				return nil
 			}
@@ -205,19 +254,20 @@ func executeUse(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, a
 		}
 	} else {
 		// CLI mode
-		targetName = args[0]
+		targetName, err = resolveUseTarget(profiles, args[0])
+		if err != nil {
+			uiProvider.ShowError(err)
+			return err
+		}
 	}
 
 	// Execute switch
-	if err := configHandler.UseConfig(targetName); err != nil {
-		// Handle specific error messages
-		if err.Error() == fmt.Sprintf("configuration '%s' is already active", targetName) {
-			uiProvider.ShowWarning("Configuration '%s' is already active", targetName)
+	if _, err := manager.Use(ctx, targetName, options); err != nil {
+		if errors.Is(err, ccswitch.ErrAlreadyActive) {
+			uiProvider.ShowWarning(ui.Text("use.already_active"), targetName)
 			// Still allow launching Claude Code if requested
 			if launchCode {
-				if err := launchClaudeCode(uiProvider, claudeArgs); err != nil {
-					uiProvider.ShowWarning("Failed to launch Claude Code: %v. Launch manually with: claude", err)
-				}
+				return launchAfterSwitch(configHandler, uiProvider, claudeArgs)
 			}
 			return nil
 		}
@@ -225,34 +275,48 @@ func executeUse(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, a
 		return err
 	}
 
-	uiProvider.ShowSuccess("Switched to configuration '%s'", targetName)
+	if options.Verify {
+		uiProvider.ShowSuccess(ui.Text("use.switched_verified"), targetName)
+	} else {
+		uiProvider.ShowSuccess(ui.Text("use.switched"), targetName)
+	}
+	warnLocalOverrides(configHandler, targetName)
 
 	// Launch Claude Code if requested
 	if launchCode {
-		if err := launchClaudeCode(uiProvider, claudeArgs); err != nil {
-			uiProvider.ShowWarning("Failed to launch Claude Code: %v. Launch manually with: claude", err)
-		}
+		return launchAfterSwitch(configHandler, uiProvider, claudeArgs)
 	}
 
 	return nil
 }
 
+// handleScratchMode creates a scratch configuration from the current one and switches to it
+func handleScratchMode(ctx context.Context, manager *ccswitch.Manager, configHandler handler.ConfigHandler, uiProvider ui.UIProvider, options ccswitch.UseOptions, launchCode bool, claudeArgs []string) error {
+	name, err := configHandler.CreateScratchConfig()
+	if err != nil {
+		uiProvider.ShowError(err)
+		return err
+	}
+	uiProvider.ShowInfo("Created scratch configuration '%s'; it is removed when you switch away (keep it with 'cc-switch scratch keep %s')", name, name)
+	return executeUse(ctx, manager, configHandler, uiProvider, []string{name}, options, launchCode, claudeArgs)
+}
+
 // handlePreviousConfig handles switching to the previous configuration
-func handlePreviousConfig(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, launchCode bool, claudeArgs []string) error {
+func handlePreviousConfig(ctx context.Context, manager *ccswitch.Manager, configHandler handler.ConfigHandler, uiProvider ui.UIProvider, options ccswitch.UseOptions, launchCode bool, claudeArgs []string) error {
 	// Special handling for empty mode: -p should behave like -r
 	if configHandler.IsEmptyMode() {
 		uiProvider.ShowInfo("In empty mode: using previous (-p) will restore from empty mode")
-		return handleRestoreMode(configHandler, uiProvider, launchCode, claudeArgs)
+		return handleRestoreMode(ctx, manager, configHandler, uiProvider, launchCode, claudeArgs)
 	}
 
 	// Get previous configuration
 	previousName, err := configHandler.GetPreviousConfig()
 	if err != nil {
-		if err.Error() == "no previous configuration available" {
+		if errors.Is(err, config.ErrNoPrevious) {
 			uiProvider.ShowWarning("No previous configuration available. Use 'cc-switch use <name>' to switch configurations first")
 			return nil
 		}
-		if strings.Contains(err.Error(), "no longer exists") {
+		if errors.Is(err, config.ErrNotFound) {
 			uiProvider.ShowWarning(err.Error() + ". The previous configuration has been deleted")
 			return nil
 		}
@@ -263,7 +327,7 @@ func handlePreviousConfig(configHandler handler.ConfigHandler, uiProvider ui.UIP
 	// Special case: if previous is "empty_mode", enter empty mode
 	if previousName == "empty_mode" {
 		uiProvider.ShowInfo("Previous state was empty mode. Entering empty mode...")
-		return handleEmptyMode(configHandler, uiProvider)
+		return handleEmptyMode(ctx, manager, configHandler, uiProvider, useYes)
 	}
 
 	// Get current configuration for display
@@ -276,30 +340,34 @@ func handlePreviousConfig(configHandler handler.ConfigHandler, uiProvider ui.UIP
 	}
 
 	// Execute switch
-	if err := configHandler.UseConfig(previousName); err != nil {
+	if _, err := manager.Use(ctx, previousName, options); err != nil {
 		uiProvider.ShowError(err)
 		return err
 	}
 
 	// Show success message with context
-	if currentName != "" {
-		uiProvider.ShowSuccess("Switched to configuration '%s' (previous: '%s')", previousName, currentName)
-	} else {
-		uiProvider.ShowSuccess("Switched to configuration '%s'", previousName)
-	}
+	switch {
+	case currentName != "" && options.Verify:
+		uiProvider.ShowSuccess(ui.Text("use.previous_verified"), previousName, currentName)
+	case currentName != "":
+		uiProvider.ShowSuccess(ui.Text("use.switched_previous"), previousName, currentName)
+	case options.Verify:
+		uiProvider.ShowSuccess(ui.Text("use.switched_verified"), previousName)
+	default:
+		uiProvider.ShowSuccess(ui.Text("use.switched"), previousName)
+	}
+	warnLocalOverrides(configHandler, previousName)
 
 	// Launch Claude Code if requested
 	if launchCode {
-		if err := launchClaudeCode(uiProvider, claudeArgs); err != nil {
-			uiProvider.ShowWarning("Failed to launch Claude Code: %v. Launch manually with: claude", err)
-		}
+		return launchAfterSwitch(configHandler, uiProvider, claudeArgs)
 	}
 
 	return nil
 }
 
 // handleEmptyMode handles enabling empty mode
-func handleEmptyMode(configHandler handler.ConfigHandler, uiProvider ui.UIProvider) error {
+func handleEmptyMode(ctx context.Context, manager *ccswitch.Manager, configHandler handler.ConfigHandler, uiProvider ui.UIProvider, skipConfirm bool) error {
 	// Check if already in empty mode
 	if configHandler.IsEmptyMode() {
 		uiProvider.ShowWarning("Already in empty mode. Use 'cc-switch use <profile>' to restore or 'cc-switch use --restore' for previous configuration")
@@ -309,24 +377,36 @@ func handleEmptyMode(configHandler handler.ConfigHandler, uiProvider ui.UIProvid
 	// Get current configuration for display
 	currentName, _ := configHandler.GetCurrentConfig()
 
+	// Confirm before removing settings.json from Claude Code's view
+	if !skipConfirm {
+		confirmMsg := "Enter EMPTY MODE? Claude Code settings will be removed until restored"
+		if currentName != "" {
+			confirmMsg = fmt.Sprintf("Deactivate configuration '%s' and enter EMPTY MODE? Claude Code settings will be removed until restored", currentName)
+		}
+		if !uiProvider.ConfirmAction(confirmMsg, false) {
+			uiProvider.ShowInfo(ui.Text("common.cancelled"))
+			return nil
+		}
+	}
+
 	// Enable empty mode
-	if err := configHandler.UseEmptyMode(); err != nil {
+	if err := manager.EnterEmptyMode(ctx); err != nil {
 		uiProvider.ShowError(err)
 		return err
 	}
 
 	// Show success message
 	if currentName != "" {
-		uiProvider.ShowSuccess("Empty mode enabled. Previous: %s. Use 'cc-switch use <profile>' to restore or '--restore' for previous", currentName)
+		uiProvider.ShowSuccess(ui.Text("use.empty_enabled_from"), currentName)
 	} else {
-		uiProvider.ShowSuccess("Empty mode enabled. Use 'cc-switch use <profile>' to restore a configuration")
+		uiProvider.ShowSuccess(ui.Text("use.empty_enabled"))
 	}
 
 	return nil
 }
 
 // handleRestoreMode handles restoring from empty mode
-func handleRestoreMode(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, launchCode bool, claudeArgs []string) error {
+func handleRestoreMode(ctx context.Context, manager *ccswitch.Manager, configHandler handler.ConfigHandler, uiProvider ui.UIProvider, launchCode bool, claudeArgs []string) error {
 	// Check if in empty mode
 	if !configHandler.IsEmptyMode() {
 		uiProvider.ShowWarning("Not in empty mode")
@@ -346,19 +426,18 @@ func handleRestoreMode(configHandler handler.ConfigHandler, uiProvider ui.UIProv
 		return nil
 	}
 
-	// Restore to previous configuration
-	if err := configHandler.RestoreToPreviousFromEmptyMode(); err != nil {
+	// Restore to previous configuration; in empty mode UsePrevious restores what was active before
+	if _, err := manager.UsePrevious(ctx, ccswitch.UseOptions{}); err != nil {
 		uiProvider.ShowError(err)
 		return err
 	}
 
-	uiProvider.ShowSuccess("Restored to previous configuration '%s'", status.PreviousProfile)
+	uiProvider.ShowSuccess(ui.Text("use.restored_previous"), status.PreviousProfile)
+	warnLocalOverrides(configHandler, status.PreviousProfile)
 
 	// Launch Claude Code if requested
 	if launchCode {
-		if err := launchClaudeCode(uiProvider, claudeArgs); err != nil {
-			uiProvider.ShowWarning("Failed to launch Claude Code: %v. Launch manually with: claude", err)
-		}
+		return launchAfterSwitch(configHandler, uiProvider, claudeArgs)
 	}
 
 	return nil
@@ -386,7 +465,7 @@ func handleRefreshMode(configHandler handler.ConfigHandler, uiProvider ui.UIProv
 	}
 
 	// Show refresh message
-	uiProvider.ShowInfo("Refreshing configuration '%s'...", currentName)
+	uiProvider.ShowInfo(ui.Text("use.refreshing"), currentName)
 
 	// Re-apply the current configuration
 	if err := configHandler.UseConfig(currentName); err != nil {
@@ -394,20 +473,91 @@ func handleRefreshMode(configHandler handler.ConfigHandler, uiProvider ui.UIProv
 		return err
 	}
 
-	uiProvider.ShowSuccess("Configuration '%s' refreshed successfully", currentName)
+	uiProvider.ShowSuccess(ui.Text("use.refreshed"), currentName)
+	warnLocalOverrides(configHandler, currentName)
 
 	// Launch Claude Code if requested
 	if launchCode {
-		if err := launchClaudeCode(uiProvider, claudeArgs); err != nil {
-			uiProvider.ShowWarning("Failed to launch Claude Code: %v. Launch manually with: claude", err)
+		return launchAfterSwitch(configHandler, uiProvider, claudeArgs)
+	}
+
+	return nil
+}
+
+// launchAfterSwitch launches Claude Code after a switch. With --test-before-launch the active
+// configuration is checked first and the launch is aborted if it is not connectable.
+func launchAfterSwitch(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, claudeArgs []string) error {
+	if useTestBeforeLaunch {
+		if err := preflightCheck(configHandler, uiProvider); err != nil {
+			return err
+		}
+	}
+
+	if err := launchClaudeCode(uiProvider, claudeArgs, launchDirectory(configHandler, uiProvider)); err != nil {
+		uiProvider.ShowWarning("Failed to launch Claude Code: %v. Launch manually with: claude", err)
+	}
+	return nil
+}
+
+// launchDirectory returns the project directory of the active configuration, or "" to
+// launch in the current directory. A project directory that has since been removed is
+// reported and ignored.
+func launchDirectory(configHandler handler.ConfigHandler, uiProvider ui.UIProvider) string {
+	if configHandler.IsEmptyMode() {
+		return ""
+	}
+	current, err := configHandler.GetCurrentConfig()
+	if err != nil || current == "" {
+		return ""
+	}
+	project, err := configHandler.GetConfigProject(current)
+	if err != nil || project == "" {
+		return ""
+	}
+
+	if info, err := os.Stat(project); err != nil || !info.IsDir() {
+		uiProvider.ShowWarning("Project directory %s of '%s' no longer exists; launching in the current directory", project, current)
+		return ""
+	}
+	return project
+}
+
+// preflightCheck runs a quick connectivity test against the active configuration
+func preflightCheck(configHandler handler.ConfigHandler, uiProvider ui.UIProvider) error {
+	uiProvider.ShowInfo("Testing API connectivity before launch...")
+
+	ctx, stop := interruptContext(context.Background())
+	defer stop()
+
+	result, err := configHandler.TestCurrentConfiguration(ctx, handler.TestOptions{
+		Quick:   true,
+		Timeout: preflightTimeout,
+	})
+	if err != nil {
+		return fmt.Errorf("connectivity check failed: %w. Claude Code was not launched", err)
+	}
+
+	if !result.IsConnectable {
+		reason := result.Error
+		for _, test := range result.Tests {
+			if test.Status != "success" && test.Error != "" {
+				reason = test.Error
+				break
+			}
+		}
+		if reason == "" {
+			reason = "API is not reachable"
 		}
+		return fmt.Errorf("configuration '%s' failed the connectivity check (%s). Claude Code was not launched; run 'cc-switch test %s' for details", result.ProfileName, reason, result.ProfileName)
 	}
 
+	uiProvider.ShowSuccess("API reachable (%dms)", result.ResponseTime.Milliseconds())
 	return nil
 }
 
-// launchClaudeCode launches Claude Code CLI with appropriate error handling
-func launchClaudeCode(uiProvider ui.UIProvider, claudeArgs []string) error {
+// launchClaudeCode launches Claude Code CLI with appropriate error handling, in dir
+// when it is set
+func launchClaudeCode(uiProvider ui.UIProvider, claudeArgs []string, dir string) error {
 	// Try to find Claude Code CLI executable
 	claudePath, err := findClaudeCodeExecutable()
 	if err != nil {
@@ -420,10 +570,14 @@ func launchClaudeCode(uiProvider ui.UIProvider, claudeArgs []string) error {
 	} else {
 		uiProvider.ShowInfo("Starting Claude Code CLI in current terminal... (Press Ctrl+C or type 'exit' to return)")
 	}
+	if dir != "" {
+		uiProvider.ShowInfo("Project directory: %s", dir)
+	}
 	fmt.Println("") // Visual separation
 
 	// Create the command with proper terminal inheritance and additional arguments
 	cmd := exec.Command(claudePath, claudeArgs...)
+	cmd.Dir = dir
 
 	// Inherit the current terminal's stdin, stdout, and stderr
 	// This allows Claude Code to run interactively in the current terminal
@@ -499,11 +653,24 @@ func isClaudeCodeCLI(execPath string) bool {
 	return false
 }
 
+// useFlagRules declares the flag combinations use rejects
+var useFlagRules = [][]flagRule{
+	exclusiveFlags("interactive", "previous", "empty", "restore", "refresh", "scratch"),
+	requiresFlag("test-before-launch", "launch"),
+	conflictsWith("note", "empty", "restore", "refresh"),
+	conflictsWith("verify", "empty", "restore", "refresh"),
+}
+
 func init() {
 	useCmd.Flags().BoolP("interactive", "i", false, "Enter interactive mode")
 	useCmd.Flags().BoolP("previous", "p", false, "Switch to previous configuration")
 	useCmd.Flags().BoolP("empty", "e", false, "Enable empty mode (remove settings)")
 	useCmd.Flags().BoolP("restore", "r", false, "Restore from empty mode to previous configuration")
 	useCmd.Flags().BoolP("refresh", "f", false, "Refresh current configuration (re-apply)")
+	useCmd.Flags().Bool("verify", false, "Check the written settings.json and roll back the switch if it is invalid (always on with the verify_switch setting)")
+	useCmd.Flags().Bool("scratch", false, "Switch to a throwaway copy of the current configuration, deleted when you switch away")
+	useCmd.Flags().BoolVarP(&useYes, "yes", "y", false, "Skip the confirmation prompt when entering empty mode")
 	useCmd.Flags().BoolP("launch", "l", false, "Launch Claude Code CLI after switching")
+	useCmd.Flags().BoolVar(&useTestBeforeLaunch, "test-before-launch", false, "Run a quick connectivity test before launching and abort if it fails")
+	useCmd.Flags().String("note", "", fmt.Sprintf("Record a note with this switch in history (max %d characters)", config.MaxNoteLength))
 }
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
+)
+
+func TestResolveUseTarget(t *testing.T) {
+	var profiles []config.Profile
+	for _, name := range []string{"3", "personal", "work", "work-eu"} {
+		profiles = append(profiles, config.Profile{Name: name})
+	}
+
+	tests := []struct {
+		name string
+		arg  string
+		want string
+		err  error
+	}{
+		{"exact name", "personal", "personal", nil},
+		{"exact name that is also a prefix", "work", "work", nil},
+		{"name that looks like an index", "3", "3", nil},
+		{"index", "2", "personal", nil},
+		{"last index", "4", "work-eu", nil},
+		{"index out of range", "5", "", config.ErrNotFound},
+		{"index zero", "0", "", config.ErrNotFound},
+		{"unique prefix", "pers", "personal", nil},
+		{"ambiguous prefix", "wo", "", config.ErrInvalid},
+		{"no match", "missing", "missing", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveUseTarget(profiles, tt.arg)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("resolveUseTarget(%q) error = %v, want %v", tt.arg, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveUseTarget(%q): %v", tt.arg, err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveUseTarget(%q) = %q, want %q", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUseByIndexAndPrefix(t *testing.T) {
+	setupHome(t)
+	cm := newTestManager(t)
+	for _, name := range []string{"3", "alpha", "personal", "work", "work-eu"} {
+		if err := cm.CreateProfileWithContent(name, map[string]interface{}{"model": name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// listed returns the configurations in the order 'list' numbers them; the first
+	// switches add a snapshot of the previous settings, so list again before each step
+	listed := func() []config.Profile {
+		t.Helper()
+		profiles, err := handler.NewConfigHandler(cm).ListConfigs()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return profiles
+	}
+	indexOf := func(name string) string {
+		t.Helper()
+		for i, profile := range listed() {
+			if profile.Name == name {
+				return strconv.Itoa(i + 1)
+			}
+		}
+		t.Fatalf("%s is not listed", name)
+		return ""
+	}
+
+	steps := []struct {
+		arg     string
+		byIndex bool // arg is a configuration whose index is passed instead
+		want    string
+	}{
+		{arg: "alpha", want: "alpha"},
+		{arg: "3", want: "3"}, // the configuration named "3", not the third one
+		{arg: "pers", want: "personal"},
+		{arg: "work", byIndex: true, want: "work"},
+		{arg: "alpha", byIndex: true, want: "alpha"},
+	}
+	for _, step := range steps {
+		arg := step.arg
+		if step.byIndex {
+			arg = indexOf(step.arg)
+		}
+		if err := runCommand(t, "use", arg); err != nil {
+			t.Fatalf("use %s: %v", arg, err)
+		}
+		if current, _ := cm.GetCurrentProfile(); current != step.want {
+			t.Fatalf("after use %s: current = %q, want %q", arg, current, step.want)
+		}
+	}
+
+	outOfRange := strconv.Itoa(len(listed()) + 1)
+	if got := ExitCode(runCommand(t, "use", outOfRange)); got != 2 {
+		t.Errorf("use %s: exit code %d, want 2", outOfRange, got)
+	}
+	if got := ExitCode(runCommand(t, "use", "wo")); got != 4 {
+		t.Errorf("use wo: exit code %d, want 4 for a prefix of several configurations", got)
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUseVerifyRollsBack(t *testing.T) {
+	setupHome(t)
+	cm := newTestManager(t)
+	for _, name := range []string{"work", "home"} {
+		if err := cm.CreateProfileWithContent(name, map[string]interface{}{"model": name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cm.UseProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	// Valid JSON with a structure Claude Code rejects
+	broken := filepath.Join(cm.GetProfilesDir(), "broken.json")
+	if err := os.WriteFile(broken, []byte(`{"model": "broken", "env": "not an object"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(cm.GetSettingsFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertRolledBack := func(args ...string) {
+		t.Helper()
+		if got := ExitCode(runCommand(t, args...)); got != 4 {
+			t.Errorf("cc-switch %v: exit code %d, want 4", args, got)
+		}
+		after, err := os.ReadFile(cm.GetSettingsFile())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(after) != string(before) {
+			t.Errorf("cc-switch %v: settings.json = %s, want it rolled back", args, after)
+		}
+		if current, _ := cm.GetCurrentProfile(); current != "work" {
+			t.Errorf("cc-switch %v: current = %q, want work", args, current)
+		}
+	}
+
+	assertRolledBack("use", "broken", "--verify")
+
+	if err := runCommand(t, "config", "set", "verify_switch", "true"); err != nil {
+		t.Fatalf("config set verify_switch: %v", err)
+	}
+	assertRolledBack("use", "broken")
+
+	if err := runCommand(t, "use", "home", "--verify"); err != nil {
+		t.Fatalf("use home --verify: %v", err)
+	}
+	if current, _ := cm.GetCurrentProfile(); current != "home" {
+		t.Errorf("current = %q, want home", current)
+	}
+}
@@ -1,11 +1,12 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 
-	"cc-switch/internal/config"
-	"cc-switch/internal/handler"
-	"cc-switch/internal/ui"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
+	"github.com/HoBeedzc/cc-switch/internal/ui"
 
 	"github.com/spf13/cobra"
 )
@@ -27,7 +28,8 @@ Template Modes:
 - CLI: cc-switch view -t <template-name>
 
 The interactive mode allows you to browse and select configurations/templates with arrow keys.
-The --current flag displays the currently active configuration.`,
+The --current flag displays the currently active configuration.
+--compact prints the content as single-line JSON for piping into other tools (implies --raw).`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := checkClaudeConfig(); err != nil {
@@ -43,6 +45,7 @@ The --current flag displays the currently active configuration.`,
 		configHandler := handler.NewConfigHandler(cm)
 		interactiveFlag, _ := cmd.Flags().GetBool("interactive")
 		raw, _ := cmd.Flags().GetBool("raw")
+		compact, _ := cmd.Flags().GetBool("compact")
 		current, _ := cmd.Flags().GetBool("current")
 		templateFlag, _ := cmd.Flags().GetBool("template")
 
@@ -61,16 +64,16 @@ The --current flag displays the currently active configuration.`,
 
 		// Execute view operation based on mode
 		if templateFlag {
-			return executeViewTemplate(configHandler, uiProvider, args, raw)
+			return executeViewTemplate(configHandler, uiProvider, args, raw, compact)
 		}
 
 		// Execute view operation
-		return executeView(configHandler, uiProvider, args, raw, current)
+		return executeView(configHandler, uiProvider, resolveAliasArg(cm, args), raw, compact, current)
 	},
 }
 
 // executeView handles the view operation with the given dependencies
-func executeView(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, args []string, raw bool, useCurrent bool) error {
+func executeView(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, args []string, raw, compact bool, useCurrent bool) error {
 	var targetName string
 
 	// Priority: explicit profile name > --current flag > interactive mode
@@ -112,11 +115,14 @@ func executeView(configHandler handler.ConfigHandler, uiProvider ui.UIProvider,
 	}
 
 	// Display configuration
+	if compact {
+		return printCompactJSON(view.Content)
+	}
 	return uiProvider.DisplayConfiguration(view, raw)
 }
 
 // executeViewTemplate handles the template view operation
-func executeViewTemplate(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, args []string, raw bool) error {
+func executeViewTemplate(configHandler handler.ConfigHandler, uiProvider ui.UIProvider, args []string, raw, compact bool) error {
 	var targetName string
 
 	// Determine execution mode
@@ -137,21 +143,11 @@ func executeViewTemplate(configHandler handler.ConfigHandler, uiProvider ui.UIPr
 		}
 
 		// Simple CLI selection for templates
-		fmt.Println("Available templates:")
-		for i, template := range templates {
-			if template == "default" {
-				fmt.Printf("  %d) %s (system default)\n", i+1, template)
-			} else {
-				fmt.Printf("  %d) %s\n", i+1, template)
-			}
-		}
-
-		fmt.Printf("Select template to view (1-%d): ", len(templates))
-		var selection int
-		if _, err := fmt.Scanln(&selection); err != nil || selection < 1 || selection > len(templates) {
-			return fmt.Errorf("invalid selection")
+		selection, err := ui.SelectFromMenu("Available templates:", ui.TemplateMenuItems(templates), "Select template to view")
+		if err != nil {
+			return fmt.Errorf("selection cancelled: %w", err)
 		}
-		targetName = templates[selection-1]
+		targetName = templates[selection]
 	}
 
 	// Validate input
@@ -167,11 +163,25 @@ func executeViewTemplate(configHandler handler.ConfigHandler, uiProvider ui.UIPr
 	}
 
 	// Display template
+	if compact {
+		return printCompactJSON(view.Content)
+	}
 	return uiProvider.DisplayTemplate(view, raw)
 }
 
+// printCompactJSON prints content as JSON on a single line without indentation
+func printCompactJSON(content map[string]interface{}) error {
+	jsonData, err := json.Marshal(content)
+	if err != nil {
+		return fmt.Errorf("failed to format JSON: %w", err)
+	}
+	fmt.Println(string(jsonData))
+	return nil
+}
+
 func init() {
 	viewCmd.Flags().BoolVar(&rawOutput, "raw", false, "Output raw JSON without metadata")
+	viewCmd.Flags().Bool("compact", false, "Output raw JSON on a single line without indentation")
 	viewCmd.Flags().BoolP("interactive", "i", false, "Enter interactive mode")
 	viewCmd.Flags().BoolP("current", "c", false, "View current active configuration")
 	viewCmd.Flags().BoolP("template", "t", false, "View template instead of configuration")
@@ -11,9 +11,9 @@ import (
 	"syscall"
 	"time"
 
-	"cc-switch/internal/config"
-	"cc-switch/internal/handler"
-	"cc-switch/internal/web"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
+	"github.com/HoBeedzc/cc-switch/internal/web"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -24,6 +24,9 @@ var (
 	webHost      string
 	webNoBrowser bool
 	webQuiet     bool
+
+	webUpdateInterval time.Duration
+	webNoUpdateCheck  bool
 )
 
 var webCmd = &cobra.Command{
@@ -39,7 +42,11 @@ The web interface allows you to:
 - Export and import configurations
 
 The server will be available at http://localhost:13501 (or custom host:port)
-By default, your web browser will open automatically to the interface.`,
+By default, your web browser will open automatically to the interface.
+
+While running, the server periodically re-checks for new releases so the
+update banner stays current. Use --update-interval to change how often
+(defaults to the configured check interval, 24h) or --no-update-check to disable it.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := checkClaudeConfig(); err != nil {
 			return err
@@ -60,6 +67,9 @@ By default, your web browser will open automatically to the interface.`,
 
 		// Create web server
 		server := web.NewServer(configHandler, webHost, webPort)
+		if !webNoUpdateCheck {
+			server.EnablePeriodicUpdateCheck(webUpdateInterval)
+		}
 
 		// Start server in goroutine
 		serverErr := make(chan error, 1)
@@ -114,6 +124,8 @@ func init() {
 	webCmd.Flags().StringVarP(&webHost, "host", "H", "localhost", "Host to bind to")
 	webCmd.Flags().BoolVarP(&webNoBrowser, "no-browser", "n", false, "Don't open browser automatically")
 	webCmd.Flags().BoolVarP(&webQuiet, "quiet", "q", false, "Suppress startup messages")
+	webCmd.Flags().DurationVar(&webUpdateInterval, "update-interval", 0, "Interval between periodic update checks (default: configured check interval)")
+	webCmd.Flags().BoolVar(&webNoUpdateCheck, "no-update-check", false, "Disable periodic update checks while the server is running")
 }
 
 // checkPortAvailable checks if a port is available
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var whichCmd = &cobra.Command{
+	Use:   "which [name]",
+	Short: "Print the file path of a configuration or template",
+	Long: `Print the absolute path of a configuration or template file, with no other output,
+so it can be used by other programs.
+
+Examples:
+  cc-switch which work              Path of the 'work' configuration
+  cc-switch which -t default        Path of the 'default' template
+  cc-switch which --current         Path of the active configuration
+  cc-switch which --settings        Path of Claude Code's settings.json
+  vim "$(cc-switch which work)"
+
+Exits with status 1 and prints nothing on stdout if the file does not exist.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		template, _ := cmd.Flags().GetBool("template")
+		current, _ := cmd.Flags().GetBool("current")
+		settings, _ := cmd.Flags().GetBool("settings")
+
+		modeCount := 0
+		for _, set := range []bool{len(args) > 0, current, settings} {
+			if set {
+				modeCount++
+			}
+		}
+		if modeCount == 0 {
+			return fmt.Errorf("must specify a name, --current or --settings")
+		}
+		if modeCount > 1 {
+			return fmt.Errorf("cannot use a name, --current and --settings together")
+		}
+		if template && len(args) == 0 {
+			return fmt.Errorf("-t/--template requires a template name")
+		}
+
+		cm, err := config.NewConfigManager()
+		if err != nil {
+			return fmt.Errorf("failed to initialize config manager: %w", err)
+		}
+
+		var path string
+		switch {
+		case settings:
+			path = cm.GetSettingsFile()
+			if _, err := os.Stat(path); err != nil {
+				return fmt.Errorf("settings file does not exist: %s", path)
+			}
+		case current:
+			if cm.IsEmptyMode() {
+				return fmt.Errorf("no active configuration (empty mode)")
+			}
+			name, err := cm.GetCurrentProfile()
+			if err != nil || name == "" {
+				return fmt.Errorf("no current configuration set")
+			}
+			if path, err = cm.ProfilePath(name); err != nil {
+				return err
+			}
+		case template:
+			if path, err = cm.TemplatePath(args[0]); err != nil {
+				return err
+			}
+		default:
+			if path, err = cm.ProfilePath(cm.ResolveProfileName(args[0])); err != nil {
+				return err
+			}
+		}
+
+		fmt.Println(path)
+		return nil
+	},
+}
+
+func init() {
+	whichCmd.Flags().BoolP("template", "t", false, "Print the path of a template instead of a configuration")
+	whichCmd.Flags().BoolP("current", "c", false, "Print the path of the active configuration")
+	whichCmd.Flags().Bool("settings", false, "Print the path of Claude Code's settings.json")
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
 	"io"
 
@@ -111,3 +112,209 @@ func clearBytes(data []byte) {
 		data[i] = 0
 	}
 }
+
+// EncryptionChunkSize is the plaintext size of each chunk sealed by NewEncryptWriter
+const EncryptionChunkSize = 64 * 1024
+
+// Chunk flags, authenticated as the additional data of each chunk
+const (
+	chunkMore  byte = 0
+	chunkFinal byte = 1
+)
+
+// NewEncryptWriter returns a writer that encrypts everything written to it with AES-256-GCM
+// in chunks of EncryptionChunkSize bytes, so arbitrarily large data can be encrypted with
+// constant memory. The salt and base nonce are written first (length-prefixed), then each
+// chunk as a flag byte, a length and the sealed chunk. Each chunk's nonce is the base nonce
+// XORed with the chunk index and the last chunk carries its own flag, so reordered or
+// truncated streams fail to decrypt. Close writes the last chunk; it does not close w.
+func NewEncryptWriter(w io.Writer, password string) (io.WriteCloser, error) {
+	salt, err := GenerateSalt()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := GenerateNonce()
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newChunkCipher(password, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range [][]byte{salt, nonce} {
+		if err := binary.Write(w, binary.LittleEndian, uint32(len(field))); err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(field); err != nil {
+			return nil, err
+		}
+	}
+
+	return &encryptWriter{
+		out:   w,
+		gcm:   gcm,
+		nonce: nonce,
+		buf:   make([]byte, 0, EncryptionChunkSize),
+	}, nil
+}
+
+// NewDecryptReader returns a reader that decrypts a stream written by NewEncryptWriter
+func NewDecryptReader(r io.Reader, password string) (io.Reader, error) {
+	var fields [2][]byte
+	for i := range fields {
+		var length uint32
+		if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+			return nil, fmt.Errorf("failed to read encryption header: %w", err)
+		}
+		if length > 64 {
+			return nil, fmt.Errorf("invalid encryption header")
+		}
+		fields[i] = make([]byte, length)
+		if _, err := io.ReadFull(r, fields[i]); err != nil {
+			return nil, fmt.Errorf("failed to read encryption header: %w", err)
+		}
+	}
+
+	gcm, err := newChunkCipher(password, fields[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(fields[1]) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid encryption header")
+	}
+	return &decryptReader{in: r, gcm: gcm, nonce: fields[1]}, nil
+}
+
+func newChunkCipher(password string, salt []byte) (cipher.AEAD, error) {
+	key := DeriveKey(password, salt)
+	defer clearBytes(key)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
+
+// chunkNonce derives the nonce of chunk index from the base nonce
+func chunkNonce(base []byte, index uint64) []byte {
+	nonce := append([]byte(nil), base...)
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], index)
+	for i := range counter {
+		nonce[len(nonce)-8+i] ^= counter[i]
+	}
+	return nonce
+}
+
+type encryptWriter struct {
+	out    io.Writer
+	gcm    cipher.AEAD
+	nonce  []byte
+	index  uint64
+	buf    []byte
+	closed bool
+}
+
+func (w *encryptWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, fmt.Errorf("write to closed encryption stream")
+	}
+	written := 0
+	for len(p) > 0 {
+		// A full chunk is only sealed once more data arrives, so the final chunk is never empty
+		// unless nothing was written at all
+		if len(w.buf) == EncryptionChunkSize {
+			if err := w.seal(chunkMore); err != nil {
+				return written, err
+			}
+		}
+		n := copy(w.buf[len(w.buf):EncryptionChunkSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (w *encryptWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.seal(chunkFinal)
+}
+
+func (w *encryptWriter) seal(flag byte) error {
+	sealed := w.gcm.Seal(nil, chunkNonce(w.nonce, w.index), w.buf, []byte{flag})
+	w.index++
+	w.buf = w.buf[:0]
+
+	if _, err := w.out.Write([]byte{flag}); err != nil {
+		return err
+	}
+	if err := binary.Write(w.out, binary.LittleEndian, uint32(len(sealed))); err != nil {
+		return err
+	}
+	_, err := w.out.Write(sealed)
+	return err
+}
+
+type decryptReader struct {
+	in    io.Reader
+	gcm   cipher.AEAD
+	nonce []byte
+	index uint64
+	plain []byte
+	final bool
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.final {
+			return 0, io.EOF
+		}
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// open reads and decrypts the next chunk
+func (r *decryptReader) open() error {
+	var flag [1]byte
+	if _, err := io.ReadFull(r.in, flag[:]); err != nil {
+		if err == io.EOF {
+			return fmt.Errorf("encrypted data is truncated")
+		}
+		return err
+	}
+	var length uint32
+	if err := binary.Read(r.in, binary.LittleEndian, &length); err != nil {
+		return fmt.Errorf("encrypted data is truncated")
+	}
+	if length > EncryptionChunkSize+uint32(r.gcm.Overhead()) {
+		return fmt.Errorf("invalid encrypted chunk length %d", length)
+	}
+	sealed := make([]byte, length)
+	if _, err := io.ReadFull(r.in, sealed); err != nil {
+		return fmt.Errorf("encrypted data is truncated")
+	}
+
+	plain, err := r.gcm.Open(sealed[:0], chunkNonce(r.nonce, r.index), sealed, flag[:])
+	if err != nil {
+		return fmt.Errorf("decryption failed (wrong password?): %w", err)
+	}
+	r.index++
+	r.plain = plain
+	r.final = flag[0] == chunkFinal
+	return nil
+}
@@ -0,0 +1,187 @@
+package common
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ProfilesDirNameEnv overrides the name of the cc-switch data directory under ~/.claude
+	ProfilesDirNameEnv = "CC_SWITCH_PROFILES_DIR_NAME"
+	// TemplatesDirNameEnv overrides the name of the templates directory under the profiles directory
+	TemplatesDirNameEnv = "CC_SWITCH_TEMPLATES_DIR_NAME"
+
+	// DefaultProfilesDirName is the default name of the cc-switch data directory
+	DefaultProfilesDirName = "profiles"
+	// DefaultTemplatesDirName is the default name of the templates directory
+	DefaultTemplatesDirName = "templates"
+
+	// UseXDGEnv opts in to the XDG layout: cc-switch's own state moves to $XDG_DATA_HOME/cc-switch
+	// and cache files to $XDG_CACHE_HOME/cc-switch, while settings.json and profiles stay in ~/.claude
+	UseXDGEnv = "CC_SWITCH_USE_XDG"
+	// XDGPointerFileName is left in the profiles directory once state has moved to the XDG
+	// directories. It records where the state went and keeps the XDG layout in effect
+	// without the environment variable.
+	XDGPointerFileName = ".xdg.json"
+
+	xdgAppDirName = "cc-switch"
+)
+
+// Layout is where cc-switch keeps its files. In the default layout the data and cache
+// directories are the profiles directory itself; in the XDG layout they are separate.
+// Every path cc-switch reads or writes is derived from a Layout.
+type Layout struct {
+	ClaudeDir   string // ~/.claude, holding settings.json
+	ProfilesDir string // ~/.claude/<profiles>, holding the profile payloads
+	DataDir     string // cc-switch state: current profile, history, metadata, templates, ...
+	CacheDir    string // Files that can be regenerated, such as the update check result
+	XDG         bool   // Whether the XDG layout is in effect
+	Migrated    bool   // Whether the pointer file exists (XDG only)
+}
+
+// xdgPointer is the content of the pointer file
+type xdgPointer struct {
+	DataDir  string `json:"data_dir"`
+	CacheDir string `json:"cache_dir"`
+}
+
+// ResolveLayout works out the layout for the profiles directory named profilesDirName.
+// An existing pointer file wins; otherwise CC_SWITCH_USE_XDG selects the XDG directories,
+// whose state still has to be migrated (Migrated is false).
+func ResolveLayout(profilesDirName string) (Layout, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return Layout{}, err
+	}
+
+	claudeDir := filepath.Join(homeDir, ".claude")
+	layout := Layout{
+		ClaudeDir:   claudeDir,
+		ProfilesDir: filepath.Join(claudeDir, profilesDirName),
+	}
+	layout.DataDir = layout.ProfilesDir
+	layout.CacheDir = layout.ProfilesDir
+
+	if data, err := os.ReadFile(layout.PointerFile()); err == nil {
+		var pointer xdgPointer
+		if err := json.Unmarshal(data, &pointer); err == nil && filepath.IsAbs(pointer.DataDir) && filepath.IsAbs(pointer.CacheDir) {
+			layout.DataDir = pointer.DataDir
+			layout.CacheDir = pointer.CacheDir
+			layout.XDG = true
+			layout.Migrated = true
+			return layout, nil
+		}
+	}
+
+	if !XDGRequested() {
+		return layout, nil
+	}
+
+	appDir := xdgAppDirName
+	if profilesDirName != DefaultProfilesDirName {
+		appDir += "-" + profilesDirName
+	}
+	layout.DataDir = filepath.Join(xdgBaseDir("XDG_DATA_HOME", filepath.Join(homeDir, ".local", "share")), appDir)
+	layout.CacheDir = filepath.Join(xdgBaseDir("XDG_CACHE_HOME", filepath.Join(homeDir, ".cache")), appDir)
+	layout.XDG = true
+	return layout, nil
+}
+
+// DefaultLayout resolves the layout of the configured profiles directory
+func DefaultLayout() (Layout, error) {
+	return ResolveLayout(ProfilesDirName())
+}
+
+// XDGRequested reports whether CC_SWITCH_USE_XDG is set to a true value
+func XDGRequested() bool {
+	enabled, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(UseXDGEnv)))
+	return err == nil && enabled
+}
+
+// xdgBaseDir reads an XDG base directory variable. The specification says relative
+// values are invalid and must be ignored.
+func xdgBaseDir(env, fallback string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir
+	}
+	return fallback
+}
+
+// SettingsFile returns the path of Claude Code's settings.json
+func (l Layout) SettingsFile() string {
+	return filepath.Join(l.ClaudeDir, "settings.json")
+}
+
+// ProfileFile returns the path of a file in the profiles directory
+func (l Layout) ProfileFile(name string) string {
+	return filepath.Join(l.ProfilesDir, name)
+}
+
+// DataFile returns the path of a state file
+func (l Layout) DataFile(name string) string {
+	return filepath.Join(l.DataDir, name)
+}
+
+// CacheFile returns the path of a cache file
+func (l Layout) CacheFile(name string) string {
+	return filepath.Join(l.CacheDir, name)
+}
+
+// TemplatesDir returns the templates directory, which lives with the state
+func (l Layout) TemplatesDir(templatesDirName string) string {
+	return filepath.Join(l.DataDir, templatesDirName)
+}
+
+// PointerFile returns the path of the XDG pointer file
+func (l Layout) PointerFile() string {
+	return filepath.Join(l.ProfilesDir, XDGPointerFileName)
+}
+
+// PointerContent returns the pointer file content recording this layout's directories
+func (l Layout) PointerContent() ([]byte, error) {
+	data, err := json.MarshalIndent(xdgPointer{DataDir: l.DataDir, CacheDir: l.CacheDir}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// ProfilesDirName returns the configured profiles directory name
+func ProfilesDirName() string {
+	return dirNameFromEnv(ProfilesDirNameEnv, DefaultProfilesDirName)
+}
+
+// TemplatesDirName returns the configured templates directory name
+func TemplatesDirName() string {
+	return dirNameFromEnv(TemplatesDirNameEnv, DefaultTemplatesDirName)
+}
+
+// ProfilesDir returns the absolute path of the profiles directory (~/.claude/<profiles>)
+func ProfilesDir() (string, error) {
+	layout, err := DefaultLayout()
+	if err != nil {
+		return "", err
+	}
+	return layout.ProfilesDir, nil
+}
+
+// dirNameFromEnv reads a directory name override, falling back to the default
+// when unset or when the value is not a single plain path element
+func dirNameFromEnv(env, fallback string) string {
+	name := strings.TrimSpace(os.Getenv(env))
+	if !IsValidDirName(name) {
+		return fallback
+	}
+	return name
+}
+
+// IsValidDirName reports whether name is usable as a single directory name
+func IsValidDirName(name string) bool {
+	if name == "" || name == "." || name == ".." {
+		return false
+	}
+	return !strings.ContainsAny(name, `/\`)
+}
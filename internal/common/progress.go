@@ -0,0 +1,122 @@
+package common
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Progress event statuses. Per-item events report running and then the item's outcome;
+// the last event of an operation has status finished and carries the overall result.
+const (
+	ProgressRunning  = "running"
+	ProgressOK       = "ok"
+	ProgressFailed   = "failed"
+	ProgressSkipped  = "skipped"
+	ProgressFinished = "finished"
+)
+
+// Overall results carried by the finished event
+const (
+	ResultOK          = "ok"
+	ResultFailed      = "failed"
+	ResultInterrupted = "interrupted"
+)
+
+// ProgressEvent is one line of machine-readable progress (--progress json).
+// Events only ever hold names, counts and statuses: error messages are left out
+// because they can quote configuration content.
+type ProgressEvent struct {
+	Op         string `json:"op"`                    // test, export, import or update
+	Item       string `json:"item,omitempty"`        // Profile or file the event is about
+	Done       int    `json:"done"`                  // Items finished so far
+	Total      int    `json:"total"`                 // Items in the operation (0 when unknown)
+	Bytes      int64  `json:"bytes,omitempty"`       // Bytes transferred or written so far
+	TotalBytes int64  `json:"total_bytes,omitempty"` // Expected bytes, when known
+	Status     string `json:"status"`                // One of the Progress* constants, or an item outcome
+	Result     string `json:"result,omitempty"`      // Finished event only: one of the Result* constants
+	Failed     int    `json:"failed,omitempty"`      // Finished event only: items that failed
+}
+
+var (
+	progressMu  sync.Mutex
+	progressOut io.Writer
+)
+
+// SetProgressOutput sends progress events to w as newline-delimited JSON; nil turns them off
+func SetProgressOutput(w io.Writer) {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	progressOut = w
+}
+
+// JSONProgress reports whether progress events are enabled. Commands use it to
+// suppress their human progress output (bars, counters) in favour of events.
+func JSONProgress() bool {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	return progressOut != nil
+}
+
+// EmitProgress writes event as a single line and flushes it. It does nothing
+// unless progress events are enabled.
+func EmitProgress(event ProgressEvent) {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	if progressOut == nil {
+		return
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	progressOut.Write(append(line, '\n'))
+	if flusher, ok := progressOut.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+}
+
+// FinishProgress emits the finished event of an operation
+func FinishProgress(op string, done, total, failed int, result string) {
+	EmitProgress(ProgressEvent{Op: op, Done: done, Total: total, Status: ProgressFinished, Result: result, Failed: failed})
+}
+
+// progressReader emits a byte-count event as data is read, at most once per percent
+// (or per MiB when the size is unknown)
+type progressReader struct {
+	r       io.Reader
+	op      string
+	item    string
+	total   int64
+	read    int64
+	emitted int64
+}
+
+// NewProgressReader wraps r so reading from it emits progress events for op.
+// total is the expected size, or -1 when unknown. Without progress events enabled
+// r is returned unchanged.
+func NewProgressReader(r io.Reader, op, item string, total int64) io.Reader {
+	if !JSONProgress() {
+		return r
+	}
+	if total < 0 {
+		total = 0
+	}
+	return &progressReader{r: r, op: op, item: item, total: total, emitted: -1}
+}
+
+func (p *progressReader) Read(buf []byte) (int, error) {
+	n, err := p.r.Read(buf)
+	p.read += int64(n)
+
+	step := int64(1 << 20)
+	if p.total > 0 {
+		step = max(p.total/100, 1)
+	}
+	if p.emitted < 0 || p.read-p.emitted >= step || (err == io.EOF && p.read != p.emitted) {
+		p.emitted = p.read
+		EmitProgress(ProgressEvent{Op: p.op, Item: p.item, Bytes: p.read, TotalBytes: p.total, Status: ProgressRunning})
+	}
+	return n, err
+}
@@ -0,0 +1,132 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+// captureProgress enables progress events into a buffer for the rest of the test
+func captureProgress(t *testing.T) *flushBuffer {
+	t.Helper()
+	out := &flushBuffer{}
+	SetProgressOutput(out)
+	t.Cleanup(func() { SetProgressOutput(nil) })
+	return out
+}
+
+// flushBuffer is a buffer that counts flushes
+type flushBuffer struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (b *flushBuffer) Flush() error {
+	b.flushes++
+	return nil
+}
+
+// decodeEvents parses newline-delimited progress events, failing on any line that is not one
+func decodeEvents(t *testing.T, data string) []ProgressEvent {
+	t.Helper()
+	var events []ProgressEvent
+	for _, line := range strings.Split(strings.TrimSuffix(data, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		var event ProgressEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("line %q is not a progress event: %v", line, err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestEmitProgressDisabled(t *testing.T) {
+	SetProgressOutput(nil)
+	if JSONProgress() {
+		t.Fatal("progress enabled without an output")
+	}
+	// Must not panic or write anywhere
+	EmitProgress(ProgressEvent{Op: "test", Status: ProgressRunning})
+	FinishProgress("test", 1, 1, 0, ResultOK)
+}
+
+func TestEmitProgress(t *testing.T) {
+	out := captureProgress(t)
+	if !JSONProgress() {
+		t.Fatal("JSONProgress = false with an output set")
+	}
+
+	EmitProgress(ProgressEvent{Op: "test", Item: "work", Done: 1, Total: 2, Status: ProgressOK})
+	FinishProgress("test", 2, 2, 1, ResultFailed)
+
+	events := decodeEvents(t, out.String())
+	want := []ProgressEvent{
+		{Op: "test", Item: "work", Done: 1, Total: 2, Status: ProgressOK},
+		{Op: "test", Done: 2, Total: 2, Status: ProgressFinished, Result: ResultFailed, Failed: 1},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("events = %+v, want %+v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, events[i], want[i])
+		}
+	}
+	if out.flushes != 2 {
+		t.Errorf("flushes = %d, want one per event", out.flushes)
+	}
+}
+
+func TestProgressReader(t *testing.T) {
+	out := captureProgress(t)
+	data := bytes.Repeat([]byte("x"), 1000)
+
+	r := NewProgressReader(bytes.NewReader(data), "update", "cc-switch.tar.gz", int64(len(data)))
+	read, err := io.ReadAll(chunkReader{r: r, n: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read, data) {
+		t.Fatal("data changed while reading")
+	}
+
+	events := decodeEvents(t, out.String())
+	if len(events) < 2 || len(events) > 102 {
+		t.Fatalf("%d events, want at most about one per percent", len(events))
+	}
+	last := events[len(events)-1]
+	if last.Bytes != int64(len(data)) || last.TotalBytes != int64(len(data)) || last.Item != "cc-switch.tar.gz" {
+		t.Errorf("last event = %+v, want all %d bytes reported", last, len(data))
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].Bytes <= events[i-1].Bytes {
+			t.Errorf("event %d reports %d bytes after %d", i, events[i].Bytes, events[i-1].Bytes)
+		}
+	}
+}
+
+func TestProgressReaderDisabled(t *testing.T) {
+	SetProgressOutput(nil)
+	r := strings.NewReader("data")
+	if got := NewProgressReader(r, "update", "file", 4); got != io.Reader(r) {
+		t.Error("the reader was wrapped although progress events are off")
+	}
+}
+
+// chunkReader returns at most n bytes per Read, like a network connection
+type chunkReader struct {
+	r io.Reader
+	n int
+}
+
+func (c chunkReader) Read(buf []byte) (int, error) {
+	if len(buf) > c.n {
+		buf = buf[:c.n]
+	}
+	return c.r.Read(buf)
+}
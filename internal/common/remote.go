@@ -0,0 +1,171 @@
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// RemoteTokenEnv names the environment variable holding an optional bearer token for remote backups
+	RemoteTokenEnv = "CC_SWITCH_REMOTE_TOKEN"
+
+	// MaxRemoteDownloadSize limits how much data is downloaded for a remote import
+	MaxRemoteDownloadSize = 10 << 20
+
+	remoteTimeout = 60 * time.Second
+)
+
+// RemoteOptions controls HTTP transfers of backup files
+type RemoteOptions struct {
+	Insecure bool   // skip TLS certificate verification (self-signed internal CAs)
+	Token    string // bearer token; defaults to $CC_SWITCH_REMOTE_TOKEN when empty
+	// Magic, when set, is the signature a downloaded file must start with; anything else
+	// (such as a login page served with status 200) is rejected before it is saved
+	Magic string
+}
+
+// IsRemoteURL reports whether a path refers to an http(s) URL
+func IsRemoteURL(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// newRemoteClient creates an HTTP client for backup transfers
+func newRemoteClient(options RemoteOptions) *http.Client {
+	return &http.Client{
+		Timeout: remoteTimeout,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{
+				MinVersion:         tls.VersionTLS12,
+				InsecureSkipVerify: options.Insecure,
+			},
+		},
+	}
+}
+
+// authorize adds the bearer token to a request if one is configured
+func (o RemoteOptions) authorize(req *http.Request) {
+	token := o.Token
+	if token == "" {
+		token = os.Getenv(RemoteTokenEnv)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// DownloadToTemp downloads url into a temporary file and returns its path.
+// Downloads larger than maxBytes are rejected. The caller must remove the file.
+func DownloadToTemp(url string, maxBytes int64, options RemoteOptions) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	options.authorize(req)
+	req.Header.Set("User-Agent", "github.com/HoBeedzc/cc-switch/"+Version)
+
+	resp, err := newRemoteClient(options).Do(req)
+	if err != nil {
+		return "", fmt.Errorf("download failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", remoteStatusError("download", resp)
+	}
+
+	if resp.ContentLength > maxBytes {
+		return "", fmt.Errorf("remote file is too large (%d bytes, limit %d bytes)", resp.ContentLength, maxBytes)
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); strings.HasPrefix(mediaType, "text/") {
+		return "", fmt.Errorf("server returned %s content instead of a file; check the URL and %s", mediaType, RemoteTokenEnv)
+	}
+
+	body := bufio.NewReader(resp.Body)
+	if options.Magic != "" {
+		head, _ := body.Peek(len(options.Magic))
+		if string(head) != options.Magic {
+			return "", fmt.Errorf("downloaded data is not a cc-switch export (missing %s header)", options.Magic)
+		}
+	}
+
+	tempFile, err := os.CreateTemp("", "cc-switch-remote-*.ccx")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer tempFile.Close()
+
+	// Read one byte past the limit to detect oversized bodies without a Content-Length
+	written, err := io.Copy(tempFile, io.LimitReader(body, maxBytes+1))
+	if err != nil {
+		os.Remove(tempFile.Name())
+		return "", fmt.Errorf("failed to save downloaded file: %w", err)
+	}
+	if written > maxBytes {
+		os.Remove(tempFile.Name())
+		return "", fmt.Errorf("remote file exceeds the %d byte limit", maxBytes)
+	}
+
+	return tempFile.Name(), nil
+}
+
+// UploadFile uploads a local file to url with HTTP PUT and returns the number of bytes sent.
+// Server errors (5xx) are retried once.
+func UploadFile(url string, path string, options RemoteOptions) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read file to upload: %w", err)
+	}
+
+	client := newRemoteClient(options)
+	var lastErr error
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+		if err != nil {
+			return 0, fmt.Errorf("invalid URL: %w", err)
+		}
+		options.authorize(req)
+		req.Header.Set("User-Agent", "github.com/HoBeedzc/cc-switch/"+Version)
+		req.Header.Set("Content-Type", "application/octet-stream")
+		req.ContentLength = int64(len(data))
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, fmt.Errorf("upload failed: %w", err)
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return int64(len(data)), nil
+		}
+
+		lastErr = remoteStatusError("upload", resp)
+		if resp.StatusCode < 500 {
+			break
+		}
+	}
+
+	return 0, lastErr
+}
+
+// remoteStatusError describes an unexpected HTTP status for a transfer
+func remoteStatusError(operation string, resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%s rejected: %s (set %s to a valid bearer token)", operation, resp.Status, RemoteTokenEnv)
+	default:
+		return fmt.Errorf("%s failed: server returned %s", operation, resp.Status)
+	}
+}
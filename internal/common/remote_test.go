@@ -0,0 +1,185 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDownloadToTemp(t *testing.T) {
+	t.Setenv(RemoteTokenEnv, "")
+
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		options  RemoteOptions
+		maxBytes int64
+		wantErr  string // empty means the download must succeed
+	}{
+		{
+			name: "success with bearer token",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer secret" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.Header().Set("Content-Type", "application/octet-stream")
+				w.Write([]byte("CCX1 payload"))
+			},
+			options:  RemoteOptions{Token: "secret", Magic: "CCX1"},
+			maxBytes: 1024,
+		},
+		{
+			name: "unauthorized",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			maxBytes: 1024,
+			wantErr:  "download rejected: 401 Unauthorized (set " + RemoteTokenEnv,
+		},
+		{
+			name: "forbidden",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			},
+			options:  RemoteOptions{Token: "wrong"},
+			maxBytes: 1024,
+			wantErr:  "download rejected: 403 Forbidden",
+		},
+		{
+			name: "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			maxBytes: 1024,
+			wantErr:  "download failed: server returned 502 Bad Gateway",
+		},
+		{
+			name: "oversized with content length",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/octet-stream")
+				w.Write([]byte(strings.Repeat("x", 64)))
+			},
+			maxBytes: 16,
+			wantErr:  "remote file is too large (64 bytes, limit 16 bytes)",
+		},
+		{
+			name: "oversized without content length",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/octet-stream")
+				// Flushing before writing the body forces chunked encoding
+				w.(http.Flusher).Flush()
+				w.Write([]byte(strings.Repeat("x", 64)))
+			},
+			maxBytes: 16,
+			wantErr:  "remote file exceeds the 16 byte limit",
+		},
+		{
+			name: "login page served as text",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				w.Write([]byte("<html>Sign in</html>"))
+			},
+			maxBytes: 1024,
+			wantErr:  "server returned text/html content instead of a file",
+		},
+		{
+			name: "missing magic",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/octet-stream")
+				w.Write([]byte("PK\x03\x04 zip archive"))
+			},
+			options:  RemoteOptions{Magic: "CCX1"},
+			maxBytes: 1024,
+			wantErr:  "missing CCX1 header",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			path, err := DownloadToTemp(server.URL, tt.maxBytes, tt.options)
+			if tt.wantErr != "" {
+				if err == nil {
+					os.Remove(path)
+					t.Fatalf("expected error containing %q, got none", tt.wantErr)
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %q, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer os.Remove(path)
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read downloaded file: %v", err)
+			}
+			if string(data) != "CCX1 payload" {
+				t.Fatalf("downloaded %q, want %q", data, "CCX1 payload")
+			}
+		})
+	}
+}
+
+func TestUploadFile(t *testing.T) {
+	t.Setenv(RemoteTokenEnv, "env-token")
+
+	path := t.TempDir() + "/backup.ccx"
+	if err := os.WriteFile(path, []byte("CCX1 data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		statuses     []int // status returned for each attempt
+		wantAttempts int
+		wantErr      string
+	}{
+		{name: "success", statuses: []int{http.StatusCreated}, wantAttempts: 1},
+		{name: "auth failure is not retried", statuses: []int{http.StatusUnauthorized}, wantAttempts: 1, wantErr: "upload rejected: 401 Unauthorized"},
+		{name: "server error is retried once", statuses: []int{http.StatusServiceUnavailable, http.StatusOK}, wantAttempts: 2},
+		{name: "server error twice", statuses: []int{http.StatusInternalServerError, http.StatusBadGateway}, wantAttempts: 2, wantErr: "upload failed: server returned 502 Bad Gateway"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPut {
+					t.Errorf("method = %s, want PUT", r.Method)
+				}
+				if got := r.Header.Get("Authorization"); got != "Bearer env-token" {
+					t.Errorf("Authorization = %q, want the token from %s", got, RemoteTokenEnv)
+				}
+				w.WriteHeader(tt.statuses[min(attempts, len(tt.statuses)-1)])
+				attempts++
+			}))
+			defer server.Close()
+
+			written, err := UploadFile(server.URL, path, RemoteOptions{})
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if written != int64(len("CCX1 data")) {
+				t.Errorf("written = %d, want %d", written, len("CCX1 data"))
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -16,6 +17,9 @@ const (
 
 	// GitHubAPIURL is the GitHub API endpoint for latest release
 	GitHubAPIURL = "https://api.github.com/repos/HoBeedzc/cc-switch/releases/latest"
+
+	// UpdateCacheFileName is the name of the update check cache file in the cache directory
+	UpdateCacheFileName = ".update_check"
 )
 
 // UpdateCheckCache stores the cached update check result
@@ -34,13 +38,13 @@ type UpdateCheckResult struct {
 }
 
 // getUpdateCacheFile returns the path to the update check cache file
-// The cache file is stored under profiles/ directory along with other cc-switch data
+// The cache file is stored in the layout's cache directory (profiles/ unless the XDG layout is used)
 func getUpdateCacheFile() (string, error) {
-	homeDir, err := os.UserHomeDir()
+	layout, err := DefaultLayout()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(homeDir, ".claude", "profiles", ".update_check"), nil
+	return layout.CacheFile(UpdateCacheFileName), nil
 }
 
 // loadUpdateCache loads the cached update check result
@@ -163,6 +167,11 @@ func checkUpdateSync() UpdateCheckResult {
 	return result
 }
 
+// CheckUpdateNow performs a synchronous update check and refreshes the cache
+func CheckUpdateNow() UpdateCheckResult {
+	return checkUpdateSync()
+}
+
 // fetchLatestVersion fetches the latest version from GitHub API
 func fetchLatestVersion() (string, error) {
 	client := &http.Client{
@@ -175,7 +184,7 @@ func fetchLatestVersion() (string, error) {
 	}
 
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
-	req.Header.Set("User-Agent", "cc-switch/"+Version)
+	req.Header.Set("User-Agent", "github.com/HoBeedzc/cc-switch/"+Version)
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -291,3 +300,32 @@ func TriggerBackgroundCheck() {
 		checkUpdateSync()
 	}()
 }
+
+// GetCheckInterval returns the configured interval between update checks
+func GetCheckInterval() time.Duration {
+	cache, _ := loadUpdateCache()
+	return getCheckInterval(cache)
+}
+
+// StartPeriodicCheck re-checks for updates every interval until ctx is cancelled
+// A non-positive interval falls back to the configured check interval
+// This is meant for long-running processes such as the web server
+func StartPeriodicCheck(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = GetCheckInterval()
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				checkUpdateSync() // Refreshes the cache used by /api/version
+			}
+		}
+	}()
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// 活动日志中的操作类型
+const (
+	ActivitySwitch     = "switch"
+	ActivityEmptyOn    = "empty_mode_on"
+	ActivityEmptyOff   = "empty_mode_off"
+	ActivityEmptyFixed = "empty_mode_recovered" // 修复了中断的空配置模式切换
+	ActivityImport     = "import"
+	ActivityBackup     = "backup"
+	ActivityTest       = "test"
+	ActivityMigrate    = "migrate_permissions"
+	activityLogName    = ".activity.log"
+	maxActivityLogSize = 512 << 10 // 超过该大小时轮转为 .activity.log.1
+)
+
+// ActivityEntry 活动日志中的一条记录（JSONL 格式，每行一条）
+type ActivityEntry struct {
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"`
+	Profile string    `json:"profile,omitempty"`
+	Note    string    `json:"note,omitempty"`
+	Result  string    `json:"result,omitempty"` // ok 或 failed（用于 test 等操作）
+	Detail  string    `json:"detail,omitempty"`
+}
+
+// LastTest 配置最近一次测试的结果（来自活动日志中的 test 记录）
+type LastTest struct {
+	Result   string    `json:"result"` // ok 或 failed
+	TestedAt time.Time `json:"tested_at"`
+	Detail   string    `json:"detail,omitempty"`
+}
+
+// activityLogPath 返回活动日志路径
+func (cm *ConfigManager) activityLogPath() string {
+	return cm.dataFile(activityLogName)
+}
+
+// LogActivity 追加一条活动记录，失败只给出警告，不影响主操作
+func (cm *ConfigManager) LogActivity(entry ActivityEntry) {
+	if err := cm.appendActivity(entry); err != nil {
+		fmt.Fprintf(cm.warnings, "Warning: failed to write activity log: %v\n", err)
+	}
+}
+
+// appendActivity 以追加方式写入一条记录，文件过大时先轮转
+func (cm *ConfigManager) appendActivity(entry ActivityEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	cm.activityMu.Lock()
+	defer cm.activityMu.Unlock()
+
+	path := cm.activityLogPath()
+	if info, err := os.Stat(path); err == nil && info.Size()+int64(len(line)) > maxActivityLogSize {
+		if err := cm.fs.Rename(path, path+".1"); err != nil {
+			return fmt.Errorf("failed to rotate activity log: %w", err)
+		}
+	}
+
+	return cm.fs.AppendFile(path, line, 0600)
+}
+
+// ReadActivity 读取活动日志（包括已轮转的部分），按时间倒序返回
+// limit 大于 0 时只返回最近的 limit 条；无法解析的行会被跳过
+func (cm *ConfigManager) ReadActivity(limit int) ([]ActivityEntry, error) {
+	path := cm.activityLogPath()
+
+	var entries []ActivityEntry
+	for _, file := range []string{path + ".1", path} {
+		fileEntries, err := readActivityFile(file)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fileEntries...)
+	}
+
+	// 文件中按时间顺序追加，反转为最新在前
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// AttachLastTests 从活动日志中为每个配置填充最近一次测试结果，不会运行新的测试
+// 从未测试过的配置 LastTest 保持为 nil
+func (cm *ConfigManager) AttachLastTests(profiles []Profile) error {
+	entries, err := cm.ReadActivity(0)
+	if err != nil {
+		return err
+	}
+
+	latest := make(map[string]*LastTest)
+	for _, entry := range entries {
+		if entry.Action != ActivityTest || entry.Profile == "" {
+			continue
+		}
+		// 记录为最新在前，只取每个配置的第一条
+		if _, seen := latest[entry.Profile]; !seen {
+			latest[entry.Profile] = &LastTest{Result: entry.Result, TestedAt: entry.Time, Detail: entry.Detail}
+		}
+	}
+
+	for i := range profiles {
+		profiles[i].LastTest = latest[profiles[i].Name]
+	}
+	return nil
+}
+
+// readActivityFile 读取单个 JSONL 日志文件（不存在时返回空）
+func readActivityFile(path string) ([]ActivityEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read activity log: %w", err)
+	}
+	defer file.Close()
+
+	var entries []ActivityEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry ActivityEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read activity log: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/HoBeedzc/cc-switch/internal/common"
+)
+
+// UntrackedProfile 没有对应元数据的配置文件，通常是在 cc-switch 之外手动复制的
+type UntrackedProfile struct {
+	Name   string `json:"name"`
+	Origin string `json:"origin,omitempty"` // 内容完全相同且已被跟踪的配置，即推测的复制来源
+	// MetadataOf 元数据记录的配置名与文件名不一致时，为元数据原本所属的配置（元数据也被一起复制了）
+	MetadataOf string `json:"metadata_of,omitempty"`
+}
+
+// profileContentHash 计算配置文件内容的 SHA-256（读取失败时返回空）
+func (cm *ConfigManager) profileContentHash(name string) string {
+	path, err := cm.ProfilePath(name)
+	if err != nil || cm.checkManagedPath(path) != nil {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// FindUntrackedProfiles 找出元数据缺失或属于其他配置的用户配置，并按内容推测复制来源
+func (cm *ConfigManager) FindUntrackedProfiles() ([]UntrackedProfile, error) {
+	profiles, err := cm.ListProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var untracked []UntrackedProfile
+	tracked := make(map[string][]string) // 内容哈希 -> 已跟踪的配置名
+	for _, profile := range profiles {
+		if profile.ReadOnly || profile.Error != "" {
+			continue
+		}
+
+		entry := UntrackedProfile{Name: profile.Name}
+		if _, err := os.Stat(cm.metadataPath(profile.Name)); os.IsNotExist(err) {
+			untracked = append(untracked, entry)
+			continue
+		}
+
+		meta, err := cm.GetProfileMetadata(profile.Name)
+		if err != nil {
+			continue
+		}
+		if meta.Name != "" && meta.Name != profile.Name {
+			entry.MetadataOf = meta.Name
+			untracked = append(untracked, entry)
+			continue
+		}
+
+		if hash := cm.profileContentHash(profile.Name); hash != "" {
+			tracked[hash] = append(tracked[hash], profile.Name)
+		}
+	}
+
+	for i := range untracked {
+		if candidates := tracked[cm.profileContentHash(untracked[i].Name)]; len(candidates) > 0 {
+			untracked[i].Origin = closestName(untracked[i].Name, candidates)
+		} else if untracked[i].MetadataOf != "" && cm.ProfileExists(untracked[i].MetadataOf) {
+			untracked[i].Origin = untracked[i].MetadataOf
+		}
+	}
+
+	return untracked, nil
+}
+
+// closestName 从内容相同的候选配置中选出与 name 公共前缀最长的一个（如 work-backup -> work）
+func closestName(name string, candidates []string) string {
+	best, bestLen := candidates[0], -1
+	for _, candidate := range candidates {
+		n := 0
+		for n < len(name) && n < len(candidate) && name[n] == candidate[n] {
+			n++
+		}
+		if n > bestLen {
+			best, bestLen = candidate, n
+		}
+	}
+	return best
+}
+
+// AdoptProfile 为未跟踪的配置创建（或修正）元数据；指定 origin 时继承其模板，inheritTags 时同时继承标签
+func (cm *ConfigManager) AdoptProfile(name, origin string, inheritTags bool) error {
+	if !cm.ProfileExists(name) {
+		return NotFoundf("configuration '%s' does not exist", name)
+	}
+	if cm.IsSystemProfile(name) {
+		return Lockedf("configuration '%s' is a read-only system profile", name)
+	}
+
+	meta, err := cm.GetProfileMetadata(name)
+	if err != nil {
+		meta = &ProfileMetadata{}
+	}
+	// 随文件一起复制来的元数据属于来源配置，只保留可继承的部分
+	if meta.Name != "" && meta.Name != name {
+		meta = &ProfileMetadata{Template: meta.Template, Tags: meta.Tags}
+		if !inheritTags {
+			meta.Tags = nil
+		}
+	}
+
+	if origin != "" {
+		originMeta, err := cm.GetProfileMetadata(origin)
+		if err != nil {
+			return fmt.Errorf("failed to read metadata of '%s': %w", origin, err)
+		}
+		if meta.Template == "" {
+			meta.Template = originMeta.Template
+		}
+		if inheritTags {
+			meta.Tags = mergeTags(meta.Tags, originMeta.Tags)
+		}
+	}
+
+	meta.Name = name
+	meta.ContentHash = cm.profileContentHash(name)
+	if meta.WrittenBy == "" {
+		meta.WrittenBy = common.Version
+		meta.UpdatedAt = time.Now()
+	}
+
+	return cm.saveProfileMetadata(name, meta)
+}
+
+// mergeTags 合并标签列表并去重，保持原有顺序
+func mergeTags(tags, extra []string) []string {
+	seen := make(map[string]bool, len(tags))
+	merged := append([]string(nil), tags...)
+	for _, tag := range tags {
+		seen[tag] = true
+	}
+	for _, tag := range extra {
+		if !seen[tag] {
+			seen[tag] = true
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
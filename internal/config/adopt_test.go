@@ -0,0 +1,173 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// copyFileForTest copies a file the way a user would with cp
+func copyFileForTest(t *testing.T, src, dst string) {
+	t.Helper()
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setupTaggedProfile creates the work profile from the default template and tags it
+func setupTaggedProfile(t *testing.T, cm *ConfigManager) {
+	t.Helper()
+	if err := cm.CreateProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cm.AddProfileTag("work", "team"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestManuallyCopiedProfileWorks(t *testing.T) {
+	cm := newTestManager(t)
+	setupTaggedProfile(t, cm)
+	copyFileForTest(t, filepath.Join(cm.profilesDir, "work.json"), filepath.Join(cm.profilesDir, "work-backup.json"))
+
+	profiles, err := cm.ListProfiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, profile := range profiles {
+		if profile.Error != "" {
+			t.Errorf("%s: %s", profile.Name, profile.Error)
+		}
+		names = append(names, profile.Name)
+	}
+	sort.Strings(names)
+	if !reflect.DeepEqual(names, []string{"work", "work-backup"}) {
+		t.Errorf("profiles = %v, want work and work-backup", names)
+	}
+
+	// The copy does not share the metadata of the original
+	if tags, err := cm.GetProfileTags("work-backup"); err != nil || len(tags) != 0 {
+		t.Errorf("work-backup tags = %v (%v), want none", tags, err)
+	}
+
+	if _, _, err := cm.GetProfileContent("work-backup"); err != nil {
+		t.Errorf("GetProfileContent: %v", err)
+	}
+	if err := cm.UseProfile("work-backup"); err != nil {
+		t.Errorf("UseProfile: %v", err)
+	}
+	if err := cm.RenameProfile("work-backup", "old-work"); err != nil {
+		t.Errorf("RenameProfile: %v", err)
+	}
+	if err := cm.CopyProfile("old-work", "another"); err != nil {
+		t.Errorf("CopyProfile: %v", err)
+	}
+}
+
+func TestFindUntrackedProfiles(t *testing.T) {
+	cm := newTestManager(t)
+	setupTaggedProfile(t, cm)
+	if err := cm.CreateProfileWithContent("home", map[string]interface{}{"model": "home"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A plain copy of the profile file, and a copy made together with its metadata
+	copyFileForTest(t, filepath.Join(cm.profilesDir, "work.json"), filepath.Join(cm.profilesDir, "work-backup.json"))
+	copyFileForTest(t, filepath.Join(cm.profilesDir, "work.json"), filepath.Join(cm.profilesDir, "clone.json"))
+	copyFileForTest(t, cm.metadataPath("work"), cm.metadataPath("clone"))
+
+	untracked, err := cm.FindUntrackedProfiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []UntrackedProfile{
+		{Name: "clone", Origin: "work", MetadataOf: "work"},
+		{Name: "work-backup", Origin: "work"},
+	}
+	if !reflect.DeepEqual(untracked, want) {
+		t.Errorf("untracked = %+v, want %+v", untracked, want)
+	}
+}
+
+func TestAdoptProfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		copyMeta    bool
+		inheritTags bool
+		wantTags    []string
+	}{
+		{name: "file copy with tags", inheritTags: true, wantTags: []string{"team"}},
+		{name: "file copy without tags", inheritTags: false},
+		{name: "copied metadata with tags", copyMeta: true, inheritTags: true, wantTags: []string{"team"}},
+		{name: "copied metadata without tags", copyMeta: true, inheritTags: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := newTestManager(t)
+			setupTaggedProfile(t, cm)
+			copyFileForTest(t, filepath.Join(cm.profilesDir, "work.json"), filepath.Join(cm.profilesDir, "copy.json"))
+			if tt.copyMeta {
+				copyFileForTest(t, cm.metadataPath("work"), cm.metadataPath("copy"))
+			}
+
+			if err := cm.AdoptProfile("copy", "work", tt.inheritTags); err != nil {
+				t.Fatalf("AdoptProfile: %v", err)
+			}
+
+			meta, err := cm.GetProfileMetadata("copy")
+			if err != nil {
+				t.Fatal(err)
+			}
+			original, _ := cm.GetProfileMetadata("work")
+			if meta.Name != "copy" {
+				t.Errorf("name = %q, want copy", meta.Name)
+			}
+			if meta.Template != original.Template || meta.Template == "" {
+				t.Errorf("template = %q, want %q inherited", meta.Template, original.Template)
+			}
+			if !reflect.DeepEqual(meta.Tags, tt.wantTags) {
+				t.Errorf("tags = %v, want %v", meta.Tags, tt.wantTags)
+			}
+			if meta.ContentHash != cm.profileContentHash("copy") {
+				t.Error("content hash not recorded")
+			}
+
+			if untracked, _ := cm.FindUntrackedProfiles(); len(untracked) != 0 {
+				t.Errorf("still untracked after adoption: %+v", untracked)
+			}
+		})
+	}
+}
+
+func TestAdoptMissingProfile(t *testing.T) {
+	cm := newTestManager(t)
+	if err := cm.AdoptProfile("missing", "", false); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestClosestName(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []string
+		want       string
+	}{
+		{name: "work-backup", candidates: []string{"home", "work"}, want: "work"},
+		{name: "work-2", candidates: []string{"work", "workshop"}, want: "work"},
+		{name: "copy", candidates: []string{"home", "work"}, want: "home"},
+	}
+	for _, tt := range tests {
+		if got := closestName(tt.name, tt.candidates); got != tt.want {
+			t.Errorf("closestName(%q, %v) = %q, want %q", tt.name, tt.candidates, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// ValidateAliasName 验证别名：不能为空、不能是保留名称，且不包含空白或路径分隔符
+func ValidateAliasName(alias string) error {
+	if alias == "" {
+		return Invalidf("alias cannot be empty")
+	}
+	if alias == "empty_mode" {
+		return Invalidf("'empty_mode' is a reserved name and cannot be used as an alias")
+	}
+	if strings.HasPrefix(alias, "-") || strings.HasPrefix(alias, ".") {
+		return Invalidf("invalid alias '%s': aliases cannot start with '-' or '.'", alias)
+	}
+	if strings.ContainsAny(alias, " \t\r\n/\\") {
+		return Invalidf("invalid alias '%s': aliases cannot contain whitespace or path separators", alias)
+	}
+	return nil
+}
+
+// ProfileAliases 返回所有别名到配置名的映射
+func (cm *ConfigManager) ProfileAliases() (map[string]string, error) {
+	profiles, err := cm.ListProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := make(map[string]string)
+	for _, profile := range profiles {
+		for _, alias := range profile.Aliases {
+			// 手动编辑元数据可能导致同一别名出现在多个配置上，按配置名排序取第一个
+			if _, taken := aliases[alias]; !taken {
+				aliases[alias] = profile.Name
+			}
+		}
+	}
+	return aliases, nil
+}
+
+// aliasTarget 返回别名指向的配置名
+func (cm *ConfigManager) aliasTarget(alias string) (string, bool) {
+	aliases, err := cm.ProfileAliases()
+	if err != nil {
+		return "", false
+	}
+	target, ok := aliases[alias]
+	return target, ok
+}
+
+// ResolveProfileName 将别名解析为配置名；name 本身是配置名或不是任何别名时原样返回
+// 配置名优先于别名，因此别名永远不会遮蔽已有配置
+func (cm *ConfigManager) ResolveProfileName(name string) string {
+	if name == "" || cm.ProfileExists(name) {
+		return name
+	}
+	if target, ok := cm.aliasTarget(name); ok {
+		return target
+	}
+	return name
+}
+
+// AddProfileAlias 为配置添加别名，返回是否实际新增（该配置已有此别名时返回 false）
+// 别名不能与已有配置同名，也不能已被其他配置使用
+func (cm *ConfigManager) AddProfileAlias(alias, name string) (bool, error) {
+	if err := ValidateAliasName(alias); err != nil {
+		return false, err
+	}
+
+	name = cm.ResolveProfileName(name)
+	if !cm.ProfileExists(name) {
+		return false, NotFoundf("configuration '%s' does not exist", name)
+	}
+	if cm.ProfileExists(alias) {
+		return false, Conflictf("'%s' is already a configuration name; an alias cannot shadow an existing configuration", alias)
+	}
+	if target, ok := cm.aliasTarget(alias); ok {
+		if target == name {
+			return false, nil
+		}
+		return false, Conflictf("alias '%s' already refers to configuration '%s'; remove it first with 'cc-switch alias rm %s'", alias, target, alias)
+	}
+
+	meta, err := cm.GetProfileMetadata(name)
+	if err != nil {
+		return false, err
+	}
+	meta.Aliases = append(meta.Aliases, alias)
+	sort.Strings(meta.Aliases)
+	if err := cm.saveProfileMetadata(name, meta); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RemoveProfileAlias 删除别名，返回它原先指向的配置名
+func (cm *ConfigManager) RemoveProfileAlias(alias string) (string, error) {
+	name, ok := cm.aliasTarget(alias)
+	if !ok {
+		return "", NotFoundf("alias '%s' does not exist", alias)
+	}
+
+	meta, err := cm.GetProfileMetadata(name)
+	if err != nil {
+		return "", err
+	}
+	kept := meta.Aliases[:0]
+	for _, existing := range meta.Aliases {
+		if existing != alias {
+			kept = append(kept, existing)
+		}
+	}
+	meta.Aliases = kept
+	if err := cm.saveProfileMetadata(name, meta); err != nil {
+		return "", err
+	}
+	return name, nil
+}
@@ -0,0 +1,278 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ActivityApply apply 命令对配置的改动
+const ActivityApply = "apply"
+
+// apply 计划中的变更类型
+const (
+	ApplyCreate = "create"
+	ApplyUpdate = "update"
+	ApplyDelete = "delete"
+)
+
+// DesiredState apply 命令读取的期望状态文件
+type DesiredState struct {
+	Profiles []DesiredProfile `json:"profiles"`
+}
+
+// DesiredProfile 期望状态中的一个配置
+// Template 为空时新配置使用默认模板，已有配置只核对 Env 中列出的字段；Tags 为 null 或省略时不改动标签
+type DesiredProfile struct {
+	Name     string            `json:"name"`
+	Template string            `json:"template,omitempty"`
+	Env      map[string]string `json:"env,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
+}
+
+// ApplyChange apply 计划中的一项变更
+type ApplyChange struct {
+	Action   string   `json:"action"`
+	Profile  string   `json:"profile"`
+	Template string   `json:"template,omitempty"`
+	Fields   []string `json:"fields,omitempty"` // 变化的字段路径；标签和来源模板的变化记为 "tags" 和 "template"
+
+	content map[string]interface{} // 写入的内容，更新时为 nil 表示内容不变
+	tags    []string               // 写入的标签，nil 表示不改动
+}
+
+// LoadDesiredState 读取并检查期望状态文件（JSON）
+// 未知字段视为错误，以免拼写错误的字段被静默忽略；不支持 YAML，也没有锁定状态字段（cc-switch 没有配置锁定）
+func LoadDesiredState(path string) (*DesiredState, error) {
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		return nil, Invalidf("YAML state files are not supported; convert %s to JSON (for example 'yq -o json %s')", path, path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state file: %w", err)
+	}
+	defer file.Close()
+
+	var state DesiredState
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&state); err != nil {
+		return nil, Invalidf("invalid state file (expected {\"profiles\": [{name, template, env, tags}]}): %w", err)
+	}
+
+	seen := make(map[string]int, len(state.Profiles))
+	for i := range state.Profiles {
+		profile := &state.Profiles[i]
+		profile.Name = strings.TrimSpace(profile.Name)
+		if profile.Name == "" {
+			return nil, Invalidf("profile %d in the state file has no name", i+1)
+		}
+		if first, ok := seen[profile.Name]; ok {
+			return nil, Invalidf("duplicate name '%s' in the state file (profiles %d and %d)", profile.Name, first, i+1)
+		}
+		seen[profile.Name] = i + 1
+
+		if profile.Tags != nil {
+			tags := make([]string, 0, len(profile.Tags))
+			for _, tag := range profile.Tags {
+				normalized, err := NormalizeTag(tag)
+				if err != nil {
+					return nil, Invalidf("profile '%s': %w", profile.Name, err)
+				}
+				if !slices.Contains(tags, normalized) {
+					tags = append(tags, normalized)
+				}
+			}
+			sort.Strings(tags)
+			profile.Tags = tags
+		}
+	}
+
+	return &state, nil
+}
+
+// PlanApply 比较期望状态与现有配置，返回需要的变更（不写入任何文件）
+// 变更按 创建、更新、删除 排序；prune 为 true 时删除未列出的配置（只读配置和快照除外）
+func (cm *ConfigManager) PlanApply(state *DesiredState, prune bool) ([]ApplyChange, error) {
+	var creates, updates, deletes []ApplyChange
+	listed := make(map[string]bool, len(state.Profiles))
+
+	for _, desired := range state.Profiles {
+		listed[desired.Name] = true
+
+		change, err := cm.planProfile(desired)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case change == nil:
+		case change.Action == ApplyCreate:
+			creates = append(creates, *change)
+		default:
+			updates = append(updates, *change)
+		}
+	}
+
+	if prune {
+		profiles, err := cm.ListProfiles()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list profiles: %w", err)
+		}
+		for _, profile := range profiles {
+			if listed[profile.Name] || profile.ReadOnly || profile.Snapshot {
+				continue
+			}
+			if profile.IsCurrent {
+				return nil, Conflictf("cannot prune the current configuration '%s'; switch to a listed configuration first", profile.Name)
+			}
+			deletes = append(deletes, ApplyChange{Action: ApplyDelete, Profile: profile.Name})
+		}
+	}
+
+	changes := append(creates, updates...)
+	return append(changes, deletes...), nil
+}
+
+// planProfile 计算单个配置的变更，已经一致时返回 nil
+func (cm *ConfigManager) planProfile(desired DesiredProfile) (*ApplyChange, error) {
+	name := desired.Name
+	if err := cm.validateProfileName(name); err != nil {
+		return nil, err
+	}
+	// 状态文件通常来自仓库，拒绝可能逃出 profiles 目录的名称
+	if strings.ContainsAny(name, `/\`) || name == "." || name == ".." || strings.HasPrefix(name, ".") {
+		return nil, Invalidf("invalid profile name '%s'", name)
+	}
+
+	if desired.Template != "" && desired.Template != "default" && !cm.TemplateExists(desired.Template) {
+		return nil, NotFoundf("profile '%s': template '%s' does not exist", name, desired.Template)
+	}
+
+	values := make(map[string]string, len(desired.Env))
+	for key, value := range desired.Env {
+		values["env."+key] = value
+	}
+
+	if !cm.ProfileExists(name) {
+		templateName, warning := cm.ResolveTemplate(desired.Template)
+		if warning != "" {
+			fmt.Fprintf(cm.warnings, "Warning: %s\n", warning)
+		}
+		template, err := cm.GetTemplateContent(templateName)
+		if err != nil {
+			return nil, err
+		}
+		content := cm.PopulateTemplate(template, values)
+		if err := checkApplyContent(name, content); err != nil {
+			return nil, err
+		}
+		return &ApplyChange{Action: ApplyCreate, Profile: name, Template: templateName, content: content, tags: desired.Tags}, nil
+	}
+
+	if err := cm.CheckProfileModifiable(name); err != nil {
+		return nil, err
+	}
+	current, _, err := cm.GetProfileContent(name)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := cm.GetProfileMetadata(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// 指定模板时整个配置由模板和 Env 决定，否则只核对 Env 中列出的字段
+	base := current
+	if desired.Template != "" {
+		if base, err = cm.GetTemplateContent(desired.Template); err != nil {
+			return nil, err
+		}
+	}
+	content := cm.PopulateTemplate(base, values)
+
+	change := &ApplyChange{Action: ApplyUpdate, Profile: name, Template: desired.Template}
+	if diffs := DiffContent(current, content); len(diffs) > 0 {
+		if err := checkApplyContent(name, content); err != nil {
+			return nil, err
+		}
+		change.content = content
+		for _, diff := range diffs {
+			change.Fields = append(change.Fields, diff.Path)
+		}
+	}
+	if desired.Template != "" && meta.Template != desired.Template {
+		change.Fields = append(change.Fields, "template")
+	}
+	if desired.Tags != nil && !reflect.DeepEqual(desired.Tags, sortedTags(meta.Tags)) {
+		change.tags = desired.Tags
+		change.Fields = append(change.Fields, "tags")
+	}
+
+	if len(change.Fields) == 0 {
+		return nil, nil
+	}
+	return change, nil
+}
+
+// checkApplyContent 拒绝校验出错误的内容，与批量创建的检查一致
+func checkApplyContent(name string, content map[string]interface{}) error {
+	for _, issue := range ValidateContent(content, false) {
+		if issue.Severity == SeverityError {
+			return Invalidf("profile '%s': invalid content at '%s': %s", name, issue.Path, issue.Message)
+		}
+	}
+	return nil
+}
+
+// sortedTags 返回排序后的标签，nil 视为空列表
+func sortedTags(tags []string) []string {
+	sorted := append([]string{}, tags...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+// Apply 执行计划中的一项变更，并记录到活动日志
+func (cm *ConfigManager) Apply(change ApplyChange) error {
+	switch change.Action {
+	case ApplyCreate:
+		if err := cm.CreateProfileWithContent(change.Profile, change.content); err != nil {
+			return err
+		}
+		cm.setProfileTemplate(change.Profile, change.Template)
+		cm.setProfileOrigin(change.Profile, OriginTemplate(change.Template))
+	case ApplyUpdate:
+		if change.content != nil {
+			if err := cm.UpdateProfile(change.Profile, change.content); err != nil {
+				return err
+			}
+		}
+		if change.Template != "" {
+			cm.setProfileTemplate(change.Profile, change.Template)
+		}
+	case ApplyDelete:
+		if err := cm.DeleteProfile(change.Profile); err != nil {
+			return err
+		}
+	default:
+		return Invalidf("unknown apply action '%s'", change.Action)
+	}
+
+	if change.tags != nil && change.Action != ApplyDelete {
+		if err := cm.setProfileTags(change.Profile, change.tags); err != nil {
+			return err
+		}
+	}
+
+	cm.LogActivity(ActivityEntry{
+		Action:  ActivityApply,
+		Profile: change.Profile,
+		Detail:  change.Action,
+	})
+	return nil
+}
@@ -0,0 +1,227 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeState writes a desired-state file and returns its path
+func writeState(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDesiredState(t *testing.T) {
+	state, err := LoadDesiredState(writeState(t, "state.json", `{"profiles": [{"name": " work ", "tags": ["Team", "alpha", "team"]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DesiredProfile{{Name: "work", Tags: []string{"alpha", "team"}}}
+	if !reflect.DeepEqual(state.Profiles, want) {
+		t.Errorf("profiles = %+v, want %+v", state.Profiles, want)
+	}
+
+	tests := map[string]struct{ file, content string }{
+		"unknown field":  {"state.json", `{"profiles": [{"name": "work", "locked": true}]}`},
+		"missing name":   {"state.json", `{"profiles": [{"env": {}}]}`},
+		"duplicate name": {"state.json", `{"profiles": [{"name": "work"}, {"name": "work"}]}`},
+		"invalid tag":    {"state.json", `{"profiles": [{"name": "work", "tags": ["a b"]}]}`},
+		"yaml":           {"state.yaml", "profiles:\n  - name: work\n"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadDesiredState(writeState(t, tt.file, tt.content)); !errors.Is(err, ErrInvalid) {
+				t.Errorf("LoadDesiredState = %v, want ErrInvalid", err)
+			}
+		})
+	}
+}
+
+// setupApply creates work (current) and old, and a company template
+func setupApply(t *testing.T) *ConfigManager {
+	t.Helper()
+	cm := newTestManager(t)
+	for _, name := range []string{"work", "old"} {
+		content := map[string]interface{}{"env": map[string]interface{}{"ANTHROPIC_BASE_URL": "https://" + name + ".example.com"}}
+		if err := cm.CreateProfileWithContent(name, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cm.UseProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.CreateTemplate("company"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.UpdateTemplate("company", map[string]interface{}{"env": map[string]interface{}{"ANTHROPIC_BASE_URL": ""}}); err != nil {
+		t.Fatal(err)
+	}
+	return cm
+}
+
+// planSummary reduces a plan to "action profile" pairs with the changed fields
+func planSummary(changes []ApplyChange) [][]string {
+	var summary [][]string
+	for _, change := range changes {
+		summary = append(summary, append([]string{change.Action, change.Profile}, change.Fields...))
+	}
+	return summary
+}
+
+func TestPlanApply(t *testing.T) {
+	work := DesiredProfile{Name: "work", Env: map[string]string{"ANTHROPIC_BASE_URL": "https://work.example.com"}}
+	old := DesiredProfile{Name: "old"}
+
+	tests := []struct {
+		name     string
+		profiles []DesiredProfile
+		prune    bool
+		want     [][]string
+	}{
+		{"unchanged", []DesiredProfile{work, old}, false, nil},
+		{"unlisted kept without prune", []DesiredProfile{work}, false, nil},
+		{
+			name:     "create",
+			profiles: []DesiredProfile{work, {Name: "new", Template: "company", Env: map[string]string{"ANTHROPIC_BASE_URL": "https://new.example.com"}}},
+			want:     [][]string{{ApplyCreate, "new"}},
+		},
+		{
+			name:     "update env",
+			profiles: []DesiredProfile{{Name: "work", Env: map[string]string{"ANTHROPIC_BASE_URL": "https://moved.example.com"}}},
+			want:     [][]string{{ApplyUpdate, "work", "env.ANTHROPIC_BASE_URL"}},
+		},
+		{
+			name:     "update tags",
+			profiles: []DesiredProfile{{Name: "work", Tags: []string{"team"}}},
+			want:     [][]string{{ApplyUpdate, "work", "tags"}},
+		},
+		{
+			name:     "update template",
+			profiles: []DesiredProfile{{Name: "old", Template: "company", Env: map[string]string{"ANTHROPIC_BASE_URL": "https://old.example.com"}}},
+			want:     [][]string{{ApplyUpdate, "old", "template"}},
+		},
+		{
+			name:     "prune",
+			profiles: []DesiredProfile{work, {Name: "new"}},
+			prune:    true,
+			want:     [][]string{{ApplyCreate, "new"}, {ApplyDelete, "old"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := setupApply(t)
+			changes, err := cm.PlanApply(&DesiredState{Profiles: tt.profiles}, tt.prune)
+			if err != nil {
+				t.Fatalf("PlanApply: %v", err)
+			}
+			if got := planSummary(changes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("plan = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanApplyNeverPrunesCurrent(t *testing.T) {
+	cm := setupApply(t)
+	before := snapshotTree(t, cm.claudeDir)
+
+	_, err := cm.PlanApply(&DesiredState{Profiles: []DesiredProfile{{Name: "old"}}}, true)
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("PlanApply = %v, want ErrConflict for the current configuration", err)
+	}
+	compareTrees(t, before, snapshotTree(t, cm.claudeDir))
+	if !cm.ProfileExists("work") {
+		t.Error("current configuration was removed")
+	}
+}
+
+func TestPlanApplyRejectsInvalidProfiles(t *testing.T) {
+	tests := map[string]DesiredProfile{
+		"path name":        {Name: "../escape"},
+		"hidden name":      {Name: ".hidden"},
+		"missing template": {Name: "new", Template: "missing"},
+	}
+	for name, profile := range tests {
+		t.Run(name, func(t *testing.T) {
+			cm := setupApply(t)
+			if _, err := cm.PlanApply(&DesiredState{Profiles: []DesiredProfile{profile}}, false); err == nil {
+				t.Error("PlanApply accepted an invalid profile")
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	cm := setupApply(t)
+	state := &DesiredState{Profiles: []DesiredProfile{
+		{Name: "work", Env: map[string]string{"ANTHROPIC_BASE_URL": "https://moved.example.com"}, Tags: []string{"team"}},
+		{Name: "new", Template: "company", Env: map[string]string{"ANTHROPIC_BASE_URL": "https://new.example.com"}, Tags: []string{"alpha"}},
+	}}
+
+	changes, err := cm.PlanApply(state, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, change := range changes {
+		if err := cm.Apply(change); err != nil {
+			t.Fatalf("Apply(%s %s): %v", change.Action, change.Profile, err)
+		}
+	}
+
+	if cm.ProfileExists("old") {
+		t.Error("old was not pruned")
+	}
+	content, _, err := cm.GetProfileContent("new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := content["env"].(map[string]interface{})["ANTHROPIC_BASE_URL"]; got != "https://new.example.com" {
+		t.Errorf("new base URL = %v", got)
+	}
+	if meta, _ := cm.GetProfileMetadata("new"); meta.Template != "company" || !reflect.DeepEqual(meta.Tags, []string{"alpha"}) {
+		t.Errorf("new metadata = %+v, want template company and tag alpha", meta)
+	}
+	if tags, _ := cm.GetProfileTags("work"); !reflect.DeepEqual(tags, []string{"team"}) {
+		t.Errorf("work tags = %v, want [team]", tags)
+	}
+	// The current configuration is synced to settings.json
+	data, err := os.ReadFile(cm.settingsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "https://moved.example.com") {
+		t.Errorf("settings.json = %s, want the updated base URL", data)
+	}
+
+	// Applying the same state again changes nothing
+	changes, err = cm.PlanApply(state, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("second plan = %v, want no changes", planSummary(changes))
+	}
+
+	entries, err := cm.ReadActivity(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied := 0
+	for _, entry := range entries {
+		if entry.Action == ActivityApply {
+			applied++
+		}
+	}
+	if applied != 3 {
+		t.Errorf("logged %d apply entries, want 3", applied)
+	}
+}
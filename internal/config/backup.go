@@ -0,0 +1,192 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 备份相关文件名
+const (
+	defaultBackupDirName = "cc-switch-backups" // 默认备份目录，位于用户主目录下
+	lastBackupFileName   = ".last_backup"      // 最近一次备份记录，位于数据目录下
+)
+
+// BackupRecord 最近一次备份的记录
+type BackupRecord struct {
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+	Profiles  int       `json:"profiles"`
+	Templates int       `json:"templates"`
+}
+
+// BackupDir 返回备份目录：全局配置 backup.dir，未设置时为 ~/cc-switch-backups
+func (cm *ConfigManager) BackupDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	cfg, err := cm.LoadGlobalConfig()
+	if err != nil {
+		return "", err
+	}
+
+	dir := strings.TrimSpace(cfg.Backup.Dir)
+	switch {
+	case dir == "":
+		return filepath.Join(homeDir, defaultBackupDirName), nil
+	case dir == "~":
+		return homeDir, nil
+	case strings.HasPrefix(dir, "~/"):
+		return filepath.Join(homeDir, dir[2:]), nil
+	}
+	return filepath.Abs(dir)
+}
+
+// NewBackupPath 返回备份目录下带时间戳的新备份文件路径
+func (cm *ConfigManager) NewBackupPath() (string, error) {
+	dir, err := cm.BackupDir()
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("cc-switch-backup-%s.ccx", time.Now().Format("20060102-150405"))
+	return filepath.Join(dir, name), nil
+}
+
+// ListBackups 列出备份目录中的 .ccx 文件，最新的在前
+func (cm *ConfigManager) ListBackups() ([]string, error) {
+	dir, err := cm.BackupDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	type backupFile struct {
+		path    string
+		modTime time.Time
+	}
+	var files []backupFile
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".ccx") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, backupFile{path: filepath.Join(dir, entry.Name()), modTime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.path)
+	}
+	return paths, nil
+}
+
+// RecordBackup 记录最近一次备份
+func (cm *ConfigManager) RecordBackup(record BackupRecord) error {
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal backup record: %w", err)
+	}
+	if err := cm.fs.WriteFile(cm.dataFile(lastBackupFileName), data, 0600, false); err != nil {
+		return fmt.Errorf("failed to save backup record: %w", err)
+	}
+	return nil
+}
+
+// LastBackup 返回最近一次备份的记录，从未备份时返回 nil
+func (cm *ConfigManager) LastBackup() (*BackupRecord, error) {
+	data, err := os.ReadFile(cm.dataFile(lastBackupFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read backup record: %w", err)
+	}
+
+	var record BackupRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse backup record: %w", err)
+	}
+	return &record, nil
+}
+
+// backupFilePattern backup 命令生成的备份文件名；清理时只删除匹配的文件，不碰用户放在同一目录的其他导出
+const backupFilePattern = "cc-switch-backup-*.ccx"
+
+// RetentionPolicy 备份保留策略，零值表示全部保留
+// 两个条件同时设置时，超出任一条件的文件都会被删除
+type RetentionPolicy struct {
+	Keep     int // 最多保留的份数
+	KeepDays int // 保留最近多少天内的备份
+}
+
+// IsZero 策略是否为空（不清理任何文件）
+func (p RetentionPolicy) IsZero() bool {
+	return p.Keep <= 0 && p.KeepDays <= 0
+}
+
+// PruneBackups 按策略清理 dir 中由 backup 命令生成的旧备份，返回被删除的文件
+// 删除经由 cm.fs，只读模式下只报告将被删除的文件
+func (cm *ConfigManager) PruneBackups(dir string, policy RetentionPolicy) ([]string, error) {
+	return pruneByPolicy(cm.fs, dir, backupFilePattern, policy, time.Now())
+}
+
+// pruneByPolicy 按修改时间删除 dir 中匹配 pattern 且超出策略的文件，最新的一个始终保留
+func pruneByPolicy(fs fileSystem, dir, pattern string, policy RetentionPolicy, now time.Time) ([]string, error) {
+	if policy.IsZero() {
+		return nil, nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern '%s': %w", pattern, err)
+	}
+
+	type candidate struct {
+		path    string
+		modTime time.Time
+	}
+	var files []candidate
+	for _, path := range paths {
+		info, err := os.Lstat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, candidate{path: path, modTime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+
+	cutoff := now.AddDate(0, 0, -policy.KeepDays)
+	var removed []string
+	for i, file := range files {
+		if i == 0 {
+			continue
+		}
+		overCount := policy.Keep > 0 && i >= policy.Keep
+		tooOld := policy.KeepDays > 0 && file.modTime.Before(cutoff)
+		if !overCount && !tooOld {
+			continue
+		}
+		if err := fs.Remove(file.path); err != nil {
+			return removed, fmt.Errorf("failed to remove old backup %s: %w", file.path, err)
+		}
+		removed = append(removed, file.path)
+	}
+	return removed, nil
+}
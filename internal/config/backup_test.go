@@ -0,0 +1,134 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// writeBackups creates a backup file per entry, aged by the given number of days before now
+func writeBackups(t *testing.T, dir string, now time.Time, ages map[string]int) {
+	t.Helper()
+	for name, days := range ages {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("backup"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		modTime := now.AddDate(0, 0, -days)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// remainingFiles lists the names left in dir, sorted
+func remainingFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestPruneByPolicy(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	ages := map[string]int{
+		"cc-switch-backup-a.ccx": 1,
+		"cc-switch-backup-b.ccx": 5,
+		"cc-switch-backup-c.ccx": 10,
+		"cc-switch-backup-d.ccx": 40,
+		"team-export.ccx":        90, // not made by backup, never removed
+	}
+
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		want   []string
+	}{
+		{"zero keeps everything", RetentionPolicy{}, []string{"cc-switch-backup-a.ccx", "cc-switch-backup-b.ccx", "cc-switch-backup-c.ccx", "cc-switch-backup-d.ccx", "team-export.ccx"}},
+		{"keep", RetentionPolicy{Keep: 2}, []string{"cc-switch-backup-a.ccx", "cc-switch-backup-b.ccx", "team-export.ccx"}},
+		{"keep days", RetentionPolicy{KeepDays: 7}, []string{"cc-switch-backup-a.ccx", "cc-switch-backup-b.ccx", "team-export.ccx"}},
+		{"either limit removes", RetentionPolicy{Keep: 3, KeepDays: 7}, []string{"cc-switch-backup-a.ccx", "cc-switch-backup-b.ccx", "team-export.ccx"}},
+		{"more than exist", RetentionPolicy{Keep: 10, KeepDays: 365}, []string{"cc-switch-backup-a.ccx", "cc-switch-backup-b.ccx", "cc-switch-backup-c.ccx", "cc-switch-backup-d.ccx", "team-export.ccx"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeBackups(t, dir, now, ages)
+
+			removed, err := pruneByPolicy(osFileSystem{}, dir, backupFilePattern, tt.policy, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := remainingFiles(t, dir)
+			if !equalStrings(got, tt.want) {
+				t.Errorf("remaining = %v, want %v", got, tt.want)
+			}
+			if len(removed)+len(got) != len(ages) {
+				t.Errorf("removed = %v, does not account for the deleted files", removed)
+			}
+		})
+	}
+}
+
+func TestPruneByPolicyKeepsNewest(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	writeBackups(t, dir, now, map[string]int{"cc-switch-backup-old.ccx": 100, "cc-switch-backup-older.ccx": 200})
+
+	if _, err := pruneByPolicy(osFileSystem{}, dir, backupFilePattern, RetentionPolicy{KeepDays: 1}, now); err != nil {
+		t.Fatal(err)
+	}
+	if got := remainingFiles(t, dir); !equalStrings(got, []string{"cc-switch-backup-old.ccx"}) {
+		t.Errorf("remaining = %v, want the newest backup kept", got)
+	}
+}
+
+func TestPruneByPolicySkipsNonRegularFiles(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	writeBackups(t, dir, now, map[string]int{"cc-switch-backup-new.ccx": 1})
+	if err := os.Mkdir(filepath.Join(dir, "cc-switch-backup-dir.ccx"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := pruneByPolicy(osFileSystem{}, dir, backupFilePattern, RetentionPolicy{Keep: 1}, now); err != nil {
+		t.Fatal(err)
+	}
+	if got := remainingFiles(t, dir); len(got) != 2 {
+		t.Errorf("remaining = %v, want the directory left alone", got)
+	}
+}
+
+// failingRemove fails every Remove call
+type failingRemove struct {
+	fileSystem
+}
+
+func (failingRemove) Remove(path string) error {
+	return &os.PathError{Op: "remove", Path: path, Err: syscall.EACCES}
+}
+
+func TestPruneByPolicyReportsRemoveFailure(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	writeBackups(t, dir, now, map[string]int{"cc-switch-backup-a.ccx": 1, "cc-switch-backup-b.ccx": 2})
+
+	removed, err := pruneByPolicy(failingRemove{osFileSystem{}}, dir, backupFilePattern, RetentionPolicy{Keep: 1}, now)
+	if err == nil {
+		t.Fatal("err = nil, want the remove failure")
+	}
+	if len(removed) != 0 {
+		t.Errorf("removed = %v, want nothing reported as removed", removed)
+	}
+}
@@ -0,0 +1,47 @@
+// Package configtest creates configuration managers for tests outside package config.
+package configtest
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/testutil"
+)
+
+// NewManager creates a configuration manager in a fresh temporary home
+func NewManager(t testing.TB, options config.Options) *config.ConfigManager {
+	t.Helper()
+	testutil.SetupHome(t)
+	return NewManagerInCurrentHome(t, options)
+}
+
+// NewManagerInCurrentHome creates a configuration manager in the home the test already
+// points at. Warnings are discarded unless options.Warnings is set.
+func NewManagerInCurrentHome(t testing.TB, options config.Options) *config.ConfigManager {
+	t.Helper()
+	if options.Warnings == nil {
+		options.Warnings = io.Discard
+	}
+	cm, err := config.NewConfigManagerWithOptions(options)
+	if err != nil {
+		t.Fatalf("NewConfigManagerWithOptions: %v", err)
+	}
+	RestoreGlobalConfig(t)
+	return cm
+}
+
+// RestoreGlobalConfig restores the default global settings when the test ends. Loading
+// a manager applies the global config (token keys, field rules, verify_switch, ...) to
+// package state, which would otherwise leak into later tests.
+func RestoreGlobalConfig(t testing.TB) {
+	t.Cleanup(func() {
+		cm, err := config.NewConfigManagerWithOptions(config.Options{Warnings: io.Discard})
+		if err != nil {
+			return
+		}
+		os.Remove(cm.GlobalConfigPath())
+		config.NewConfigManagerWithOptions(config.Options{Warnings: io.Discard})
+	})
+}
@@ -0,0 +1,51 @@
+package config
+
+import "fmt"
+
+// DefaultTemplateName 返回 new 未指定模板时使用的模板名（仅读取设置，不检查模板是否存在）
+func (cm *ConfigManager) DefaultTemplateName() string {
+	cfg, err := cm.LoadGlobalConfig()
+	if err != nil || cfg.DefaultTemplate == "" {
+		return "default"
+	}
+	return cfg.DefaultTemplate
+}
+
+// ResolveTemplate 按 显式指定 > default_template 设置 > default 的顺序确定创建配置使用的模板
+// 指定的模板不存在时回退到下一级，并通过 warning 说明原因（无回退时为空）
+func (cm *ConfigManager) ResolveTemplate(explicit string) (name string, warning string) {
+	configured := cm.DefaultTemplateName()
+
+	if explicit != "" {
+		if explicit == "default" || cm.TemplateExists(explicit) {
+			return explicit, ""
+		}
+		fallback := configured
+		if !cm.TemplateExists(fallback) {
+			fallback = "default"
+		}
+		return fallback, fmt.Sprintf("template '%s' not found, using template '%s'", explicit, fallback)
+	}
+
+	if configured != "default" && !cm.TemplateExists(configured) {
+		return "default", fmt.Sprintf("default template '%s' (default_template setting) not found, using template 'default'", configured)
+	}
+	return configured, ""
+}
+
+// SetDefaultTemplate 设置 new 默认使用的模板，传入空字符串或 default 时清除设置
+func (cm *ConfigManager) SetDefaultTemplate(name string) error {
+	if name != "" && name != "default" && !cm.TemplateExists(name) {
+		return NotFoundf("template '%s' does not exist", name)
+	}
+
+	cfg, err := cm.LoadGlobalConfig()
+	if err != nil {
+		return err
+	}
+	if name == "default" {
+		name = ""
+	}
+	cfg.DefaultTemplate = name
+	return cm.SaveGlobalConfig(cfg)
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveTemplate(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string // default_template setting
+		removed    bool   // the configured template file was removed by hand after being set
+		explicit   string
+		want       string
+		warning    string
+	}{
+		{name: "nothing set", want: "default"},
+		{name: "setting", configured: "team", want: "team"},
+		{name: "explicit beats setting", configured: "team", explicit: "solo", want: "solo"},
+		{name: "explicit default beats setting", configured: "team", explicit: "default", want: "default"},
+		{name: "missing explicit falls back to setting", configured: "team", explicit: "gone", want: "team", warning: "template 'gone' not found, using template 'team'"},
+		{name: "missing explicit falls back to default", explicit: "gone", want: "default", warning: "template 'gone' not found, using template 'default'"},
+		{name: "missing setting falls back to default", configured: "team", removed: true, want: "default", warning: "default template 'team' (default_template setting) not found"},
+		{name: "missing explicit and setting", configured: "team", removed: true, explicit: "gone", want: "default", warning: "template 'gone' not found, using template 'default'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := newTestManager(t)
+			for _, name := range []string{"team", "solo"} {
+				if err := cm.CreateTemplate(name); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.configured != "" {
+				if err := cm.SetDefaultTemplate(tt.configured); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.removed {
+				if err := os.Remove(filepath.Join(cm.templatesDir, tt.configured+".json")); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, warning := cm.ResolveTemplate(tt.explicit)
+			if got != tt.want {
+				t.Errorf("template = %q, want %q", got, tt.want)
+			}
+			if (tt.warning == "") != (warning == "") || !strings.Contains(warning, tt.warning) {
+				t.Errorf("warning = %q, want %q", warning, tt.warning)
+			}
+		})
+	}
+}
+
+func TestSetDefaultTemplate(t *testing.T) {
+	cm := newTestManager(t)
+	if err := cm.CreateTemplate("team"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cm.SetDefaultTemplate("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing template: err = %v, want ErrNotFound", err)
+	}
+
+	if err := cm.SetDefaultTemplate("team"); err != nil {
+		t.Fatal(err)
+	}
+	if got := cm.DefaultTemplateName(); got != "team" {
+		t.Errorf("default template = %q, want team", got)
+	}
+
+	// Setting default clears the setting
+	if err := cm.SetDefaultTemplate("default"); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := cm.LoadGlobalConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DefaultTemplate != "" {
+		t.Errorf("default_template = %q, want it cleared", cfg.DefaultTemplate)
+	}
+}
+
+func TestDeleteTemplateClearsDefault(t *testing.T) {
+	cm := newTestManager(t)
+	if err := cm.CreateTemplate("team"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.SetDefaultTemplate("team"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.DeleteTemplate("team"); err != nil {
+		t.Fatal(err)
+	}
+	if got, warning := cm.ResolveTemplate(""); got != "default" || warning != "" {
+		t.Errorf("ResolveTemplate = %q, %q; want default without a warning", got, warning)
+	}
+}
+
+func TestCreateProfileUsesDefaultTemplate(t *testing.T) {
+	cm := newTestManager(t)
+	if err := cm.CreateTemplate("team"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.SetDefaultTemplate("team"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cm.CreateProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	meta, err := cm.GetProfileMetadata("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Template != "team" {
+		t.Errorf("template = %q, want team", meta.Template)
+	}
+}
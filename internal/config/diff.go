@@ -0,0 +1,217 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// 差异类型
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+	DiffUnset   = "unset" // 模板中的占位字段（空字符串）在另一侧没有值
+)
+
+// DiffKinds 所有差异类型，按输出顺序排列
+var DiffKinds = []string{DiffAdded, DiffRemoved, DiffChanged, DiffUnset}
+
+// 比较对象类型
+const (
+	OperandProfile  = "profile"
+	OperandTemplate = "template"
+	OperandSettings = "settings"
+)
+
+// DiffOperand 参与比较的一方：配置、模板或 Claude Code 正在使用的 settings.json
+type DiffOperand struct {
+	Kind string `json:"kind"`           // OperandProfile、OperandTemplate 或 OperandSettings
+	Name string `json:"name,omitempty"` // settings 时为空
+}
+
+// ParseDiffOperand 解析 "profile:work"、"template:team" 或 "settings"，不带前缀的名称视为配置
+func ParseDiffOperand(spec string) (DiffOperand, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == OperandSettings {
+		return DiffOperand{Kind: OperandSettings}, nil
+	}
+
+	kind, name := OperandProfile, spec
+	if prefix, rest, found := strings.Cut(spec, ":"); found && (prefix == OperandProfile || prefix == OperandTemplate) {
+		kind, name = prefix, rest
+	}
+	if name == "" {
+		return DiffOperand{}, Invalidf("invalid operand '%s': a %s name is required", spec, kind)
+	}
+	return DiffOperand{Kind: kind, Name: name}, nil
+}
+
+// String 返回 ParseDiffOperand 可解析的形式，如 "template:team"
+func (o DiffOperand) String() string {
+	if o.Kind == OperandSettings {
+		return OperandSettings
+	}
+	return o.Kind + ":" + o.Name
+}
+
+// LoadDiffOperand 读取比较对象的内容；settings.json 不存在（如空配置模式）时返回 nil 内容
+func (cm *ConfigManager) LoadDiffOperand(operand DiffOperand) (map[string]interface{}, error) {
+	switch operand.Kind {
+	case OperandProfile:
+		if !cm.ProfileExists(operand.Name) {
+			return nil, NotFoundf("configuration '%s' does not exist", operand.Name)
+		}
+		content, _, err := cm.GetProfileContent(operand.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read configuration '%s': %w", operand.Name, err)
+		}
+		return content, nil
+	case OperandTemplate:
+		if !cm.TemplateExists(operand.Name) {
+			return nil, NotFoundf("template '%s' does not exist", operand.Name)
+		}
+		content, err := cm.GetTemplateContent(operand.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template '%s': %w", operand.Name, err)
+		}
+		return content, nil
+	case OperandSettings:
+		return cm.GetSettingsContent()
+	default:
+		return nil, fmt.Errorf("unknown operand type '%s'", operand.Kind)
+	}
+}
+
+// DiffOperands 比较两个对象（配置、模板或 settings.json 任意组合）
+// 模板中的空字符串是待填写的占位符：另一侧有值时不算差异，另一侧没有该字段时记为 DiffUnset
+func (cm *ConfigManager) DiffOperands(left, right DiffOperand) ([]DiffEntry, error) {
+	from, err := cm.LoadDiffOperand(left)
+	if err != nil {
+		return nil, err
+	}
+	to, err := cm.LoadDiffOperand(right)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := DiffContent(from, to)
+	leftTemplate, rightTemplate := left.Kind == OperandTemplate, right.Kind == OperandTemplate
+	if !leftTemplate && !rightTemplate {
+		return diffs, nil
+	}
+
+	filtered := diffs[:0]
+	for _, diff := range diffs {
+		oldPlaceholder := leftTemplate && diff.Old == ""
+		newPlaceholder := rightTemplate && diff.New == ""
+		switch {
+		case diff.Kind == DiffChanged && (oldPlaceholder || newPlaceholder):
+			continue
+		case diff.Kind == DiffRemoved && oldPlaceholder, diff.Kind == DiffAdded && newPlaceholder:
+			diff.Kind = DiffUnset
+		}
+		filtered = append(filtered, diff)
+	}
+	return filtered, nil
+}
+
+// SummarizeDiff 按差异类型统计数量，所有类型都会出现（没有时为 0）
+func SummarizeDiff(diffs []DiffEntry) map[string]int {
+	summary := make(map[string]int, len(DiffKinds))
+	for _, kind := range DiffKinds {
+		summary[kind] = 0
+	}
+	for _, diff := range diffs {
+		summary[diff.Kind]++
+	}
+	return summary
+}
+
+// DiffEntry 两份配置内容之间的一处差异
+type DiffEntry struct {
+	Path string      `json:"path"` // 字段路径，嵌套对象以 "." 连接，如 "env.ANTHROPIC_BASE_URL"
+	Kind string      `json:"kind"` // DiffAdded、DiffRemoved、DiffChanged 或 DiffUnset
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// DiffContent 比较两份配置内容，返回按路径排序的差异
+// 嵌套对象逐字段比较，数组和其他值整体比较；from 为 nil 时所有字段视为新增，to 为 nil 时视为删除
+func DiffContent(from, to map[string]interface{}) []DiffEntry {
+	diffs := []DiffEntry{}
+	diffObjects("", from, to, &diffs)
+	sort.SliceStable(diffs, func(i, j int) bool {
+		return diffs[i].Path < diffs[j].Path
+	})
+	return diffs
+}
+
+// diffObjects 递归比较两个对象
+func diffObjects(prefix string, from, to map[string]interface{}, diffs *[]DiffEntry) {
+	for key, oldValue := range from {
+		path := joinDiffPath(prefix, key)
+		newValue, exists := to[key]
+		if !exists {
+			// 非空对象展开到叶子字段，便于逐项查看（也避免整体输出凭据）
+			if object, ok := oldValue.(map[string]interface{}); ok && len(object) > 0 {
+				diffObjects(path, object, nil, diffs)
+				continue
+			}
+			*diffs = append(*diffs, DiffEntry{Path: path, Kind: DiffRemoved, Old: oldValue})
+			continue
+		}
+
+		oldObject, oldIsObject := oldValue.(map[string]interface{})
+		newObject, newIsObject := newValue.(map[string]interface{})
+		if oldIsObject && newIsObject {
+			diffObjects(path, oldObject, newObject, diffs)
+			continue
+		}
+
+		if !reflect.DeepEqual(oldValue, newValue) {
+			*diffs = append(*diffs, DiffEntry{Path: path, Kind: DiffChanged, Old: oldValue, New: newValue})
+		}
+	}
+
+	for key, newValue := range to {
+		if _, exists := from[key]; !exists {
+			path := joinDiffPath(prefix, key)
+			if object, ok := newValue.(map[string]interface{}); ok && len(object) > 0 {
+				diffObjects(path, nil, object, diffs)
+				continue
+			}
+			*diffs = append(*diffs, DiffEntry{Path: path, Kind: DiffAdded, New: newValue})
+		}
+	}
+}
+
+// joinDiffPath 拼接字段路径
+func joinDiffPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// GetSettingsContent 读取 Claude Code 正在使用的 settings.json
+// 文件不存在（如空配置模式）时返回 nil 内容而不是错误
+func (cm *ConfigManager) GetSettingsContent() (map[string]interface{}, error) {
+	data, err := os.ReadFile(cm.settingsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	var content map[string]interface{}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("failed to parse settings file: %w", err)
+	}
+
+	return content, nil
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package config
+
+import "syscall"
+
+// diskFree 返回目录所在文件系统中非特权用户可用的字节数
+func diskFree(dir string) (uint64, bool) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, false
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), true
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package config
+
+// diskFree Windows 下不检查可用空间
+func diskFree(dir string) (uint64, bool) {
+	return 0, false
+}
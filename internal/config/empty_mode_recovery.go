@@ -0,0 +1,118 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+)
+
+// emptyModeRecoveryGrace 半完成状态至少存在这么久才修复，避免打断另一个进程正在进行的切换
+const emptyModeRecoveryGrace = 10 * time.Second
+
+// recoverEmptyMode 检测并修复进入/退出空配置模式时中断留下的半完成状态：
+//   - 有 .empty_mode 标记且 settings.json 存在：内容与备份相同（或备份已丢失）时视为
+//     退出未完成，删除标记和备份；内容不同时说明空配置模式期间有人写入了 settings.json，不做处理
+//   - 有标记但 settings.json 和备份都不存在：备份无法恢复，删除标记并按之前的配置重新生成 settings.json
+//   - 没有标记但备份存在：settings.json 存在时备份是残留，直接删除；否则用备份恢复 settings.json
+//
+// 每次修复都会在 stderr 说明所做的操作并写入活动日志；只读模式下不做修复
+func (cm *ConfigManager) recoverEmptyMode() error {
+	if cm.ReadOnly() {
+		return nil
+	}
+	defaultBackup := cm.dataFile(emptyBackupFileName)
+
+	if !cm.IsEmptyMode() {
+		backupInfo, err := os.Stat(defaultBackup)
+		if err != nil || time.Since(backupInfo.ModTime()) < emptyModeRecoveryGrace {
+			return nil
+		}
+		if fileExists(cm.settingsFile) {
+			if err := cm.fs.Remove(defaultBackup); err != nil {
+				return fmt.Errorf("failed to remove leftover empty mode backup: %w", err)
+			}
+			cm.reportEmptyModeRecovery("", "removed a leftover empty mode backup")
+			return nil
+		}
+		if err := cm.copyFile(defaultBackup, cm.settingsFile); err != nil {
+			return fmt.Errorf("failed to restore settings.json from the empty mode backup: %w", err)
+		}
+		cm.fs.Remove(defaultBackup)
+		cm.reportEmptyModeRecovery("", "restored settings.json from an empty mode backup left without a state file")
+		return nil
+	}
+
+	info, err := cm.GetEmptyModeInfo()
+	if err != nil {
+		// 状态文件损坏时无法判断应恢复到哪里，交给 use --restore 等命令报错
+		return nil
+	}
+	if time.Since(info.Timestamp) < emptyModeRecoveryGrace {
+		return nil
+	}
+	backupPath := info.BackupPath
+	if backupPath == "" {
+		backupPath = defaultBackup
+	}
+	backupExists := fileExists(backupPath)
+
+	switch {
+	case fileExists(cm.settingsFile):
+		if backupExists {
+			same, err := filesEqual(cm.settingsFile, backupPath)
+			if err != nil {
+				return fmt.Errorf("failed to compare settings.json with the empty mode backup: %w", err)
+			}
+			if !same {
+				return nil
+			}
+		}
+		if err := cm.removeEmptyModeInfo(); err != nil {
+			return err
+		}
+		if backupExists {
+			cm.fs.Remove(backupPath)
+		}
+		cm.reportEmptyModeRecovery(info.PreviousProfile, "finished leaving empty mode (settings.json was already in place)")
+	case !backupExists:
+		if err := cm.removeEmptyModeInfo(); err != nil {
+			return err
+		}
+		if info.PreviousProfile == "" || !cm.ProfileExists(info.PreviousProfile) {
+			cm.reportEmptyModeRecovery(info.PreviousProfile, "left empty mode whose backup was missing; no configuration to restore")
+			return nil
+		}
+		profilePath, _ := cm.resolveProfilePath(info.PreviousProfile)
+		if err := cm.writeSettingsFromProfile(profilePath); err != nil {
+			return fmt.Errorf("failed to restore configuration '%s': %w", info.PreviousProfile, err)
+		}
+		cm.reportEmptyModeRecovery(info.PreviousProfile, fmt.Sprintf("left empty mode whose backup was missing; settings.json was rebuilt from '%s'", info.PreviousProfile))
+	}
+	return nil
+}
+
+// reportEmptyModeRecovery 在 stderr 和活动日志中记录一次空配置模式修复
+func (cm *ConfigManager) reportEmptyModeRecovery(profile, detail string) {
+	fmt.Fprintf(cm.warnings, "Note: an interrupted empty mode change was recovered: %s\n", detail)
+	cm.LogActivity(ActivityEntry{Action: ActivityEmptyFixed, Profile: profile, Detail: detail})
+}
+
+// fileExists 判断路径是否存在
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// filesEqual 比较两个文件的内容是否相同
+func filesEqual(a, b string) (bool, error) {
+	dataA, err := os.ReadFile(a)
+	if err != nil {
+		return false, err
+	}
+	dataB, err := os.ReadFile(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(dataA, dataB), nil
+}
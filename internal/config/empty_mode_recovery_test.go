@@ -0,0 +1,224 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+// enterStaleEmptyMode enters empty mode from work and backdates the state past the recovery grace period
+func enterStaleEmptyMode(t *testing.T) *ConfigManager {
+	t.Helper()
+	cm := setupSwitch(t)
+	if err := cm.EnableEmptyMode(); err != nil {
+		t.Fatal(err)
+	}
+	info, err := cm.GetEmptyModeInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info.Timestamp = time.Now().Add(-time.Minute)
+	if err := cm.saveEmptyModeInfo(info); err != nil {
+		t.Fatal(err)
+	}
+	backdateEmptyBackup(t, cm)
+	return cm
+}
+
+// backdateEmptyBackup moves the empty mode backup's modification time past the recovery grace period
+func backdateEmptyBackup(t *testing.T, cm *ConfigManager) {
+	t.Helper()
+	old := time.Now().Add(-time.Minute)
+	if err := os.Chtimes(cm.dataFile(emptyBackupFileName), old, old); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// settingsModel returns the model in settings.json, or "" when the file is missing
+func settingsModel(t *testing.T, cm *ConfigManager) string {
+	t.Helper()
+	data, err := os.ReadFile(cm.settingsFile)
+	if os.IsNotExist(err) {
+		return ""
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	var settings map[string]interface{}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatal(err)
+	}
+	model, _ := settings["model"].(string)
+	return model
+}
+
+func TestRecoverEmptyMode(t *testing.T) {
+	writeSettings := func(t *testing.T, cm *ConfigManager, content string) {
+		t.Helper()
+		if err := os.WriteFile(cm.settingsFile, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		prepare      func(t *testing.T, cm *ConfigManager)
+		wantEmpty    bool
+		wantModel    string
+		wantBackup   bool
+		wantRecovery bool
+	}{
+		{
+			name: "exit interrupted after restoring settings",
+			prepare: func(t *testing.T, cm *ConfigManager) {
+				if err := cm.copyFile(cm.dataFile(emptyBackupFileName), cm.settingsFile); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantModel:    "work-edited",
+			wantRecovery: true,
+		},
+		{
+			name: "exit interrupted after removing the backup",
+			prepare: func(t *testing.T, cm *ConfigManager) {
+				writeSettings(t, cm, `{"model": "work-edited"}`)
+				os.Remove(cm.dataFile(emptyBackupFileName))
+			},
+			wantModel:    "work-edited",
+			wantRecovery: true,
+		},
+		{
+			name: "settings written during empty mode",
+			prepare: func(t *testing.T, cm *ConfigManager) {
+				writeSettings(t, cm, `{"model": "manual"}`)
+			},
+			wantEmpty:  true,
+			wantModel:  "manual",
+			wantBackup: true,
+		},
+		{
+			name: "backup and settings both missing",
+			prepare: func(t *testing.T, cm *ConfigManager) {
+				os.Remove(cm.dataFile(emptyBackupFileName))
+			},
+			wantModel:    "work",
+			wantRecovery: true,
+		},
+		{
+			name: "enter interrupted before the state file",
+			prepare: func(t *testing.T, cm *ConfigManager) {
+				writeSettings(t, cm, `{"model": "work-edited"}`)
+				if err := cm.removeEmptyModeInfo(); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantModel:    "work-edited",
+			wantRecovery: true,
+		},
+		{
+			name: "exit interrupted after removing the state file",
+			prepare: func(t *testing.T, cm *ConfigManager) {
+				if err := cm.removeEmptyModeInfo(); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantModel:    "work-edited",
+			wantRecovery: true,
+		},
+		{
+			name:       "complete empty mode",
+			prepare:    func(t *testing.T, cm *ConfigManager) {},
+			wantEmpty:  true,
+			wantBackup: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := enterStaleEmptyMode(t)
+			tt.prepare(t, cm)
+
+			if err := cm.recoverEmptyMode(); err != nil {
+				t.Fatalf("recoverEmptyMode: %v", err)
+			}
+
+			if got := cm.IsEmptyMode(); got != tt.wantEmpty {
+				t.Errorf("IsEmptyMode = %v, want %v", got, tt.wantEmpty)
+			}
+			if got := settingsModel(t, cm); got != tt.wantModel {
+				t.Errorf("settings model = %q, want %q", got, tt.wantModel)
+			}
+			if got := fileExists(cm.dataFile(emptyBackupFileName)); got != tt.wantBackup {
+				t.Errorf("backup exists = %v, want %v", got, tt.wantBackup)
+			}
+
+			entries, err := cm.ReadActivity(0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			recovered := 0
+			for _, entry := range entries {
+				if entry.Action == ActivityEmptyFixed {
+					recovered++
+				}
+			}
+			want := 0
+			if tt.wantRecovery {
+				want = 1
+			}
+			if recovered != want {
+				t.Errorf("logged %d recoveries, want %d", recovered, want)
+			}
+		})
+	}
+}
+
+func TestRecoverEmptyModeSkipsRecentStates(t *testing.T) {
+	cm := setupSwitch(t)
+	if err := cm.EnableEmptyMode(); err != nil {
+		t.Fatal(err)
+	}
+	// A second process may be in the middle of leaving empty mode
+	if err := cm.copyFile(cm.dataFile(emptyBackupFileName), cm.settingsFile); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cm.recoverEmptyMode(); err != nil {
+		t.Fatal(err)
+	}
+	if !cm.IsEmptyMode() || !fileExists(cm.dataFile(emptyBackupFileName)) {
+		t.Error("a transition inside the grace period was recovered")
+	}
+}
+
+func TestRecoverEmptyModeSkipsDryRun(t *testing.T) {
+	cm := enterStaleEmptyMode(t)
+	if err := cm.copyFile(cm.dataFile(emptyBackupFileName), cm.settingsFile); err != nil {
+		t.Fatal(err)
+	}
+	dry, _ := newDryRunManager(t, cm)
+
+	before := snapshotTree(t, cm.claudeDir)
+	if err := dry.recoverEmptyMode(); err != nil {
+		t.Fatal(err)
+	}
+	compareTrees(t, before, snapshotTree(t, cm.claudeDir))
+}
+
+func TestInitializeRecoversEmptyMode(t *testing.T) {
+	cm := enterStaleEmptyMode(t)
+	if err := cm.removeEmptyModeInfo(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cm.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if got := settingsModel(t, cm); got != "work-edited" {
+		t.Errorf("settings model = %q, want the backup restored", got)
+	}
+	if fileExists(cm.dataFile(emptyBackupFileName)) {
+		t.Error("backup was not removed after restoring it")
+	}
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// endpointSetsFileName 全局端点集合文件名，位于数据目录下
+const endpointSetsFileName = ".endpoint-sets.json"
+
+// TestEndpoints 可用于连通性测试的端点名称
+var TestEndpoints = []string{"basic", "auth", "models", "chat"}
+
+// builtinEndpointSets 内置端点集合，可被全局或配置级同名集合覆盖
+var builtinEndpointSets = map[string][]string{
+	"minimal": {"basic"},
+	"full":    {"auth", "models", "chat"},
+	"no-chat": {"auth", "models"},
+}
+
+// EndpointSet 命名的端点集合及其来源
+type EndpointSet struct {
+	Name      string   `json:"name"`
+	Endpoints []string `json:"endpoints"`
+	Source    string   `json:"source"` // "builtin"、"global" 或 "profile"
+}
+
+// 端点集合来源
+const (
+	EndpointSetBuiltin = "builtin"
+	EndpointSetGlobal  = "global"
+	EndpointSetProfile = "profile"
+)
+
+// NormalizeTestEndpoint 规范化并验证端点名称
+func NormalizeTestEndpoint(endpoint string) (string, error) {
+	endpoint = strings.ToLower(strings.TrimSpace(endpoint))
+	for _, valid := range TestEndpoints {
+		if endpoint == valid {
+			return endpoint, nil
+		}
+	}
+	return "", Invalidf("invalid endpoint '%s', valid values: %s", endpoint, strings.Join(TestEndpoints, ", "))
+}
+
+// EndpointSetsPath 返回全局端点集合文件路径
+func (cm *ConfigManager) EndpointSetsPath() string {
+	return cm.dataFile(endpointSetsFileName)
+}
+
+// loadGlobalEndpointSets 读取全局端点集合（文件不存在时返回空）
+func (cm *ConfigManager) loadGlobalEndpointSets() (map[string][]string, error) {
+	data, err := os.ReadFile(cm.EndpointSetsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read endpoint sets: %w", err)
+	}
+
+	var sets map[string][]string
+	if err := json.Unmarshal(data, &sets); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", cm.EndpointSetsPath(), err)
+	}
+	return sets, nil
+}
+
+// ListEndpointSets 返回对指定配置生效的全部端点集合（按名称排序）
+// 优先级：配置元数据中的集合 > 全局集合 > 内置集合；profile 为空时只包含全局与内置集合
+func (cm *ConfigManager) ListEndpointSets(profile string) ([]EndpointSet, error) {
+	merged := make(map[string]EndpointSet)
+	for name, endpoints := range builtinEndpointSets {
+		merged[name] = EndpointSet{Name: name, Endpoints: endpoints, Source: EndpointSetBuiltin}
+	}
+
+	global, err := cm.loadGlobalEndpointSets()
+	if err != nil {
+		return nil, err
+	}
+	for name, endpoints := range global {
+		merged[name] = EndpointSet{Name: name, Endpoints: endpoints, Source: EndpointSetGlobal}
+	}
+
+	if profile != "" {
+		meta, err := cm.GetProfileMetadata(profile)
+		if err != nil {
+			return nil, err
+		}
+		for name, endpoints := range meta.EndpointSets {
+			merged[name] = EndpointSet{Name: name, Endpoints: endpoints, Source: EndpointSetProfile}
+		}
+	}
+
+	sets := make([]EndpointSet, 0, len(merged))
+	for _, set := range merged {
+		sets = append(sets, set)
+	}
+	sort.Slice(sets, func(i, j int) bool { return sets[i].Name < sets[j].Name })
+	return sets, nil
+}
+
+// ResolveEndpointSet 将端点集合名称展开为端点列表，未知集合或集合中含无效端点时返回错误
+func (cm *ConfigManager) ResolveEndpointSet(name, profile string) ([]string, error) {
+	sets, err := cm.ListEndpointSets(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	var names []string
+	for _, set := range sets {
+		names = append(names, set.Name)
+		if set.Name != name {
+			continue
+		}
+		if len(set.Endpoints) == 0 {
+			return nil, fmt.Errorf("endpoint set '%s' (%s) is empty", name, set.Source)
+		}
+		endpoints := make([]string, 0, len(set.Endpoints))
+		for _, endpoint := range set.Endpoints {
+			normalized, err := NormalizeTestEndpoint(endpoint)
+			if err != nil {
+				return nil, fmt.Errorf("endpoint set '%s' (%s): %w", name, set.Source, err)
+			}
+			endpoints = append(endpoints, normalized)
+		}
+		return endpoints, nil
+	}
+
+	return nil, fmt.Errorf("unknown endpoint set '%s' (available: %s)", name, strings.Join(names, ", "))
+}
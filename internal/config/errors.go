@@ -0,0 +1,55 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 错误类别，命令行据此选择退出码；用 errors.Is(err, ErrNotFound) 等判断
+var (
+	ErrNotFound = errors.New("not found")           // 配置、模板或密钥不存在
+	ErrConflict = errors.New("conflict")            // 目标已存在或仍被引用
+	ErrInvalid  = errors.New("validation failed")   // 名称、内容或参数无效
+	ErrLocked   = errors.New("locked or read-only") // 密钥库未解锁或配置只读
+)
+
+// ErrNoPrevious 没有可供 use -p 切换的上一个配置，属于 ErrNotFound 类别
+var ErrNoPrevious = NotFoundf("no previous configuration available")
+
+// kindError 带类别的错误，Error() 保持原始消息不变
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() error {
+	return e.err
+}
+
+func (e *kindError) Is(target error) bool {
+	return target == e.kind
+}
+
+// NotFoundf 创建 ErrNotFound 类别的错误，格式同 fmt.Errorf（支持 %w）
+func NotFoundf(format string, args ...interface{}) error {
+	return &kindError{kind: ErrNotFound, err: fmt.Errorf(format, args...)}
+}
+
+// Conflictf 创建 ErrConflict 类别的错误
+func Conflictf(format string, args ...interface{}) error {
+	return &kindError{kind: ErrConflict, err: fmt.Errorf(format, args...)}
+}
+
+// Invalidf 创建 ErrInvalid 类别的错误
+func Invalidf(format string, args ...interface{}) error {
+	return &kindError{kind: ErrInvalid, err: fmt.Errorf(format, args...)}
+}
+
+// Lockedf 创建 ErrLocked 类别的错误
+func Lockedf(format string, args ...interface{}) error {
+	return &kindError{kind: ErrLocked, err: fmt.Errorf(format, args...)}
+}
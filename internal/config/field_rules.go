@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// FieldRule 根据字段名识别字段用途的规则，Name、Suffix、Contains 三者只能设置一个
+// 匹配按单词进行：键名按 _、-、. 和驼峰拆分，因此 MONKEY 不会匹配 KEY
+type FieldRule struct {
+	Name     string `json:"name,omitempty"`     // 完整键名（不区分大小写）
+	Suffix   string `json:"suffix,omitempty"`   // 键名以这些单词结尾，如 TOKEN、API_KEY
+	Contains string `json:"contains,omitempty"` // 键名包含这些连续单词，如 URL
+	// Description 交互输入时的提示，为空时使用通用提示
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"` // 模板中为空时必须填写
+	Secret      bool   `json:"secret,omitempty"`   // 显示时遮蔽，输入时按凭据校验
+	URL         bool   `json:"url,omitempty"`      // 输入时按 URL 校验
+}
+
+// DefaultFieldRules 内置的字段规则，按顺序匹配，第一条匹配的规则生效
+var DefaultFieldRules = []FieldRule{
+	{Name: "ANTHROPIC_AUTH_TOKEN", Description: "Enter your Claude API token", Required: true, Secret: true},
+	{Name: "ANTHROPIC_BASE_URL", Description: "Enter custom base URL (optional)", URL: true},
+	{Name: "OPENAI_API_KEY", Description: "Enter your OpenAI API key", Required: true, Secret: true},
+	{Suffix: "API_KEY", Description: "Enter your API key", Required: true, Secret: true},
+	{Suffix: "TOKEN", Description: "Enter authentication token", Required: true, Secret: true},
+	{Suffix: "SECRET", Description: "Enter secret key", Required: true, Secret: true},
+	{Suffix: "KEY", Required: true, Secret: true},
+	{Contains: "PASSWORD", Secret: true},
+	{Suffix: "BASE_URL", Description: "Enter base URL (optional)", URL: true},
+	{Contains: "ENDPOINT", Description: "Enter API endpoint URL", URL: true},
+	{Contains: "URL", URL: true},
+}
+
+var (
+	fieldRulesMu sync.RWMutex
+	userRules    []FieldRule
+)
+
+// setFieldRules 设置全局配置 field_rules 中的规则，它们先于内置规则匹配
+func setFieldRules(rules []FieldRule) {
+	fieldRulesMu.Lock()
+	defer fieldRulesMu.Unlock()
+	userRules = rules
+}
+
+// validateFieldRule 检查规则恰好设置了一种匹配方式
+func validateFieldRule(rule FieldRule) error {
+	set := 0
+	for _, matcher := range []string{rule.Name, rule.Suffix, rule.Contains} {
+		if strings.TrimSpace(matcher) != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("each field rule needs exactly one of name, suffix or contains")
+	}
+	return nil
+}
+
+// MatchFieldRule 返回第一条匹配键名的规则：先是全局配置中的规则，然后是内置规则
+// auth.token_keys 中的键总是视为必填凭据
+func MatchFieldRule(key string) (FieldRule, bool) {
+	fieldRulesMu.RLock()
+	rules := append(slices.Clone(userRules), DefaultFieldRules...)
+	fieldRulesMu.RUnlock()
+
+	words := fieldWords(key)
+	for _, rule := range rules {
+		if rule.matches(key, words) {
+			if IsTokenKey(key) {
+				rule.Required, rule.Secret = true, true
+			}
+			return rule, true
+		}
+	}
+	if IsTokenKey(key) {
+		return FieldRule{Name: key, Description: "Enter your API token", Required: true, Secret: true}, true
+	}
+	return FieldRule{}, false
+}
+
+// matches 判断规则是否匹配键名，words 为键名拆分后的大写单词
+func (rule FieldRule) matches(key string, words []string) bool {
+	switch {
+	case rule.Name != "":
+		return strings.EqualFold(rule.Name, key)
+	case rule.Suffix != "":
+		suffix := fieldWords(rule.Suffix)
+		return len(suffix) > 0 && len(suffix) <= len(words) && slices.Equal(words[len(words)-len(suffix):], suffix)
+	case rule.Contains != "":
+		part := fieldWords(rule.Contains)
+		for i := 0; len(part) > 0 && i+len(part) <= len(words); i++ {
+			if slices.Equal(words[i:i+len(part)], part) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldWords 将键名拆分为大写单词：按非字母数字字符和驼峰边界拆分
+// 例如 MY_GATEWAY_API_KEY -> [MY GATEWAY API KEY]，apiKeyHelper -> [API KEY HELPER]
+func fieldWords(key string) []string {
+	var words []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToUpper(string(current)))
+			current = current[:0]
+		}
+	}
+	runes := []rune(key)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]) {
+			flush()
+		}
+		current = append(current, r)
+	}
+	flush()
+	return words
+}
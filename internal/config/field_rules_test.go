@@ -0,0 +1,164 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestFieldWords(t *testing.T) {
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"MY_GATEWAY_API_KEY", []string{"MY", "GATEWAY", "API", "KEY"}},
+		{"apiKeyHelper", []string{"API", "KEY", "HELPER"}},
+		{"base-url.v2", []string{"BASE", "URL", "V2"}},
+		{"MONKEY", []string{"MONKEY"}},
+		{"__", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := fieldWords(tt.key); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fieldWords(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchFieldRule(t *testing.T) {
+	tests := []struct {
+		key         string
+		required    bool
+		secret      bool
+		url         bool
+		description string
+	}{
+		{"ANTHROPIC_AUTH_TOKEN", true, true, false, "Enter your Claude API token"},
+		{"OPENAI_API_KEY", true, true, false, "Enter your OpenAI API key"},
+		{"MY_GATEWAY_API_KEY", true, true, false, "Enter your API key"},
+		{"gatewayApiKey", true, true, false, "Enter your API key"},
+		{"GITHUB_TOKEN", true, true, false, "Enter authentication token"},
+		{"CLIENT_SECRET", true, true, false, "Enter secret key"},
+		{"SIGNING_KEY", true, true, false, "Enter value for SIGNING_KEY"},
+		{"DB_PASSWORD", false, true, false, "Enter value for DB_PASSWORD"},
+		{"ANTHROPIC_BASE_URL", false, false, true, "Enter custom base URL (optional)"},
+		{"PROXY_BASE_URL", false, false, true, "Enter base URL (optional)"},
+		{"SERVICE_ENDPOINT_HOST", false, false, true, "Enter API endpoint URL"},
+		{"URL_PREFIX", false, false, true, "Enter value for URL_PREFIX"},
+		// Matching is by whole words, so these must not be treated as secrets or URLs
+		{"MONKEY", false, false, false, "Enter value for MONKEY"},
+		{"KEYBOARD_LAYOUT", false, false, false, "Enter value for KEYBOARD_LAYOUT"},
+		{"CLAUDE_CODE_MAX_OUTPUT_TOKENS", false, false, false, "Enter value for CLAUDE_CODE_MAX_OUTPUT_TOKENS"},
+		{"apiKeyHelper", false, false, false, "Enter value for apiKeyHelper"},
+		{"CURLY_BRACES", false, false, false, "Enter value for CURLY_BRACES"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			rule, _ := MatchFieldRule(tt.key)
+			if rule.Required != tt.required || rule.Secret != tt.secret || rule.URL != tt.url {
+				t.Errorf("MatchFieldRule(%q) = required %v, secret %v, url %v; want %v, %v, %v",
+					tt.key, rule.Required, rule.Secret, rule.URL, tt.required, tt.secret, tt.url)
+			}
+			if got := IsSecretKey(tt.key); got != tt.secret {
+				t.Errorf("IsSecretKey(%q) = %v, want %v", tt.key, got, tt.secret)
+			}
+			if got := isFieldRequired(tt.key); got != tt.required {
+				t.Errorf("isFieldRequired(%q) = %v, want %v", tt.key, got, tt.required)
+			}
+			if got := getFieldDescription(tt.key); got != tt.description {
+				t.Errorf("getFieldDescription(%q) = %q, want %q", tt.key, got, tt.description)
+			}
+		})
+	}
+}
+
+func TestMatchFieldRuleTokenKeys(t *testing.T) {
+	setTokenKeys([]string{"GATEWAY_CREDENTIAL", "OPENAI_BASE_URL"})
+	t.Cleanup(func() { setTokenKeys(nil) })
+
+	// A configured token key without a matching rule is still a required secret
+	rule, ok := MatchFieldRule("GATEWAY_CREDENTIAL")
+	if !ok || !rule.Required || !rule.Secret || rule.Description != "Enter your API token" {
+		t.Errorf("GATEWAY_CREDENTIAL = %+v, %v, want a required secret", rule, ok)
+	}
+	// A token key matched by another rule keeps that rule but becomes a required secret
+	rule, _ = MatchFieldRule("OPENAI_BASE_URL")
+	if !rule.Required || !rule.Secret {
+		t.Errorf("OPENAI_BASE_URL = %+v, want a required secret", rule)
+	}
+}
+
+func TestFieldRulesFromGlobalConfig(t *testing.T) {
+	cm := newTestManager(t)
+	globalConfig := `{"field_rules": [
+		{"name": "MONKEY", "secret": true},
+		{"suffix": "API_KEY", "description": "Enter your gateway key", "required": true, "secret": true},
+		{"contains": "HOST", "description": "Enter a host URL", "url": true}
+	]}`
+	if err := os.WriteFile(cm.GlobalConfigPath(), []byte(globalConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// The manager reloads the global config when the test ends; remove it first so the rules go away
+	t.Cleanup(func() { os.Remove(cm.GlobalConfigPath()) })
+	cm.applyGlobalConfig()
+
+	if !IsSecretKey("MONKEY") {
+		t.Error("MONKEY should be a secret once a field rule names it")
+	}
+	if got := getFieldDescription("MY_GATEWAY_API_KEY"); got != "Enter your gateway key" {
+		t.Errorf("description = %q, want the configured rule before the built-in one", got)
+	}
+	if rule, _ := MatchFieldRule("PROXY_HOST"); !rule.URL {
+		t.Error("PROXY_HOST should be a URL field")
+	}
+	// Built-in rules still apply to names the configured rules don't match
+	if !isFieldRequired("GITHUB_TOKEN") {
+		t.Error("GITHUB_TOKEN should still be required")
+	}
+}
+
+func TestLoadGlobalConfigRejectsInvalidFieldRules(t *testing.T) {
+	tests := map[string]string{
+		"no matcher":  `{"field_rules": [{"secret": true}]}`,
+		"two matcher": `{"field_rules": [{"name": "A", "suffix": "KEY"}]}`,
+		"blank":       `{"field_rules": [{"contains": "  "}]}`,
+	}
+
+	for name, globalConfig := range tests {
+		t.Run(name, func(t *testing.T) {
+			cm := newTestManager(t)
+			if err := os.WriteFile(cm.GlobalConfigPath(), []byte(globalConfig), 0600); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { os.Remove(cm.GlobalConfigPath()) })
+
+			if _, err := cm.LoadGlobalConfig(); !errors.Is(err, ErrInvalid) {
+				t.Errorf("LoadGlobalConfig error = %v, want an invalid config error", err)
+			}
+		})
+	}
+}
+
+func TestDetectEmptyFieldsUsesFieldRules(t *testing.T) {
+	cm := newTestManager(t)
+	content := map[string]interface{}{
+		"env": map[string]interface{}{
+			"MY_GATEWAY_API_KEY": "",
+			"MONKEY":             "",
+			"PROXY_BASE_URL":     "",
+		},
+	}
+
+	want := []TemplateField{
+		{Path: "env.MONKEY", Name: "MONKEY", Description: "Enter value for MONKEY"},
+		{Path: "env.MY_GATEWAY_API_KEY", Name: "MY_GATEWAY_API_KEY", Description: "Enter your API key", Required: true},
+		{Path: "env.PROXY_BASE_URL", Name: "PROXY_BASE_URL", Description: "Enter base URL (optional)"},
+	}
+	if got := cm.DetectEmptyFields(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("DetectEmptyFields = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// dryRun 为 true 时新建的配置管理器进入只读模式（全局 --dry-run）
+var dryRun bool
+
+// SetDryRun 设置之后创建的配置管理器是否为只读模式
+func SetDryRun(enabled bool) {
+	dryRun = enabled
+}
+
+// DryRun 返回是否处于只读模式
+func DryRun() bool {
+	return dryRun
+}
+
+// ReadOnly 返回该配置管理器是否处于只读模式
+func (cm *ConfigManager) ReadOnly() bool {
+	_, ok := cm.fs.(*dryRunFileSystem)
+	return ok
+}
+
+// fileSystem 配置管理器对磁盘的全部写操作。所有修改 ~/.claude 的方法都经由它写入，
+// 只读模式下换成 dryRunFileSystem，各命令无需自行实现 dry-run
+type fileSystem interface {
+	// WriteFile 原子写入文件（临时文件 + 重命名），sync 为 true 时刷盘
+	WriteFile(path string, data []byte, perm os.FileMode, sync bool) error
+	// AppendFile 追加写入，文件不存在时创建
+	AppendFile(path string, data []byte, perm os.FileMode) error
+	Rename(oldPath, newPath string) error
+	Remove(path string) error
+	MkdirAll(path string, perm os.FileMode) error
+	Chmod(path string, perm os.FileMode) error
+}
+
+// osFileSystem 直接写入磁盘
+type osFileSystem struct{}
+
+func (osFileSystem) WriteFile(path string, data []byte, perm os.FileMode, sync bool) error {
+	return writeFileAtomicSync(path, data, perm, sync)
+}
+
+func (osFileSystem) AppendFile(path string, data []byte, perm os.FileMode) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (osFileSystem) Rename(oldPath, newPath string) error { return os.Rename(oldPath, newPath) }
+
+func (osFileSystem) Remove(path string) error { return os.Remove(path) }
+
+func (osFileSystem) MkdirAll(path string, perm os.FileMode) error { return os.MkdirAll(path, perm) }
+
+func (osFileSystem) Chmod(path string, perm os.FileMode) error { return os.Chmod(path, perm) }
+
+// dryRunFileSystem 不改动磁盘，只把将要执行的操作写到 out 并返回成功
+// 它记住本次运行中“已写入”和“已删除”的路径，先创建再修改权限或重命名的操作序列能照常进行；
+// 对不存在的文件删除、重命名或修改权限时返回与真实文件系统相同的错误，调用方的分支判断保持一致
+type dryRunFileSystem struct {
+	out     io.Writer
+	pending map[string]bool // 路径 -> 本次运行中视为存在（true）或已删除（false）
+}
+
+func newDryRunFileSystem(out io.Writer) *dryRunFileSystem {
+	return &dryRunFileSystem{out: out, pending: make(map[string]bool)}
+}
+
+func (fs *dryRunFileSystem) report(format string, args ...interface{}) {
+	fmt.Fprintf(fs.out, "[dry-run] would "+format+"\n", args...)
+}
+
+// exists 判断路径在本次运行的视角下是否存在，不存在时返回对应的错误
+func (fs *dryRunFileSystem) exists(op, path string) error {
+	if present, ok := fs.pending[path]; ok {
+		if present {
+			return nil
+		}
+		return &os.PathError{Op: op, Path: path, Err: os.ErrNotExist}
+	}
+	if _, err := os.Lstat(path); err != nil {
+		return &os.PathError{Op: op, Path: path, Err: err.(*os.PathError).Err}
+	}
+	return nil
+}
+
+func (fs *dryRunFileSystem) WriteFile(path string, data []byte, perm os.FileMode, sync bool) error {
+	if fs.exists("write", path) == nil {
+		fs.report("overwrite %s (%d bytes)", path, len(data))
+	} else {
+		fs.report("create %s (%d bytes)", path, len(data))
+	}
+	fs.pending[path] = true
+	return nil
+}
+
+func (fs *dryRunFileSystem) AppendFile(path string, data []byte, perm os.FileMode) error {
+	fs.report("append %d bytes to %s", len(data), path)
+	fs.pending[path] = true
+	return nil
+}
+
+func (fs *dryRunFileSystem) Rename(oldPath, newPath string) error {
+	if err := fs.exists("rename", oldPath); err != nil {
+		return &os.LinkError{Op: "rename", Old: oldPath, New: newPath, Err: err.(*os.PathError).Err}
+	}
+	fs.report("rename %s to %s", oldPath, newPath)
+	fs.pending[oldPath] = false
+	fs.pending[newPath] = true
+	return nil
+}
+
+func (fs *dryRunFileSystem) Remove(path string) error {
+	if err := fs.exists("remove", path); err != nil {
+		return err
+	}
+	fs.report("remove %s", path)
+	fs.pending[path] = false
+	return nil
+}
+
+func (fs *dryRunFileSystem) MkdirAll(path string, perm os.FileMode) error {
+	if fs.exists("mkdir", path) == nil {
+		return nil
+	}
+	fs.report("create directory %s", path)
+	fs.pending[path] = true
+	return nil
+}
+
+func (fs *dryRunFileSystem) Chmod(path string, perm os.FileMode) error {
+	if err := fs.exists("chmod", path); err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm() == perm.Perm() {
+		return nil
+	}
+	fs.report("change the permissions of %s to %04o", path, perm.Perm())
+	return nil
+}
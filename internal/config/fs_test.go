@@ -0,0 +1,189 @@
+package config
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// snapshotTree records every file under root with its mode and content
+func snapshotTree(t *testing.T, root string) map[string]string {
+	t.Helper()
+	tree := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		value := info.Mode().String()
+		if info.Mode().IsRegular() {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			value += " " + string(data)
+		}
+		tree[path] = value
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+// compareTrees reports every path added, removed or changed between two snapshots
+func compareTrees(t *testing.T, before, after map[string]string) {
+	t.Helper()
+	for path, value := range before {
+		if got, ok := after[path]; !ok {
+			t.Errorf("%s was removed", path)
+		} else if got != value {
+			t.Errorf("%s changed", path)
+		}
+	}
+	for path := range after {
+		if _, ok := before[path]; !ok {
+			t.Errorf("%s was created", path)
+		}
+	}
+}
+
+// newDryRunManager opens a read-only manager on the same home as cm
+func newDryRunManager(t *testing.T, cm *ConfigManager) (*ConfigManager, *bytes.Buffer) {
+	t.Helper()
+	var report bytes.Buffer
+	dry, err := NewConfigManagerWithOptions(Options{DryRun: true, Warnings: &report})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dry.ReadOnly() || cm.ReadOnly() {
+		t.Fatal("only the dry-run manager should be read-only")
+	}
+	return dry, &report
+}
+
+func TestDryRunLeavesDiskUntouched(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func(cm *ConfigManager) error
+	}{
+		{"use", func(cm *ConfigManager) error { return cm.UseProfile("home") }},
+		{"update", func(cm *ConfigManager) error {
+			return cm.UpdateProfile("home", map[string]interface{}{"model": "changed"})
+		}},
+		{"create", func(cm *ConfigManager) error { return cm.CreateProfile("new") }},
+		{"copy", func(cm *ConfigManager) error { return cm.CopyProfile("home", "copy") }},
+		{"rename", func(cm *ConfigManager) error { return cm.RenameProfile("home", "house") }},
+		{"delete", func(cm *ConfigManager) error { return cm.DeleteProfile("home") }},
+		{"empty mode", func(cm *ConfigManager) error { return cm.EnableEmptyMode() }},
+		{"create template", func(cm *ConfigManager) error { return cm.CreateTemplate("team") }},
+		{"update template", func(cm *ConfigManager) error {
+			return cm.UpdateTemplate("default", map[string]interface{}{"model": "changed"})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := setupSwitch(t)
+			home := filepath.Dir(cm.claudeDir)
+			before := snapshotTree(t, home)
+
+			dry, report := newDryRunManager(t, cm)
+			if err := tt.op(dry); err != nil {
+				t.Fatalf("dry run: %v", err)
+			}
+			compareTrees(t, before, snapshotTree(t, home))
+			if !strings.Contains(report.String(), "[dry-run] would ") {
+				t.Errorf("report = %q, want the intended changes listed", report.String())
+			}
+		})
+	}
+}
+
+func TestDryRunStillReportsErrors(t *testing.T) {
+	cm := setupSwitch(t)
+	dry, _ := newDryRunManager(t, cm)
+
+	if err := dry.UseProfile("missing"); err == nil {
+		t.Error("UseProfile(missing) succeeded in a dry run")
+	}
+	if err := dry.RenameProfile("home", "work"); err == nil {
+		t.Error("renaming onto an existing profile succeeded in a dry run")
+	}
+}
+
+func TestDryRunFileSystemTracksPendingChanges(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.json")
+	if err := os.WriteFile(existing, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var report bytes.Buffer
+	dry := newDryRunFileSystem(&report)
+
+	// Later steps of a command see the effect of earlier ones
+	created := filepath.Join(dir, "created.json")
+	if err := dry.WriteFile(created, []byte("{}"), 0600, false); err != nil {
+		t.Fatal(err)
+	}
+	moved := filepath.Join(dir, "moved.json")
+	if err := dry.Rename(created, moved); err != nil {
+		t.Errorf("renaming a file created earlier in the run: %v", err)
+	}
+	if err := dry.Remove(created); !os.IsNotExist(err) {
+		t.Errorf("removing the renamed file = %v, want not exist", err)
+	}
+	if err := dry.Remove(existing); err != nil {
+		t.Fatal(err)
+	}
+	if err := dry.Rename(existing, moved); !os.IsNotExist(err) {
+		t.Errorf("renaming a removed file = %v, want not exist", err)
+	}
+	if err := dry.Remove(filepath.Join(dir, "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("removing a missing file = %v, want not exist", err)
+	}
+
+	if _, err := os.Stat(existing); err != nil {
+		t.Errorf("existing file was touched: %v", err)
+	}
+	for _, path := range []string{created, moved} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s exists on disk", path)
+		}
+	}
+	for _, want := range []string{"would create " + created, "would rename " + created + " to " + moved, "would remove " + existing} {
+		if !strings.Contains(report.String(), want) {
+			t.Errorf("report is missing %q:\n%s", want, report.String())
+		}
+	}
+}
+
+func TestPruneBackupsDryRun(t *testing.T) {
+	cm := newTestManager(t)
+	dir := t.TempDir()
+	now := time.Now()
+	writeBackups(t, dir, now, map[string]int{"cc-switch-backup-a.ccx": 1, "cc-switch-backup-b.ccx": 2, "cc-switch-backup-c.ccx": 3})
+
+	dry, report := newDryRunManager(t, cm)
+	removed, err := dry.PruneBackups(dir, RetentionPolicy{Keep: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 2 {
+		t.Errorf("removed = %v, want the two older backups reported", removed)
+	}
+	if got := remainingFiles(t, dir); len(got) != 3 {
+		t.Errorf("remaining = %v, want every backup kept", got)
+	}
+	if strings.Count(report.String(), "would remove") != 2 {
+		t.Errorf("report = %q, want two removals", report.String())
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package config
+
+import "os"
+
+// syncDir 将目录项刷到磁盘，使重命名在断电后仍然可见
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package config
+
+// syncDir Windows 不支持对目录 fsync，重命名由 NTFS 日志保证
+func syncDir(dir string) error {
+	return nil
+}
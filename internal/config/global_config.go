@@ -0,0 +1,306 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// globalConfigFileName 全局配置文件名，位于数据目录下
+const globalConfigFileName = ".config.json"
+
+// DefaultTokenKeys 默认保存 API 凭据的 env 键名，按优先级排列
+var DefaultTokenKeys = []string{"ANTHROPIC_AUTH_TOKEN", "ANTHROPIC_API_KEY"}
+
+// GlobalConfig cc-switch 全局配置
+type GlobalConfig struct {
+	Auth   AuthConfig   `json:"auth"`
+	Backup BackupConfig `json:"backup"`
+	// DefaultTemplate new 未指定 --template 时使用的模板；为空时使用 default
+	DefaultTemplate string `json:"default_template,omitempty"`
+	// DurableWrites 写入配置、模板等文件时也 fsync；settings.json 总是 fsync
+	DurableWrites bool `json:"durable_writes,omitempty"`
+	// VerifySwitch 每次切换后校验 settings.json，失败时回滚（等同于 use --verify）
+	VerifySwitch bool `json:"verify_switch,omitempty"`
+	// CoalesceWindow 快速连续切换的合并窗口，如 "30s"；"0" 表示不合并，为空时使用 DefaultCoalesceWindow
+	CoalesceWindow string `json:"coalesce_window,omitempty"`
+	// FieldRules 识别必填、凭据和 URL 字段的附加规则，先于 DefaultFieldRules 匹配
+	FieldRules []FieldRule `json:"field_rules,omitempty"`
+
+	// unknown 当前版本不认识的顶层键（如更新版本写入的设置），读取时忽略，保存时原样写回
+	unknown map[string]json.RawMessage
+}
+
+// UnknownKeys 返回当前版本不认识、被忽略的顶层设置名
+func (cfg *GlobalConfig) UnknownKeys() []string {
+	keys := make([]string, 0, len(cfg.unknown))
+	for key := range cfg.unknown {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// globalConfigKeys 返回 GlobalConfig 能识别的顶层 JSON 键
+func globalConfigKeys() map[string]bool {
+	keys := make(map[string]bool)
+	t := reflect.TypeOf(GlobalConfig{})
+	for i := 0; i < t.NumField(); i++ {
+		if name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}
+
+// BackupConfig backup 命令相关配置
+type BackupConfig struct {
+	// Dir 备份文件目录，支持 ~ 开头；为空时使用 ~/cc-switch-backups
+	Dir string `json:"dir,omitempty"`
+}
+
+// AuthConfig 凭据相关配置
+type AuthConfig struct {
+	// TokenKeys 保存 API 凭据的 env 键名，按优先级排列；为空时使用 DefaultTokenKeys
+	TokenKeys []string `json:"token_keys,omitempty"`
+}
+
+var (
+	tokenKeysMu sync.RWMutex
+	tokenKeys   = DefaultTokenKeys
+
+	durableWritesMu sync.RWMutex
+	durableWrites   bool
+
+	verifySwitchMu sync.RWMutex
+	verifySwitch   bool
+
+	coalesceWindowMu sync.RWMutex
+	coalesceWindow   = DefaultCoalesceWindow
+)
+
+// ParseCoalesceWindow 解析切换记录合并窗口，空字符串表示默认值
+func ParseCoalesceWindow(value string) (time.Duration, error) {
+	if value == "" {
+		return DefaultCoalesceWindow, nil
+	}
+	window, err := time.ParseDuration(value)
+	if err != nil || window < 0 {
+		return 0, fmt.Errorf("coalesce_window must be a non-negative duration such as 5s, got '%s'", value)
+	}
+	return window, nil
+}
+
+// CoalesceWindow 返回快速连续切换的合并窗口，0 表示不合并
+func CoalesceWindow() time.Duration {
+	coalesceWindowMu.RLock()
+	defer coalesceWindowMu.RUnlock()
+	return coalesceWindow
+}
+
+// setCoalesceWindow 设置合并窗口，无法解析时恢复默认值
+func setCoalesceWindow(value string) {
+	window, err := ParseCoalesceWindow(value)
+	if err != nil {
+		window = DefaultCoalesceWindow
+	}
+	coalesceWindowMu.Lock()
+	defer coalesceWindowMu.Unlock()
+	coalesceWindow = window
+}
+
+// VerifySwitch 返回是否每次切换后都校验 settings.json
+func VerifySwitch() bool {
+	verifySwitchMu.RLock()
+	defer verifySwitchMu.RUnlock()
+	return verifySwitch
+}
+
+// setVerifySwitch 设置是否每次切换后都校验 settings.json
+func setVerifySwitch(enabled bool) {
+	verifySwitchMu.Lock()
+	defer verifySwitchMu.Unlock()
+	verifySwitch = enabled
+}
+
+// DurableWrites 返回 settings.json 以外的文件写入时是否 fsync
+func DurableWrites() bool {
+	durableWritesMu.RLock()
+	defer durableWritesMu.RUnlock()
+	return durableWrites
+}
+
+// setDurableWrites 设置 settings.json 以外的文件写入时是否 fsync
+func setDurableWrites(enabled bool) {
+	durableWritesMu.Lock()
+	defer durableWritesMu.Unlock()
+	durableWrites = enabled
+}
+
+// TokenKeys 返回当前生效的凭据键名列表
+func TokenKeys() []string {
+	tokenKeysMu.RLock()
+	defer tokenKeysMu.RUnlock()
+	keys := make([]string, len(tokenKeys))
+	copy(keys, tokenKeys)
+	return keys
+}
+
+// IsTokenKey 判断 env 键是否为配置的凭据键
+func IsTokenKey(key string) bool {
+	tokenKeysMu.RLock()
+	defer tokenKeysMu.RUnlock()
+	for _, tokenKey := range tokenKeys {
+		if key == tokenKey {
+			return true
+		}
+	}
+	return false
+}
+
+// setTokenKeys 设置生效的凭据键名，空列表恢复默认值
+func setTokenKeys(keys []string) {
+	tokenKeysMu.Lock()
+	defer tokenKeysMu.Unlock()
+	if len(keys) == 0 {
+		tokenKeys = DefaultTokenKeys
+		return
+	}
+	tokenKeys = keys
+}
+
+// GlobalConfigPath 返回全局配置文件路径
+func (cm *ConfigManager) GlobalConfigPath() string {
+	return cm.dataFile(globalConfigFileName)
+}
+
+// LoadGlobalConfig 读取全局配置（文件不存在时返回空配置）
+func (cm *ConfigManager) LoadGlobalConfig() (*GlobalConfig, error) {
+	cfg := &GlobalConfig{}
+	data, err := os.ReadFile(cm.GlobalConfigPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read global config: %w", err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, Invalidf("invalid global config %s: %w", cm.GlobalConfigPath(), err)
+	}
+
+	// 记下不认识的键，保存时写回，避免旧版本抹掉新版本的设置
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err == nil {
+		known := globalConfigKeys()
+		for key, value := range raw {
+			if !known[key] {
+				if cfg.unknown == nil {
+					cfg.unknown = make(map[string]json.RawMessage)
+				}
+				cfg.unknown[key] = value
+			}
+		}
+	}
+
+	// 去除空白与重复的键名
+	var keys []string
+	seen := make(map[string]bool)
+	for _, key := range cfg.Auth.TokenKeys {
+		key = strings.TrimSpace(key)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	cfg.Auth.TokenKeys = keys
+	cfg.DefaultTemplate = strings.TrimSpace(cfg.DefaultTemplate)
+
+	cfg.CoalesceWindow = strings.TrimSpace(cfg.CoalesceWindow)
+	if _, err := ParseCoalesceWindow(cfg.CoalesceWindow); err != nil {
+		return nil, Invalidf("invalid global config %s: %v", cm.GlobalConfigPath(), err)
+	}
+
+	for i, rule := range cfg.FieldRules {
+		if err := validateFieldRule(rule); err != nil {
+			return nil, Invalidf("invalid global config %s: field_rules[%d]: %v", cm.GlobalConfigPath(), i, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// SaveGlobalConfig 原子性保存全局配置并使其立即生效
+func (cm *ConfigManager) SaveGlobalConfig(cfg *GlobalConfig) error {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal global config: %w", err)
+	}
+	if len(cfg.unknown) > 0 {
+		merged := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(data, &merged); err != nil {
+			return fmt.Errorf("failed to marshal global config: %w", err)
+		}
+		for key, value := range cfg.unknown {
+			merged[key] = value
+		}
+		if data, err = json.Marshal(merged); err != nil {
+			return fmt.Errorf("failed to marshal global config: %w", err)
+		}
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, data, "", "  "); err != nil {
+		return fmt.Errorf("failed to marshal global config: %w", err)
+	}
+	data = indented.Bytes()
+
+	if err := cm.fs.WriteFile(cm.GlobalConfigPath(), data, 0600, false); err != nil {
+		return fmt.Errorf("failed to save global config: %w", err)
+	}
+
+	setTokenKeys(cfg.Auth.TokenKeys)
+	setDurableWrites(cfg.DurableWrites)
+	setVerifySwitch(cfg.VerifySwitch)
+	setCoalesceWindow(cfg.CoalesceWindow)
+	setFieldRules(cfg.FieldRules)
+	return nil
+}
+
+// applyGlobalConfig 加载全局配置并使其生效，读取失败时保留默认值并给出警告
+func (cm *ConfigManager) applyGlobalConfig() {
+	cfg, err := cm.LoadGlobalConfig()
+	if err != nil {
+		fmt.Fprintf(cm.warnings, "Warning: %v; using default token keys and field rules\n", err)
+		setTokenKeys(nil)
+		setDurableWrites(false)
+		setVerifySwitch(false)
+		setCoalesceWindow("")
+		setFieldRules(nil)
+		return
+	}
+	setTokenKeys(cfg.Auth.TokenKeys)
+	setDurableWrites(cfg.DurableWrites)
+	setVerifySwitch(cfg.VerifySwitch)
+	setCoalesceWindow(cfg.CoalesceWindow)
+	setFieldRules(cfg.FieldRules)
+}
+
+// MissingTokenMessage 描述未设置任何凭据键的情况，如 "neither A nor B is set"
+func MissingTokenMessage() string {
+	keys := TokenKeys()
+	switch len(keys) {
+	case 1:
+		return keys[0] + " is not set"
+	case 2:
+		return fmt.Sprintf("neither %s nor %s is set", keys[0], keys[1])
+	default:
+		return fmt.Sprintf("none of %s is set", strings.Join(keys, ", "))
+	}
+}
@@ -0,0 +1,317 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HoBeedzc/cc-switch/internal/testutil"
+)
+
+// newTestManager creates a configuration manager in a temporary home directory
+func newTestManager(t *testing.T) *ConfigManager {
+	t.Helper()
+	return newTestManagerInHome(t, t.TempDir())
+}
+
+// newTestManagerInHome creates a configuration manager in the given home directory
+func newTestManagerInHome(t *testing.T, home string) *ConfigManager {
+	t.Helper()
+	testutil.UseHome(t, home)
+
+	cm, err := NewConfigManagerWithOptions(Options{Warnings: io.Discard})
+	if err != nil {
+		t.Fatalf("NewConfigManagerWithOptions: %v", err)
+	}
+	// Global settings loaded by the manager are package state; restore the defaults afterwards
+	t.Cleanup(func() {
+		os.Remove(cm.GlobalConfigPath())
+		cm.applyGlobalConfig()
+	})
+	return cm
+}
+
+// setCoalesceWindowForTest saves a coalesce window to the global config
+func setCoalesceWindowForTest(t *testing.T, cm *ConfigManager, window string) {
+	t.Helper()
+	cfg, err := cm.LoadGlobalConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.CoalesceWindow = window
+	if err := cm.SaveGlobalConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { setCoalesceWindow("") })
+}
+
+// backdateHistory moves every recorded switch back by d, so the next switch is not part of the same burst
+func backdateHistory(t *testing.T, cm *ConfigManager, d time.Duration) {
+	t.Helper()
+	history, err := cm.loadHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range history.Entries {
+		history.Entries[i].SwitchedAt = history.Entries[i].SwitchedAt.Add(-d)
+	}
+	if err := cm.saveHistory(history); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// entryProfiles returns the profile of each history entry, newest first
+func entryProfiles(t *testing.T, cm *ConfigManager) []string {
+	t.Helper()
+	entries, err := cm.GetHistoryEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	profiles := make([]string, len(entries))
+	for i, entry := range entries {
+		profiles[i] = entry.Profile
+	}
+	return profiles
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRapidSwitchesCoalesce(t *testing.T) {
+	cm := newTestManager(t)
+
+	if err := cm.updateHistory("home", ""); err != nil {
+		t.Fatal(err)
+	}
+	backdateHistory(t, cm, time.Minute)
+
+	// 20 switches in a row: only the first and the last of the burst are recorded
+	for i := 1; i <= 20; i++ {
+		if err := cm.updateHistory(fmt.Sprintf("p%d", i), ""); err != nil {
+			t.Fatalf("switch %d: %v", i, err)
+		}
+	}
+
+	want := []string{"p20", "p1", "home"}
+	if got := entryProfiles(t, cm); !equalStrings(got, want) {
+		t.Fatalf("history entries = %v, want %v", got, want)
+	}
+
+	history, err := cm.loadHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history.Current != "p20" {
+		t.Errorf("current = %q, want p20", history.Current)
+	}
+}
+
+func TestCoalesceWindowSetting(t *testing.T) {
+	tests := []struct {
+		name   string
+		window string
+		notes  bool
+		want   int // history entries after the switches
+	}{
+		{name: "default window", window: "", want: 3},
+		{name: "disabled", window: "0", want: 21},
+		{name: "notes are kept", window: "", notes: true, want: 21},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := newTestManager(t)
+			setCoalesceWindowForTest(t, cm, tt.window)
+
+			if err := cm.updateHistory("home", ""); err != nil {
+				t.Fatal(err)
+			}
+			backdateHistory(t, cm, time.Minute)
+			for i := 1; i <= 20; i++ {
+				note := ""
+				if tt.notes {
+					note = fmt.Sprintf("switch %d", i)
+				}
+				if err := cm.updateHistory(fmt.Sprintf("p%d", i), note); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := entryProfiles(t, cm); len(got) != tt.want {
+				t.Fatalf("history has %d entries %v, want %d", len(got), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoalesceSwitchBackToStart(t *testing.T) {
+	cm := newTestManager(t)
+
+	for _, profile := range []string{"home", "work"} {
+		if err := cm.updateHistory(profile, ""); err != nil {
+			t.Fatal(err)
+		}
+		backdateHistory(t, cm, time.Minute)
+	}
+
+	// a -> b -> a within the window leaves no trace of b
+	for _, profile := range []string{"a", "b", "a"} {
+		if err := cm.updateHistory(profile, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	history, err := cm.loadHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := entryProfiles(t, cm), []string{"a", "work", "home"}; !equalStrings(got, want) {
+		t.Fatalf("history entries = %v, want %v", got, want)
+	}
+	if history.Current != "a" || history.Previous != "work" {
+		t.Errorf("current = %q, previous = %q, want a and work", history.Current, history.Previous)
+	}
+}
+
+func TestParseCoalesceWindow(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "", want: DefaultCoalesceWindow},
+		{value: "0", want: 0},
+		{value: "30s", want: 30 * time.Second},
+		{value: "1m30s", want: 90 * time.Second},
+		{value: "-5s", wantErr: true},
+		{value: "5", wantErr: true},
+		{value: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseCoalesceWindow(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseCoalesceWindow(%q) = %v, want an error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCoalesceWindow(%q): %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Fatalf("ParseCoalesceWindow(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadGlobalConfigRejectsInvalidCoalesceWindow(t *testing.T) {
+	cm := newTestManager(t)
+	if err := cm.fs.WriteFile(cm.GlobalConfigPath(), []byte(`{"coalesce_window": "forever"}`), 0600, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cm.LoadGlobalConfig(); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("LoadGlobalConfig error = %v, want an invalid config error", err)
+	}
+}
+
+// historyOf builds a history from a sequence of switches, oldest first; the last one is current
+func historyOf(sequence ...string) *ConfigHistory {
+	history := &ConfigHistory{}
+	for _, profile := range sequence {
+		history.Entries = append([]HistoryEntry{{Profile: profile}}, history.Entries...)
+		history.Current = profile
+	}
+	return history
+}
+
+func TestResolvePrevious(t *testing.T) {
+	tests := []struct {
+		sequence []string
+		want     string
+	}{
+		{[]string{"a"}, ""},
+		{[]string{"a", "b"}, "a"},
+		{[]string{"a", "b", "b"}, "a"},
+		{[]string{"a", "b", "a"}, "b"},
+		{[]string{"a", "b", "a", "b"}, "a"},
+		{[]string{"a", "empty_mode"}, "a"},
+		{[]string{"a", "b", "empty_mode"}, "b"},
+		// Entering empty mode and restoring the same configuration is not a switch
+		{[]string{"a", "empty_mode", "a"}, ""},
+		{[]string{"a", "b", "empty_mode", "b"}, "a"},
+		{[]string{"a", "empty_mode", "a", "b", "empty_mode", "b"}, "a"},
+		{[]string{"a", "b", "empty_mode", "b", "empty_mode", "b"}, "a"},
+		// Leaving empty mode for another configuration makes empty mode the previous state
+		{[]string{"a", "b", "empty_mode", "c"}, "empty_mode"},
+		{[]string{"empty_mode", "a"}, "empty_mode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.sequence, ","), func(t *testing.T) {
+			if got := ResolvePrevious(historyOf(tt.sequence...)); got != tt.want {
+				t.Errorf("ResolvePrevious = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolvePreviousWithoutEntries(t *testing.T) {
+	// History files written before switch entries were recorded only have Previous
+	if got := ResolvePrevious(&ConfigHistory{Current: "b", Previous: "a"}); got != "a" {
+		t.Errorf("ResolvePrevious = %q, want a", got)
+	}
+	if got := ResolvePrevious(&ConfigHistory{Current: "a", Previous: "a"}); got != "" {
+		t.Errorf("ResolvePrevious = %q, want nothing", got)
+	}
+}
+
+func TestPreviousProfileAfterEmptyModeRoundTrips(t *testing.T) {
+	cm := newTestManager(t)
+	setCoalesceWindowForTest(t, cm, "0")
+	for _, name := range []string{"a", "b"} {
+		if err := cm.CreateProfile(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := cm.GetPreviousProfile(); !errors.Is(err, ErrNoPrevious) {
+		t.Errorf("err = %v, want ErrNoPrevious before any switch", err)
+	}
+
+	if err := cm.UseProfile("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.UseProfile("b"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := cm.EnableEmptyMode(); err != nil {
+			t.Fatal(err)
+		}
+		if previous, _ := cm.GetPreviousProfile(); previous != "b" {
+			t.Errorf("cycle %d in empty mode: previous = %q, want b", i, previous)
+		}
+		if err := cm.DisableEmptyMode(); err != nil {
+			t.Fatal(err)
+		}
+		if previous, _ := cm.GetPreviousProfile(); previous != "a" {
+			t.Errorf("cycle %d after restoring: previous = %q, want a", i, previous)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package config
+
+import "sort"
+
+// 校验问题的稳定 ID，供 doctor --explain 与 Web 接口引用
+const (
+	IssueInvalidJSON              = "invalid-json"
+	IssueProfileUnreadable        = "profile-unreadable"
+	IssueContentEmpty             = "content-empty"
+	IssueContentNotSerializable   = "content-not-serializable"
+	IssueContentTooLarge          = "content-too-large"
+	IssueEnvNotObject             = "env-not-object"
+	IssueEnvValueNotString        = "env-value-not-string"
+	IssueEnvMissing               = "env-missing"
+	IssueSecretEmpty              = "secret-empty"
+	IssueSecretMissing            = "secret-missing"
+	IssuePermissionsNotObject     = "permissions-not-object"
+	IssuePermissionsNotArray      = "permissions-not-array"
+	IssuePermissionsItemNotString = "permissions-item-not-string"
+	IssuePermissionObsolete       = "permission-obsolete"
+	IssueStatusLineNotObject      = "statusline-not-object"
+	IssueStatusLineCommandMissing = "statusline-command-missing"
+	IssueModelNotString           = "model-not-string"
+	IssueNetNotObject             = "net-not-object"
+	IssueNetInvalid               = "net-invalid"
+	IssueTemplateSecretFilled     = "template-secret-filled"
+	IssueTemplateFieldMissing     = "template-field-missing"
+)
+
+// IssueInfo 校验问题的说明：简短标题、详细解释与修复方法（命令或 JSON 片段）
+type IssueInfo struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Explanation string `json:"explanation"`
+	Remediation string `json:"remediation"`
+}
+
+// issueCatalog 所有校验问题的目录
+var issueCatalog = []IssueInfo{
+	{
+		ID:          IssueInvalidJSON,
+		Title:       "File is not valid JSON",
+		Explanation: "The file could not be parsed as JSON, so none of its settings can be read. Common causes are trailing commas, comments, single quotes and unquoted keys.",
+		Remediation: "cc-switch edit <name>   # fix the syntax; the editor refuses to save invalid JSON",
+	},
+	{
+		ID:          IssueProfileUnreadable,
+		Title:       "Profile file cannot be read",
+		Explanation: "The profile exists in the listing but its file could not be opened, usually because of file permissions or because it was removed while cc-switch was running.",
+		Remediation: "ls -l \"$(cc-switch which <name>)\" && chmod 600 \"$(cc-switch which <name>)\"",
+	},
+	{
+		ID:          IssueContentEmpty,
+		Title:       "Configuration is empty",
+		Explanation: "The configuration has no content at all. Claude Code would start without credentials or settings.",
+		Remediation: "cc-switch edit <name> --reset   # restore it from the template it was created from",
+	},
+	{
+		ID:          IssueContentNotSerializable,
+		Title:       "Configuration cannot be serialized",
+		Explanation: "The content contains values that cannot be written back as JSON. This only happens when content is built programmatically.",
+		Remediation: "cc-switch edit <name> --reset   # or rewrite the offending field with cc-switch edit <name>",
+	},
+	{
+		ID:          IssueContentTooLarge,
+		Title:       "Configuration is too large",
+		Explanation: "The serialized configuration exceeds the 1 MB limit shared with the web interface. Large embedded data usually belongs in a separate file.",
+		Remediation: "cc-switch edit <name>   # remove large or unused fields",
+	},
+	{
+		ID:          IssueEnvNotObject,
+		Title:       "env must be an object",
+		Explanation: "Claude Code reads environment variables from the \"env\" object. Any other type (string, array, number) is ignored, so no credentials are passed.",
+		Remediation: `"env": {"ANTHROPIC_AUTH_TOKEN": "<token>", "ANTHROPIC_BASE_URL": "<url>"}`,
+	},
+	{
+		ID:          IssueEnvValueNotString,
+		Title:       "Environment values must be strings",
+		Explanation: "Environment variables are always strings. Numbers, booleans and objects in \"env\" are not passed to Claude Code.",
+		Remediation: `cc-switch edit <name> --json-patch '[{"op":"replace","path":"/env/<KEY>","value":"<value as string>"}]'`,
+	},
+	{
+		ID:          IssueEnvMissing,
+		Title:       "No env section",
+		Explanation: "Without an \"env\" section Claude Code gets no API token or base URL from this configuration and falls back to whatever is in your shell environment.",
+		Remediation: `cc-switch edit <name> --json-patch '[{"op":"add","path":"/env","value":{"ANTHROPIC_AUTH_TOKEN":"<token>"}}]'`,
+	},
+	{
+		ID:          IssueSecretEmpty,
+		Title:       "Credential is empty",
+		Explanation: "ANTHROPIC_AUTH_TOKEN or ANTHROPIC_API_KEY is present but empty, which usually means a template field was never filled in.",
+		Remediation: `cc-switch edit <name> --field env.ANTHROPIC_AUTH_TOKEN   # or use "@secret:<name>" with cc-switch secret set`,
+	},
+	{
+		ID:          IssueSecretMissing,
+		Title:       "No API credential",
+		Explanation: "None of the token keys (ANTHROPIC_AUTH_TOKEN and ANTHROPIC_API_KEY, or the keys listed in auth.token_keys of the global config) is set, so requests made with this configuration will not be authenticated.",
+		Remediation: `cc-switch edit <name> --json-patch '[{"op":"add","path":"/env/ANTHROPIC_AUTH_TOKEN","value":"<token>"}]'`,
+	},
+	{
+		ID:          IssuePermissionsNotObject,
+		Title:       "permissions must be an object",
+		Explanation: "The \"permissions\" field holds \"allow\" and \"deny\" lists. Any other type is ignored by Claude Code.",
+		Remediation: `"permissions": {"allow": [], "deny": []}`,
+	},
+	{
+		ID:          IssuePermissionsNotArray,
+		Title:       "permissions.allow/deny must be arrays of strings",
+		Explanation: "The allow and deny entries are lists of permission rules. A single string or an object is not accepted.",
+		Remediation: `"permissions": {"allow": ["Bash(npm run test:*)"], "deny": []}`,
+	},
+	{
+		ID:          IssuePermissionsItemNotString,
+		Title:       "Permission rules must be strings",
+		Explanation: "Each entry in permissions.allow and permissions.deny is a rule string such as \"Bash(git diff:*)\".",
+		Remediation: `cc-switch edit <name> --field permissions   # quote every rule`,
+	},
+	{
+		ID:          IssuePermissionObsolete,
+		Title:       "Permission rule uses a renamed tool",
+		Explanation: "Claude Code has renamed the tool this rule refers to (for example View is now Read), so the rule no longer matches anything and the permission it grants or denies is silently lost.",
+		Remediation: `cc-switch migrate-permissions <name>`,
+	},
+	{
+		ID:          IssueStatusLineNotObject,
+		Title:       "statusLine must be an object",
+		Explanation: "The status line is configured with an object describing the command to run.",
+		Remediation: `"statusLine": {"type": "command", "command": "<command>"}`,
+	},
+	{
+		ID:          IssueStatusLineCommandMissing,
+		Title:       "statusLine command not found",
+		Explanation: "The program the status line runs does not exist on this machine, is not on PATH or is not executable. Claude Code does not report this; the status line just stays empty. The check only covers the program itself and, for commands such as \"bash ~/statusline.sh\", the script path.",
+		Remediation: `cc-switch edit <name> --field statusLine   # or chmod +x the script`,
+	},
+	{
+		ID:          IssueModelNotString,
+		Title:       "model must be a string",
+		Explanation: "The \"model\" field names a single model. Lists and objects are not supported.",
+		Remediation: `cc-switch edit <name> --json-patch '[{"op":"replace","path":"/model","value":"<model-name>"}]'`,
+	},
+	{
+		ID:          IssueNetNotObject,
+		Title:       "_net must be an object",
+		Explanation: "\"_net\" holds the proxy and extra request headers cc-switch uses for this configuration. It is an object with the optional fields \"proxy\" and \"headers\".",
+		Remediation: `cc-switch edit <name> --json-patch '[{"op":"replace","path":"/_net","value":{"proxy":"http://proxy.example.com:8080"}}]'`,
+	},
+	{
+		ID:          IssueNetInvalid,
+		Title:       "Invalid network settings",
+		Explanation: "\"_net.proxy\" must be an http, https, socks5 or socks5h URL with a host, and \"_net.headers\" must map valid header names to string values. cc-switch refuses to pass malformed values on to Claude Code.",
+		Remediation: "cc-switch edit <name> --field _net.proxy   # or fix the field named in the message with cc-switch edit <name>",
+	},
+	{
+		ID:          IssueTemplateSecretFilled,
+		Title:       "Template contains a real secret",
+		Explanation: "A token or key field in the template has a value. Templates are meant to be shared and every configuration created from them inherits the value, so a real credential here is a leak risk. This usually means a configuration was edited as if it were the template.",
+		Remediation: "cc-switch edit -t <name> --field <path>   # clear the value, or use \"@secret:<secret-name>\"",
+	},
+	{
+		ID:          IssueTemplateFieldMissing,
+		Title:       "Template declares a field that does not exist",
+		Explanation: "\"_fields\" lists the paths to fill in when creating a configuration from the template. A path that does not exist in the template is never asked for.",
+		Remediation: "cc-switch edit -t <name>   # add the field or remove it from _fields",
+	},
+}
+
+// IssueCatalog 返回按 ID 排序的全部校验问题说明
+func IssueCatalog() []IssueInfo {
+	catalog := make([]IssueInfo, len(issueCatalog))
+	copy(catalog, issueCatalog)
+	sort.Slice(catalog, func(i, j int) bool { return catalog[i].ID < catalog[j].ID })
+	return catalog
+}
+
+// LookupIssue 按 ID 查找校验问题说明
+func LookupIssue(id string) (IssueInfo, bool) {
+	for _, info := range issueCatalog {
+		if info.ID == id {
+			return info, true
+		}
+	}
+	return IssueInfo{}, false
+}
+
+// NewIssue 创建校验问题，并从目录中补全解释与修复方法
+func NewIssue(id, severity, path, message string) ValidationIssue {
+	issue := ValidationIssue{ID: id, Severity: severity, Path: path, Message: message}
+	if info, ok := LookupIssue(id); ok {
+		issue.Explanation = info.Explanation
+		issue.Remediation = info.Remediation
+	}
+	return issue
+}
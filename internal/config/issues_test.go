@@ -0,0 +1,104 @@
+package config
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+// allIssueIDs lists every issue ID constant
+var allIssueIDs = []string{
+	IssueInvalidJSON, IssueProfileUnreadable, IssueContentEmpty, IssueContentNotSerializable,
+	IssueContentTooLarge, IssueEnvNotObject, IssueEnvValueNotString, IssueEnvMissing,
+	IssueSecretEmpty, IssueSecretMissing, IssuePermissionsNotObject, IssuePermissionsNotArray,
+	IssuePermissionsItemNotString, IssuePermissionObsolete, IssueStatusLineNotObject,
+	IssueStatusLineCommandMissing, IssueModelNotString, IssueNetNotObject, IssueNetInvalid,
+	IssueTemplateSecretFilled, IssueTemplateFieldMissing,
+}
+
+func TestIssueCatalog(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, info := range issueCatalog {
+		if seen[info.ID] {
+			t.Errorf("duplicate issue ID %q", info.ID)
+		}
+		seen[info.ID] = true
+
+		if info.ID == "" || info.ID != strings.ToLower(info.ID) || strings.ContainsAny(info.ID, " _") {
+			t.Errorf("issue ID %q must be lower-case words joined by hyphens", info.ID)
+		}
+		if strings.TrimSpace(info.Title) == "" {
+			t.Errorf("%s has no title", info.ID)
+		}
+		if strings.TrimSpace(info.Explanation) == "" {
+			t.Errorf("%s has no explanation", info.ID)
+		}
+		if strings.TrimSpace(info.Remediation) == "" {
+			t.Errorf("%s has no remediation", info.ID)
+		}
+	}
+
+	for _, id := range allIssueIDs {
+		if !seen[id] {
+			t.Errorf("issue ID %q is not in the catalog", id)
+		}
+	}
+	if len(seen) != len(allIssueIDs) {
+		t.Errorf("catalog has %d issues, %d ID constants", len(seen), len(allIssueIDs))
+	}
+
+	catalog := IssueCatalog()
+	if !sort.SliceIsSorted(catalog, func(i, j int) bool { return catalog[i].ID < catalog[j].ID }) {
+		t.Error("IssueCatalog is not sorted by ID")
+	}
+}
+
+func TestNewIssueFillsFromCatalog(t *testing.T) {
+	issue := NewIssue(IssueEnvMissing, SeverityWarning, "env", "no env section")
+	info, ok := LookupIssue(IssueEnvMissing)
+	if !ok {
+		t.Fatal("LookupIssue did not find env-missing")
+	}
+	if issue.Explanation != info.Explanation || issue.Remediation != info.Remediation {
+		t.Errorf("issue = %+v, want the catalog explanation and remediation", issue)
+	}
+
+	if _, ok := LookupIssue("no-such-issue"); ok {
+		t.Error("LookupIssue found an unknown ID")
+	}
+}
+
+func TestValidateContentIssueIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		content map[string]interface{}
+		want    string
+	}{
+		{name: "nil content", content: nil, want: IssueContentEmpty},
+		{name: "env not an object", content: map[string]interface{}{"env": "x"}, want: IssueEnvNotObject},
+		{name: "env value not a string", content: map[string]interface{}{"env": map[string]interface{}{"A": 1}}, want: IssueEnvValueNotString},
+		{name: "no env", content: map[string]interface{}{"model": "opus"}, want: IssueEnvMissing},
+		{name: "no token", content: map[string]interface{}{"env": map[string]interface{}{}}, want: IssueSecretMissing},
+		{name: "permissions not an object", content: map[string]interface{}{"permissions": []interface{}{}}, want: IssuePermissionsNotObject},
+		{name: "allow not an array", content: map[string]interface{}{"permissions": map[string]interface{}{"allow": "Bash"}}, want: IssuePermissionsNotArray},
+		{name: "allow item not a string", content: map[string]interface{}{"permissions": map[string]interface{}{"allow": []interface{}{1}}}, want: IssuePermissionsItemNotString},
+		{name: "statusLine not an object", content: map[string]interface{}{"statusLine": "x"}, want: IssueStatusLineNotObject},
+		{name: "model not a string", content: map[string]interface{}{"model": 1}, want: IssueModelNotString},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := ValidateContent(tt.content, false)
+			found := false
+			for _, issue := range issues {
+				if issue.Remediation == "" {
+					t.Errorf("issue %s at %q has no remediation", issue.ID, issue.Path)
+				}
+				found = found || issue.ID == tt.want
+			}
+			if !found {
+				t.Errorf("issues = %+v, want %s", issues, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,310 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PatchOperation JSON Patch 操作（RFC 6902 子集：add、replace、remove、test）
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// ParseJSONPatch 解析 JSON Patch 文档
+func ParseJSONPatch(data []byte) ([]PatchOperation, error) {
+	var raw []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, Invalidf("invalid JSON patch: must be an array of operations: %w", err)
+	}
+
+	ops := make([]PatchOperation, 0, len(raw))
+	for i, item := range raw {
+		var op PatchOperation
+		if err := json.Unmarshal(item["op"], &op.Op); err != nil {
+			return nil, Invalidf("operation %d: missing or invalid 'op'", i)
+		}
+		if err := json.Unmarshal(item["path"], &op.Path); err != nil {
+			return nil, Invalidf("operation %d: missing or invalid 'path'", i)
+		}
+
+		switch op.Op {
+		case "add", "replace", "test":
+			// 这些操作必须带 value（允许为 null）
+			value, ok := item["value"]
+			if !ok {
+				return nil, Invalidf("operation %d (%s): missing 'value'", i, op.Op)
+			}
+			if err := json.Unmarshal(value, &op.Value); err != nil {
+				return nil, Invalidf("operation %d (%s): invalid 'value': %w", i, op.Op, err)
+			}
+		case "remove":
+		default:
+			return nil, Invalidf("operation %d: unsupported op '%s' (supported: add, replace, remove, test)", i, op.Op)
+		}
+
+		ops = append(ops, op)
+	}
+
+	return ops, nil
+}
+
+// ApplyJSONPatch 将补丁应用到配置内容的副本上，原内容不会被修改
+// 任一操作失败时返回错误，不会产生部分修改的结果
+func ApplyJSONPatch(content map[string]interface{}, ops []PatchOperation) (map[string]interface{}, error) {
+	// 通过 JSON 往返进行深拷贝，同时统一数值类型
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy content: %w", err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to copy content: %w", err)
+	}
+
+	for i, op := range ops {
+		tokens, err := parsePointer(op.Path)
+		if err != nil {
+			return nil, Invalidf("operation %d (%s): %w", i, op.Op, err)
+		}
+
+		// 统一 value 的类型表示，便于比较和写入
+		value, err := normalizeJSON(op.Value)
+		if err != nil {
+			return nil, Invalidf("operation %d (%s): invalid value: %w", i, op.Op, err)
+		}
+
+		switch op.Op {
+		case "add":
+			doc, err = patchAdd(doc, tokens, value)
+		case "replace":
+			doc, err = patchReplace(doc, tokens, value)
+		case "remove":
+			doc, err = patchRemove(doc, tokens)
+		case "test":
+			err = patchTest(doc, tokens, value)
+		default:
+			err = fmt.Errorf("unsupported op")
+		}
+		if err != nil {
+			return nil, Invalidf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	result, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, Invalidf("patch result must be a JSON object")
+	}
+	return result, nil
+}
+
+// normalizeJSON 将值转换为 encoding/json 解码后的标准表示
+func normalizeJSON(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// parsePointer 解析 JSON Pointer（RFC 6901）
+func parsePointer(path string) ([]string, error) {
+	if path == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, Invalidf("invalid path '%s': must be empty or start with '/'", path)
+	}
+
+	tokens := strings.Split(path[1:], "/")
+	for i, token := range tokens {
+		token = strings.ReplaceAll(token, "~1", "/")
+		tokens[i] = strings.ReplaceAll(token, "~0", "~")
+	}
+	return tokens, nil
+}
+
+// arrayIndex 解析数组下标；allowEnd 表示允许 "-" 或 len 作为追加位置
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if token == "-" {
+		if allowEnd {
+			return length, nil
+		}
+		return 0, fmt.Errorf("'-' is only valid for add")
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, Invalidf("invalid array index '%s'", token)
+	}
+
+	limit := length - 1
+	if allowEnd {
+		limit = length
+	}
+	if index > limit {
+		return 0, fmt.Errorf("array index %d out of range (length %d)", index, length)
+	}
+	return index, nil
+}
+
+// getAt 获取指针指向的值
+func getAt(doc interface{}, tokens []string) (interface{}, error) {
+	current := doc
+	for _, token := range tokens {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path not found: member '%s' does not exist", token)
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("path not found: cannot descend into '%s'", token)
+		}
+	}
+	return current, nil
+}
+
+// setAt 对父节点执行修改，返回修改后的文档（数组修改可能需要替换父引用）
+func setAt(doc interface{}, tokens []string, modify func(parent interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(tokens) == 0 {
+		return modify(nil, "")
+	}
+
+	parentTokens, key := tokens[:len(tokens)-1], tokens[len(tokens)-1]
+	parent, err := getAt(doc, parentTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := modify(parent, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// 数组追加/删除会生成新的切片，需要写回其父节点
+	if _, isArray := parent.([]interface{}); isArray {
+		if len(parentTokens) == 0 {
+			return updated, nil
+		}
+		return setAt(doc, parentTokens, func(grandparent interface{}, parentKey string) (interface{}, error) {
+			switch node := grandparent.(type) {
+			case map[string]interface{}:
+				node[parentKey] = updated
+			case []interface{}:
+				index, err := arrayIndex(parentKey, len(node), false)
+				if err != nil {
+					return nil, err
+				}
+				node[index] = updated
+			}
+			return grandparent, nil
+		})
+	}
+
+	return doc, nil
+}
+
+func patchAdd(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	return setAt(doc, tokens, func(parent interface{}, key string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[key] = value
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(key, len(node), true)
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+			return node, nil
+		default:
+			return nil, fmt.Errorf("parent of '%s' is not an object or array", key)
+		}
+	})
+}
+
+func patchReplace(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	return setAt(doc, tokens, func(parent interface{}, key string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := node[key]; !ok {
+				return nil, fmt.Errorf("path not found: member '%s' does not exist", key)
+			}
+			node[key] = value
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(key, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			node[index] = value
+			return node, nil
+		default:
+			return nil, fmt.Errorf("parent of '%s' is not an object or array", key)
+		}
+	})
+}
+
+func patchRemove(doc interface{}, tokens []string) (interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("cannot remove the whole document")
+	}
+
+	return setAt(doc, tokens, func(parent interface{}, key string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := node[key]; !ok {
+				return nil, fmt.Errorf("path not found: member '%s' does not exist", key)
+			}
+			delete(node, key)
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(key, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			return append(node[:index], node[index+1:]...), nil
+		default:
+			return nil, fmt.Errorf("parent of '%s' is not an object or array", key)
+		}
+	})
+}
+
+func patchTest(doc interface{}, tokens []string, expected interface{}) error {
+	actual, err := getAt(doc, tokens)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		actualJSON, _ := json.Marshal(actual)
+		expectedJSON, _ := json.Marshal(expected)
+		return fmt.Errorf("test failed: value is %s, expected %s", actualJSON, expectedJSON)
+	}
+	return nil
+}
@@ -0,0 +1,304 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// patchBase is the document every patch test starts from
+const patchBase = `{
+	"env": {"ANTHROPIC_BASE_URL": "https://api.example.com", "a/b": "slash", "m~n": "tilde"},
+	"list": [1, 2, 3],
+	"nested": {"items": [{"name": "first"}]}
+}`
+
+func decodeTestJSON(t *testing.T, data string) map[string]interface{} {
+	t.Helper()
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatalf("bad test JSON %s: %v", data, err)
+	}
+	return doc
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   string
+		want    string // expected document; empty when wantErr is set
+		wantErr string
+	}{
+		// add
+		{
+			name:  "add object member",
+			patch: `[{"op": "add", "path": "/env/ANTHROPIC_MODEL", "value": "opus"}]`,
+			want:  `{"env": {"ANTHROPIC_BASE_URL": "https://api.example.com", "ANTHROPIC_MODEL": "opus", "a/b": "slash", "m~n": "tilde"}, "list": [1, 2, 3], "nested": {"items": [{"name": "first"}]}}`,
+		},
+		{
+			name:  "add replaces existing member",
+			patch: `[{"op": "add", "path": "/env/ANTHROPIC_BASE_URL", "value": "https://proxy"}]`,
+			want:  `{"env": {"ANTHROPIC_BASE_URL": "https://proxy", "a/b": "slash", "m~n": "tilde"}, "list": [1, 2, 3], "nested": {"items": [{"name": "first"}]}}`,
+		},
+		{
+			name:  "add inserts into array",
+			patch: `[{"op": "add", "path": "/list/1", "value": 9}]`,
+			want:  `{"env": {"ANTHROPIC_BASE_URL": "https://api.example.com", "a/b": "slash", "m~n": "tilde"}, "list": [1, 9, 2, 3], "nested": {"items": [{"name": "first"}]}}`,
+		},
+		{
+			name:  "add appends with dash",
+			patch: `[{"op": "add", "path": "/list/-", "value": 4}]`,
+			want:  `{"env": {"ANTHROPIC_BASE_URL": "https://api.example.com", "a/b": "slash", "m~n": "tilde"}, "list": [1, 2, 3, 4], "nested": {"items": [{"name": "first"}]}}`,
+		},
+		{
+			name:  "add appends at length",
+			patch: `[{"op": "add", "path": "/list/3", "value": 4}]`,
+			want:  `{"env": {"ANTHROPIC_BASE_URL": "https://api.example.com", "a/b": "slash", "m~n": "tilde"}, "list": [1, 2, 3, 4], "nested": {"items": [{"name": "first"}]}}`,
+		},
+		{
+			name:  "add into nested array",
+			patch: `[{"op": "add", "path": "/nested/items/0", "value": {"name": "zero"}}]`,
+			want:  `{"env": {"ANTHROPIC_BASE_URL": "https://api.example.com", "a/b": "slash", "m~n": "tilde"}, "list": [1, 2, 3], "nested": {"items": [{"name": "zero"}, {"name": "first"}]}}`,
+		},
+		{
+			name:  "add null value",
+			patch: `[{"op": "add", "path": "/extra", "value": null}]`,
+			want:  `{"env": {"ANTHROPIC_BASE_URL": "https://api.example.com", "a/b": "slash", "m~n": "tilde"}, "extra": null, "list": [1, 2, 3], "nested": {"items": [{"name": "first"}]}}`,
+		},
+		{
+			name:  "add whole document",
+			patch: `[{"op": "add", "path": "", "value": {"env": {}}}]`,
+			want:  `{"env": {}}`,
+		},
+		{
+			name:    "add past end of array",
+			patch:   `[{"op": "add", "path": "/list/4", "value": 5}]`,
+			wantErr: "array index 4 out of range (length 3)",
+		},
+		{
+			name:    "add under missing parent",
+			patch:   `[{"op": "add", "path": "/missing/key", "value": 1}]`,
+			wantErr: "member 'missing' does not exist",
+		},
+		{
+			name:    "add below a string",
+			patch:   `[{"op": "add", "path": "/env/ANTHROPIC_BASE_URL/x", "value": 1}]`,
+			wantErr: "parent of 'x' is not an object or array",
+		},
+
+		// replace
+		{
+			name:  "replace object member",
+			patch: `[{"op": "replace", "path": "/env/ANTHROPIC_BASE_URL", "value": "https://proxy"}]`,
+			want:  `{"env": {"ANTHROPIC_BASE_URL": "https://proxy", "a/b": "slash", "m~n": "tilde"}, "list": [1, 2, 3], "nested": {"items": [{"name": "first"}]}}`,
+		},
+		{
+			name:  "replace array element",
+			patch: `[{"op": "replace", "path": "/list/2", "value": "three"}]`,
+			want:  `{"env": {"ANTHROPIC_BASE_URL": "https://api.example.com", "a/b": "slash", "m~n": "tilde"}, "list": [1, 2, "three"], "nested": {"items": [{"name": "first"}]}}`,
+		},
+		{
+			name:    "replace missing member",
+			patch:   `[{"op": "replace", "path": "/env/MISSING", "value": "x"}]`,
+			wantErr: "member 'MISSING' does not exist",
+		},
+		{
+			name:    "replace array index equal to length",
+			patch:   `[{"op": "replace", "path": "/list/3", "value": 4}]`,
+			wantErr: "array index 3 out of range (length 3)",
+		},
+		{
+			name:    "replace with dash",
+			patch:   `[{"op": "replace", "path": "/list/-", "value": 4}]`,
+			wantErr: "'-' is only valid for add",
+		},
+
+		// remove
+		{
+			name:  "remove object member",
+			patch: `[{"op": "remove", "path": "/nested"}]`,
+			want:  `{"env": {"ANTHROPIC_BASE_URL": "https://api.example.com", "a/b": "slash", "m~n": "tilde"}, "list": [1, 2, 3]}`,
+		},
+		{
+			name:  "remove array element",
+			patch: `[{"op": "remove", "path": "/list/0"}]`,
+			want:  `{"env": {"ANTHROPIC_BASE_URL": "https://api.example.com", "a/b": "slash", "m~n": "tilde"}, "list": [2, 3], "nested": {"items": [{"name": "first"}]}}`,
+		},
+		{
+			name:  "remove from nested array",
+			patch: `[{"op": "remove", "path": "/nested/items/0"}]`,
+			want:  `{"env": {"ANTHROPIC_BASE_URL": "https://api.example.com", "a/b": "slash", "m~n": "tilde"}, "list": [1, 2, 3], "nested": {"items": []}}`,
+		},
+		{
+			name:    "remove missing member",
+			patch:   `[{"op": "remove", "path": "/env/MISSING"}]`,
+			wantErr: "member 'MISSING' does not exist",
+		},
+		{
+			name:    "remove out of range index",
+			patch:   `[{"op": "remove", "path": "/list/7"}]`,
+			wantErr: "array index 7 out of range (length 3)",
+		},
+		{
+			name:    "remove whole document",
+			patch:   `[{"op": "remove", "path": ""}]`,
+			wantErr: "cannot remove the whole document",
+		},
+
+		// test
+		{
+			name:  "test passes before replace",
+			patch: `[{"op": "test", "path": "/list/0", "value": 1}, {"op": "replace", "path": "/list/0", "value": 0}]`,
+			want:  `{"env": {"ANTHROPIC_BASE_URL": "https://api.example.com", "a/b": "slash", "m~n": "tilde"}, "list": [0, 2, 3], "nested": {"items": [{"name": "first"}]}}`,
+		},
+		{
+			name:  "test compares objects",
+			patch: `[{"op": "test", "path": "/nested/items/0", "value": {"name": "first"}}]`,
+			want:  patchBase,
+		},
+		{
+			name:    "test fails on different value",
+			patch:   `[{"op": "test", "path": "/env/ANTHROPIC_BASE_URL", "value": "https://other"}]`,
+			wantErr: `test failed: value is "https://api.example.com", expected "https://other"`,
+		},
+		{
+			name:    "test fails on different type",
+			patch:   `[{"op": "test", "path": "/list/0", "value": "1"}]`,
+			wantErr: `test failed: value is 1, expected "1"`,
+		},
+		{
+			name:    "test fails on missing path",
+			patch:   `[{"op": "test", "path": "/env/MISSING", "value": "x"}]`,
+			wantErr: "member 'MISSING' does not exist",
+		},
+		{
+			name:    "failed test stops later operations",
+			patch:   `[{"op": "replace", "path": "/list/0", "value": 0}, {"op": "test", "path": "/list/0", "value": 1}]`,
+			wantErr: "operation 1 (test /list/0): test failed",
+		},
+
+		// escapes
+		{
+			name:  "tilde one is a slash",
+			patch: `[{"op": "replace", "path": "/env/a~1b", "value": "escaped"}]`,
+			want:  `{"env": {"ANTHROPIC_BASE_URL": "https://api.example.com", "a/b": "escaped", "m~n": "tilde"}, "list": [1, 2, 3], "nested": {"items": [{"name": "first"}]}}`,
+		},
+		{
+			name:  "tilde zero is a tilde",
+			patch: `[{"op": "remove", "path": "/env/m~0n"}]`,
+			want:  `{"env": {"ANTHROPIC_BASE_URL": "https://api.example.com", "a/b": "slash"}, "list": [1, 2, 3], "nested": {"items": [{"name": "first"}]}}`,
+		},
+		{
+			name:  "tilde zero one is a literal tilde one",
+			patch: `[{"op": "add", "path": "/env/x~01", "value": "v"}]`,
+			want:  `{"env": {"ANTHROPIC_BASE_URL": "https://api.example.com", "a/b": "slash", "m~n": "tilde", "x~1": "v"}, "list": [1, 2, 3], "nested": {"items": [{"name": "first"}]}}`,
+		},
+		{
+			name:    "unescaped slash addresses a nested member",
+			patch:   `[{"op": "test", "path": "/env/a/b", "value": "slash"}]`,
+			wantErr: "member 'a' does not exist",
+		},
+
+		// pointers and indices
+		{
+			name:    "path without leading slash",
+			patch:   `[{"op": "remove", "path": "env"}]`,
+			wantErr: "must be empty or start with '/'",
+		},
+		{
+			name:    "negative index",
+			patch:   `[{"op": "remove", "path": "/list/-1"}]`,
+			wantErr: "invalid array index '-1'",
+		},
+		{
+			name:    "index with leading zero",
+			patch:   `[{"op": "replace", "path": "/list/01", "value": 0}]`,
+			wantErr: "invalid array index '01'",
+		},
+		{
+			name:    "non-numeric index",
+			patch:   `[{"op": "remove", "path": "/list/first"}]`,
+			wantErr: "invalid array index 'first'",
+		},
+		{
+			name:    "result is not an object",
+			patch:   `[{"op": "replace", "path": "", "value": [1]}]`,
+			wantErr: "patch result must be a JSON object",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := decodeTestJSON(t, patchBase)
+			ops, err := ParseJSONPatch([]byte(tt.patch))
+			if err != nil {
+				t.Fatalf("ParseJSONPatch: %v", err)
+			}
+
+			got, err := ApplyJSONPatch(base, ops)
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("expected an error containing %q, got %v", tt.wantErr, got)
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %q, want it to contain %q", err, tt.wantErr)
+				}
+				if !errors.Is(err, ErrInvalid) {
+					t.Fatalf("error %q is not ErrInvalid", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyJSONPatch: %v", err)
+			}
+			if want := decodeTestJSON(t, tt.want); !reflect.DeepEqual(got, want) {
+				gotJSON, _ := json.Marshal(got)
+				wantJSON, _ := json.Marshal(want)
+				t.Fatalf("result = %s\nwant     %s", gotJSON, wantJSON)
+			}
+
+			// The input document is never modified
+			if !reflect.DeepEqual(base, decodeTestJSON(t, patchBase)) {
+				t.Fatal("ApplyJSONPatch modified its input")
+			}
+		})
+	}
+}
+
+func TestParseJSONPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   string
+		wantErr string
+	}{
+		{name: "valid operations", patch: `[{"op": "add", "path": "/a", "value": 1}, {"op": "remove", "path": "/a"}]`},
+		{name: "null value is allowed", patch: `[{"op": "replace", "path": "/a", "value": null}]`},
+		{name: "empty patch", patch: `[]`},
+		{name: "not an array", patch: `{"op": "add"}`, wantErr: "must be an array of operations"},
+		{name: "missing op", patch: `[{"path": "/a"}]`, wantErr: "operation 0: missing or invalid 'op'"},
+		{name: "missing path", patch: `[{"op": "remove"}]`, wantErr: "operation 0: missing or invalid 'path'"},
+		{name: "missing value", patch: `[{"op": "test", "path": "/a"}]`, wantErr: "operation 0 (test): missing 'value'"},
+		{name: "unsupported op", patch: `[{"op": "move", "from": "/a", "path": "/b"}]`, wantErr: "unsupported op 'move'"},
+		{name: "error names the operation", patch: `[{"op": "remove", "path": "/a"}, {"op": "copy", "path": "/b"}]`, wantErr: "operation 1:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseJSONPatch([]byte(tt.patch))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			if !errors.Is(err, ErrInvalid) {
+				t.Fatalf("error %q is not ErrInvalid", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// localSettingsFileName Claude Code 在 settings.json 之上叠加的本地覆盖文件
+const localSettingsFileName = "settings.local.json"
+
+// LocalSettingsPath 返回 ~/.claude/settings.local.json 的路径
+func (cm *ConfigManager) LocalSettingsPath() string {
+	return filepath.Join(cm.claudeDir, localSettingsFileName)
+}
+
+// GetLocalSettingsContent 读取 settings.local.json，文件不存在时返回 nil
+func (cm *ConfigManager) GetLocalSettingsContent() (map[string]interface{}, error) {
+	if _, err := os.Stat(cm.LocalSettingsPath()); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return readJSONFile(cm.LocalSettingsPath())
+}
+
+// LocalOverrides 返回 settings.local.json 相对于 content 覆盖（DiffChanged）或额外设置（DiffAdded）的字段
+// cc-switch 只管理 settings.json，这些字段在切换后仍以本地文件为准；没有本地文件时返回 nil
+func (cm *ConfigManager) LocalOverrides(content map[string]interface{}) ([]DiffEntry, error) {
+	local, err := cm.GetLocalSettingsContent()
+	if err != nil || local == nil {
+		return nil, err
+	}
+
+	var overrides []DiffEntry
+	for _, diff := range DiffContent(content, local) {
+		if diff.Kind == DiffChanged || diff.Kind == DiffAdded {
+			overrides = append(overrides, diff)
+		}
+	}
+	return overrides, nil
+}
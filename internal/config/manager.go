@@ -1,41 +1,117 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/HoBeedzc/cc-switch/internal/common"
 )
 
+// SystemProfilesDirEnv 指定只读系统配置目录的环境变量
+const SystemProfilesDirEnv = "CC_SWITCH_SYSTEM_PROFILES_DIR"
+
 // ConfigManager 管理Claude配置切换
 type ConfigManager struct {
-	claudeDir     string
-	profilesDir   string
-	templatesDir  string
-	currentFile   string
-	settingsFile  string
-	historyFile   string
-	emptyModeFile string
+	claudeDir         string
+	profilesDir       string
+	systemProfilesDir string
+	templatesDir      string
+	currentFile       string
+	settingsFile      string
+	historyFile       string
+	emptyModeFile     string
+	layout            common.Layout // 自身状态文件所在目录，所有状态文件路径经 dataFile 构造
+
+	newerVersionWarned map[string]bool        // 已提示过由更新版本写入的配置
+	secretsPassphrase  string                 // 本次运行中已解锁的密钥库口令
+	passphraseSource   func() (string, error) // 密钥库口令来源，默认在终端中提示输入
+	warnings           io.Writer              // 警告和提示信息的输出位置
+	fs                 fileSystem             // 所有写操作经由此处，只读模式下不改动磁盘
+
+	currentMu    sync.Mutex
+	currentCache *currentProfileCache // 最近一次读取的 .current，nil 表示需要重新读取
+
+	activityMu sync.Mutex // 串行化活动日志的轮转和追加，并行测试会同时写入
+}
+
+// currentProfileCache 缓存的当前配置名，以及读取时 .current 的修改时间和大小
+// 文件被其他进程改写时修改时间或大小会变化，缓存随之失效
+type currentProfileCache struct {
+	name    string
+	modTime time.Time
+	size    int64
 }
 
 // Profile 配置文件信息
 type Profile struct {
-	Name      string `json:"name"`
-	IsCurrent bool   `json:"is_current"`
-	Path      string `json:"path"`
+	Name        string    `json:"name"`
+	IsCurrent   bool      `json:"is_current"`
+	Path        string    `json:"path"`
+	ReadOnly    bool      `json:"read_only,omitempty"`    // 来自系统配置目录，只读
+	Snapshot    bool      `json:"snapshot,omitempty"`     // 首次运行时保存的 original-settings 快照，不可修改
+	Error       string    `json:"error,omitempty"`        // 配置文件无法读取的原因（权限不足、失效的符号链接等）
+	DisplayName string    `json:"display_name,omitempty"` // 仅用于展示的友好名称
+	Scratch     bool      `json:"scratch,omitempty"`      // 临时配置，切换离开后自动删除
+	Aliases     []string  `json:"aliases,omitempty"`      // 配置的别名
+	Tags        []string  `json:"tags,omitempty"`         // 配置的标签
+	Project     string    `json:"project,omitempty"`      // 配置对应的项目目录
+	LastTest    *LastTest `json:"last_test,omitempty"`    // 最近一次测试结果，仅由 AttachLastTests 填充
+}
+
+// Label 返回用于展示的名称，未设置显示名称时使用配置名
+func (p Profile) Label() string {
+	if p.DisplayName != "" {
+		return p.DisplayName
+	}
+	return p.Name
 }
 
 // ConfigHistory 配置历史记录
 type ConfigHistory struct {
-	Current   string    `json:"current"`
-	Previous  string    `json:"previous"`
-	History   []string  `json:"history"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Current     string         `json:"current"`
+	Previous    string         `json:"previous"`
+	History     []string       `json:"history"`
+	Entries     []HistoryEntry `json:"entries,omitempty"`      // 切换记录，最新的在前
+	CurrentHash string         `json:"current_hash,omitempty"` // 切换时 settings.json 的内容哈希
+	UpdatedAt   time.Time      `json:"updated_at"`
 }
 
+// HistoryEntry 单次配置切换记录
+type HistoryEntry struct {
+	Profile    string    `json:"profile"`
+	Note       string    `json:"note,omitempty"`
+	SwitchedAt time.Time `json:"switched_at"`
+}
+
+// MaxNoteLength 切换备注的最大长度（字符数）
+const MaxNoteLength = 200
+
+// maxHistoryEntries 保留的切换记录数量
+const maxHistoryEntries = 50
+
+// emptyBackupFileName 空配置模式下 settings.json 备份文件名，位于数据目录下
+const emptyBackupFileName = ".empty_backup_settings.json"
+
+// 状态文件名，位于数据目录（默认即 profiles/）下
+const (
+	currentFileName   = ".current"
+	historyFileName   = ".history"
+	emptyModeFileName = ".empty_mode"
+)
+
+// DefaultCoalesceWindow 默认的切换记录合并窗口
+const DefaultCoalesceWindow = 5 * time.Second
+
 // EmptyModeError 空配置模式错误
 type EmptyModeError struct {
 	Message     string
@@ -56,6 +132,10 @@ func (e *NoCurrentProfileError) Error() string {
 	return e.Message
 }
 
+func (e *NoCurrentProfileError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
 // ProfileMissingError 配置文件缺失错误
 type ProfileMissingError struct {
 	ProfileName string
@@ -67,6 +147,10 @@ func (e *ProfileMissingError) Error() string {
 	return e.Message
 }
 
+func (e *ProfileMissingError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
 // EmptyModeInfo 空配置模式信息
 type EmptyModeInfo struct {
 	Enabled         bool      `json:"enabled"`
@@ -77,10 +161,11 @@ type EmptyModeInfo struct {
 
 // TemplateField 模板字段信息
 type TemplateField struct {
-	Path        string `json:"path"`        // 字段路径，如 "env.ANTHROPIC_AUTH_TOKEN"
-	Name        string `json:"name"`        // 字段名称，如 "ANTHROPIC_AUTH_TOKEN"
-	Description string `json:"description"` // 用户友好的描述
-	Required    bool   `json:"required"`    // 是否必填
+	Path        string `json:"path"`              // 字段路径，如 "env.ANTHROPIC_AUTH_TOKEN"
+	Name        string `json:"name"`              // 字段名称，如 "ANTHROPIC_AUTH_TOKEN"
+	Description string `json:"description"`       // 用户友好的描述
+	Required    bool   `json:"required"`          // 是否必填
+	Current     string `json:"current,omitempty"` // 模板中已有的值，为空表示待填写
 }
 
 // TemplateFieldInput 模板字段输入结果
@@ -89,9 +174,35 @@ type TemplateFieldInput struct {
 	Value string        `json:"value"`
 }
 
+// Options 配置管理器选项
+type Options struct {
+	ProfilesDirName  string // cc-switch 数据目录名，位于 ~/.claude 下
+	TemplatesDirName string // 模板目录名，位于数据目录下
+	DryRun           bool   // 只读模式：写操作只输出将要执行的动作，不改动磁盘
+
+	// Warnings 警告和提示信息的输出位置，为空时写到 stderr
+	Warnings io.Writer
+	// Passphrase 密钥库口令来源，未设置口令和环境变量时调用；为空时使用 PromptPassphrase
+	Passphrase func() (string, error)
+}
+
+// DefaultOptions 返回默认选项（可通过环境变量覆盖目录名）
+func DefaultOptions() Options {
+	return Options{
+		ProfilesDirName:  common.ProfilesDirName(),
+		TemplatesDirName: common.TemplatesDirName(),
+		DryRun:           dryRun,
+	}
+}
+
 // NewConfigManager 创建新的配置管理器
 func NewConfigManager() (*ConfigManager, error) {
-	cm, err := NewConfigManagerNoInit()
+	return NewConfigManagerWithOptions(DefaultOptions())
+}
+
+// NewConfigManagerWithOptions 使用指定选项创建并初始化配置管理器
+func NewConfigManagerWithOptions(opts Options) (*ConfigManager, error) {
+	cm, err := newConfigManager(opts)
 	if err != nil {
 		return nil, err
 	}
@@ -106,44 +217,188 @@ func NewConfigManager() (*ConfigManager, error) {
 
 // NewConfigManagerNoInit 创建配置管理器但不执行初始化（用于init命令）
 func NewConfigManagerNoInit() (*ConfigManager, error) {
-	homeDir, err := os.UserHomeDir()
+	return newConfigManager(DefaultOptions())
+}
+
+// newConfigManager 根据选项构造配置管理器，空的目录名使用默认值
+func newConfigManager(opts Options) (*ConfigManager, error) {
+	if opts.ProfilesDirName == "" {
+		opts.ProfilesDirName = common.DefaultProfilesDirName
+	}
+	if opts.TemplatesDirName == "" {
+		opts.TemplatesDirName = common.DefaultTemplatesDirName
+	}
+	if opts.Warnings == nil {
+		opts.Warnings = os.Stderr
+	}
+	if opts.Passphrase == nil {
+		opts.Passphrase = PromptPassphrase
+	}
+	if !common.IsValidDirName(opts.ProfilesDirName) {
+		return nil, Invalidf("invalid profiles directory name '%s'", opts.ProfilesDirName)
+	}
+	if !common.IsValidDirName(opts.TemplatesDirName) {
+		return nil, Invalidf("invalid templates directory name '%s'", opts.TemplatesDirName)
+	}
+
+	layout, err := common.ResolveLayout(opts.ProfilesDirName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get home directory: %w", err)
 	}
-
-	claudeDir := filepath.Join(homeDir, ".claude")
-	profilesDir := filepath.Join(claudeDir, "profiles")
-	templatesDir := filepath.Join(profilesDir, "templates")
-	settingsFile := filepath.Join(claudeDir, "settings.json")
-
-	// All cc-switch data files are now stored under profiles/ directory
-	// This makes cleanup easier - just remove the profiles/ directory
-	currentFile := filepath.Join(profilesDir, ".current")
-	historyFile := filepath.Join(profilesDir, ".history")
-	emptyModeFile := filepath.Join(profilesDir, ".empty_mode")
+	if layout.XDG && !layout.Migrated && opts.DryRun {
+		// 只读模式下不能迁移，本次运行沿用原有布局
+		layout.DataDir, layout.CacheDir, layout.XDG = layout.ProfilesDir, layout.ProfilesDir, false
+	}
 
 	cm := &ConfigManager{
-		claudeDir:     claudeDir,
-		profilesDir:   profilesDir,
-		templatesDir:  templatesDir,
-		currentFile:   currentFile,
-		settingsFile:  settingsFile,
-		historyFile:   historyFile,
-		emptyModeFile: emptyModeFile,
+		claudeDir:         layout.ClaudeDir,
+		profilesDir:       layout.ProfilesDir,
+		systemProfilesDir: defaultSystemProfilesDir(),
+		templatesDir:      layout.TemplatesDir(opts.TemplatesDirName),
+		currentFile:       layout.DataFile(currentFileName),
+		settingsFile:      layout.SettingsFile(),
+		historyFile:       layout.DataFile(historyFileName),
+		emptyModeFile:     layout.DataFile(emptyModeFileName),
+		layout:            layout,
+		passphraseSource:  opts.Passphrase,
+		warnings:          opts.Warnings,
+		fs:                osFileSystem{},
+	}
+	if opts.DryRun {
+		cm.fs = newDryRunFileSystem(opts.Warnings)
+	}
+
+	// 已有配置目录时立即迁移到 XDG 目录；全新安装在 Initialize 中创建目录后再写入指针文件
+	if _, err := os.Stat(cm.profilesDir); err == nil {
+		if err := cm.migrateToXDG(); err != nil {
+			return nil, err
+		}
 	}
+	cm.applyGlobalConfig()
 
 	return cm, nil
 }
 
+// defaultSystemProfilesDir 返回只读系统配置目录（可通过环境变量覆盖，设置为空字符串则禁用）
+func defaultSystemProfilesDir() string {
+	if dir, ok := os.LookupEnv(SystemProfilesDirEnv); ok {
+		return dir
+	}
+
+	if runtime.GOOS == "windows" {
+		if programData := os.Getenv("ProgramData"); programData != "" {
+			return filepath.Join(programData, "cc-switch", "profiles")
+		}
+		return ""
+	}
+
+	return "/etc/cc-switch/profiles"
+}
+
+// GetProfilesDir 获取配置目录
+func (cm *ConfigManager) GetProfilesDir() string {
+	return cm.profilesDir
+}
+
+// GetDataDir 获取自身状态文件所在目录（默认即配置目录，XDG 布局下为 $XDG_DATA_HOME/cc-switch）
+func (cm *ConfigManager) GetDataDir() string {
+	return cm.layout.DataDir
+}
+
+// Warnings 获取警告和提示信息的输出位置
+func (cm *ConfigManager) Warnings() io.Writer {
+	return cm.warnings
+}
+
+// Layout 获取文件布局
+func (cm *ConfigManager) Layout() common.Layout {
+	return cm.layout
+}
+
+// dataFile 返回状态文件路径
+func (cm *ConfigManager) dataFile(name string) string {
+	return cm.layout.DataFile(name)
+}
+
+// GetTemplatesDir 获取模板目录
+func (cm *ConfigManager) GetTemplatesDir() string {
+	return cm.templatesDir
+}
+
+// GetSettingsFile 获取 settings.json 路径
+func (cm *ConfigManager) GetSettingsFile() string {
+	return cm.settingsFile
+}
+
+// ProfilePath 获取配置文件的绝对路径（用户配置优先，其次为系统配置）
+func (cm *ConfigManager) ProfilePath(name string) (string, error) {
+	path, _ := cm.resolveProfilePath(name)
+	if _, err := os.Stat(path); err != nil {
+		return "", NotFoundf("profile '%s' does not exist", name)
+	}
+	return path, nil
+}
+
+// TemplatePath 获取模板文件的绝对路径
+func (cm *ConfigManager) TemplatePath(name string) (string, error) {
+	path := filepath.Join(cm.templatesDir, name+".json")
+	if _, err := os.Stat(path); err != nil {
+		return "", NotFoundf("template '%s' does not exist", name)
+	}
+	return path, nil
+}
+
+// GetSystemProfilesDir 获取只读系统配置目录
+func (cm *ConfigManager) GetSystemProfilesDir() string {
+	return cm.systemProfilesDir
+}
+
+// resolveProfilePath 解析配置文件路径：用户配置优先，其次为系统配置
+// 返回值 system 表示路径位于只读系统目录
+func (cm *ConfigManager) resolveProfilePath(name string) (path string, system bool) {
+	userPath := filepath.Join(cm.profilesDir, name+".json")
+	if _, err := os.Stat(userPath); err == nil {
+		return userPath, false
+	}
+
+	if cm.systemProfilesDir != "" {
+		systemPath := filepath.Join(cm.systemProfilesDir, name+".json")
+		if _, err := os.Stat(systemPath); err == nil {
+			return systemPath, true
+		}
+	}
+
+	return userPath, false
+}
+
+// IsSystemProfile 检查配置是否为只读系统配置（未被同名用户配置覆盖）
+func (cm *ConfigManager) IsSystemProfile(name string) bool {
+	_, system := cm.resolveProfilePath(name)
+	return system
+}
+
+// checkProfileWritable 确保配置不是只读系统配置
+func (cm *ConfigManager) checkProfileWritable(name string) error {
+	if cm.IsSystemProfile(name) {
+		return Lockedf("profile '%s' is a read-only system profile (%s)", name, cm.systemProfilesDir)
+	}
+	return nil
+}
+
 // validateProfileName 验证配置名称是否有效
 func (cm *ConfigManager) validateProfileName(name string) error {
 	if name == "" {
-		return fmt.Errorf("profile name cannot be empty")
+		return Invalidf("profile name cannot be empty")
 	}
 
 	// 检查保留名称
 	if name == "empty_mode" {
-		return fmt.Errorf("'empty_mode' is a reserved name and cannot be used for configurations")
+		return Invalidf("'empty_mode' is a reserved name and cannot be used for configurations")
+	}
+
+	// 别名解析时配置名优先，与别名同名的新配置会让别名失效
+	if target, ok := cm.aliasTarget(name); ok {
+		return Conflictf("'%s' is an alias for configuration '%s'; remove it with 'cc-switch alias rm %s' or choose another name", name, target, name)
 	}
 
 	return nil
@@ -159,8 +414,8 @@ func (cm *ConfigManager) migrateOldFiles() error {
 	// Old location: ~/.claude/<file>
 	// New location: ~/.claude/profiles/<file>
 	migrations := map[string]string{
-		filepath.Join(cm.claudeDir, ".current"):    cm.currentFile,   // -> profiles/.current
-		filepath.Join(cm.claudeDir, ".empty_mode"): cm.emptyModeFile, // -> profiles/.empty_mode
+		filepath.Join(cm.claudeDir, currentFileName):   cm.currentFile,   // -> profiles/.current
+		filepath.Join(cm.claudeDir, emptyModeFileName): cm.emptyModeFile, // -> profiles/.empty_mode
 	}
 
 	for oldPath, newPath := range migrations {
@@ -172,21 +427,21 @@ func (cm *ConfigManager) migrateOldFiles() error {
 		// Check if new file already exists (don't overwrite)
 		if _, err := os.Stat(newPath); err == nil {
 			// New file exists, remove old file to clean up
-			os.Remove(oldPath)
+			cm.fs.Remove(oldPath)
 			continue
 		}
 
 		// Migrate: move old file to new location
-		if err := os.Rename(oldPath, newPath); err != nil {
+		if err := cm.fs.Rename(oldPath, newPath); err != nil {
 			// If rename fails (e.g., cross-device), try copy + delete
 			data, readErr := os.ReadFile(oldPath)
 			if readErr != nil {
 				return fmt.Errorf("failed to read old file %s: %w", oldPath, readErr)
 			}
-			if writeErr := os.WriteFile(newPath, data, 0600); writeErr != nil {
+			if writeErr := cm.fs.WriteFile(newPath, data, 0600, false); writeErr != nil {
 				return fmt.Errorf("failed to write new file %s: %w", newPath, writeErr)
 			}
-			os.Remove(oldPath) // Clean up old file
+			cm.fs.Remove(oldPath) // Clean up old file
 		}
 	}
 
@@ -195,11 +450,15 @@ func (cm *ConfigManager) migrateOldFiles() error {
 
 // Initialize 初始化配置目录和默认配置
 func (cm *ConfigManager) Initialize() error {
-	if err := os.MkdirAll(cm.profilesDir, 0755); err != nil {
+	if err := cm.fs.MkdirAll(cm.profilesDir, 0755); err != nil {
 		return fmt.Errorf("failed to create profiles directory: %w", err)
 	}
 
-	if err := os.MkdirAll(cm.templatesDir, 0755); err != nil {
+	if err := cm.migrateToXDG(); err != nil {
+		return err
+	}
+
+	if err := cm.fs.MkdirAll(cm.templatesDir, 0755); err != nil {
 		return fmt.Errorf("failed to create templates directory: %w", err)
 	}
 
@@ -209,13 +468,18 @@ func (cm *ConfigManager) Initialize() error {
 	// After sufficient time has passed (e.g., 2-3 major versions), this code can be removed
 	if err := cm.migrateOldFiles(); err != nil {
 		// Migration errors are non-fatal, just log a warning
-		fmt.Fprintf(os.Stderr, "Warning: failed to migrate old files: %v\n", err)
+		fmt.Fprintf(cm.warnings, "Warning: failed to migrate old files: %v\n", err)
 	}
 
 	if err := cm.initializeDefaultTemplate(); err != nil {
 		return fmt.Errorf("failed to initialize default template: %w", err)
 	}
 
+	// 修复进入或退出空配置模式时被中断留下的半完成状态
+	if err := cm.recoverEmptyMode(); err != nil {
+		fmt.Fprintf(cm.warnings, "Warning: failed to recover an interrupted empty mode change: %v\n", err)
+	}
+
 	// 检查settings.json是否存在
 	if _, err := os.Stat(cm.settingsFile); err == nil {
 		// 存在settings.json，检查是否已经有default配置
@@ -227,9 +491,16 @@ func (cm *ConfigManager) Initialize() error {
 			}
 
 			// 设置权限
-			if err := os.Chmod(defaultProfilePath, 0600); err != nil {
+			if err := cm.fs.Chmod(defaultProfilePath, 0600); err != nil {
 				return fmt.Errorf("failed to set profile permissions: %w", err)
 			}
+			cm.stampProfile("default")
+			cm.setProfileOrigin("default", OriginSnapshot)
+
+			// 首次运行：额外保存一份只读快照，default 被修改后仍可找回原始配置
+			if err := cm.captureOriginalSettings(); err != nil {
+				fmt.Fprintf(cm.warnings, "Warning: failed to save a snapshot of the original settings: %v\n", err)
+			}
 		}
 
 		// 设置当前配置为default（如果.current文件不存在）
@@ -252,30 +523,114 @@ func (cm *ConfigManager) ListProfiles() ([]Profile, error) {
 
 	currentProfile, _ := cm.getCurrentProfile()
 	var profiles []Profile
+	seen := make(map[string]bool)
 
 	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+		// 以 . 开头的是 cc-switch 自身的数据文件（如 .endpoint-sets.json），不是配置
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") || strings.HasPrefix(entry.Name(), ".") {
 			continue
 		}
 
 		name := strings.TrimSuffix(entry.Name(), ".json")
-		profiles = append(profiles, Profile{
+		path := filepath.Join(cm.profilesDir, entry.Name())
+		seen[name] = true
+		profile := Profile{
 			Name:      name,
 			IsCurrent: name == currentProfile,
-			Path:      filepath.Join(cm.profilesDir, entry.Name()),
+			Path:      path,
+			Snapshot:  IsOriginalSettingsProfile(name),
+			Error:     cm.probeManagedFile(path),
+		}
+		cm.applyListMetadata(&profile)
+		profiles = append(profiles, profile)
+	}
+
+	// 叠加系统配置目录中的只读配置（同名用户配置优先）
+	if cm.systemProfilesDir != "" {
+		systemEntries, err := os.ReadDir(cm.systemProfilesDir)
+		if err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(cm.warnings, "Warning: failed to read system profiles directory: %v\n", err)
+		}
+
+		for _, entry := range systemEntries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+
+			name := strings.TrimSuffix(entry.Name(), ".json")
+			if seen[name] {
+				continue
+			}
+			path := filepath.Join(cm.systemProfilesDir, entry.Name())
+			profile := Profile{
+				Name:      name,
+				IsCurrent: name == currentProfile,
+				Path:      path,
+				ReadOnly:  true,
+				Error:     probeProfileFile(path),
+			}
+			cm.applyListMetadata(&profile)
+			profiles = append(profiles, profile)
+		}
+
+		sort.Slice(profiles, func(i, j int) bool {
+			return profiles[i].Name < profiles[j].Name
 		})
 	}
 
 	return profiles, nil
 }
 
-// CreateProfile 创建新配置（从模板）
+// probeProfileFile 检查配置文件能否打开读取，返回无法读取的原因（可读时返回空字符串）
+func probeProfileFile(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "broken symlink"
+		}
+		if os.IsPermission(err) {
+			return "permission denied"
+		}
+		return err.Error()
+	}
+	file.Close()
+	return ""
+}
+
+// probeManagedFile 在 probeProfileFile 的基础上拒绝指向目录之外的符号链接
+// 断开的链接无法读取也无从判断目标，按断开报告
+func (cm *ConfigManager) probeManagedFile(path string) string {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return "broken symlink"
+	}
+	if err := cm.checkManagedPath(path); err != nil {
+		return "symlink outside the profiles directory"
+	}
+	return probeProfileFile(path)
+}
+
+// CreateProfile 创建新配置（从 default_template 设置的模板，未设置时为 default）
 func (cm *ConfigManager) CreateProfile(name string) error {
-	return cm.CreateProfileFromTemplate(name, "default")
+	templateName, warning := cm.ResolveTemplate("")
+	if warning != "" {
+		fmt.Fprintf(cm.warnings, "Warning: %s\n", warning)
+	}
+	return cm.CreateProfileFromTemplate(name, templateName)
 }
 
-// CreateProfileFromTemplateInteractive 从模板交互式创建配置
+// CreateProfileFromTemplateInteractive 从模板交互式创建配置，只提示模板中的空字段
 func (cm *ConfigManager) CreateProfileFromTemplateInteractive(name, templateName string, uiProvider interface{}) error {
+	return cm.createProfileFromTemplateInteractive(name, templateName, uiProvider, false)
+}
+
+// CreateProfileFromTemplateInteractiveAll 从模板交互式创建配置，逐一确认所有字符串字段
+// 已有值的字段以当前值预填，直接回车保留原值
+func (cm *ConfigManager) CreateProfileFromTemplateInteractiveAll(name, templateName string, uiProvider interface{}) error {
+	return cm.createProfileFromTemplateInteractive(name, templateName, uiProvider, true)
+}
+
+// createProfileFromTemplateInteractive 交互式创建配置，allFields 为 true 时提示所有字符串字段
+func (cm *ConfigManager) createProfileFromTemplateInteractive(name, templateName string, uiProvider interface{}, allFields bool) error {
 	// 验证配置名称
 	if err := cm.validateProfileName(name); err != nil {
 		return err
@@ -284,13 +639,13 @@ func (cm *ConfigManager) CreateProfileFromTemplateInteractive(name, templateName
 	// 检查配置是否已存在
 	profilePath := filepath.Join(cm.profilesDir, name+".json")
 	if _, err := os.Stat(profilePath); err == nil {
-		return fmt.Errorf("profile '%s' already exists", name)
+		return Conflictf("profile '%s' already exists", name)
 	}
 
 	// 检查模板是否存在
 	templatePath := filepath.Join(cm.templatesDir, templateName+".json")
 	if _, err := os.Stat(templatePath); os.IsNotExist(err) {
-		return fmt.Errorf("template '%s' does not exist", templateName)
+		return NotFoundf("template '%s' does not exist", templateName)
 	}
 
 	// 读取模板内容
@@ -299,46 +654,69 @@ func (cm *ConfigManager) CreateProfileFromTemplateInteractive(name, templateName
 		return fmt.Errorf("failed to read template: %w", err)
 	}
 
-	// 检测空字段
-	emptyFields := cm.DetectEmptyFields(template)
+	// 检测需要填写的字段
+	var emptyFields []TemplateField
+	if allFields {
+		emptyFields = cm.DetectStringFields(template)
+	} else {
+		emptyFields = cm.DetectEmptyFields(template)
+	}
 	if len(emptyFields) == 0 {
-		// 没有空字段，直接使用现有方法
+		// 没有需要填写的字段，直接使用现有方法
 		return cm.CreateProfileFromTemplate(name, templateName)
 	}
 
 	// 尝试类型断言，获取 UI 提供者
-	ui, ok := uiProvider.(interface {
-		ConfirmTemplateCreation(fields []TemplateField) bool
-		GetTemplateFieldInput(field TemplateField) (string, error)
-		ShowTemplateFieldSummary(fields []TemplateField)
-	})
+	ui, ok := uiProvider.(templateFieldUI)
 	if !ok {
 		// UI 不支持交互式模板输入，回退到非交互模式
 		return cm.CreateProfileFromTemplate(name, templateName)
 	}
 
+	populatedTemplate, err := cm.promptTemplateFields(template, emptyFields, ui)
+	if err != nil {
+		return err
+	}
+
+	// 创建配置
+	if err := cm.CreateProfileWithContent(name, populatedTemplate); err != nil {
+		return err
+	}
+
+	cm.setProfileTemplate(name, templateName)
+	cm.setProfileOrigin(name, OriginTemplate(templateName))
+	return nil
+}
+
+// templateFieldUI 支持交互式填写模板字段的 UI 提供者
+type templateFieldUI interface {
+	ConfirmTemplateCreation(fields []TemplateField) bool
+	GetTemplateFieldInput(field TemplateField) (string, error)
+	ShowTemplateFieldSummary(fields []TemplateField)
+}
+
+// promptTemplateFields 显示字段摘要并确认后逐一提示输入，返回填充后的模板内容
+func (cm *ConfigManager) promptTemplateFields(template map[string]interface{}, fields []TemplateField, ui templateFieldUI) (map[string]interface{}, error) {
 	// 显示将要填充的字段摘要
-	ui.ShowTemplateFieldSummary(emptyFields)
+	ui.ShowTemplateFieldSummary(fields)
 
 	// 确认是否继续交互式创建
-	if !ui.ConfirmTemplateCreation(emptyFields) {
-		return fmt.Errorf("template creation cancelled by user")
+	if !ui.ConfirmTemplateCreation(fields) {
+		return nil, fmt.Errorf("template creation cancelled by user")
 	}
 
 	// 收集用户输入
 	inputs := make(map[string]string)
-	for _, field := range emptyFields {
+	for _, field := range fields {
 		value, err := ui.GetTemplateFieldInput(field)
 		if err != nil {
-			return fmt.Errorf("failed to get input for field '%s': %w", field.Name, err)
+			return nil, fmt.Errorf("failed to get input for field '%s': %w", field.Name, err)
 		}
 
 		inputs[field.Path] = value
 	}
 
-	// 填充模板并创建配置
-	populatedTemplate := cm.PopulateTemplate(template, inputs)
-	return cm.CreateProfileWithContent(name, populatedTemplate)
+	return cm.PopulateTemplate(template, inputs), nil
 }
 
 // CreateProfileFromTemplate 从指定模板创建新配置
@@ -351,13 +729,13 @@ func (cm *ConfigManager) CreateProfileFromTemplate(name, templateName string) er
 	// 检查配置是否已存在
 	profilePath := filepath.Join(cm.profilesDir, name+".json")
 	if _, err := os.Stat(profilePath); err == nil {
-		return fmt.Errorf("profile '%s' already exists", name)
+		return Conflictf("profile '%s' already exists", name)
 	}
 
 	// 检查模板是否存在
 	templatePath := filepath.Join(cm.templatesDir, templateName+".json")
 	if _, err := os.Stat(templatePath); os.IsNotExist(err) {
-		return fmt.Errorf("template '%s' does not exist", templateName)
+		return NotFoundf("template '%s' does not exist", templateName)
 	}
 
 	// 从模板复制创建配置
@@ -365,6 +743,9 @@ func (cm *ConfigManager) CreateProfileFromTemplate(name, templateName string) er
 		return fmt.Errorf("failed to create profile from template: %w", err)
 	}
 
+	cm.stampProfile(name)
+	cm.setProfileTemplate(name, templateName)
+	cm.setProfileOrigin(name, OriginTemplate(templateName))
 	return nil
 }
 
@@ -378,7 +759,7 @@ func (cm *ConfigManager) CreateProfileWithContent(name string, content map[strin
 	// 检查配置是否已存在
 	profilePath := filepath.Join(cm.profilesDir, name+".json")
 	if _, err := os.Stat(profilePath); err == nil {
-		return fmt.Errorf("profile '%s' already exists", name)
+		return Conflictf("profile '%s' already exists", name)
 	}
 
 	// 将内容写入文件
@@ -387,60 +768,195 @@ func (cm *ConfigManager) CreateProfileWithContent(name string, content map[strin
 		return fmt.Errorf("failed to marshal config content: %w", err)
 	}
 
-	// 原子性写入：使用临时文件
-	tempFile := profilePath + ".tmp"
-	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+	// 原子性写入
+	if err := cm.writeFile(profilePath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
-	// 原子性重命名
-	if err := os.Rename(tempFile, profilePath); err != nil {
-		os.Remove(tempFile) // 清理临时文件
-		return fmt.Errorf("failed to finalize config file: %w", err)
+	cm.stampProfile(name)
+	cm.setProfileOrigin(name, OriginManual)
+	return nil
+}
+
+// IsSecretKey 判断字段是否保存凭据（见 FieldRule.Secret）
+func IsSecretKey(key string) bool {
+	rule, _ := MatchFieldRule(key)
+	return rule.Secret
+}
+
+// ProfileTemplate 获取配置记录的来源模板
+func (cm *ConfigManager) ProfileTemplate(name string) (string, error) {
+	meta, err := cm.GetProfileMetadata(name)
+	if err != nil {
+		return "", err
+	}
+	if meta.Template == "" {
+		return "", fmt.Errorf("profile '%s' has no recorded template", name)
 	}
+	return meta.Template, nil
+}
 
+// ResetProfileToTemplate 将配置内容重置为模板内容，保留配置名称
+// templateName 为空时使用记录的来源模板；keepSecrets 为 true 时保留已有的非空凭据值
+func (cm *ConfigManager) ResetProfileToTemplate(name, templateName string, keepSecrets bool) error {
+	if err := cm.CheckProfileModifiable(name); err != nil {
+		return err
+	}
+
+	current, _, err := cm.GetProfileContent(name)
+	if err != nil {
+		return err
+	}
+
+	if templateName == "" {
+		templateName, err = cm.ProfileTemplate(name)
+		if err != nil {
+			return err
+		}
+	}
+
+	content, err := cm.GetTemplateContent(templateName)
+	if err != nil {
+		return err
+	}
+
+	if keepSecrets {
+		carryOverSecrets(current, content)
+	}
+
+	if err := cm.UpdateProfile(name, content); err != nil {
+		return err
+	}
+
+	cm.setProfileTemplate(name, templateName)
 	return nil
 }
 
+// carryOverSecrets 将 from 中非空的凭据值按相同路径复制到 to
+func carryOverSecrets(from, to map[string]interface{}) {
+	for key, value := range from {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			child, ok := to[key].(map[string]interface{})
+			if !ok {
+				if _, exists := to[key]; exists {
+					continue
+				}
+				child = make(map[string]interface{})
+			}
+			carryOverSecrets(v, child)
+			if len(child) > 0 {
+				to[key] = child
+			}
+		case string:
+			if v != "" && IsSecretKey(key) {
+				to[key] = v
+			}
+		}
+	}
+}
+
 // UseProfile 切换到指定配置
 func (cm *ConfigManager) UseProfile(name string) error {
-	profilePath := filepath.Join(cm.profilesDir, name+".json")
+	return cm.UseProfileWithNote(name, "")
+}
+
+// UseProfileWithNote 切换到指定配置，并在历史记录中附加备注
+func (cm *ConfigManager) UseProfileWithNote(name, note string) error {
+	return cm.UseProfileWithOptions(name, SwitchOptions{Note: note})
+}
+
+// UseProfileWithOptions 按选项切换到指定配置
+func (cm *ConfigManager) UseProfileWithOptions(name string, opts SwitchOptions) error {
+	note := strings.TrimSpace(opts.Note)
+	if len([]rune(note)) > MaxNoteLength {
+		return Invalidf("note is too long (maximum %d characters)", MaxNoteLength)
+	}
+
+	profilePath, _ := cm.resolveProfilePath(name)
 
 	// 检查配置是否存在
 	if _, err := os.Stat(profilePath); os.IsNotExist(err) {
-		return fmt.Errorf("profile '%s' does not exist", name)
+		return NotFoundf("profile '%s' does not exist", name)
 	}
 
-	// 备份当前配置到profiles中（如果有的话）
+	// 备份当前配置到profiles中（如果有的话，只读系统配置不回写，自切换后未修改的也不回写）
 	currentProfile, err := cm.getCurrentProfile()
-	if err == nil && currentProfile != "" {
-		currentProfilePath := filepath.Join(cm.profilesDir, currentProfile+".json")
-		if err := cm.copyFile(cm.settingsFile, currentProfilePath); err != nil {
-			return fmt.Errorf("failed to backup current profile: %w", err)
+	backfill := err == nil && currentProfile != "" && !cm.IsSystemProfile(currentProfile) && !cm.warnIfWrittenByNewer(currentProfile) && cm.settingsChangedSinceSwitch(currentProfile)
+	if backfill && IsOriginalSettingsProfile(currentProfile) {
+		// 快照不可修改，直接在 settings.json 中做的修改会随切换丢弃
+		fmt.Fprintf(cm.warnings, "Warning: changes made to settings.json were not saved because '%s' is a read-only snapshot\n", currentProfile)
+		backfill = false
+	}
+
+	// 写入任何文件前先确认空间足够，避免磁盘写满时半途失败
+	if err := cm.checkSwitchSpace(profilePath, backfill); err != nil {
+		return &SwitchStepError{Profile: name, Step: StepCheckSpace, Path: filepath.Dir(cm.settingsFile), Err: err}
+	}
+
+	if backfill {
+		if err := cm.backfillProfileFromSettings(currentProfile); err != nil {
+			return &SwitchStepError{Profile: name, Step: StepBackfill, Path: filepath.Join(cm.profilesDir, currentProfile+".json"), Err: err}
 		}
 	}
 
-	// 原子性操作：使用临时文件
-	tempFile := cm.settingsFile + ".tmp"
-	if err := cm.copyFile(profilePath, tempFile); err != nil {
-		return fmt.Errorf("failed to prepare new settings: %w", err)
+	// 记录切换前的 settings.json 与当前配置标记，后续步骤失败时回滚
+	settingsBefore, err := snapshotFile(cm.settingsFile)
+	if err != nil {
+		return &SwitchStepError{Profile: name, Step: StepWriteSettings, Path: cm.settingsFile, Err: err}
+	}
+	currentBefore, err := snapshotFile(cm.currentFile)
+	if err != nil {
+		return &SwitchStepError{Profile: name, Step: StepCurrentMarker, Path: cm.currentFile, Err: err}
+	}
+	rollback := func(stepErr *SwitchStepError) error {
+		defer cm.invalidateCurrentProfile()
+		if err := settingsBefore.restore(cm.fs); err != nil {
+			stepErr.RollbackErr = err
+			return stepErr
+		}
+		if err := currentBefore.restore(cm.fs); err != nil {
+			stepErr.RollbackErr = err
+			return stepErr
+		}
+		stepErr.RolledBack = true
+		return stepErr
 	}
 
-	// 原子性重命名
-	if err := os.Rename(tempFile, cm.settingsFile); err != nil {
-		os.Remove(tempFile) // 清理临时文件
-		return fmt.Errorf("failed to switch profile: %w", err)
+	// 原子性写入新配置（密钥引用在此解析，配置文件中保留引用）；失败时 settings.json 保持原样
+	if err := cm.writeSettingsFromProfile(profilePath); err != nil {
+		return &SwitchStepError{Profile: name, Step: StepWriteSettings, Path: cm.settingsFile, Err: err}
+	}
+
+	// 确保 settings.json 权限为 0600（重命名不一定会重置已有文件的权限）
+	cm.ensureSettingsPermissions()
+
+	// 校验写入的 settings.json，失败时恢复切换前的内容，不记录历史
+	if (opts.Verify || VerifySwitch()) && !cm.ReadOnly() {
+		if err := cm.verifySettings(); err != nil {
+			return rollback(&SwitchStepError{Profile: name, Step: StepVerify, Path: cm.settingsFile, Err: err})
+		}
 	}
 
 	// 更新当前配置标记
 	if err := cm.setCurrentProfile(name); err != nil {
-		return fmt.Errorf("failed to update current profile marker: %w", err)
+		return rollback(&SwitchStepError{Profile: name, Step: StepCurrentMarker, Path: cm.currentFile, Err: err})
+	}
+
+	// 更新历史记录；写入失败说明磁盘不可写，回滚以免 settings.json 与记录不一致
+	if err := cm.updateHistory(name, note); err != nil {
+		return rollback(&SwitchStepError{Profile: name, Step: StepRecordHistory, Path: cm.historyFile, Err: err})
 	}
 
-	// 更新历史记录
-	if err := cm.updateHistory(name); err != nil {
-		// 历史记录更新失败不应该阻止配置切换，只记录错误
-		fmt.Fprintf(os.Stderr, "Warning: failed to update history: %v\n", err)
+	entry := ActivityEntry{Action: ActivitySwitch, Profile: name, Note: note}
+	if currentProfile != "" && currentProfile != name {
+		entry.Detail = "from " + currentProfile
+	}
+	cm.LogActivity(entry)
+
+	// 离开临时配置后将其删除
+	if currentProfile != "" && currentProfile != name {
+		cm.cleanupScratchProfile(currentProfile)
 	}
 
 	return nil
@@ -449,7 +965,7 @@ func (cm *ConfigManager) UseProfile(name string) error {
 // DeleteProfile 删除配置
 func (cm *ConfigManager) DeleteProfile(name string) error {
 	if name == "" {
-		return fmt.Errorf("profile name cannot be empty")
+		return Invalidf("profile name cannot be empty")
 	}
 
 	// 检查是否为当前配置
@@ -458,21 +974,54 @@ func (cm *ConfigManager) DeleteProfile(name string) error {
 		return fmt.Errorf("cannot delete current profile '%s'. Switch to another profile first", name)
 	}
 
+	if err := cm.checkProfileWritable(name); err != nil {
+		return err
+	}
+
 	profilePath := filepath.Join(cm.profilesDir, name+".json")
 
 	// 检查配置是否存在
 	if _, err := os.Stat(profilePath); os.IsNotExist(err) {
-		return fmt.Errorf("profile '%s' does not exist", name)
+		return NotFoundf("profile '%s' does not exist", name)
 	}
 
 	// 删除配置文件
-	if err := os.Remove(profilePath); err != nil {
+	if err := cm.fs.Remove(profilePath); err != nil {
 		return fmt.Errorf("failed to delete profile: %w", err)
 	}
 
+	cm.removeProfileMetadata(name)
 	return nil
 }
 
+// DeleteCurrentProfile 删除当前配置并进入空配置模式
+func (cm *ConfigManager) DeleteCurrentProfile() error {
+	name, err := cm.getCurrentProfile()
+	if err != nil || name == "" {
+		return fmt.Errorf("no current profile to delete")
+	}
+
+	if err := cm.checkProfileWritable(name); err != nil {
+		return err
+	}
+
+	if err := cm.EnableEmptyMode(); err != nil {
+		return err
+	}
+
+	// 配置即将被删除：清除当前配置标记，避免退出空配置模式时恢复到不存在的配置
+	cm.fs.Remove(cm.currentFile)
+	cm.invalidateCurrentProfile()
+	if info, err := cm.GetEmptyModeInfo(); err == nil {
+		info.PreviousProfile = ""
+		if err := cm.saveEmptyModeInfo(info); err != nil {
+			fmt.Fprintf(cm.warnings, "Warning: %v\n", err)
+		}
+	}
+
+	return cm.DeleteProfile(name)
+}
+
 // GetCurrentProfile 获取当前配置名
 func (cm *ConfigManager) GetCurrentProfile() (string, error) {
 	return cm.getCurrentProfile()
@@ -523,21 +1072,80 @@ func (cm *ConfigManager) GetCurrentConfigurationForOperation() (string, error) {
 // 私有方法
 
 // getCurrentProfile 读取当前配置名
+// 一次命令中会多次调用，.current 未变化时直接返回缓存，只需 stat 而无需重新读取
 func (cm *ConfigManager) getCurrentProfile() (string, error) {
+	info, err := os.Stat(cm.currentFile)
+	if err != nil {
+		cm.invalidateCurrentProfile()
+		return "", err
+	}
+
+	cm.currentMu.Lock()
+	defer cm.currentMu.Unlock()
+	if cache := cm.currentCache; cache != nil && cache.modTime.Equal(info.ModTime()) && cache.size == info.Size() {
+		return cache.name, nil
+	}
+
 	data, err := os.ReadFile(cm.currentFile)
 	if err != nil {
+		cm.currentCache = nil
 		return "", err
 	}
-	return strings.TrimSpace(string(data)), nil
+	name := strings.TrimSpace(string(data))
+	cm.currentCache = &currentProfileCache{name: name, modTime: info.ModTime(), size: info.Size()}
+	return name, nil
 }
 
 // setCurrentProfile 设置当前配置名
 func (cm *ConfigManager) setCurrentProfile(name string) error {
-	return os.WriteFile(cm.currentFile, []byte(name), 0644)
+	defer cm.invalidateCurrentProfile()
+	return cm.writeFile(cm.currentFile, []byte(name), 0644)
+}
+
+// invalidateCurrentProfile 丢弃缓存的当前配置名；本进程改写或删除 .current 后调用，
+// 避免同一时间粒度内写入相同大小的内容时修改时间和大小都未变化
+func (cm *ConfigManager) invalidateCurrentProfile() {
+	cm.currentMu.Lock()
+	cm.currentCache = nil
+	cm.currentMu.Unlock()
+}
+
+// ensureSettingsPermissions 将 settings.json 权限收紧为 0600 并校验结果
+// 失败或权限仍然过宽时只给出警告，不影响切换
+func (cm *ConfigManager) ensureSettingsPermissions() {
+	// Windows 不使用 Unix 权限位
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	if err := cm.fs.Chmod(cm.settingsFile, 0600); err != nil {
+		fmt.Fprintf(cm.warnings, "Warning: failed to restrict permissions on %s: %v\n", cm.settingsFile, err)
+		return
+	}
+	// 只读模式下权限并未实际修改，校验没有意义
+	if cm.ReadOnly() {
+		return
+	}
+
+	info, err := os.Stat(cm.settingsFile)
+	if err != nil {
+		fmt.Fprintf(cm.warnings, "Warning: failed to verify permissions on %s: %v\n", cm.settingsFile, err)
+		return
+	}
+
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		fmt.Fprintf(cm.warnings, "Warning: %s is accessible by other users (mode %04o); it may contain API credentials\n", cm.settingsFile, perm)
+	}
 }
 
 // copyFile 复制文件
 func (cm *ConfigManager) copyFile(src, dst string) error {
+	for _, path := range []string{src, dst} {
+		if err := cm.checkManagedPath(path); err != nil {
+			return err
+		}
+	}
+
 	data, err := os.ReadFile(src)
 	if err != nil {
 		return err
@@ -546,29 +1154,32 @@ func (cm *ConfigManager) copyFile(src, dst string) error {
 	// 验证JSON格式
 	var temp interface{}
 	if err := json.Unmarshal(data, &temp); err != nil {
-		return fmt.Errorf("invalid JSON format in source file: %w", err)
+		return Invalidf("invalid JSON format in source file: %w", err)
 	}
 
-	return os.WriteFile(dst, data, 0600)
+	return cm.writeFile(dst, data, 0600)
 }
 
 // ProfileExists 检查配置是否存在
 func (cm *ConfigManager) ProfileExists(name string) bool {
-	profilePath := filepath.Join(cm.profilesDir, name+".json")
+	profilePath, _ := cm.resolveProfilePath(name)
 	_, err := os.Stat(profilePath)
 	return err == nil
 }
 
 // GetProfileContent 获取配置内容和元数据
 func (cm *ConfigManager) GetProfileContent(name string) (map[string]interface{}, Profile, error) {
-	profilePath := filepath.Join(cm.profilesDir, name+".json")
+	profilePath, system := cm.resolveProfilePath(name)
 
 	// 检查配置是否存在
 	if _, err := os.Stat(profilePath); os.IsNotExist(err) {
-		return nil, Profile{}, fmt.Errorf("profile '%s' does not exist", name)
+		return nil, Profile{}, NotFoundf("profile '%s' does not exist", name)
 	}
 
 	// 读取配置文件
+	if err := cm.checkManagedPath(profilePath); err != nil {
+		return nil, Profile{}, err
+	}
 	data, err := os.ReadFile(profilePath)
 	if err != nil {
 		return nil, Profile{}, fmt.Errorf("failed to read profile file: %w", err)
@@ -583,9 +1194,12 @@ func (cm *ConfigManager) GetProfileContent(name string) (map[string]interface{},
 	// 创建元数据
 	currentProfile, _ := cm.getCurrentProfile()
 	metadata := Profile{
-		Name:      name,
-		IsCurrent: name == currentProfile,
-		Path:      profilePath,
+		Name:        name,
+		IsCurrent:   name == currentProfile,
+		Path:        profilePath,
+		ReadOnly:    system,
+		Snapshot:    !system && IsOriginalSettingsProfile(name),
+		DisplayName: cm.profileDisplayName(name),
 	}
 
 	return content, metadata, nil
@@ -597,14 +1211,23 @@ func (cm *ConfigManager) UpdateProfile(name string, content map[string]interface
 
 	// 检查配置是否存在
 	if _, err := os.Stat(profilePath); os.IsNotExist(err) {
-		return fmt.Errorf("profile '%s' does not exist", name)
+		if err := cm.checkProfileWritable(name); err != nil {
+			return err
+		}
+		return NotFoundf("profile '%s' does not exist", name)
+	}
+	if err := cm.CheckProfileModifiable(name); err != nil {
+		return err
 	}
 
 	// 验证JSON内容
 	if err := cm.validateProfileContent(content); err != nil {
-		return fmt.Errorf("invalid profile content: %w", err)
+		return Invalidf("invalid profile content: %w", err)
 	}
 
+	// 显式更新由用户发起，允许覆盖更新版本写入的配置，但给出提示
+	cm.warnIfWrittenByNewer(name)
+
 	// 创建备份
 	backupPath := profilePath + ".backup"
 	if err := cm.copyFile(profilePath, backupPath); err != nil {
@@ -618,28 +1241,23 @@ func (cm *ConfigManager) UpdateProfile(name string, content map[string]interface
 	}
 
 	// 原子性写入
-	tempFile := profilePath + ".tmp"
-	if err := os.WriteFile(tempFile, jsonData, 0600); err != nil {
-		return fmt.Errorf("failed to write to temporary file: %w", err)
-	}
-
-	// 原子性重命名
-	if err := os.Rename(tempFile, profilePath); err != nil {
-		os.Remove(tempFile) // 清理临时文件
+	if err := cm.writeFile(profilePath, jsonData, 0600); err != nil {
 		return fmt.Errorf("failed to update profile: %w", err)
 	}
 
 	// 如果是当前配置，同时更新settings.json
 	currentProfile, _ := cm.getCurrentProfile()
 	if name == currentProfile {
-		if err := os.WriteFile(cm.settingsFile, jsonData, 0600); err != nil {
+		if err := cm.writeSettingsFromProfile(profilePath); err != nil {
 			return fmt.Errorf("failed to sync current settings: %w", err)
 		}
+		cm.ensureSettingsPermissions()
 	}
 
 	// 清理备份文件（更新成功后）
-	os.Remove(backupPath)
+	cm.fs.Remove(backupPath)
 
+	cm.stampProfile(name)
 	return nil
 }
 
@@ -649,12 +1267,17 @@ func (cm *ConfigManager) validateProfileContent(content map[string]interface{})
 
 	// 检查必要字段（可根据需要扩展）
 	if content == nil {
-		return fmt.Errorf("content cannot be nil")
+		return Invalidf("content cannot be nil")
 	}
 
 	// 验证是否可以序列化
 	if _, err := json.Marshal(content); err != nil {
-		return fmt.Errorf("content cannot be serialized to JSON: %w", err)
+		return Invalidf("content cannot be serialized to JSON: %w", err)
+	}
+
+	// _net 会被转换后写入 settings.json，格式错误时拒绝保存
+	if _, err := ProfileNet(content); err != nil {
+		return err
 	}
 
 	return nil
@@ -668,7 +1291,7 @@ func (cm *ConfigManager) RenameProfile(oldName, newName string) error {
 	}
 
 	if oldName == "" {
-		return fmt.Errorf("old profile name cannot be empty")
+		return Invalidf("old profile name cannot be empty")
 	}
 
 	if oldName == newName {
@@ -678,31 +1301,57 @@ func (cm *ConfigManager) RenameProfile(oldName, newName string) error {
 	oldPath := filepath.Join(cm.profilesDir, oldName+".json")
 	newPath := filepath.Join(cm.profilesDir, newName+".json")
 
+	if err := cm.CheckProfileModifiable(oldName); err != nil {
+		return err
+	}
+
 	// 检查源配置是否存在
 	if _, err := os.Stat(oldPath); os.IsNotExist(err) {
-		return fmt.Errorf("profile '%s' does not exist", oldName)
+		return NotFoundf("profile '%s' does not exist", oldName)
 	}
 
 	// 检查目标名称是否已存在
 	if _, err := os.Stat(newPath); err == nil {
-		return fmt.Errorf("profile '%s' already exists", newName)
+		return Conflictf("profile '%s' already exists", newName)
 	}
 
 	// 执行重命名
-	if err := os.Rename(oldPath, newPath); err != nil {
+	if err := cm.fs.Rename(oldPath, newPath); err != nil {
 		return fmt.Errorf("failed to rename profile: %w", err)
 	}
+	cm.renameProfileMetadata(oldName, newName)
 
-	// 如果重命名的是当前配置，更新当前配置指向
+	// 如果重命名的是当前配置，更新当前配置指向并重新同步 settings.json
 	currentProfile, _ := cm.getCurrentProfile()
 	if oldName == currentProfile {
 		if err := cm.setCurrentProfile(newName); err != nil {
 			// 如果更新当前配置失败，尝试回滚重命名操作
-			os.Rename(newPath, oldPath)
+			cm.fs.Rename(newPath, oldPath)
+			cm.renameProfileMetadata(newName, oldName)
 			return fmt.Errorf("failed to update current profile marker: %w", err)
 		}
+
+		// 空配置模式下 settings.json 已被移除，不应重新写入
+		if !cm.IsEmptyMode() {
+			if err := cm.syncSettingsFromProfile(newName); err != nil {
+				return fmt.Errorf("profile renamed but failed to resync settings: %w", err)
+			}
+		}
+	}
+
+	cm.retargetCopyOrigins(oldName, newName)
+	return nil
+}
+
+// syncSettingsFromProfile 将配置内容原子性写入 settings.json
+func (cm *ConfigManager) syncSettingsFromProfile(name string) error {
+	profilePath := filepath.Join(cm.profilesDir, name+".json")
+
+	if err := cm.writeSettingsFromProfile(profilePath); err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
 	}
 
+	cm.ensureSettingsPermissions()
 	return nil
 }
 
@@ -714,24 +1363,24 @@ func (cm *ConfigManager) CopyProfile(sourceName, destName string) error {
 	}
 
 	if sourceName == "" {
-		return fmt.Errorf("source profile name cannot be empty")
+		return Invalidf("source profile name cannot be empty")
 	}
 
 	if sourceName == destName {
 		return fmt.Errorf("source and destination names cannot be the same")
 	}
 
-	sourcePath := filepath.Join(cm.profilesDir, sourceName+".json")
+	sourcePath, _ := cm.resolveProfilePath(sourceName)
 	destPath := filepath.Join(cm.profilesDir, destName+".json")
 
 	// 检查源配置是否存在
 	if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
-		return fmt.Errorf("profile '%s' does not exist", sourceName)
+		return NotFoundf("profile '%s' does not exist", sourceName)
 	}
 
 	// 检查目标名称是否已存在
 	if _, err := os.Stat(destPath); err == nil {
-		return fmt.Errorf("profile '%s' already exists", destName)
+		return Conflictf("profile '%s' already exists", destName)
 	}
 
 	// 执行复制
@@ -739,44 +1388,98 @@ func (cm *ConfigManager) CopyProfile(sourceName, destName string) error {
 		return fmt.Errorf("failed to copy profile: %w", err)
 	}
 
+	cm.stampProfile(destName)
+	cm.setProfileOrigin(destName, OriginCopyOf(sourceName))
 	return nil
 }
 
 // SetCurrentProfile 公开设置当前配置的方法
 func (cm *ConfigManager) SetCurrentProfile(name string) error {
 	// 检查配置是否存在
-	profilePath := filepath.Join(cm.profilesDir, name+".json")
-	if _, err := os.Stat(profilePath); os.IsNotExist(err) {
-		return fmt.Errorf("profile '%s' does not exist", name)
+	if !cm.ProfileExists(name) {
+		return NotFoundf("profile '%s' does not exist", name)
 	}
 
 	return cm.setCurrentProfile(name)
 }
 
-// GetPreviousProfile 获取上一个配置名称
+// ResolvePrevious 返回 use -p 应切换到的目标，没有可用目标时返回空字符串
+// 只依据历史记录计算，不访问文件系统。按切换顺序整理状态序列：
+//   - 连续重复的记录只算一次
+//   - X → empty_mode → X（进入空配置模式后又恢复）视为没有发生
+//
+// 整理后紧接在当前状态之前的是空配置模式时返回 "empty_mode"，
+// 否则返回最近一个与当前配置不同的真实配置。
+// 没有切换记录（旧版本写入的历史文件）时回退到 Previous 字段
+func ResolvePrevious(history *ConfigHistory) string {
+	if len(history.Entries) == 0 {
+		if history.Previous == history.Current {
+			return ""
+		}
+		return history.Previous
+	}
+
+	// Entries 最新的在前，按时间顺序处理
+	states := make([]string, 0, len(history.Entries)+1)
+	push := func(state string) {
+		n := len(states)
+		switch {
+		case n > 0 && states[n-1] == state:
+			// 连续重复
+		case n > 1 && states[n-1] == "empty_mode" && states[n-2] == state:
+			// 空配置模式往返
+			states = states[:n-1]
+		default:
+			states = append(states, state)
+		}
+	}
+	for i := len(history.Entries) - 1; i >= 0; i-- {
+		push(history.Entries[i].Profile)
+	}
+	if history.Current != "" {
+		push(history.Current)
+	}
+
+	if len(states) < 2 {
+		return ""
+	}
+	current := states[len(states)-1]
+	if states[len(states)-2] == "empty_mode" {
+		return "empty_mode"
+	}
+	for i := len(states) - 2; i >= 0; i-- {
+		if states[i] != "empty_mode" && states[i] != current {
+			return states[i]
+		}
+	}
+	return ""
+}
+
+// GetPreviousProfile 获取 use -p 应切换到的配置名称（见 ResolvePrevious）
 func (cm *ConfigManager) GetPreviousProfile() (string, error) {
 	history, err := cm.loadHistory()
 	if err != nil {
 		return "", fmt.Errorf("failed to load history: %w", err)
 	}
 
-	if history.Previous == "" {
-		return "", fmt.Errorf("no previous configuration available")
+	previous := ResolvePrevious(history)
+	if previous == "" {
+		return "", ErrNoPrevious
 	}
 
 	// Special case: "empty_mode" is a virtual state, not a real profile file
-	if history.Previous == "empty_mode" {
-		return history.Previous, nil
+	if previous == "empty_mode" {
+		return previous, nil
 	}
 
 	// 检查上一个配置是否仍然存在
-	if !cm.ProfileExists(history.Previous) {
+	if !cm.ProfileExists(previous) {
 		// 清理无效的历史记录
 		cm.cleanupHistory()
-		return "", fmt.Errorf("previous configuration '%s' no longer exists", history.Previous)
+		return "", NotFoundf("previous configuration '%s' no longer exists", previous)
 	}
 
-	return history.Previous, nil
+	return previous, nil
 }
 
 // loadHistory 加载配置历史记录
@@ -816,13 +1519,7 @@ func (cm *ConfigManager) saveHistory(history *ConfigHistory) error {
 	}
 
 	// 原子性写入
-	tempFile := cm.historyFile + ".tmp"
-	if err := os.WriteFile(tempFile, jsonData, 0600); err != nil {
-		return fmt.Errorf("failed to write temporary history file: %w", err)
-	}
-
-	if err := os.Rename(tempFile, cm.historyFile); err != nil {
-		os.Remove(tempFile) // 清理临时文件
+	if err := cm.writeFile(cm.historyFile, jsonData, 0600); err != nil {
 		return fmt.Errorf("failed to save history file: %w", err)
 	}
 
@@ -830,25 +1527,112 @@ func (cm *ConfigManager) saveHistory(history *ConfigHistory) error {
 }
 
 // updateHistory 更新配置历史记录
-func (cm *ConfigManager) updateHistory(newProfile string) error {
+func (cm *ConfigManager) updateHistory(newProfile, note string) error {
 	history, err := cm.loadHistory()
 	if err != nil {
 		return fmt.Errorf("failed to load history: %w", err)
 	}
 
-	// 如果当前配置不为空且与新配置不同，将其设为previous
-	if history.Current != "" && history.Current != newProfile {
-		history.Previous = history.Current
+	// 记录本次切换
+	entry := HistoryEntry{
+		Profile:    newProfile,
+		Note:       note,
+		SwitchedAt: time.Now(),
+	}
+
+	if cm.shouldCoalesce(history, entry) {
+		// 快速连续切换：只保留起点和终点，中间记录被替换
+		if entry.Profile == history.Entries[1].Profile {
+			// 切回了起点，去掉中间记录即可，previous 恢复为起点之前的配置
+			history.Entries = history.Entries[1:]
+			history.Previous = ""
+			if len(history.Entries) > 1 {
+				history.Previous = history.Entries[1].Profile
+			}
+		} else {
+			history.Entries[0] = entry
+		}
+	} else {
+		// 如果当前配置不为空且与新配置不同，将其设为previous
+		if history.Current != "" && history.Current != newProfile {
+			history.Previous = history.Current
+
+			// 更新历史列表，保持最近5个记录
+			history.History = cm.addToHistory(history.History, history.Current, 5)
+		}
 
-		// 更新历史列表，保持最近5个记录
-		history.History = cm.addToHistory(history.History, history.Current, 5)
+		history.Entries = append([]HistoryEntry{entry}, history.Entries...)
+		if len(history.Entries) > maxHistoryEntries {
+			history.Entries = history.Entries[:maxHistoryEntries]
+		}
 	}
 
 	history.Current = newProfile
 
+	// 记录切换时的内容哈希，用于下次切换时判断是否需要回写
+	history.CurrentHash = ""
+	if newProfile != "empty_mode" {
+		if hash, err := fileHash(cm.settingsFile); err == nil {
+			history.CurrentHash = hash
+		}
+	}
+
 	return cm.saveHistory(history)
 }
 
+// shouldCoalesce 判断本次切换是否应合并掉最新的一条记录
+// 最新记录距起点记录和本次切换都在窗口内时，它只是快速切换中的中间点
+func (cm *ConfigManager) shouldCoalesce(history *ConfigHistory, entry HistoryEntry) bool {
+	window := CoalesceWindow()
+	if window == 0 || len(history.Entries) < 2 {
+		return false
+	}
+
+	latest, start := history.Entries[0], history.Entries[1]
+
+	// 带备注的切换是用户有意记录的，保留
+	if latest.Note != "" || entry.Note != "" {
+		return false
+	}
+
+	return entry.SwitchedAt.Sub(latest.SwitchedAt) < window && latest.SwitchedAt.Sub(start.SwitchedAt) < window
+}
+
+// settingsChangedSinceSwitch 判断 settings.json 自切换到该配置后是否被修改
+// 无法确定时返回 true，保证回写不会被错误跳过
+func (cm *ConfigManager) settingsChangedSinceSwitch(name string) bool {
+	history, err := cm.loadHistory()
+	if err != nil || history.Current != name || history.CurrentHash == "" {
+		return true
+	}
+
+	hash, err := fileHash(cm.settingsFile)
+	if err != nil {
+		return true
+	}
+
+	return hash != history.CurrentHash
+}
+
+// fileHash 计算文件内容的 SHA-256 哈希
+func fileHash(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// GetHistoryEntries 获取配置切换记录（最新的在前）
+func (cm *ConfigManager) GetHistoryEntries() ([]HistoryEntry, error) {
+	history, err := cm.loadHistory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load history: %w", err)
+	}
+	return history.Entries, nil
+}
+
 // addToHistory 添加配置到历史列表，保持指定数量的最新记录
 func (cm *ConfigManager) addToHistory(history []string, profile string, maxSize int) []string {
 	// 移除重复项
@@ -896,43 +1680,36 @@ func (cm *ConfigManager) cleanupHistory() error {
 
 // Template Field Detection and Processing
 
-// getFieldDescription 获取字段的用户友好描述
+// getFieldDescription 获取字段的用户友好描述（见 FieldRule.Description）
 func getFieldDescription(fieldName string) string {
-	descriptions := map[string]string{
-		"ANTHROPIC_AUTH_TOKEN": "Enter your Claude API token",
-		"ANTHROPIC_BASE_URL":   "Enter custom base URL (optional)",
-		"OPENAI_API_KEY":       "Enter your OpenAI API key",
-		"API_KEY":              "Enter your API key",
-		"BASE_URL":             "Enter base URL (optional)",
-		"TOKEN":                "Enter authentication token",
-		"SECRET":               "Enter secret key",
-		"ENDPOINT":             "Enter API endpoint URL",
-	}
-
-	if desc, exists := descriptions[fieldName]; exists {
-		return desc
+	if rule, _ := MatchFieldRule(fieldName); rule.Description != "" {
+		return rule.Description
 	}
 
 	// Generate generic description from field name
 	return fmt.Sprintf("Enter value for %s", fieldName)
 }
 
-// isFieldRequired 判断字段是否必填
+// isFieldRequired 判断字段是否必填（见 FieldRule.Required）
 func isFieldRequired(fieldName string) bool {
-	requiredFields := map[string]bool{
-		"ANTHROPIC_AUTH_TOKEN": true,
-		"OPENAI_API_KEY":       true,
-		"API_KEY":              true,
-		"TOKEN":                true,
-	}
-
-	return requiredFields[fieldName]
+	rule, _ := MatchFieldRule(fieldName)
+	return rule.Required
 }
 
 // DetectEmptyFields 检测模板中的空字符串字段
 func (cm *ConfigManager) DetectEmptyFields(content map[string]interface{}) []TemplateField {
+	return cm.detectFields(content, true)
+}
+
+// DetectStringFields 列出模板中的所有字符串字段（包括已有值的字段），Current 为当前值
+func (cm *ConfigManager) DetectStringFields(content map[string]interface{}) []TemplateField {
+	return cm.detectFields(content, false)
+}
+
+// detectFields 检测字符串字段并按路径排序，emptyOnly 为 true 时只返回空字段
+func (cm *ConfigManager) detectFields(content map[string]interface{}, emptyOnly bool) []TemplateField {
 	var fields []TemplateField
-	cm.detectEmptyFieldsRecursive(content, "", &fields)
+	cm.detectFieldsRecursive(content, "", emptyOnly, &fields)
 
 	// 按照字段路径排序，确保顺序一致
 	sort.Slice(fields, func(i, j int) bool {
@@ -942,8 +1719,8 @@ func (cm *ConfigManager) DetectEmptyFields(content map[string]interface{}) []Tem
 	return fields
 }
 
-// detectEmptyFieldsRecursive 递归检测空字段
-func (cm *ConfigManager) detectEmptyFieldsRecursive(content map[string]interface{}, pathPrefix string, fields *[]TemplateField) {
+// detectFieldsRecursive 递归检测字符串字段
+func (cm *ConfigManager) detectFieldsRecursive(content map[string]interface{}, pathPrefix string, emptyOnly bool, fields *[]TemplateField) {
 	for key, value := range content {
 		currentPath := key
 		if pathPrefix != "" {
@@ -952,20 +1729,21 @@ func (cm *ConfigManager) detectEmptyFieldsRecursive(content map[string]interface
 
 		switch v := value.(type) {
 		case string:
-			// 检测空字符串
-			if v == "" {
+			// 检测空字符串（emptyOnly 为 false 时包括已有值的字段）
+			if v == "" || !emptyOnly {
 				field := TemplateField{
 					Path:        currentPath,
 					Name:        key,
 					Description: getFieldDescription(key),
 					Required:    isFieldRequired(key),
+					Current:     v,
 				}
 				*fields = append(*fields, field)
 			}
 		case map[string]interface{}:
 			// 递归处理嵌套对象（跳过空对象）
 			if len(v) > 0 {
-				cm.detectEmptyFieldsRecursive(v, currentPath, fields)
+				cm.detectFieldsRecursive(v, currentPath, emptyOnly, fields)
 			}
 			// 跳过其他类型：arrays, numbers, booleans 等
 		}
@@ -985,6 +1763,23 @@ func (cm *ConfigManager) PopulateTemplate(content map[string]interface{}, inputs
 	return result
 }
 
+// RenderTemplate 返回模板按 inputs（字段路径 -> 值）填充后的内容，与交互式创建配置的填充过程相同，不写入任何文件
+func (cm *ConfigManager) RenderTemplate(templateName string, inputs map[string]string) (map[string]interface{}, error) {
+	if !cm.TemplateExists(templateName) {
+		return nil, NotFoundf("template '%s' does not exist", templateName)
+	}
+	template, err := cm.GetTemplateContent(templateName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+	for path := range inputs {
+		if path == "" || strings.HasPrefix(path, ".") || strings.HasSuffix(path, ".") || strings.Contains(path, "..") {
+			return nil, Invalidf("invalid field path '%s'", path)
+		}
+	}
+	return cm.PopulateTemplate(template, inputs), nil
+}
+
 // deepCopyMap 深拷贝 map
 func (cm *ConfigManager) deepCopyMap(original map[string]interface{}) map[string]interface{} {
 	copy := make(map[string]interface{})
@@ -1032,7 +1827,7 @@ func (cm *ConfigManager) setNestedValue(content map[string]interface{}, path str
 			current = nested
 		} else {
 			// 路径冲突，无法设置值
-			fmt.Fprintf(os.Stderr, "Warning: cannot set value at path '%s', path conflict at '%s'\n",
+			fmt.Fprintf(cm.warnings, "Warning: cannot set value at path '%s', path conflict at '%s'\n",
 				path, strings.Join(parts[:i+1], "."))
 			return
 		}
@@ -1071,7 +1866,7 @@ func (cm *ConfigManager) initializeDefaultTemplate() error {
 	}
 
 	// 写入默认模板文件
-	if err := os.WriteFile(defaultTemplatePath, jsonData, 0600); err != nil {
+	if err := cm.fs.WriteFile(defaultTemplatePath, jsonData, 0600, false); err != nil {
 		return fmt.Errorf("failed to create default template: %w", err)
 	}
 
@@ -1107,14 +1902,14 @@ func (cm *ConfigManager) TemplateExists(name string) bool {
 // CreateTemplate 创建新模板
 func (cm *ConfigManager) CreateTemplate(name string) error {
 	if name == "" {
-		return fmt.Errorf("template name cannot be empty")
+		return Invalidf("template name cannot be empty")
 	}
 
 	templatePath := filepath.Join(cm.templatesDir, name+".json")
 
 	// 检查模板是否已存在
 	if _, err := os.Stat(templatePath); err == nil {
-		return fmt.Errorf("template '%s' already exists", name)
+		return Conflictf("template '%s' already exists", name)
 	}
 
 	// 创建空模板内容（基于默认模板）
@@ -1136,7 +1931,7 @@ func (cm *ConfigManager) CreateTemplate(name string) error {
 	}
 
 	// 写入文件
-	if err := os.WriteFile(templatePath, jsonData, 0600); err != nil {
+	if err := cm.fs.WriteFile(templatePath, jsonData, 0600, false); err != nil {
 		return fmt.Errorf("failed to create template: %w", err)
 	}
 
@@ -1149,10 +1944,13 @@ func (cm *ConfigManager) GetTemplateContent(name string) (map[string]interface{}
 
 	// 检查模板是否存在
 	if _, err := os.Stat(templatePath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("template '%s' does not exist", name)
+		return nil, NotFoundf("template '%s' does not exist", name)
 	}
 
 	// 读取模板文件
+	if err := cm.checkManagedPath(templatePath); err != nil {
+		return nil, err
+	}
 	data, err := os.ReadFile(templatePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read template file: %w", err)
@@ -1173,12 +1971,12 @@ func (cm *ConfigManager) UpdateTemplate(name string, content map[string]interfac
 
 	// 检查模板是否存在
 	if _, err := os.Stat(templatePath); os.IsNotExist(err) {
-		return fmt.Errorf("template '%s' does not exist", name)
+		return NotFoundf("template '%s' does not exist", name)
 	}
 
 	// 验证JSON内容
 	if err := cm.validateTemplateContent(content); err != nil {
-		return fmt.Errorf("invalid template content: %w", err)
+		return Invalidf("invalid template content: %w", err)
 	}
 
 	// 创建备份
@@ -1194,27 +1992,30 @@ func (cm *ConfigManager) UpdateTemplate(name string, content map[string]interfac
 	}
 
 	// 原子性写入
-	tempFile := templatePath + ".tmp"
-	if err := os.WriteFile(tempFile, jsonData, 0600); err != nil {
-		return fmt.Errorf("failed to write to temporary file: %w", err)
-	}
-
-	// 原子性重命名
-	if err := os.Rename(tempFile, templatePath); err != nil {
-		os.Remove(tempFile) // 清理临时文件
+	if err := cm.writeFile(templatePath, jsonData, 0600); err != nil {
 		return fmt.Errorf("failed to update template: %w", err)
 	}
 
 	// 清理备份文件（更新成功后）
-	os.Remove(backupPath)
+	cm.fs.Remove(backupPath)
 
 	return nil
 }
 
-// DeleteTemplate 删除模板
+// DeleteTemplate 删除模板（仍被配置引用时返回 TemplateInUseError）
 func (cm *ConfigManager) DeleteTemplate(name string) error {
+	return cm.deleteTemplate(name, false)
+}
+
+// DeleteTemplateDetached 删除模板，并解除所有配置对该模板的引用
+func (cm *ConfigManager) DeleteTemplateDetached(name string) error {
+	return cm.deleteTemplate(name, true)
+}
+
+// deleteTemplate 删除模板
+func (cm *ConfigManager) deleteTemplate(name string, detach bool) error {
 	if name == "" {
-		return fmt.Errorf("template name cannot be empty")
+		return Invalidf("template name cannot be empty")
 	}
 
 	if name == "default" {
@@ -1225,14 +2026,36 @@ func (cm *ConfigManager) DeleteTemplate(name string) error {
 
 	// 检查模板是否存在
 	if _, err := os.Stat(templatePath); os.IsNotExist(err) {
-		return fmt.Errorf("template '%s' does not exist", name)
+		return NotFoundf("template '%s' does not exist", name)
+	}
+
+	// 检查引用该模板的配置
+	dependents, err := cm.TemplateInUse(name)
+	if err != nil {
+		return fmt.Errorf("failed to check template usage: %w", err)
+	}
+	if len(dependents) > 0 && !detach {
+		return &TemplateInUseError{Template: name, Dependents: dependents}
 	}
 
 	// 删除模板文件
-	if err := os.Remove(templatePath); err != nil {
+	if err := cm.fs.Remove(templatePath); err != nil {
 		return fmt.Errorf("failed to delete template: %w", err)
 	}
 
+	if len(dependents) > 0 {
+		if err := cm.retargetTemplateReferences(name, ""); err != nil {
+			fmt.Fprintf(cm.warnings, "Warning: failed to detach configurations from template: %v\n", err)
+		}
+	}
+
+	// 删除的是 new 默认使用的模板时清除该设置
+	if cm.DefaultTemplateName() == name {
+		if err := cm.SetDefaultTemplate(""); err != nil {
+			fmt.Fprintf(cm.warnings, "Warning: failed to clear default_template setting: %v\n", err)
+		}
+	}
+
 	return nil
 }
 
@@ -1240,12 +2063,12 @@ func (cm *ConfigManager) DeleteTemplate(name string) error {
 func (cm *ConfigManager) CopyTemplate(sourceName, destName string) error {
 	// 验证源模板存在
 	if !cm.TemplateExists(sourceName) {
-		return fmt.Errorf("source template '%s' does not exist", sourceName)
+		return NotFoundf("source template '%s' does not exist", sourceName)
 	}
 
 	// 验证目标模板不存在
 	if cm.TemplateExists(destName) {
-		return fmt.Errorf("destination template '%s' already exists", destName)
+		return Conflictf("destination template '%s' already exists", destName)
 	}
 
 	// 获取源模板内容
@@ -1267,12 +2090,12 @@ func (cm *ConfigManager) CopyTemplate(sourceName, destName string) error {
 func (cm *ConfigManager) MoveTemplate(oldName, newName string) error {
 	// 验证源模板存在
 	if !cm.TemplateExists(oldName) {
-		return fmt.Errorf("template '%s' does not exist", oldName)
+		return NotFoundf("template '%s' does not exist", oldName)
 	}
 
 	// 验证目标模板不存在
 	if cm.TemplateExists(newName) {
-		return fmt.Errorf("template '%s' already exists", newName)
+		return Conflictf("template '%s' already exists", newName)
 	}
 
 	// 防止删除默认模板
@@ -1284,10 +2107,21 @@ func (cm *ConfigManager) MoveTemplate(oldName, newName string) error {
 	oldPath := filepath.Join(cm.templatesDir, oldName+".json")
 	newPath := filepath.Join(cm.templatesDir, newName+".json")
 
-	if err := os.Rename(oldPath, newPath); err != nil {
+	if err := cm.fs.Rename(oldPath, newPath); err != nil {
 		return fmt.Errorf("failed to move template: %w", err)
 	}
 
+	// 更新引用该模板的配置
+	if err := cm.retargetTemplateReferences(oldName, newName); err != nil {
+		fmt.Fprintf(cm.warnings, "Warning: failed to update template references: %v\n", err)
+	}
+
+	if cm.DefaultTemplateName() == oldName {
+		if err := cm.SetDefaultTemplate(newName); err != nil {
+			fmt.Fprintf(cm.warnings, "Warning: failed to update default_template setting: %v\n", err)
+		}
+	}
+
 	return nil
 }
 
@@ -1295,12 +2129,12 @@ func (cm *ConfigManager) MoveTemplate(oldName, newName string) error {
 func (cm *ConfigManager) validateTemplateContent(content map[string]interface{}) error {
 	// 基本JSON格式验证（通过能够unmarshal已经验证）
 	if content == nil {
-		return fmt.Errorf("content cannot be nil")
+		return Invalidf("content cannot be nil")
 	}
 
 	// 验证是否可以序列化
 	if _, err := json.Marshal(content); err != nil {
-		return fmt.Errorf("content cannot be serialized to JSON: %w", err)
+		return Invalidf("content cannot be serialized to JSON: %w", err)
 	}
 
 	return nil
@@ -1338,7 +2172,7 @@ func (cm *ConfigManager) InitializeFromScratch(authToken, baseURL string) error
 	}
 
 	// 确保目录存在
-	if err := os.MkdirAll(cm.claudeDir, 0755); err != nil {
+	if err := cm.fs.MkdirAll(cm.claudeDir, 0755); err != nil {
 		return fmt.Errorf("failed to create claude directory: %w", err)
 	}
 
@@ -1364,15 +2198,8 @@ func (cm *ConfigManager) writeConfigFile(filePath string, content map[string]int
 	}
 
 	// 原子性写入
-	tempFile := filePath + ".tmp"
-	if err := os.WriteFile(tempFile, jsonData, 0600); err != nil {
-		return fmt.Errorf("failed to write to temporary file: %w", err)
-	}
-
-	// 原子性重命名
-	if err := os.Rename(tempFile, filePath); err != nil {
-		os.Remove(tempFile) // 清理临时文件
-		return fmt.Errorf("failed to create configuration file: %w", err)
+	if err := cm.writeFile(filePath, jsonData, 0600); err != nil {
+		return fmt.Errorf("failed to write configuration file: %w", err)
 	}
 
 	return nil
@@ -1418,7 +2245,7 @@ func (cm *ConfigManager) EnableEmptyMode() error {
 	}
 
 	// 创建备份路径
-	backupPath := filepath.Join(cm.profilesDir, ".empty_backup_settings.json")
+	backupPath := cm.dataFile(emptyBackupFileName)
 
 	// 获取当前配置名
 	currentProfile, _ := cm.getCurrentProfile()
@@ -1438,24 +2265,25 @@ func (cm *ConfigManager) EnableEmptyMode() error {
 
 	// 步骤3: 保存状态（原子性）
 	if err := cm.saveEmptyModeInfo(emptyInfo); err != nil {
-		os.Remove(backupPath) // 清理备份
+		cm.fs.Remove(backupPath) // 清理备份
 		return fmt.Errorf("failed to save empty mode info: %w", err)
 	}
 
 	// 步骤4: 更新历史记录，将进入empty mode记录为历史
-	if err := cm.updateHistory("empty_mode"); err != nil {
+	if err := cm.updateHistory("empty_mode", ""); err != nil {
 		// 历史记录更新失败不应该阻止empty mode启用，只记录错误
-		fmt.Fprintf(os.Stderr, "Warning: failed to update history: %v\n", err)
+		fmt.Fprintf(cm.warnings, "Warning: failed to update history: %v\n", err)
 	}
 
 	// 步骤5: 移除 settings.json（最后步骤）
-	if err := os.Remove(cm.settingsFile); err != nil {
+	if err := cm.fs.Remove(cm.settingsFile); err != nil {
 		// 回滚操作
 		cm.removeEmptyModeInfo()
-		os.Remove(backupPath)
+		cm.fs.Remove(backupPath)
 		return fmt.Errorf("failed to remove settings file: %w", err)
 	}
 
+	cm.LogActivity(ActivityEntry{Action: ActivityEmptyOn, Profile: currentProfile})
 	return nil
 }
 
@@ -1478,14 +2306,7 @@ func (cm *ConfigManager) DisableEmptyMode() error {
 	}
 
 	// 步骤1: 恢复 settings.json（原子性）
-	tempFile := cm.settingsFile + ".tmp"
-	if err := cm.copyFile(emptyInfo.BackupPath, tempFile); err != nil {
-		return fmt.Errorf("failed to prepare settings restoration: %w", err)
-	}
-
-	// 原子性重命名
-	if err := os.Rename(tempFile, cm.settingsFile); err != nil {
-		os.Remove(tempFile) // 清理临时文件
+	if err := cm.copyFile(emptyInfo.BackupPath, cm.settingsFile); err != nil {
 		return fmt.Errorf("failed to restore settings file: %w", err)
 	}
 
@@ -1493,13 +2314,13 @@ func (cm *ConfigManager) DisableEmptyMode() error {
 	if emptyInfo.PreviousProfile != "" {
 		if err := cm.setCurrentProfile(emptyInfo.PreviousProfile); err != nil {
 			// 不是致命错误，记录警告但继续
-			fmt.Fprintf(os.Stderr, "Warning: failed to set current profile marker: %v\n", err)
+			fmt.Fprintf(cm.warnings, "Warning: failed to set current profile marker: %v\n", err)
 		}
 
 		// 步骤3: 更新历史记录，恢复到之前的配置
-		if err := cm.updateHistory(emptyInfo.PreviousProfile); err != nil {
+		if err := cm.updateHistory(emptyInfo.PreviousProfile, ""); err != nil {
 			// 历史记录更新失败不应该阻止配置恢复，只记录错误
-			fmt.Fprintf(os.Stderr, "Warning: failed to update history: %v\n", err)
+			fmt.Fprintf(cm.warnings, "Warning: failed to update history: %v\n", err)
 		}
 	}
 
@@ -1509,8 +2330,9 @@ func (cm *ConfigManager) DisableEmptyMode() error {
 	}
 
 	// 步骤5: 清理备份文件
-	os.Remove(emptyInfo.BackupPath)
+	cm.fs.Remove(emptyInfo.BackupPath)
 
+	cm.LogActivity(ActivityEntry{Action: ActivityEmptyOff, Profile: emptyInfo.PreviousProfile})
 	return nil
 }
 
@@ -1545,6 +2367,52 @@ func (cm *ConfigManager) RestoreToPreviousProfile() error {
 	return nil
 }
 
+// EmptyModeBackupPath 返回空配置模式下 settings.json 备份文件的路径
+// 状态文件中未记录路径时使用默认位置
+func (cm *ConfigManager) EmptyModeBackupPath() (string, error) {
+	info, err := cm.GetEmptyModeInfo()
+	if err != nil {
+		return "", err
+	}
+	if info.BackupPath == "" {
+		return cm.dataFile(emptyBackupFileName), nil
+	}
+	return info.BackupPath, nil
+}
+
+// GetEmptyModeBackupContent 读取空配置模式下备份的 settings.json 内容
+func (cm *ConfigManager) GetEmptyModeBackupContent() (map[string]interface{}, error) {
+	backupPath, err := cm.EmptyModeBackupPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("backup file not found: %s", backupPath)
+		}
+		return nil, fmt.Errorf("failed to read backup file: %w", err)
+	}
+
+	var content map[string]interface{}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("failed to parse backup file: %w", err)
+	}
+
+	return content, nil
+}
+
+// ExportEmptyModeBackup 将空配置模式的备份保存为新配置，不退出空配置模式
+func (cm *ConfigManager) ExportEmptyModeBackup(name string) error {
+	content, err := cm.GetEmptyModeBackupContent()
+	if err != nil {
+		return err
+	}
+
+	return cm.CreateProfileWithContent(name, content)
+}
+
 // saveEmptyModeInfo 保存空配置模式信息
 func (cm *ConfigManager) saveEmptyModeInfo(info *EmptyModeInfo) error {
 	jsonData, err := json.MarshalIndent(info, "", "  ")
@@ -1553,13 +2421,7 @@ func (cm *ConfigManager) saveEmptyModeInfo(info *EmptyModeInfo) error {
 	}
 
 	// 原子性写入
-	tempFile := cm.emptyModeFile + ".tmp"
-	if err := os.WriteFile(tempFile, jsonData, 0600); err != nil {
-		return fmt.Errorf("failed to write temporary empty mode file: %w", err)
-	}
-
-	if err := os.Rename(tempFile, cm.emptyModeFile); err != nil {
-		os.Remove(tempFile) // 清理临时文件
+	if err := cm.fs.WriteFile(cm.emptyModeFile, jsonData, 0600, false); err != nil {
 		return fmt.Errorf("failed to save empty mode file: %w", err)
 	}
 
@@ -1568,7 +2430,7 @@ func (cm *ConfigManager) saveEmptyModeInfo(info *EmptyModeInfo) error {
 
 // removeEmptyModeInfo 移除空配置模式信息
 func (cm *ConfigManager) removeEmptyModeInfo() error {
-	if err := os.Remove(cm.emptyModeFile); err != nil && !os.IsNotExist(err) {
+	if err := cm.fs.Remove(cm.emptyModeFile); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to remove empty mode file: %w", err)
 	}
 	return nil
@@ -0,0 +1,282 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HoBeedzc/cc-switch/internal/testutil"
+)
+
+// addUnreadableProfile places a profile file in the profiles directory that cannot be read
+func addUnreadableProfile(t *testing.T, cm *ConfigManager, name, kind string) {
+	t.Helper()
+	path := filepath.Join(cm.profilesDir, name+".json")
+	switch kind {
+	case "dangling symlink":
+		if err := os.Symlink("missing-target", path); err != nil {
+			t.Skipf("cannot create symlinks: %v", err)
+		}
+	case "outside symlink":
+		target := filepath.Join(t.TempDir(), "elsewhere.json")
+		if err := os.WriteFile(target, []byte("{}"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(target, path); err != nil {
+			t.Skipf("cannot create symlinks: %v", err)
+		}
+	case "no permission":
+		if runtime.GOOS == "windows" {
+			t.Skip("file modes do not restrict reading on Windows")
+		}
+		if os.Geteuid() == 0 {
+			t.Skip("root can read files without permission")
+		}
+		if err := os.WriteFile(path, []byte("{}"), 0000); err != nil {
+			t.Fatal(err)
+		}
+	default:
+		t.Fatalf("unknown kind %q", kind)
+	}
+}
+
+func TestListProfilesReportsUnreadableFiles(t *testing.T) {
+	tests := []struct {
+		kind string
+		want string
+	}{
+		{kind: "dangling symlink", want: "broken symlink"},
+		{kind: "outside symlink", want: "symlink outside the profiles directory"},
+		{kind: "no permission", want: "permission denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			cm := newTestManager(t)
+			if err := cm.CreateProfileWithContent("work", map[string]interface{}{}); err != nil {
+				t.Fatal(err)
+			}
+			addUnreadableProfile(t, cm, "broken", tt.kind)
+
+			profiles, err := cm.ListProfiles()
+			if err != nil {
+				t.Fatalf("ListProfiles: %v", err)
+			}
+
+			errs := make(map[string]string)
+			for _, profile := range profiles {
+				errs[profile.Name] = profile.Error
+			}
+			if len(errs) != 2 {
+				t.Fatalf("profiles = %v, want broken and work", profiles)
+			}
+			if errs["broken"] != tt.want {
+				t.Errorf("broken error = %q, want %q", errs["broken"], tt.want)
+			}
+			if errs["work"] != "" {
+				t.Errorf("work error = %q, want none", errs["work"])
+			}
+		})
+	}
+}
+
+// rewriteCurrentFile replaces .current behind the manager's back, keeping the given modification time
+func rewriteCurrentFile(t *testing.T, cm *ConfigManager, name string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(cm.currentFile, []byte(name), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(cm.currentFile, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// currentModTime returns the modification time of .current
+func currentModTime(t *testing.T, cm *ConfigManager) time.Time {
+	t.Helper()
+	info, err := os.Stat(cm.currentFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.ModTime()
+}
+
+func TestCurrentProfileCache(t *testing.T) {
+	cm := newTestManager(t)
+	for _, name := range []string{"aaaa", "bbbb"} {
+		if err := cm.CreateProfile(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cm.UseProfile("aaaa"); err != nil {
+		t.Fatal(err)
+	}
+	if current, _ := cm.GetCurrentProfile(); current != "aaaa" {
+		t.Fatalf("current = %q, want aaaa", current)
+	}
+	modTime := currentModTime(t, cm)
+
+	// Same size and modification time: the cached name is used without reading the file
+	rewriteCurrentFile(t, cm, "bbbb", modTime)
+	if current, _ := cm.GetCurrentProfile(); current != "aaaa" {
+		t.Errorf("current = %q, want the cached aaaa", current)
+	}
+
+	// Another process switching changes the modification time, which is noticed
+	rewriteCurrentFile(t, cm, "bbbb", modTime.Add(time.Second))
+	if current, _ := cm.GetCurrentProfile(); current != "bbbb" {
+		t.Errorf("current = %q, want bbbb after an outside change", current)
+	}
+}
+
+func TestCurrentProfileCacheInvalidatedByWrites(t *testing.T) {
+	cm := newTestManager(t)
+	for _, name := range []string{"aaaa", "bbbb"} {
+		if err := cm.CreateProfile(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cm.UseProfile("aaaa"); err != nil {
+		t.Fatal(err)
+	}
+	cm.GetCurrentProfile()
+	modTime := currentModTime(t, cm)
+
+	// A switch made by the manager is seen even if the file ends up with the old
+	// size and modification time, as can happen within the file system's time granularity
+	if err := cm.UseProfile("bbbb"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(cm.currentFile, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	if current, _ := cm.GetCurrentProfile(); current != "bbbb" {
+		t.Errorf("current after switch = %q, want bbbb", current)
+	}
+
+	// Removing .current clears the cache as well
+	if err := os.Remove(cm.currentFile); err != nil {
+		t.Fatal(err)
+	}
+	if current, err := cm.GetCurrentProfile(); current != "" || err == nil {
+		t.Errorf("current = %q, %v; want an error once .current is gone", current, err)
+	}
+	rewriteCurrentFile(t, cm, "aaaa", modTime)
+	if current, _ := cm.GetCurrentProfile(); current != "aaaa" {
+		t.Errorf("current = %q, want aaaa read again", current)
+	}
+}
+
+func TestCurrentProfileCacheConcurrentAccess(t *testing.T) {
+	cm := newTestManager(t)
+	for _, name := range []string{"aaaa", "bbbb"} {
+		if err := cm.CreateProfile(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cm.UseProfile("aaaa"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Run with -race: readers share the cache while a writer keeps invalidating it
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if current, err := cm.GetCurrentProfile(); err == nil && current != "aaaa" && current != "bbbb" {
+					t.Errorf("current = %q", current)
+				}
+			}
+		}()
+	}
+	for j := 0; j < 20; j++ {
+		cm.invalidateCurrentProfile()
+	}
+	wg.Wait()
+}
+
+func TestRenderTemplate(t *testing.T) {
+	cm := newTestManager(t)
+	if err := cm.CreateTemplate("team"); err != nil {
+		t.Fatal(err)
+	}
+	template := map[string]interface{}{
+		"model": "sonnet",
+		"env": map[string]interface{}{
+			"ANTHROPIC_BASE_URL":   "https://llm.example.com",
+			"ANTHROPIC_AUTH_TOKEN": "",
+		},
+	}
+	if err := cm.UpdateTemplate("team", template); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(filepath.Join(cm.templatesDir, "team.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		inputs map[string]string
+		want   map[string]interface{}
+	}{
+		{"no variables", nil, template},
+		{
+			"variables",
+			map[string]string{"env.ANTHROPIC_AUTH_TOKEN": "sk-ant-test", "permissions.defaultMode": "plan"},
+			map[string]interface{}{
+				"model": "sonnet",
+				"env": map[string]interface{}{
+					"ANTHROPIC_BASE_URL":   "https://llm.example.com",
+					"ANTHROPIC_AUTH_TOKEN": "sk-ant-test",
+				},
+				"permissions": map[string]interface{}{"defaultMode": "plan"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cm.RenderTemplate("team", tt.inputs)
+			if err != nil {
+				t.Fatalf("RenderTemplate: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RenderTemplate = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	after, err := os.ReadFile(filepath.Join(cm.templatesDir, "team.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("template changed by rendering:\n%s\nwant\n%s", after, before)
+	}
+	if profiles, _ := cm.ListProfiles(); len(profiles) != 0 {
+		t.Errorf("rendering created profiles: %v", profiles)
+	}
+
+	if _, err := cm.RenderTemplate("missing", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing template: err = %v, want ErrNotFound", err)
+	}
+	if _, err := cm.RenderTemplate("team", map[string]string{"env..TOKEN": "x"}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("invalid path: err = %v, want ErrInvalid", err)
+	}
+}
+
+func TestTestHomeClearsConfigEnv(t *testing.T) {
+	for _, name := range []string{SystemProfilesDirEnv, SecretsPassphraseEnv} {
+		if !slices.Contains(testutil.ClearedEnv, name) {
+			t.Errorf("testutil.ClearedEnv does not clear %s", name)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ManifestEntry 批量创建清单中的一项
+type ManifestEntry struct {
+	Name     string            `json:"name"`
+	Template string            `json:"template,omitempty"` // 为空时使用 default 模板
+	Values   map[string]string `json:"values,omitempty"`   // 字段路径 -> 值，如 "env.ANTHROPIC_AUTH_TOKEN"
+}
+
+// ManifestResult 清单中单项的处理结果
+type ManifestResult struct {
+	Name     string `json:"name"`
+	Template string `json:"template"`
+	Created  bool   `json:"created"` // dry-run 时为 false
+	Error    string `json:"error,omitempty"`
+}
+
+// csvColumnPaths CSV 清单中常用列对应的字段路径
+var csvColumnPaths = map[string]string{
+	"token":    "env.ANTHROPIC_AUTH_TOKEN",
+	"base_url": "env.ANTHROPIC_BASE_URL",
+}
+
+// LoadManifest 读取批量创建清单，.csv 文件按 CSV 解析，其余按 JSON 数组解析
+func LoadManifest(path string) ([]ManifestEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer file.Close()
+
+	var entries []ManifestEntry
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		entries, err = parseCSVManifest(file)
+	} else {
+		err = json.NewDecoder(file).Decode(&entries)
+		if err != nil {
+			err = Invalidf("invalid JSON manifest (expected an array of {name, template, values}): %w", err)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateManifest(entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// parseCSVManifest 解析 CSV 清单
+// 首行为表头，必须包含 name 列；template、token、base_url 为常用列，其他列名按字段路径处理（如 env.HTTP_PROXY）
+func parseCSVManifest(r io.Reader) ([]ManifestEntry, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, Invalidf("invalid CSV manifest: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("CSV manifest is empty")
+	}
+
+	header := records[0]
+	nameColumn := -1
+	for i, column := range header {
+		header[i] = strings.TrimSpace(column)
+		if strings.EqualFold(header[i], "name") {
+			nameColumn = i
+		}
+	}
+	if nameColumn < 0 {
+		return nil, Invalidf("CSV manifest must have a 'name' column")
+	}
+
+	entries := make([]ManifestEntry, 0, len(records)-1)
+	for _, record := range records[1:] {
+		entry := ManifestEntry{Values: make(map[string]string)}
+		for i, value := range record {
+			value = strings.TrimSpace(value)
+			column := strings.ToLower(header[i])
+			switch {
+			case column == "name":
+				entry.Name = value
+			case column == "template":
+				entry.Template = value
+			case value == "":
+				// 空单元格不覆盖模板中的值
+			case csvColumnPaths[column] != "":
+				entry.Values[csvColumnPaths[column]] = value
+			default:
+				entry.Values[header[i]] = value
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// validateManifest 检查清单整体问题：空清单、缺少名称、名称重复
+func validateManifest(entries []ManifestEntry) error {
+	if len(entries) == 0 {
+		return fmt.Errorf("manifest contains no entries")
+	}
+
+	seen := make(map[string]int, len(entries))
+	for i, entry := range entries {
+		name := strings.TrimSpace(entry.Name)
+		if name == "" {
+			return fmt.Errorf("manifest entry %d has no name", i+1)
+		}
+		if first, ok := seen[name]; ok {
+			return fmt.Errorf("duplicate name '%s' in manifest (entries %d and %d)", name, first, i+1)
+		}
+		seen[name] = i + 1
+	}
+
+	return nil
+}
+
+// CreateProfilesFromManifest 按清单逐项创建配置，单项失败不影响其他项
+// dryRun 为 true 时只做校验，不写入任何文件
+func (cm *ConfigManager) CreateProfilesFromManifest(entries []ManifestEntry, dryRun bool) []ManifestResult {
+	results := make([]ManifestResult, 0, len(entries))
+	defaultTemplate, warning := cm.ResolveTemplate("")
+	if warning != "" {
+		fmt.Fprintf(cm.warnings, "Warning: %s\n", warning)
+	}
+
+	for _, entry := range entries {
+		result := ManifestResult{
+			Name:     strings.TrimSpace(entry.Name),
+			Template: entry.Template,
+		}
+		if result.Template == "" {
+			result.Template = defaultTemplate
+		}
+
+		if err := cm.createManifestEntry(result.Name, result.Template, entry.Values, dryRun); err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Created = !dryRun
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// createManifestEntry 校验并创建清单中的一项
+func (cm *ConfigManager) createManifestEntry(name, templateName string, values map[string]string, dryRun bool) error {
+	if err := cm.validateProfileName(name); err != nil {
+		return err
+	}
+	// 清单通常来自他人，拒绝可能逃出 profiles 目录的名称
+	if strings.ContainsAny(name, `/\`) || name == "." || name == ".." || strings.HasPrefix(name, ".") {
+		return Invalidf("invalid profile name '%s'", name)
+	}
+
+	if cm.ProfileExists(name) {
+		return Conflictf("profile '%s' already exists", name)
+	}
+
+	template, err := cm.GetTemplateContent(templateName)
+	if err != nil {
+		return err
+	}
+
+	content := cm.PopulateTemplate(template, values)
+	for _, issue := range ValidateContent(content, false) {
+		if issue.Severity == SeverityError {
+			return Invalidf("invalid content at '%s': %s", issue.Path, issue.Message)
+		}
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	if err := cm.CreateProfileWithContent(name, content); err != nil {
+		return err
+	}
+
+	cm.setProfileTemplate(name, templateName)
+	cm.setProfileOrigin(name, OriginTemplate(templateName))
+	return nil
+}
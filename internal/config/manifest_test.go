@@ -0,0 +1,142 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeManifest writes a manifest file with the given name and returns its path
+func writeManifest(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []ManifestEntry
+		wantErr string
+	}{
+		{
+			name: "json",
+			file: "team.json",
+			content: `[
+				{"name": "alice", "template": "proxy", "values": {"env.ANTHROPIC_AUTH_TOKEN": "sk-a"}},
+				{"name": "bob"}
+			]`,
+			want: []ManifestEntry{
+				{Name: "alice", Template: "proxy", Values: map[string]string{"env.ANTHROPIC_AUTH_TOKEN": "sk-a"}},
+				{Name: "bob"},
+			},
+		},
+		{
+			name:    "csv with common and path columns",
+			file:    "team.CSV",
+			content: "name, template, token, base_url, env.HTTP_PROXY\nalice,proxy,sk-a,https://a.example.com,http://proxy:8080\nbob,,sk-b,,\n",
+			want: []ManifestEntry{
+				{Name: "alice", Template: "proxy", Values: map[string]string{
+					"env.ANTHROPIC_AUTH_TOKEN": "sk-a",
+					"env.ANTHROPIC_BASE_URL":   "https://a.example.com",
+					"env.HTTP_PROXY":           "http://proxy:8080",
+				}},
+				{Name: "bob", Values: map[string]string{"env.ANTHROPIC_AUTH_TOKEN": "sk-b"}},
+			},
+		},
+		{name: "duplicate names", file: "team.json", content: `[{"name": "alice"}, {"name": " alice "}]`, wantErr: "duplicate name 'alice' in manifest (entries 1 and 2)"},
+		{name: "missing name", file: "team.json", content: `[{"name": "alice"}, {"template": "proxy"}]`, wantErr: "manifest entry 2 has no name"},
+		{name: "no entries", file: "team.json", content: `[]`, wantErr: "manifest contains no entries"},
+		{name: "not an array", file: "team.json", content: `{"name": "alice"}`, wantErr: "invalid JSON manifest"},
+		{name: "csv without name column", file: "team.csv", content: "template,token\nproxy,sk-a\n", wantErr: "must have a 'name' column"},
+		{name: "empty csv", file: "team.csv", content: "", wantErr: "CSV manifest is empty"},
+		{name: "csv header only", file: "team.csv", content: "name,token\n", wantErr: "manifest contains no entries"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := LoadManifest(writeManifest(t, tt.file, tt.content))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadManifest: %v", err)
+			}
+			if !reflect.DeepEqual(entries, tt.want) {
+				t.Errorf("entries = %+v, want %+v", entries, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateProfilesFromManifest(t *testing.T) {
+	cm := newTestManager(t)
+	if err := cm.CreateProfileWithContent("taken", map[string]interface{}{}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := []ManifestEntry{
+		{Name: "alice", Values: map[string]string{"env.ANTHROPIC_AUTH_TOKEN": "sk-alice"}},
+		{Name: "../escape"},
+		{Name: "taken"},
+		{Name: "carol", Template: "missing"},
+		{Name: "bob", Values: map[string]string{"env.ANTHROPIC_BASE_URL": "https://bob.example.com"}},
+	}
+	results := cm.CreateProfilesFromManifest(entries, false)
+	if len(results) != len(entries) {
+		t.Fatalf("got %d results, want %d", len(results), len(entries))
+	}
+
+	// Failures are reported per entry and do not stop the later ones
+	for _, result := range results {
+		wantCreated := result.Name == "alice" || result.Name == "bob"
+		if result.Created != wantCreated || (result.Error == "") != wantCreated {
+			t.Errorf("%s: created %v, error %q", result.Name, result.Created, result.Error)
+		}
+		if result.Template == "" {
+			t.Errorf("%s: template not resolved", result.Name)
+		}
+	}
+	if !strings.Contains(results[2].Error, "already exists") {
+		t.Errorf("taken: error = %q, want already exists", results[2].Error)
+	}
+
+	content, _, err := cm.GetProfileContent("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token := content["env"].(map[string]interface{})["ANTHROPIC_AUTH_TOKEN"]; token != "sk-alice" {
+		t.Errorf("alice token = %v, want the manifest value", token)
+	}
+	if meta, _ := cm.GetProfileMetadata("alice"); meta.Template != results[0].Template {
+		t.Errorf("alice template = %q, want %q", meta.Template, results[0].Template)
+	}
+	if cm.ProfileExists("carol") {
+		t.Error("carol was created from a missing template")
+	}
+}
+
+func TestCreateProfilesFromManifestDryRun(t *testing.T) {
+	cm := newTestManager(t)
+
+	results := cm.CreateProfilesFromManifest([]ManifestEntry{{Name: "alice"}, {Name: "../escape"}}, true)
+	if results[0].Error != "" || results[0].Created {
+		t.Errorf("alice: %+v, want valid and not created", results[0])
+	}
+	if results[1].Error == "" {
+		t.Error("../escape: invalid name passed the dry run")
+	}
+	if cm.ProfileExists("alice") {
+		t.Error("dry run created alice")
+	}
+}
@@ -0,0 +1,404 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/HoBeedzc/cc-switch/internal/common"
+)
+
+// metadataDirName 配置元数据（sidecar）目录名，位于数据目录下
+const metadataDirName = ".meta"
+
+// ProfileMetadata 配置元数据，与配置文件分开存储以保持 settings.json 内容不变
+type ProfileMetadata struct {
+	Name        string    `json:"name,omitempty"`         // 该元数据所属的配置名，用于识别随配置文件一起被手动复制的元数据
+	ContentHash string    `json:"content_hash,omitempty"` // 最后写入时配置文件内容的 SHA-256，用于识别手动复制的配置
+	WrittenBy   string    `json:"written_by,omitempty"`   // 最后写入该配置的 cc-switch 版本
+	UpdatedAt   time.Time `json:"updated_at,omitempty"`
+	Template    string    `json:"template,omitempty"`     // 创建该配置所用的模板
+	Tags        []string  `json:"tags,omitempty"`         // 用户添加的标签，用于分组和筛选
+	DisplayName string    `json:"display_name,omitempty"` // 仅用于展示的友好名称，命令中仍使用文件名
+	Origin      string    `json:"origin,omitempty"`       // 配置的来源，如 template:<name>、import:<文件名>、copy-of:<配置>
+	Ephemeral   bool      `json:"ephemeral,omitempty"`    // 临时配置（use --scratch），切换离开后自动删除
+	Aliases     []string  `json:"aliases,omitempty"`      // 配置的简短别名，可代替配置名用于 use、view 等命令
+	Project     string    `json:"project,omitempty"`      // 配置对应的项目目录（绝对路径），use --launch 时在该目录启动 Claude Code
+	Provider    string    `json:"provider,omitempty"`     // 创建配置所用的提供商预设（new --provider），测试时据此选择认证方式
+
+	EndpointSets map[string][]string `json:"endpoint_sets,omitempty"` // 仅对该配置生效的测试端点集合
+}
+
+// TemplateInUseError 模板仍被配置引用错误
+type TemplateInUseError struct {
+	Template   string
+	Dependents []string
+}
+
+func (e *TemplateInUseError) Error() string {
+	return fmt.Sprintf("template '%s' is still used by %d configuration(s): %s", e.Template, len(e.Dependents), strings.Join(e.Dependents, ", "))
+}
+
+func (e *TemplateInUseError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// metadataPath 返回配置元数据文件路径
+func (cm *ConfigManager) metadataPath(name string) string {
+	return filepath.Join(cm.dataFile(metadataDirName), name+".json")
+}
+
+// GetProfileMetadata 获取配置元数据（不存在时返回空元数据）
+func (cm *ConfigManager) GetProfileMetadata(name string) (*ProfileMetadata, error) {
+	data, err := os.ReadFile(cm.metadataPath(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &ProfileMetadata{}, nil
+		}
+		return nil, fmt.Errorf("failed to read profile metadata: %w", err)
+	}
+
+	var meta ProfileMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse profile metadata: %w", err)
+	}
+
+	return &meta, nil
+}
+
+// saveProfileMetadata 原子性保存配置元数据
+func (cm *ConfigManager) saveProfileMetadata(name string, meta *ProfileMetadata) error {
+	metaPath := cm.metadataPath(name)
+	if err := cm.fs.MkdirAll(filepath.Dir(metaPath), 0755); err != nil {
+		return fmt.Errorf("failed to create metadata directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal profile metadata: %w", err)
+	}
+
+	if err := cm.fs.WriteFile(metaPath, data, 0600, false); err != nil {
+		return fmt.Errorf("failed to save profile metadata: %w", err)
+	}
+
+	return nil
+}
+
+// stampProfile 记录当前版本写入了该配置（失败只给出警告，不影响主操作）
+func (cm *ConfigManager) stampProfile(name string) {
+	meta, err := cm.GetProfileMetadata(name)
+	if err != nil {
+		meta = &ProfileMetadata{}
+	}
+
+	meta.Name = name
+	meta.ContentHash = cm.profileContentHash(name)
+	meta.WrittenBy = common.Version
+	meta.UpdatedAt = time.Now()
+
+	if err := cm.saveProfileMetadata(name, meta); err != nil {
+		fmt.Fprintf(cm.warnings, "Warning: %v\n", err)
+	}
+}
+
+// removeProfileMetadata 删除配置元数据
+func (cm *ConfigManager) removeProfileMetadata(name string) {
+	cm.fs.Remove(cm.metadataPath(name))
+}
+
+// renameProfileMetadata 随配置重命名移动元数据
+func (cm *ConfigManager) renameProfileMetadata(oldName, newName string) {
+	oldPath := cm.metadataPath(oldName)
+	if _, err := os.Stat(oldPath); err != nil {
+		return
+	}
+	if err := cm.fs.Rename(oldPath, cm.metadataPath(newName)); err != nil {
+		return
+	}
+
+	// 同步更新元数据中记录的配置名
+	if meta, err := cm.GetProfileMetadata(newName); err == nil && meta.Name != "" {
+		meta.Name = newName
+		cm.saveProfileMetadata(newName, meta)
+	}
+}
+
+// WrittenByNewerVersion 检查配置是否由更新的 cc-switch 主/次版本写入
+// 返回写入该配置的版本号以及是否更新
+func (cm *ConfigManager) WrittenByNewerVersion(name string) (string, bool) {
+	meta, err := cm.GetProfileMetadata(name)
+	if err != nil || meta.WrittenBy == "" {
+		return "", false
+	}
+
+	return meta.WrittenBy, common.IsNewerVersion(majorMinor(meta.WrittenBy), majorMinor(common.Version))
+}
+
+// warnIfWrittenByNewer 如果配置由更新版本写入，每个配置只警告一次
+// 返回 true 表示配置由更新版本写入
+func (cm *ConfigManager) warnIfWrittenByNewer(name string) bool {
+	version, newer := cm.WrittenByNewerVersion(name)
+	if !newer {
+		return false
+	}
+
+	if cm.newerVersionWarned == nil {
+		cm.newerVersionWarned = make(map[string]bool)
+	}
+	if !cm.newerVersionWarned[name] {
+		cm.newerVersionWarned[name] = true
+		fmt.Fprintf(cm.warnings, "Warning: profile '%s' was written by cc-switch %s (this is %s); it will not be rewritten automatically. Consider running 'cc-switch update'.\n", name, version, common.Version)
+	}
+
+	return true
+}
+
+// majorMinor 截取版本号的主版本和次版本部分，如 "1.2.3" -> "1.2"
+func majorMinor(version string) string {
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+	return strings.Join(parts, ".")
+}
+
+// setProfileTemplate 记录配置所基于的模板
+func (cm *ConfigManager) setProfileTemplate(name, templateName string) {
+	meta, err := cm.GetProfileMetadata(name)
+	if err != nil {
+		meta = &ProfileMetadata{}
+	}
+
+	meta.Template = templateName
+	if err := cm.saveProfileMetadata(name, meta); err != nil {
+		fmt.Fprintf(cm.warnings, "Warning: %v\n", err)
+	}
+}
+
+// 配置来源，创建时写入元数据；带参数的来源形如 template:<name>
+const (
+	OriginUnknown  = "unknown"  // 没有记录来源（早期版本创建或手动放入的配置）
+	OriginManual   = "manual"   // 直接提供内容创建，如 Web 界面中手动填写
+	OriginSnapshot = "snapshot" // 首次运行时从 settings.json 保存
+
+	originTemplatePrefix = "template:"
+	originImportPrefix   = "import:"
+	originCopyPrefix     = "copy-of:"
+)
+
+// OriginTemplate 从模板创建的来源
+func OriginTemplate(templateName string) string {
+	return originTemplatePrefix + templateName
+}
+
+// OriginImport 从导出文件导入的来源，只记录文件名
+func OriginImport(archivePath string) string {
+	return originImportPrefix + filepath.Base(archivePath)
+}
+
+// OriginCopyOf 复制自其他配置的来源
+func OriginCopyOf(source string) string {
+	return originCopyPrefix + source
+}
+
+// setProfileOrigin 记录配置来源（失败只给出警告，不影响主操作）
+func (cm *ConfigManager) setProfileOrigin(name, origin string) {
+	meta, err := cm.GetProfileMetadata(name)
+	if err != nil {
+		meta = &ProfileMetadata{}
+	}
+
+	meta.Origin = origin
+	if err := cm.saveProfileMetadata(name, meta); err != nil {
+		fmt.Fprintf(cm.warnings, "Warning: %v\n", err)
+	}
+}
+
+// SetProfileOrigin 记录配置来源，供导入等在 config 包之外创建配置的流程使用
+func (cm *ConfigManager) SetProfileOrigin(name, origin string) error {
+	if !cm.ProfileExists(name) {
+		return NotFoundf("profile '%s' does not exist", name)
+	}
+	cm.setProfileOrigin(name, origin)
+	return nil
+}
+
+// ProfileOrigin 返回配置记录的来源，未记录时为 unknown
+func (cm *ConfigManager) ProfileOrigin(name string) string {
+	meta, err := cm.GetProfileMetadata(name)
+	if err != nil || meta.Origin == "" {
+		return OriginUnknown
+	}
+	return meta.Origin
+}
+
+// ProfileOriginChain 返回配置的来源链：复制来的配置会继续追溯其来源配置，如 [copy-of:work template:default]
+// 来源配置已被删除时链在此处结束
+func (cm *ConfigManager) ProfileOriginChain(name string) []string {
+	var chain []string
+	seen := map[string]bool{name: true}
+	for {
+		origin := cm.ProfileOrigin(name)
+		chain = append(chain, origin)
+
+		source, ok := strings.CutPrefix(origin, originCopyPrefix)
+		if !ok || seen[source] || !cm.ProfileExists(source) {
+			return chain
+		}
+		seen[source] = true
+		name = source
+	}
+}
+
+// retargetCopyOrigins 配置重命名后，将复制自它的配置的来源改为新名称，保持来源链完整
+func (cm *ConfigManager) retargetCopyOrigins(oldName, newName string) {
+	profiles, err := cm.ListProfiles()
+	if err != nil {
+		return
+	}
+	for _, profile := range profiles {
+		if !profile.ReadOnly && cm.ProfileOrigin(profile.Name) == OriginCopyOf(oldName) {
+			cm.setProfileOrigin(profile.Name, OriginCopyOf(newName))
+		}
+	}
+}
+
+// MaxDisplayNameLength 显示名称的最大长度（字符数）
+const MaxDisplayNameLength = 64
+
+// SetProfileDisplayName 设置配置的显示名称（为空则清除）
+func (cm *ConfigManager) SetProfileDisplayName(name, displayName string) error {
+	displayName = strings.TrimSpace(displayName)
+	if len([]rune(displayName)) > MaxDisplayNameLength {
+		return Invalidf("display name is too long (maximum %d characters)", MaxDisplayNameLength)
+	}
+	if strings.ContainsAny(displayName, "\r\n\t") {
+		return fmt.Errorf("display name cannot contain line breaks or tabs")
+	}
+
+	if !cm.ProfileExists(name) {
+		return NotFoundf("profile '%s' does not exist", name)
+	}
+
+	meta, err := cm.GetProfileMetadata(name)
+	if err != nil {
+		return err
+	}
+
+	meta.DisplayName = displayName
+	return cm.saveProfileMetadata(name, meta)
+}
+
+// SetProfileProject 设置配置的项目目录（为空则清除），返回保存的绝对路径
+// 目录必须在设置时存在；路径中的 ~ 与环境变量会被展开
+func (cm *ConfigManager) SetProfileProject(name, dir string) (string, error) {
+	dir = strings.TrimSpace(dir)
+	if dir != "" {
+		abs, err := filepath.Abs(expandCommandPath(dir))
+		if err != nil {
+			return "", Invalidf("invalid project directory '%s': %v", dir, err)
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return "", NotFoundf("project directory '%s' does not exist", abs)
+			}
+			return "", fmt.Errorf("failed to check project directory: %w", err)
+		}
+		if !info.IsDir() {
+			return "", Invalidf("'%s' is not a directory", abs)
+		}
+		dir = abs
+	}
+
+	if !cm.ProfileExists(name) {
+		return "", NotFoundf("profile '%s' does not exist", name)
+	}
+
+	meta, err := cm.GetProfileMetadata(name)
+	if err != nil {
+		return "", err
+	}
+
+	meta.Project = dir
+	if err := cm.saveProfileMetadata(name, meta); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// ProfileProject 返回配置的项目目录（未设置或读取失败时返回空字符串），不检查目录是否仍然存在
+func (cm *ConfigManager) ProfileProject(name string) string {
+	meta, err := cm.GetProfileMetadata(name)
+	if err != nil {
+		return ""
+	}
+	return meta.Project
+}
+
+// applyListMetadata 一次读取元数据，填充列表中配置的显示名称、临时标记、别名、标签和项目目录
+// 随配置文件一起被手动复制的元数据（name 与配置名不符）中的别名不生效，以免两个配置共用同一别名
+func (cm *ConfigManager) applyListMetadata(profile *Profile) {
+	meta, err := cm.GetProfileMetadata(profile.Name)
+	if err != nil {
+		return
+	}
+	profile.DisplayName = meta.DisplayName
+	profile.Scratch = meta.Ephemeral
+	profile.Tags = meta.Tags
+	profile.Project = meta.Project
+	if meta.Name == "" || meta.Name == profile.Name {
+		profile.Aliases = meta.Aliases
+	}
+}
+
+// profileDisplayName 读取配置的显示名称（未设置或读取失败时返回空字符串）
+func (cm *ConfigManager) profileDisplayName(name string) string {
+	meta, err := cm.GetProfileMetadata(name)
+	if err != nil {
+		return ""
+	}
+	return meta.DisplayName
+}
+
+// TemplateInUse 返回基于指定模板创建的配置列表
+func (cm *ConfigManager) TemplateInUse(templateName string) ([]string, error) {
+	profiles, err := cm.ListProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var dependents []string
+	for _, profile := range profiles {
+		meta, err := cm.GetProfileMetadata(profile.Name)
+		if err != nil {
+			continue
+		}
+		if meta.Template == templateName {
+			dependents = append(dependents, profile.Name)
+		}
+	}
+
+	return dependents, nil
+}
+
+// retargetTemplateReferences 将引用 oldName 模板的配置改为引用 newName（newName 为空表示解除引用）
+func (cm *ConfigManager) retargetTemplateReferences(oldName, newName string) error {
+	dependents, err := cm.TemplateInUse(oldName)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range dependents {
+		cm.setProfileTemplate(name, newName)
+		if newName != "" && cm.ProfileOrigin(name) == OriginTemplate(oldName) {
+			cm.setProfileOrigin(name, OriginTemplate(newName))
+		}
+	}
+
+	return nil
+}
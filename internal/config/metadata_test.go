@@ -0,0 +1,291 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/HoBeedzc/cc-switch/internal/common"
+)
+
+// setWrittenByForTest records a cc-switch version as the last writer of a profile
+func setWrittenByForTest(t *testing.T, cm *ConfigManager, name, version string) {
+	t.Helper()
+	meta, err := cm.GetProfileMetadata(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta.WrittenBy = version
+	if err := cm.saveProfileMetadata(name, meta); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMajorMinor(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{version: "1.2.3", want: "1.2"},
+		{version: "v1.2.3", want: "1.2"},
+		{version: "1.2", want: "1.2"},
+		{version: "1", want: "1"},
+		{version: "2.0.0-beta.1", want: "2.0"},
+	}
+	for _, tt := range tests {
+		if got := majorMinor(tt.version); got != tt.want {
+			t.Errorf("majorMinor(%q) = %q, want %q", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestProfileStampedWithVersion(t *testing.T) {
+	cm := newTestManager(t)
+	if err := cm.CreateProfileWithContent("work", map[string]interface{}{"model": "opus"}); err != nil {
+		t.Fatal(err)
+	}
+
+	meta, err := cm.GetProfileMetadata("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.WrittenBy != common.Version {
+		t.Errorf("written_by = %q, want %q", meta.WrittenBy, common.Version)
+	}
+}
+
+func TestWrittenByNewerVersion(t *testing.T) {
+	current := majorMinor(common.Version)
+	tests := []struct {
+		name      string
+		writtenBy string
+		want      bool
+	}{
+		{name: "unknown writer", writtenBy: "", want: false},
+		{name: "same version", writtenBy: common.Version, want: false},
+		{name: "newer patch", writtenBy: current + ".99", want: false},
+		{name: "older major", writtenBy: "0.1.0", want: false},
+		{name: "newer minor", writtenBy: current + "0.0", want: true}, // 1.1 -> 1.10.0
+		{name: "newer major", writtenBy: "99.0.0", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := newTestManager(t)
+			if err := cm.CreateProfileWithContent("work", map[string]interface{}{}); err != nil {
+				t.Fatal(err)
+			}
+			setWrittenByForTest(t, cm, "work", tt.writtenBy)
+
+			version, newer := cm.WrittenByNewerVersion("work")
+			if newer != tt.want {
+				t.Errorf("newer = %v, want %v", newer, tt.want)
+			}
+			if version != tt.writtenBy {
+				t.Errorf("version = %q, want %q", version, tt.writtenBy)
+			}
+		})
+	}
+}
+
+func TestNewerVersionWarningIsShownOnce(t *testing.T) {
+	cm := newTestManager(t)
+	var warnings bytes.Buffer
+	cm.warnings = &warnings
+
+	for _, name := range []string{"work", "home"} {
+		if err := cm.CreateProfileWithContent(name, map[string]interface{}{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setWrittenByForTest(t, cm, "work", "99.0.0")
+
+	if cm.warnIfWrittenByNewer("home") {
+		t.Error("home was written by this version but is reported as newer")
+	}
+	for i := 0; i < 3; i++ {
+		if !cm.warnIfWrittenByNewer("work") {
+			t.Fatal("work was written by 99.0.0 but is not reported as newer")
+		}
+	}
+
+	if count := strings.Count(warnings.String(), "Warning:"); count != 1 {
+		t.Errorf("got %d warnings, want 1:\n%s", count, warnings.String())
+	}
+	if !strings.Contains(warnings.String(), "'work' was written by cc-switch 99.0.0") {
+		t.Errorf("warning does not name the profile and version: %s", warnings.String())
+	}
+}
+
+func TestSwitchDoesNotBackfillProfileWrittenByNewer(t *testing.T) {
+	cm := newTestManager(t)
+	var warnings bytes.Buffer
+	cm.warnings = &warnings
+
+	for _, name := range []string{"work", "home"} {
+		if err := cm.CreateProfileWithContent(name, map[string]interface{}{"model": name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cm.UseProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	setWrittenByForTest(t, cm, "work", "99.0.0")
+
+	// Edit settings.json directly, as Claude Code would
+	edited, _ := json.Marshal(map[string]interface{}{"model": "edited"})
+	if err := os.WriteFile(cm.settingsFile, edited, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cm.UseProfile("home"); err != nil {
+		t.Fatal(err)
+	}
+
+	content, _, err := cm.GetProfileContent("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content["model"] != "work" {
+		t.Errorf("work model = %v, want it left as written by the newer version", content["model"])
+	}
+	if meta, _ := cm.GetProfileMetadata("work"); meta.WrittenBy != "99.0.0" {
+		t.Errorf("written_by = %q, want 99.0.0 kept", meta.WrittenBy)
+	}
+	if !strings.Contains(warnings.String(), "will not be rewritten automatically") {
+		t.Errorf("no warning about the newer version: %q", warnings.String())
+	}
+}
+
+func TestCreationPathsRecordOrigin(t *testing.T) {
+	tests := []struct {
+		name   string
+		create func(t *testing.T, cm *ConfigManager) string // returns the created profile
+		want   string
+	}{
+		{
+			name: "template",
+			create: func(t *testing.T, cm *ConfigManager) string {
+				if err := cm.CreateTemplate("team"); err != nil {
+					t.Fatal(err)
+				}
+				if err := cm.CreateProfileFromTemplate("work", "team"); err != nil {
+					t.Fatal(err)
+				}
+				return "work"
+			},
+			want: OriginTemplate("team"),
+		},
+		{
+			name: "manual",
+			create: func(t *testing.T, cm *ConfigManager) string {
+				if err := cm.CreateProfileWithContent("manual", map[string]interface{}{}); err != nil {
+					t.Fatal(err)
+				}
+				return "manual"
+			},
+			want: OriginManual,
+		},
+		{
+			name: "copy",
+			create: func(t *testing.T, cm *ConfigManager) string {
+				if err := cm.CopyProfile("base", "copy"); err != nil {
+					t.Fatal(err)
+				}
+				return "copy"
+			},
+			want: OriginCopyOf("base"),
+		},
+		{
+			name: "manifest",
+			create: func(t *testing.T, cm *ConfigManager) string {
+				results := cm.CreateProfilesFromManifest([]ManifestEntry{{Name: "listed"}}, false)
+				if results[0].Error != "" {
+					t.Fatal(results[0].Error)
+				}
+				return "listed"
+			},
+			want: OriginTemplate("default"),
+		},
+		{
+			name: "file placed by hand",
+			create: func(t *testing.T, cm *ConfigManager) string {
+				if err := os.WriteFile(filepath.Join(cm.profilesDir, "dropped.json"), []byte("{}"), 0600); err != nil {
+					t.Fatal(err)
+				}
+				return "dropped"
+			},
+			want: OriginUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := newTestManager(t)
+			if err := cm.CreateProfileWithContent("base", map[string]interface{}{}); err != nil {
+				t.Fatal(err)
+			}
+			name := tt.create(t, cm)
+			if got := cm.ProfileOrigin(name); got != tt.want {
+				t.Errorf("origin = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotOrigin(t *testing.T) {
+	home := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(home, ".claude"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".claude", "settings.json"), []byte(`{"model": "opus"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first run saves the existing settings.json as the default profile
+	cm := newTestManagerInHome(t, home)
+	if got := cm.ProfileOrigin("default"); got != OriginSnapshot {
+		t.Errorf("origin = %q, want %q", got, OriginSnapshot)
+	}
+}
+
+func TestOriginFollowsRenamesAndCopies(t *testing.T) {
+	cm := newTestManager(t)
+	if err := cm.CreateProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.CopyProfile("work", "backup"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.CopyProfile("backup", "backup2"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{OriginCopyOf("backup"), OriginCopyOf("work"), OriginTemplate("default")}
+	if got := cm.ProfileOriginChain("backup2"); !equalStrings(got, want) {
+		t.Errorf("chain = %v, want %v", got, want)
+	}
+
+	// Renaming keeps the origin of the renamed profile and retargets copies of it
+	if err := cm.RenameProfile("work", "job"); err != nil {
+		t.Fatal(err)
+	}
+	if got := cm.ProfileOrigin("job"); got != OriginTemplate("default") {
+		t.Errorf("job origin = %q, want it kept", got)
+	}
+	want = []string{OriginCopyOf("backup"), OriginCopyOf("job"), OriginTemplate("default")}
+	if got := cm.ProfileOriginChain("backup2"); !equalStrings(got, want) {
+		t.Errorf("chain after rename = %v, want %v", got, want)
+	}
+
+	// The chain ends at a deleted source
+	if err := cm.DeleteProfile("backup"); err != nil {
+		t.Fatal(err)
+	}
+	if got := cm.ProfileOriginChain("backup2"); !equalStrings(got, []string{OriginCopyOf("backup")}) {
+		t.Errorf("chain after delete = %v", got)
+	}
+}
@@ -0,0 +1,255 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// NetKey 配置中保存网络设置（代理、附加请求头）的键
+// Claude Code 不认识该键：写入 settings.json 时去除，并转换为 Claude Code 读取的 env
+const NetKey = "_net"
+
+// ProxyEnvKey Claude Code 读取的代理 env 键
+const ProxyEnvKey = "HTTPS_PROXY"
+
+// proxySchemes 代理 URL 允许的协议
+var proxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// NetSettings 配置的网络设置，test 和 Claude Code 使用同一份
+type NetSettings struct {
+	Proxy   string            `json:"proxy,omitempty"`   // 代理 URL，如 http://proxy.example.com:8080
+	Headers map[string]string `json:"headers,omitempty"` // 每个请求附加的请求头
+}
+
+// ProfileNet 读取配置中的网络设置，没有 _net 时返回 nil；格式有误时返回第一个问题
+func ProfileNet(content map[string]interface{}) (*NetSettings, error) {
+	raw, ok := content[NetKey]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	if issues := validateNet(content); len(issues) > 0 {
+		return nil, Invalidf("%s: %s", issues[0].Path, issues[0].Message)
+	}
+
+	object := raw.(map[string]interface{})
+	net := &NetSettings{}
+	net.Proxy, _ = object["proxy"].(string)
+	if headers, ok := object["headers"].(map[string]interface{}); ok && len(headers) > 0 {
+		net.Headers = make(map[string]string, len(headers))
+		for name, value := range headers {
+			net.Headers[name] = value.(string)
+		}
+	}
+	return net, nil
+}
+
+// validateNet 校验 _net：必须是对象，proxy 为合法的代理 URL，headers 为合法请求头名到字符串的映射
+func validateNet(content map[string]interface{}) []ValidationIssue {
+	raw, ok := content[NetKey]
+	if !ok || raw == nil {
+		return nil
+	}
+	object, ok := raw.(map[string]interface{})
+	if !ok {
+		return []ValidationIssue{NewIssue(IssueNetNotObject, SeverityError, NetKey, NetKey+" must be an object")}
+	}
+
+	var issues []ValidationIssue
+	for key := range object {
+		if key != "proxy" && key != "headers" {
+			issues = append(issues, NewIssue(IssueNetInvalid, SeverityError, NetKey+"."+key, "unknown field; "+NetKey+" supports proxy and headers"))
+		}
+	}
+
+	if value, ok := object["proxy"]; ok && value != nil {
+		proxy, ok := value.(string)
+		if !ok {
+			issues = append(issues, NewIssue(IssueNetInvalid, SeverityError, NetKey+".proxy", "proxy must be a string"))
+		} else if err := ValidateProxyURL(proxy); err != nil {
+			issues = append(issues, NewIssue(IssueNetInvalid, SeverityError, NetKey+".proxy", err.Error()))
+		}
+	}
+
+	if value, ok := object["headers"]; ok && value != nil {
+		headers, ok := value.(map[string]interface{})
+		if !ok {
+			issues = append(issues, NewIssue(IssueNetInvalid, SeverityError, NetKey+".headers", "headers must be an object of header names to values"))
+		} else {
+			for _, name := range sortedKeysOf(headers) {
+				path := NetKey + ".headers." + name
+				switch headerValue, ok := headers[name].(string); {
+				case !IsValidHeaderName(name):
+					issues = append(issues, NewIssue(IssueNetInvalid, SeverityError, path, fmt.Sprintf("'%s' is not a valid header name", name)))
+				case !ok:
+					issues = append(issues, NewIssue(IssueNetInvalid, SeverityError, path, "header values must be strings"))
+				case strings.ContainsAny(headerValue, "\r\n"):
+					issues = append(issues, NewIssue(IssueNetInvalid, SeverityError, path, "header values cannot contain line breaks"))
+				}
+			}
+		}
+	}
+
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Path < issues[j].Path })
+	return issues
+}
+
+// ValidateProxyURL 检查代理 URL：协议为 http、https、socks5 或 socks5h，且包含主机
+func ValidateProxyURL(raw string) error {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	if !slices.Contains(proxySchemes, strings.ToLower(parsed.Scheme)) {
+		return fmt.Errorf("proxy URL scheme must be one of %s", strings.Join(proxySchemes, ", "))
+	}
+	if parsed.Hostname() == "" {
+		return fmt.Errorf("proxy URL has no host")
+	}
+	return nil
+}
+
+// IsValidHeaderName 判断是否为合法的 HTTP 请求头名（RFC 9110 token）
+func IsValidHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// netEnv 返回网络设置对应的 env：proxy 写入 HTTPS_PROXY，headers 按名称排序后写入 ANTHROPIC_CUSTOM_HEADERS
+func (n *NetSettings) netEnv() map[string]string {
+	env := make(map[string]string)
+	if n.Proxy != "" {
+		env[ProxyEnvKey] = n.Proxy
+	}
+	if len(n.Headers) > 0 {
+		names := make([]string, 0, len(n.Headers))
+		for name := range n.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		lines := make([]string, len(names))
+		for i, name := range names {
+			lines[i] = name + ": " + n.Headers[name]
+		}
+		env[CustomHeadersKey] = strings.Join(lines, "\n")
+	}
+	return env
+}
+
+// Summary 返回用于展示的网络设置摘要，代理 URL 中的密码被隐藏，请求头只列出名称
+func (n *NetSettings) Summary() string {
+	var parts []string
+	if n.Proxy != "" {
+		proxy := n.Proxy
+		if parsed, err := url.Parse(proxy); err == nil {
+			proxy = parsed.Redacted()
+		}
+		parts = append(parts, "proxy "+proxy)
+	}
+	if len(n.Headers) > 0 {
+		names := make([]string, 0, len(n.Headers))
+		for name := range n.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		parts = append(parts, "headers "+strings.Join(names, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// MaterializeContent 返回写入 settings.json 的内容：去除 _net，并把其中的设置转换为 env
+// env 中已有的 HTTPS_PROXY 或 ANTHROPIC_CUSTOM_HEADERS 优先，不会被覆盖；_net 格式有误时只去除该键
+func MaterializeContent(content map[string]interface{}) map[string]interface{} {
+	if _, ok := content[NetKey]; !ok {
+		return content
+	}
+	net, _ := ProfileNet(content)
+
+	result := make(map[string]interface{}, len(content))
+	for key, value := range content {
+		if key != NetKey {
+			result[key] = value
+		}
+	}
+	if net == nil {
+		return result
+	}
+
+	env := make(map[string]interface{})
+	if existing, ok := content["env"].(map[string]interface{}); ok {
+		for key, value := range existing {
+			env[key] = value
+		}
+	}
+	for key, value := range net.netEnv() {
+		if _, exists := env[key]; !exists {
+			env[key] = value
+		}
+	}
+	if len(env) > 0 {
+		result["env"] = env
+	}
+	return result
+}
+
+// restoreNetSettings 将 settings.json 回写到配置时还原 _net，并去掉写入时由 _net 转换出的 env 值
+func restoreNetSettings(stored, live map[string]interface{}) map[string]interface{} {
+	raw, ok := stored[NetKey]
+	if !ok {
+		return live
+	}
+
+	result := make(map[string]interface{}, len(live)+1)
+	for key, value := range live {
+		result[key] = value
+	}
+	result[NetKey] = raw
+
+	net, err := ProfileNet(stored)
+	if err != nil || net == nil {
+		return result
+	}
+	liveEnv, ok := live["env"].(map[string]interface{})
+	if !ok {
+		return result
+	}
+	storedEnv, _ := stored["env"].(map[string]interface{})
+
+	env := make(map[string]interface{}, len(liveEnv))
+	for key, value := range liveEnv {
+		env[key] = value
+	}
+	for key, value := range net.netEnv() {
+		if _, had := storedEnv[key]; !had && env[key] == value {
+			delete(env, key)
+		}
+	}
+	if len(env) == 0 && storedEnv == nil {
+		delete(result, "env")
+	} else {
+		result["env"] = env
+	}
+	return result
+}
+
+// sortedKeysOf 返回对象的键（已排序）
+func sortedKeysOf(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// OriginalSettingsProfile 首次运行时保存的 settings.json 快照，用于找回使用 cc-switch 之前的配置
+const OriginalSettingsProfile = "original-settings"
+
+// IsOriginalSettingsProfile 检查配置是否为首次运行时的快照（只读，可以删除）
+func IsOriginalSettingsProfile(name string) bool {
+	return name == OriginalSettingsProfile
+}
+
+// captureOriginalSettings 将 settings.json 保存为 original-settings 快照，已存在时不覆盖
+// 只在首次运行（尚未创建 default 配置）时调用，删除快照后也不会再次创建
+func (cm *ConfigManager) captureOriginalSettings() error {
+	snapshotPath := filepath.Join(cm.profilesDir, OriginalSettingsProfile+".json")
+	if _, err := os.Stat(snapshotPath); err == nil {
+		return nil
+	}
+
+	if err := cm.copyFile(cm.settingsFile, snapshotPath); err != nil {
+		return err
+	}
+
+	meta := &ProfileMetadata{DisplayName: "Settings before cc-switch", Origin: OriginSnapshot}
+	if err := cm.saveProfileMetadata(OriginalSettingsProfile, meta); err != nil {
+		return err
+	}
+	cm.stampProfile(OriginalSettingsProfile)
+	return nil
+}
+
+// CheckProfileModifiable 确保配置内容可以修改：不是只读系统配置，也不是 original-settings 快照
+func (cm *ConfigManager) CheckProfileModifiable(name string) error {
+	if err := cm.checkProfileWritable(name); err != nil {
+		return err
+	}
+	if IsOriginalSettingsProfile(name) {
+		return Lockedf("profile '%s' is a snapshot of your settings before cc-switch and cannot be modified; copy it with 'cc-switch cp %s <name>' to make changes", name, name)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// obsoletePermissionTools Claude Code 已更名的工具：旧名 -> 新名
+// 权限规则形如 "Tool" 或 "Tool(specifier)"，迁移时只替换工具名，保留括号中的内容
+// Claude Code 更名工具时只需在此添加条目
+var obsoletePermissionTools = map[string]string{
+	"View":             "Read",
+	"Replace":          "Write",
+	"GlobTool":         "Glob",
+	"GrepTool":         "Grep",
+	"LSTool":           "LS",
+	"ReadNotebook":     "NotebookRead",
+	"NotebookEditCell": "NotebookEdit",
+	"dispatch_agent":   "Task",
+}
+
+// PermissionMigration 一条需要迁移的权限规则
+type PermissionMigration struct {
+	Path string `json:"path"` // 如 "permissions.allow[2]"
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// MigratePermissionRule 返回规则迁移后的形式；规则未使用已更名的工具时 ok 为 false
+func MigratePermissionRule(rule string) (migrated string, ok bool) {
+	tool, rest := rule, ""
+	if i := strings.Index(rule, "("); i >= 0 {
+		tool, rest = rule[:i], rule[i:]
+	}
+	replacement, ok := obsoletePermissionTools[strings.TrimSpace(tool)]
+	if !ok {
+		return rule, false
+	}
+	return replacement + rest, true
+}
+
+// FindObsoletePermissions 列出 permissions.allow/deny 中使用已更名工具的规则
+func FindObsoletePermissions(content map[string]interface{}) []PermissionMigration {
+	var migrations []PermissionMigration
+	permissions, _ := content["permissions"].(map[string]interface{})
+	for _, field := range []string{"allow", "deny"} {
+		list, _ := permissions[field].([]interface{})
+		for i, item := range list {
+			rule, ok := item.(string)
+			if !ok {
+				continue
+			}
+			if migrated, ok := MigratePermissionRule(rule); ok {
+				migrations = append(migrations, PermissionMigration{
+					Path: fmt.Sprintf("permissions.%s[%d]", field, i),
+					Old:  rule,
+					New:  migrated,
+				})
+			}
+		}
+	}
+	return migrations
+}
+
+// migratePermissions 原地改写 permissions.allow/deny 中的旧规则；新规则已存在时直接删除旧规则，避免重复
+func migratePermissions(content map[string]interface{}) {
+	permissions, _ := content["permissions"].(map[string]interface{})
+	for _, field := range []string{"allow", "deny"} {
+		list, ok := permissions[field].([]interface{})
+		if !ok {
+			continue
+		}
+
+		seen := make(map[string]bool, len(list))
+		for _, item := range list {
+			if rule, ok := item.(string); ok {
+				if _, obsolete := MigratePermissionRule(rule); !obsolete {
+					seen[rule] = true
+				}
+			}
+		}
+
+		migrated := make([]interface{}, 0, len(list))
+		for _, item := range list {
+			rule, ok := item.(string)
+			if !ok {
+				migrated = append(migrated, item)
+				continue
+			}
+			if replacement, obsolete := MigratePermissionRule(rule); obsolete {
+				if seen[replacement] {
+					continue
+				}
+				seen[replacement] = true
+				migrated = append(migrated, replacement)
+				continue
+			}
+			migrated = append(migrated, rule)
+		}
+		permissions[field] = migrated
+	}
+}
+
+// MigrateProfilePermissions 改写配置中使用已更名工具的权限规则，并记录到活动日志
+// 没有需要迁移的规则时不写入文件，返回空列表
+func (cm *ConfigManager) MigrateProfilePermissions(name string) ([]PermissionMigration, error) {
+	content, _, err := cm.GetProfileContent(name)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := FindObsoletePermissions(content)
+	if len(migrations) == 0 {
+		return nil, nil
+	}
+
+	migratePermissions(content)
+	if err := cm.UpdateProfile(name, content); err != nil {
+		return nil, err
+	}
+
+	rules := make([]string, len(migrations))
+	for i, migration := range migrations {
+		rules[i] = migration.Old + " -> " + migration.New
+	}
+	cm.LogActivity(ActivityEntry{
+		Action:  ActivityMigrate,
+		Profile: name,
+		Detail:  strings.Join(rules, ", "),
+	})
+	return migrations, nil
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestObsoletePermissionToolsTable(t *testing.T) {
+	for old, replacement := range obsoletePermissionTools {
+		if old == replacement {
+			t.Errorf("%s is mapped to itself", old)
+		}
+		if strings.ContainsAny(old+replacement, "() ") {
+			t.Errorf("%s -> %s: entries are bare tool names", old, replacement)
+		}
+		// A replacement that is itself obsolete would need a second migration run
+		if _, chained := obsoletePermissionTools[replacement]; chained {
+			t.Errorf("%s -> %s: the replacement is also in the table", old, replacement)
+		}
+	}
+}
+
+func TestMigratePermissionRule(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string
+		ok   bool
+	}{
+		{rule: "View", want: "Read", ok: true},
+		{rule: "View(/etc/**)", want: "Read(/etc/**)", ok: true},
+		{rule: "dispatch_agent", want: "Task", ok: true},
+		{rule: "Read", want: "Read", ok: false},
+		{rule: "Bash(View:*)", want: "Bash(View:*)", ok: false},
+		{rule: "Viewer", want: "Viewer", ok: false},
+		{rule: "", want: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			got, ok := MigratePermissionRule(tt.rule)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("MigratePermissionRule(%q) = %q, %v, want %q, %v", tt.rule, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+// permissionsContent builds profile content with the given allow and deny lists
+func permissionsContent(allow, deny []interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"permissions": map[string]interface{}{"allow": allow, "deny": deny},
+	}
+}
+
+func TestFindObsoletePermissions(t *testing.T) {
+	content := permissionsContent(
+		[]interface{}{"Bash(ls:*)", "View", 42, "GrepTool(*.go)"},
+		[]interface{}{"Replace(/etc/**)"},
+	)
+	want := []PermissionMigration{
+		{Path: "permissions.allow[1]", Old: "View", New: "Read"},
+		{Path: "permissions.allow[3]", Old: "GrepTool(*.go)", New: "Grep(*.go)"},
+		{Path: "permissions.deny[0]", Old: "Replace(/etc/**)", New: "Write(/etc/**)"},
+	}
+	if got := FindObsoletePermissions(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindObsoletePermissions = %+v, want %+v", got, want)
+	}
+
+	for _, content := range []map[string]interface{}{nil, {}, {"permissions": "x"}} {
+		if got := FindObsoletePermissions(content); len(got) != 0 {
+			t.Errorf("FindObsoletePermissions(%v) = %+v, want none", content, got)
+		}
+	}
+}
+
+func TestMigratePermissions(t *testing.T) {
+	content := permissionsContent(
+		// Read already exists, so View is dropped rather than duplicated
+		[]interface{}{"View", "Read", "GlobTool", 42, "GlobTool"},
+		[]interface{}{"Bash(rm:*)"},
+	)
+	migratePermissions(content)
+
+	permissions := content["permissions"].(map[string]interface{})
+	if want := []interface{}{"Read", "Glob", 42}; !reflect.DeepEqual(permissions["allow"], want) {
+		t.Errorf("allow = %v, want %v", permissions["allow"], want)
+	}
+	if want := []interface{}{"Bash(rm:*)"}; !reflect.DeepEqual(permissions["deny"], want) {
+		t.Errorf("deny = %v, want %v", permissions["deny"], want)
+	}
+}
+
+func TestMigrateProfilePermissions(t *testing.T) {
+	cm := newTestManager(t)
+	if err := cm.CreateProfileWithContent("work", permissionsContent([]interface{}{"View", "Bash(ls:*)"}, []interface{}{})); err != nil {
+		t.Fatal(err)
+	}
+
+	migrations, err := cm.MigrateProfilePermissions("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 1 || migrations[0].New != "Read" {
+		t.Fatalf("migrations = %+v, want View -> Read", migrations)
+	}
+
+	content, _, err := cm.GetProfileContent("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := FindObsoletePermissions(content); len(got) != 0 {
+		t.Errorf("obsolete rules left after migration: %+v", got)
+	}
+	allow := content["permissions"].(map[string]interface{})["allow"]
+	if want := []interface{}{"Read", "Bash(ls:*)"}; !reflect.DeepEqual(allow, want) {
+		t.Errorf("allow = %v, want %v", allow, want)
+	}
+
+	entries, err := cm.ReadActivity(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Action != ActivityMigrate || entries[0].Detail != "View -> Read" {
+		t.Errorf("activity = %+v, want the migration recorded", entries)
+	}
+
+	// A second run finds nothing and records nothing
+	migrations, err = cm.MigrateProfilePermissions("work")
+	if err != nil || len(migrations) != 0 {
+		t.Errorf("second run = %+v, %v, want nothing to do", migrations, err)
+	}
+	all, err := cm.ReadActivity(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for _, entry := range all {
+		if entry.Action == ActivityMigrate {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("%d migration entries after the second run, want 1", count)
+	}
+}
+
+func TestValidateContentFlagsObsoletePermissions(t *testing.T) {
+	issues := ValidateContent(permissionsContent([]interface{}{"Bash(ls:*)"}, []interface{}{"View"}), false)
+	var found []ValidationIssue
+	for _, issue := range issues {
+		if issue.ID == IssuePermissionObsolete {
+			found = append(found, issue)
+		}
+	}
+	if len(found) != 1 || found[0].Path != "permissions.deny[0]" || found[0].Severity != SeverityWarning {
+		t.Errorf("issues = %+v, want one warning at permissions.deny[0]", found)
+	}
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"slices"
+	"strings"
+)
+
+// permissions 中可编辑的规则列表
+const (
+	PermissionAllow = "allow"
+	PermissionDeny  = "deny"
+)
+
+// ProfilePermissions 配置 permissions 中的 allow/deny 规则
+type ProfilePermissions struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// ValidatePermissionRule 对规则做基本检查：非空、以工具名开头、括号成对
+func ValidatePermissionRule(rule string) error {
+	if strings.TrimSpace(rule) == "" {
+		return Invalidf("permission rule cannot be empty")
+	}
+	if strings.HasPrefix(strings.TrimSpace(rule), "(") {
+		return Invalidf("permission rule '%s' has no tool name, e.g. Bash(git *)", rule)
+	}
+
+	depth := 0
+	for _, r := range rule {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return Invalidf("permission rule '%s' has an unmatched ')'", rule)
+			}
+		}
+	}
+	if depth != 0 {
+		return Invalidf("permission rule '%s' has an unmatched '('", rule)
+	}
+	return nil
+}
+
+// permissionsOf 读取内容中的 allow/deny 规则，非字符串项被忽略
+func permissionsOf(content map[string]interface{}) ProfilePermissions {
+	perms := ProfilePermissions{Allow: []string{}, Deny: []string{}}
+	permissions, _ := content["permissions"].(map[string]interface{})
+	for _, field := range []string{PermissionAllow, PermissionDeny} {
+		list, _ := permissions[field].([]interface{})
+		for _, item := range list {
+			if rule, ok := item.(string); ok {
+				if field == PermissionAllow {
+					perms.Allow = append(perms.Allow, rule)
+				} else {
+					perms.Deny = append(perms.Deny, rule)
+				}
+			}
+		}
+	}
+	return perms
+}
+
+// GetProfilePermissions 返回配置的 allow/deny 规则
+func (cm *ConfigManager) GetProfilePermissions(name string) (ProfilePermissions, error) {
+	content, _, err := cm.GetProfileContent(name)
+	if err != nil {
+		return ProfilePermissions{}, err
+	}
+	return permissionsOf(content), nil
+}
+
+// AddProfilePermission 向 allow 或 deny 添加一条规则，规则已存在时不修改并返回 false
+func (cm *ConfigManager) AddProfilePermission(name, list, rule string) (bool, error) {
+	if list != PermissionAllow && list != PermissionDeny {
+		return false, Invalidf("unknown permission list '%s' (use allow or deny)", list)
+	}
+	if err := ValidatePermissionRule(rule); err != nil {
+		return false, err
+	}
+
+	content, _, err := cm.GetProfileContent(name)
+	if err != nil {
+		return false, err
+	}
+
+	perms := permissionsOf(content)
+	target := &perms.Allow
+	if list == PermissionDeny {
+		target = &perms.Deny
+	}
+	if slices.Contains(*target, rule) {
+		return false, nil
+	}
+	*target = append(*target, rule)
+
+	return true, cm.saveProfilePermissions(name, content, perms)
+}
+
+// RemoveProfilePermission 从 allow 和 deny 中删除一条规则，返回规则所在的列表
+// 规则不在任何列表中时返回 ErrNotFound
+func (cm *ConfigManager) RemoveProfilePermission(name, rule string) ([]string, error) {
+	content, _, err := cm.GetProfileContent(name)
+	if err != nil {
+		return nil, err
+	}
+
+	perms := permissionsOf(content)
+	var removedFrom []string
+	for _, entry := range []struct {
+		list  string
+		rules *[]string
+	}{{PermissionAllow, &perms.Allow}, {PermissionDeny, &perms.Deny}} {
+		before := len(*entry.rules)
+		*entry.rules = slices.DeleteFunc(*entry.rules, func(r string) bool { return r == rule })
+		if len(*entry.rules) != before {
+			removedFrom = append(removedFrom, entry.list)
+		}
+	}
+	if len(removedFrom) == 0 {
+		return nil, NotFoundf("configuration '%s' has no permission rule '%s'", name, rule)
+	}
+
+	return removedFrom, cm.saveProfilePermissions(name, content, perms)
+}
+
+// CopyTemplatePermissions 用模板的 allow/deny 规则整体替换配置中的规则，返回新的规则
+func (cm *ConfigManager) CopyTemplatePermissions(name, template string) (ProfilePermissions, error) {
+	templateContent, err := cm.GetTemplateContent(template)
+	if err != nil {
+		return ProfilePermissions{}, err
+	}
+	content, _, err := cm.GetProfileContent(name)
+	if err != nil {
+		return ProfilePermissions{}, err
+	}
+
+	perms := permissionsOf(templateContent).deduplicated()
+	return perms, cm.saveProfilePermissions(name, content, perms)
+}
+
+// saveProfilePermissions 写回 allow/deny（去重，保留 permissions 中的其他字段）
+// 通过 UpdateProfile 保存，当前配置会同步到 settings.json
+func (cm *ConfigManager) saveProfilePermissions(name string, content map[string]interface{}, perms ProfilePermissions) error {
+	permissions, ok := content["permissions"].(map[string]interface{})
+	if !ok {
+		permissions = make(map[string]interface{})
+		content["permissions"] = permissions
+	}
+	perms = perms.deduplicated()
+	for field, rules := range map[string][]string{PermissionAllow: perms.Allow, PermissionDeny: perms.Deny} {
+		list := make([]interface{}, len(rules))
+		for i, rule := range rules {
+			list[i] = rule
+		}
+		permissions[field] = list
+	}
+
+	return cm.UpdateProfile(name, content)
+}
+
+// deduplicated 返回按首次出现顺序去重后的规则
+func (p ProfilePermissions) deduplicated() ProfilePermissions {
+	unique := func(rules []string) []string {
+		seen := make(map[string]bool, len(rules))
+		result := make([]string, 0, len(rules))
+		for _, rule := range rules {
+			if !seen[rule] {
+				seen[rule] = true
+				result = append(result, rule)
+			}
+		}
+		return result
+	}
+	return ProfilePermissions{Allow: unique(p.Allow), Deny: unique(p.Deny)}
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestValidatePermissionRule(t *testing.T) {
+	tests := []struct {
+		rule  string
+		valid bool
+	}{
+		{"Bash", true},
+		{"Bash(git *)", true},
+		{"Bash(echo (nested))", true},
+		{"mcp__server__tool", true},
+		{"", false},
+		{"   ", false},
+		{"(git *)", false},
+		{"Bash(git *", false},
+		{"Bash git *)", false},
+		{"Bash())(", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			err := ValidatePermissionRule(tt.rule)
+			if tt.valid && err != nil {
+				t.Errorf("ValidatePermissionRule(%q) = %v, want valid", tt.rule, err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalid) {
+				t.Errorf("ValidatePermissionRule(%q) = %v, want ErrInvalid", tt.rule, err)
+			}
+		})
+	}
+}
+
+// setupPermissionsProfile creates work with some rules and other permission settings
+func setupPermissionsProfile(t *testing.T) *ConfigManager {
+	t.Helper()
+	cm := newTestManager(t)
+	content := map[string]interface{}{
+		"model": "opus",
+		"permissions": map[string]interface{}{
+			"allow":       []interface{}{"Bash(git *)", "Read"},
+			"deny":        []interface{}{"Read"},
+			"defaultMode": "acceptEdits",
+		},
+	}
+	if err := cm.CreateProfileWithContent("work", content); err != nil {
+		t.Fatal(err)
+	}
+	return cm
+}
+
+func TestAddProfilePermission(t *testing.T) {
+	cm := setupPermissionsProfile(t)
+
+	added, err := cm.AddProfilePermission("work", PermissionDeny, "Bash(rm *)")
+	if err != nil || !added {
+		t.Fatalf("AddProfilePermission = %v, %v, want added", added, err)
+	}
+
+	// A rule already in the list is not added twice
+	added, err = cm.AddProfilePermission("work", PermissionAllow, "Read")
+	if err != nil || added {
+		t.Errorf("adding a duplicate = %v, %v, want not added", added, err)
+	}
+
+	perms, err := cm.GetProfilePermissions("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ProfilePermissions{Allow: []string{"Bash(git *)", "Read"}, Deny: []string{"Read", "Bash(rm *)"}}
+	if !reflect.DeepEqual(perms, want) {
+		t.Errorf("permissions = %+v, want %+v", perms, want)
+	}
+
+	// The rest of the profile is left alone
+	content, _, err := cm.GetProfileContent("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content["model"] != "opus" || content["permissions"].(map[string]interface{})["defaultMode"] != "acceptEdits" {
+		t.Errorf("content = %v, want model and defaultMode kept", content)
+	}
+
+	for _, tt := range []struct{ list, rule string }{{"ask", "Read"}, {PermissionAllow, "Bash(git *"}} {
+		if _, err := cm.AddProfilePermission("work", tt.list, tt.rule); !errors.Is(err, ErrInvalid) {
+			t.Errorf("AddProfilePermission(%s, %q) = %v, want ErrInvalid", tt.list, tt.rule, err)
+		}
+	}
+	if _, err := cm.AddProfilePermission("missing", PermissionAllow, "Read"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("adding to a missing profile = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRemoveProfilePermission(t *testing.T) {
+	cm := setupPermissionsProfile(t)
+
+	removedFrom, err := cm.RemoveProfilePermission("work", "Read")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(removedFrom, []string{PermissionAllow, PermissionDeny}) {
+		t.Errorf("removed from %v, want both lists", removedFrom)
+	}
+	perms, _ := cm.GetProfilePermissions("work")
+	if want := (ProfilePermissions{Allow: []string{"Bash(git *)"}, Deny: []string{}}); !reflect.DeepEqual(perms, want) {
+		t.Errorf("permissions = %+v, want %+v", perms, want)
+	}
+
+	before, _, _ := cm.GetProfileContent("work")
+	if _, err := cm.RemoveProfilePermission("work", "Write"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("removing a missing rule = %v, want ErrNotFound", err)
+	}
+	if after, _, _ := cm.GetProfileContent("work"); !reflect.DeepEqual(after, before) {
+		t.Error("removing a missing rule changed the profile")
+	}
+}
+
+func TestCopyTemplatePermissions(t *testing.T) {
+	cm := setupPermissionsProfile(t)
+	if err := cm.CreateTemplate("locked"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.UpdateTemplate("locked", map[string]interface{}{
+		"permissions": map[string]interface{}{
+			"allow": []interface{}{"Read", "Grep", "Read"},
+			"deny":  []interface{}{"Bash"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	perms, err := cm.CopyTemplatePermissions("work", "locked")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ProfilePermissions{Allow: []string{"Read", "Grep"}, Deny: []string{"Bash"}}
+	if !reflect.DeepEqual(perms, want) {
+		t.Errorf("returned %+v, want %+v", perms, want)
+	}
+	if stored, _ := cm.GetProfilePermissions("work"); !reflect.DeepEqual(stored, want) {
+		t.Errorf("stored %+v, want %+v", stored, want)
+	}
+
+	if _, err := cm.CopyTemplatePermissions("work", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("copying from a missing template = %v, want ErrNotFound", err)
+	}
+}
+
+func TestPermissionEditsSyncCurrentProfile(t *testing.T) {
+	cm := setupPermissionsProfile(t)
+	if err := cm.UseProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+
+	settingsAllow := func() []interface{} {
+		t.Helper()
+		data, err := os.ReadFile(cm.settingsFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var settings map[string]interface{}
+		if err := json.Unmarshal(data, &settings); err != nil {
+			t.Fatal(err)
+		}
+		allow, _ := settings["permissions"].(map[string]interface{})["allow"].([]interface{})
+		return allow
+	}
+
+	if _, err := cm.AddProfilePermission("work", PermissionAllow, "Grep"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := settingsAllow(), []interface{}{"Bash(git *)", "Read", "Grep"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("settings allow = %v, want %v", got, want)
+	}
+
+	if _, err := cm.RemoveProfilePermission("work", "Bash(git *)"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := settingsAllow(), []interface{}{"Read", "Grep"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("settings allow = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,239 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// builtinProvidersJSON 内置的提供商预设目录，格式与用户的 .providers.json 相同
+//
+//go:embed builtin_providers.json
+var builtinProvidersJSON []byte
+
+// providersFileName 用户提供商预设文件名，位于数据目录下，同名预设覆盖内置预设
+// 以点开头，避免被当作名为 providers 的配置列出
+const providersFileName = ".providers.json"
+
+// 测试 API 时使用的认证方式
+const (
+	AuthStyleBearer = "bearer"    // Authorization: Bearer <token>
+	AuthStyleAPIKey = "x-api-key" // x-api-key: <key>
+)
+
+// 提供商预设来源
+const (
+	ProviderBuiltin = "builtin"
+	ProviderUser    = "user"
+)
+
+// CustomHeadersKey Claude Code 读取的附加请求头 env 键，每行一个 "Name: value"（test 也接受 JSON 对象）
+const CustomHeadersKey = "ANTHROPIC_CUSTOM_HEADERS"
+
+// ProviderPreset Anthropic 兼容网关的预设：基础 URL、需要填写的 env 键以及附加的 env 和请求头
+type ProviderPreset struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	BaseURL     string            `json:"base_url,omitempty"`
+	RequiredEnv []string          `json:"required_env,omitempty"` // 创建时留空、交互模式下提示填写的 env 键
+	Env         map[string]string `json:"env,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`    // 写入 ANTHROPIC_CUSTOM_HEADERS
+	AuthStyle   string            `json:"auth_style,omitempty"` // bearer（默认）或 x-api-key
+	Source      string            `json:"source"`               // builtin 或 user
+}
+
+// ProvidersPath 返回用户提供商预设文件路径
+func (cm *ConfigManager) ProvidersPath() string {
+	return cm.dataFile(providersFileName)
+}
+
+// parseProviders 解析提供商预设文件（预设名 -> 预设）
+func parseProviders(data []byte, source, path string) (map[string]ProviderPreset, error) {
+	var presets map[string]ProviderPreset
+	if err := json.Unmarshal(data, &presets); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	for name, preset := range presets {
+		switch preset.AuthStyle {
+		case "":
+			preset.AuthStyle = AuthStyleBearer
+		case AuthStyleBearer, AuthStyleAPIKey:
+		default:
+			return nil, Invalidf("%s: provider '%s' has invalid auth_style '%s' (valid values: %s, %s)", path, name, preset.AuthStyle, AuthStyleBearer, AuthStyleAPIKey)
+		}
+		preset.Name = name
+		preset.Source = source
+		presets[name] = preset
+	}
+	return presets, nil
+}
+
+// ListProviders 返回全部提供商预设（按名称排序），用户预设覆盖同名内置预设
+func (cm *ConfigManager) ListProviders() ([]ProviderPreset, error) {
+	merged, err := parseProviders(builtinProvidersJSON, ProviderBuiltin, "built-in providers")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(cm.ProvidersPath())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read providers: %w", err)
+	}
+	if err == nil {
+		user, err := parseProviders(data, ProviderUser, cm.ProvidersPath())
+		if err != nil {
+			return nil, err
+		}
+		for name, preset := range user {
+			merged[name] = preset
+		}
+	}
+
+	presets := make([]ProviderPreset, 0, len(merged))
+	for _, preset := range merged {
+		presets = append(presets, preset)
+	}
+	sort.Slice(presets, func(i, j int) bool { return presets[i].Name < presets[j].Name })
+	return presets, nil
+}
+
+// GetProvider 按名称查找提供商预设
+func (cm *ConfigManager) GetProvider(name string) (*ProviderPreset, error) {
+	presets, err := cm.ListProviders()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for i := range presets {
+		if presets[i].Name == name {
+			return &presets[i], nil
+		}
+		names = append(names, presets[i].Name)
+	}
+	return nil, NotFoundf("unknown provider '%s' (available: %s)", name, strings.Join(names, ", "))
+}
+
+// ApplyProviderPreset 将预设合并到模板内容上：设置基础 URL、附加 env 与请求头，
+// 必填键不存在时以空值加入，使交互模式提示填写。预设指定了必填键时，模板中其余
+// 为空的凭据键会被移除，避免同时提示两种凭据
+func ApplyProviderPreset(content map[string]interface{}, preset *ProviderPreset) {
+	env, ok := content["env"].(map[string]interface{})
+	if !ok {
+		env = make(map[string]interface{})
+		content["env"] = env
+	}
+
+	if len(preset.RequiredEnv) > 0 {
+		required := make(map[string]bool, len(preset.RequiredEnv))
+		for _, key := range preset.RequiredEnv {
+			required[key] = true
+		}
+		for key, value := range env {
+			if IsTokenKey(key) && value == "" && !required[key] {
+				delete(env, key)
+			}
+		}
+	}
+
+	if preset.BaseURL != "" {
+		env["ANTHROPIC_BASE_URL"] = preset.BaseURL
+	}
+	for key, value := range preset.Env {
+		env[key] = value
+	}
+	if len(preset.Headers) > 0 {
+		names := make([]string, 0, len(preset.Headers))
+		for name := range preset.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		lines := make([]string, 0, len(names))
+		for _, name := range names {
+			lines = append(lines, name+": "+preset.Headers[name])
+		}
+		env[CustomHeadersKey] = strings.Join(lines, "\n")
+	}
+	for _, key := range preset.RequiredEnv {
+		if _, exists := env[key]; !exists {
+			env[key] = ""
+		}
+	}
+}
+
+// CreateProfileFromProvider 将提供商预设合并到模板上后创建配置，并在元数据中记录提供商
+// uiProvider 支持交互输入时提示填写空字段（allFields 为 true 时提示所有字符串字段），否则必填项留空
+func (cm *ConfigManager) CreateProfileFromProvider(name, templateName, provider string, uiProvider interface{}, allFields bool) error {
+	if err := cm.validateProfileName(name); err != nil {
+		return err
+	}
+	if cm.ProfileExists(name) {
+		return Conflictf("profile '%s' already exists", name)
+	}
+
+	preset, err := cm.GetProvider(provider)
+	if err != nil {
+		return err
+	}
+
+	template, err := cm.GetTemplateContent(templateName)
+	if err != nil {
+		return err
+	}
+	ApplyProviderPreset(template, preset)
+
+	content := template
+	if ui, ok := uiProvider.(templateFieldUI); ok {
+		var fields []TemplateField
+		if allFields {
+			fields = cm.DetectStringFields(template)
+		} else {
+			fields = cm.DetectEmptyFields(template)
+		}
+		if len(fields) > 0 {
+			content, err = cm.promptTemplateFields(template, fields, ui)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := cm.CreateProfileWithContent(name, content); err != nil {
+		return err
+	}
+
+	cm.setProfileTemplate(name, templateName)
+	cm.setProfileOrigin(name, OriginTemplate(templateName))
+	cm.setProfileProvider(name, preset.Name)
+	return nil
+}
+
+// setProfileProvider 记录配置所用的提供商预设
+func (cm *ConfigManager) setProfileProvider(name, provider string) {
+	meta, err := cm.GetProfileMetadata(name)
+	if err != nil {
+		meta = &ProfileMetadata{}
+	}
+
+	meta.Provider = provider
+	if err := cm.saveProfileMetadata(name, meta); err != nil {
+		fmt.Fprintf(cm.warnings, "Warning: %v\n", err)
+	}
+}
+
+// ProfileAuthStyle 返回测试配置时使用的认证方式：配置记录了提供商且预设仍存在时使用预设的方式，否则为 bearer
+func (cm *ConfigManager) ProfileAuthStyle(name string) string {
+	meta, err := cm.GetProfileMetadata(name)
+	if err != nil || meta.Provider == "" {
+		return AuthStyleBearer
+	}
+	preset, err := cm.GetProvider(meta.Provider)
+	if err != nil {
+		return AuthStyleBearer
+	}
+	return preset.AuthStyle
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// scratchPrefix 临时配置的名称前缀
+const scratchPrefix = "scratch-"
+
+// IsScratchProfile 判断配置是否为临时配置（元数据中标记为 ephemeral）
+func (cm *ConfigManager) IsScratchProfile(name string) bool {
+	meta, err := cm.GetProfileMetadata(name)
+	return err == nil && meta.Ephemeral
+}
+
+// CreateScratchProfile 创建临时配置：复制当前配置，空配置模式或没有当前配置时使用默认模板
+// 临时配置在切换到其他配置后自动删除，返回新配置名
+func (cm *ConfigManager) CreateScratchProfile() (string, error) {
+	name := scratchPrefix + time.Now().Format("20060102-150405")
+	for i := 2; cm.ProfileExists(name); i++ {
+		name = fmt.Sprintf("%s%s-%d", scratchPrefix, time.Now().Format("20060102-150405"), i)
+	}
+
+	current, _ := cm.getCurrentProfile()
+	if current != "" && !cm.IsEmptyMode() && cm.ProfileExists(current) {
+		if err := cm.CopyProfile(current, name); err != nil {
+			return "", err
+		}
+	} else {
+		templateName, warning := cm.ResolveTemplate("")
+		if warning != "" {
+			fmt.Fprintf(cm.warnings, "Warning: %s\n", warning)
+		}
+		if err := cm.CreateProfileFromTemplate(name, templateName); err != nil {
+			return "", err
+		}
+	}
+
+	if err := cm.setScratch(name, true); err != nil {
+		cm.DeleteProfile(name)
+		return "", err
+	}
+	return name, nil
+}
+
+// KeepScratchProfile 取消临时标记，使配置不再被自动删除
+func (cm *ConfigManager) KeepScratchProfile(name string) error {
+	if !cm.ProfileExists(name) {
+		return NotFoundf("configuration '%s' does not exist", name)
+	}
+	if !cm.IsScratchProfile(name) {
+		return Invalidf("configuration '%s' is not a scratch configuration", name)
+	}
+	return cm.setScratch(name, false)
+}
+
+// ClearScratchProfiles 删除所有临时配置；当前激活的（包括空配置模式下可恢复的）和唯一剩下的配置除外
+func (cm *ConfigManager) ClearScratchProfiles() (removed []string, kept []string, err error) {
+	profiles, err := cm.ListProfiles()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current, _ := cm.getCurrentProfile()
+	remaining := len(profiles)
+	for _, profile := range profiles {
+		if !cm.IsScratchProfile(profile.Name) {
+			continue
+		}
+		if profile.Name == current || remaining <= 1 {
+			kept = append(kept, profile.Name)
+			continue
+		}
+		if err := cm.DeleteProfile(profile.Name); err != nil {
+			return removed, kept, fmt.Errorf("failed to delete scratch profile '%s': %w", profile.Name, err)
+		}
+		removed = append(removed, profile.Name)
+		remaining--
+	}
+	return removed, kept, nil
+}
+
+// cleanupScratchProfile 切换离开临时配置后将其删除；失败只给出警告，不影响切换结果
+func (cm *ConfigManager) cleanupScratchProfile(name string) {
+	if !cm.IsScratchProfile(name) {
+		return
+	}
+	if current, _ := cm.getCurrentProfile(); current == name {
+		return
+	}
+	if err := cm.DeleteProfile(name); err != nil {
+		fmt.Fprintf(cm.warnings, "Warning: failed to remove scratch profile '%s': %v\n", name, err)
+	}
+}
+
+// setScratch 在元数据中设置或取消临时标记
+func (cm *ConfigManager) setScratch(name string, ephemeral bool) error {
+	meta, err := cm.GetProfileMetadata(name)
+	if err != nil {
+		meta = &ProfileMetadata{}
+	}
+	meta.Ephemeral = ephemeral
+	return cm.saveProfileMetadata(name, meta)
+}
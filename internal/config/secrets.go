@@ -0,0 +1,486 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/HoBeedzc/cc-switch/internal/common"
+
+	"golang.org/x/term"
+)
+
+const (
+	// SecretRefPrefix 配置值以此前缀开头时表示引用密钥库中的密钥，如 "@secret:work-token"
+	SecretRefPrefix = "@secret:"
+
+	// SecretsPassphraseEnv 密钥库口令的环境变量（未设置时在终端中提示输入）
+	SecretsPassphraseEnv = "CC_SWITCH_SECRETS_PASSPHRASE"
+
+	// secretsFileName 加密密钥库文件名，位于数据目录下
+	secretsFileName = ".secrets.enc"
+)
+
+// secretNamePattern 密钥名称只允许字母、数字、点、下划线和连字符
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// secretsFile 密钥库文件格式，内容为 AES-256-GCM 加密后的 JSON 对象
+type secretsFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// MissingSecretError 配置引用了不存在的密钥
+type MissingSecretError struct {
+	Secret string
+	Path   string
+}
+
+func (e *MissingSecretError) Error() string {
+	return fmt.Sprintf("secret '%s' referenced at '%s' is not defined (use 'cc-switch secret set %s')", e.Secret, e.Path, e.Secret)
+}
+
+func (e *MissingSecretError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ParseSecretReference 解析密钥引用，返回密钥名称及是否为引用
+func ParseSecretReference(value string) (string, bool) {
+	if !strings.HasPrefix(value, SecretRefPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(value, SecretRefPrefix), true
+}
+
+// validateSecretName 验证密钥名称
+func validateSecretName(name string) error {
+	if !secretNamePattern.MatchString(name) {
+		return Invalidf("invalid secret name '%s': use letters, digits, '.', '_' or '-'", name)
+	}
+	return nil
+}
+
+// secretsPath 返回密钥库文件路径
+func (cm *ConfigManager) secretsPath() string {
+	return cm.dataFile(secretsFileName)
+}
+
+// SecretsStoreExists 检查密钥库是否已创建
+func (cm *ConfigManager) SecretsStoreExists() bool {
+	_, err := os.Stat(cm.secretsPath())
+	return err == nil
+}
+
+// SetSecretsPassphrase 设置本次运行使用的密钥库口令
+func (cm *ConfigManager) SetSecretsPassphrase(passphrase string) {
+	cm.secretsPassphrase = passphrase
+}
+
+// PromptPassphrase 在终端中提示输入密钥库口令，是 Options.Passphrase 的默认值
+func PromptPassphrase() (string, error) {
+	if !term.IsTerminal(int(syscall.Stdin)) {
+		return "", Lockedf("secrets store is locked: set %s to its passphrase", SecretsPassphraseEnv)
+	}
+
+	fmt.Fprint(os.Stderr, "Secrets passphrase: ")
+	input, err := term.ReadPassword(int(syscall.Stdin))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return string(input), nil
+}
+
+// secretsPassphraseValue 获取密钥库口令：已设置的口令 > 环境变量 > Options.Passphrase（默认终端输入）
+func (cm *ConfigManager) secretsPassphraseValue() (string, error) {
+	if cm.secretsPassphrase != "" {
+		return cm.secretsPassphrase, nil
+	}
+
+	if passphrase := os.Getenv(SecretsPassphraseEnv); passphrase != "" {
+		cm.secretsPassphrase = passphrase
+		return passphrase, nil
+	}
+
+	passphrase, err := cm.passphraseSource()
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", Invalidf("passphrase cannot be empty")
+	}
+
+	cm.secretsPassphrase = passphrase
+	return passphrase, nil
+}
+
+// loadSecrets 解密并读取密钥库（密钥库不存在时返回空集合，无需口令）
+func (cm *ConfigManager) loadSecrets() (map[string]string, error) {
+	data, err := os.ReadFile(cm.secretsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]string), nil
+		}
+		return nil, fmt.Errorf("failed to read secrets store: %w", err)
+	}
+
+	var file secretsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets store: %w", err)
+	}
+
+	passphrase, err := cm.secretsPassphraseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := common.DecryptData(&common.EncryptionData{
+		Salt:      file.Salt,
+		Nonce:     file.Nonce,
+		Encrypted: file.Data,
+	}, passphrase)
+	if err != nil {
+		// 口令错误时不缓存，便于调用方重新设置
+		cm.secretsPassphrase = ""
+		return nil, fmt.Errorf("failed to unlock secrets store (wrong passphrase?)")
+	}
+
+	secrets := make(map[string]string)
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets store: %w", err)
+	}
+
+	return secrets, nil
+}
+
+// saveSecrets 加密并原子性保存密钥库
+func (cm *ConfigManager) saveSecrets(secrets map[string]string) error {
+	passphrase, err := cm.secretsPassphraseValue()
+	if err != nil {
+		return err
+	}
+
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return fmt.Errorf("failed to marshal secrets: %w", err)
+	}
+
+	encrypted, err := common.EncryptData(plain, passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt secrets: %w", err)
+	}
+
+	data, err := json.MarshalIndent(&secretsFile{
+		Version: 1,
+		Salt:    encrypted.Salt,
+		Nonce:   encrypted.Nonce,
+		Data:    encrypted.Encrypted,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal secrets store: %w", err)
+	}
+
+	if err := cm.fs.WriteFile(cm.secretsPath(), data, 0600, false); err != nil {
+		return fmt.Errorf("failed to save secrets store: %w", err)
+	}
+
+	return nil
+}
+
+// SetSecret 添加或更新密钥
+func (cm *ConfigManager) SetSecret(name, value string) error {
+	if err := validateSecretName(name); err != nil {
+		return err
+	}
+	if value == "" {
+		return Invalidf("secret value cannot be empty")
+	}
+
+	secrets, err := cm.loadSecrets()
+	if err != nil {
+		return err
+	}
+
+	secrets[name] = value
+	return cm.saveSecrets(secrets)
+}
+
+// GetSecret 获取密钥值
+func (cm *ConfigManager) GetSecret(name string) (string, error) {
+	secrets, err := cm.loadSecrets()
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := secrets[name]
+	if !ok {
+		return "", NotFoundf("secret '%s' does not exist", name)
+	}
+	return value, nil
+}
+
+// ListSecrets 列出所有密钥名称（已排序）
+func (cm *ConfigManager) ListSecrets() ([]string, error) {
+	secrets, err := cm.loadSecrets()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// RemoveSecret 删除密钥，仍被配置引用时拒绝删除
+func (cm *ConfigManager) RemoveSecret(name string) error {
+	secrets, err := cm.loadSecrets()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := secrets[name]; !ok {
+		return NotFoundf("secret '%s' does not exist", name)
+	}
+
+	if users := cm.profilesReferencingSecret(name); len(users) > 0 {
+		return fmt.Errorf("secret '%s' is still referenced by: %s", name, strings.Join(users, ", "))
+	}
+
+	delete(secrets, name)
+	return cm.saveSecrets(secrets)
+}
+
+// MergeSecrets 将导入的密钥加入密钥库，已存在的密钥保持不变
+// 返回新增的密钥名称，以及本地值与导入值不同的密钥名称
+func (cm *ConfigManager) MergeSecrets(incoming map[string]string) ([]string, []string, error) {
+	if len(incoming) == 0 {
+		return nil, nil, nil
+	}
+
+	secrets, err := cm.loadSecrets()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var added, conflicts []string
+	for name, value := range incoming {
+		if err := validateSecretName(name); err != nil {
+			return nil, nil, err
+		}
+		existing, ok := secrets[name]
+		switch {
+		case !ok:
+			secrets[name] = value
+			added = append(added, name)
+		case existing != value:
+			conflicts = append(conflicts, name)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(conflicts)
+
+	if len(added) > 0 {
+		if err := cm.saveSecrets(secrets); err != nil {
+			return nil, nil, err
+		}
+	}
+	return added, conflicts, nil
+}
+
+// profilesReferencingSecret 返回引用指定密钥的配置列表
+func (cm *ConfigManager) profilesReferencingSecret(name string) []string {
+	profiles, err := cm.ListProfiles()
+	if err != nil {
+		return nil
+	}
+
+	var users []string
+	for _, profile := range profiles {
+		content, _, err := cm.GetProfileContent(profile.Name)
+		if err != nil {
+			continue
+		}
+		for _, secret := range FindSecretReferences(content) {
+			if secret == name {
+				users = append(users, profile.Name)
+				break
+			}
+		}
+	}
+	return users
+}
+
+// FindSecretReferences 查找内容中的所有密钥引用，返回 字段路径 -> 密钥名称
+func FindSecretReferences(content map[string]interface{}) map[string]string {
+	refs := make(map[string]string)
+	collectSecretReferences(content, "", refs)
+	return refs
+}
+
+// collectSecretReferences 递归收集密钥引用
+func collectSecretReferences(content map[string]interface{}, prefix string, refs map[string]string) {
+	for key, value := range content {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			collectSecretReferences(v, path, refs)
+		case string:
+			if name, ok := ParseSecretReference(v); ok {
+				refs[path] = name
+			}
+		}
+	}
+}
+
+// ResolveSecretReferences 返回将密钥引用替换为实际值后的内容副本
+// 内容不含引用时直接返回原内容，不需要口令
+func (cm *ConfigManager) ResolveSecretReferences(content map[string]interface{}) (map[string]interface{}, error) {
+	refs := FindSecretReferences(content)
+	if len(refs) == 0 {
+		return content, nil
+	}
+
+	secrets, err := cm.loadSecrets()
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(refs))
+	for path := range refs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	resolved := cm.deepCopyMap(content)
+	for _, path := range paths {
+		value, ok := secrets[refs[path]]
+		if !ok {
+			return nil, &MissingSecretError{Secret: refs[path], Path: path}
+		}
+		cm.setNestedValue(resolved, path, value)
+	}
+
+	return resolved, nil
+}
+
+// restoreSecretReferences 将回写内容中由密钥解析出的值还原为引用
+// live 中的值与密钥一致时还原引用；值已被修改时保留新值并给出警告；
+// 密钥库无法解锁时一律保留引用，避免明文写入配置文件
+func (cm *ConfigManager) restoreSecretReferences(stored, live map[string]interface{}) map[string]interface{} {
+	refs := FindSecretReferences(stored)
+	if len(refs) == 0 {
+		return live
+	}
+
+	secrets, err := cm.loadSecrets()
+	if err != nil {
+		fmt.Fprintf(cm.warnings, "Warning: %v; keeping secret references unchanged\n", err)
+		secrets = nil
+	}
+
+	restored := cm.deepCopyMap(live)
+	for path, name := range refs {
+		current, exists := lookupNestedValue(restored, path)
+		if !exists {
+			continue
+		}
+		if value, ok := secrets[name]; secrets != nil && (!ok || current != value) {
+			fmt.Fprintf(cm.warnings, "Warning: '%s' no longer matches secret '%s'; the new value is stored in the profile\n", path, name)
+			continue
+		}
+		cm.setNestedValue(restored, path, SecretRefPrefix+name)
+	}
+
+	return restored
+}
+
+// lookupNestedValue 获取嵌套路径上的值
+func lookupNestedValue(content map[string]interface{}, path string) (interface{}, bool) {
+	parts := strings.Split(path, ".")
+	current := content
+	for _, part := range parts[:len(parts)-1] {
+		nested, ok := current[part].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current = nested
+	}
+	value, ok := current[parts[len(parts)-1]]
+	return value, ok
+}
+
+// writeSettingsFromProfile 将配置内容（解析密钥引用后）原子性写入 settings.json
+func (cm *ConfigManager) writeSettingsFromProfile(profilePath string) error {
+	if err := cm.checkManagedPath(profilePath); err != nil {
+		return err
+	}
+	content, err := readJSONFile(profilePath)
+	if err != nil {
+		return err
+	}
+
+	_, hasNet := content[NetKey]
+	if hasNet || len(FindSecretReferences(content)) > 0 {
+		resolved, err := cm.ResolveSecretReferences(content)
+		if err != nil {
+			return err
+		}
+		return cm.writeConfigFile(cm.settingsFile, MaterializeContent(resolved))
+	}
+
+	// 不含引用和 _net 时按原样复制，保留原始格式（copyFile 为原子写入）
+	return cm.copyFile(profilePath, cm.settingsFile)
+}
+
+// backfillProfileFromSettings 将 settings.json 回写到配置，并还原其中的密钥引用和 _net
+func (cm *ConfigManager) backfillProfileFromSettings(name string) error {
+	profilePath := filepath.Join(cm.profilesDir, name+".json")
+
+	stored, err := readJSONFile(profilePath)
+	if err != nil {
+		return cm.copyFile(cm.settingsFile, profilePath)
+	}
+	_, hasNet := stored[NetKey]
+	hasRefs := len(FindSecretReferences(stored)) > 0
+	if !hasNet && !hasRefs {
+		return cm.copyFile(cm.settingsFile, profilePath)
+	}
+
+	live, err := readJSONFile(cm.settingsFile)
+	if err != nil {
+		return err
+	}
+	if hasRefs {
+		live = cm.restoreSecretReferences(stored, live)
+	}
+
+	return cm.writeConfigFile(profilePath, restoreNetSettings(stored, live))
+}
+
+// readJSONFile 读取 JSON 对象文件
+func readJSONFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var content map[string]interface{}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, Invalidf("invalid JSON format in %s: %w", path, err)
+	}
+	return content, nil
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// shell 环境变量与 settings.json 中 env 的比较结果
+const (
+	EnvMatches     = "matches"      // 值相同
+	EnvDiffers     = "differs"      // 两边都设置了，但值不同
+	EnvShellOnly   = "shell_only"   // 只在 shell 中设置
+	EnvProfileOnly = "profile_only" // 只在配置中设置，shell 未设置
+)
+
+// shellOnlyPrefix 只在 shell 中设置时也需要提示的变量前缀，配置的凭据键名总是包含在内
+// CLAUDE_CODE_* 大多由 Claude Code 自身在子进程中设置，不在此列
+const shellOnlyPrefix = "ANTHROPIC_"
+
+// EnvComparison 单个环境变量的比较结果，不包含值，避免泄露凭据
+type EnvComparison struct {
+	Key    string `json:"key"`
+	Status string `json:"status"`
+}
+
+// CompareShellEnv 比较配置内容中的 env 与 shell 环境变量（environ 为 os.Environ 的格式），结果按键名排序
+// 配置中没有的变量只报告 ANTHROPIC_* 与凭据键名
+func CompareShellEnv(content map[string]interface{}, environ []string) []EnvComparison {
+	shell := make(map[string]string, len(environ))
+	for _, entry := range environ {
+		if key, value, found := strings.Cut(entry, "="); found {
+			shell[key] = value
+		}
+	}
+
+	profileEnv, _ := content["env"].(map[string]interface{})
+
+	var comparisons []EnvComparison
+	for key, value := range profileEnv {
+		shellValue, set := shell[key]
+		status := EnvProfileOnly
+		if set {
+			status = EnvDiffers
+			if shellValue == envValueString(value) {
+				status = EnvMatches
+			}
+		}
+		comparisons = append(comparisons, EnvComparison{Key: key, Status: status})
+	}
+
+	for key := range shell {
+		if _, inProfile := profileEnv[key]; inProfile || !isClaudeEnvKey(key) {
+			continue
+		}
+		comparisons = append(comparisons, EnvComparison{Key: key, Status: EnvShellOnly})
+	}
+
+	sort.Slice(comparisons, func(i, j int) bool {
+		return comparisons[i].Key < comparisons[j].Key
+	})
+	return comparisons
+}
+
+// envValueString 将 env 中的值转换为环境变量中的字符串形式
+func envValueString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
+
+// isClaudeEnvKey 判断变量是否影响 Claude Code 使用的 API 端点与凭据
+func isClaudeEnvKey(key string) bool {
+	return IsTokenKey(key) || strings.HasPrefix(key, shellOnlyPrefix)
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// statusLineInterpreters 常见的脚本解释器，命令以它们开头时同时检查紧随其后的脚本路径
+var statusLineInterpreters = map[string]bool{
+	"sh": true, "bash": true, "zsh": true, "fish": true,
+	"node": true, "python": true, "python3": true, "ruby": true, "perl": true,
+}
+
+// checkStatusLineCommand 检查 statusLine.command 引用的可执行文件在本机是否存在
+// 只检查命令的第一个词（绝对路径、~ 开头的路径或 PATH 中的命令名）以及解释器后的脚本路径，
+// 相对路径取决于 Claude Code 的工作目录，不做检查
+func checkStatusLineCommand(content map[string]interface{}) []ValidationIssue {
+	statusLine, ok := content["statusLine"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if kind, ok := statusLine["type"].(string); ok && kind != "command" {
+		return nil
+	}
+	command, ok := statusLine["command"].(string)
+	if !ok || strings.TrimSpace(command) == "" {
+		return nil
+	}
+
+	words := splitCommandWords(command, 2)
+	if len(words) == 0 {
+		return nil
+	}
+	program := expandCommandPath(words[0])
+	if problem := commandProblem(program); problem != "" {
+		return []ValidationIssue{NewIssue(IssueStatusLineCommandMissing, SeverityWarning, "statusLine.command", problem)}
+	}
+
+	if len(words) > 1 && statusLineInterpreters[filepath.Base(program)] {
+		script := expandCommandPath(words[1])
+		if filepath.IsAbs(script) {
+			if _, err := os.Stat(script); err != nil {
+				return []ValidationIssue{NewIssue(IssueStatusLineCommandMissing, SeverityWarning, "statusLine.command", fmt.Sprintf("script '%s' does not exist", script))}
+			}
+		}
+	}
+	return nil
+}
+
+// commandProblem 描述命令无法执行的原因；可以执行或无法判断时返回空
+func commandProblem(program string) string {
+	switch {
+	case filepath.IsAbs(program):
+		info, err := os.Stat(program)
+		if err != nil {
+			return fmt.Sprintf("'%s' does not exist", program)
+		}
+		if info.IsDir() {
+			return fmt.Sprintf("'%s' is a directory", program)
+		}
+		if _, err := exec.LookPath(program); err != nil {
+			return fmt.Sprintf("'%s' is not executable", program)
+		}
+	case !strings.ContainsAny(program, `/\`):
+		if _, err := exec.LookPath(program); err != nil {
+			return fmt.Sprintf("'%s' was not found on PATH", program)
+		}
+	}
+	return ""
+}
+
+// splitCommandWords 按空白拆分命令的前 n 个词，支持单双引号
+func splitCommandWords(command string, n int) []string {
+	var words []string
+	var word strings.Builder
+	var quote rune
+	inWord := false
+	for _, r := range strings.TrimSpace(command) {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+				if len(words) == n {
+					return words
+				}
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// expandCommandPath 展开命令路径中的 ~ 与环境变量
+func expandCommandPath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	return os.ExpandEnv(path)
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SwitchOptions 切换配置的选项
+type SwitchOptions struct {
+	Note string // 记录在历史中的备注
+	// Verify 写入 settings.json 后重新读取并校验，发现错误时回滚；全局配置 verify_switch 开启时总是校验
+	Verify bool
+}
+
+// switchSpaceMargin 切换时在所需空间之外预留的余量，覆盖块对齐与元数据开销
+const switchSpaceMargin = 64 * 1024
+
+// freeSpace 查询目录所在文件系统的可用空间，测试中替换以模拟磁盘已满
+var freeSpace = diskFree
+
+// syncFile 和 syncDirectory 执行刷盘，测试中替换以检查刷盘与重命名的顺序
+var (
+	syncFile      = (*os.File).Sync
+	syncDirectory = syncDir
+)
+
+// 切换过程中的各个步骤，出错时写入 SwitchStepError
+const (
+	StepCheckSpace    = "checking free disk space"
+	StepBackfill      = "saving changes back to the outgoing configuration"
+	StepWriteSettings = "writing settings.json"
+	StepVerify        = "verifying the new settings.json"
+	StepCurrentMarker = "updating the current configuration marker"
+	StepRecordHistory = "recording the switch in history"
+)
+
+// SwitchStepError 切换在某一步骤失败；settings.json 已被修改时会尝试回滚
+type SwitchStepError struct {
+	Profile string
+	Step    string
+	Path    string
+	Err     error
+	// RolledBack settings.json 等已修改的文件是否已恢复为切换前的内容
+	RolledBack bool
+	// RollbackErr 回滚本身失败时的错误
+	RollbackErr error
+}
+
+func (e *SwitchStepError) Error() string {
+	msg := fmt.Sprintf("switch to '%s' failed while %s (%s): %v", e.Profile, e.Step, e.Path, e.Err)
+	switch {
+	case e.RollbackErr != nil:
+		msg += fmt.Sprintf("; rollback also failed (%v), settings.json may not match the current configuration", e.RollbackErr)
+	case e.RolledBack:
+		msg += "; settings.json was restored to its previous content"
+	default:
+		msg += "; nothing was changed"
+	}
+	return msg
+}
+
+func (e *SwitchStepError) Unwrap() error {
+	return e.Err
+}
+
+// verifySettings 重新读取刚写入的 settings.json，确认它是合法的 JSON 且没有校验错误
+func (cm *ConfigManager) verifySettings() error {
+	data, err := os.ReadFile(cm.settingsFile)
+	if err != nil {
+		return fmt.Errorf("failed to read back settings.json: %w", err)
+	}
+	var content map[string]interface{}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return Invalidf("settings.json is not valid JSON: %v", err)
+	}
+
+	var problems []string
+	for _, issue := range ValidateContent(content, false) {
+		if issue.Severity != SeverityError {
+			continue
+		}
+		if issue.Path != "" {
+			problems = append(problems, fmt.Sprintf("%s: %s", issue.Path, issue.Message))
+		} else {
+			problems = append(problems, issue.Message)
+		}
+	}
+	if len(problems) > 0 {
+		return Invalidf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// writeFileAtomicSync 先写入同目录下的临时文件再重命名，失败时清理临时文件，不会留下写了一半的目标文件
+// sync 为 true 时保证断电安全。顺序很重要：先 fsync 临时文件再重命名，否则某些文件系统在断电后
+// 会留下空文件或旧文件；重命名后再 fsync 所在目录，确保目录项指向新文件
+func writeFileAtomicSync(path string, data []byte, perm os.FileMode, sync bool) error {
+	// 清理上次中断留下的临时文件后以 O_EXCL 创建，不会跟随预先放置的符号链接
+	tempFile := path + ".tmp"
+	if err := os.Remove(tempFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	f, err := os.OpenFile(tempFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil && sync {
+		err = syncFile(f)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tempFile)
+		return err
+	}
+
+	if err := os.Rename(tempFile, path); err != nil {
+		os.Remove(tempFile)
+		return err
+	}
+	if sync {
+		return syncDirectory(filepath.Dir(path))
+	}
+	return nil
+}
+
+// writeFile 原子写入 cc-switch 管理的文件；settings.json 总是刷盘，其他文件在开启 durable_writes 时刷盘
+func (cm *ConfigManager) writeFile(path string, data []byte, perm os.FileMode) error {
+	if err := cm.checkManagedPath(path); err != nil {
+		return err
+	}
+	return cm.fs.WriteFile(path, data, perm, path == cm.settingsFile || DurableWrites())
+}
+
+// fileSnapshot 文件在切换前的内容，用于失败时回滚
+type fileSnapshot struct {
+	path    string
+	data    []byte
+	existed bool
+}
+
+// snapshotFile 读取文件当前内容；文件不存在时记录为不存在
+func snapshotFile(path string) (*fileSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &fileSnapshot{path: path}, nil
+		}
+		return nil, err
+	}
+	return &fileSnapshot{path: path, data: data, existed: true}, nil
+}
+
+// restore 经由 fs 将文件恢复为快照内容（快照时不存在则删除）
+func (s *fileSnapshot) restore(fs fileSystem) error {
+	if !s.existed {
+		if err := fs.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return fs.WriteFile(s.path, s.data, 0600, true)
+}
+
+// checkSwitchSpace 确认 ~/.claude 所在文件系统有足够空间写入新的 settings.json、回写的配置和历史记录
+// 无法获取可用空间（如 Windows）时跳过检查
+func (cm *ConfigManager) checkSwitchSpace(profilePath string, backfill bool) error {
+	needed := int64(switchSpaceMargin)
+	for _, path := range []string{profilePath, cm.historyFile} {
+		if info, err := os.Stat(path); err == nil {
+			needed += info.Size()
+		}
+	}
+	if backfill {
+		if info, err := os.Stat(cm.settingsFile); err == nil {
+			needed += info.Size()
+		}
+	}
+
+	dir := filepath.Dir(cm.settingsFile)
+	free, ok := freeSpace(dir)
+	if !ok || free >= uint64(needed) {
+		return nil
+	}
+	return fmt.Errorf("only %d bytes free, at least %d needed", free, needed)
+}
@@ -0,0 +1,468 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// faultyFileSystem fails the failAt-th WriteFile call (counting from 1) with ENOSPC
+// and records the path of every write
+type faultyFileSystem struct {
+	fileSystem
+	failAt int
+	writes []string
+}
+
+func (fs *faultyFileSystem) WriteFile(path string, data []byte, perm os.FileMode, sync bool) error {
+	fs.writes = append(fs.writes, path)
+	if len(fs.writes) == fs.failAt {
+		return &os.PathError{Op: "write", Path: path, Err: syscall.ENOSPC}
+	}
+	return fs.fileSystem.WriteFile(path, data, perm, sync)
+}
+
+// switchState is the on-disk state a failed switch must leave untouched
+type switchState struct {
+	settings []byte
+	current  []byte
+	history  []byte
+}
+
+func readSwitchState(t *testing.T, cm *ConfigManager) switchState {
+	t.Helper()
+	read := func(path string) []byte {
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		return data
+	}
+	return switchState{settings: read(cm.settingsFile), current: read(cm.currentFile), history: read(cm.historyFile)}
+}
+
+// setupSwitch creates work and home, switches to work and edits settings.json so the
+// next switch also saves the edit back to work
+func setupSwitch(t *testing.T) *ConfigManager {
+	t.Helper()
+	cm := newTestManager(t)
+	for _, name := range []string{"work", "home"} {
+		if err := cm.CreateProfileWithContent(name, map[string]interface{}{"model": name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cm.UseProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cm.settingsFile, []byte(`{"model": "work-edited"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return cm
+}
+
+// traceSwitchWrites returns the base names of the files a successful switch writes, in order
+func traceSwitchWrites(t *testing.T) []string {
+	t.Helper()
+	trace := &faultyFileSystem{fileSystem: osFileSystem{}}
+	cm := setupSwitch(t)
+	cm.fs = trace
+	if err := cm.UseProfile("home"); err != nil {
+		t.Fatalf("UseProfile: %v", err)
+	}
+	names := make([]string, len(trace.writes))
+	for i, path := range trace.writes {
+		names[i] = filepath.Base(path)
+	}
+	return names
+}
+
+func TestSwitchRollsBackOnWriteFailure(t *testing.T) {
+	steps := map[string]string{
+		"work.json":     StepBackfill,
+		"settings.json": StepWriteSettings,
+		".current":      StepCurrentMarker,
+		".history":      StepRecordHistory,
+	}
+
+	// Fail each write of a successful switch in turn
+	writes := traceSwitchWrites(t)
+	tested := 0
+	for n, name := range writes {
+		step, ok := steps[name]
+		if !ok {
+			continue // metadata and activity log writes are best effort
+		}
+		tested++
+		t.Run(step, func(t *testing.T) {
+			cm := setupSwitch(t)
+			before := readSwitchState(t, cm)
+			cm.fs = &faultyFileSystem{fileSystem: osFileSystem{}, failAt: n + 1}
+
+			err := cm.UseProfile("home")
+			var stepErr *SwitchStepError
+			if !errors.As(err, &stepErr) {
+				t.Fatalf("err = %v, want a SwitchStepError", err)
+			}
+			if stepErr.Step != step || filepath.Base(stepErr.Path) != name {
+				t.Errorf("failed step = %q (%s), want %q (%s)", stepErr.Step, stepErr.Path, step, name)
+			}
+			if !errors.Is(err, syscall.ENOSPC) {
+				t.Errorf("err = %v, want it to wrap ENOSPC", err)
+			}
+			wantRolledBack := step == StepCurrentMarker || step == StepRecordHistory
+			if stepErr.RolledBack != wantRolledBack || stepErr.RollbackErr != nil {
+				t.Errorf("rolled back = %v (%v), want %v", stepErr.RolledBack, stepErr.RollbackErr, wantRolledBack)
+			}
+
+			after := readSwitchState(t, cm)
+			if !bytes.Equal(after.settings, before.settings) {
+				t.Errorf("settings.json = %s, want %s", after.settings, before.settings)
+			}
+			if !bytes.Equal(after.current, before.current) {
+				t.Errorf(".current = %q, want %q", after.current, before.current)
+			}
+			if !bytes.Equal(after.history, before.history) {
+				t.Error("history changed")
+			}
+			if current, _ := cm.GetCurrentProfile(); current != "work" {
+				t.Errorf("current = %q, want work", current)
+			}
+		})
+	}
+	if tested != len(steps) {
+		t.Fatalf("writes = %v, want the outgoing profile, settings.json, .current and history", writes)
+	}
+}
+
+func TestSwitchRollbackFailureIsReported(t *testing.T) {
+	writes := traceSwitchWrites(t)
+	marker := -1
+	for i, name := range writes {
+		if name == ".current" {
+			marker = i
+		}
+	}
+	if marker < 0 {
+		t.Fatalf("writes = %v, want .current", writes)
+	}
+
+	// The disk becomes read-only while writing .current, so restoring settings.json fails too
+	cm := setupSwitch(t)
+	cm.fs = &alwaysFailAfter{fileSystem: osFileSystem{}, after: marker}
+	err := cm.UseProfile("home")
+	var stepErr *SwitchStepError
+	if !errors.As(err, &stepErr) || stepErr.Step != StepCurrentMarker {
+		t.Fatalf("err = %v, want a failure while %s", err, StepCurrentMarker)
+	}
+	if stepErr.RolledBack || !errors.Is(stepErr.RollbackErr, syscall.EROFS) {
+		t.Errorf("rolled back = %v, rollback error = %v; want the rollback failure reported", stepErr.RolledBack, stepErr.RollbackErr)
+	}
+}
+
+// alwaysFailAfter lets the first after writes through and fails every later one
+type alwaysFailAfter struct {
+	fileSystem
+	after int
+	count int
+}
+
+func (fs *alwaysFailAfter) WriteFile(path string, data []byte, perm os.FileMode, sync bool) error {
+	fs.count++
+	if fs.count > fs.after {
+		return &os.PathError{Op: "write", Path: path, Err: syscall.EROFS}
+	}
+	return fs.fileSystem.WriteFile(path, data, perm, sync)
+}
+
+func TestSwitchChecksFreeSpace(t *testing.T) {
+	cm := setupSwitch(t)
+	before := readSwitchState(t, cm)
+
+	freeSpace = func(string) (uint64, bool) { return 1024, true }
+	t.Cleanup(func() { freeSpace = diskFree })
+
+	err := cm.UseProfile("home")
+	var stepErr *SwitchStepError
+	if !errors.As(err, &stepErr) || stepErr.Step != StepCheckSpace {
+		t.Fatalf("err = %v, want a failure while %s", err, StepCheckSpace)
+	}
+	if after := readSwitchState(t, cm); !bytes.Equal(after.settings, before.settings) || !bytes.Equal(after.current, before.current) {
+		t.Error("files changed although the space check failed")
+	}
+	content, _, err := cm.GetProfileContent("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content["model"] != "work" {
+		t.Errorf("work model = %v, want the edit not saved back yet", content["model"])
+	}
+}
+
+// recordSyncs replaces the fsync functions with ones that record which file or directory
+// was synced, after calling check so the test can inspect the disk at that moment
+func recordSyncs(t *testing.T, check func(event string)) *[]string {
+	t.Helper()
+	var events []string
+	syncFile = func(f *os.File) error {
+		events = append(events, "file "+filepath.Base(f.Name()))
+		check("file")
+		return f.Sync()
+	}
+	syncDirectory = func(dir string) error {
+		events = append(events, "dir")
+		check("dir")
+		return syncDir(dir)
+	}
+	t.Cleanup(func() {
+		syncFile = (*os.File).Sync
+		syncDirectory = syncDir
+	})
+	return &events
+}
+
+func TestWriteFileAtomicSyncOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The temp file must be synced before it replaces the target, and the directory after
+	events := recordSyncs(t, func(event string) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]string{"file": "old", "dir": "new"}[event]
+		if string(data) != want {
+			t.Errorf("target holds %q when syncing the %s, want %q", data, event, want)
+		}
+	})
+
+	if err := writeFileAtomicSync(path, []byte("new"), 0600, true); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"file settings.json.tmp", "dir"}
+	if !equalStrings(*events, want) {
+		t.Errorf("syncs = %v, want %v", *events, want)
+	}
+
+	*events = nil
+	if err := writeFileAtomicSync(path, []byte("old"), 0600, false); err != nil {
+		t.Fatal(err)
+	}
+	if len(*events) != 0 {
+		t.Errorf("syncs = %v, want none without sync", *events)
+	}
+}
+
+func TestWriteFileAtomicSyncFailureKeepsTarget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	syncFile = func(*os.File) error { return syscall.EIO }
+	t.Cleanup(func() { syncFile = (*os.File).Sync })
+
+	if err := writeFileAtomicSync(path, []byte("new"), 0600, true); !errors.Is(err, syscall.EIO) {
+		t.Fatalf("err = %v, want EIO", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "old" {
+		t.Errorf("target = %q, want it untouched", data)
+	}
+	if _, err := os.Lstat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temp file left behind: %v", err)
+	}
+}
+
+func TestWriteFileAtomicSyncIgnoresPlantedTempFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.json")
+	outside := filepath.Join(dir, "outside")
+	if err := os.WriteFile(outside, []byte("keep"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, path+".tmp"); err != nil {
+		t.Skipf("cannot create symlinks: %v", err)
+	}
+
+	if err := writeFileAtomicSync(path, []byte("new"), 0600, true); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(outside); string(data) != "keep" {
+		t.Errorf("symlink target = %q, want it untouched", data)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("target = %q, want new", data)
+	}
+}
+
+// syncRecorder records whether each write asked for an fsync
+type syncRecorder struct {
+	fileSystem
+	synced map[string]bool
+}
+
+func (fs *syncRecorder) WriteFile(path string, data []byte, perm os.FileMode, sync bool) error {
+	fs.synced[filepath.Base(path)] = sync
+	return fs.fileSystem.WriteFile(path, data, perm, sync)
+}
+
+func TestDurableWrites(t *testing.T) {
+	for _, durable := range []bool{false, true} {
+		t.Run(fmt.Sprintf("durable_writes=%v", durable), func(t *testing.T) {
+			cm := setupSwitch(t)
+			cfg, err := cm.LoadGlobalConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg.DurableWrites = durable
+			if err := cm.SaveGlobalConfig(cfg); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { setDurableWrites(false) })
+
+			recorder := &syncRecorder{fileSystem: osFileSystem{}, synced: map[string]bool{}}
+			cm.fs = recorder
+			if err := cm.UseProfile("home"); err != nil {
+				t.Fatal(err)
+			}
+
+			// settings.json is always synced; every other file follows the setting
+			if !recorder.synced["settings.json"] {
+				t.Error("settings.json was written without fsync")
+			}
+			for name, synced := range recorder.synced {
+				if name != "settings.json" && synced != durable {
+					t.Errorf("%s synced = %v, want %v", name, synced, durable)
+				}
+			}
+			if len(recorder.synced) < 2 {
+				t.Errorf("writes = %v, want settings.json and the files around it", recorder.synced)
+			}
+		})
+	}
+}
+
+// addBrokenProfile writes a profile that is valid JSON but fails validation, bypassing the
+// checks CreateProfileWithContent would apply
+func addBrokenProfile(t *testing.T, cm *ConfigManager) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(cm.profilesDir, "broken.json"), []byte(`{"model": "broken", "env": "not an object"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifiedSwitchRollsBack(t *testing.T) {
+	tests := []struct {
+		name   string
+		enable func(t *testing.T) SwitchOptions
+	}{
+		{"verify option", func(t *testing.T) SwitchOptions { return SwitchOptions{Verify: true} }},
+		{"verify_switch setting", func(t *testing.T) SwitchOptions {
+			setVerifySwitch(true)
+			t.Cleanup(func() { setVerifySwitch(false) })
+			return SwitchOptions{}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := setupSwitch(t)
+			addBrokenProfile(t, cm)
+			before := readSwitchState(t, cm)
+
+			err := cm.UseProfileWithOptions("broken", tt.enable(t))
+			var stepErr *SwitchStepError
+			if !errors.As(err, &stepErr) || stepErr.Step != StepVerify {
+				t.Fatalf("err = %v, want a SwitchStepError at %q", err, StepVerify)
+			}
+			if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), "env") {
+				t.Errorf("err = %v, want the validation error for env", err)
+			}
+			if !stepErr.RolledBack || stepErr.RollbackErr != nil {
+				t.Errorf("rolled back = %v (%v), want true", stepErr.RolledBack, stepErr.RollbackErr)
+			}
+
+			after := readSwitchState(t, cm)
+			if !bytes.Equal(after.settings, before.settings) {
+				t.Errorf("settings.json = %s, want %s", after.settings, before.settings)
+			}
+			if !bytes.Equal(after.current, before.current) {
+				t.Errorf(".current = %q, want %q", after.current, before.current)
+			}
+			if !bytes.Equal(after.history, before.history) {
+				t.Error("a rolled-back switch was recorded in history")
+			}
+			if current, _ := cm.GetCurrentProfile(); current != "work" {
+				t.Errorf("current = %q, want work", current)
+			}
+		})
+	}
+}
+
+func TestVerifiedSwitch(t *testing.T) {
+	cm := setupSwitch(t)
+	if err := cm.UseProfileWithOptions("home", SwitchOptions{Verify: true, Note: "verified"}); err != nil {
+		t.Fatalf("UseProfileWithOptions: %v", err)
+	}
+	if current, _ := cm.GetCurrentProfile(); current != "home" {
+		t.Errorf("current = %q, want home", current)
+	}
+	entries, err := cm.GetHistoryEntries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 || entries[0].Profile != "home" || entries[0].Note != "verified" {
+		t.Errorf("history = %+v, want the verified switch recorded", entries)
+	}
+
+	// Verification is optional: without it a broken profile is written as it is
+	addBrokenProfile(t, cm)
+	if err := cm.UseProfile("broken"); err != nil {
+		t.Fatalf("UseProfile without verification: %v", err)
+	}
+	if current, _ := cm.GetCurrentProfile(); current != "broken" {
+		t.Errorf("current = %q, want broken", current)
+	}
+}
+
+// looseModeFileSystem leaves every written file with mode 0644, like a write that keeps
+// the mode of an existing file or a file system that ignores the requested mode
+type looseModeFileSystem struct {
+	fileSystem
+}
+
+func (fs looseModeFileSystem) WriteFile(path string, data []byte, perm os.FileMode, sync bool) error {
+	if err := fs.fileSystem.WriteFile(path, data, perm, sync); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0644)
+}
+
+func TestSwitchRestrictsSettingsPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows does not use Unix permission bits")
+	}
+	cm := setupSwitch(t)
+	if err := os.Chmod(cm.settingsFile, 0644); err != nil {
+		t.Fatal(err)
+	}
+	cm.fs = looseModeFileSystem{fileSystem: osFileSystem{}}
+
+	if err := cm.UseProfile("home"); err != nil {
+		t.Fatalf("UseProfile: %v", err)
+	}
+	info, err := os.Stat(cm.settingsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("settings.json mode = %04o, want 0600", perm)
+	}
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SymlinkedFile 配置或模板目录中的符号链接
+type SymlinkedFile struct {
+	Path   string `json:"path"`
+	Target string `json:"target"` // 解析后的目标路径；链接已断开时为链接内容
+	// Outside 目标位于所在目录之外，cc-switch 会拒绝读写该文件
+	Outside bool `json:"outside"`
+}
+
+// checkManagedPath 拒绝配置目录或模板目录中解析后指向目录之外的符号链接，
+// 防止读入外部文件或通过链接覆盖任意文件
+// 其他路径（如常被链接到 dotfiles 仓库的 settings.json）不受限制
+func (cm *ConfigManager) checkManagedPath(path string) error {
+	dir := filepath.Dir(path)
+	if dir != cm.profilesDir && dir != cm.templatesDir {
+		return nil
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return nil
+	}
+
+	target, outside := resolveSymlink(dir, path)
+	if outside {
+		return Invalidf("refusing to use %s: it is a symlink to %s, outside %s", path, target, dir)
+	}
+	return nil
+}
+
+// resolveSymlink 解析符号链接并判断目标是否位于 dir 之外；链接断开时无法确认目标，视为在外
+func resolveSymlink(dir, path string) (target string, outside bool) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		link, _ := os.Readlink(path)
+		return link, true
+	}
+
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		realDir = dir
+	}
+	rel, err := filepath.Rel(realDir, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return resolved, true
+	}
+	return resolved, false
+}
+
+// FindSymlinkedFiles 列出配置目录和模板目录中的符号链接
+func (cm *ConfigManager) FindSymlinkedFiles() ([]SymlinkedFile, error) {
+	var files []SymlinkedFile
+	for _, dir := range []string{cm.profilesDir, cm.templatesDir} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.Type()&os.ModeSymlink == 0 {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			target, outside := resolveSymlink(dir, path)
+			files = append(files, SymlinkedFile{Path: path, Target: target, Outside: outside})
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// symlinkOutside links dir/name.json to a file outside dir holding `{"model": "outside"}`
+// and returns the target path
+func symlinkOutside(t *testing.T, dir, name string) string {
+	t.Helper()
+	target := filepath.Join(t.TempDir(), "elsewhere.json")
+	if err := os.WriteFile(target, []byte(`{"model": "outside"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, filepath.Join(dir, name+".json")); err != nil {
+		t.Skipf("cannot create symlinks: %v", err)
+	}
+	return target
+}
+
+func TestSymlinkOutsideIsRefused(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func(cm *ConfigManager) error
+	}{
+		{"read", func(cm *ConfigManager) error {
+			_, _, err := cm.GetProfileContent("linked")
+			return err
+		}},
+		{"update", func(cm *ConfigManager) error {
+			return cm.UpdateProfile("linked", map[string]interface{}{"model": "written"})
+		}},
+		{"use", func(cm *ConfigManager) error { return cm.UseProfile("linked") }},
+		{"copy from", func(cm *ConfigManager) error { return cm.CopyProfile("linked", "copy") }},
+		{"validate", func(cm *ConfigManager) error {
+			_, err := cm.ValidateProfile("linked")
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := newTestManager(t)
+			target := symlinkOutside(t, cm.profilesDir, "linked")
+
+			if err := tt.op(cm); !errors.Is(err, ErrInvalid) {
+				t.Fatalf("err = %v, want ErrInvalid", err)
+			}
+			if data, _ := os.ReadFile(target); string(data) != `{"model": "outside"}` {
+				t.Errorf("target = %s, want it untouched", data)
+			}
+			if data, _ := os.ReadFile(cm.settingsFile); string(data) == `{"model": "outside"}` {
+				t.Error("the outside file was copied into settings.json")
+			}
+		})
+	}
+}
+
+func TestTemplateSymlinkOutsideIsRefused(t *testing.T) {
+	cm := newTestManager(t)
+	target := symlinkOutside(t, cm.templatesDir, "linked")
+
+	if _, err := cm.GetTemplateContent("linked"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("GetTemplateContent err = %v, want ErrInvalid", err)
+	}
+	if err := cm.UpdateTemplate("linked", map[string]interface{}{"model": "written"}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("UpdateTemplate err = %v, want ErrInvalid", err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != `{"model": "outside"}` {
+		t.Errorf("target = %s, want it untouched", data)
+	}
+}
+
+func TestSymlinkInsideIsAllowed(t *testing.T) {
+	cm := newTestManager(t)
+	if err := cm.CreateProfileWithContent("work", map[string]interface{}{"model": "work"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("work.json", filepath.Join(cm.profilesDir, "alias.json")); err != nil {
+		t.Skipf("cannot create symlinks: %v", err)
+	}
+
+	content, _, err := cm.GetProfileContent("alias")
+	if err != nil {
+		t.Fatalf("GetProfileContent: %v", err)
+	}
+	if content["model"] != "work" {
+		t.Errorf("model = %v, want work", content["model"])
+	}
+	if err := cm.UseProfile("alias"); err != nil {
+		t.Errorf("UseProfile: %v", err)
+	}
+}
+
+func TestFindSymlinkedFiles(t *testing.T) {
+	cm := newTestManager(t)
+	if err := cm.CreateProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("work.json", filepath.Join(cm.profilesDir, "alias.json")); err != nil {
+		t.Skipf("cannot create symlinks: %v", err)
+	}
+	if err := os.Symlink("missing-target", filepath.Join(cm.profilesDir, "dangling.json")); err != nil {
+		t.Fatal(err)
+	}
+	symlinkOutside(t, cm.templatesDir, "linked")
+
+	files, err := cm.FindSymlinkedFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]bool{}
+	for _, file := range files {
+		got[filepath.Base(file.Path)] = file.Outside
+	}
+	want := map[string]bool{"alias.json": false, "dangling.json": true, "linked.json": true}
+	if len(got) != len(want) {
+		t.Fatalf("found %v, want %v", got, want)
+	}
+	for name, outside := range want {
+		if got[name] != outside {
+			t.Errorf("%s outside = %v, want %v", name, got[name], outside)
+		}
+	}
+}
+
+func TestSettingsSymlinkIsAllowed(t *testing.T) {
+	// settings.json is often linked into a dotfiles repository; only the managed directories are restricted
+	cm := newTestManager(t)
+	target := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(target, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(cm.settingsFile); err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, cm.settingsFile); err != nil {
+		t.Skipf("cannot create symlinks: %v", err)
+	}
+	if err := cm.checkManagedPath(cm.settingsFile); err != nil {
+		t.Errorf("checkManagedPath(settings.json) = %v, want nil", err)
+	}
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// NormalizeTag 规范化并验证标签：转为小写，不能为空，且不包含空白或逗号
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return "", Invalidf("tag cannot be empty")
+	}
+	if strings.ContainsAny(tag, " \t\r\n,") {
+		return "", Invalidf("invalid tag '%s': tags cannot contain whitespace or commas", tag)
+	}
+	return tag, nil
+}
+
+// GetProfileTags 获取配置的标签（已排序）
+func (cm *ConfigManager) GetProfileTags(name string) ([]string, error) {
+	meta, err := cm.GetProfileMetadata(name)
+	if err != nil {
+		return nil, err
+	}
+	return meta.Tags, nil
+}
+
+// AddProfileTag 为配置添加标签，返回是否实际新增（已有该标签时返回 false）
+func (cm *ConfigManager) AddProfileTag(name, tag string) (bool, error) {
+	return cm.updateProfileTags(name, tag, true)
+}
+
+// RemoveProfileTag 移除配置的标签，返回是否实际移除（没有该标签时返回 false）
+func (cm *ConfigManager) RemoveProfileTag(name, tag string) (bool, error) {
+	return cm.updateProfileTags(name, tag, false)
+}
+
+// updateProfileTags 添加或移除单个标签并保存元数据
+func (cm *ConfigManager) updateProfileTags(name, tag string, add bool) (bool, error) {
+	tag, err := NormalizeTag(tag)
+	if err != nil {
+		return false, err
+	}
+
+	if !cm.ProfileExists(name) {
+		return false, NotFoundf("profile '%s' does not exist", name)
+	}
+
+	meta, err := cm.GetProfileMetadata(name)
+	if err != nil {
+		return false, err
+	}
+
+	index := -1
+	for i, existing := range meta.Tags {
+		if existing == tag {
+			index = i
+			break
+		}
+	}
+
+	switch {
+	case add && index < 0:
+		meta.Tags = append(meta.Tags, tag)
+		sort.Strings(meta.Tags)
+	case !add && index >= 0:
+		meta.Tags = append(meta.Tags[:index], meta.Tags[index+1:]...)
+	default:
+		return false, nil
+	}
+
+	if err := cm.saveProfileMetadata(name, meta); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// setProfileTags 用给定的标签替换配置的全部标签
+func (cm *ConfigManager) setProfileTags(name string, tags []string) error {
+	meta, err := cm.GetProfileMetadata(name)
+	if err != nil {
+		return err
+	}
+	meta.Tags = sortedTags(tags)
+	return cm.saveProfileMetadata(name, meta)
+}
+
+// ListProfilesFiltered 列出名称匹配 glob 模式的配置
+// 模式以 "tag:" 开头时按标签筛选，如 "tag:work"；无法读取的配置会被跳过
+func (cm *ConfigManager) ListProfilesFiltered(filter string) ([]Profile, error) {
+	profiles, err := cm.ListProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	tagFilter, byTag := strings.CutPrefix(filter, "tag:")
+	if !byTag {
+		// 提前检查模式语法，避免逐个匹配时才报错
+		if _, err := filepath.Match(filter, ""); err != nil {
+			return nil, Invalidf("invalid filter '%s': %w", filter, err)
+		}
+	}
+
+	var matched []Profile
+	for _, profile := range profiles {
+		if profile.Error != "" {
+			continue
+		}
+
+		if byTag {
+			if cm.profileHasTag(profile.Name, strings.ToLower(tagFilter)) {
+				matched = append(matched, profile)
+			}
+			continue
+		}
+
+		if ok, _ := filepath.Match(filter, profile.Name); ok {
+			matched = append(matched, profile)
+		}
+	}
+
+	return matched, nil
+}
+
+// profileHasTag 检查配置是否带有指定标签
+func (cm *ConfigManager) profileHasTag(name, tag string) bool {
+	tags, err := cm.GetProfileTags(name)
+	if err != nil {
+		fmt.Fprintf(cm.warnings, "Warning: %v\n", err)
+		return false
+	}
+	for _, existing := range tags {
+		if existing == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// templateFieldsKey 模板中声明待填写字段路径的顶层键，如 "_fields": ["env.ANTHROPIC_AUTH_TOKEN"]
+const templateFieldsKey = "_fields"
+
+// LintTemplate 检查模板是否确实是一份模板：JSON 可解析、有 env 段、凭据字段没有填入真实值、
+// _fields 声明的路径都存在。常见的误操作是把配置当作模板编辑，把密钥提交进共享模板
+func (cm *ConfigManager) LintTemplate(name string) ([]ValidationIssue, error) {
+	templatePath := filepath.Join(cm.templatesDir, name+".json")
+	if err := cm.checkManagedPath(templatePath); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(templatePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, NotFoundf("template '%s' does not exist", name)
+		}
+		return nil, fmt.Errorf("failed to read template file: %w", err)
+	}
+
+	var content map[string]interface{}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return []ValidationIssue{NewIssue(IssueInvalidJSON, SeverityError, "", fmt.Sprintf("invalid JSON: %v", err))}, nil
+	}
+
+	issues := ValidateContent(content, true)
+
+	if _, ok := content["env"]; !ok {
+		issues = append(issues, NewIssue(IssueEnvMissing, SeverityWarning, "env", "no env section; configurations created from this template will have no API credentials"))
+	}
+
+	for _, field := range cm.DetectStringFields(content) {
+		if !IsSecretKey(field.Name) || strings.TrimSpace(field.Current) == "" {
+			continue
+		}
+		if _, isRef := ParseSecretReference(field.Current); isRef {
+			continue
+		}
+		issues = append(issues, NewIssue(IssueTemplateSecretFilled, SeverityError, field.Path, "holds a real value; templates are shared, leave secrets empty or use an @secret: reference"))
+	}
+
+	issues = append(issues, lintTemplateFields(content)...)
+	return issues, nil
+}
+
+// lintTemplateFields 检查 _fields 是字符串数组，且其中的每个路径都存在于模板中
+func lintTemplateFields(content map[string]interface{}) []ValidationIssue {
+	raw, ok := content[templateFieldsKey]
+	if !ok {
+		return nil
+	}
+	paths, ok := raw.([]interface{})
+	if !ok {
+		return []ValidationIssue{NewIssue(IssueTemplateFieldMissing, SeverityError, templateFieldsKey, "_fields must be an array of field paths")}
+	}
+
+	var issues []ValidationIssue
+	for i, item := range paths {
+		location := fmt.Sprintf("%s[%d]", templateFieldsKey, i)
+		path, ok := item.(string)
+		if !ok {
+			issues = append(issues, NewIssue(IssueTemplateFieldMissing, SeverityError, location, "must be a string"))
+			continue
+		}
+		if !contentHasPath(content, path) {
+			issues = append(issues, NewIssue(IssueTemplateFieldMissing, SeverityError, location, fmt.Sprintf("'%s' does not exist in the template", path)))
+		}
+	}
+	return issues
+}
+
+// contentHasPath 判断以点分隔的字段路径（如 env.ANTHROPIC_BASE_URL）是否存在
+func contentHasPath(content map[string]interface{}, path string) bool {
+	current := content
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		value, ok := current[part]
+		if !ok {
+			return false
+		}
+		if i == len(parts)-1 {
+			return true
+		}
+		if current, ok = value.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// MaxContentSize 配置内容序列化后的最大字节数（与Web接口的请求体限制一致）
+const MaxContentSize = 1 << 20
+
+// 校验问题的严重程度
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// ValidationIssue 配置校验问题
+type ValidationIssue struct {
+	ID          string `json:"id"`       // 稳定的问题 ID，见 issues.go
+	Severity    string `json:"severity"` // "error" 或 "warning"
+	Path        string `json:"path"`     // 字段路径，如 "env.ANTHROPIC_AUTH_TOKEN"，根节点为空
+	Message     string `json:"message"`
+	Explanation string `json:"explanation,omitempty"` // 问题的详细说明
+	Remediation string `json:"remediation,omitempty"` // 修复方法：命令或 JSON 片段
+}
+
+// ValidateContent 校验配置或模板内容，返回所有发现的问题（不修改任何状态）
+// 模板中的凭据字段允许为空，因此不会产生空值警告
+func ValidateContent(content map[string]interface{}, isTemplate bool) []ValidationIssue {
+	issues := []ValidationIssue{}
+
+	if content == nil {
+		return append(issues, NewIssue(IssueContentEmpty, SeverityError, "", "content cannot be empty"))
+	}
+
+	// 大小检查
+	data, err := json.Marshal(content)
+	if err != nil {
+		return append(issues, NewIssue(IssueContentNotSerializable, SeverityError, "", fmt.Sprintf("content cannot be serialized to JSON: %v", err)))
+	}
+	if len(data) > MaxContentSize {
+		issues = append(issues, NewIssue(IssueContentTooLarge, SeverityError, "", fmt.Sprintf("content is %d bytes, exceeding the %d byte limit", len(data), MaxContentSize)))
+	}
+
+	// env: 字符串键值对
+	if raw, ok := content["env"]; ok && raw != nil {
+		env, ok := raw.(map[string]interface{})
+		if !ok {
+			issues = append(issues, NewIssue(IssueEnvNotObject, SeverityError, "env", "env must be an object"))
+		} else {
+			keys := make([]string, 0, len(env))
+			for key := range env {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			for _, key := range keys {
+				if _, ok := env[key].(string); !ok {
+					issues = append(issues, NewIssue(IssueEnvValueNotString, SeverityError, "env."+key, "environment values must be strings"))
+				}
+			}
+
+			if !isTemplate {
+				issues = append(issues, validateSecrets(env)...)
+			}
+		}
+	} else if !isTemplate {
+		issues = append(issues, NewIssue(IssueEnvMissing, SeverityWarning, "env", "no env section; Claude Code will have no API credentials"))
+	}
+
+	// permissions: allow/deny 字符串数组
+	if raw, ok := content["permissions"]; ok && raw != nil {
+		permissions, ok := raw.(map[string]interface{})
+		if !ok {
+			issues = append(issues, NewIssue(IssuePermissionsNotObject, SeverityError, "permissions", "permissions must be an object"))
+		} else {
+			for _, field := range []string{"allow", "deny"} {
+				value, exists := permissions[field]
+				if !exists || value == nil {
+					continue
+				}
+				list, ok := value.([]interface{})
+				if !ok {
+					issues = append(issues, NewIssue(IssuePermissionsNotArray, SeverityError, "permissions."+field, "must be an array of strings"))
+					continue
+				}
+				for i, item := range list {
+					rule, ok := item.(string)
+					if !ok {
+						issues = append(issues, NewIssue(IssuePermissionsItemNotString, SeverityError, fmt.Sprintf("permissions.%s[%d]", field, i), "must be a string"))
+						continue
+					}
+					if migrated, obsolete := MigratePermissionRule(rule); obsolete {
+						issues = append(issues, NewIssue(IssuePermissionObsolete, SeverityWarning, fmt.Sprintf("permissions.%s[%d]", field, i), fmt.Sprintf("'%s' uses a renamed tool; it is now '%s'", rule, migrated)))
+					}
+				}
+			}
+		}
+	}
+
+	// statusLine: 对象
+	if raw, ok := content["statusLine"]; ok && raw != nil {
+		if _, ok := raw.(map[string]interface{}); !ok {
+			issues = append(issues, NewIssue(IssueStatusLineNotObject, SeverityError, "statusLine", "statusLine must be an object"))
+		}
+	}
+
+	// model: 字符串
+	if raw, ok := content["model"]; ok && raw != nil {
+		if _, ok := raw.(string); !ok {
+			issues = append(issues, NewIssue(IssueModelNotString, SeverityError, "model", "model must be a string"))
+		}
+	}
+
+	// _net: 代理与附加请求头
+	issues = append(issues, validateNet(content)...)
+
+	return issues
+}
+
+// validateSecrets 检查凭据字段是否存在且非空
+func validateSecrets(env map[string]interface{}) []ValidationIssue {
+	var issues []ValidationIssue
+	hasSecret := false
+
+	for _, key := range TokenKeys() {
+		value, exists := env[key]
+		if !exists {
+			continue
+		}
+		if str, ok := value.(string); ok && strings.TrimSpace(str) == "" {
+			issues = append(issues, NewIssue(IssueSecretEmpty, SeverityWarning, "env."+key, fmt.Sprintf("%s is empty", key)))
+			continue
+		}
+		hasSecret = true
+	}
+
+	if !hasSecret && len(issues) == 0 {
+		issues = append(issues, NewIssue(IssueSecretMissing, SeverityWarning, "env", MissingTokenMessage()))
+	}
+
+	return issues
+}
+
+// ValidateProfile 校验已保存的配置
+func (cm *ConfigManager) ValidateProfile(name string) ([]ValidationIssue, error) {
+	profilePath, _ := cm.resolveProfilePath(name)
+	if err := cm.checkManagedPath(profilePath); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(profilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, NotFoundf("profile '%s' does not exist", name)
+		}
+		return nil, fmt.Errorf("failed to read profile: %w", err)
+	}
+
+	var content map[string]interface{}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return []ValidationIssue{NewIssue(IssueInvalidJSON, SeverityError, "", fmt.Sprintf("invalid JSON: %v", err))}, nil
+	}
+
+	// 命令路径只对本机保存的配置有意义，不放在 ValidateContent 中
+	issues := ValidateContent(content, false)
+	return append(issues, checkStatusLineCommand(content)...), nil
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/HoBeedzc/cc-switch/internal/common"
+)
+
+// StateEntries cc-switch 自身的状态文件和目录（位于数据目录下），XDG 迁移和 uninstall 据此处理
+// 模板目录与更新检查缓存另行处理
+var StateEntries = []string{
+	currentFileName,
+	historyFileName,
+	emptyModeFileName,
+	emptyBackupFileName,
+	lastBackupFileName,
+	providersFileName,
+	globalConfigFileName,
+	secretsFileName,
+	endpointSetsFileName,
+	activityLogName,
+	activityLogName + ".1",
+	metadataDirName,
+}
+
+// TransientStateEntries StateEntries 中可以随时丢弃的运行状态，uninstall（不带 --full）只删除这些；
+// 凭据、元数据、全局配置、供应商和活动日志属于用户数据，需要保留
+var TransientStateEntries = []string{
+	currentFileName,
+	historyFileName,
+	emptyModeFileName,
+	emptyBackupFileName,
+	lastBackupFileName,
+}
+
+// migrateToXDG 在启用 XDG 布局后一次性地把状态文件从配置目录移到数据目录、缓存移到缓存目录，
+// 最后在配置目录中留下指针文件。之后即使不再设置环境变量，也按指针文件使用 XDG 布局。
+// 目标已存在的条目保留在原处并给出警告，不会覆盖
+func (cm *ConfigManager) migrateToXDG() error {
+	if !cm.layout.XDG || cm.layout.Migrated {
+		return nil
+	}
+
+	type move struct{ from, to string }
+	var moves []move
+	for _, name := range StateEntries {
+		moves = append(moves, move{filepath.Join(cm.profilesDir, name), cm.dataFile(name)})
+	}
+	moves = append(moves,
+		move{filepath.Join(cm.profilesDir, filepath.Base(cm.templatesDir)), cm.templatesDir},
+		move{filepath.Join(cm.profilesDir, common.UpdateCacheFileName), cm.layout.CacheFile(common.UpdateCacheFileName)},
+	)
+
+	for _, dir := range []string{cm.layout.DataDir, cm.layout.CacheDir} {
+		if err := cm.fs.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+
+	moved := 0
+	for _, m := range moves {
+		if _, err := os.Lstat(m.from); err != nil {
+			continue
+		}
+		if _, err := os.Lstat(m.to); err == nil {
+			fmt.Fprintf(cm.warnings, "Warning: left %s in place because %s already exists\n", m.from, m.to)
+			continue
+		}
+		if err := cm.moveEntry(m.from, m.to); err != nil {
+			return fmt.Errorf("failed to move %s to %s: %w", m.from, m.to, err)
+		}
+		moved++
+	}
+
+	pointer, err := cm.layout.PointerContent()
+	if err != nil {
+		return err
+	}
+	if err := cm.fs.WriteFile(cm.layout.PointerFile(), pointer, 0644, false); err != nil {
+		return fmt.Errorf("failed to write %s: %w", cm.layout.PointerFile(), err)
+	}
+	cm.layout.Migrated = true
+
+	if moved == 0 {
+		return nil
+	}
+	fmt.Fprintf(cm.warnings, "Moved cc-switch state to %s (XDG layout); %s records the new location.\n",
+		cm.layout.DataDir, cm.layout.PointerFile())
+	return nil
+}
+
+// moveEntry 移动文件或目录，跨设备无法重命名时改为复制后删除
+func (cm *ConfigManager) moveEntry(from, to string) error {
+	if err := cm.fs.Rename(from, to); err == nil {
+		return nil
+	}
+
+	err := filepath.WalkDir(from, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(to, rel)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return cm.fs.MkdirAll(target, info.Mode().Perm())
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return cm.fs.WriteFile(target, data, info.Mode().Perm(), true)
+	})
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(from)
+}
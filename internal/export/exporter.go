@@ -6,20 +6,26 @@ import (
 	"path/filepath"
 	"time"
 
-	"cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/config"
 )
 
 // Exporter interface defines export operations
 type Exporter interface {
 	ExportProfile(name string, password string, outputPath string) error
+	ExportProfiles(names []string, password string, outputPath string) error
 	ExportAll(password string, outputPath string) error
 	ExportCurrent(password string, outputPath string) error
 }
 
 // ExporterImpl implements the Exporter interface
 type ExporterImpl struct {
-	configManager *config.ConfigManager
-	ccxHandler    *CCXHandler
+	configManager    *config.ConfigManager
+	ccxHandler       *CCXHandler
+	includeSecrets   bool
+	includeTemplates bool
+	includeDefault   bool
+	templateNames    []string
+	progress         ProgressFunc
 }
 
 // NewExporter creates a new exporter instance
@@ -30,75 +36,214 @@ func NewExporter(configManager *config.ConfigManager) *ExporterImpl {
 	}
 }
 
+// SetIncludeSecrets controls whether secrets referenced with @secret: are embedded in the export
+func (e *ExporterImpl) SetIncludeSecrets(include bool) {
+	e.includeSecrets = include
+}
+
+// SetIncludeTemplates controls whether all templates are embedded in the export
+func (e *ExporterImpl) SetIncludeTemplates(include bool) {
+	e.includeTemplates = include
+}
+
+// SetIncludeDefaultTemplate controls whether the default template is embedded
+// when all templates are included
+func (e *ExporterImpl) SetIncludeDefaultTemplate(include bool) {
+	e.includeDefault = include
+}
+
+// SetTemplates embeds only the named templates instead of all of them
+func (e *ExporterImpl) SetTemplates(names []string) {
+	e.templateNames = names
+}
+
+// SetProgress registers a callback invoked after each profile has been written
+func (e *ExporterImpl) SetProgress(progress ProgressFunc) {
+	e.progress = progress
+}
+
+// ExportTemplates writes a template-only archive with the templates selected by
+// SetTemplates or SetIncludeTemplates, and returns their names
+func (e *ExporterImpl) ExportTemplates(password string, outputPath string) ([]string, error) {
+	tail := &ExportData{}
+	if err := e.attachTemplates(tail); err != nil {
+		return nil, err
+	}
+	if len(tail.Templates) == 0 {
+		return nil, fmt.Errorf("no templates found to export")
+	}
+
+	stream, err := e.startStream(password, outputPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Commit(outputPath, tail); err != nil {
+		return nil, fmt.Errorf("failed to write export data: %w", err)
+	}
+
+	names := make([]string, 0, len(tail.Templates))
+	for _, template := range tail.Templates {
+		names = append(names, template.Name)
+	}
+	return names, nil
+}
+
 // ExportProfile exports a single profile
 func (e *ExporterImpl) ExportProfile(name string, password string, outputPath string) error {
-	// Validate profile exists
-	if !e.configManager.ProfileExists(name) {
-		return fmt.Errorf("profile '%s' does not exist", name)
+	return e.ExportProfiles([]string{name}, password, outputPath)
+}
+
+// ExportProfiles exports the named profiles into a single file
+func (e *ExporterImpl) ExportProfiles(names []string, password string, outputPath string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("no profiles specified")
 	}
 
-	// Get profile content
-	content, metadata, err := e.configManager.GetProfileContent(name)
+	for _, name := range names {
+		if !e.configManager.ProfileExists(name) {
+			return config.NotFoundf("profile '%s' does not exist", name)
+		}
+	}
+
+	_, _, err := e.streamProfiles(names, false, password, outputPath)
+	return err
+}
+
+// ExportAll exports all readable profiles; unreadable ones are skipped with a warning
+func (e *ExporterImpl) ExportAll(password string, outputPath string) error {
+	names, _, err := e.listExportableProfiles(false)
 	if err != nil {
-		return fmt.Errorf("failed to read profile '%s': %w", name, err)
+		return err
 	}
 
-	// Create export data
-	exportData := &ExportData{
-		Profiles: []ProfileData{
-			{
-				Name:      metadata.Name,
-				IsCurrent: metadata.IsCurrent,
-				Content:   content,
-				Metadata: ProfileMetadata{
-					CreatedAt:  time.Now().UTC().Format(time.RFC3339),
-					ModifiedAt: time.Now().UTC().Format(time.RFC3339),
-				},
-			},
-		},
+	exported, _, err := e.streamProfiles(names, false, password, outputPath)
+	if err != nil {
+		return err
+	}
+	if len(exported) == 0 {
+		return fmt.Errorf("no readable profiles found to export")
+	}
+	return nil
+}
+
+// ExportReadable exports every profile that can be read and parsed, skipping broken ones
+// instead of failing. It returns the exported and skipped profile names; when nothing
+// is readable no file is written.
+func (e *ExporterImpl) ExportReadable(password string, outputPath string) ([]string, []string, error) {
+	names, skipped, err := e.listExportableProfiles(true)
+	if err != nil {
+		return nil, skipped, err
 	}
 
-	return e.writeExportFile(exportData, password, outputPath)
+	exported, unreadable, err := e.streamProfiles(names, true, password, outputPath)
+	skipped = append(skipped, unreadable...)
+	if err != nil {
+		return nil, skipped, err
+	}
+	return exported, skipped, nil
 }
 
-// ExportAll exports all profiles
-func (e *ExporterImpl) ExportAll(password string, outputPath string) error {
-	// Get all profiles
+// listExportableProfiles returns the names of all profiles, skipping (with a warning)
+// files that cannot be opened
+func (e *ExporterImpl) listExportableProfiles(lenient bool) ([]string, []string, error) {
 	profiles, err := e.configManager.ListProfiles()
 	if err != nil {
-		return fmt.Errorf("failed to list profiles: %w", err)
+		return nil, nil, fmt.Errorf("failed to list profiles: %w", err)
 	}
 
-	if len(profiles) == 0 {
-		return fmt.Errorf("no profiles found to export")
+	if len(profiles) == 0 && !lenient {
+		return nil, nil, fmt.Errorf("no profiles found to export")
 	}
 
-	// Create export data
-	exportData := &ExportData{
-		Profiles: make([]ProfileData, 0, len(profiles)),
+	names := make([]string, 0, len(profiles))
+	var skipped []string
+	for _, profile := range profiles {
+		if profile.Error != "" {
+			fmt.Fprintf(e.configManager.Warnings(), "Warning: skipping profile '%s': %s\n", profile.Name, profile.Error)
+			skipped = append(skipped, profile.Name)
+			continue
+		}
+		names = append(names, profile.Name)
 	}
+	return names, skipped, nil
+}
 
-	// Collect all profile data
-	for _, profile := range profiles {
-		content, _, err := e.configManager.GetProfileContent(profile.Name)
+// streamProfiles reads the named profiles one at a time and streams them into the export
+// file, so memory use does not grow with the number of profiles. With lenient set,
+// profiles whose content cannot be read are skipped instead of failing the export.
+// It returns the exported and skipped names; when nothing was exported no file is written.
+func (e *ExporterImpl) streamProfiles(names []string, lenient bool, password string, outputPath string) ([]string, []string, error) {
+	stream, err := e.startStream(password, outputPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer stream.Discard()
+
+	var skipped []string
+	referenced := make(map[string]bool)
+	for i, name := range names {
+		content, metadata, err := e.configManager.GetProfileContent(name)
 		if err != nil {
-			return fmt.Errorf("failed to read profile '%s': %w", profile.Name, err)
+			if !lenient {
+				return nil, nil, fmt.Errorf("failed to read profile '%s': %w", name, err)
+			}
+			fmt.Fprintf(e.configManager.Warnings(), "Warning: skipping profile '%s': %v\n", name, err)
+			skipped = append(skipped, name)
+		} else {
+			for _, secret := range config.FindSecretReferences(content) {
+				referenced[secret] = true
+			}
+
+			err = stream.WriteProfile(ProfileData{
+				Name:      metadata.Name,
+				IsCurrent: metadata.IsCurrent,
+				Content:   content,
+				Metadata: ProfileMetadata{
+					CreatedAt:  time.Now().UTC().Format(time.RFC3339),
+					ModifiedAt: time.Now().UTC().Format(time.RFC3339),
+				},
+			})
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to write export data: %w", err)
+			}
 		}
 
-		profileData := ProfileData{
-			Name:      profile.Name,
-			IsCurrent: profile.IsCurrent,
-			Content:   content,
-			Metadata: ProfileMetadata{
-				CreatedAt:  time.Now().UTC().Format(time.RFC3339),
-				ModifiedAt: time.Now().UTC().Format(time.RFC3339),
-			},
+		if e.progress != nil {
+			e.progress(Progress{
+				Name:     name,
+				Profiles: i + 1,
+				Total:    len(names),
+				Bytes:    stream.BytesWritten(),
+				Percent:  (i + 1) * 100 / len(names),
+			})
 		}
+	}
 
-		exportData.Profiles = append(exportData.Profiles, profileData)
+	exported := stream.Profiles()
+	if len(exported) == 0 {
+		return exported, skipped, nil
+	}
+
+	tail := &ExportData{}
+	if err := e.attachSecrets(tail, referenced); err != nil {
+		return nil, nil, err
+	}
+	if err := e.attachTemplates(tail); err != nil {
+		return nil, nil, err
 	}
+	if err := stream.Commit(outputPath, tail); err != nil {
+		return nil, nil, fmt.Errorf("failed to write export data: %w", err)
+	}
+	return exported, skipped, nil
+}
 
-	return e.writeExportFile(exportData, password, outputPath)
+// startStream creates the output directory and starts a streaming export next to outputPath
+func (e *ExporterImpl) startStream(password string, outputPath string) (*StreamWriter, error) {
+	outputDir := filepath.Dir(outputPath)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	return e.ccxHandler.NewStreamWriter(outputDir, password)
 }
 
 // ExportCurrent exports the current active profile
@@ -116,32 +261,60 @@ func (e *ExporterImpl) ExportCurrent(password string, outputPath string) error {
 	return e.ExportProfile(currentProfile, password, outputPath)
 }
 
-// writeExportFile writes export data to file
-func (e *ExporterImpl) writeExportFile(data *ExportData, password string, outputPath string) error {
-	// Ensure output directory exists
-	outputDir := filepath.Dir(outputPath)
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+// attachSecrets embeds the referenced secrets, or warns that references are exported as-is
+func (e *ExporterImpl) attachSecrets(data *ExportData, referenced map[string]bool) error {
+	if len(referenced) == 0 {
+		return nil
 	}
 
-	// Create output file
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
+	if !e.includeSecrets {
+		fmt.Fprintf(e.configManager.Warnings(), "Warning: exported profiles reference %d secret(s) that are not included; use --include-secrets to embed them\n", len(referenced))
+		return nil
 	}
-	defer file.Close()
 
-	// Set restrictive permissions for the export file
-	if err := file.Chmod(0600); err != nil {
-		return fmt.Errorf("failed to set file permissions: %w", err)
+	data.Secrets = make(map[string]string, len(referenced))
+	for name := range referenced {
+		value, err := e.configManager.GetSecret(name)
+		if err != nil {
+			return err
+		}
+		data.Secrets[name] = value
 	}
+	return nil
+}
 
-	// Write data using CCX format
-	if err := e.ccxHandler.Write(data, file, password); err != nil {
-		// Clean up the file on error
-		os.Remove(outputPath)
-		return fmt.Errorf("failed to write export data: %w", err)
+// attachTemplates embeds the templates selected with SetTemplates, or every readable
+// template (except default unless SetIncludeDefaultTemplate) when templates are included
+func (e *ExporterImpl) attachTemplates(data *ExportData) error {
+	if len(e.templateNames) > 0 {
+		for _, name := range e.templateNames {
+			content, err := e.configManager.GetTemplateContent(name)
+			if err != nil {
+				return fmt.Errorf("failed to read template '%s': %w", name, err)
+			}
+			data.Templates = append(data.Templates, TemplateData{Name: name, Content: content})
+		}
+		return nil
 	}
 
+	if !e.includeTemplates {
+		return nil
+	}
+
+	names, err := e.configManager.ListTemplates()
+	if err != nil {
+		return fmt.Errorf("failed to list templates: %w", err)
+	}
+	for _, name := range names {
+		if name == "default" && !e.includeDefault {
+			continue
+		}
+		content, err := e.configManager.GetTemplateContent(name)
+		if err != nil {
+			fmt.Fprintf(e.configManager.Warnings(), "Warning: skipping template '%s': %v\n", name, err)
+			continue
+		}
+		data.Templates = append(data.Templates, TemplateData{Name: name, Content: content})
+	}
 	return nil
 }
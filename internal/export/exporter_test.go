@@ -0,0 +1,81 @@
+package export
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/config/configtest"
+)
+
+// newTestExporter creates an exporter on a configuration manager in a temporary home,
+// with warnings written to the returned buffer
+func newTestExporter(t *testing.T) (*ExporterImpl, *config.ConfigManager, *bytes.Buffer) {
+	t.Helper()
+	var warnings bytes.Buffer
+	cm := configtest.NewManager(t, config.Options{Warnings: &warnings})
+	return NewExporter(cm), cm, &warnings
+}
+
+// addBrokenSymlink adds a profile whose file is a symlink to nothing
+func addBrokenSymlink(t *testing.T, cm *config.ConfigManager, name string) {
+	t.Helper()
+	path := filepath.Join(cm.GetProfilesDir(), name+".json")
+	if err := os.Symlink("missing-target", path); err != nil {
+		t.Skipf("cannot create symlinks: %v", err)
+	}
+}
+
+func TestExportAllSkipsUnreadableProfiles(t *testing.T) {
+	exporter, cm, warnings := newTestExporter(t)
+	if err := cm.CreateProfileWithContent("work", map[string]interface{}{"model": "opus"}); err != nil {
+		t.Fatal(err)
+	}
+	addBrokenSymlink(t, cm, "broken")
+
+	output := filepath.Join(t.TempDir(), "all.ccx")
+	if err := exporter.ExportAll("password", output); err != nil {
+		t.Fatalf("ExportAll: %v", err)
+	}
+	if _, err := os.Stat(output); err != nil {
+		t.Errorf("export file not written: %v", err)
+	}
+	if !strings.Contains(warnings.String(), "skipping profile 'broken': broken symlink") {
+		t.Errorf("warnings = %q, want one for the broken profile", warnings.String())
+	}
+}
+
+func TestExportReadableCountsSkippedProfiles(t *testing.T) {
+	exporter, cm, _ := newTestExporter(t)
+	if err := cm.CreateProfileWithContent("work", map[string]interface{}{"model": "opus"}); err != nil {
+		t.Fatal(err)
+	}
+	addBrokenSymlink(t, cm, "broken")
+
+	exported, skipped, err := exporter.ExportReadable("password", filepath.Join(t.TempDir(), "all.ccx"))
+	if err != nil {
+		t.Fatalf("ExportReadable: %v", err)
+	}
+	if len(exported) != 1 || exported[0] != "work" {
+		t.Errorf("exported = %v, want [work]", exported)
+	}
+	if len(skipped) != 1 || skipped[0] != "broken" {
+		t.Errorf("skipped = %v, want [broken]", skipped)
+	}
+}
+
+func TestExportAllFailsWhenNothingIsReadable(t *testing.T) {
+	exporter, cm, _ := newTestExporter(t)
+	addBrokenSymlink(t, cm, "broken")
+
+	output := filepath.Join(t.TempDir(), "all.ccx")
+	if err := exporter.ExportAll("password", output); err == nil {
+		t.Fatal("ExportAll succeeded with no readable profiles")
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("export file exists after a failed export: %v", err)
+	}
+}
@@ -7,21 +7,32 @@ import (
 	"fmt"
 	"hash/crc32"
 	"io"
-	"time"
+	"math"
 
-	"cc-switch/internal/common"
+	"github.com/HoBeedzc/cc-switch/internal/common"
 )
 
 const (
 	// CCX file format magic number
 	MagicNumber = "CCX1"
 	Version     = 1
+	// VersionChunked is written for encrypted files, whose payload is encrypted in
+	// chunks (see common.NewEncryptWriter) so exports can be streamed
+	VersionChunked = 2
 
 	// Flags
 	FlagEncrypted  = 1 << 0
 	FlagCompressed = 1 << 1
 )
 
+// Export types recorded in CCXMetadata.ExportType
+const (
+	ExportTypeSingle   = "single"
+	ExportTypeMultiple = "multiple"
+	// ExportTypeTemplates marks a template-only archive; importing it never creates profiles
+	ExportTypeTemplates = "templates"
+)
+
 // CCXHeader represents the CCX file header
 type CCXHeader struct {
 	Magic     [4]byte // "CCX1"
@@ -34,13 +45,14 @@ type CCXHeader struct {
 
 // CCXMetadata contains file metadata
 type CCXMetadata struct {
-	Version       string `json:"version"`
-	ExportedAt    string `json:"exported_at"`
-	ToolVersion   string `json:"tool_version"`
-	ExportType    string `json:"export_type"`
-	ProfilesCount int    `json:"profiles_count"`
-	Encryption    string `json:"encryption"`
-	Compression   string `json:"compression"`
+	Version        string `json:"version"`
+	ExportedAt     string `json:"exported_at"`
+	ToolVersion    string `json:"tool_version"`
+	ExportType     string `json:"export_type"`
+	ProfilesCount  int    `json:"profiles_count"`
+	TemplatesCount int    `json:"templates_count,omitempty"`
+	Encryption     string `json:"encryption"`
+	Compression    string `json:"compression"`
 }
 
 // ProfileData represents a profile in the export
@@ -59,7 +71,15 @@ type ProfileMetadata struct {
 
 // ExportData represents the complete export structure
 type ExportData struct {
-	Profiles []ProfileData `json:"profiles"`
+	Profiles  []ProfileData     `json:"profiles"`
+	Secrets   map[string]string `json:"secrets,omitempty"`   // Secrets referenced with @secret:, only with --include-secrets
+	Templates []TemplateData    `json:"templates,omitempty"` // Templates, in backups and template exports
+}
+
+// TemplateData represents a template in the export
+type TemplateData struct {
+	Name    string                 `json:"name"`
+	Content map[string]interface{} `json:"content"`
 }
 
 // CCXHandler handles CCX file format operations
@@ -70,97 +90,6 @@ func NewCCXHandler() *CCXHandler {
 	return &CCXHandler{}
 }
 
-// Write writes export data to CCX format
-func (h *CCXHandler) Write(data *ExportData, writer io.Writer, password string) error {
-	// Create metadata
-	metadata := CCXMetadata{
-		Version:       common.Version,
-		ExportedAt:    time.Now().UTC().Format(time.RFC3339),
-		ToolVersion:   "cc-switch v" + common.Version,
-		ExportType:    h.getExportType(data),
-		ProfilesCount: len(data.Profiles),
-		Encryption:    "aes-256-gcm",
-		Compression:   "gzip",
-	}
-
-	// Serialize metadata
-	metadataBytes, err := json.Marshal(metadata)
-	if err != nil {
-		return fmt.Errorf("failed to serialize metadata: %w", err)
-	}
-
-	// Serialize payload
-	payloadBytes, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to serialize payload: %w", err)
-	}
-
-	// Compress payload
-	compressedPayload, err := common.CompressData(payloadBytes)
-	if err != nil {
-		return fmt.Errorf("failed to compress payload: %w", err)
-	}
-
-	// Encrypt payload if password provided
-	var finalPayload []byte
-	flags := uint32(FlagCompressed)
-
-	if password != "" {
-		encData, err := common.EncryptData(compressedPayload, password)
-		if err != nil {
-			return fmt.Errorf("failed to encrypt payload: %w", err)
-		}
-
-		// Serialize encryption data
-		encBytes, err := h.serializeEncryptionData(encData)
-		if err != nil {
-			return fmt.Errorf("failed to serialize encryption data: %w", err)
-		}
-
-		finalPayload = encBytes
-		flags |= FlagEncrypted
-	} else {
-		finalPayload = compressedPayload
-	}
-
-	// Prepare metadata with length prefix for checksum calculation
-	var metadataWithLength bytes.Buffer
-	if err := h.writeWithLength(&metadataWithLength, metadataBytes); err != nil {
-		return fmt.Errorf("failed to prepare metadata for checksum: %w", err)
-	}
-	metadataWithLengthBytes := metadataWithLength.Bytes()
-
-	// Calculate checksum
-	checksum := crc32.ChecksumIEEE(append(metadataWithLengthBytes, finalPayload...))
-
-	// Create header
-	header := CCXHeader{
-		Version:   Version,
-		Flags:     flags,
-		Timestamp: time.Now().Unix(),
-		DataLen:   uint64(len(metadataWithLengthBytes) + len(finalPayload)),
-		Checksum:  checksum,
-	}
-	copy(header.Magic[:], MagicNumber)
-
-	// Write header
-	if err := binary.Write(writer, binary.LittleEndian, header); err != nil {
-		return fmt.Errorf("failed to write header: %w", err)
-	}
-
-	// Write metadata with length prefix
-	if _, err := writer.Write(metadataWithLengthBytes); err != nil {
-		return fmt.Errorf("failed to write metadata: %w", err)
-	}
-
-	// Write payload
-	if _, err := writer.Write(finalPayload); err != nil {
-		return fmt.Errorf("failed to write payload: %w", err)
-	}
-
-	return nil
-}
-
 // Read reads export data from CCX format
 func (h *CCXHandler) Read(reader io.Reader, password string) (*ExportData, error) {
 	// Read and validate header
@@ -173,7 +102,7 @@ func (h *CCXHandler) Read(reader io.Reader, password string) (*ExportData, error
 		return nil, fmt.Errorf("invalid file format: magic number mismatch")
 	}
 
-	if header.Version != Version {
+	if header.Version != Version && header.Version != VersionChunked {
 		return nil, fmt.Errorf("unsupported file version: %d", header.Version)
 	}
 
@@ -183,10 +112,13 @@ func (h *CCXHandler) Read(reader io.Reader, password string) (*ExportData, error
 		return nil, fmt.Errorf("failed to read metadata: %w", err)
 	}
 
-	// Read payload
-	payloadSize := header.DataLen - uint64(len(metadataBytes)) - 4 // 4 bytes for metadata length
-	payloadBytes := make([]byte, payloadSize)
-	if _, err := io.ReadFull(reader, payloadBytes); err != nil {
+	// Read payload; DataLen comes from the file, so it is checked before anything is allocated
+	metadataSize := uint64(len(metadataBytes)) + 4 // 4 bytes for metadata length
+	if header.DataLen < metadataSize {
+		return nil, fmt.Errorf("invalid file format: data length %d is smaller than the metadata", header.DataLen)
+	}
+	payloadBytes, err := readSection(reader, header.DataLen-metadataSize)
+	if err != nil {
 		return nil, fmt.Errorf("failed to read payload: %w", err)
 	}
 
@@ -210,14 +142,9 @@ func (h *CCXHandler) Read(reader io.Reader, password string) (*ExportData, error
 			return nil, fmt.Errorf("file is encrypted but no password provided")
 		}
 
-		encData, err := h.deserializeEncryptionData(payloadBytes)
-		if err != nil {
-			return nil, fmt.Errorf("failed to deserialize encryption data: %w", err)
-		}
-
-		decrypted, err := common.DecryptData(encData, password)
+		decrypted, err := h.decryptPayload(header.Version, payloadBytes, password)
 		if err != nil {
-			return nil, fmt.Errorf("failed to decrypt payload: %w", err)
+			return nil, err
 		}
 
 		compressedPayload = decrypted
@@ -258,7 +185,7 @@ func (h *CCXHandler) ValidateFile(reader io.Reader) (*CCXMetadata, error) {
 		return nil, fmt.Errorf("invalid file format: magic number mismatch")
 	}
 
-	if header.Version != Version {
+	if header.Version != Version && header.Version != VersionChunked {
 		return nil, fmt.Errorf("unsupported file version: %d", header.Version)
 	}
 
@@ -279,11 +206,40 @@ func (h *CCXHandler) ValidateFile(reader io.Reader) (*CCXMetadata, error) {
 
 // Helper methods
 
-func (h *CCXHandler) getExportType(data *ExportData) string {
-	if len(data.Profiles) == 1 {
-		return "single"
+func exportType(profiles, templates int) string {
+	if profiles == 0 && templates > 0 {
+		return ExportTypeTemplates
+	}
+	if profiles == 1 {
+		return ExportTypeSingle
+	}
+	return ExportTypeMultiple
+}
+
+// decryptPayload decrypts a version 1 payload encrypted in one piece, or a chunked one
+func (h *CCXHandler) decryptPayload(version uint32, payload []byte, password string) ([]byte, error) {
+	if version == VersionChunked {
+		reader, err := common.NewDecryptReader(bytes.NewReader(payload), password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to deserialize encryption data: %w", err)
+		}
+		decrypted, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt payload: %w", err)
+		}
+		return decrypted, nil
+	}
+
+	encData, err := h.deserializeEncryptionData(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize encryption data: %w", err)
+	}
+
+	decrypted, err := common.DecryptData(encData, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload: %w", err)
 	}
-	return "multiple"
+	return decrypted, nil
 }
 
 func (h *CCXHandler) writeWithLength(writer io.Writer, data []byte) error {
@@ -305,37 +261,46 @@ func (h *CCXHandler) readWithLength(reader io.Reader) ([]byte, error) {
 		return nil, err
 	}
 
-	// Read data
-	data := make([]byte, length)
-	if _, err := io.ReadFull(reader, data); err != nil {
-		return nil, err
-	}
-
-	return data, nil
+	return readSection(reader, uint64(length))
 }
 
-func (h *CCXHandler) serializeEncryptionData(encData *common.EncryptionData) ([]byte, error) {
-	var buf bytes.Buffer
-
-	// Write salt length and salt
-	if err := binary.Write(&buf, binary.LittleEndian, uint32(len(encData.Salt))); err != nil {
-		return nil, err
+// readSection reads exactly n bytes whose length was declared by the file. A length
+// beyond the end of a seekable reader is rejected up front, and the buffer only grows
+// with the data actually read, so a corrupt or crafted length cannot force a huge allocation.
+func readSection(reader io.Reader, n uint64) ([]byte, error) {
+	if seeker, ok := reader.(io.Seeker); ok {
+		if remaining, err := remainingBytes(seeker); err == nil && n > remaining {
+			return nil, fmt.Errorf("declared length %d exceeds the %d bytes left in the file", n, remaining)
+		}
 	}
-	buf.Write(encData.Salt)
-
-	// Write nonce length and nonce
-	if err := binary.Write(&buf, binary.LittleEndian, uint32(len(encData.Nonce))); err != nil {
-		return nil, err
+	if n > math.MaxInt64 {
+		return nil, fmt.Errorf("declared length %d is too large", n)
 	}
-	buf.Write(encData.Nonce)
 
-	// Write encrypted data length and data
-	if err := binary.Write(&buf, binary.LittleEndian, uint32(len(encData.Encrypted))); err != nil {
+	data, err := io.ReadAll(io.LimitReader(reader, int64(n)))
+	if err != nil {
 		return nil, err
 	}
-	buf.Write(encData.Encrypted)
+	if uint64(len(data)) != n {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return data, nil
+}
 
-	return buf.Bytes(), nil
+// remainingBytes returns how many bytes are left after the current position of a seeker
+func remainingBytes(seeker io.Seeker) (uint64, error) {
+	current, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := seeker.Seek(current, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return uint64(max(end-current, 0)), nil
 }
 
 func (h *CCXHandler) deserializeEncryptionData(data []byte) (*common.EncryptionData, error) {
@@ -346,8 +311,8 @@ func (h *CCXHandler) deserializeEncryptionData(data []byte) (*common.EncryptionD
 	if err := binary.Read(reader, binary.LittleEndian, &saltLen); err != nil {
 		return nil, err
 	}
-	salt := make([]byte, saltLen)
-	if _, err := io.ReadFull(reader, salt); err != nil {
+	salt, err := readSection(reader, uint64(saltLen))
+	if err != nil {
 		return nil, err
 	}
 
@@ -356,8 +321,8 @@ func (h *CCXHandler) deserializeEncryptionData(data []byte) (*common.EncryptionD
 	if err := binary.Read(reader, binary.LittleEndian, &nonceLen); err != nil {
 		return nil, err
 	}
-	nonce := make([]byte, nonceLen)
-	if _, err := io.ReadFull(reader, nonce); err != nil {
+	nonce, err := readSection(reader, uint64(nonceLen))
+	if err != nil {
 		return nil, err
 	}
 
@@ -366,8 +331,8 @@ func (h *CCXHandler) deserializeEncryptionData(data []byte) (*common.EncryptionD
 	if err := binary.Read(reader, binary.LittleEndian, &encLen); err != nil {
 		return nil, err
 	}
-	encrypted := make([]byte, encLen)
-	if _, err := io.ReadFull(reader, encrypted); err != nil {
+	encrypted, err := readSection(reader, uint64(encLen))
+	if err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,120 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// dataLenOffset is the position of CCXHeader.DataLen in an encoded file
+const dataLenOffset = 4 + 4 + 4 + 8
+
+// writeTestExport writes a CCX file with the given profiles and returns its bytes
+func writeTestExport(t *testing.T, password string, names ...string) []byte {
+	t.Helper()
+	dir := t.TempDir()
+
+	writer, err := NewCCXHandler().NewStreamWriter(dir, password)
+	if err != nil {
+		t.Fatalf("NewStreamWriter: %v", err)
+	}
+	for _, name := range names {
+		profile := ProfileData{Name: name, Content: map[string]interface{}{"env": map[string]interface{}{"ANTHROPIC_BASE_URL": "https://" + name}}}
+		if err := writer.WriteProfile(profile); err != nil {
+			t.Fatalf("WriteProfile: %v", err)
+		}
+	}
+
+	path := filepath.Join(dir, "export.ccx")
+	if err := writer.Commit(path, &ExportData{}); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// withDataLen returns a copy of data whose header declares the given DataLen
+func withDataLen(data []byte, dataLen uint64) []byte {
+	crafted := bytes.Clone(data)
+	binary.LittleEndian.PutUint64(crafted[dataLenOffset:], dataLen)
+	return crafted
+}
+
+// onlyReader hides the Seek method of the wrapped reader, like a network stream
+type onlyReader struct{ io.Reader }
+
+func TestReadRoundTrip(t *testing.T) {
+	for _, password := range []string{"", "correct horse"} {
+		data := writeTestExport(t, password, "work", "personal")
+
+		exportData, err := NewCCXHandler().Read(bytes.NewReader(data), password)
+		if err != nil {
+			t.Fatalf("Read (password %q): %v", password, err)
+		}
+		if len(exportData.Profiles) != 2 || exportData.Profiles[0].Name != "work" || exportData.Profiles[1].Name != "personal" {
+			t.Fatalf("Read (password %q) returned profiles %+v", password, exportData.Profiles)
+		}
+	}
+}
+
+func TestReadRejectsCraftedLengths(t *testing.T) {
+	valid := writeTestExport(t, "", "work")
+	validLen := binary.LittleEndian.Uint64(valid[dataLenOffset:])
+
+	truncatedMetadata := bytes.Clone(valid[:binary.Size(CCXHeader{})])
+	truncatedMetadata = binary.LittleEndian.AppendUint32(truncatedMetadata, 1<<31)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		seekable bool
+		wantErr  string
+	}{
+		{name: "data length smaller than metadata", data: withDataLen(valid, 3), seekable: true, wantErr: "smaller than the metadata"},
+		{name: "data length zero", data: withDataLen(valid, 0), seekable: true, wantErr: "smaller than the metadata"},
+		{name: "data length past end of file", data: withDataLen(valid, validLen+1), seekable: true, wantErr: "exceeds the"},
+		{name: "huge data length", data: withDataLen(valid, 1<<62), seekable: true, wantErr: "exceeds the"},
+		{name: "maximum data length", data: withDataLen(valid, ^uint64(0)), seekable: true, wantErr: "exceeds the"},
+		{name: "huge data length from a stream", data: withDataLen(valid, 1<<40), seekable: false, wantErr: "unexpected EOF"},
+		{name: "huge metadata length", data: truncatedMetadata, seekable: true, wantErr: "failed to read metadata"},
+		{name: "huge metadata length from a stream", data: truncatedMetadata, seekable: false, wantErr: "failed to read metadata"},
+		{name: "truncated payload", data: valid[:len(valid)-5], seekable: true, wantErr: "exceeds the"},
+		{name: "header only", data: valid[:binary.Size(CCXHeader{})], seekable: true, wantErr: "failed to read metadata"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reader io.Reader = bytes.NewReader(tt.data)
+			if !tt.seekable {
+				reader = onlyReader{reader}
+			}
+
+			_, err := NewCCXHandler().Read(reader, "")
+			if err == nil {
+				t.Fatalf("expected an error containing %q", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadRejectsCorruptEncryptionFields(t *testing.T) {
+	// A version 1 encrypted payload starts with length-prefixed salt, nonce and ciphertext
+	var payload bytes.Buffer
+	binary.Write(&payload, binary.LittleEndian, uint32(0xFFFFFFF0))
+	payload.WriteString("short")
+
+	_, err := NewCCXHandler().deserializeEncryptionData(payload.Bytes())
+	if err == nil || !strings.Contains(err.Error(), "exceeds the") {
+		t.Fatalf("error = %v, want a declared length error", err)
+	}
+}
@@ -0,0 +1,255 @@
+package export
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/HoBeedzc/cc-switch/internal/common"
+)
+
+// Progress describes how far an export has got
+type Progress struct {
+	Name     string `json:"name"`     // Profile just processed
+	Profiles int    `json:"profiles"` // Profiles processed so far, including skipped ones
+	Total    int    `json:"total"`    // Profiles selected for export
+	Bytes    int64  `json:"bytes"`    // Bytes of (compressed) payload written so far
+	Percent  int    `json:"percent"`  // Profiles as a percentage of Total
+}
+
+// ProgressFunc is invoked after each profile has been processed
+type ProgressFunc func(progress Progress)
+
+// StreamWriter writes a CCX file without holding the whole export in memory.
+// Profiles are encoded one at a time into the JSON payload, which is compressed and
+// (with a password) encrypted on the fly into a temporary file next to the output.
+// The header records the payload length and checksum, so it is only written by Commit,
+// followed by a copy of the finished payload.
+type StreamWriter struct {
+	password string
+	temp     *os.File
+	counter  *countingWriter
+	cipher   io.WriteCloser // nil without a password
+	gzip     *gzip.Writer
+	profiles int
+	names    []string
+}
+
+// NewStreamWriter starts a streaming export whose temporary payload file is created in dir
+func (h *CCXHandler) NewStreamWriter(dir string, password string) (*StreamWriter, error) {
+	temp, err := os.CreateTemp(dir, ".cc-switch-export-*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	if err := temp.Chmod(0600); err != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+		return nil, fmt.Errorf("failed to set file permissions: %w", err)
+	}
+
+	s := &StreamWriter{password: password, temp: temp, counter: &countingWriter{w: temp}}
+	var sink io.Writer = s.counter
+	if password != "" {
+		s.cipher, err = common.NewEncryptWriter(s.counter, password)
+		if err != nil {
+			s.Discard()
+			return nil, fmt.Errorf("failed to encrypt payload: %w", err)
+		}
+		sink = s.cipher
+	}
+	s.gzip = gzip.NewWriter(sink)
+
+	if _, err := io.WriteString(s.gzip, `{"profiles":[`); err != nil {
+		s.Discard()
+		return nil, fmt.Errorf("failed to write payload: %w", err)
+	}
+	return s, nil
+}
+
+// WriteProfile appends a profile to the payload
+func (s *StreamWriter) WriteProfile(profile ProfileData) error {
+	encoded, err := json.Marshal(profile)
+	if err != nil {
+		return fmt.Errorf("failed to serialize profile '%s': %w", profile.Name, err)
+	}
+	if s.profiles > 0 {
+		encoded = append([]byte{','}, encoded...)
+	}
+	if _, err := s.gzip.Write(encoded); err != nil {
+		return fmt.Errorf("failed to write payload: %w", err)
+	}
+	s.profiles++
+	s.names = append(s.names, profile.Name)
+	return nil
+}
+
+// Profiles returns the names of the profiles written so far
+func (s *StreamWriter) Profiles() []string {
+	return s.names
+}
+
+// BytesWritten returns the size of the payload written to disk so far
+func (s *StreamWriter) BytesWritten() int64 {
+	return s.counter.n
+}
+
+// Commit completes the payload with the secrets and templates of tail (its profiles
+// are ignored) and writes the CCX file to outputPath. The temporary files are removed
+// whether or not Commit succeeds, and outputPath is only replaced on success.
+func (s *StreamWriter) Commit(outputPath string, tail *ExportData) error {
+	defer s.Discard()
+
+	if err := s.finishPayload(tail); err != nil {
+		return err
+	}
+
+	metadata := CCXMetadata{
+		Version:        common.Version,
+		ExportedAt:     time.Now().UTC().Format(time.RFC3339),
+		ToolVersion:    "cc-switch v" + common.Version,
+		ExportType:     exportType(s.profiles, len(tail.Templates)),
+		ProfilesCount:  s.profiles,
+		TemplatesCount: len(tail.Templates),
+		Encryption:     "aes-256-gcm",
+		Compression:    "gzip",
+	}
+	flags := uint32(FlagCompressed)
+	version := uint32(Version)
+	if s.password == "" {
+		metadata.Encryption = "none"
+	} else {
+		flags |= FlagEncrypted
+		version = VersionChunked
+	}
+
+	metadataBytes, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to serialize metadata: %w", err)
+	}
+	metadataWithLength := make([]byte, 4, 4+len(metadataBytes))
+	binary.LittleEndian.PutUint32(metadataWithLength, uint32(len(metadataBytes)))
+	metadataWithLength = append(metadataWithLength, metadataBytes...)
+
+	// Write next to the output and rename into place, so a failed export never
+	// truncates or removes an existing file at outputPath
+	file, err := os.CreateTemp(filepath.Dir(outputPath), ".cc-switch-export-*.ccx")
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	if err := s.writeFile(file, version, flags, metadataWithLength); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	if err := os.Rename(file.Name(), outputPath); err != nil {
+		os.Remove(file.Name())
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	return nil
+}
+
+// Discard abandons the export and removes the temporary payload file
+func (s *StreamWriter) Discard() {
+	if s.temp == nil {
+		return
+	}
+	s.temp.Close()
+	os.Remove(s.temp.Name())
+	s.temp = nil
+}
+
+// finishPayload closes the profile array, appends secrets and templates and flushes
+// the compressor and cipher
+func (s *StreamWriter) finishPayload(tail *ExportData) error {
+	rest := []byte{']'}
+	if len(tail.Secrets) > 0 {
+		encoded, err := json.Marshal(tail.Secrets)
+		if err != nil {
+			return fmt.Errorf("failed to serialize secrets: %w", err)
+		}
+		rest = append(append(rest, `,"secrets":`...), encoded...)
+	}
+	if len(tail.Templates) > 0 {
+		encoded, err := json.Marshal(tail.Templates)
+		if err != nil {
+			return fmt.Errorf("failed to serialize templates: %w", err)
+		}
+		rest = append(append(rest, `,"templates":`...), encoded...)
+	}
+	rest = append(rest, '}')
+
+	if _, err := s.gzip.Write(rest); err != nil {
+		return fmt.Errorf("failed to write payload: %w", err)
+	}
+	if err := s.gzip.Close(); err != nil {
+		return fmt.Errorf("failed to compress payload: %w", err)
+	}
+	if s.cipher != nil {
+		if err := s.cipher.Close(); err != nil {
+			return fmt.Errorf("failed to encrypt payload: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeFile writes a placeholder header, the metadata and the payload, then rewrites
+// the header with the checksum computed while copying
+func (s *StreamWriter) writeFile(file *os.File, version, flags uint32, metadataWithLength []byte) error {
+	if err := file.Chmod(0600); err != nil {
+		return fmt.Errorf("failed to set file permissions: %w", err)
+	}
+
+	header := CCXHeader{
+		Version:   version,
+		Flags:     flags,
+		Timestamp: time.Now().Unix(),
+		DataLen:   uint64(len(metadataWithLength)) + uint64(s.counter.n),
+	}
+	copy(header.Magic[:], MagicNumber)
+	if err := binary.Write(file, binary.LittleEndian, header); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+	if _, err := file.Write(metadataWithLength); err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+
+	checksum := crc32.NewIEEE()
+	checksum.Write(metadataWithLength)
+	if _, err := s.temp.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read payload: %w", err)
+	}
+	if _, err := io.Copy(io.MultiWriter(file, checksum), s.temp); err != nil {
+		return fmt.Errorf("failed to write payload: %w", err)
+	}
+
+	header.Checksum = checksum.Sum32()
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+	if err := binary.Write(file, binary.LittleEndian, header); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+	return nil
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
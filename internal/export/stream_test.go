@@ -0,0 +1,163 @@
+package export
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// liveHeap returns the bytes of heap still reachable after a garbage collection
+func liveHeap() uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+func TestStreamWriterMemoryIsBounded(t *testing.T) {
+	if testing.Short() {
+		t.Skip("writes a large export")
+	}
+
+	const (
+		profiles    = 20000
+		valueLength = 1024 // bytes of random hex per profile, so the payload does not compress away
+	)
+
+	dir := t.TempDir()
+	writer, err := NewCCXHandler().NewStreamWriter(dir, "password")
+	if err != nil {
+		t.Fatalf("NewStreamWriter: %v", err)
+	}
+
+	random := make([]byte, valueLength/2)
+	baseline := liveHeap()
+	var peak uint64
+	for i := 0; i < profiles; i++ {
+		rand.Read(random)
+		profile := ProfileData{
+			Name: "profile-" + hex.EncodeToString(random[:4]),
+			Content: map[string]interface{}{
+				"env": map[string]interface{}{"ANTHROPIC_AUTH_TOKEN": hex.EncodeToString(random)},
+			},
+		}
+		if err := writer.WriteProfile(profile); err != nil {
+			t.Fatalf("WriteProfile %d: %v", i, err)
+		}
+		if i%2000 == 0 {
+			peak = max(peak, liveHeap())
+		}
+	}
+
+	payload := writer.BytesWritten()
+	if payload < profiles*valueLength/2 {
+		t.Fatalf("payload is only %d bytes; the test data compressed more than expected", payload)
+	}
+
+	// Only the profile names are kept in memory; the payload itself is on disk
+	const limit = 8 << 20
+	if peak > baseline && peak-baseline > limit {
+		t.Fatalf("live heap grew by %d bytes while writing a %d byte payload (limit %d)", peak-baseline, payload, limit)
+	}
+
+	output := filepath.Join(dir, "export.ccx")
+	if err := writer.Commit(output, &ExportData{}); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if len(writer.Profiles()) != profiles {
+		t.Fatalf("Profiles() has %d names, want %d", len(writer.Profiles()), profiles)
+	}
+	info, err := os.Stat(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() < payload {
+		t.Fatalf("export file is %d bytes, smaller than the %d byte payload", info.Size(), payload)
+	}
+}
+
+func TestStreamWriterCommitReplacesOnlyOnSuccess(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "export.ccx")
+	previous := []byte("previous export")
+	if err := os.WriteFile(output, previous, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A payload that cannot be copied into the output leaves the previous export alone.
+	// The payload finishes into io.Discard, so the failure happens while writing the file.
+	failing, err := NewCCXHandler().NewStreamWriter(dir, "")
+	if err != nil {
+		t.Fatalf("NewStreamWriter: %v", err)
+	}
+	if err := failing.WriteProfile(ProfileData{Name: "work"}); err != nil {
+		t.Fatalf("WriteProfile: %v", err)
+	}
+	failing.counter.w = io.Discard
+	failing.temp.Close()
+	if err := failing.Commit(output, &ExportData{}); err == nil {
+		t.Fatal("Commit succeeded with a closed payload file")
+	}
+	if data, _ := os.ReadFile(output); !bytes.Equal(data, previous) {
+		t.Fatalf("failed Commit changed the existing export to %q", data)
+	}
+
+	// Renaming onto a directory fails after the file has been written
+	blocked := filepath.Join(dir, "blocked")
+	if err := os.Mkdir(blocked, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(blocked, "keep"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	writer, err := NewCCXHandler().NewStreamWriter(dir, "")
+	if err != nil {
+		t.Fatalf("NewStreamWriter: %v", err)
+	}
+	if err := writer.Commit(blocked, &ExportData{}); err == nil {
+		t.Fatal("Commit succeeded onto a directory")
+	}
+	if _, err := os.Stat(filepath.Join(blocked, "keep")); err != nil {
+		t.Fatalf("failed Commit removed the directory contents: %v", err)
+	}
+
+	// A successful Commit replaces the previous export
+	writer, err = NewCCXHandler().NewStreamWriter(dir, "")
+	if err != nil {
+		t.Fatalf("NewStreamWriter: %v", err)
+	}
+	if err := writer.WriteProfile(ProfileData{Name: "work"}); err != nil {
+		t.Fatalf("WriteProfile: %v", err)
+	}
+	if err := writer.Commit(output, &ExportData{}); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	file, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	exportData, err := NewCCXHandler().Read(file, "")
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(exportData.Profiles) != 1 || exportData.Profiles[0].Name != "work" {
+		t.Fatalf("Read returned profiles %+v", exportData.Profiles)
+	}
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.Name() != "export.ccx" && entry.Name() != "blocked" {
+			t.Errorf("unexpected file left in the output directory: %s", entry.Name())
+		}
+	}
+}
@@ -2,8 +2,6 @@ package handler
 
 import (
 	"bytes"
-	"cc-switch/internal/common"
-	"cc-switch/internal/config"
 	"context"
 	"crypto/tls"
 	"encoding/json"
@@ -14,7 +12,11 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/HoBeedzc/cc-switch/internal/common"
+	"github.com/HoBeedzc/cc-switch/internal/config"
 )
 
 // User-Agent 中附带工具版本，版本来源统一于 internal/common/version.go
@@ -32,6 +34,7 @@ func NewAPITester(configManager *config.ConfigManager) *APITester {
 	client := &http.Client{
 		Timeout: 30 * time.Second,
 		Transport: &http.Transport{
+			Proxy: requestProxy,
 			TLSClientConfig: &tls.Config{
 				MinVersion: tls.VersionTLS12,
 			},
@@ -47,10 +50,33 @@ func NewAPITester(configManager *config.ConfigManager) *APITester {
 	}
 }
 
-// TestAPIConnectivity tests the API connectivity for a specific profile
-func (t *APITester) TestAPIConnectivity(profileName string, options TestOptions) (*APITestResult, error) {
+// TestAPIConnectivity tests the API connectivity for a specific profile and records the run in the activity log.
+// Cancelling ctx stops the remaining endpoint tests; the partial result is returned and not logged.
+func (t *APITester) TestAPIConnectivity(ctx context.Context, profileName string, options TestOptions) (*APITestResult, error) {
+	result, err := t.testAPIConnectivity(ctx, profileName, options)
+	if err != nil || profileName == "empty_mode" || ctx.Err() != nil {
+		return result, err
+	}
+
+	entry := config.ActivityEntry{Action: config.ActivityTest, Profile: profileName, Result: "ok"}
+	if !result.IsConnectable {
+		entry.Result = "failed"
+		entry.Detail = result.Error
+	}
+	t.configManager.LogActivity(entry)
+
+	return result, nil
+}
+
+// TestProfile is a convenience wrapper around TestAPIConnectivity for callers that never cancel
+func (t *APITester) TestProfile(profileName string, options TestOptions) (*APITestResult, error) {
+	return t.TestAPIConnectivity(context.Background(), profileName, options)
+}
+
+// testAPIConnectivity runs the connectivity tests for a profile
+func (t *APITester) testAPIConnectivity(ctx context.Context, profileName string, options TestOptions) (*APITestResult, error) {
 	if profileName == "" {
-		return nil, fmt.Errorf("profile name cannot be empty")
+		return nil, config.Invalidf("profile name cannot be empty")
 	}
 
 	// Handle special case for empty mode
@@ -63,8 +89,7 @@ func (t *APITester) TestAPIConnectivity(profileName string, options TestOptions)
 		}, nil
 	}
 
-	// Extract API credentials from configuration
-	credentials, err := t.extractAPICredentials(profileName)
+	target, err := t.loadProfileTarget(profileName)
 	if err != nil {
 		return &APITestResult{
 			ProfileName:   profileName,
@@ -74,84 +99,283 @@ func (t *APITester) TestAPIConnectivity(profileName string, options TestOptions)
 		}, nil
 	}
 
+	return t.runTests(ctx, target, options), nil
+}
+
+// TestSettingsFile tests an arbitrary settings.json file without looking up a profile
+func (t *APITester) TestSettingsFile(ctx context.Context, path string, options TestOptions) (*APITestResult, error) {
+	content, err := ReadSettingsFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.runTests(ctx, testTarget{label: path, content: content}, options), nil
+}
+
+// ReadSettingsFile reads and validates a standalone settings file. A file that is not
+// valid JSON or fails schema validation is reported as a *SettingsFileError.
+func ReadSettingsFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	var content map[string]interface{}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, &SettingsFileError{
+			Path:   path,
+			Issues: []config.ValidationIssue{config.NewIssue(config.IssueInvalidJSON, config.SeverityError, "", fmt.Sprintf("invalid JSON: %v", err))},
+		}
+	}
+
+	issues := config.ValidateContent(content, false)
+	for _, issue := range issues {
+		if issue.Severity == config.SeverityError {
+			return nil, &SettingsFileError{Path: path, Issues: issues}
+		}
+	}
+
+	return content, nil
+}
+
+// testTarget is the configuration under test: a stored profile or a standalone settings file
+type testTarget struct {
+	label   string                 // profile name or file path reported in the result
+	content map[string]interface{} // content with @secret: references resolved
+	// authStyle is how the API key is sent (config.AuthStyleBearer or AuthStyleAPIKey);
+	// empty means bearer
+	authStyle string
+}
+
+// runTests runs the selected endpoint tests against a target, stopping between tests once ctx is cancelled
+func (t *APITester) runTests(ctx context.Context, target testTarget, options TestOptions) *APITestResult {
+	// 与 Claude Code 看到的内容一致：_net 转换为 HTTPS_PROXY 和 ANTHROPIC_CUSTOM_HEADERS
+	target.content = config.MaterializeContent(target.content)
+	credentials, err := t.extractAPICredentials(target.content)
+	if err != nil {
+		return &APITestResult{
+			ProfileName:   target.label,
+			IsConnectable: false,
+			TestedAt:      time.Now(),
+			Error:         fmt.Sprintf("Failed to extract credentials: %v", err),
+		}
+	}
+	credentials.AuthStyle = target.authStyle
+	if err := checkProtectedHeaders(credentials.Headers, options.AllowHeaderOverride); err != nil {
+		return &APITestResult{
+			ProfileName:   target.label,
+			IsConnectable: false,
+			TestedAt:      time.Now(),
+			Error:         err.Error(),
+		}
+	}
+	ctx = withRequestProxy(ctx, credentials.Proxy)
+
 	// 不再修改 httpClient 的全局 Timeout，避免并发场景下的相互影响
 
 	result := &APITestResult{
-		ProfileName: profileName,
+		ProfileName: target.label,
 		TestedAt:    time.Now(),
 		Tests:       []EndpointTest{},
 	}
+	if credentials.Proxy != nil {
+		result.Proxy = credentials.Proxy.Redacted()
+	}
 
 	start := time.Now()
 
 	// 构造测试集合：优先考虑 endpoints 过滤；其次考虑 quick；否则执行完整套件
-	var tests []EndpointTest
+	var tests []func() EndpointTest
 	timeout := options.Timeout
 
+	basic := func() EndpointTest { return t.testBasicConnectivity(ctx, credentials, timeout) }
+	auth := func() EndpointTest { return t.testAuthentication(ctx, credentials, timeout) }
+	models := func() EndpointTest { return t.testModelsEndpoint(ctx, credentials, timeout) }
+	chat := func() EndpointTest { return t.testChatEndpoint(ctx, target, credentials, timeout, !options.NoIsolate) }
+
 	// 规范 endpoints 取值：basic/auth/models/chat
 	if len(options.Endpoints) > 0 {
 		for _, ep := range options.Endpoints {
 			switch strings.ToLower(strings.TrimSpace(ep)) {
 			case "basic":
-				tests = append(tests, t.testBasicConnectivity(credentials, timeout))
+				tests = append(tests, basic)
 			case "auth":
-				tests = append(tests, t.testAuthentication(credentials, timeout))
+				tests = append(tests, auth)
 			case "models":
-				tests = append(tests, t.testModelsEndpoint(credentials, timeout))
+				tests = append(tests, models)
 			case "chat":
-				tests = append(tests, t.testChatEndpoint(profileName, credentials, timeout))
+				tests = append(tests, chat)
 			}
 		}
-		result.Tests = append(result.Tests, tests...)
 	} else if options.Quick {
-		result.Tests = append(result.Tests, t.testBasicConnectivity(credentials, timeout))
+		tests = append(tests, basic)
 	} else {
 		// 完整套件
-		result.Tests = append(result.Tests,
-			t.testAuthentication(credentials, timeout),
-			t.testModelsEndpoint(credentials, timeout),
-			t.testChatEndpoint(profileName, credentials, timeout),
-		)
+		tests = append(tests, auth, models, chat)
+	}
+
+	for _, test := range tests {
+		if ctx.Err() != nil {
+			break
+		}
+		result.Tests = append(result.Tests, test())
+	}
+
+	for _, test := range result.Tests {
+		if rateLimit := parseRateLimit(test.Headers, time.Now()); rateLimit != nil {
+			result.RateLimit = rateLimit
+		}
 	}
 
 	// Calculate total response time and connectivity status
 	result.ResponseTime = time.Since(start)
 	result.IsConnectable = t.aggregateResults(result.Tests)
 
-	return result, nil
+	if ctx.Err() != nil {
+		result.IsConnectable = false
+		result.Error = "test cancelled"
+	}
+
+	return result
+}
+
+// TestAllConfigurations tests API connectivity for all available configurations.
+// When ctx is cancelled the results gathered so far are returned together with ctx.Err().
+func (t *APITester) TestAllConfigurations(ctx context.Context, options TestOptions) ([]APITestResult, error) {
+	return t.TestAllConfigurationsWithProgress(ctx, options, nil)
 }
 
-// TestAllConfigurations tests API connectivity for all available configurations
-func (t *APITester) TestAllConfigurations(options TestOptions) ([]APITestResult, error) {
+// TestAllConfigurationsWithProgress tests all configurations, up to options.Concurrency
+// at a time, and calls onResult as each one completes. Calls to onResult are never
+// concurrent. The results are returned in profile order; when ctx is cancelled running
+// tests are stopped and the results gathered so far are returned together with ctx.Err().
+func (t *APITester) TestAllConfigurationsWithProgress(ctx context.Context, options TestOptions, onResult func(APITestResult)) ([]APITestResult, error) {
 	profiles, err := t.configManager.ListProfiles()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list profiles: %w", err)
 	}
 
-	results := make([]APITestResult, 0, len(profiles))
+	results := make([]APITestResult, len(profiles))
+	tested := make([]bool, len(profiles))
+	slots := make(chan struct{}, max(options.Concurrency, 1))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	done := 0
+	report := func(i int, result APITestResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		results[i], tested[i] = result, true
+		done++
+		EmitTestProgress(result.ProfileName, done, len(profiles), &results[i])
+		if onResult != nil {
+			onResult(result)
+		}
+	}
 
-	for _, profile := range profiles {
-		result, err := t.TestAPIConnectivity(profile.Name, options)
-		if err != nil {
-			// Create error result for this profile
-			result = &APITestResult{
-				ProfileName:   profile.Name,
-				IsConnectable: false,
-				TestedAt:      time.Now(),
-				Error:         err.Error(),
+	for i, profile := range profiles {
+		if ctx.Err() != nil {
+			break
+		}
+
+		if profile.Error != "" {
+			report(i, SkippedTestResult(profile))
+			continue
+		}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		mu.Lock()
+		EmitTestProgress(profile.Name, done, len(profiles), nil)
+		mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			result, err := t.TestAPIConnectivity(ctx, profile.Name, options)
+			if err != nil {
+				// Create error result for this profile
+				result = &APITestResult{
+					ProfileName:   profile.Name,
+					IsConnectable: false,
+					TestedAt:      time.Now(),
+					Error:         err.Error(),
+				}
 			}
+			report(i, *result)
+		}()
+	}
+	wg.Wait()
+
+	completed := make([]APITestResult, 0, done)
+	for i, result := range results {
+		if tested[i] {
+			completed = append(completed, result)
+		}
+	}
+
+	FinishTestProgress(completed, len(profiles), ctx.Err() != nil)
+	return completed, ctx.Err()
+}
+
+// EmitTestProgress emits a progress event for one configuration of a test run:
+// running before it is tested (result is nil), then its outcome
+func EmitTestProgress(name string, done, total int, result *APITestResult) {
+	status := common.ProgressRunning
+	switch {
+	case result == nil:
+	case result.Skipped:
+		status = common.ProgressSkipped
+	case result.IsConnectable:
+		status = common.ProgressOK
+	default:
+		status = common.ProgressFailed
+	}
+	common.EmitProgress(common.ProgressEvent{Op: "test", Item: name, Done: done, Total: total, Status: status})
+}
+
+// FinishTestProgress emits the finished event of a test run over total configurations
+func FinishTestProgress(results []APITestResult, total int, interrupted bool) {
+	failed := 0
+	for _, result := range results {
+		if !result.IsConnectable && !result.Skipped {
+			failed++
 		}
-		results = append(results, *result)
 	}
 
-	return results, nil
+	outcome := common.ResultOK
+	if interrupted {
+		outcome = common.ResultInterrupted
+	} else if failed > 0 {
+		outcome = common.ResultFailed
+	}
+	common.FinishProgress("test", len(results), total, failed, outcome)
+}
+
+// SkippedTestResult builds the result reported for a profile whose file cannot be read
+func SkippedTestResult(profile config.Profile) APITestResult {
+	return APITestResult{
+		ProfileName:   profile.Name,
+		IsConnectable: false,
+		TestedAt:      time.Now(),
+		Error:         fmt.Sprintf("unreadable profile: %s", profile.Error),
+		Skipped:       true,
+	}
 }
 
 // TestCurrentConfiguration tests the currently active configuration
-func (t *APITester) TestCurrentConfiguration(options TestOptions) (*APITestResult, error) {
+func (t *APITester) TestCurrentConfiguration(ctx context.Context, options TestOptions) (*APITestResult, error) {
 	// Check if in empty mode
 	if t.configManager.IsEmptyMode() {
-		return t.TestAPIConnectivity("empty_mode", options)
+		return t.TestAPIConnectivity(ctx, "empty_mode", options)
 	}
 
 	currentProfile, err := t.configManager.GetCurrentProfile()
@@ -159,16 +383,29 @@ func (t *APITester) TestCurrentConfiguration(options TestOptions) (*APITestResul
 		return nil, fmt.Errorf("failed to get current profile: %w", err)
 	}
 
-	return t.TestAPIConnectivity(currentProfile, options)
+	return t.TestAPIConnectivity(ctx, currentProfile, options)
 }
 
-// extractAPICredentials extracts API credentials from a configuration profile
-func (t *APITester) extractAPICredentials(profileName string) (*APICredentials, error) {
+// loadProfileTarget loads a profile with its @secret: references resolved
+func (t *APITester) loadProfileTarget(profileName string) (testTarget, error) {
 	content, _, err := t.configManager.GetProfileContent(profileName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load profile content: %w", err)
+		return testTarget{}, fmt.Errorf("failed to load profile content: %w", err)
+	}
+
+	resolved, err := t.configManager.ResolveSecretReferences(content)
+	if err != nil {
+		return testTarget{}, fmt.Errorf("failed to resolve secrets: %w", err)
 	}
+	return testTarget{
+		label:     profileName,
+		content:   resolved,
+		authStyle: t.configManager.ProfileAuthStyle(profileName),
+	}, nil
+}
 
+// extractAPICredentials extracts API credentials from configuration content
+func (t *APITester) extractAPICredentials(content map[string]interface{}) (*APICredentials, error) {
 	credentials := &APICredentials{
 		BaseURL: "https://api.anthropic.com",
 		Version: "2023-06-01",
@@ -176,10 +413,12 @@ func (t *APITester) extractAPICredentials(profileName string) (*APICredentials,
 
 	// Extract API key from env section
 	if env, ok := content["env"].(map[string]interface{}); ok {
-		if apiKey, ok := env["ANTHROPIC_AUTH_TOKEN"].(string); ok && apiKey != "" {
-			credentials.APIKey = apiKey
-		} else if apiKey, ok := env["ANTHROPIC_API_KEY"].(string); ok && apiKey != "" {
-			credentials.APIKey = apiKey
+		// 按配置的凭据键名顺序取第一个非空值（全局配置 auth.token_keys）
+		for _, key := range config.TokenKeys() {
+			if apiKey, ok := env[key].(string); ok && apiKey != "" {
+				credentials.APIKey = apiKey
+				break
+			}
 		}
 
 		// Extract base URL if provided
@@ -191,20 +430,38 @@ func (t *APITester) extractAPICredentials(profileName string) (*APICredentials,
 		if version, ok := env["ANTHROPIC_VERSION"].(string); ok && version != "" {
 			credentials.Version = version
 		}
+
+		// Extra headers required by authenticated proxies, as Claude Code sends them
+		if value, ok := env[config.CustomHeadersKey].(string); ok {
+			headers, err := parseCustomHeaders(value)
+			if err != nil {
+				return nil, err
+			}
+			credentials.Headers = headers
+		}
+
+		// Proxy the configuration routes Claude Code through
+		if value, ok := env[config.ProxyEnvKey].(string); ok {
+			proxy, err := parseProxyURL(value)
+			if err != nil {
+				return nil, err
+			}
+			credentials.Proxy = proxy
+		}
 	}
 
 	if credentials.APIKey == "" {
-		return nil, fmt.Errorf("no API key found in configuration")
+		return nil, fmt.Errorf("no API key found in configuration: %s", config.MissingTokenMessage())
 	}
 
 	return credentials, nil
 }
 
 // testBasicConnectivity performs a basic connectivity test to the API
-func (t *APITester) testBasicConnectivity(credentials *APICredentials, timeout time.Duration) EndpointTest {
+func (t *APITester) testBasicConnectivity(ctx context.Context, credentials *APICredentials, timeout time.Duration) EndpointTest {
 	start := time.Now()
 
-	req, err := http.NewRequest("HEAD", credentials.BaseURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "HEAD", credentials.BaseURL, nil)
 	if err != nil {
 		return EndpointTest{
 			Endpoint:     credentials.BaseURL,
@@ -216,14 +473,17 @@ func (t *APITester) testBasicConnectivity(credentials *APICredentials, timeout t
 		}
 	}
 
+	applyCustomHeaders(req, credentials)
+
 	resp, err := t.doRequest(req, timeout)
 	duration := time.Since(start)
 
 	test := EndpointTest{
-		Endpoint:     credentials.BaseURL,
-		FullURL:      credentials.BaseURL,
-		Method:       "HEAD",
-		ResponseTime: duration,
+		Endpoint:       credentials.BaseURL,
+		FullURL:        credentials.BaseURL,
+		Method:         "HEAD",
+		ResponseTime:   duration,
+		RequestHeaders: maskedRequestHeaders(credentials.Headers),
 	}
 
 	if err != nil {
@@ -247,13 +507,13 @@ func (t *APITester) testBasicConnectivity(credentials *APICredentials, timeout t
 }
 
 // testAuthentication tests API authentication
-func (t *APITester) testAuthentication(credentials *APICredentials, timeout time.Duration) EndpointTest {
+func (t *APITester) testAuthentication(ctx context.Context, credentials *APICredentials, timeout time.Duration) EndpointTest {
 	start := time.Now()
 
 	endpoint := "/v1/models"
 	url := strings.TrimSuffix(credentials.BaseURL, "/") + endpoint
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return EndpointTest{
 			Endpoint:     endpoint,
@@ -265,18 +525,20 @@ func (t *APITester) testAuthentication(credentials *APICredentials, timeout time
 		}
 	}
 
-	req.Header.Set("Authorization", "Bearer "+credentials.APIKey)
+	setAuthHeader(req, credentials)
 	req.Header.Set("User-Agent", userAgent)
 	req.Header.Set("anthropic-version", credentials.Version)
+	applyCustomHeaders(req, credentials)
 
 	resp, err := t.doRequest(req, timeout)
 	duration := time.Since(start)
 
 	test := EndpointTest{
-		Endpoint:     endpoint,
-		FullURL:      url,
-		Method:       "GET",
-		ResponseTime: duration,
+		Endpoint:       endpoint,
+		FullURL:        url,
+		Method:         "GET",
+		ResponseTime:   duration,
+		RequestHeaders: maskedRequestHeaders(credentials.Headers),
 	}
 
 	if err != nil {
@@ -287,6 +549,7 @@ func (t *APITester) testAuthentication(credentials *APICredentials, timeout time
 	defer resp.Body.Close()
 
 	test.StatusCode = resp.StatusCode
+	test.Headers = captureResponseHeaders(resp.Header)
 
 	switch resp.StatusCode {
 	case 200:
@@ -312,14 +575,23 @@ func (t *APITester) testAuthentication(credentials *APICredentials, timeout time
 	return test
 }
 
+// setAuthHeader sends the API key the way the configuration's provider expects
+func setAuthHeader(req *http.Request, credentials *APICredentials) {
+	if credentials.AuthStyle == config.AuthStyleAPIKey {
+		req.Header.Set("x-api-key", credentials.APIKey)
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+credentials.APIKey)
+}
+
 // testModelsEndpoint tests the models endpoint specifically
-func (t *APITester) testModelsEndpoint(credentials *APICredentials, timeout time.Duration) EndpointTest {
+func (t *APITester) testModelsEndpoint(ctx context.Context, credentials *APICredentials, timeout time.Duration) EndpointTest {
 	start := time.Now()
 
 	endpoint := "/v1/models"
 	url := strings.TrimSuffix(credentials.BaseURL, "/") + endpoint
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return EndpointTest{
 			Endpoint:     endpoint,
@@ -331,15 +603,16 @@ func (t *APITester) testModelsEndpoint(credentials *APICredentials, timeout time
 		}
 	}
 
-	req.Header.Set("Authorization", "Bearer "+credentials.APIKey)
+	setAuthHeader(req, credentials)
 	req.Header.Set("User-Agent", userAgent)
 	req.Header.Set("anthropic-version", credentials.Version)
+	applyCustomHeaders(req, credentials)
 
 	// 使用自定义超时（若未设置则回退到 10s）
 	if timeout <= 0 {
 		timeout = 10 * time.Second
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
 	defer cancel()
 	req = req.WithContext(ctx)
 
@@ -347,10 +620,11 @@ func (t *APITester) testModelsEndpoint(credentials *APICredentials, timeout time
 	duration := time.Since(start)
 
 	test := EndpointTest{
-		Endpoint:     endpoint,
-		FullURL:      url,
-		Method:       "GET-MODELS", // Different method to distinguish from auth test
-		ResponseTime: duration,
+		Endpoint:       endpoint,
+		FullURL:        url,
+		Method:         "GET-MODELS", // Different method to distinguish from auth test
+		ResponseTime:   duration,
+		RequestHeaders: maskedRequestHeaders(credentials.Headers),
 	}
 
 	if err != nil {
@@ -361,6 +635,7 @@ func (t *APITester) testModelsEndpoint(credentials *APICredentials, timeout time
 	defer resp.Body.Close()
 
 	test.StatusCode = resp.StatusCode
+	test.Headers = captureResponseHeaders(resp.Header)
 
 	if resp.StatusCode == 200 {
 		// Try to parse response to validate it's working properly
@@ -394,7 +669,9 @@ func (t *APITester) testModelsEndpoint(credentials *APICredentials, timeout time
 }
 
 // testChatEndpoint tests the chat endpoint using real Claude Code CLI
-func (t *APITester) testChatEndpoint(profileName string, credentials *APICredentials, timeout time.Duration) EndpointTest {
+// When isolated is true, the CLI runs against a throwaway HOME so that the test
+// neither reads nor writes anything under the user's real ~/.claude directory.
+func (t *APITester) testChatEndpoint(ctx context.Context, target testTarget, credentials *APICredentials, timeout time.Duration, isolated bool) EndpointTest {
 	start := time.Now()
 
 	endpoint := "/v1/messages"
@@ -415,62 +692,317 @@ func (t *APITester) testChatEndpoint(profileName string, credentials *APICredent
 		return test
 	}
 
-	// Get the actual configuration file path for the profile being tested
-	configPath, err := t.getConfigFilePath(profileName)
-	if err != nil {
-		test.Status = "failed"
-		test.Error = fmt.Sprintf("Failed to get config file path: %v", err)
-		test.ResponseTime = time.Since(start)
-		return test
+	// 交给 CLI 的是解析密钥并转换 _net 后的内容，与 use 写入的 settings.json 一致，而不是原始配置文件
+	var configPath string
+	var sandboxEnv []string
+	if isolated {
+		// 隔离模式：在临时 HOME 中仅放置被测配置，避免污染用户环境
+		sandboxDir, settingsPath, env, err := t.prepareIsolatedHome(target.content)
+		if err != nil {
+			test.Status = "failed"
+			test.Error = fmt.Sprintf("Failed to prepare isolated environment: %v", err)
+			test.ResponseTime = time.Since(start)
+			return test
+		}
+		defer os.RemoveAll(sandboxDir)
+
+		configPath = settingsPath
+		sandboxEnv = env
+	} else {
+		settingsDir, err := os.MkdirTemp("", "cc-switch-test-*")
+		if err != nil {
+			test.Status = "failed"
+			test.Error = fmt.Sprintf("Failed to create temporary settings: %v", err)
+			test.ResponseTime = time.Since(start)
+			return test
+		}
+		defer os.RemoveAll(settingsDir)
+
+		configPath = filepath.Join(settingsDir, "settings.json")
+		if err := writeTestSettings(configPath, target.content); err != nil {
+			test.Status = "failed"
+			test.Error = err.Error()
+			test.ResponseTime = time.Since(start)
+			return test
+		}
 	}
 
 	// 使用给定超时（默认 30s）执行 claude 命令
 	if timeout <= 0 {
 		timeout = 30 * time.Second
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, claudePath, "-p", "Hi", "--settings", configPath)
-
-	// Capture both stdout and stderr
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err = cmd.Run()
+	// CLI 报告的临时错误（限流、过载、网络中断）在剩余时间内重试一次
+	var stdout, stderr string
+	var cliErr *claudeCLIError
+	for attempt := 0; ; attempt++ {
+		stdout, stderr, err = t.runClaudeChat(runCtx, claudePath, configPath, sandboxEnv)
+		// 退出码为 0 时仅在输出明确是错误报告时才分类，避免误判正常回复
+		cliErr = nil
+		if output := stdout + "\n" + stderr; err != nil || reportsCLIError(output) {
+			cliErr = classifyClaudeCLIOutput(output)
+		}
+		if err == nil && cliErr == nil {
+			break
+		}
+		if attempt >= chatTransientRetries || cliErr == nil || !cliErr.transient || runCtx.Err() != nil {
+			break
+		}
+		select {
+		case <-runCtx.Done():
+		case <-time.After(chatRetryDelay):
+		}
+		if runCtx.Err() != nil {
+			break
+		}
+	}
 	test.ResponseTime = time.Since(start)
 
-	if ctx.Err() == context.DeadlineExceeded {
+	if ctx.Err() != nil {
+		test.Status = "failed"
+		test.Error = "Test cancelled"
+		return test
+	}
+
+	if runCtx.Err() == context.DeadlineExceeded {
 		test.Status = "timeout"
-		test.Error = "Command timed out after 30 seconds"
+		test.Error = fmt.Sprintf("Command timed out after %s", timeout)
+		return test
+	}
+
+	if cliErr != nil {
+		test.Status = "failed"
+		test.Error = cliErr.message
 		return test
 	}
 
 	if err != nil {
 		test.Status = "failed"
-		stderrStr := stderr.String()
-		if stderrStr != "" {
-			test.Error = fmt.Sprintf("Command failed: %v - %s", err, stderrStr)
+		if detail := lastOutputLine(stderr, stdout); detail != "" {
+			test.Error = fmt.Sprintf("Command failed: %v - %s", err, detail)
 		} else {
 			test.Error = fmt.Sprintf("Command failed: %v", err)
 		}
 		return test
 	}
 
-	// Check if we got a response
-	output := stdout.String()
-	if output == "" {
+	// 部分 CLI 版本把回复写到 stderr 并正常退出，此时同样视为成功
+	if strings.TrimSpace(stdout) == "" && strings.TrimSpace(stderr) == "" {
 		test.Status = "failed"
 		test.Error = "No output from Claude CLI"
 		return test
 	}
 
 	test.Status = "success"
-	test.Details = "Chat endpoint functional via Claude CLI"
+	if isolated {
+		test.Details = "Chat endpoint functional via Claude CLI (isolated)"
+	} else {
+		test.Details = "Chat endpoint functional via Claude CLI"
+	}
 	return test
 }
 
+// runClaudeChat runs a single "claude -p" prompt against configPath and returns its output
+func (t *APITester) runClaudeChat(ctx context.Context, claudePath, configPath string, env []string) (string, string, error) {
+	cmd := exec.CommandContext(ctx, claudePath, "-p", "Hi", "--settings", configPath)
+	if env != nil {
+		cmd.Env = env
+	}
+	// 取消时结束整个进程组；若仍有进程占用输出管道，最多再等待 chatKillGrace，避免 Run 一直阻塞
+	configureProcessGroup(cmd)
+	cmd.WaitDelay = chatKillGrace
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	return stdout.String(), stderr.String(), err
+}
+
+// claudeCLIError is a known failure recognised in Claude CLI output
+type claudeCLIError struct {
+	patterns  []string // lower-case substrings matched against stdout and stderr
+	message   string   // message reported in EndpointTest.Error
+	transient bool     // whether the chat test retries once
+}
+
+// claudeCLIErrors lists the failures recognised in Claude CLI output, most specific first
+var claudeCLIErrors = []*claudeCLIError{
+	{
+		patterns: []string{"invalid api key", "invalid x-api-key", "authentication_error", "invalid bearer token", "please run /login"},
+		message:  "Claude CLI reports invalid API key",
+	},
+	{
+		patterns: []string{"credit balance is too low", "insufficient credit", "insufficient_quota"},
+		message:  "Claude CLI reports insufficient credit balance",
+	},
+	{
+		patterns: []string{"permission_error", "403 forbidden", "not allowed to access"},
+		message:  "Claude CLI reports the API key lacks permission for this request",
+	},
+	{
+		patterns: []string{"not_found_error", "model not found", "model_not_found", "invalid model"},
+		message:  "Claude CLI reports the model is not available on this endpoint",
+	},
+	{
+		patterns:  []string{"rate limit", "rate_limit", "too many requests", "error: 429"},
+		message:   "Claude CLI reports the API rate limit was exceeded",
+		transient: true,
+	},
+	{
+		patterns:  []string{"overloaded", "error: 529", "503 service unavailable"},
+		message:   "Claude CLI reports the API is overloaded",
+		transient: true,
+	},
+	{
+		patterns: []string{"econnrefused", "enotfound", "getaddrinfo", "certificate", "unable to verify"},
+		message:  "Claude CLI could not connect to the API endpoint",
+	},
+	{
+		patterns:  []string{"econnreset", "etimedout", "socket hang up", "fetch failed", "network error", "connection error"},
+		message:   "Claude CLI lost the connection to the API endpoint",
+		transient: true,
+	},
+}
+
+// classifyClaudeCLIOutput returns the known failure reported in output, or nil
+func classifyClaudeCLIOutput(output string) *claudeCLIError {
+	lower := strings.ToLower(output)
+	for _, known := range claudeCLIErrors {
+		for _, pattern := range known.patterns {
+			if strings.Contains(lower, pattern) {
+				return known
+			}
+		}
+	}
+	return nil
+}
+
+// reportsCLIError reports whether output is an error report rather than a chat reply
+func reportsCLIError(output string) bool {
+	lower := strings.ToLower(output)
+	return strings.Contains(lower, "api error") || strings.Contains(lower, "invalid api key")
+}
+
+// lastOutputLine returns the last non-empty line of the first output that has one,
+// truncated so raw CLI dumps stay readable in test results
+func lastOutputLine(outputs ...string) string {
+	for _, output := range outputs {
+		lines := strings.Split(strings.TrimSpace(output), "\n")
+		if line := strings.TrimSpace(lines[len(lines)-1]); line != "" {
+			if len(line) > chatErrorMaxLen {
+				line = line[:chatErrorMaxLen] + "..."
+			}
+			return line
+		}
+	}
+	return ""
+}
+
+// Claude CLI chat test limits
+const (
+	chatTransientRetries = 1               // extra attempts after a transient CLI error
+	chatRetryDelay       = 2 * time.Second // pause before retrying
+	chatErrorMaxLen      = 200             // longest CLI output line quoted in an error
+)
+
+// chatKillGrace bounds how long a cancelled Claude CLI chat test may keep its output pipes open
+const chatKillGrace = 2 * time.Second
+
+// isolatedEnvPassthrough lists the host environment variables kept in the isolated
+// chat test; everything else (including stray ANTHROPIC_* variables) is dropped
+var isolatedEnvPassthrough = []string{
+	"PATH", "SYSTEMROOT", "TMPDIR", "TEMP", "TMP", "LANG",
+	// network settings, so tests behind a proxy or a private CA behave like the real CLI
+	"HTTPS_PROXY", "HTTP_PROXY", "NO_PROXY", "https_proxy", "http_proxy", "no_proxy", "SSL_CERT_FILE",
+}
+
+// prepareIsolatedHome creates a temporary HOME containing only the content under test
+// as .claude/settings.json and returns the directory, the settings path and the
+// sanitized environment for the Claude CLI. The caller must remove the directory.
+func (t *APITester) prepareIsolatedHome(content map[string]interface{}) (string, string, []string, error) {
+	sandboxDir, err := os.MkdirTemp("", "cc-switch-test-*")
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to create temporary home: %w", err)
+	}
+
+	claudeDir := filepath.Join(sandboxDir, ".claude")
+	if err := os.MkdirAll(claudeDir, 0700); err != nil {
+		os.RemoveAll(sandboxDir)
+		return "", "", nil, fmt.Errorf("failed to create temporary claude directory: %w", err)
+	}
+
+	settingsPath := filepath.Join(claudeDir, "settings.json")
+	if err := writeTestSettings(settingsPath, content); err != nil {
+		os.RemoveAll(sandboxDir)
+		return "", "", nil, err
+	}
+
+	env := []string{
+		"HOME=" + sandboxDir,
+		"USERPROFILE=" + sandboxDir,
+		"CLAUDE_CONFIG_DIR=" + claudeDir,
+	}
+	for _, key := range isolatedEnvPassthrough {
+		if value, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+value)
+		}
+	}
+
+	// Export the profile's env section explicitly so the CLI cannot fall back to other credentials
+	if profileEnv, ok := content["env"].(map[string]interface{}); ok {
+		for key, value := range profileEnv {
+			if str, ok := value.(string); ok && str != "" {
+				env = append(env, key+"="+str)
+			}
+		}
+	}
+
+	return sandboxDir, settingsPath, env, nil
+}
+
+// writeTestSettings writes the content under test as the settings file handed to the Claude CLI
+func writeTestSettings(path string, content map[string]interface{}) error {
+	data, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize profile: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write temporary settings: %w", err)
+	}
+	return nil
+}
+
+// claudeVersionTimeout bounds the "claude --version" call made for diagnostics
+const claudeVersionTimeout = 5 * time.Second
+
+// ClaudeCLIInfo locates the Claude CLI and asks it for its version
+func (t *APITester) ClaudeCLIInfo() ClaudeCLIInfo {
+	claudePath, err := t.findClaudeCommand()
+	if err != nil {
+		return ClaudeCLIInfo{Error: err.Error()}
+	}
+
+	info := ClaudeCLIInfo{Path: claudePath}
+	if resolved, err := exec.LookPath(claudePath); err == nil {
+		info.Path = resolved
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), claudeVersionTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, claudePath, "--version").Output()
+	if err != nil {
+		info.Error = fmt.Sprintf("failed to get version: %v", err)
+		return info
+	}
+	info.Version = strings.TrimSpace(string(output))
+	return info
+}
+
 // findClaudeCommand locates the claude command in common locations
 func (t *APITester) findClaudeCommand() (string, error) {
 	// Try common locations for claude command
@@ -506,17 +1038,6 @@ func (t *APITester) findClaudeCommand() (string, error) {
 	return "", fmt.Errorf("claude command not found in common locations")
 }
 
-// getConfigFilePath returns the full path to the configuration file for the given profile
-func (t *APITester) getConfigFilePath(profileName string) (string, error) {
-	// Use GetProfileContent to verify the profile exists and get the path
-	_, profile, err := t.configManager.GetProfileContent(profileName)
-	if err != nil {
-		return "", fmt.Errorf("failed to get profile content: %v", err)
-	}
-
-	return profile.Path, nil
-}
-
 // aggregateResults determines overall connectivity status from individual test results
 func (t *APITester) aggregateResults(tests []EndpointTest) bool {
 	if len(tests) == 0 {
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/config/configtest"
+)
+
+// newTestManager creates a configuration manager in a temporary home directory
+func newTestManager(t *testing.T) *config.ConfigManager {
+	t.Helper()
+	return configtest.NewManager(t, config.Options{})
+}
+
+func TestTestAllSkipsUnreadableProfiles(t *testing.T) {
+	cm := newTestManager(t)
+	path := filepath.Join(cm.GetProfilesDir(), "broken.json")
+	if err := os.Symlink("missing-target", path); err != nil {
+		t.Skipf("cannot create symlinks: %v", err)
+	}
+
+	results, err := NewAPITester(cm).TestAllConfigurations(context.Background(), TestOptions{Quick: true})
+	if err != nil {
+		t.Fatalf("TestAllConfigurations: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("results = %+v, want one for the broken profile", results)
+	}
+
+	result := results[0]
+	if result.ProfileName != "broken" || !result.Skipped || result.IsConnectable {
+		t.Errorf("result = %+v, want broken skipped and not connectable", result)
+	}
+	if result.Error != "unreadable profile: broken symlink" {
+		t.Errorf("error = %q, want the unreadable reason", result.Error)
+	}
+}
+
+func TestSkippedTestResult(t *testing.T) {
+	result := SkippedTestResult(config.Profile{Name: "locked", Error: "permission denied"})
+	if !result.Skipped || result.IsConnectable || len(result.Tests) != 0 {
+		t.Errorf("result = %+v, want skipped with no tests", result)
+	}
+	if result.Error != "unreadable profile: permission denied" {
+		t.Errorf("error = %q", result.Error)
+	}
+}
+
+// fakeClaude puts a claude script on PATH that copies the --settings file it is given
+// to the returned path and prints a reply
+func fakeClaude(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake Claude CLI is a shell script")
+	}
+	dir := t.TempDir()
+	captured := filepath.Join(dir, "captured-settings.json")
+	script := fmt.Sprintf("#!/bin/sh\nwhile [ $# -gt 0 ]; do\n  if [ \"$1\" = --settings ]; then cp \"$2\" %q; fi\n  shift\ndone\necho Hello\n", captured)
+	if err := os.WriteFile(filepath.Join(dir, "claude"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return captured
+}
+
+func TestChatTestUsesResolvedSettings(t *testing.T) {
+	for _, isolated := range []bool{true, false} {
+		t.Run(fmt.Sprintf("isolated=%v", isolated), func(t *testing.T) {
+			captured := fakeClaude(t)
+			cm := newTestManager(t)
+			cm.SetSecretsPassphrase("test-passphrase")
+			if err := cm.SetSecret("work-token", "sk-resolved-token"); err != nil {
+				t.Fatal(err)
+			}
+			if err := cm.CreateProfileWithContent("work", map[string]interface{}{
+				"env":         map[string]interface{}{"ANTHROPIC_AUTH_TOKEN": config.SecretRefPrefix + "work-token"},
+				config.NetKey: map[string]interface{}{"headers": map[string]interface{}{"X-Team": "platform"}},
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := NewAPITester(cm).TestAPIConnectivity(context.Background(), "work", TestOptions{Endpoints: []string{"chat"}, NoIsolate: !isolated, Timeout: 10 * time.Second})
+			if err != nil {
+				t.Fatalf("TestAPIConnectivity: %v", err)
+			}
+			if len(result.Tests) != 1 || result.Tests[0].Status != "success" {
+				t.Fatalf("tests = %+v, want a successful chat test", result.Tests)
+			}
+
+			data, err := os.ReadFile(captured)
+			if err != nil {
+				t.Fatalf("the Claude CLI was not given a settings file: %v", err)
+			}
+			var settings map[string]interface{}
+			if err := json.Unmarshal(data, &settings); err != nil {
+				t.Fatal(err)
+			}
+			env, _ := settings["env"].(map[string]interface{})
+			if env["ANTHROPIC_AUTH_TOKEN"] != "sk-resolved-token" {
+				t.Errorf("token = %v, want the resolved secret", env["ANTHROPIC_AUTH_TOKEN"])
+			}
+			if !strings.Contains(fmt.Sprint(env["ANTHROPIC_CUSTOM_HEADERS"]), "X-Team: platform") {
+				t.Errorf("custom headers = %v, want the materialized _net headers", env["ANTHROPIC_CUSTOM_HEADERS"])
+			}
+			if _, ok := settings[config.NetKey]; ok {
+				t.Errorf("settings = %s, want %s materialized", data, config.NetKey)
+			}
+		})
+	}
+}
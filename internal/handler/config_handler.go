@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -8,7 +9,8 @@ import (
 	"path/filepath"
 	"strings"
 
-	"cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/common"
+	"github.com/HoBeedzc/cc-switch/internal/config"
 )
 
 // configHandler implements the ConfigHandler interface
@@ -58,8 +60,11 @@ func (h *configHandler) DeleteAllConfigs() error {
 		return fmt.Errorf("no configurations found to delete")
 	}
 
-	// Delete all profiles
+	// Delete all profiles (read-only system profiles and the original-settings snapshot are left in place)
 	for _, profile := range profiles {
+		if profile.ReadOnly || profile.Snapshot {
+			continue
+		}
 		if err := h.configManager.DeleteProfile(profile.Name); err != nil {
 			return fmt.Errorf("failed to delete configuration '%s': %w", profile.Name, err)
 		}
@@ -82,31 +87,23 @@ func (h *configHandler) DeleteCurrentConfig() error {
 		return fmt.Errorf("failed to get current configuration: %w", err)
 	}
 
-	// Delete the current configuration
-	if err := h.configManager.DeleteProfile(currentName); err != nil {
+	// Enter empty mode and delete the current configuration
+	if err := h.configManager.DeleteCurrentProfile(); err != nil {
 		return fmt.Errorf("failed to delete current configuration '%s': %w", currentName, err)
 	}
 
-	// Enter empty mode
-	return h.configManager.EnableEmptyMode()
+	return nil
 }
 
 // CreateConfig creates a new configuration from a template
 func (h *configHandler) CreateConfig(name string, templateName string) error {
 	// Validate configuration doesn't already exist
 	if h.configManager.ProfileExists(name) {
-		return fmt.Errorf("configuration '%s' already exists", name)
+		return config.Conflictf("configuration '%s' already exists", name)
 	}
 
-	// Use default template if not specified
-	if templateName == "" {
-		templateName = "default"
-	}
-
-	// Check if template exists, fallback to default if not
-	if !h.configManager.TemplateExists(templateName) && templateName != "default" {
-		templateName = "default"
-	}
+	// Resolve the template: explicit name, then the default_template setting, then "default"
+	templateName, _ = h.configManager.ResolveTemplate(templateName)
 
 	// Create the configuration from template
 	return h.configManager.CreateProfileFromTemplate(name, templateName)
@@ -116,7 +113,7 @@ func (h *configHandler) CreateConfig(name string, templateName string) error {
 func (h *configHandler) CreateConfigWithContent(name string, content map[string]interface{}) error {
 	// Validate configuration doesn't already exist
 	if h.configManager.ProfileExists(name) {
-		return fmt.Errorf("configuration '%s' already exists", name)
+		return config.Conflictf("configuration '%s' already exists", name)
 	}
 
 	// Create the configuration with custom content
@@ -139,6 +136,37 @@ func (h *configHandler) UseConfig(name string) error {
 	return h.configManager.UseProfile(name)
 }
 
+// UseConfigWithNote switches to the specified configuration and records a note in history
+func (h *configHandler) UseConfigWithNote(name, note string) error {
+	return h.UseConfigWithOptions(name, config.SwitchOptions{Note: note})
+}
+
+// UseConfigWithOptions switches to the specified configuration, optionally verifying
+// the new settings.json and rolling back if it fails
+func (h *configHandler) UseConfigWithOptions(name string, options config.SwitchOptions) error {
+	// Validate configuration exists
+	if err := h.ValidateConfigExists(name); err != nil {
+		return err
+	}
+
+	// Check if already current
+	if h.IsCurrentConfig(name) {
+		return fmt.Errorf("configuration '%s' is already active", name)
+	}
+
+	return h.configManager.UseProfileWithOptions(name, options)
+}
+
+// GetHistory returns the configuration switch history, newest first
+func (h *configHandler) GetHistory() ([]config.HistoryEntry, error) {
+	return h.configManager.GetHistoryEntries()
+}
+
+// AttachLastTests fills in each profile's last recorded test result from the activity log
+func (h *configHandler) AttachLastTests(profiles []config.Profile) error {
+	return h.configManager.AttachLastTests(profiles)
+}
+
 // ViewConfig returns the configuration view
 func (h *configHandler) ViewConfig(name string, raw bool) (*ConfigView, error) {
 	// Validate configuration exists
@@ -152,11 +180,22 @@ func (h *configHandler) ViewConfig(name string, raw bool) (*ConfigView, error) {
 		return nil, fmt.Errorf("failed to read configuration: %w", err)
 	}
 
+	var network string
+	if net, err := config.ProfileNet(content); err == nil && net != nil {
+		network = net.Summary()
+	}
+
+	chain := h.configManager.ProfileOriginChain(name)
 	return &ConfigView{
-		Name:      metadata.Name,
-		IsCurrent: metadata.IsCurrent,
-		Path:      metadata.Path,
-		Content:   content,
+		Name:        metadata.Name,
+		DisplayName: metadata.DisplayName,
+		IsCurrent:   metadata.IsCurrent,
+		Path:        metadata.Path,
+		Project:     h.configManager.ProfileProject(name),
+		Network:     network,
+		Origin:      chain[0],
+		OriginChain: chain,
+		Content:     content,
 	}, nil
 }
 
@@ -166,6 +205,9 @@ func (h *configHandler) EditConfig(name string, field string, useNano bool) erro
 	if err := h.ValidateConfigExists(name); err != nil {
 		return err
 	}
+	if err := h.configManager.CheckProfileModifiable(name); err != nil {
+		return err
+	}
 
 	if field != "" {
 		// Field editing mode
@@ -179,11 +221,60 @@ func (h *configHandler) EditConfig(name string, field string, useNano bool) erro
 // ValidateConfigExists checks if a configuration exists
 func (h *configHandler) ValidateConfigExists(name string) error {
 	if !h.configManager.ProfileExists(name) {
-		return fmt.Errorf("configuration '%s' does not exist", name)
+		return config.NotFoundf("configuration '%s' does not exist", name)
 	}
 	return nil
 }
 
+// ValidateConfig validates a stored configuration and returns any issues found
+func (h *configHandler) ValidateConfig(name string) ([]config.ValidationIssue, error) {
+	return h.configManager.ValidateProfile(name)
+}
+
+// ValidateContent validates configuration or template content without saving it
+func (h *configHandler) ValidateContent(content map[string]interface{}, isTemplate bool) []config.ValidationIssue {
+	return config.ValidateContent(content, isTemplate)
+}
+
+// DiagnoseProfiles validates every configuration and reports which cc-switch version last wrote it
+func (h *configHandler) DiagnoseProfiles() ([]ProfileDiagnosis, error) {
+	profiles, err := h.ListConfigs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	diagnoses := make([]ProfileDiagnosis, 0, len(profiles))
+	for _, profile := range profiles {
+		diagnosis := ProfileDiagnosis{Name: profile.Name}
+
+		if meta, err := h.configManager.GetProfileMetadata(profile.Name); err == nil && meta.WrittenBy != "" {
+			diagnosis.WrittenBy = meta.WrittenBy
+			diagnosis.OtherVersion = meta.WrittenBy != common.Version
+			_, diagnosis.NewerVersion = h.configManager.WrittenByNewerVersion(profile.Name)
+		}
+
+		issues, err := h.configManager.ValidateProfile(profile.Name)
+		if err != nil {
+			issues = []config.ValidationIssue{config.NewIssue(config.IssueProfileUnreadable, config.SeverityError, "", err.Error())}
+		}
+		diagnosis.Issues = issues
+
+		diagnoses = append(diagnoses, diagnosis)
+	}
+
+	return diagnoses, nil
+}
+
+// FindUntrackedProfiles lists profile files that have no metadata of their own, such as manual copies
+func (h *configHandler) FindUntrackedProfiles() ([]config.UntrackedProfile, error) {
+	return h.configManager.FindUntrackedProfiles()
+}
+
+// AdoptProfile creates metadata for an untracked profile, optionally inheriting tags from origin
+func (h *configHandler) AdoptProfile(name, origin string, inheritTags bool) error {
+	return h.configManager.AdoptProfile(name, origin, inheritTags)
+}
+
 // GetCurrentConfig returns the current configuration name
 func (h *configHandler) GetCurrentConfig() (string, error) {
 	return h.configManager.GetCurrentProfile()
@@ -214,7 +305,7 @@ func (h *configHandler) MoveConfig(oldName, newName string) error {
 
 	// Validate destination name is not empty and different
 	if newName == "" {
-		return fmt.Errorf("new configuration name cannot be empty")
+		return config.Invalidf("new configuration name cannot be empty")
 	}
 
 	if oldName == newName {
@@ -223,7 +314,7 @@ func (h *configHandler) MoveConfig(oldName, newName string) error {
 
 	// Check if destination already exists
 	if h.configManager.ProfileExists(newName) {
-		return fmt.Errorf("configuration '%s' already exists", newName)
+		return config.Conflictf("configuration '%s' already exists", newName)
 	}
 
 	// Execute the move operation
@@ -239,7 +330,7 @@ func (h *configHandler) CopyConfig(sourceName, destName string) error {
 
 	// Validate destination name is not empty and different
 	if destName == "" {
-		return fmt.Errorf("destination configuration name cannot be empty")
+		return config.Invalidf("destination configuration name cannot be empty")
 	}
 
 	if sourceName == destName {
@@ -248,13 +339,23 @@ func (h *configHandler) CopyConfig(sourceName, destName string) error {
 
 	// Check if destination already exists
 	if h.configManager.ProfileExists(destName) {
-		return fmt.Errorf("configuration '%s' already exists", destName)
+		return config.Conflictf("configuration '%s' already exists", destName)
 	}
 
 	// Execute the copy operation
 	return h.configManager.CopyProfile(sourceName, destName)
 }
 
+// CreateScratchConfig creates a throwaway configuration that is removed
+// once another configuration is used, and returns its name
+func (h *configHandler) CreateScratchConfig() (string, error) {
+	name, err := h.configManager.CreateScratchProfile()
+	if err != nil {
+		return "", fmt.Errorf("failed to create scratch configuration: %w", err)
+	}
+	return name, nil
+}
+
 // UpdateConfig updates a configuration with new content
 func (h *configHandler) UpdateConfig(name string, content map[string]interface{}) error {
 	// Validate configuration exists
@@ -264,7 +365,7 @@ func (h *configHandler) UpdateConfig(name string, content map[string]interface{}
 
 	// Validate content is not nil
 	if content == nil {
-		return fmt.Errorf("content cannot be nil")
+		return config.Invalidf("content cannot be nil")
 	}
 
 	// Update the configuration using the config manager
@@ -275,6 +376,175 @@ func (h *configHandler) UpdateConfig(name string, content map[string]interface{}
 	return nil
 }
 
+// PatchConfig applies an RFC 6902 JSON patch to a configuration
+func (h *configHandler) PatchConfig(name string, patch []byte) error {
+	if err := h.ValidateConfigExists(name); err != nil {
+		return err
+	}
+
+	content, _, err := h.configManager.GetProfileContent(name)
+	if err != nil {
+		return fmt.Errorf("failed to read configuration: %w", err)
+	}
+
+	patched, err := applyPatch(content, patch, false)
+	if err != nil {
+		return err
+	}
+
+	return h.configManager.UpdateProfile(name, patched)
+}
+
+// GetConfigPermissions returns the allow and deny rules of a configuration
+func (h *configHandler) GetConfigPermissions(name string) (config.ProfilePermissions, error) {
+	return h.configManager.GetProfilePermissions(name)
+}
+
+// AddConfigPermission adds a rule to the allow or deny list; it returns false if the rule was already there
+func (h *configHandler) AddConfigPermission(name, list, rule string) (bool, error) {
+	return h.configManager.AddProfilePermission(name, list, rule)
+}
+
+// RemoveConfigPermission removes a rule from both lists and returns the lists it was removed from
+func (h *configHandler) RemoveConfigPermission(name, rule string) ([]string, error) {
+	return h.configManager.RemoveProfilePermission(name, rule)
+}
+
+// CopyTemplatePermissions replaces the allow and deny rules of a configuration with a template's
+func (h *configHandler) CopyTemplatePermissions(name, templateName string) (config.ProfilePermissions, error) {
+	return h.configManager.CopyTemplatePermissions(name, templateName)
+}
+
+// ResetConfig restores a configuration to the content of a template, keeping its name.
+// An empty templateName uses the template the configuration was created from.
+func (h *configHandler) ResetConfig(name, templateName string, keepSecrets bool) error {
+	if err := h.ValidateConfigExists(name); err != nil {
+		return err
+	}
+
+	if templateName != "" {
+		if err := h.ValidateTemplateExists(templateName); err != nil {
+			return err
+		}
+	}
+
+	return h.configManager.ResetProfileToTemplate(name, templateName, keepSecrets)
+}
+
+// GetConfigTemplate returns the template a configuration was created from
+func (h *configHandler) GetConfigTemplate(name string) (string, error) {
+	if err := h.ValidateConfigExists(name); err != nil {
+		return "", err
+	}
+	return h.configManager.ProfileTemplate(name)
+}
+
+// SetConfigDisplayName sets or clears the presentational name of a configuration
+func (h *configHandler) SetConfigDisplayName(name, displayName string) error {
+	if err := h.ValidateConfigExists(name); err != nil {
+		return err
+	}
+	return h.configManager.SetProfileDisplayName(name, displayName)
+}
+
+// SetConfigProject sets or clears the project directory of a configuration and
+// returns the absolute path that was stored
+func (h *configHandler) SetConfigProject(name, dir string) (string, error) {
+	if err := h.ValidateConfigExists(name); err != nil {
+		return "", err
+	}
+	return h.configManager.SetProfileProject(name, dir)
+}
+
+// GetConfigProject returns the project directory of a configuration, or "" when none is set
+func (h *configHandler) GetConfigProject(name string) (string, error) {
+	if err := h.ValidateConfigExists(name); err != nil {
+		return "", err
+	}
+	return h.configManager.ProfileProject(name), nil
+}
+
+// DiffConfigs compares two stored configurations
+func (h *configHandler) DiffConfigs(fromName, toName string) ([]config.DiffEntry, error) {
+	from, err := h.loadConfigContent(fromName)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := h.loadConfigContent(toName)
+	if err != nil {
+		return nil, err
+	}
+
+	return config.DiffContent(from, to), nil
+}
+
+// DiffConfigAgainstSettings compares a stored configuration with the live settings.json.
+// In empty mode there are no live settings, so every key is reported as removed.
+func (h *configHandler) DiffConfigAgainstSettings(name string) ([]config.DiffEntry, error) {
+	from, err := h.loadConfigContent(name)
+	if err != nil {
+		return nil, err
+	}
+
+	live, err := h.configManager.GetSettingsContent()
+	if err != nil {
+		return nil, err
+	}
+
+	return config.DiffContent(from, live), nil
+}
+
+// DiffOperands compares any two of a configuration, a template and the live
+// settings.json. Empty strings in a template are placeholders, not differences.
+func (h *configHandler) DiffOperands(left, right config.DiffOperand) ([]config.DiffEntry, error) {
+	return h.configManager.DiffOperands(left, right)
+}
+
+// LocalSettingsOverrides lists the settings in ~/.claude/settings.local.json that
+// override or add to the named configuration. Claude Code applies that file on top
+// of settings.json, so these values win after every switch. An empty name lists
+// every setting in the file; nil means there is no local file or nothing overlaps.
+func (h *configHandler) LocalSettingsOverrides(name string) ([]config.DiffEntry, error) {
+	var content map[string]interface{}
+	if name != "" {
+		var err error
+		if content, err = h.loadConfigContent(name); err != nil {
+			return nil, err
+		}
+	}
+	return h.configManager.LocalOverrides(content)
+}
+
+// PlanSwitch reports what switching to a configuration would change in the live settings.json.
+// In empty mode there are no live settings, so every key is reported as added.
+func (h *configHandler) PlanSwitch(name string) ([]config.DiffEntry, error) {
+	to, err := h.loadConfigContent(name)
+	if err != nil {
+		return nil, err
+	}
+
+	live, err := h.configManager.GetSettingsContent()
+	if err != nil {
+		return nil, err
+	}
+
+	return config.DiffContent(live, to), nil
+}
+
+// loadConfigContent reads the content of an existing configuration
+func (h *configHandler) loadConfigContent(name string) (map[string]interface{}, error) {
+	if err := h.ValidateConfigExists(name); err != nil {
+		return nil, err
+	}
+
+	content, _, err := h.configManager.GetProfileContent(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration '%s': %w", name, err)
+	}
+	return content, nil
+}
+
 // editProfileField edits a specific field in the configuration
 func (h *configHandler) editProfileField(name, field string) error {
 	content, _, err := h.configManager.GetProfileContent(name)
@@ -307,7 +577,7 @@ func (h *configHandler) editProfileField(name, field string) error {
 	// Parse new value
 	var newValue interface{}
 	if err := json.Unmarshal([]byte(newValueStr), &newValue); err != nil {
-		return fmt.Errorf("invalid JSON format for new value: %w", err)
+		return config.Invalidf("invalid JSON format for new value: %w", err)
 	}
 
 	// Set new value
@@ -390,7 +660,7 @@ func (h *configHandler) editProfileWithEditor(name string, useNano bool) error {
 	// Validate JSON format
 	var editedContent map[string]interface{}
 	if err := json.Unmarshal(editedData, &editedContent); err != nil {
-		return fmt.Errorf("invalid JSON format: %w", err)
+		return config.Invalidf("invalid JSON format: %w", err)
 	}
 
 	// Save changes
@@ -460,15 +730,31 @@ func (h *configHandler) ListTemplates() ([]string, error) {
 	return h.configManager.ListTemplates()
 }
 
+// DefaultTemplateName returns the template new uses when none is given
+func (h *configHandler) DefaultTemplateName() string {
+	return h.configManager.DefaultTemplateName()
+}
+
+// SetDefaultTemplate makes new use the given template by default ("" restores "default")
+func (h *configHandler) SetDefaultTemplate(name string) error {
+	return h.configManager.SetDefaultTemplate(name)
+}
+
+// ResolveTemplate picks the template to create a configuration from and
+// explains any fallback from a missing template
+func (h *configHandler) ResolveTemplate(name string) (string, string) {
+	return h.configManager.ResolveTemplate(name)
+}
+
 // CreateTemplate creates a new template
 func (h *configHandler) CreateTemplate(name string) error {
 	if name == "" {
-		return fmt.Errorf("template name cannot be empty")
+		return config.Invalidf("template name cannot be empty")
 	}
 
 	// Check if template already exists
 	if h.configManager.TemplateExists(name) {
-		return fmt.Errorf("template '%s' already exists", name)
+		return config.Conflictf("template '%s' already exists", name)
 	}
 
 	return h.configManager.CreateTemplate(name)
@@ -477,7 +763,7 @@ func (h *configHandler) CreateTemplate(name string) error {
 // ValidateTemplateExists checks if a template exists
 func (h *configHandler) ValidateTemplateExists(name string) error {
 	if !h.configManager.TemplateExists(name) {
-		return fmt.Errorf("template '%s' does not exist", name)
+		return config.NotFoundf("template '%s' does not exist", name)
 	}
 	return nil
 }
@@ -508,6 +794,21 @@ func (h *configHandler) DeleteTemplate(name string) error {
 	return h.configManager.DeleteTemplate(name)
 }
 
+// DeleteTemplateDetached deletes a template and detaches configurations created from it
+func (h *configHandler) DeleteTemplateDetached(name string) error {
+	// Validate template exists
+	if err := h.ValidateTemplateExists(name); err != nil {
+		return err
+	}
+
+	return h.configManager.DeleteTemplateDetached(name)
+}
+
+// TemplateInUse returns the configurations that were created from a template
+func (h *configHandler) TemplateInUse(name string) ([]string, error) {
+	return h.configManager.TemplateInUse(name)
+}
+
 // UpdateTemplate updates a template with new content
 func (h *configHandler) UpdateTemplate(name string, content map[string]interface{}) error {
 	// Validate template exists
@@ -518,6 +819,46 @@ func (h *configHandler) UpdateTemplate(name string, content map[string]interface
 	return h.configManager.UpdateTemplate(name, content)
 }
 
+// PatchTemplate applies an RFC 6902 JSON patch to a template
+func (h *configHandler) PatchTemplate(name string, patch []byte) error {
+	if err := h.ValidateTemplateExists(name); err != nil {
+		return err
+	}
+
+	content, err := h.configManager.GetTemplateContent(name)
+	if err != nil {
+		return fmt.Errorf("failed to read template: %w", err)
+	}
+
+	patched, err := applyPatch(content, patch, true)
+	if err != nil {
+		return err
+	}
+
+	return h.configManager.UpdateTemplate(name, patched)
+}
+
+// applyPatch parses and applies a JSON patch, then validates the result before it is saved
+func applyPatch(content map[string]interface{}, patch []byte, isTemplate bool) (map[string]interface{}, error) {
+	ops, err := config.ParseJSONPatch(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := config.ApplyJSONPatch(content, ops)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply patch: %w", err)
+	}
+
+	for _, issue := range config.ValidateContent(patched, isTemplate) {
+		if issue.Severity == config.SeverityError {
+			return nil, fmt.Errorf("patched content is invalid at '%s': %s", issue.Path, issue.Message)
+		}
+	}
+
+	return patched, nil
+}
+
 // CopyTemplate copies a template
 func (h *configHandler) CopyTemplate(sourceName, destName string) error {
 	return h.configManager.CopyTemplate(sourceName, destName)
@@ -541,9 +882,7 @@ func (h *configHandler) ViewTemplate(name string, raw bool) (*TemplateView, erro
 		return nil, fmt.Errorf("failed to read template content: %w", err)
 	}
 
-	// Construct template path (similar to how it's done in the config manager)
-	claudeDir := filepath.Join(os.Getenv("HOME"), ".claude")
-	templatePath := filepath.Join(claudeDir, "profiles", "templates", name+".json")
+	templatePath := filepath.Join(h.configManager.GetTemplatesDir(), name+".json")
 
 	return &TemplateView{
 		Name:    name,
@@ -584,7 +923,7 @@ func (h *configHandler) editTemplateField(name, field string) error {
 	// Parse new value
 	var newValue interface{}
 	if err := json.Unmarshal([]byte(newValueStr), &newValue); err != nil {
-		return fmt.Errorf("invalid JSON format for new value: %w", err)
+		return config.Invalidf("invalid JSON format for new value: %w", err)
 	}
 
 	// Set new value
@@ -667,7 +1006,7 @@ func (h *configHandler) editTemplateWithEditor(name string, useNano bool) error
 	// Validate JSON format
 	var editedContent map[string]interface{}
 	if err := json.Unmarshal(editedData, &editedContent); err != nil {
-		return fmt.Errorf("invalid JSON format: %w", err)
+		return config.Invalidf("invalid JSON format: %w", err)
 	}
 
 	// Save changes
@@ -734,19 +1073,88 @@ func (h *configHandler) GetEmptyModeStatus() (*EmptyModeStatus, error) {
 	}, nil
 }
 
+// GetEmptyModeBackup returns the empty mode status together with the backed-up settings
+func (h *configHandler) GetEmptyModeBackup() (*EmptyModeBackup, error) {
+	status, err := h.GetEmptyModeStatus()
+	if err != nil {
+		return nil, err
+	}
+	if !status.Enabled {
+		return nil, fmt.Errorf("not in empty mode")
+	}
+
+	backupPath, err := h.configManager.EmptyModeBackupPath()
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := h.configManager.GetEmptyModeBackupContent()
+	if err != nil {
+		return nil, err
+	}
+
+	backup := &EmptyModeBackup{
+		EmptyModeStatus: *status,
+		BackupPath:      backupPath,
+		Content:         content,
+	}
+	if info, err := os.Stat(backupPath); err == nil {
+		backup.Size = info.Size()
+	}
+
+	return backup, nil
+}
+
+// ExportEmptyModeBackup saves the empty mode backup as a new configuration
+func (h *configHandler) ExportEmptyModeBackup(name string) error {
+	if !h.configManager.IsEmptyMode() {
+		return fmt.Errorf("not in empty mode")
+	}
+	if h.configManager.ProfileExists(name) {
+		return config.Conflictf("configuration '%s' already exists", name)
+	}
+	return h.configManager.ExportEmptyModeBackup(name)
+}
+
 // API Connectivity Testing Methods
 
 // TestAPIConnectivity tests the API connectivity for a specific profile
-func (h *configHandler) TestAPIConnectivity(profileName string, options TestOptions) (*APITestResult, error) {
-	return h.apiTester.TestAPIConnectivity(profileName, options)
+func (h *configHandler) TestAPIConnectivity(ctx context.Context, profileName string, options TestOptions) (*APITestResult, error) {
+	return h.apiTester.TestAPIConnectivity(ctx, profileName, options)
 }
 
 // TestAllConfigurations tests API connectivity for all available configurations
-func (h *configHandler) TestAllConfigurations(options TestOptions) ([]APITestResult, error) {
-	return h.apiTester.TestAllConfigurations(options)
+func (h *configHandler) TestAllConfigurations(ctx context.Context, options TestOptions) ([]APITestResult, error) {
+	return h.apiTester.TestAllConfigurations(ctx, options)
+}
+
+// TestAllConfigurationsWithProgress tests all configurations and reports each result as it completes
+func (h *configHandler) TestAllConfigurationsWithProgress(ctx context.Context, options TestOptions, onResult func(APITestResult)) ([]APITestResult, error) {
+	return h.apiTester.TestAllConfigurationsWithProgress(ctx, options, onResult)
 }
 
 // TestCurrentConfiguration tests the currently active configuration
-func (h *configHandler) TestCurrentConfiguration(options TestOptions) (*APITestResult, error) {
-	return h.apiTester.TestCurrentConfiguration(options)
+func (h *configHandler) TestCurrentConfiguration(ctx context.Context, options TestOptions) (*APITestResult, error) {
+	return h.apiTester.TestCurrentConfiguration(ctx, options)
+}
+
+// GetClaudeCLIInfo reports the path and version of the Claude CLI used by the chat test
+func (h *configHandler) GetClaudeCLIInfo() ClaudeCLIInfo {
+	return h.apiTester.ClaudeCLIInfo()
+}
+
+// ListEndpointSets returns the named endpoint sets available when testing a profile.
+// An empty profile name lists only the built-in and global sets.
+func (h *configHandler) ListEndpointSets(profileName string) ([]config.EndpointSet, error) {
+	return h.configManager.ListEndpointSets(profileName)
+}
+
+// ResolveEndpointSet expands a named endpoint set into the endpoints it tests
+func (h *configHandler) ResolveEndpointSet(setName, profileName string) ([]string, error) {
+	return h.configManager.ResolveEndpointSet(setName, profileName)
+}
+
+// TestSettingsFile tests API connectivity for a settings file that is not a stored profile
+func (h *configHandler) TestSettingsFile(ctx context.Context, path string, options TestOptions) (*APITestResult, error) {
+	return h.apiTester.TestSettingsFile(ctx, path, options)
 }
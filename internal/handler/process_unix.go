@@ -0,0 +1,16 @@
+//go:build !windows
+
+package handler
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// configureProcessGroup 让 claude CLI 在独立的进程组中运行，取消时连同其派生的子进程一起结束
+func configureProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package handler
+
+import "os/exec"
+
+// configureProcessGroup 在 Windows 上保持默认行为：取消时仅结束 claude 进程本身
+func configureProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+)
+
+// protectedHeaders are request headers cc-switch sets itself. Custom headers may only
+// replace them when TestOptions.AllowHeaderOverride is set.
+var protectedHeaders = map[string]bool{
+	"Host":          true,
+	"Authorization": true,
+	"X-Api-Key":     true,
+}
+
+// parseCustomHeaders parses the ANTHROPIC_CUSTOM_HEADERS value: either one
+// "Name: value" per line, as Claude Code reads it, or a JSON object of name to value.
+// Names are returned in canonical form; a name given twice is an error.
+func parseCustomHeaders(value string) (map[string]string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	pairs := make(map[string]string)
+	if strings.HasPrefix(value, "{") {
+		if err := json.Unmarshal([]byte(value), &pairs); err != nil {
+			return nil, fmt.Errorf("%s is not a valid JSON object of header names to values: %w", config.CustomHeadersKey, err)
+		}
+	} else {
+		for i, line := range strings.Split(value, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			name, headerValue, ok := strings.Cut(line, ":")
+			if !ok {
+				return nil, fmt.Errorf("%s line %d is not in 'Name: value' form", config.CustomHeadersKey, i+1)
+			}
+			name = strings.TrimSpace(name)
+			if _, exists := pairs[name]; exists {
+				return nil, fmt.Errorf("header '%s' is set twice in %s", name, config.CustomHeadersKey)
+			}
+			pairs[name] = strings.TrimSpace(headerValue)
+		}
+	}
+
+	headers := make(map[string]string, len(pairs))
+	for name, headerValue := range pairs {
+		if !config.IsValidHeaderName(name) {
+			return nil, fmt.Errorf("invalid header name '%s' in %s", name, config.CustomHeadersKey)
+		}
+		if strings.ContainsAny(headerValue, "\r\n") {
+			return nil, fmt.Errorf("header '%s' in %s has a line break in its value", name, config.CustomHeadersKey)
+		}
+		canonical := http.CanonicalHeaderKey(name)
+		if _, exists := headers[canonical]; exists {
+			return nil, fmt.Errorf("header '%s' is set twice in %s", canonical, config.CustomHeadersKey)
+		}
+		headers[canonical] = headerValue
+	}
+	return headers, nil
+}
+
+// checkProtectedHeaders rejects custom headers that would replace the ones cc-switch sets,
+// unless overriding them was explicitly allowed
+func checkProtectedHeaders(headers map[string]string, allowOverride bool) error {
+	if allowOverride {
+		return nil
+	}
+	for name := range headers {
+		if protectedHeaders[name] {
+			return fmt.Errorf("%s sets '%s', which cc-switch sets itself; use --allow-header-override to send it anyway", config.CustomHeadersKey, name)
+		}
+	}
+	return nil
+}
+
+// applyCustomHeaders adds the configuration's custom headers to a test request.
+// It runs after the standard headers are set so allowed overrides take effect.
+func applyCustomHeaders(req *http.Request, credentials *APICredentials) {
+	for name, value := range credentials.Headers {
+		if name == "Host" {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+}
+
+// maskedRequestHeaders returns the custom headers for display, with values of headers
+// that look like credentials masked
+func maskedRequestHeaders(headers map[string]string) map[string]string {
+	if len(headers) == 0 {
+		return nil
+	}
+	masked := make(map[string]string, len(headers))
+	for name, value := range headers {
+		if isSecretHeader(name) {
+			value = maskHeaderValue(value)
+		}
+		masked[name] = value
+	}
+	return masked
+}
+
+// isSecretHeader reports whether a header name suggests its value is a credential
+func isSecretHeader(name string) bool {
+	switch strings.ToLower(name) {
+	case "authorization", "proxy-authorization", "cookie":
+		return true
+	}
+	return config.IsSecretKey(strings.ReplaceAll(name, "-", "_"))
+}
+
+// maskHeaderValue hides all but the last four characters of a header value
+func maskHeaderValue(value string) string {
+	if len(value) <= 8 {
+		return strings.Repeat("*", len(value))
+	}
+	return strings.Repeat("*", 8) + value[len(value)-4:]
+}
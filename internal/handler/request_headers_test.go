@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseCustomHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    map[string]string
+		wantErr string
+	}{
+		{name: "empty", value: "  ", want: nil},
+		{name: "lines", value: "x-org-token: abc\n\nX-Team: platform \n", want: map[string]string{"X-Org-Token": "abc", "X-Team": "platform"}},
+		{name: "value with colon", value: "X-Trace: a:b:c", want: map[string]string{"X-Trace": "a:b:c"}},
+		{name: "json", value: `{"x-org-token": "abc", "X-Team": "platform"}`, want: map[string]string{"X-Org-Token": "abc", "X-Team": "platform"}},
+		{name: "line without colon", value: "X-Org-Token abc", wantErr: "line 1"},
+		{name: "duplicate line", value: "X-Team: a\nX-Team: b", wantErr: "set twice"},
+		{name: "duplicate after canonicalizing", value: `{"x-team": "a", "X-Team": "b"}`, wantErr: "set twice"},
+		{name: "invalid name", value: "X Team: a", wantErr: "invalid header name"},
+		{name: "line break in value", value: `{"X-Team": "a\r\nHost: evil"}`, wantErr: "line break"},
+		{name: "invalid json", value: `{"X-Team": 1}`, wantErr: "not a valid JSON object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCustomHeaders(tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("headers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckProtectedHeaders(t *testing.T) {
+	for _, name := range []string{"Host", "Authorization", "X-Api-Key"} {
+		headers := map[string]string{name: "value"}
+		if err := checkProtectedHeaders(headers, false); err == nil {
+			t.Errorf("%s accepted without override", name)
+		}
+		if err := checkProtectedHeaders(headers, true); err != nil {
+			t.Errorf("%s rejected with override: %v", name, err)
+		}
+	}
+	if err := checkProtectedHeaders(map[string]string{"X-Org-Token": "abc"}, false); err != nil {
+		t.Errorf("an ordinary header was rejected: %v", err)
+	}
+}
+
+func TestMaskedRequestHeaders(t *testing.T) {
+	got := maskedRequestHeaders(map[string]string{
+		"X-Team":        "platform",
+		"X-Org-Token":   "org-token-123456",
+		"Authorization": "Bearer sk-123456789",
+		"Cookie":        "short",
+	})
+	want := map[string]string{
+		"X-Team":        "platform",
+		"X-Org-Token":   "********3456",
+		"Authorization": "********6789",
+		"Cookie":        "*****",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("masked = %v, want %v", got, want)
+	}
+	if got := maskedRequestHeaders(nil); got != nil {
+		t.Errorf("masked = %v, want nil", got)
+	}
+}
+
+// headerStub is a stub API server that records the headers of every request
+type headerStub struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []http.Header
+}
+
+func newHeaderStub(t *testing.T) *headerStub {
+	t.Helper()
+	stub := &headerStub{}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stub.mu.Lock()
+		header := r.Header.Clone()
+		header.Set("Host", r.Host)
+		stub.requests = append(stub.requests, header)
+		stub.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": []}`))
+	}))
+	t.Cleanup(stub.Close)
+	for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy"} {
+		t.Setenv(name, "")
+	}
+	return stub
+}
+
+func TestCustomHeadersAreSent(t *testing.T) {
+	stub := newHeaderStub(t)
+	cm := newTestManager(t)
+	if err := cm.CreateProfileWithContent("gateway", map[string]interface{}{"env": map[string]interface{}{
+		"ANTHROPIC_BASE_URL":       stub.URL,
+		"ANTHROPIC_AUTH_TOKEN":     "sk-stub",
+		"ANTHROPIC_CUSTOM_HEADERS": "X-Org-Token: org-token-123456\nX-Team: platform",
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewAPITester(cm).TestAPIConnectivity(context.Background(), "gateway", TestOptions{Endpoints: []string{"basic", "auth", "models"}, Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("TestAPIConnectivity: %v", err)
+	}
+	if result.Error != "" {
+		t.Fatalf("result error: %s", result.Error)
+	}
+	if len(stub.requests) != 3 {
+		t.Fatalf("stub saw %d requests, want 3", len(stub.requests))
+	}
+	for i, header := range stub.requests {
+		if header.Get("X-Org-Token") != "org-token-123456" || header.Get("X-Team") != "platform" {
+			t.Errorf("request %d headers = %v, want the custom headers", i, header)
+		}
+	}
+
+	// Reports show the headers that were sent, with the token masked
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "org-token-123456") || strings.Contains(string(data), "sk-stub") {
+		t.Error("the JSON result contains a credential")
+	}
+	for _, test := range result.Tests {
+		if test.RequestHeaders["X-Team"] != "platform" || test.RequestHeaders["X-Org-Token"] != "********3456" {
+			t.Errorf("%s request headers = %v, want them reported and masked", test.Method, test.RequestHeaders)
+		}
+	}
+}
+
+func TestCustomHeaderOverride(t *testing.T) {
+	stub := newHeaderStub(t)
+	cm := newTestManager(t)
+	if err := cm.CreateProfileWithContent("gateway", map[string]interface{}{"env": map[string]interface{}{
+		"ANTHROPIC_BASE_URL":       stub.URL,
+		"ANTHROPIC_AUTH_TOKEN":     "sk-stub",
+		"ANTHROPIC_CUSTOM_HEADERS": `{"Authorization": "Gateway sk-gateway", "Host": "api.internal"}`,
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	tester := NewAPITester(cm)
+	options := TestOptions{Endpoints: []string{"auth"}, Timeout: 5 * time.Second}
+
+	result, err := tester.TestAPIConnectivity(context.Background(), "gateway", options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result.Error, "--allow-header-override") || len(stub.requests) != 0 {
+		t.Fatalf("error = %q with %d requests, want the override refused before sending", result.Error, len(stub.requests))
+	}
+
+	options.AllowHeaderOverride = true
+	if _, err := tester.TestAPIConnectivity(context.Background(), "gateway", options); err != nil {
+		t.Fatal(err)
+	}
+	if len(stub.requests) != 1 {
+		t.Fatalf("stub saw %d requests, want 1", len(stub.requests))
+	}
+	if got := stub.requests[0].Get("Authorization"); got != "Gateway sk-gateway" {
+		t.Errorf("Authorization = %q, want the override", got)
+	}
+	if got := stub.requests[0].Get("Host"); got != "api.internal" {
+		t.Errorf("Host = %q, want the override", got)
+	}
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+)
+
+// proxyContextKey carries the proxy a test run should use in the request context
+type proxyContextKey struct{}
+
+// parseProxyURL parses the HTTPS_PROXY value of a configuration, as Claude Code would use it
+func parseProxyURL(value string) (*url.URL, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	if err := config.ValidateProxyURL(value); err != nil {
+		return nil, fmt.Errorf("%s: %w", config.ProxyEnvKey, err)
+	}
+	return url.Parse(value)
+}
+
+// withRequestProxy returns a context whose requests go through proxy; a nil proxy leaves ctx unchanged
+func withRequestProxy(ctx context.Context, proxy *url.URL) context.Context {
+	if proxy == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, proxyContextKey{}, proxy)
+}
+
+// requestProxy is the transport's proxy function. Tests only use the proxy configured in the
+// profile being tested, never one from cc-switch's own environment, so results do not depend on
+// the shell they are run from.
+func requestProxy(req *http.Request) (*url.URL, error) {
+	proxy, _ := req.Context().Value(proxyContextKey{}).(*url.URL)
+	return proxy, nil
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxHeaderValueLength caps captured header values so a misbehaving proxy cannot flood the output
+const maxHeaderValueLength = 200
+
+// capturedHeaderNames are response headers copied verbatim into EndpointTest.Headers
+var capturedHeaderNames = map[string]bool{
+	"request-id":   true,
+	"x-request-id": true,
+	"server":       true,
+}
+
+// capturedHeaderPrefixes are header prefixes copied into EndpointTest.Headers
+var capturedHeaderPrefixes = []string{"anthropic-", "ratelimit-", "x-ratelimit-"}
+
+// rateLimitPrefixes are the header families checked for rate-limit information, most specific first
+var rateLimitPrefixes = []string{
+	"anthropic-ratelimit-requests-",
+	"anthropic-ratelimit-tokens-",
+	"x-ratelimit-",
+	"ratelimit-",
+}
+
+// captureResponseHeaders copies the safelisted response headers, keyed by lower-case name.
+// Only headers that identify the backend or describe quota are kept; credentials and
+// cookies never match the safelist.
+func captureResponseHeaders(header http.Header) map[string]string {
+	var captured map[string]string
+	for name, values := range header {
+		name = strings.ToLower(name)
+		if len(values) == 0 || !isCapturedHeader(name) {
+			continue
+		}
+		value := strings.Join(values, ", ")
+		if len(value) > maxHeaderValueLength {
+			value = value[:maxHeaderValueLength] + "..."
+		}
+		if captured == nil {
+			captured = make(map[string]string)
+		}
+		captured[name] = value
+	}
+	return captured
+}
+
+// isCapturedHeader reports whether a lower-case header name is on the safelist
+func isCapturedHeader(name string) bool {
+	if name == "authorization" || name == "cookie" || name == "set-cookie" || strings.Contains(name, "api-key") {
+		return false
+	}
+	if capturedHeaderNames[name] {
+		return true
+	}
+	for _, prefix := range capturedHeaderPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRateLimit extracts the remaining quota and reset time from captured headers.
+// Returns nil when no family reports a remaining count.
+func parseRateLimit(headers map[string]string, now time.Time) *RateLimit {
+	for _, prefix := range rateLimitPrefixes {
+		remaining, err := strconv.Atoi(strings.TrimSpace(headers[prefix+"remaining"]))
+		if err != nil {
+			continue
+		}
+
+		rateLimit := &RateLimit{Source: strings.TrimSuffix(prefix, "-"), Remaining: remaining}
+		if limit, err := strconv.Atoi(strings.TrimSpace(headers[prefix+"limit"])); err == nil {
+			rateLimit.Limit = limit
+		}
+		if reset, ok := parseRateLimitReset(headers[prefix+"reset"], now); ok {
+			rateLimit.Reset = reset
+		}
+		return rateLimit
+	}
+	return nil
+}
+
+// parseRateLimitReset understands the reset formats gateways use: an RFC 3339 timestamp,
+// a Go-style duration ("6m0s"), a Unix timestamp or a number of seconds from now
+func parseRateLimitReset(value string, now time.Time) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		// 大于 10 年的秒数只可能是 Unix 时间戳
+		if seconds > 10*365*24*3600 {
+			return time.Unix(int64(seconds), 0), true
+		}
+		return now.Add(time.Duration(seconds * float64(time.Second))), true
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(d), true
+	}
+	return time.Time{}, false
+}
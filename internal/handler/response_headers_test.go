@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCaptureResponseHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Request-Id", "req_123")
+	header.Set("Server", "envoy")
+	header.Set("Anthropic-Ratelimit-Requests-Remaining", "42")
+	header.Set("X-Ratelimit-Limit", "100")
+	header.Set("Authorization", "Bearer sk-secret")
+	header.Set("Set-Cookie", "session=secret")
+	header.Set("X-Api-Key", "sk-secret")
+	header.Set("Anthropic-Api-Key", "sk-secret")
+	header.Set("Content-Type", "application/json")
+	header.Set("X-Request-Id", strings.Repeat("a", 300))
+
+	got := captureResponseHeaders(header)
+	for _, name := range []string{"request-id", "server", "anthropic-ratelimit-requests-remaining", "x-ratelimit-limit", "x-request-id"} {
+		if _, ok := got[name]; !ok {
+			t.Errorf("%s was not captured", name)
+		}
+	}
+	for _, name := range []string{"authorization", "set-cookie", "x-api-key", "anthropic-api-key", "content-type"} {
+		if value, ok := got[name]; ok {
+			t.Errorf("%s was captured as %q", name, value)
+		}
+	}
+	if len(got["x-request-id"]) != maxHeaderValueLength+len("...") {
+		t.Errorf("x-request-id has %d characters, want it truncated", len(got["x-request-id"]))
+	}
+
+	if got := captureResponseHeaders(http.Header{"Content-Type": {"text/plain"}}); got != nil {
+		t.Errorf("captured %v, want nil without safelisted headers", got)
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    *RateLimit
+	}{
+		{name: "none", headers: map[string]string{"server": "envoy"}, want: nil},
+		{name: "remaining not a number", headers: map[string]string{"x-ratelimit-remaining": "lots"}, want: nil},
+		{
+			name: "anthropic requests preferred",
+			headers: map[string]string{
+				"anthropic-ratelimit-requests-remaining": "49",
+				"anthropic-ratelimit-requests-limit":     "50",
+				"anthropic-ratelimit-requests-reset":     "2026-10-16T12:01:00Z",
+				"anthropic-ratelimit-tokens-remaining":   "1000",
+			},
+			want: &RateLimit{Source: "anthropic-ratelimit-requests", Limit: 50, Remaining: 49, Reset: now.Add(time.Minute)},
+		},
+		{
+			name:    "seconds from now",
+			headers: map[string]string{"x-ratelimit-remaining": "3", "x-ratelimit-reset": "30"},
+			want:    &RateLimit{Source: "x-ratelimit", Remaining: 3, Reset: now.Add(30 * time.Second)},
+		},
+		{
+			name:    "unix timestamp",
+			headers: map[string]string{"ratelimit-remaining": "0", "ratelimit-reset": "1791000000"},
+			want:    &RateLimit{Source: "ratelimit", Remaining: 0, Reset: time.Unix(1791000000, 0)},
+		},
+		{
+			name:    "duration",
+			headers: map[string]string{"x-ratelimit-remaining": "7", "x-ratelimit-reset": "6m0s"},
+			want:    &RateLimit{Source: "x-ratelimit", Remaining: 7, Reset: now.Add(6 * time.Minute)},
+		},
+		{
+			name:    "unparseable reset",
+			headers: map[string]string{"x-ratelimit-remaining": "7", "x-ratelimit-reset": "soon"},
+			want:    &RateLimit{Source: "x-ratelimit", Remaining: 7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseRateLimit(tt.headers, now)
+			if (got == nil) != (tt.want == nil) {
+				t.Fatalf("parseRateLimit = %+v, want %+v", got, tt.want)
+			}
+			if got != nil && (got.Source != tt.want.Source || got.Limit != tt.want.Limit || got.Remaining != tt.want.Remaining || !got.Reset.Equal(tt.want.Reset)) {
+				t.Errorf("parseRateLimit = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPITestCapturesResponseHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Request-Id", "req_stub")
+		w.Header().Set("Server", "stub-gateway")
+		w.Header().Set("Anthropic-Ratelimit-Requests-Limit", "50")
+		w.Header().Set("Anthropic-Ratelimit-Requests-Remaining", "49")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": []}`))
+	}))
+	defer server.Close()
+
+	cm := newTestManager(t)
+	for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy"} {
+		t.Setenv(name, "")
+	}
+	content := map[string]interface{}{"env": map[string]interface{}{
+		"ANTHROPIC_BASE_URL":   server.URL,
+		"ANTHROPIC_AUTH_TOKEN": "sk-stub",
+	}}
+	if err := cm.CreateProfileWithContent("stub", content); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewAPITester(cm).TestAPIConnectivity(context.Background(), "stub", TestOptions{Endpoints: []string{"auth", "models"}, Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("TestAPIConnectivity: %v", err)
+	}
+	if len(result.Tests) != 2 {
+		t.Fatalf("tests = %+v, want auth and models", result.Tests)
+	}
+	for _, test := range result.Tests {
+		if test.Status != "success" {
+			t.Errorf("%s: status %s (%s)", test.Method, test.Status, test.Error)
+		}
+		if test.Headers["request-id"] != "req_stub" || test.Headers["server"] != "stub-gateway" {
+			t.Errorf("%s headers = %v, want request-id and server", test.Method, test.Headers)
+		}
+		if _, ok := test.Headers["set-cookie"]; ok {
+			t.Errorf("%s captured the cookie", test.Method)
+		}
+	}
+	if result.RateLimit == nil || result.RateLimit.Remaining != 49 || result.RateLimit.Limit != 50 {
+		t.Errorf("rate limit = %+v, want 49 of 50 remaining", result.RateLimit)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"headers":{`, `"rate_limit":{`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("JSON is missing %s", want)
+		}
+	}
+	if strings.Contains(string(data), "sk-stub") || strings.Contains(string(data), "session=secret") {
+		t.Error("JSON contains a credential")
+	}
+}
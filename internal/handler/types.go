@@ -1,8 +1,12 @@
 package handler
 
 import (
-	"cc-switch/internal/config"
+	"context"
+	"fmt"
+	"net/url"
 	"time"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
 )
 
 // ConfigHandler defines the business logic interface for configuration operations
@@ -13,6 +17,8 @@ type ConfigHandler interface {
 	DeleteAllConfigs() error
 	DeleteCurrentConfig() error
 	UseConfig(name string) error
+	UseConfigWithNote(name, note string) error
+	UseConfigWithOptions(name string, options config.SwitchOptions) error
 	ViewConfig(name string, raw bool) (*ConfigView, error)
 	EditConfig(name string, field string, useNano bool) error
 	CreateConfig(name string, templateName string) error
@@ -21,18 +27,40 @@ type ConfigHandler interface {
 	// New configuration operations
 	MoveConfig(oldName, newName string) error
 	CopyConfig(sourceName, destName string) error
+	CreateScratchConfig() (string, error)
 	UpdateConfig(name string, content map[string]interface{}) error
+	PatchConfig(name string, patch []byte) error
+	GetConfigPermissions(name string) (config.ProfilePermissions, error)
+	AddConfigPermission(name, list, rule string) (bool, error)
+	RemoveConfigPermission(name, rule string) ([]string, error)
+	CopyTemplatePermissions(name, templateName string) (config.ProfilePermissions, error)
+	ResetConfig(name, templateName string, keepSecrets bool) error
+	GetConfigTemplate(name string) (string, error)
+	SetConfigDisplayName(name, displayName string) error
+	SetConfigProject(name, dir string) (string, error)
+	GetConfigProject(name string) (string, error)
+	DiffConfigs(fromName, toName string) ([]config.DiffEntry, error)
+	DiffConfigAgainstSettings(name string) ([]config.DiffEntry, error)
+	DiffOperands(left, right config.DiffOperand) ([]config.DiffEntry, error)
+	LocalSettingsOverrides(name string) ([]config.DiffEntry, error)
+	PlanSwitch(name string) ([]config.DiffEntry, error)
 
 	// Template management operations
 	ListTemplates() ([]string, error)
 	CreateTemplate(name string) error
 	EditTemplate(name string, field string, useNano bool) error
 	UpdateTemplate(name string, content map[string]interface{}) error
+	PatchTemplate(name string, patch []byte) error
 	DeleteTemplate(name string) error
+	DeleteTemplateDetached(name string) error
+	TemplateInUse(name string) ([]string, error)
 	ValidateTemplateExists(name string) error
 	CopyTemplate(sourceName, destName string) error
 	MoveTemplate(oldName, newName string) error
 	ViewTemplate(name string, raw bool) (*TemplateView, error)
+	DefaultTemplateName() string
+	SetDefaultTemplate(name string) error
+	ResolveTemplate(name string) (string, string)
 
 	// Init operations
 	InitializeConfig(authToken, baseURL string) error
@@ -40,10 +68,14 @@ type ConfigHandler interface {
 
 	// Helper operations
 	ValidateConfigExists(name string) error
+	ValidateConfig(name string) ([]config.ValidationIssue, error)
+	ValidateContent(content map[string]interface{}, isTemplate bool) []config.ValidationIssue
 	GetCurrentConfig() (string, error)
 	GetCurrentConfigurationForOperation() (string, error)
 	IsCurrentConfig(name string) bool
 	GetPreviousConfig() (string, error)
+	GetHistory() ([]config.HistoryEntry, error)
+	AttachLastTests(profiles []config.Profile) error
 
 	// Empty mode operations
 	UseEmptyMode() error
@@ -51,19 +83,39 @@ type ConfigHandler interface {
 	RestoreToPreviousFromEmptyMode() error
 	IsEmptyMode() bool
 	GetEmptyModeStatus() (*EmptyModeStatus, error)
+	GetEmptyModeBackup() (*EmptyModeBackup, error)
+	ExportEmptyModeBackup(name string) error
+
+	// Diagnostics operations
+	DiagnoseProfiles() ([]ProfileDiagnosis, error)
+	FindUntrackedProfiles() ([]config.UntrackedProfile, error)
+	AdoptProfile(name, origin string, inheritTags bool) error
 
 	// API connectivity testing operations
-	TestAPIConnectivity(profileName string, options TestOptions) (*APITestResult, error)
-	TestAllConfigurations(options TestOptions) ([]APITestResult, error)
-	TestCurrentConfiguration(options TestOptions) (*APITestResult, error)
+	TestAPIConnectivity(ctx context.Context, profileName string, options TestOptions) (*APITestResult, error)
+	TestAllConfigurations(ctx context.Context, options TestOptions) ([]APITestResult, error)
+	TestAllConfigurationsWithProgress(ctx context.Context, options TestOptions, onResult func(APITestResult)) ([]APITestResult, error)
+	TestCurrentConfiguration(ctx context.Context, options TestOptions) (*APITestResult, error)
+	TestSettingsFile(ctx context.Context, path string, options TestOptions) (*APITestResult, error)
+	GetClaudeCLIInfo() ClaudeCLIInfo
+	ListEndpointSets(profileName string) ([]config.EndpointSet, error)
+	ResolveEndpointSet(setName, profileName string) ([]string, error)
 }
 
 // ConfigView represents the view of a configuration
 type ConfigView struct {
-	Name      string                 `json:"name"`
-	IsCurrent bool                   `json:"is_current"`
-	Path      string                 `json:"path"`
-	Content   map[string]interface{} `json:"content"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name,omitempty"`
+	IsCurrent   bool   `json:"is_current"`
+	Path        string `json:"path"`
+	Project     string `json:"project,omitempty"` // Project directory Claude Code is launched in
+	Network     string `json:"network,omitempty"` // Summary of the _net proxy and headers
+	// Origin is where the configuration came from (template:<name>, import:<file>,
+	// snapshot, manual, copy-of:<name> or unknown); OriginChain follows copies
+	// back to their source, starting with Origin
+	Origin      string                 `json:"origin"`
+	OriginChain []string               `json:"origin_chain,omitempty"`
+	Content     map[string]interface{} `json:"content"`
 }
 
 // DeleteResult represents the result of a delete operation
@@ -119,6 +171,15 @@ type TemplateResult struct {
 	Message string `json:"message"`
 }
 
+// ProfileDiagnosis represents the health of a single configuration as reported by doctor
+type ProfileDiagnosis struct {
+	Name         string                   `json:"name"`
+	WrittenBy    string                   `json:"written_by,omitempty"`
+	OtherVersion bool                     `json:"other_version"` // last written by a different cc-switch version
+	NewerVersion bool                     `json:"newer_version"` // last written by a newer major/minor version
+	Issues       []config.ValidationIssue `json:"issues,omitempty"`
+}
+
 // EmptyModeStatus represents the current empty mode status
 type EmptyModeStatus struct {
 	Enabled         bool   `json:"enabled"`
@@ -127,6 +188,14 @@ type EmptyModeStatus struct {
 	Timestamp       string `json:"timestamp,omitempty"`
 }
 
+// EmptyModeBackup describes the settings.json backup kept while in empty mode
+type EmptyModeBackup struct {
+	EmptyModeStatus
+	BackupPath string                 `json:"backup_path"`
+	Size       int64                  `json:"size"`
+	Content    map[string]interface{} `json:"content"`
+}
+
 // API Testing Types
 
 // APITestResult represents connectivity test results for a configuration
@@ -137,6 +206,19 @@ type APITestResult struct {
 	Tests         []EndpointTest `json:"tests"`
 	TestedAt      time.Time      `json:"tested_at"`
 	Error         string         `json:"error,omitempty"`
+	Skipped       bool           `json:"skipped,omitempty"` // profile file could not be read, so no tests ran
+	// RateLimit is the quota reported by the most recent response that carried rate-limit headers
+	RateLimit *RateLimit `json:"rate_limit,omitempty"`
+	// Proxy is the proxy the requests went through, with any password redacted
+	Proxy string `json:"proxy,omitempty"`
+}
+
+// RateLimit is the quota parsed from rate-limit response headers
+type RateLimit struct {
+	Source    string    `json:"source"` // header family, e.g. "anthropic-ratelimit-requests"
+	Limit     int       `json:"limit,omitempty"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset,omitempty"`
 }
 
 // EndpointTest represents individual API endpoint test results
@@ -149,6 +231,10 @@ type EndpointTest struct {
 	ResponseTime time.Duration `json:"response_time_ms"`
 	Error        string        `json:"error,omitempty"`
 	Details      string        `json:"details,omitempty"`
+	// Headers holds safelisted response headers (request ids, server, anthropic-*, rate limits)
+	Headers map[string]string `json:"headers,omitempty"`
+	// RequestHeaders holds the custom headers sent (ANTHROPIC_CUSTOM_HEADERS), credentials masked
+	RequestHeaders map[string]string `json:"request_headers,omitempty"`
 }
 
 // TestOptions controls API test behavior
@@ -161,6 +247,41 @@ type TestOptions struct {
 	RetryEnabled  bool          `json:"retry_enabled"`
 	MaxRetries    int           `json:"max_retries"` // 0 means infinite retries
 	RetryInterval time.Duration `json:"retry_interval"`
+	// NoIsolate runs the Claude CLI chat test against the real ~/.claude instead of a temporary HOME
+	NoIsolate bool `json:"no_isolate,omitempty"`
+	// AllowHeaderOverride lets ANTHROPIC_CUSTOM_HEADERS replace Host, Authorization and x-api-key
+	AllowHeaderOverride bool `json:"allow_header_override"`
+	// Concurrency is how many configurations TestAllConfigurations tests at once; 0 or 1 tests them one by one
+	Concurrency int `json:"concurrency,omitempty"`
+}
+
+// ClaudeCLIInfo describes the Claude CLI used by the chat test
+type ClaudeCLIInfo struct {
+	Path    string `json:"path,omitempty"`
+	Version string `json:"version,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// DiagnosticBundle is a redacted report of a test run for attaching to bug reports
+type DiagnosticBundle struct {
+	GeneratedAt     time.Time              `json:"generated_at"`
+	CCSwitchVersion string                 `json:"cc_switch_version"`
+	OS              string                 `json:"os"`
+	Arch            string                 `json:"arch"`
+	ClaudeCLI       ClaudeCLIInfo          `json:"claude_cli"`
+	Profile         string                 `json:"profile"`
+	Content         map[string]interface{} `json:"content"` // profile content with secrets masked
+	Result          *APITestResult         `json:"result"`
+}
+
+// SettingsFileError reports a settings file that cannot be tested because it is malformed
+type SettingsFileError struct {
+	Path   string
+	Issues []config.ValidationIssue
+}
+
+func (e *SettingsFileError) Error() string {
+	return fmt.Sprintf("settings file '%s' is not a valid configuration", e.Path)
 }
 
 // APICredentials represents extracted API authentication credentials
@@ -168,4 +289,13 @@ type APICredentials struct {
 	APIKey  string `json:"api_key"`
 	BaseURL string `json:"base_url"`
 	Version string `json:"version,omitempty"`
+	// AuthStyle is config.AuthStyleAPIKey to send the key as x-api-key, otherwise
+	// it is sent as a bearer token
+	AuthStyle string `json:"auth_style,omitempty"`
+	// Headers are the custom request headers from ANTHROPIC_CUSTOM_HEADERS, by canonical name.
+	// They can hold credentials, so they are never serialized.
+	Headers map[string]string `json:"-"`
+	// Proxy is the proxy from HTTPS_PROXY that test requests are sent through, if any.
+	// It can hold credentials, so it is never serialized.
+	Proxy *url.URL `json:"-"`
 }
@@ -3,47 +3,113 @@ package importer
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
-	"cc-switch/internal/config"
-	"cc-switch/internal/export"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/export"
 )
 
 // ImportOptions defines import configuration
 type ImportOptions struct {
 	ConflictMode string `json:"conflict_mode"` // How to handle conflicts: skip, overwrite, both
 	DryRun       bool   `json:"dry_run"`       // Only validate, don't actually import
+	ShowDiff     bool   `json:"show_diff"`     // In a dry run, compute what each overwrite would change
+	// RestoreCurrent switches to the profile marked current in the file once it has been imported
+	RestoreCurrent bool `json:"restore_current"`
+	// Only limits the import to profiles whose names match one of these names or globs; empty imports all
+	Only []string `json:"only,omitempty"`
 }
 
 // ImportResult represents the result of an import operation
 type ImportResult struct {
-	ProfilesImported []string      // Successfully imported profiles
-	Conflicts        []string      // Profiles that had conflicts
-	Errors           []error       // Errors encountered during import
-	Summary          ImportSummary // Summary statistics
+	ProfilesImported  []string      // Successfully imported profiles
+	TemplatesImported []string      // Successfully imported templates
+	Conflicts         []string      // Profiles that had conflicts
+	Errors            []error       // Errors encountered during import
+	Summary           ImportSummary // Summary statistics
+
+	// OverwriteDiffs maps each profile that would be overwritten to its changes (dry run with ShowDiff only)
+	OverwriteDiffs map[string][]config.DiffEntry
+
+	// CurrentRestored is the (possibly renamed) profile switched to with RestoreCurrent
+	CurrentRestored string
+	// CurrentNotice explains why RestoreCurrent did not switch, e.g. the profile was skipped
+	CurrentNotice string
 }
 
 // ImportSummary provides import statistics
 type ImportSummary struct {
-	TotalProfiles int // Total profiles in import file
-	ImportedCount int // Successfully imported
-	SkippedCount  int // Skipped due to conflicts
-	RenamedCount  int // Renamed due to conflicts
-	ErrorCount    int // Failed imports
+	TotalProfiles  int // Total profiles in import file
+	TotalTemplates int // Total templates in import file
+	ImportedCount  int // Successfully imported
+	SkippedCount   int // Skipped due to conflicts
+	RenamedCount   int // Renamed due to conflicts
+	ErrorCount     int // Failed imports
 }
 
+// Profile progress statuses reported to a ProgressFunc
+const (
+	StatusImported    = "imported"
+	StatusOverwritten = "overwritten"
+	StatusRenamed     = "renamed"
+	StatusSkipped     = "skipped"
+	StatusDryRun      = "dry_run"
+	StatusError       = "error"
+)
+
+// ProfileProgress describes the outcome of importing a single profile
+type ProfileProgress struct {
+	Name    string `json:"name"`               // Name of the profile in the import file
+	Status  string `json:"status"`             // One of the Status* constants
+	NewName string `json:"new_name,omitempty"` // Final name when the profile was renamed
+	Error   string `json:"error,omitempty"`
+	Index   int    `json:"index"` // 1-based position in the import file
+	Total   int    `json:"total"`
+}
+
+// ProgressFunc is invoked after each profile has been processed
+type ProgressFunc func(progress ProfileProgress)
+
 // ConflictInfo represents a naming conflict
 type ConflictInfo struct {
 	OriginalName  string // Original profile name
 	ConflictName  string // Conflicting name
 	SuggestedName string // Suggested alternative name
+	Template      bool   // The conflict is with a template rather than a profile
+}
+
+// ArchiveListing describes the contents of an import file without importing it
+type ArchiveListing struct {
+	Metadata  *export.CCXMetadata `json:"metadata"`
+	Profiles  []ListedProfile     `json:"profiles"`
+	Templates []ListedTemplate    `json:"templates,omitempty"`
+	Secrets   int                 `json:"secrets,omitempty"` // Number of secrets embedded with --include-secrets
+}
+
+// ListedProfile is one profile in an ArchiveListing. Only env key names are listed, never values.
+type ListedProfile struct {
+	Name       string   `json:"name"`
+	IsCurrent  bool     `json:"is_current,omitempty"` // Active when the file was exported
+	Conflict   bool     `json:"conflict"`             // A local profile already has this name
+	EnvKeys    []string `json:"env_keys"`
+	ModifiedAt string   `json:"modified_at,omitempty"`
+}
+
+// ListedTemplate is one template in an ArchiveListing
+type ListedTemplate struct {
+	Name     string `json:"name"`
+	Conflict bool   `json:"conflict"` // A local template already has this name
 }
 
 // Importer interface defines import operations
 type Importer interface {
 	Import(inputPath string, password string, options ImportOptions) (*ImportResult, error)
+	ImportWithProgress(inputPath string, password string, options ImportOptions, progress ProgressFunc) (*ImportResult, error)
 	ValidateFile(inputPath string) (*export.CCXMetadata, error)
 	CheckConflicts(inputPath string, password string) ([]ConflictInfo, error)
+	List(inputPath string, password string, only []string) (*ArchiveListing, error)
 }
 
 // ImporterImpl implements the Importer interface
@@ -62,6 +128,11 @@ func NewImporter(configManager *config.ConfigManager) *ImporterImpl {
 
 // Import imports profiles from a CCX file
 func (i *ImporterImpl) Import(inputPath string, password string, options ImportOptions) (*ImportResult, error) {
+	return i.ImportWithProgress(inputPath, password, options, nil)
+}
+
+// ImportWithProgress imports profiles from a CCX file, calling progress (if non-nil) after each profile
+func (i *ImporterImpl) ImportWithProgress(inputPath string, password string, options ImportOptions, progress ProgressFunc) (*ImportResult, error) {
 	// Validate file exists
 	if _, err := os.Stat(inputPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("import file does not exist: %s", inputPath)
@@ -79,31 +150,162 @@ func (i *ImporterImpl) Import(inputPath string, password string, options ImportO
 	if err != nil {
 		return nil, fmt.Errorf("failed to read import file: %w", err)
 	}
+	exportData.Profiles, err = filterProfiles(exportData.Profiles, options.Only)
+	if err != nil {
+		return nil, err
+	}
 
 	// Initialize result
 	result := &ImportResult{
 		ProfilesImported: make([]string, 0),
 		Conflicts:        make([]string, 0),
 		Errors:           make([]error, 0),
+		OverwriteDiffs:   make(map[string][]config.DiffEntry),
 		Summary: ImportSummary{
-			TotalProfiles: len(exportData.Profiles),
+			TotalProfiles:  len(exportData.Profiles),
+			TotalTemplates: len(exportData.Templates),
 		},
 	}
 
-	// Process each profile
-	for _, profileData := range exportData.Profiles {
-		if err := i.importProfile(profileData, options, result); err != nil {
+	// Process each profile, remembering what became of the one marked current
+	currentName, currentStatus := "", ""
+	for index, profileData := range exportData.Profiles {
+		finalName, status, err := i.importProfile(profileData, inputPath, options, result)
+		if profileData.IsCurrent {
+			currentName, currentStatus = finalName, status
+			if err != nil {
+				currentStatus = StatusError
+			}
+		}
+		if err != nil {
 			result.Errors = append(result.Errors, fmt.Errorf("failed to import profile '%s': %w", profileData.Name, err))
 			result.Summary.ErrorCount++
 		}
+
+		if progress != nil {
+			event := ProfileProgress{
+				Name:   profileData.Name,
+				Status: status,
+				Index:  index + 1,
+				Total:  len(exportData.Profiles),
+			}
+			if err != nil {
+				event.Status = StatusError
+				event.Error = err.Error()
+			}
+			if finalName != profileData.Name {
+				event.NewName = finalName
+			}
+			progress(event)
+		}
+	}
+
+	// Import secrets embedded with --include-secrets; local secrets are never overwritten
+	if len(exportData.Secrets) > 0 && !options.DryRun {
+		_, conflicts, err := i.configManager.MergeSecrets(exportData.Secrets)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("failed to import secrets: %w", err))
+			result.Summary.ErrorCount++
+		}
+		for _, name := range conflicts {
+			result.Conflicts = append(result.Conflicts, fmt.Sprintf("secret %s (kept local value)", name))
+		}
+	}
+
+	// Import templates embedded in backups and template exports
+	for _, templateData := range exportData.Templates {
+		if err := i.importTemplate(templateData, options, result); err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("failed to import template '%s': %w", templateData.Name, err))
+			result.Summary.ErrorCount++
+		}
+	}
+
+	if options.RestoreCurrent {
+		i.restoreCurrent(currentName, currentStatus, options, result)
 	}
 
 	// Update summary
 	result.Summary.ImportedCount = len(result.ProfilesImported)
 
+	if !options.DryRun {
+		i.configManager.LogActivity(config.ActivityEntry{
+			Action: config.ActivityImport,
+			Detail: fmt.Sprintf("%s: %d imported, %d skipped, %d errors", filepath.Base(inputPath),
+				result.Summary.ImportedCount, result.Summary.SkippedCount, result.Summary.ErrorCount),
+		})
+	}
+
 	return result, nil
 }
 
+// restoreCurrent switches to the imported profile that was current in the file,
+// following renames; profiles that were skipped or failed are left alone
+func (i *ImporterImpl) restoreCurrent(name, status string, options ImportOptions, result *ImportResult) {
+	switch {
+	case name == "":
+		result.CurrentNotice = "the file does not mark a current profile"
+		return
+	case status == StatusSkipped:
+		result.CurrentNotice = fmt.Sprintf("'%s' was skipped because of a conflict, so the active profile was not changed", name)
+		return
+	case status == StatusError:
+		result.CurrentNotice = fmt.Sprintf("'%s' failed to import, so the active profile was not changed", name)
+		return
+	case status == StatusDryRun:
+		result.CurrentRestored = name
+		return
+	case i.configManager.IsEmptyMode():
+		result.CurrentNotice = fmt.Sprintf("empty mode is active; run 'cc-switch use %s' to activate it", name)
+		return
+	}
+
+	// An overwritten profile that is already active has been synced to settings.json by the update
+	if current, _ := i.configManager.GetCurrentProfile(); current != name {
+		if err := i.configManager.UseProfile(name); err != nil {
+			result.CurrentNotice = fmt.Sprintf("failed to switch to '%s': %v", name, err)
+			return
+		}
+	}
+	result.CurrentRestored = name
+}
+
+// filterProfiles keeps the profiles matching one of the --only names or globs.
+// It is an error if patterns are given and nothing matches, so a typo does not import nothing silently.
+func filterProfiles(profiles []export.ProfileData, only []string) ([]export.ProfileData, error) {
+	if len(only) == 0 {
+		return profiles, nil
+	}
+	for _, pattern := range only {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, config.Invalidf("invalid --only pattern '%s': %w", pattern, err)
+		}
+	}
+
+	var matched []export.ProfileData
+	for _, profile := range profiles {
+		if MatchesOnly(profile.Name, only) {
+			matched = append(matched, profile)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, config.NotFoundf("no profiles in the file match %s", strings.Join(only, ", "))
+	}
+	return matched, nil
+}
+
+// MatchesOnly reports whether a profile name is selected by the --only names or globs (all names when empty)
+func MatchesOnly(name string, only []string) bool {
+	if len(only) == 0 {
+		return true
+	}
+	for _, pattern := range only {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
 // ValidateFile validates a CCX file format
 func (i *ImporterImpl) ValidateFile(inputPath string) (*export.CCXMetadata, error) {
 	file, err := os.Open(inputPath)
@@ -138,13 +340,78 @@ func (i *ImporterImpl) CheckConflicts(inputPath string, password string) ([]Conf
 			})
 		}
 	}
+	for _, templateData := range exportData.Templates {
+		if i.configManager.TemplateExists(templateData.Name) {
+			conflicts = append(conflicts, ConflictInfo{
+				OriginalName:  templateData.Name,
+				ConflictName:  templateData.Name,
+				SuggestedName: i.generateAlternativeTemplateName(templateData.Name),
+				Template:      true,
+			})
+		}
+	}
 
 	return conflicts, nil
 }
 
-// importProfile imports a single profile
-func (i *ImporterImpl) importProfile(profileData export.ProfileData, options ImportOptions, result *ImportResult) error {
+// List reads an import file and describes its profiles and templates without writing anything.
+// only filters the profiles as ImportOptions.Only does.
+func (i *ImporterImpl) List(inputPath string, password string, only []string) (*ArchiveListing, error) {
+	metadata, err := i.ValidateFile(inputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open import file: %w", err)
+	}
+	defer file.Close()
+
+	exportData, err := i.ccxHandler.Read(file, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read import file: %w", err)
+	}
+	profiles, err := filterProfiles(exportData.Profiles, only)
+	if err != nil {
+		return nil, err
+	}
+
+	listing := &ArchiveListing{
+		Metadata: metadata,
+		Profiles: make([]ListedProfile, 0, len(profiles)),
+		Secrets:  len(exportData.Secrets),
+	}
+	for _, profileData := range profiles {
+		envKeys := []string{}
+		if env, ok := profileData.Content["env"].(map[string]interface{}); ok {
+			for key := range env {
+				envKeys = append(envKeys, key)
+			}
+			sort.Strings(envKeys)
+		}
+		listing.Profiles = append(listing.Profiles, ListedProfile{
+			Name:       profileData.Name,
+			IsCurrent:  profileData.IsCurrent,
+			Conflict:   i.configManager.ProfileExists(profileData.Name),
+			EnvKeys:    envKeys,
+			ModifiedAt: profileData.Metadata.ModifiedAt,
+		})
+	}
+	for _, templateData := range exportData.Templates {
+		listing.Templates = append(listing.Templates, ListedTemplate{
+			Name:     templateData.Name,
+			Conflict: i.configManager.TemplateExists(templateData.Name),
+		})
+	}
+
+	return listing, nil
+}
+
+// importProfile imports a single profile and returns its final name and progress status
+func (i *ImporterImpl) importProfile(profileData export.ProfileData, inputPath string, options ImportOptions, result *ImportResult) (string, string, error) {
 	finalName := profileData.Name
+	status := StatusImported
 
 	// Check for conflicts
 	if i.configManager.ProfileExists(finalName) {
@@ -157,15 +424,23 @@ func (i *ImporterImpl) importProfile(profileData export.ProfileData, options Imp
 				result.Conflicts = append(result.Conflicts, fmt.Sprintf("%s (skipped)", finalName))
 				result.Summary.SkippedCount++
 			}
-			return nil
+			return finalName, StatusSkipped, nil
 
 		case "overwrite":
 			// Overwrite existing profiles
 			if options.DryRun {
 				result.Conflicts = append(result.Conflicts, fmt.Sprintf("%s (would be overwritten)", finalName))
+				if options.ShowDiff {
+					existing, _, err := i.configManager.GetProfileContent(finalName)
+					if err != nil {
+						return finalName, StatusError, fmt.Errorf("failed to read existing profile: %w", err)
+					}
+					result.OverwriteDiffs[finalName] = config.DiffContent(existing, profileData.Content)
+				}
 			} else {
 				result.Conflicts = append(result.Conflicts, fmt.Sprintf("%s (overwritten)", finalName))
 			}
+			status = StatusOverwritten
 
 		case "both":
 			// Rename conflicting profiles
@@ -177,40 +452,109 @@ func (i *ImporterImpl) importProfile(profileData export.ProfileData, options Imp
 				result.Summary.RenamedCount++
 			}
 			finalName = alternativeName
+			status = StatusRenamed
 		}
 	}
 
 	if options.DryRun {
 		result.ProfilesImported = append(result.ProfilesImported, finalName+" (dry run)")
-		return nil
+		return finalName, StatusDryRun, nil
 	}
 
 	// Validate profile content
 	if err := i.validateProfileContent(profileData.Content); err != nil {
-		return fmt.Errorf("invalid profile content: %w", err)
+		return finalName, StatusError, fmt.Errorf("invalid profile content: %w", err)
 	}
 
 	// Create or update the profile
 	if i.configManager.ProfileExists(finalName) && options.ConflictMode == "overwrite" {
 		// Update existing profile
 		if err := i.configManager.UpdateProfile(finalName, profileData.Content); err != nil {
-			return fmt.Errorf("failed to update profile: %w", err)
+			return finalName, StatusError, fmt.Errorf("failed to update profile: %w", err)
 		}
 	} else {
 		// Create new profile
 		if err := i.configManager.CreateProfile(finalName); err != nil {
-			return fmt.Errorf("failed to create profile: %w", err)
+			return finalName, StatusError, fmt.Errorf("failed to create profile: %w", err)
 		}
 
 		// Update with imported content
 		if err := i.configManager.UpdateProfile(finalName, profileData.Content); err != nil {
 			// Clean up on failure
 			i.configManager.DeleteProfile(finalName)
-			return fmt.Errorf("failed to update new profile: %w", err)
+			return finalName, StatusError, fmt.Errorf("failed to update new profile: %w", err)
 		}
 	}
 
+	i.configManager.SetProfileOrigin(finalName, config.OriginImport(inputPath))
+
 	result.ProfilesImported = append(result.ProfilesImported, finalName)
+	return finalName, status, nil
+}
+
+// importTemplate imports a single template; conflicts are handled like profiles,
+// except that an incoming default template is always renamed (or skipped when
+// identical) so the local default is never replaced
+func (i *ImporterImpl) importTemplate(templateData export.TemplateData, options ImportOptions, result *ImportResult) error {
+	if templateData.Content == nil {
+		return fmt.Errorf("template content cannot be nil")
+	}
+
+	name := templateData.Name
+	exists := i.configManager.TemplateExists(name)
+	if exists {
+		mode := options.ConflictMode
+		if name == "default" {
+			if local, err := i.configManager.GetTemplateContent(name); err == nil && len(config.DiffContent(local, templateData.Content)) == 0 {
+				result.Conflicts = append(result.Conflicts, "template default (identical to local, skipped)")
+				return nil
+			}
+			mode = "both"
+		}
+
+		switch mode {
+		case "overwrite":
+			if options.DryRun {
+				result.Conflicts = append(result.Conflicts, fmt.Sprintf("template %s (would be overwritten)", name))
+			} else {
+				result.Conflicts = append(result.Conflicts, fmt.Sprintf("template %s (overwritten)", name))
+			}
+		case "both":
+			alternativeName := i.generateAlternativeTemplateName(name)
+			if options.DryRun {
+				result.Conflicts = append(result.Conflicts, fmt.Sprintf("template %s -> %s (would be renamed)", name, alternativeName))
+			} else {
+				result.Conflicts = append(result.Conflicts, fmt.Sprintf("template %s -> %s (renamed)", name, alternativeName))
+			}
+			name = alternativeName
+			exists = false
+		default:
+			if options.DryRun {
+				result.Conflicts = append(result.Conflicts, fmt.Sprintf("template %s (would be skipped)", name))
+			} else {
+				result.Conflicts = append(result.Conflicts, fmt.Sprintf("template %s (skipped)", name))
+			}
+			return nil
+		}
+	}
+	if options.DryRun {
+		result.TemplatesImported = append(result.TemplatesImported, name+" (dry run)")
+		return nil
+	}
+
+	if !exists {
+		if err := i.configManager.CreateTemplate(name); err != nil {
+			return err
+		}
+	}
+	if err := i.configManager.UpdateTemplate(name, templateData.Content); err != nil {
+		if !exists {
+			i.configManager.DeleteTemplate(name)
+		}
+		return err
+	}
+
+	result.TemplatesImported = append(result.TemplatesImported, name)
 	return nil
 }
 
@@ -227,6 +571,16 @@ func (i *ImporterImpl) validateProfileContent(content map[string]interface{}) er
 
 // generateAlternativeName generates an alternative name for conflicting profiles
 func (i *ImporterImpl) generateAlternativeName(originalName string) string {
+	return alternativeName(originalName, i.configManager.ProfileExists)
+}
+
+// generateAlternativeTemplateName generates an alternative name for conflicting templates
+func (i *ImporterImpl) generateAlternativeTemplateName(originalName string) string {
+	return alternativeName(originalName, i.configManager.TemplateExists)
+}
+
+// alternativeName returns the first "<base>-<n>" name for which exists reports false
+func alternativeName(originalName string, exists func(name string) bool) string {
 	counter := 1
 	baseName := originalName
 
@@ -239,7 +593,7 @@ func (i *ImporterImpl) generateAlternativeName(originalName string) string {
 
 	for {
 		candidateName := fmt.Sprintf("%s-%d", baseName, counter)
-		if !i.configManager.ProfileExists(candidateName) {
+		if !exists(candidateName) {
 			return candidateName
 		}
 		counter++
@@ -0,0 +1,410 @@
+package importer
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/config/configtest"
+	"github.com/HoBeedzc/cc-switch/internal/export"
+)
+
+const testPassword = "correct horse"
+
+// newTestManager creates a configuration manager in a fresh temporary home
+func newTestManager(t *testing.T) *config.ConfigManager {
+	t.Helper()
+	return configtest.NewManager(t, config.Options{})
+}
+
+// exportFrom builds a home with the given profiles, exports them to a file named
+// fileName outside that home and returns the file's path
+func exportFrom(t *testing.T, fileName string, profiles ...string) string {
+	t.Helper()
+	cm := newTestManager(t)
+	for _, name := range profiles {
+		if err := cm.CreateProfile(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), fileName)
+	if err := export.NewExporter(cm).ExportProfiles(profiles, testPassword, path); err != nil {
+		t.Fatalf("ExportProfiles: %v", err)
+	}
+	return path
+}
+
+func TestImportRecordsOrigin(t *testing.T) {
+	path := exportFrom(t, "team.ccx", "work")
+
+	cm := newTestManager(t)
+	result, err := NewImporter(cm).Import(path, testPassword, ImportOptions{ConflictMode: "skip"})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(result.ProfilesImported) != 1 {
+		t.Fatalf("imported %v, want [work]", result.ProfilesImported)
+	}
+	if got, want := cm.ProfileOrigin("work"), config.OriginImport(path); got != want {
+		t.Errorf("origin = %q, want %q", got, want)
+	}
+	if got := cm.ProfileOrigin("work"); got != "import:team.ccx" {
+		t.Errorf("origin = %q, want only the file name recorded", got)
+	}
+}
+
+func TestImportRenamedProfileRecordsOrigin(t *testing.T) {
+	path := exportFrom(t, "team.ccx", "work")
+
+	cm := newTestManager(t)
+	if err := cm.CreateProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	result, err := NewImporter(cm).Import(path, testPassword, ImportOptions{ConflictMode: "both"})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(result.ProfilesImported) != 1 {
+		t.Fatalf("imported %v, want one renamed profile", result.ProfilesImported)
+	}
+	renamed := result.ProfilesImported[0]
+	if got := cm.ProfileOrigin(renamed); got != config.OriginImport(path) {
+		t.Errorf("origin of %s = %q, want %q", renamed, got, config.OriginImport(path))
+	}
+	// The local profile keeps its own origin
+	if got := cm.ProfileOrigin("work"); got != config.OriginTemplate("default") {
+		t.Errorf("local origin = %q, want it unchanged", got)
+	}
+}
+
+// addTemplate creates a template whose content records model, so copies can be told apart
+func addTemplate(t *testing.T, cm *config.ConfigManager, name, model string) {
+	t.Helper()
+	if !cm.TemplateExists(name) {
+		if err := cm.CreateTemplate(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cm.UpdateTemplate(name, map[string]interface{}{"model": model}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// templateModel returns the model recorded by addTemplate
+func templateModel(t *testing.T, cm *config.ConfigManager, name string) string {
+	t.Helper()
+	content, err := cm.GetTemplateContent(name)
+	if err != nil {
+		t.Fatalf("GetTemplateContent(%s): %v", name, err)
+	}
+	model, _ := content["model"].(string)
+	return model
+}
+
+// exportTemplatesFrom builds a home with the team and proxy templates and a changed
+// default template, then writes a template-only archive configured by configure
+func exportTemplatesFrom(t *testing.T, configure func(e *export.ExporterImpl)) (string, []string) {
+	t.Helper()
+	cm := newTestManager(t)
+	addTemplate(t, cm, "team", "remote-team")
+	addTemplate(t, cm, "proxy", "remote-proxy")
+	addTemplate(t, cm, "default", "remote-default")
+
+	exporter := export.NewExporter(cm)
+	configure(exporter)
+	path := filepath.Join(t.TempDir(), "templates.ccx")
+	names, err := exporter.ExportTemplates(testPassword, path)
+	if err != nil {
+		t.Fatalf("ExportTemplates: %v", err)
+	}
+	sort.Strings(names)
+	return path, names
+}
+
+func TestExportTemplatesSelection(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(e *export.ExporterImpl)
+		want      []string
+	}{
+		{"named", func(e *export.ExporterImpl) { e.SetTemplates([]string{"team"}) }, []string{"team"}},
+		{"all", func(e *export.ExporterImpl) { e.SetIncludeTemplates(true) }, []string{"proxy", "team"}},
+		{"all with default", func(e *export.ExporterImpl) {
+			e.SetIncludeTemplates(true)
+			e.SetIncludeDefaultTemplate(true)
+		}, []string{"default", "proxy", "team"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, names := exportTemplatesFrom(t, tt.configure)
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("exported %v, want %v", names, tt.want)
+			}
+
+			metadata, err := NewImporter(newTestManager(t)).ValidateFile(path)
+			if err != nil {
+				t.Fatalf("ValidateFile: %v", err)
+			}
+			if metadata.ExportType != export.ExportTypeTemplates {
+				t.Errorf("export type = %q, want %q", metadata.ExportType, export.ExportTypeTemplates)
+			}
+		})
+	}
+}
+
+func TestImportTemplateOnlyArchive(t *testing.T) {
+	path, _ := exportTemplatesFrom(t, func(e *export.ExporterImpl) { e.SetIncludeTemplates(true) })
+
+	cm := newTestManager(t)
+	before, err := cm.ListProfiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := NewImporter(cm).Import(path, testPassword, ImportOptions{ConflictMode: "skip"})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("errors: %v", result.Errors)
+	}
+
+	imported := append([]string(nil), result.TemplatesImported...)
+	sort.Strings(imported)
+	if !reflect.DeepEqual(imported, []string{"proxy", "team"}) {
+		t.Errorf("templates imported = %v, want [proxy team]", imported)
+	}
+	if len(result.ProfilesImported) != 0 {
+		t.Errorf("profiles imported = %v, want none", result.ProfilesImported)
+	}
+	after, err := cm.ListProfiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(before) {
+		t.Errorf("profiles changed from %d to %d", len(before), len(after))
+	}
+	if got := templateModel(t, cm, "team"); got != "remote-team" {
+		t.Errorf("team model = %q, want remote-team", got)
+	}
+}
+
+func TestImportTemplateConflicts(t *testing.T) {
+	tests := []struct {
+		mode      string
+		wantLocal string // model of the local team template afterwards
+		wantCopy  string // template holding the imported copy, if renamed
+	}{
+		{mode: "skip", wantLocal: "local-team"},
+		{mode: "overwrite", wantLocal: "remote-team"},
+		{mode: "both", wantLocal: "local-team", wantCopy: "team-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			path, _ := exportTemplatesFrom(t, func(e *export.ExporterImpl) { e.SetTemplates([]string{"team"}) })
+
+			cm := newTestManager(t)
+			addTemplate(t, cm, "team", "local-team")
+			importer := NewImporter(cm)
+
+			conflicts, err := importer.CheckConflicts(path, testPassword)
+			if err != nil {
+				t.Fatalf("CheckConflicts: %v", err)
+			}
+			if len(conflicts) != 1 || !conflicts[0].Template || conflicts[0].SuggestedName != "team-1" {
+				t.Errorf("conflicts = %+v, want one template conflict suggesting team-1", conflicts)
+			}
+
+			if _, err := importer.Import(path, testPassword, ImportOptions{ConflictMode: tt.mode}); err != nil {
+				t.Fatalf("Import: %v", err)
+			}
+			if got := templateModel(t, cm, "team"); got != tt.wantLocal {
+				t.Errorf("team model = %q, want %q", got, tt.wantLocal)
+			}
+			if tt.wantCopy != "" {
+				if got := templateModel(t, cm, tt.wantCopy); got != "remote-team" {
+					t.Errorf("%s model = %q, want remote-team", tt.wantCopy, got)
+				}
+			} else if cm.TemplateExists("team-1") {
+				t.Error("team-1 was created")
+			}
+		})
+	}
+}
+
+func TestImportDefaultTemplateIsNeverReplaced(t *testing.T) {
+	path, _ := exportTemplatesFrom(t, func(e *export.ExporterImpl) { e.SetTemplates([]string{"default"}) })
+
+	cm := newTestManager(t)
+	local, err := cm.GetTemplateContent("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := NewImporter(cm).Import(path, testPassword, ImportOptions{ConflictMode: "overwrite"})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if !reflect.DeepEqual(result.TemplatesImported, []string{"default-1"}) {
+		t.Errorf("templates imported = %v, want [default-1]", result.TemplatesImported)
+	}
+	after, err := cm.GetTemplateContent("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(after, local) {
+		t.Error("local default template was replaced")
+	}
+	if got := templateModel(t, cm, "default-1"); got != "remote-default" {
+		t.Errorf("default-1 model = %q, want remote-default", got)
+	}
+}
+
+func TestImportMixedArchive(t *testing.T) {
+	source := newTestManager(t)
+	addTemplate(t, source, "team", "remote-team")
+	if err := source.CreateProfileFromTemplate("work", "team"); err != nil {
+		t.Fatal(err)
+	}
+	exporter := export.NewExporter(source)
+	exporter.SetIncludeTemplates(true)
+	path := filepath.Join(t.TempDir(), "mixed.ccx")
+	if err := exporter.ExportProfiles([]string{"work"}, testPassword, path); err != nil {
+		t.Fatalf("ExportProfiles: %v", err)
+	}
+
+	cm := newTestManager(t)
+	importer := NewImporter(cm)
+	metadata, err := importer.ValidateFile(path)
+	if err != nil {
+		t.Fatalf("ValidateFile: %v", err)
+	}
+	if metadata.ExportType != export.ExportTypeSingle {
+		t.Errorf("export type = %q, want %q", metadata.ExportType, export.ExportTypeSingle)
+	}
+
+	result, err := importer.Import(path, testPassword, ImportOptions{ConflictMode: "skip"})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if !reflect.DeepEqual(result.ProfilesImported, []string{"work"}) {
+		t.Errorf("profiles imported = %v, want [work]", result.ProfilesImported)
+	}
+	if !reflect.DeepEqual(result.TemplatesImported, []string{"team"}) {
+		t.Errorf("templates imported = %v, want [team]", result.TemplatesImported)
+	}
+	content, _, err := cm.GetProfileContent("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content["model"] != "remote-team" {
+		t.Errorf("work model = %v, want remote-team", content["model"])
+	}
+	if got := templateModel(t, cm, "team"); got != "remote-team" {
+		t.Errorf("team model = %q, want remote-team", got)
+	}
+}
+
+func TestListArchive(t *testing.T) {
+	source := newTestManager(t)
+	for name, env := range map[string]map[string]interface{}{
+		"work":     {"ANTHROPIC_BASE_URL": "https://work.example.com", "ANTHROPIC_AUTH_TOKEN": "sk-work-secret"},
+		"personal": {"ANTHROPIC_AUTH_TOKEN": "sk-personal-secret"},
+	} {
+		if err := source.CreateProfileWithContent(name, map[string]interface{}{"env": env}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := source.UseProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "team.ccx")
+	if err := export.NewExporter(source).ExportProfiles([]string{"personal", "work"}, testPassword, path); err != nil {
+		t.Fatalf("ExportProfiles: %v", err)
+	}
+
+	cm := newTestManager(t)
+	if err := cm.CreateProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	importer := NewImporter(cm)
+
+	listing, err := importer.List(path, testPassword, nil)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if listing.Metadata == nil {
+		t.Error("listing has no metadata")
+	}
+	byName := make(map[string]ListedProfile)
+	for _, profile := range listing.Profiles {
+		byName[profile.Name] = profile
+	}
+	if len(byName) != 2 {
+		t.Fatalf("listed %+v, want personal and work", listing.Profiles)
+	}
+	work := byName["work"]
+	if !work.IsCurrent || !work.Conflict {
+		t.Errorf("work = %+v, want current in the archive and conflicting locally", work)
+	}
+	if want := []string{"ANTHROPIC_AUTH_TOKEN", "ANTHROPIC_BASE_URL"}; !reflect.DeepEqual(work.EnvKeys, want) {
+		t.Errorf("work env keys = %v, want %v", work.EnvKeys, want)
+	}
+	if personal := byName["personal"]; personal.IsCurrent || personal.Conflict {
+		t.Errorf("personal = %+v, want neither current nor conflicting", personal)
+	}
+
+	// Only key names are listed, never values
+	data, err := json.Marshal(listing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") || strings.Contains(string(data), "example.com") {
+		t.Errorf("listing leaks values: %s", data)
+	}
+
+	// Listing imports nothing
+	if cm.ProfileExists("personal") {
+		t.Error("List imported a profile")
+	}
+
+	filtered, err := importer.List(path, testPassword, []string{"pers*"})
+	if err != nil {
+		t.Fatalf("List with a filter: %v", err)
+	}
+	if len(filtered.Profiles) != 1 || filtered.Profiles[0].Name != "personal" {
+		t.Errorf("filtered listing = %+v, want only personal", filtered.Profiles)
+	}
+
+	if _, err := importer.List(path, "wrong-password", nil); err == nil {
+		t.Error("List accepted a wrong password")
+	}
+}
+
+func TestListTemplateArchive(t *testing.T) {
+	path, _ := exportTemplatesFrom(t, func(e *export.ExporterImpl) { e.SetIncludeTemplates(true) })
+
+	cm := newTestManager(t)
+	addTemplate(t, cm, "team", "local-team")
+	listing, err := NewImporter(cm).List(path, testPassword, nil)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(listing.Profiles) != 0 {
+		t.Errorf("profiles = %+v, want none", listing.Profiles)
+	}
+	conflicts := make(map[string]bool)
+	for _, template := range listing.Templates {
+		conflicts[template.Name] = template.Conflict
+	}
+	if conflict, ok := conflicts["team"]; !ok || !conflict {
+		t.Errorf("templates = %+v, want team listed as conflicting", listing.Templates)
+	}
+	if conflict, ok := conflicts["proxy"]; !ok || conflict {
+		t.Errorf("templates = %+v, want proxy listed without a conflict", listing.Templates)
+	}
+}
@@ -0,0 +1,39 @@
+// Package testutil holds helpers shared by the test suites.
+package testutil
+
+import (
+	"testing"
+
+	"github.com/HoBeedzc/cc-switch/internal/common"
+)
+
+// ClearedEnv lists the variables UseHome clears. They move cc-switch's directories or
+// supply secrets, so leaving them set would let a test see the developer's own setup.
+var ClearedEnv = []string{
+	"XDG_DATA_HOME",
+	"XDG_CACHE_HOME",
+	"XDG_CONFIG_HOME",
+	common.UseXDGEnv,
+	common.ProfilesDirNameEnv,
+	common.TemplatesDirNameEnv,
+	"CC_SWITCH_SYSTEM_PROFILES_DIR", // config.SystemProfilesDirEnv
+	"CC_SWITCH_SECRETS_PASSPHRASE",  // config.SecretsPassphraseEnv
+}
+
+// SetupHome points cc-switch at an empty temporary home directory and returns it
+func SetupHome(t testing.TB) string {
+	t.Helper()
+	home := t.TempDir()
+	UseHome(t, home)
+	return home
+}
+
+// UseHome points HOME at home and clears ClearedEnv for the rest of the test
+func UseHome(t testing.TB, home string) {
+	t.Helper()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	for _, name := range ClearedEnv {
+		t.Setenv(name, "")
+	}
+}
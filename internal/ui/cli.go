@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"strings"
 
-	"cc-switch/internal/config"
-	"cc-switch/internal/handler"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
 
 	"github.com/fatih/color"
 )
@@ -95,6 +95,9 @@ func (ui *cliUI) GetTemplateFieldInput(field config.TemplateField) (string, erro
 	if field.Required {
 		prompt += " (required)"
 	}
+	if field.Current != "" {
+		prompt += fmt.Sprintf(" [%s]", fieldDisplayValue(field))
+	}
 
 	fmt.Printf("%s: ", prompt)
 
@@ -103,6 +106,11 @@ func (ui *cliUI) GetTemplateFieldInput(field config.TemplateField) (string, erro
 
 	input = strings.TrimSpace(input)
 
+	// Pressing Enter keeps the current value
+	if input == "" && field.Current != "" {
+		return field.Current, nil
+	}
+
 	// For required fields, validate non-empty input
 	if field.Required && input == "" {
 		return "", fmt.Errorf("field '%s' is required and cannot be empty", field.Name)
@@ -122,12 +130,18 @@ func (ui *cliUI) ShowTemplateFieldSummary(fields []config.TemplateField) {
 		return
 	}
 
-	fmt.Printf("Template has %d empty field(s) that need to be filled:\n", len(fields))
+	if hasPrefilledFields(fields) {
+		fmt.Printf("Template has %d field(s) to review (press Enter to keep the value in brackets):\n", len(fields))
+	} else {
+		fmt.Printf("Template has %d empty field(s) that need to be filled:\n", len(fields))
+	}
 	for _, field := range fields {
-		if field.Required {
-			color.Yellow("  • %s (required)", field.Name)
+		if field.Current != "" {
+			color.White("  %s %s = %s", Sym().Bullet, field.Name, fieldDisplayValue(field))
+		} else if field.Required {
+			color.Yellow("  %s %s (required)", Sym().Bullet, field.Name)
 		} else {
-			color.White("  • %s (optional)", field.Name)
+			color.White("  %s %s (optional)", Sym().Bullet, field.Name)
 		}
 	}
 	fmt.Println()
@@ -148,52 +162,52 @@ func (ui *cliUI) GetInitInput(fieldName, description string) (string, error) {
 
 // ShowInitWelcome displays welcome message for initialization
 func (ui *cliUI) ShowInitWelcome() {
-	color.Cyan("🚀 Welcome to Claude Code configuration setup!")
+	color.Cyan(Text("init.welcome"))
 	fmt.Println()
-	color.Yellow("This will create your initial Claude Code configuration.")
-	fmt.Println("You can leave fields empty if you don't have the information yet.")
+	color.Yellow(Text("init.intro"))
+	fmt.Println(Text("init.empty_hint"))
 	fmt.Println()
 }
 
 // ShowInitSuccess displays success message after initialization
 func (ui *cliUI) ShowInitSuccess() {
 	fmt.Println()
-	color.Green("✓ Configuration created successfully")
-	color.Green("✓ Default profile 'default' created")
-	color.Green("✓ cc-switch directory structure initialized")
+	color.Green(Text("init.created"))
+	color.Green(Text("init.default_created"))
+	color.Green(Text("init.dirs_created"))
 	fmt.Println()
-	color.Cyan("🎉 Your Claude Code configuration is ready!")
-	color.White("You can now use 'cc-switch' commands to manage your configurations.")
+	color.Cyan(Text("init.ready"))
+	color.White(Text("init.next"))
 }
 
 // ShowAlreadyInitialized displays message when configuration already exists
 func (ui *cliUI) ShowAlreadyInitialized() {
-	color.Yellow("⚠ Claude Code configuration already exists")
+	color.Yellow(Text("init.already"))
 	fmt.Println()
-	fmt.Println("If you want to reconfigure, you can:")
-	color.Cyan("  • Use 'cc-switch edit default' to modify existing configuration")
-	color.Cyan("  • Use 'cc-switch new <name>' to create additional configurations")
-	color.Cyan("  • Manually backup and remove settings.json to reinitialize")
+	fmt.Println(Text("init.reconfigure"))
+	color.Cyan(Text("init.reconfigure_edit"))
+	color.Cyan(Text("init.reconfigure_new"))
+	color.Cyan(Text("init.reconfigure_manual"))
 }
 
 // ShowError displays error messages
 func (ui *cliUI) ShowError(err error) {
-	color.Red("Error: %v", err)
+	color.Red(Text("common.error"), err)
 }
 
 // ShowSuccess displays success messages
 func (ui *cliUI) ShowSuccess(message string, args ...interface{}) {
-	color.Green("✓ "+message, args...)
+	color.Green(Sym().Check+" "+message, args...)
 }
 
 // ShowWarning displays warning messages
 func (ui *cliUI) ShowWarning(message string, args ...interface{}) {
-	color.Yellow("⚠ "+message, args...)
+	color.Yellow(Sym().Warning+" "+message, args...)
 }
 
 // ShowInfo displays informational messages
 func (ui *cliUI) ShowInfo(message string, args ...interface{}) {
-	color.Cyan("ℹ "+message, args...)
+	color.Cyan(Sym().Info+" "+message, args...)
 }
 
 // DisplayConfiguration displays configuration content
@@ -208,11 +222,21 @@ func (ui *cliUI) DisplayConfiguration(view *handler.ConfigView, raw bool) error
 	} else {
 		// Formatted output
 		color.Blue("Configuration: %s", view.Name)
+		if view.DisplayName != "" {
+			fmt.Printf("Display Name: %s\n", view.DisplayName)
+		}
+		if view.Project != "" {
+			fmt.Printf("Project: %s\n", view.Project)
+		}
+		if view.Network != "" {
+			fmt.Printf("Network: %s\n", view.Network)
+		}
 		if view.IsCurrent {
 			color.Green("Status: Current")
 		} else {
 			color.White("Status: Available")
 		}
+		fmt.Printf("Origin: %s\n", strings.Join(view.OriginChain, " "+Sym().LeftArrow+" "))
 		fmt.Printf("Path: %s\n\n", view.Path)
 
 		color.Yellow("Content:")
@@ -255,3 +279,24 @@ func (ui *cliUI) DisplayTemplate(view *handler.TemplateView, raw bool) error {
 
 	return nil
 }
+
+// hasPrefilledFields reports whether any field already has a value in the template
+func hasPrefilledFields(fields []config.TemplateField) bool {
+	for _, field := range fields {
+		if field.Current != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldDisplayValue returns a field's current value for display, masking secrets
+func fieldDisplayValue(field config.TemplateField) string {
+	if config.IsSecretKey(field.Name) {
+		if len(field.Current) <= 8 {
+			return strings.Repeat("*", len(field.Current))
+		}
+		return strings.Repeat("*", 8) + field.Current[len(field.Current)-4:]
+	}
+	return field.Current
+}
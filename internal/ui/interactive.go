@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"strings"
 
-	"cc-switch/internal/config"
-	"cc-switch/internal/handler"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
 
 	"github.com/fatih/color"
 	"github.com/manifoldco/promptui"
@@ -41,25 +41,44 @@ func (ui *interactiveUI) SelectConfiguration(configs []config.Profile, action st
 		return nil, fmt.Errorf("no configurations available")
 	}
 
+	// Arrow-key menus need a terminal; fall back to a numbered menu when stdin is piped
+	if !stdinIsTerminal() {
+		items := make([]MenuItem, len(configs))
+		for i := range configs {
+			items[i] = profileMenuItem(&configs[i])
+		}
+		i, err := SelectFromMenu("Available configurations:", items, fmt.Sprintf("Select configuration to %s", action))
+		if err != nil {
+			return nil, err
+		}
+		return &configs[i], nil
+	}
+
 	// Custom templates for better visual experience
 	templates := &promptui.SelectTemplates{
 		Label:    "{{ . }}:",
-		Active:   "▶ {{ .Name | cyan }}{{ if .IsCurrent }} {{ \"(current)\" | green }}{{ end }}",
-		Inactive: "  {{ .Name }}{{ if .IsCurrent }} {{ \"(current)\" | faint }}{{ end }}",
-		Selected: "✓ {{ .Name | green }}{{ if .IsCurrent }} {{ \"(current)\" | faint }}{{ end }}",
+		Active:   Sym().Pointer + " {{ .Label | cyan }}{{ if .IsCurrent }} {{ \"(current)\" | green }}{{ end }}",
+		Inactive: "  {{ .Label }}{{ if .IsCurrent }} {{ \"(current)\" | faint }}{{ end }}",
+		Selected: Sym().Check + " {{ .Label | green }}{{ if .IsCurrent }} {{ \"(current)\" | faint }}{{ end }}",
 		Details: `
 --------- Configuration Details ----------
-{{ "Name:" | faint }}	{{ .Name }}
+{{ "Name:" | faint }}	{{ .Name }}{{ if .DisplayName }}
+{{ "Display Name:" | faint }}	{{ .DisplayName }}{{ end }}
 {{ "Status:" | faint }}	{{ if .IsCurrent }}{{ "Current" | green }}{{ else }}{{ "Available" | yellow }}{{ end }}
-{{ "Path:" | faint }}	{{ .Path }}`,
+{{ "Path:" | faint }}	{{ .Path }}{{ if .Project }}
+{{ "Project:" | faint }}	{{ .Project }}{{ end }}`,
 	}
 
+	// "/" toggles search; matches are drawn from the whole list, not just the visible window
 	prompt := promptui.Select{
 		Label:        fmt.Sprintf("Select configuration to %s", action),
 		Items:        configs,
 		Templates:    templates,
 		Size:         10,
 		HideSelected: false,
+		Searcher: func(input string, index int) bool {
+			return profileMatches(input, &configs[index])
+		},
 	}
 
 	i, _, err := prompt.Run()
@@ -104,7 +123,7 @@ func (ui *interactiveUI) SelectConfigurationWithEmptyMode(configs []config.Profi
 	// Add regular configurations
 	for i := range configs {
 		items = append(items, SelectItem{
-			Name:        configs[i].Name,
+			Name:        configs[i].Label(),
 			Type:        "profile",
 			IsCurrent:   configs[i].IsCurrent,
 			IsSpecial:   false,
@@ -113,18 +132,39 @@ func (ui *interactiveUI) SelectConfigurationWithEmptyMode(configs []config.Profi
 		})
 	}
 
+	if !stdinIsTerminal() {
+		menu := make([]MenuItem, len(items))
+		for i, item := range items {
+			if item.Profile != nil {
+				menu[i] = profileMenuItem(item.Profile)
+			} else {
+				menu[i] = MenuItem{Label: item.Name, Description: "- " + item.Description}
+			}
+		}
+		i, err := SelectFromMenu("Available configurations:", menu, fmt.Sprintf("Select configuration to %s", action))
+		if err != nil {
+			return nil, err
+		}
+		return &SpecialSelection{
+			Type:    items[i].Type,
+			Profile: items[i].Profile,
+			Action:  items[i].Type,
+		}, nil
+	}
+
 	// Custom templates
 	templates := &promptui.SelectTemplates{
 		Label:    "{{ . }}:",
-		Active:   "▶ {{ if .IsSpecial }}{{ .Name | yellow }}{{ else }}{{ .Name | cyan }}{{ end }}{{ if .IsCurrent }} {{ \"(current)\" | green }}{{ end }}",
+		Active:   Sym().Pointer + " {{ if .IsSpecial }}{{ .Name | yellow }}{{ else }}{{ .Name | cyan }}{{ end }}{{ if .IsCurrent }} {{ \"(current)\" | green }}{{ end }}",
 		Inactive: "  {{ if .IsSpecial }}{{ .Name | faint }}{{ else }}{{ .Name }}{{ end }}{{ if .IsCurrent }} {{ \"(current)\" | faint }}{{ end }}",
-		Selected: "✓ {{ if .IsSpecial }}{{ .Name | yellow }}{{ else }}{{ .Name | green }}{{ end }}{{ if .IsCurrent }} {{ \"(current)\" | faint }}{{ end }}",
+		Selected: Sym().Check + " {{ if .IsSpecial }}{{ .Name | yellow }}{{ else }}{{ .Name | green }}{{ end }}{{ if .IsCurrent }} {{ \"(current)\" | faint }}{{ end }}",
 		Details: `
 --------- Selection Details ----------
 {{ "Option:" | faint }}	{{ .Name }}
 {{ "Type:" | faint }}	{{ if .IsSpecial }}{{ "Special Action" | yellow }}{{ else }}{{ "Configuration" | green }}{{ end }}
 {{ "Description:" | faint }}	{{ .Description }}{{ if .Profile }}
-{{ "Path:" | faint }}	{{ .Profile.Path }}{{ end }}`,
+{{ "Path:" | faint }}	{{ .Profile.Path }}{{ if .Profile.Project }}
+{{ "Project:" | faint }}	{{ .Profile.Project }}{{ end }}{{ end }}`,
 	}
 
 	prompt := promptui.Select{
@@ -133,6 +173,13 @@ func (ui *interactiveUI) SelectConfigurationWithEmptyMode(configs []config.Profi
 		Templates:    templates,
 		Size:         10,
 		HideSelected: false,
+		Searcher: func(input string, index int) bool {
+			if items[index].Profile != nil {
+				return profileMatches(input, items[index].Profile)
+			}
+			// Special entries stay reachable by typing their name, e.g. "empty" or "restore"
+			return fuzzyMatch(input, items[index].Name)
+		},
 	}
 
 	i, _, err := prompt.Run()
@@ -148,6 +195,15 @@ func (ui *interactiveUI) SelectConfigurationWithEmptyMode(configs []config.Profi
 	}, nil
 }
 
+// profileMenuItem builds the numbered-menu entry for a configuration
+func profileMenuItem(profile *config.Profile) MenuItem {
+	item := MenuItem{Label: profile.Label()}
+	if profile.IsCurrent {
+		item.Description = "(current)"
+	}
+	return item
+}
+
 // SelectWithPreview provides configuration selection with preview
 func (ui *interactiveUI) SelectWithPreview(configs []config.Profile, action string) (*config.Profile, error) {
 	// For now, use the same implementation as SelectConfiguration
@@ -171,9 +227,9 @@ func (ui *interactiveUI) ShowActionMenu(selectedConfig *config.Profile) (string,
 
 	templates := &promptui.SelectTemplates{
 		Label:    "{{ . }}:",
-		Active:   "▶ {{ . | cyan }}",
+		Active:   Sym().Pointer + " {{ . | cyan }}",
 		Inactive: "  {{ . }}",
-		Selected: "✓ {{ . | green }}",
+		Selected: Sym().Check + " {{ . | green }}",
 	}
 
 	prompt := promptui.Select{
@@ -262,9 +318,22 @@ func (ui *interactiveUI) GetTemplateFieldInput(field config.TemplateField) (stri
 		Label: label,
 	}
 
+	// Pre-fill the current value; secrets are masked in the label and kept when the input is empty
+	if field.Current != "" {
+		if config.IsSecretKey(field.Name) {
+			promptUI.Label = fmt.Sprintf("%s [%s]", label, fieldDisplayValue(field))
+		} else {
+			promptUI.Default = field.Current
+			promptUI.AllowEdit = true
+		}
+	}
+
 	// Add validation for required fields and field-specific validation
 	promptUI.Validate = func(input string) error {
 		input = strings.TrimSpace(input)
+		if input == "" && field.Current != "" {
+			return nil
+		}
 
 		// Check required fields
 		if field.Required && input == "" {
@@ -280,7 +349,11 @@ func (ui *interactiveUI) GetTemplateFieldInput(field config.TemplateField) (stri
 		return "", fmt.Errorf("failed to get input for field '%s': %w", field.Name, err)
 	}
 
-	return strings.TrimSpace(result), nil
+	result = strings.TrimSpace(result)
+	if result == "" && field.Current != "" {
+		return field.Current, nil
+	}
+	return result, nil
 }
 
 // validateFieldValueUI performs client-side validation
@@ -289,15 +362,16 @@ func validateFieldValueUI(fieldName, value string) error {
 		return nil // Empty values handled by required field check
 	}
 
-	switch fieldName {
-	case "ANTHROPIC_AUTH_TOKEN", "OPENAI_API_KEY", "API_KEY", "TOKEN":
+	rule, _ := config.MatchFieldRule(fieldName)
+	switch {
+	case rule.Secret && rule.Required:
 		if len(value) < 10 {
 			return fmt.Errorf("API token appears to be too short (minimum 10 characters)")
 		}
 		if strings.Contains(value, " ") {
 			return fmt.Errorf("API token should not contain spaces")
 		}
-	case "ANTHROPIC_BASE_URL", "BASE_URL", "ENDPOINT":
+	case rule.URL:
 		if !strings.HasPrefix(value, "http://") && !strings.HasPrefix(value, "https://") {
 			return fmt.Errorf("URL must start with http:// or https://")
 		}
@@ -335,10 +409,16 @@ func (ui *interactiveUI) ShowTemplateFieldSummary(fields []config.TemplateField)
 		return
 	}
 
-	fmt.Printf("Template has %d empty field(s):\n\n", len(fields))
+	if hasPrefilledFields(fields) {
+		fmt.Printf("Template has %d field(s) to review:\n\n", len(fields))
+	} else {
+		fmt.Printf("Template has %d empty field(s):\n\n", len(fields))
+	}
 
 	for _, field := range fields {
-		if field.Required {
+		if field.Current != "" {
+			color.White("  %s = %s: %s", field.Name, fieldDisplayValue(field), field.Description)
+		} else if field.Required {
 			color.Yellow("  %s (required): %s", field.Name, field.Description)
 		} else {
 			color.White("  %s (optional): %s", field.Name, field.Description)
@@ -379,22 +459,22 @@ func (ui *interactiveUI) GetFieldInput(fieldName string, currentValue interface{
 
 // ShowError displays error messages
 func (ui *interactiveUI) ShowError(err error) {
-	color.Red("Error: %v", err)
+	color.Red(Text("common.error"), err)
 }
 
 // ShowSuccess displays success messages
 func (ui *interactiveUI) ShowSuccess(message string, args ...interface{}) {
-	color.Green("✓ "+message, args...)
+	color.Green(Sym().Check+" "+message, args...)
 }
 
 // ShowWarning displays warning messages
 func (ui *interactiveUI) ShowWarning(message string, args ...interface{}) {
-	color.Yellow("⚠ "+message, args...)
+	color.Yellow(Sym().Warning+" "+message, args...)
 }
 
 // ShowInfo displays informational messages
 func (ui *interactiveUI) ShowInfo(message string, args ...interface{}) {
-	color.Cyan("ℹ "+message, args...)
+	color.Cyan(Sym().Info+" "+message, args...)
 }
 
 // DisplayConfiguration displays configuration content
@@ -409,11 +489,21 @@ func (ui *interactiveUI) DisplayConfiguration(view *handler.ConfigView, raw bool
 	} else {
 		// Formatted output
 		color.Blue("Configuration: %s", view.Name)
+		if view.DisplayName != "" {
+			fmt.Printf("Display Name: %s\n", view.DisplayName)
+		}
+		if view.Project != "" {
+			fmt.Printf("Project: %s\n", view.Project)
+		}
+		if view.Network != "" {
+			fmt.Printf("Network: %s\n", view.Network)
+		}
 		if view.IsCurrent {
 			color.Green("Status: Current")
 		} else {
 			fmt.Println("Status: Available")
 		}
+		fmt.Printf("Origin: %s\n", strings.Join(view.OriginChain, " "+Sym().LeftArrow+" "))
 		fmt.Printf("Path: %s\n\n", view.Path)
 
 		color.Yellow("Content:")
@@ -476,30 +566,30 @@ func (ui *interactiveUI) GetInitInput(fieldName, description string) (string, er
 
 // ShowInitWelcome displays welcome message for initialization
 func (ui *interactiveUI) ShowInitWelcome() {
-	color.Cyan("🚀 Welcome to Claude Code configuration setup!")
+	color.Cyan(Text("init.welcome"))
 	fmt.Println()
-	color.Yellow("This will create your initial Claude Code configuration.")
-	fmt.Println("You can leave fields empty if you don't have the information yet.")
+	color.Yellow(Text("init.intro"))
+	fmt.Println(Text("init.empty_hint"))
 	fmt.Println()
 }
 
 // ShowInitSuccess displays success message after initialization
 func (ui *interactiveUI) ShowInitSuccess() {
 	fmt.Println()
-	color.Green("✓ Configuration created successfully")
-	color.Green("✓ Default profile 'default' created")
-	color.Green("✓ cc-switch directory structure initialized")
+	color.Green(Text("init.created"))
+	color.Green(Text("init.default_created"))
+	color.Green(Text("init.dirs_created"))
 	fmt.Println()
-	color.Cyan("🎉 Your Claude Code configuration is ready!")
-	color.White("You can now use 'cc-switch' commands to manage your configurations.")
+	color.Cyan(Text("init.ready"))
+	color.White(Text("init.next"))
 }
 
 // ShowAlreadyInitialized displays message when configuration already exists
 func (ui *interactiveUI) ShowAlreadyInitialized() {
-	color.Yellow("⚠ Claude Code configuration already exists")
+	color.Yellow(Text("init.already"))
 	fmt.Println()
-	fmt.Println("If you want to reconfigure, you can:")
-	color.Cyan("  • Use 'cc-switch edit default' to modify existing configuration")
-	color.Cyan("  • Use 'cc-switch new <name>' to create additional configurations")
-	color.Cyan("  • Manually backup and remove settings.json to reinitialize")
+	fmt.Println(Text("init.reconfigure"))
+	color.Cyan(Text("init.reconfigure_edit"))
+	color.Cyan(Text("init.reconfigure_new"))
+	color.Cyan(Text("init.reconfigure_manual"))
 }
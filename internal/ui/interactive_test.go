@@ -0,0 +1,35 @@
+package ui
+
+import "testing"
+
+func TestValidateFieldValueUI(t *testing.T) {
+	tests := []struct {
+		field string
+		value string
+		valid bool
+	}{
+		{"ANTHROPIC_AUTH_TOKEN", "sk-ant-0123456789", true},
+		{"ANTHROPIC_AUTH_TOKEN", "short", false},
+		{"MY_GATEWAY_API_KEY", "short", false},
+		{"MY_GATEWAY_API_KEY", "has a space in it", false},
+		{"MY_GATEWAY_API_KEY", "", true},
+		{"ANTHROPIC_BASE_URL", "https://example.com", true},
+		{"SERVICE_ENDPOINT", "example.com", false},
+		{"PROXY_URL", "http://a b", false},
+		// Passwords are secret but optional, and MONKEY is not a key
+		{"DB_PASSWORD", "pw", true},
+		{"MONKEY", "x", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field+"="+tt.value, func(t *testing.T) {
+			err := validateFieldValueUI(tt.field, tt.value)
+			if tt.valid && err != nil {
+				t.Errorf("validateFieldValueUI(%q, %q) = %v, want valid", tt.field, tt.value, err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("validateFieldValueUI(%q, %q) accepted an invalid value", tt.field, tt.value)
+			}
+		})
+	}
+}
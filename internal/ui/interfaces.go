@@ -1,8 +1,8 @@
 package ui
 
 import (
-	"cc-switch/internal/config"
-	"cc-switch/internal/handler"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
 )
 
 // SpecialSelection represents a selection that could be a profile or a special action
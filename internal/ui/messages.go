@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"os"
+	"strings"
+)
+
+// LangEnv 选择命令行输出语言的环境变量，如 "zh"、"zh_CN.UTF-8"、"en"
+const LangEnv = "CC_SWITCH_LANG"
+
+// 支持的输出语言
+const (
+	LangEnglish = "en"
+	LangChinese = "zh"
+)
+
+// messageCatalogs 各语言的消息目录，键相同、值为可直接作为格式串的文本
+var messageCatalogs = map[string]map[string]string{
+	LangEnglish: englishMessages,
+	LangChinese: chineseMessages,
+}
+
+var englishMessages = map[string]string{
+	"common.error":     "Error: %v",
+	"common.cancelled": "Operation cancelled",
+
+	"select.prompt":  "%s (1-%d, Enter or q to cancel): ",
+	"select.invalid": "Please enter a number between 1 and %d, or press Enter to cancel",
+
+	"init.welcome":            "🚀 Welcome to Claude Code configuration setup!",
+	"init.intro":              "This will create your initial Claude Code configuration.",
+	"init.empty_hint":         "You can leave fields empty if you don't have the information yet.",
+	"init.created":            "✓ Configuration created successfully",
+	"init.default_created":    "✓ Default profile 'default' created",
+	"init.dirs_created":       "✓ cc-switch directory structure initialized",
+	"init.ready":              "🎉 Your Claude Code configuration is ready!",
+	"init.next":               "You can now use 'cc-switch' commands to manage your configurations.",
+	"init.already":            "⚠ Claude Code configuration already exists",
+	"init.reconfigure":        "If you want to reconfigure, you can:",
+	"init.reconfigure_edit":   "  • Use 'cc-switch edit default' to modify existing configuration",
+	"init.reconfigure_new":    "  • Use 'cc-switch new <name>' to create additional configurations",
+	"init.reconfigure_manual": "  • Manually backup and remove settings.json to reinitialize",
+
+	"use.restoring_empty":    "Currently in empty mode. Restoring settings first...",
+	"use.restored_empty":     "Settings restored from empty mode.",
+	"use.no_configs":         "No configurations found. Use 'cc-switch new <name>' to create your first configuration.",
+	"use.already_active":     "Configuration '%s' is already active",
+	"use.switched":           "Switched to configuration '%s'",
+	"use.switched_previous":  "Switched to configuration '%s' (previous: '%s')",
+	"use.switched_verified":  "Switched to configuration '%s' (settings.json verified)",
+	"use.previous_verified":  "Switched to configuration '%s' (previous: '%s', settings.json verified)",
+	"use.empty_enabled":      "Empty mode enabled. Use 'cc-switch use <profile>' to restore a configuration",
+	"use.empty_enabled_from": "Empty mode enabled. Previous: %s. Use 'cc-switch use <profile>' to restore or '--restore' for previous",
+	"use.restored_previous":  "Restored to previous configuration '%s'",
+	"use.refreshing":         "Refreshing configuration '%s'...",
+	"use.refreshed":          "Configuration '%s' refreshed successfully",
+
+	"rm.none_found":       "No configurations found.",
+	"rm.delete_all":       "This will delete ALL %d configuration(s):",
+	"rm.confirm_all":      "Type 'DELETE ALL' to confirm deletion of all configurations",
+	"rm.confirm_mismatch": "Operation cancelled - confirmation text did not match",
+	"rm.all_deleted":      "All configurations deleted successfully. Entering EMPTY MODE.",
+	"rm.confirm_current":  "Delete current configuration '%s' and enter EMPTY MODE?",
+	"rm.current_deleted":  "Current configuration '%s' deleted. Entering EMPTY MODE.",
+	"rm.confirm":          "Are you sure you want to remove configuration '%s'?",
+	"rm.removed":          "Configuration '%s' removed successfully",
+
+	"test.testing":         "Testing configuration: %s",
+	"test.testing_current": "Testing current configuration...",
+	"test.testing_all":     "Testing all configurations...",
+	"test.functional":      "✅ Result: Configuration is functional",
+	"test.total_time":      "   Total response time: %s",
+	"test.not_functional":  "❌ Result: Configuration has connectivity issues",
+	"test.summary":         "Summary: %d/%d configurations functional",
+	"test.summary_skipped": ", %d skipped",
+}
+
+var chineseMessages = map[string]string{
+	"common.error":     "错误：%v",
+	"common.cancelled": "操作已取消",
+
+	"select.prompt":  "%s（1-%d，回车或 q 取消）：",
+	"select.invalid": "请输入 1 到 %d 之间的数字，或回车取消",
+
+	"init.welcome":            "🚀 欢迎使用 Claude Code 配置向导！",
+	"init.intro":              "将为你创建初始的 Claude Code 配置。",
+	"init.empty_hint":         "暂时没有的信息可以留空。",
+	"init.created":            "✓ 配置创建成功",
+	"init.default_created":    "✓ 已创建默认配置 'default'",
+	"init.dirs_created":       "✓ 已初始化 cc-switch 目录结构",
+	"init.ready":              "🎉 Claude Code 配置已就绪！",
+	"init.next":               "现在可以使用 'cc-switch' 命令管理你的配置。",
+	"init.already":            "⚠ Claude Code 配置已存在",
+	"init.reconfigure":        "如需重新配置，可以：",
+	"init.reconfigure_edit":   "  • 使用 'cc-switch edit default' 修改现有配置",
+	"init.reconfigure_new":    "  • 使用 'cc-switch new <name>' 创建更多配置",
+	"init.reconfigure_manual": "  • 手动备份并删除 settings.json 后重新初始化",
+
+	"use.restoring_empty":    "当前处于空配置模式，先恢复设置...",
+	"use.restored_empty":     "已从空配置模式恢复设置。",
+	"use.no_configs":         "没有找到配置。使用 'cc-switch new <name>' 创建第一个配置。",
+	"use.already_active":     "配置 '%s' 已是当前配置",
+	"use.switched":           "已切换到配置 '%s'",
+	"use.switched_previous":  "已切换到配置 '%s'（上一个：'%s'）",
+	"use.switched_verified":  "已切换到配置 '%s'（settings.json 已校验）",
+	"use.previous_verified":  "已切换到配置 '%s'（上一个：'%s'，settings.json 已校验）",
+	"use.empty_enabled":      "已进入空配置模式。使用 'cc-switch use <profile>' 恢复配置",
+	"use.empty_enabled_from": "已进入空配置模式。上一个配置：%s。使用 'cc-switch use <profile>' 恢复，或使用 '--restore' 回到上一个配置",
+	"use.restored_previous":  "已恢复到上一个配置 '%s'",
+	"use.refreshing":         "正在刷新配置 '%s'...",
+	"use.refreshed":          "配置 '%s' 已刷新",
+
+	"rm.none_found":       "没有找到配置。",
+	"rm.delete_all":       "将删除全部 %d 个配置：",
+	"rm.confirm_all":      "输入 'DELETE ALL' 确认删除全部配置",
+	"rm.confirm_mismatch": "确认文本不匹配，操作已取消",
+	"rm.all_deleted":      "已删除全部配置，进入空配置模式。",
+	"rm.confirm_current":  "删除当前配置 '%s' 并进入空配置模式？",
+	"rm.current_deleted":  "已删除当前配置 '%s'，进入空配置模式。",
+	"rm.confirm":          "确定要删除配置 '%s' 吗？",
+	"rm.removed":          "配置 '%s' 已删除",
+
+	"test.testing":         "正在测试配置：%s",
+	"test.testing_current": "正在测试当前配置...",
+	"test.testing_all":     "正在测试全部配置...",
+	"test.functional":      "✅ 结果：配置可用",
+	"test.total_time":      "   总响应时间：%s",
+	"test.not_functional":  "❌ 结果：配置存在连接问题",
+	"test.summary":         "汇总：%d/%d 个配置可用",
+	"test.summary_skipped": "，跳过 %d 个",
+}
+
+// Language 返回当前输出语言，由 CC_SWITCH_LANG 决定，未设置或不支持时为英文
+func Language() string {
+	value := strings.ToLower(strings.TrimSpace(os.Getenv(LangEnv)))
+	// 兼容 locale 写法，如 zh_CN.UTF-8、zh-Hans
+	if i := strings.IndexAny(value, "_-."); i >= 0 {
+		value = value[:i]
+	}
+	if _, ok := messageCatalogs[value]; ok {
+		return value
+	}
+	return LangEnglish
+}
+
+// Text 返回消息键在当前语言下的文本，可直接作为格式串使用
+// 缺少翻译时回退到英文；英文中也不存在时返回键本身
+func Text(key string) string {
+	if message, ok := messageCatalogs[Language()][key]; ok {
+		return asciiText(message)
+	}
+	if message, ok := englishMessages[key]; ok {
+		return asciiText(message)
+	}
+	return key
+}
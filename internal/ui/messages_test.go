@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+// formatVerb matches the fmt verbs in a catalog message
+var formatVerb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func TestCatalogsHaveSameKeys(t *testing.T) {
+	for lang, catalog := range messageCatalogs {
+		for other, otherCatalog := range messageCatalogs {
+			for key := range catalog {
+				if _, ok := otherCatalog[key]; !ok {
+					t.Errorf("%q is in the %s catalog but missing from %s", key, lang, other)
+				}
+			}
+		}
+	}
+}
+
+func TestCatalogFormatVerbsMatch(t *testing.T) {
+	for lang, catalog := range messageCatalogs {
+		if lang == LangEnglish {
+			continue
+		}
+		for key, message := range catalog {
+			want := formatVerb.FindAllString(englishMessages[key], -1)
+			if got := formatVerb.FindAllString(message, -1); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s %q has verbs %v, English has %v", lang, key, got, want)
+			}
+		}
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: LangEnglish},
+		{value: "en", want: LangEnglish},
+		{value: "zh", want: LangChinese},
+		{value: "ZH", want: LangChinese},
+		{value: "zh_CN.UTF-8", want: LangChinese},
+		{value: "zh-Hans", want: LangChinese},
+		{value: " zh ", want: LangChinese},
+		{value: "fr_FR", want: LangEnglish},
+	}
+	for _, tt := range tests {
+		t.Setenv(LangEnv, tt.value)
+		if got := Language(); got != tt.want {
+			t.Errorf("Language() with %s=%q = %q, want %q", LangEnv, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	SetASCII(false)
+	t.Cleanup(func() { SetASCII(false) })
+
+	englishMessages["test.only_english"] = "only in English"
+	t.Cleanup(func() { delete(englishMessages, "test.only_english") })
+
+	tests := []struct {
+		name  string
+		lang  string
+		key   string
+		ascii bool
+		want  string
+	}{
+		{name: "english", lang: "en", key: "common.cancelled", want: englishMessages["common.cancelled"]},
+		{name: "chinese", lang: "zh", key: "common.cancelled", want: chineseMessages["common.cancelled"]},
+		{name: "missing translation", lang: "zh", key: "test.only_english", want: "only in English"},
+		{name: "unknown key", lang: "zh", key: "no.such.key", want: "no.such.key"},
+		{name: "ascii symbols", lang: "en", key: "test.functional", ascii: true, want: "[ok] Result: Configuration is functional"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(LangEnv, tt.lang)
+			SetASCII(tt.ascii)
+			if got := Text(tt.key); got != tt.want {
+				t.Errorf("Text(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+)
+
+// fuzzyMatch reports whether the characters of query appear in text in order, ignoring
+// case and spaces in the query, so "wpv2" matches "work-anthropic-proxy-v2"
+func fuzzyMatch(query, text string) bool {
+	target := []rune(strings.ToLower(text))
+	pos := 0
+	for _, r := range strings.ToLower(query) {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		for pos < len(target) && target[pos] != r {
+			pos++
+		}
+		if pos == len(target) {
+			return false
+		}
+		pos++
+	}
+	return true
+}
+
+// profileMatches reports whether query fuzzy-matches the configuration's name, display
+// name, one of its aliases or one of its tags. Each is matched on its own, so a query
+// cannot be satisfied by letters spread across different fields.
+func profileMatches(query string, profile *config.Profile) bool {
+	fields := append([]string{profile.Name, profile.DisplayName}, profile.Aliases...)
+	fields = append(fields, profile.Tags...)
+	for _, field := range fields {
+		if field != "" && fuzzyMatch(query, field) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ErrSelectionCancelled is returned when the user leaves a menu without choosing an item
+var ErrSelectionCancelled = errors.New("no item chosen")
+
+// MenuItem is one entry of a numbered text menu
+type MenuItem struct {
+	Label       string
+	Description string // optional, shown after the label
+}
+
+// SelectFromMenu prints a numbered menu under title and asks for a choice with prompt (such as
+// "Select template to copy"), returning the index of the chosen item. Invalid or out-of-range input re-prompts; empty input, "q" or EOF cancels
+// with ErrSelectionCancelled.
+func SelectFromMenu(title string, items []MenuItem, prompt string) (int, error) {
+	return selectFromMenu(os.Stdin, os.Stdout, title, items, prompt)
+}
+
+func selectFromMenu(in io.Reader, out io.Writer, title string, items []MenuItem, prompt string) (int, error) {
+	if len(items) == 0 {
+		return -1, fmt.Errorf("nothing to select")
+	}
+
+	fmt.Fprintln(out, title)
+	for i, item := range items {
+		if item.Description != "" {
+			fmt.Fprintf(out, "  %d) %s %s\n", i+1, item.Label, item.Description)
+		} else {
+			fmt.Fprintf(out, "  %d) %s\n", i+1, item.Label)
+		}
+	}
+
+	for {
+		fmt.Fprintf(out, Text("select.prompt"), prompt, len(items))
+		line, err := readLine(in)
+		if err != nil {
+			// The prompt line was never terminated by the user's Enter
+			fmt.Fprintln(out)
+			if err != io.EOF {
+				return -1, fmt.Errorf("failed to read selection: %w", err)
+			}
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" || strings.EqualFold(line, "q") {
+			return -1, ErrSelectionCancelled
+		}
+		if n, convErr := strconv.Atoi(line); convErr == nil && n >= 1 && n <= len(items) {
+			return n - 1, nil
+		}
+		if err != nil {
+			// Input ended on an invalid entry, there is nothing left to re-prompt for
+			return -1, ErrSelectionCancelled
+		}
+		fmt.Fprintf(out, Text("select.invalid")+"\n", len(items))
+	}
+}
+
+// readLine reads a single line one byte at a time, so input meant for later prompts is not
+// consumed by buffering. The error is io.EOF when input ends, possibly after a partial line.
+func readLine(in io.Reader) (string, error) {
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := in.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				return strings.TrimSuffix(string(line), "\r"), nil
+			}
+			line = append(line, buf[0])
+		}
+		if err != nil {
+			return string(line), err
+		}
+	}
+}
+
+// stdinIsTerminal reports whether stdin is a terminal that can drive arrow-key menus
+func stdinIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// TemplateMenuItems builds menu entries for template names, marking the system default
+func TemplateMenuItems(templates []string) []MenuItem {
+	items := make([]MenuItem, len(templates))
+	for i, template := range templates {
+		items[i] = MenuItem{Label: template}
+		if template == "default" {
+			items[i].Description = "(system default)"
+		}
+	}
+	return items
+}
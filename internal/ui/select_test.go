@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSelectFromMenu(t *testing.T) {
+	items := []MenuItem{{Label: "work"}, {Label: "home", Description: "(current)"}, {Label: "proxy"}}
+
+	tests := []struct {
+		name        string
+		input       string
+		want        int
+		wantErr     error
+		wantInvalid bool // the out-of-range hint is shown
+	}{
+		{name: "choice", input: "2\n", want: 1},
+		{name: "choice with spaces", input: "  3 \n", want: 2},
+		{name: "windows line ending", input: "1\r\n", want: 0},
+		{name: "last line without newline", input: "3", want: 2},
+		{name: "empty line cancels", input: "\n", want: -1, wantErr: ErrSelectionCancelled},
+		{name: "q cancels", input: "Q\n", want: -1, wantErr: ErrSelectionCancelled},
+		{name: "eof cancels", input: "", want: -1, wantErr: ErrSelectionCancelled},
+		{name: "out of range re-prompts", input: "4\n1\n", want: 0, wantInvalid: true},
+		{name: "text re-prompts", input: "home\n2\n", want: 1, wantInvalid: true},
+		{name: "invalid last line cancels", input: "0", want: -1, wantErr: ErrSelectionCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			got, err := selectFromMenu(strings.NewReader(tt.input), &out, "Available:", items, "Select")
+			if got != tt.want || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("selectFromMenu = %d, %v, want %d, %v", got, err, tt.want, tt.wantErr)
+			}
+			invalid := strings.Contains(out.String(), fmt.Sprintf(Text("select.invalid"), len(items)))
+			if invalid != tt.wantInvalid {
+				t.Errorf("invalid hint shown = %v, want %v; output:\n%s", invalid, tt.wantInvalid, out.String())
+			}
+		})
+	}
+}
+
+func TestSelectFromMenuListsItems(t *testing.T) {
+	var out strings.Builder
+	items := []MenuItem{{Label: "work"}, {Label: "home", Description: "(current)"}}
+	if _, err := selectFromMenu(strings.NewReader("1\n"), &out, "Available:", items, "Select"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Available:\n", "  1) work\n", "  2) home (current)\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output is missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestSelectFromMenuRejectsEmptyMenu(t *testing.T) {
+	_, err := selectFromMenu(strings.NewReader("1\n"), io.Discard, "Available:", nil, "Select")
+	if err == nil || errors.Is(err, ErrSelectionCancelled) {
+		t.Errorf("err = %v, want an error other than cancellation", err)
+	}
+}
+
+func TestSelectFromMenuLeavesLaterInput(t *testing.T) {
+	// Input meant for the next prompt must still be there after a selection
+	in := strings.NewReader("2\nnew-name\n")
+	if _, err := selectFromMenu(in, io.Discard, "Available:", []MenuItem{{Label: "a"}, {Label: "b"}}, "Select"); err != nil {
+		t.Fatal(err)
+	}
+	rest, err := io.ReadAll(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(rest) != "new-name\n" {
+		t.Errorf("remaining input = %q, want %q", rest, "new-name\n")
+	}
+}
+
+func TestTemplateMenuItems(t *testing.T) {
+	got := TemplateMenuItems([]string{"default", "team"})
+	want := []MenuItem{{Label: "default", Description: "(system default)"}, {Label: "team"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TemplateMenuItems = %+v, want %+v", got, want)
+	}
+}
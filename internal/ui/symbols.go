@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/manifoldco/promptui"
+)
+
+// asciiEnv forces the ASCII symbol set when set to a true value
+const asciiEnv = "CC_SWITCH_ASCII"
+
+// Symbols are the markers printed in front of messages, selector entries and test results
+type Symbols struct {
+	Pointer string // highlighted selector entry
+	Check   string // chosen selector entry and success messages
+	Warning string
+	Info    string
+	Bullet  string
+	Passed  string // test status: success
+	Failed  string // test status: failed
+	Timeout string // test status: timeout
+	Unknown string // test status: anything else
+	Skipped string // test skipped
+	// LeftArrow joins an origin chain, e.g. "copy-of:a ← template:team"
+	LeftArrow string
+}
+
+// UnicodeSymbols are used on terminals that can display them
+var UnicodeSymbols = Symbols{
+	Pointer: "▶",
+	Check:   "✓",
+	Warning: "⚠",
+	Info:    "ℹ",
+	Bullet:  "•",
+	Passed:  "✅",
+	Failed:  "❌",
+	Timeout: "⏱️",
+	Unknown: "❓",
+	Skipped: "⏭️",
+
+	LeftArrow: "←",
+}
+
+// ASCIISymbols are used where unicode output is not safe, or when CC_SWITCH_ASCII=1
+var ASCIISymbols = Symbols{
+	Pointer: ">",
+	Check:   "*",
+	Warning: "!",
+	Info:    "i",
+	Bullet:  "-",
+	Passed:  "[ok]",
+	Failed:  "[x]",
+	Timeout: "[timeout]",
+	Unknown: "[?]",
+	Skipped: "[skip]",
+
+	LeftArrow: "<-",
+}
+
+var (
+	symbolsMu sync.RWMutex
+	symbols   = UnicodeSymbols
+	// promptIcons holds promptui's own icons so the unicode set can put them back
+	promptIcons = [...]string{promptui.IconInitial, promptui.IconGood, promptui.IconWarn, promptui.IconBad, promptui.IconSelect}
+	// asciiReplacer rewrites the symbols and emoji used in the message catalogs
+	asciiReplacer = strings.NewReplacer(
+		"🚀 ", "", "🎉 ", "",
+		"✅", ASCIISymbols.Passed, "❌", ASCIISymbols.Failed,
+		"✓", ASCIISymbols.Check, "⚠", ASCIISymbols.Warning, "•", ASCIISymbols.Bullet,
+	)
+)
+
+// Sym returns the active symbol set
+func Sym() Symbols {
+	symbolsMu.RLock()
+	defer symbolsMu.RUnlock()
+	return symbols
+}
+
+// ASCII reports whether the ASCII symbol set is active
+func ASCII() bool {
+	return Sym() == ASCIISymbols
+}
+
+// SetASCII switches between the ASCII and unicode symbol sets, including the icons
+// promptui draws in its prompts
+func SetASCII(enabled bool) {
+	symbolsMu.Lock()
+	defer symbolsMu.Unlock()
+	if enabled {
+		symbols = ASCIISymbols
+		promptui.IconInitial = promptui.Styler(promptui.FGBlue)("?")
+		promptui.IconGood = promptui.Styler(promptui.FGGreen)("*")
+		promptui.IconWarn = promptui.Styler(promptui.FGYellow)("!")
+		promptui.IconBad = promptui.Styler(promptui.FGRed)("x")
+		promptui.IconSelect = promptui.Styler(promptui.FGBold)(">")
+	} else {
+		symbols = UnicodeSymbols
+		promptui.IconInitial, promptui.IconGood, promptui.IconWarn, promptui.IconBad, promptui.IconSelect =
+			promptIcons[0], promptIcons[1], promptIcons[2], promptIcons[3], promptIcons[4]
+	}
+}
+
+// SetupTerminal probes the terminal once at startup: it enables ANSI escape
+// processing where the platform needs it, and selects the ASCII symbol set when
+// CC_SWITCH_ASCII is set or the terminal cannot display unicode safely
+func SetupTerminal() {
+	vt := enableVirtualTerminal()
+	if value, ok := os.LookupEnv(asciiEnv); ok && value != "" {
+		SetASCII(value != "0" && !strings.EqualFold(value, "false"))
+		return
+	}
+	SetASCII(!vt || !unicodeSafe())
+}
+
+// asciiText rewrites the symbols in a catalog message when the ASCII set is active
+func asciiText(message string) string {
+	if !ASCII() {
+		return message
+	}
+	return asciiReplacer.Replace(message)
+}
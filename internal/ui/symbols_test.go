@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/manifoldco/promptui"
+)
+
+func TestSetASCIIRestoresPromptIcons(t *testing.T) {
+	original := []string{promptui.IconInitial, promptui.IconGood, promptui.IconWarn, promptui.IconBad, promptui.IconSelect}
+	t.Cleanup(func() { SetASCII(false) })
+
+	SetASCII(true)
+	if !ASCII() || promptui.IconGood == original[1] {
+		t.Fatalf("SetASCII(true): ASCII() = %v, IconGood = %q, want ASCII icons", ASCII(), promptui.IconGood)
+	}
+
+	SetASCII(false)
+	got := []string{promptui.IconInitial, promptui.IconGood, promptui.IconWarn, promptui.IconBad, promptui.IconSelect}
+	for i := range original {
+		if got[i] != original[i] {
+			t.Errorf("SetASCII(false): icon %d = %q, want promptui's %q", i, got[i], original[i])
+		}
+	}
+	if ASCII() {
+		t.Error("SetASCII(false) left the ASCII symbol set active")
+	}
+}
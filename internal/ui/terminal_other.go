@@ -0,0 +1,26 @@
+//go:build !windows
+
+package ui
+
+import (
+	"os"
+	"strings"
+)
+
+// enableVirtualTerminal is a no-op: other platforms' terminals process ANSI escapes
+func enableVirtualTerminal() bool {
+	return true
+}
+
+// unicodeSafe reports whether the locale allows unicode output. The first of
+// LC_ALL, LC_CTYPE and LANG that is set decides; an explicit non-UTF-8 locale
+// such as C or POSIX selects ASCII, while no locale at all keeps unicode
+func unicodeSafe() bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := os.Getenv(name); locale != "" {
+			locale = strings.ToLower(locale)
+			return strings.Contains(locale, "utf-8") || strings.Contains(locale, "utf8")
+		}
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+//go:build windows
+
+package ui
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// utf8CodePage is the console code page for UTF-8 output
+const utf8CodePage = 65001
+
+// enableVirtualTerminal turns on ANSI escape processing for the console so
+// promptui's cursor movement and colors work in cmd.exe and older PowerShell.
+// It reports false when the console does not support it (before Windows 10);
+// output that is not a console is left alone
+func enableVirtualTerminal() bool {
+	handle := windows.Handle(os.Stdout.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(handle, &mode); err != nil {
+		return true
+	}
+	if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+		return true
+	}
+	return windows.SetConsoleMode(handle, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING) == nil
+}
+
+// unicodeSafe reports whether the terminal displays unicode symbols: Windows
+// Terminal, VS Code and ConEmu do, and so does a console using the UTF-8 code page
+func unicodeSafe() bool {
+	if os.Getenv("WT_SESSION") != "" || os.Getenv("TERM_PROGRAM") == "vscode" || os.Getenv("ConEmuANSI") == "ON" {
+		return true
+	}
+	cp, err := windows.GetConsoleOutputCP()
+	return err == nil && cp == utf8CodePage
+}
@@ -27,6 +27,13 @@ class CCSwitch {
         
         // Show profiles tab by default
         this.showSection('profiles');
+
+        // Report a switch confirmed on a /switch/{profile} page
+        const switched = new URLSearchParams(window.location.search).get('switched');
+        if (switched) {
+            this.showSuccess(`Switched to configuration: ${switched}`);
+            window.history.replaceState(null, '', '/');
+        }
         
         console.log('✅ cc-switch web interface ready!');
     }
@@ -153,13 +160,16 @@ class CCSwitch {
             return `
                 <div class="profile-item ${isCurrent ? 'current' : ''}">
                     <div class="profile-info">
-                        <div class="profile-name">${this.escapeHtml(profile.name)}</div>
+                        <div class="profile-name" title="${this.escapeHtml(profile.name)}">${this.escapeHtml(profile.display_name || profile.name)}</div>
+                        ${profile.display_name ? `<div class="profile-id">${this.escapeHtml(profile.name)}</div>` : ''}
                         ${isCurrent ? '<div class="profile-status current">Current</div>' : ''}
+                        ${profile.snapshot ? '<div class="profile-status system" title="Snapshot of settings.json before cc-switch, read-only">Snapshot</div>' : ''}
+                        ${this.lastTestBadge(profile.last_test)}
                     </div>
                     <div class="profile-actions">
                         ${!isCurrent ? `<button class="btn btn-success" onclick="app.switchProfile('${this.escapeHtml(profile.name)}')">Use</button>` : ''}
                         <button class="btn btn-outline" onclick="app.viewProfile('${this.escapeHtml(profile.name)}')">View</button>
-                        <button class="btn btn-warning" onclick="app.editProfile('${this.escapeHtml(profile.name)}')">Edit</button>
+                        ${!profile.snapshot ? `<button class="btn btn-warning" onclick="app.editProfile('${this.escapeHtml(profile.name)}')">Edit</button>` : ''}
                         <button class="btn btn-danger" onclick="app.deleteProfile('${this.escapeHtml(profile.name)}')">Delete</button>
                     </div>
                 </div>
@@ -171,6 +181,7 @@ class CCSwitch {
                 <h2>Available Configurations</h2>
                 <div class="flex gap-2">
                     ${this.isEmptyMode ? '<button class="btn btn-success" onclick="app.restoreFromEmptyMode()">Restore Config</button>' : '<button class="btn btn-secondary" onclick="app.useEmptyMode()">Empty Mode</button>'}
+                    <button class="btn btn-outline" onclick="app.showDiffModal()">Compare</button>
                     <button class="btn btn-primary" onclick="app.createProfile()">New Config</button>
                 </div>
             </div>
@@ -229,6 +240,18 @@ class CCSwitch {
                 <h3>Test Results</h3>
                 <div id="test-results-content"></div>
             </div>
+            <h2 style="margin-top: 2rem;">Health Scan</h2>
+            <p>Test every profile in parallel and watch the results arrive.</p>
+            <div class="form-group">
+                <button class="btn btn-primary" onclick="app.runHealthScan()" id="scan-button">
+                    Scan All Profiles
+                </button>
+                <button class="btn btn-outline" onclick="app.cancelHealthScan()" id="scan-cancel" style="display: none;">
+                    Cancel
+                </button>
+            </div>
+            <div id="scan-summary" style="margin-bottom: 1rem;"></div>
+            <div id="scan-results"></div>
         `;
     }
 
@@ -260,6 +283,7 @@ class CCSwitch {
                     </div>
                     <div class="profile-actions">
                         <button class="btn btn-outline" onclick="app.viewTemplate('${this.escapeHtml(template)}')">View</button>
+                        <button class="btn btn-outline" onclick="app.showDiffModal(null, 'template:${this.escapeHtml(template)}')">Compare</button>
                         ${!isDefault ? `<button class="btn btn-warning" onclick="app.editTemplate('${this.escapeHtml(template)}')">Edit</button>` : ''}
                         ${!isDefault ? `<button class="btn btn-secondary" onclick="app.copyTemplate('${this.escapeHtml(template)}')">Copy</button>` : ''}
                         ${!isDefault ? `<button class="btn btn-danger" onclick="app.deleteTemplate('${this.escapeHtml(template)}')">Delete</button>` : ''}
@@ -301,9 +325,8 @@ class CCSwitch {
 
     async useEmptyMode() {
         try {
-            const response = await this.apiCall('/api/switch', {
-                method: 'POST',
-                body: JSON.stringify({ profile: '' })
+            const response = await this.apiCall('/api/empty-mode', {
+                method: 'POST'
             });
             
             this.showSuccess('Switched to empty mode');
@@ -316,9 +339,8 @@ class CCSwitch {
 
     async restoreFromEmptyMode() {
         try {
-            const response = await this.apiCall('/api/switch', {
-                method: 'POST',
-                body: JSON.stringify({ restore: true })
+            const response = await this.apiCall('/api/empty-mode', {
+                method: 'DELETE'
             });
             
             this.showSuccess('Configuration restored from empty mode');
@@ -330,8 +352,13 @@ class CCSwitch {
     }
 
     async deleteProfile(profileName) {
+        const isCurrent = profileName === this.currentProfile && !this.isEmptyMode;
+        const message = isCurrent
+            ? `"${profileName}" is the active configuration. Deleting it will enter empty mode. Continue?`
+            : `Are you sure you want to delete configuration "${profileName}"?`;
+
         const confirmed = await this.showConfirm(
-            `Are you sure you want to delete configuration "${profileName}"?`,
+            message,
             {
                 title: 'Delete Configuration',
                 type: 'danger',
@@ -344,10 +371,13 @@ class CCSwitch {
 
         try {
             const response = await this.apiCall(`/api/profiles/${encodeURIComponent(profileName)}`, {
-                method: 'DELETE'
+                method: 'DELETE',
+                body: JSON.stringify({ force: isCurrent })
             });
             
-            this.showSuccess(`Configuration "${profileName}" deleted successfully`);
+            this.showSuccess(isCurrent
+                ? `Configuration "${profileName}" deleted, empty mode enabled`
+                : `Configuration "${profileName}" deleted successfully`);
             await this.loadData();
             this.renderProfiles();
         } catch (error) {
@@ -439,6 +469,97 @@ class CCSwitch {
         }
     }
 
+    // Runs a health scan of every profile over server-sent events; each row is
+    // filled in as its result arrives
+    runHealthScan() {
+        if (this.scanSource) return;
+
+        const quick = document.getElementById('test-quick').checked;
+        const scanButton = document.getElementById('scan-button');
+        const cancelButton = document.getElementById('scan-cancel');
+        const summary = document.getElementById('scan-summary');
+        const resultsEl = document.getElementById('scan-results');
+        const rows = new Map();
+        let total = 0;
+        let done = 0;
+
+        const finish = () => {
+            if (this.scanSource) {
+                this.scanSource.close();
+                this.scanSource = null;
+            }
+            scanButton.disabled = false;
+            scanButton.innerHTML = 'Scan All Profiles';
+            cancelButton.style.display = 'none';
+        };
+
+        scanButton.disabled = true;
+        scanButton.innerHTML = '<div class="spinner"></div>Scanning...';
+        cancelButton.style.display = '';
+        summary.textContent = 'Starting scan...';
+        resultsEl.innerHTML = '';
+
+        const source = new EventSource(`/api/test/all?quick=${quick}&timeout=45`);
+        this.scanSource = source;
+
+        source.addEventListener('start', event => {
+            const data = JSON.parse(event.data);
+            total = data.profiles.length;
+            resultsEl.innerHTML = data.profiles.map(name => `
+                <div class="scan-row" data-profile="${this.escapeHtml(name)}" style="padding: 0.5rem 1rem; margin-bottom: 0.5rem; border-left: 3px solid var(--text-secondary); background: var(--bg-secondary);">
+                    ⏳ <strong>${this.escapeHtml(name)}</strong> <span class="scan-detail">testing...</span>
+                </div>
+            `).join('');
+            resultsEl.querySelectorAll('.scan-row').forEach(row => rows.set(row.dataset.profile, row));
+            summary.textContent = `0/${total} profiles tested`;
+        });
+
+        source.addEventListener('result', event => {
+            const result = JSON.parse(event.data);
+            done++;
+            summary.textContent = `${done}/${total} profiles tested`;
+
+            const row = rows.get(result.profile_name);
+            if (!row) return;
+            const responseTime = Math.round(result.response_time_ms / 1000000);
+            let icon = result.is_connectable ? '✅' : '❌';
+            let detail = result.is_connectable ? `functional (${responseTime}ms)` : (result.error || 'has issues');
+            let color = result.is_connectable ? '#28a745' : '#dc3545';
+            if (result.skipped) {
+                icon = '⏭️';
+                detail = `skipped: ${result.error}`;
+                color = 'var(--text-secondary)';
+            }
+            row.style.borderLeftColor = color;
+            row.innerHTML = `${icon} <strong>${this.escapeHtml(result.profile_name)}</strong> <span class="scan-detail">${this.escapeHtml(detail)}</span>`;
+        });
+
+        source.addEventListener('done', event => {
+            const data = JSON.parse(event.data);
+            summary.textContent = `${data.passed} functional, ${data.failed} with issues, ${data.skipped} skipped`;
+            finish();
+        });
+
+        source.onerror = () => {
+            // EventSource reconnects on its own, which would start a new scan
+            if (!this.scanSource) return;
+            summary.textContent = done > 0 ? `Scan interrupted after ${done}/${total} profiles` : '';
+            this.showError('Health scan failed or was interrupted');
+            finish();
+        };
+    }
+
+    // Closing the event stream cancels the scan on the server
+    cancelHealthScan() {
+        if (!this.scanSource) return;
+        this.scanSource.close();
+        this.scanSource = null;
+        document.getElementById('scan-summary').textContent = 'Scan cancelled';
+        document.getElementById('scan-button').disabled = false;
+        document.getElementById('scan-button').innerHTML = 'Scan All Profiles';
+        document.getElementById('scan-cancel').style.display = 'none';
+    }
+
     // Helper function to get user-friendly test names
     getTestName(test) {
         if (test.method === 'GET' && test.endpoint === '/v1/models') {
@@ -574,6 +695,131 @@ class CCSwitch {
         document.body.appendChild(modal);
     }
 
+    // Diff view: compare any two of a profile, a template and the live settings
+    showDiffModal(left = null, right = null) {
+        const defaultLeft = this.currentProfile && !this.isEmptyMode ? `profile:${this.currentProfile}` : 'settings';
+        const options = (selected) => `
+            <option value="settings" ${selected === 'settings' ? 'selected' : ''}>Live settings.json</option>
+            <optgroup label="Profiles">
+                ${this.profiles.map(p => {
+                    const value = `profile:${p.name}`;
+                    return `<option value="${this.escapeHtml(value)}" ${selected === value ? 'selected' : ''}>${this.escapeHtml(p.name)}</option>`;
+                }).join('')}
+            </optgroup>
+            <optgroup label="Templates">
+                ${this.templates.map(t => {
+                    const value = `template:${t}`;
+                    return `<option value="${this.escapeHtml(value)}" ${selected === value ? 'selected' : ''}>${this.escapeHtml(t)}</option>`;
+                }).join('')}
+            </optgroup>
+        `;
+
+        const content = `
+            <div class="diff-controls">
+                <div class="form-group">
+                    <label class="form-label" for="diff-left">Left</label>
+                    <select id="diff-left" class="form-input">${options(left || defaultLeft)}</select>
+                </div>
+                <div class="form-group">
+                    <label class="form-label" for="diff-right">Right</label>
+                    <select id="diff-right" class="form-input">${options(right || 'template:default')}</select>
+                </div>
+                <div class="form-group">
+                    <button class="btn btn-primary" onclick="app.runDiff()">Compare</button>
+                </div>
+            </div>
+            <div id="diff-results"></div>
+        `;
+
+        this.showModal('Compare Configurations', content);
+        this.runDiff();
+    }
+
+    async runDiff() {
+        const results = document.getElementById('diff-results');
+        const left = document.getElementById('diff-left')?.value;
+        const right = document.getElementById('diff-right')?.value;
+        if (!results || !left || !right) return;
+
+        results.innerHTML = '<div class="loading"><div class="spinner"></div>COMPARING...</div>';
+        try {
+            const query = new URLSearchParams({ left, right });
+            const response = await this.apiCall(`/api/diff?${query}`);
+            results.innerHTML = this.renderDiff(response.data);
+        } catch (error) {
+            results.innerHTML = `<div class="status status-offline">${this.escapeHtml(error.message)}</div>`;
+        }
+    }
+
+    renderDiff(data) {
+        const changes = data.changes || [];
+        if (changes.length === 0) {
+            return '<div class="status status-online">✓ No differences</div>';
+        }
+
+        const labels = { added: 'only on right', removed: 'only on left', changed: 'changed', unset: 'placeholder not set' };
+        const summary = Object.entries(data.summary || {})
+            .filter(([, count]) => count > 0)
+            .map(([kind, count]) => `<span class="diff-badge ${kind}">${count} ${labels[kind] || kind}</span>`)
+            .join('');
+
+        // Group the dotted paths into a tree so nested objects read like the JSON they come from
+        const root = { children: new Map(), entries: [] };
+        changes.forEach(change => {
+            const parts = change.path.split('.');
+            let node = root;
+            parts.slice(0, -1).forEach(part => {
+                if (!node.children.has(part)) {
+                    node.children.set(part, { children: new Map(), entries: [] });
+                }
+                node = node.children.get(part);
+            });
+            node.entries.push({ key: parts[parts.length - 1], change });
+        });
+
+        return `
+            <div class="diff-summary">${summary}</div>
+            <div class="code-block"><ul class="diff-tree">${this.renderDiffNode(root)}</ul></div>
+        `;
+    }
+
+    renderDiffNode(node) {
+        const format = (value) => this.escapeHtml(JSON.stringify(value === undefined ? null : value));
+        const markers = { added: '+', removed: '-', changed: '~', unset: '?' };
+
+        const groups = [...node.children.entries()].map(([name, child]) => `
+            <li class="diff-group">
+                <span class="diff-group-name">${this.escapeHtml(name)}</span>
+                <ul>${this.renderDiffNode(child)}</ul>
+                <span style="color: #9ca3af;">}</span>
+            </li>
+        `).join('');
+
+        const lines = node.entries.map(({ key, change }) => {
+            let value;
+            switch (change.kind) {
+                case 'added':
+                    value = format(change.new);
+                    break;
+                case 'removed':
+                    value = format(change.old);
+                    break;
+                case 'unset':
+                    value = '<em>template placeholder, not set</em>';
+                    break;
+                default:
+                    value = `<span class="diff-old">${format(change.old)}</span> → ${format(change.new)}`;
+            }
+            return `
+                <li class="diff-line ${change.kind}" title="${this.escapeHtml(change.path)}">
+                    <span class="diff-marker">${markers[change.kind] || ''}</span>${this.escapeHtml(key)}: ${value}
+                </li>
+            `;
+        }).join('');
+
+        return groups + lines;
+    }
+
     renderProfileView(profile) {
         const content = profile.content;
         const formattedJson = JSON.stringify(content, null, 2);
@@ -582,6 +828,10 @@ class CCSwitch {
             <div class="profile-metadata">
                 <dt>Name:</dt>
                 <dd>${this.escapeHtml(profile.name)}</dd>
+                ${profile.display_name ? `<dt>Display Name:</dt>
+                <dd>${this.escapeHtml(profile.display_name)}</dd>` : ''}
+                <dt>Origin:</dt>
+                <dd>${this.escapeHtml((profile.origin_chain || [profile.origin || 'unknown']).join(' ← '))}</dd>
                 <dt>Path:</dt>
                 <dd>${this.escapeHtml(profile.path)}</dd>
                 <dt>Current:</dt>
@@ -758,6 +1008,11 @@ class CCSwitch {
             const newName = nameInput ? nameInput.value.trim() : profileName;
             const formData = this.collectFormData();
             
+            // Validate raw JSON content before saving
+            if (window.currentEditMode === 'raw' && !(await this.validateContent('profile', formData))) {
+                return;
+            }
+            
             // Check if name changed
             if (newName !== profileName) {
                 // Validate new name
@@ -806,6 +1061,30 @@ class CCSwitch {
         }
     }
 
+    // Validate content with the server, showing errors and returning false if it cannot be saved
+    async validateContent(type, content) {
+        try {
+            const response = await this.apiCall('/api/validate', {
+                method: 'POST',
+                body: JSON.stringify({ type, content })
+            });
+            const issues = response.data.issues || [];
+            const errors = issues.filter(issue => issue.severity === 'error');
+            if (errors.length > 0) {
+                this.showError(errors.map(issue => {
+                    const text = `${issue.path || '(root)'}: ${issue.message}`;
+                    return issue.remediation ? `${text} (fix: ${issue.remediation})` : text;
+                }).join('; '));
+                return false;
+            }
+            return true;
+        } catch (error) {
+            // Fall back to server-side checks on save if validation is unavailable
+            console.error('Validation failed:', error);
+            return true;
+        }
+    }
+
     collectFormData() {
         // Check current edit mode
         if (window.currentEditMode === 'raw') {
@@ -1126,6 +1405,23 @@ class CCSwitch {
         }
     }
 
+    // Last recorded test result from the activity log, e.g. "✅ tested 2 h ago"
+    lastTestBadge(lastTest) {
+        if (!lastTest) {
+            return '<div class="profile-id" title="No test recorded">❓ never tested</div>';
+        }
+        const symbol = lastTest.result === 'ok' ? '✅' : '❌';
+        const minutes = Math.max(0, Math.floor((Date.now() - new Date(lastTest.tested_at)) / 60000));
+        let age = `${minutes} min`;
+        if (minutes >= 48 * 60) {
+            age = `${Math.floor(minutes / (24 * 60))} days`;
+        } else if (minutes >= 60) {
+            age = `${Math.floor(minutes / 60)} h`;
+        }
+        const title = `Tested ${new Date(lastTest.tested_at).toLocaleString()}${lastTest.detail ? ': ' + lastTest.detail : ''}`;
+        return `<div class="profile-id" title="${this.escapeHtml(title)}">${symbol} tested ${age} ago</div>`;
+    }
+
     // Utility Methods
     escapeHtml(text) {
         const div = document.createElement('div');
@@ -1575,7 +1871,7 @@ class CCSwitch {
             exportButton.disabled = true;
             exportButton.innerHTML = '<div class="spinner"></div>Exporting...';
             
-            const response = await fetch('/api/export', {
+            const response = await fetch('/api/export?stream=true', {
                 method: 'POST',
                 headers: {
                     'Content-Type': 'application/json'
@@ -1583,15 +1879,23 @@ class CCSwitch {
                 body: JSON.stringify(requestData)
             });
             
-            if (response.ok) {
-                // Download file
-                const blob = await response.blob();
+            const result = await this.readImportStream(response, (progress) => {
+                exportButton.innerHTML = `<div class="spinner"></div>Exporting... ${progress.percent}% (${progress.profiles}/${progress.total})`;
+            });
+            
+            if (response.ok && result.success) {
+                // Download file (content is base64-encoded)
+                const binary = atob(result.data.content);
+                const bytes = new Uint8Array(binary.length);
+                for (let i = 0; i < binary.length; i++) {
+                    bytes[i] = binary.charCodeAt(i);
+                }
+                const blob = new Blob([bytes], { type: 'application/octet-stream' });
                 const url = window.URL.createObjectURL(blob);
                 const a = document.createElement('a');
                 a.href = url;
                 
-                const timestamp = new Date().toISOString().slice(0, 19).replace(/[:-]/g, '');
-                const filename = `cc-switch-${exportType}-${timestamp}.ccx`;
+                const filename = result.data.filename;
                 a.download = filename;
                 
                 document.body.appendChild(a);
@@ -1602,8 +1906,7 @@ class CCSwitch {
                 this.showSuccess(`Export completed successfully! File: ${filename}`);
                 this.closeModal();
             } else {
-                const error = await response.json();
-                this.showError(`Export failed: ${error.error || 'Unknown error'}`);
+                this.showError(`Export failed: ${result.error || 'Unknown error'}`);
             }
         } catch (error) {
             this.showError(`Export failed: ${error.message}`);
@@ -1882,6 +2185,10 @@ class CCSwitch {
                             <input type="checkbox" id="import-preview" style="margin-right: 0.5rem;">
                             Preview only (don't actually import)
                         </label>
+                        <label class="checkbox-label" style="display: block; margin-bottom: 0.5rem;">
+                            <input type="checkbox" id="import-restore-current" style="margin-right: 0.5rem;">
+                            Switch to the profile that was active when the file was exported
+                        </label>
                     </div>
                     
                     <div class="form-group" style="margin-top: 1rem;">
@@ -1978,7 +2285,8 @@ class CCSwitch {
         
         const options = {
             conflict_mode: document.getElementById('import-conflict').value,
-            dry_run: document.getElementById('import-preview').checked
+            dry_run: document.getElementById('import-preview').checked,
+            restore_current: document.getElementById('import-restore-current').checked
         };
         
         const password = document.getElementById('import-password').value;
@@ -1995,12 +2303,14 @@ class CCSwitch {
             importButton.disabled = true;
             importButton.innerHTML = '<div class="spinner"></div>Importing...';
             
-            const response = await fetch('/api/import', {
+            const response = await fetch('/api/import?stream=true', {
                 method: 'POST',
                 body: formData
             });
             
-            const result = await response.json();
+            const result = await this.readImportStream(response, (progress) => {
+                importButton.innerHTML = `<div class="spinner"></div>Importing... (${progress.index}/${progress.total})`;
+            });
             
             if (response.ok && result.success) {
                 this.showImportResults(result.data);
@@ -2019,6 +2329,39 @@ class CCSwitch {
         }
     }
 
+    // Read an NDJSON import or export stream, reporting progress until the final summary line
+    async readImportStream(response, onProgress) {
+        if (!response.body || !(response.headers.get('Content-Type') || '').includes('application/x-ndjson')) {
+            return response.json();
+        }
+        
+        const reader = response.body.getReader();
+        const decoder = new TextDecoder();
+        let buffer = '';
+        let final = { success: false, error: 'Stream ended unexpectedly' };
+        
+        while (true) {
+            const { value, done } = await reader.read();
+            if (done) break;
+            
+            buffer += decoder.decode(value, { stream: true });
+            const lines = buffer.split('\n');
+            buffer = lines.pop();
+            
+            for (const line of lines) {
+                if (!line.trim()) continue;
+                const event = JSON.parse(line);
+                if (event.done) {
+                    final = event;
+                } else {
+                    onProgress(event);
+                }
+            }
+        }
+        
+        return final;
+    }
+
     showImportResults(result) {
         const isDryRun = result.dry_run || false;
         const title = isDryRun ? 'Import Preview Results' : 'Import Results';
@@ -2059,6 +2402,18 @@ class CCSwitch {
                     </div>`;
         }
         
+        if (result.current_restored) {
+            content += `
+                    <div class="summary-item" style="margin-bottom: 0.5rem;">
+                        <strong>${isDryRun ? 'Would switch to' : 'Switched to'}:</strong> ${this.escapeHtml(result.current_restored)}
+                    </div>`;
+        } else if (result.current_notice) {
+            content += `
+                    <div class="summary-item" style="margin-bottom: 0.5rem; color: #ffc107;">
+                        <strong>Active profile not restored:</strong> ${this.escapeHtml(result.current_notice)}
+                    </div>`;
+        }
+        
         content += '</div>';
         
         if (result.profiles_imported && result.profiles_imported.length > 0) {
@@ -2130,8 +2485,8 @@ class CCSwitch {
                 const dismissBtn = document.getElementById('update-dismiss');
                 
                 if (banner && currentSpan && latestSpan) {
-                    currentSpan.textContent = `v${data.current_version}`;
-                    latestSpan.textContent = `v${data.latest_version}`;
+                    currentSpan.textContent = `v${data.current}`;
+                    latestSpan.textContent = `v${data.latest}`;
                     banner.style.display = 'block';
                     
                     // Setup dismiss button
@@ -2139,13 +2494,13 @@ class CCSwitch {
                         dismissBtn.addEventListener('click', () => {
                             banner.style.display = 'none';
                             // Store dismissal in session storage
-                            sessionStorage.setItem('update-dismissed', data.latest_version);
+                            sessionStorage.setItem('update-dismissed', data.latest);
                         });
                     }
                     
                     // Check if already dismissed this version
                     const dismissedVersion = sessionStorage.getItem('update-dismissed');
-                    if (dismissedVersion === data.latest_version) {
+                    if (dismissedVersion === data.latest) {
                         banner.style.display = 'none';
                     }
                 }
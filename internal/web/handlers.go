@@ -2,24 +2,29 @@ package web
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
-	"cc-switch/internal/common"
-	"cc-switch/internal/config"
-	"cc-switch/internal/export"
-	"cc-switch/internal/handler"
-	importpkg "cc-switch/internal/import"
+	"github.com/HoBeedzc/cc-switch/internal/common"
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/export"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
+	importpkg "github.com/HoBeedzc/cc-switch/internal/import"
 )
 
 // APIHandler handles API requests
 type APIHandler struct {
 	handler handler.ConfigHandler
+	// scanning is set while a /api/test/all health scan is running
+	scanning atomic.Bool
 }
 
 // validateTemplateName validates template names to prevent path traversal attacks
@@ -51,9 +56,21 @@ type APIResponse struct {
 	Success bool        `json:"success"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	Code    string      `json:"code,omitempty"` // machine-readable error code
 	Message string      `json:"message,omitempty"`
 }
 
+// Error codes returned in APIResponse.Code
+const (
+	codeProfileIsCurrent  = "profile_is_current"
+	codeEmptyModeActive   = "empty_mode_active"
+	codeEmptyModeInactive = "empty_mode_inactive"
+	codeEmptyProfileName  = "empty_profile_name"
+)
+
+// emptyModeEndpointHint points /api/switch callers at the explicit empty mode endpoints
+const emptyModeEndpointHint = "Use POST /api/empty-mode to enter empty mode and DELETE /api/empty-mode to restore"
+
 // HandleProfiles handles /api/profiles requests
 func (api *APIHandler) HandleProfiles(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
@@ -97,6 +114,8 @@ func (api *APIHandler) handleSingleProfile(w http.ResponseWriter, r *http.Reques
 		api.getProfile(w, r, profileName)
 	case http.MethodPut:
 		api.updateProfile(w, r, profileName)
+	case http.MethodPatch:
+		api.patchProfile(w, r, profileName)
 	case http.MethodDelete:
 		api.deleteProfile(w, r, profileName)
 	default:
@@ -111,6 +130,8 @@ func (api *APIHandler) handleProfileOperation(w http.ResponseWriter, r *http.Req
 		api.moveProfile(w, r, profileName)
 	case "copy":
 		api.copyProfile(w, r, profileName)
+	case "permissions":
+		api.profilePermissions(w, r, profileName)
 	default:
 		api.sendError(w, fmt.Sprintf("Unknown operation: %s", operation), http.StatusBadRequest)
 	}
@@ -166,18 +187,29 @@ func (api *APIHandler) HandleSwitch(w http.ResponseWriter, r *http.Request) {
 	var message string
 
 	if request.Restore {
+		// Deprecated: kept for one release so older pages keep working
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Warning", `299 - "{\"restore\": true} on /api/switch is deprecated; use DELETE /api/empty-mode"`)
 		err = api.handler.RestoreFromEmptyMode()
 		message = "Configuration restored from empty mode"
-	} else if request.Profile == "" {
-		err = api.handler.UseEmptyMode()
-		message = "Switched to empty mode"
+	} else if strings.TrimSpace(request.Profile) == "" {
+		// An empty profile used to mean "enter empty mode", so a blank form value wiped settings.json
+		api.sendJSON(w, APIResponse{
+			Success: false,
+			Error:   "Profile name is required",
+			Code:    codeEmptyProfileName,
+			Data: map[string]interface{}{
+				"hint": emptyModeEndpointHint,
+			},
+		}, http.StatusUnprocessableEntity)
+		return
 	} else {
 		err = api.handler.UseConfig(request.Profile)
 		message = fmt.Sprintf("Switched to configuration: %s", request.Profile)
 	}
 
 	if err != nil {
-		api.sendError(w, fmt.Sprintf("Failed to switch configuration: %v", err), http.StatusInternalServerError)
+		api.sendError(w, fmt.Sprintf("Failed to switch configuration: %v", err), errorStatus(err))
 		return
 	}
 
@@ -187,6 +219,80 @@ func (api *APIHandler) HandleSwitch(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// HandleEmptyMode handles /api/empty-mode: GET reports the status, POST enters empty mode
+// and DELETE restores the previous configuration. Asking for the state that is already
+// in effect returns 409 so clients can tell a no-op from a transition.
+func (api *APIHandler) HandleEmptyMode(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		api.getEmptyModeStatus(w)
+	case http.MethodPost:
+		api.enterEmptyMode(w)
+	case http.MethodDelete:
+		api.leaveEmptyMode(w)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// getEmptyModeStatus returns the empty mode status
+func (api *APIHandler) getEmptyModeStatus(w http.ResponseWriter) {
+	status, err := api.handler.GetEmptyModeStatus()
+	if err != nil {
+		api.sendError(w, fmt.Sprintf("Failed to get empty mode status: %v", err), http.StatusInternalServerError)
+		return
+	}
+	api.sendSuccess(w, status)
+}
+
+// enterEmptyMode moves settings.json aside; 409 when empty mode is already active
+func (api *APIHandler) enterEmptyMode(w http.ResponseWriter) {
+	if api.handler.IsEmptyMode() {
+		api.sendJSON(w, APIResponse{
+			Success: false,
+			Error:   "Empty mode is already active",
+			Code:    codeEmptyModeActive,
+		}, http.StatusConflict)
+		return
+	}
+
+	if err := api.handler.UseEmptyMode(); err != nil {
+		api.sendError(w, fmt.Sprintf("Failed to enter empty mode: %v", err), http.StatusInternalServerError)
+		return
+	}
+	api.sendEmptyModeStatus(w, "Switched to empty mode")
+}
+
+// leaveEmptyMode restores settings.json; 409 when empty mode is not active
+func (api *APIHandler) leaveEmptyMode(w http.ResponseWriter) {
+	if !api.handler.IsEmptyMode() {
+		api.sendJSON(w, APIResponse{
+			Success: false,
+			Error:   "Empty mode is not active",
+			Code:    codeEmptyModeInactive,
+		}, http.StatusConflict)
+		return
+	}
+
+	if err := api.handler.RestoreFromEmptyMode(); err != nil {
+		api.sendError(w, fmt.Sprintf("Failed to restore from empty mode: %v", err), http.StatusInternalServerError)
+		return
+	}
+	api.sendEmptyModeStatus(w, "Configuration restored from empty mode")
+}
+
+// sendEmptyModeStatus responds to an empty mode transition with the new status
+func (api *APIHandler) sendEmptyModeStatus(w http.ResponseWriter, message string) {
+	data := map[string]interface{}{"message": message}
+	if status, err := api.handler.GetEmptyModeStatus(); err == nil {
+		data["status"] = status
+	}
+	api.sendSuccess(w, data)
+}
+
+// maxTestTimeoutSeconds caps the per-endpoint timeout accepted by /api/test
+const maxTestTimeoutSeconds = 120
+
 // HandleTest handles /api/test requests
 func (api *APIHandler) HandleTest(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -205,6 +311,11 @@ func (api *APIHandler) HandleTest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if request.Timeout < 0 || request.Timeout > maxTestTimeoutSeconds {
+		api.sendError(w, fmt.Sprintf("timeout must be between 0 and %d seconds", maxTestTimeoutSeconds), http.StatusBadRequest)
+		return
+	}
+
 	options := handler.TestOptions{
 		Quick:   request.Quick,
 		Timeout: time.Duration(request.Timeout) * time.Second,
@@ -217,10 +328,16 @@ func (api *APIHandler) HandleTest(w http.ResponseWriter, r *http.Request) {
 	var result *handler.APITestResult
 	var err error
 
+	// The request context is cancelled when the client disconnects, which also stops
+	// a running Claude CLI chat test instead of leaving the process behind
 	if request.Profile == "" {
-		result, err = api.handler.TestCurrentConfiguration(options)
+		result, err = api.handler.TestCurrentConfiguration(r.Context(), options)
 	} else {
-		result, err = api.handler.TestAPIConnectivity(request.Profile, options)
+		result, err = api.handler.TestAPIConnectivity(r.Context(), request.Profile, options)
+	}
+
+	if r.Context().Err() != nil {
+		return // client went away; nobody is left to read the response
 	}
 
 	if err != nil {
@@ -231,6 +348,94 @@ func (api *APIHandler) HandleTest(w http.ResponseWriter, r *http.Request) {
 	api.sendSuccess(w, result)
 }
 
+// Bounds of the concurrency accepted by /api/test/all
+const (
+	defaultScanConcurrency = 4
+	maxScanConcurrency     = 8
+)
+
+// HandleTestAll handles GET /api/test/all, a health scan of every configuration streamed as
+// server-sent events: "start" lists the profiles, "result" carries each APITestResult as it
+// completes and "done" the totals. Closing the connection cancels the scan. Only one scan
+// runs at a time.
+func (api *APIHandler) HandleTestAll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	timeout, err := parseNonNegative(query, "timeout")
+	if err != nil || timeout > maxTestTimeoutSeconds {
+		api.sendError(w, fmt.Sprintf("timeout must be between 0 and %d seconds", maxTestTimeoutSeconds), http.StatusBadRequest)
+		return
+	}
+	concurrency, err := parseNonNegative(query, "concurrency")
+	if err != nil || concurrency > maxScanConcurrency {
+		api.sendError(w, fmt.Sprintf("concurrency must be between 1 and %d", maxScanConcurrency), http.StatusBadRequest)
+		return
+	}
+
+	options := handler.TestOptions{
+		Quick:       query.Get("quick") == "true",
+		Timeout:     time.Duration(timeout) * time.Second,
+		Concurrency: concurrency,
+	}
+	if options.Timeout == 0 {
+		options.Timeout = 10 * time.Second
+	}
+	if options.Concurrency == 0 {
+		options.Concurrency = defaultScanConcurrency
+	}
+
+	if !api.scanning.CompareAndSwap(false, true) {
+		api.sendError(w, "A health scan is already running", http.StatusConflict)
+		return
+	}
+	defer api.scanning.Store(false)
+
+	profiles, err := api.handler.ListConfigs()
+	if err != nil {
+		api.sendError(w, fmt.Sprintf("Failed to list profiles: %v", err), http.StatusInternalServerError)
+		return
+	}
+	names := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		names = append(names, profile.Name)
+	}
+
+	sendEvent := api.startSSE(w)
+	sendEvent("start", map[string]interface{}{
+		"profiles":    names,
+		"concurrency": options.Concurrency,
+	})
+
+	// The request context is cancelled when the client disconnects; the scan then stops
+	// its running tests, including Claude CLI chat tests, before the handler returns
+	passed, failed, skipped := 0, 0, 0
+	results, _ := api.handler.TestAllConfigurationsWithProgress(r.Context(), options, func(result handler.APITestResult) {
+		switch {
+		case result.Skipped:
+			skipped++
+		case result.IsConnectable:
+			passed++
+		default:
+			failed++
+		}
+		sendEvent("result", result)
+	})
+
+	if r.Context().Err() != nil {
+		return // client went away; nobody is left to read the summary
+	}
+	sendEvent("done", map[string]interface{}{
+		"total":   len(results),
+		"passed":  passed,
+		"failed":  failed,
+		"skipped": skipped,
+	})
+}
+
 // HandleTemplates handles /api/templates requests
 func (api *APIHandler) HandleTemplates(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
@@ -280,6 +485,8 @@ func (api *APIHandler) handleSingleTemplate(w http.ResponseWriter, r *http.Reque
 		api.getTemplate(w, r, templateName)
 	case http.MethodPut:
 		api.updateTemplate(w, r, templateName)
+	case http.MethodPatch:
+		api.patchTemplate(w, r, templateName)
 	case http.MethodDelete:
 		api.deleteTemplate(w, r, templateName)
 	default:
@@ -322,18 +529,170 @@ func (api *APIHandler) HandleHealth(w http.ResponseWriter, r *http.Request) {
 	api.sendSuccess(w, health)
 }
 
+// HandleValidate handles /api/validate requests
+// It validates either submitted content or a stored profile without modifying anything
+// HandleDiff handles GET /api/diff?left=profile:work&right=template:team.
+// Operands are "profile:<name>", "template:<name>" or "settings" (the live
+// settings.json); a bare name is a profile. Secret values are masked on both sides.
+func (api *APIHandler) HandleDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	if query.Get("left") == "" || query.Get("right") == "" {
+		api.sendError(w, "Both 'left' and 'right' are required", http.StatusBadRequest)
+		return
+	}
+
+	left, err := config.ParseDiffOperand(query.Get("left"))
+	if err != nil {
+		api.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	right, err := config.ParseDiffOperand(query.Get("right"))
+	if err != nil {
+		api.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	diffs, err := api.handler.DiffOperands(left, right)
+	if err != nil {
+		api.sendError(w, fmt.Sprintf("Failed to compare: %v", err), http.StatusNotFound)
+		return
+	}
+
+	for i := range diffs {
+		diffs[i].Old = maskDiffValue(diffs[i].Path, diffs[i].Old)
+		diffs[i].New = maskDiffValue(diffs[i].Path, diffs[i].New)
+	}
+
+	api.sendSuccess(w, map[string]interface{}{
+		"left":       left,
+		"right":      right,
+		"empty_mode": api.handler.IsEmptyMode(),
+		"changes":    diffs,
+		"summary":    config.SummarizeDiff(diffs),
+	})
+}
+
+func (api *APIHandler) HandleValidate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Limit request body size slightly above the content limit so oversized content is reported as an issue
+	r.Body = http.MaxBytesReader(w, r.Body, 2*config.MaxContentSize)
+
+	var request struct {
+		Type    string                 `json:"type"`
+		Name    string                 `json:"name,omitempty"`
+		Content map[string]interface{} `json:"content,omitempty"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		api.sendError(w, fmt.Sprintf("Invalid JSON body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if request.Type == "" {
+		request.Type = "profile"
+	}
+	if request.Type != "profile" && request.Type != "template" {
+		api.sendError(w, "Type must be 'profile' or 'template'", http.StatusBadRequest)
+		return
+	}
+
+	var issues []config.ValidationIssue
+	switch {
+	case request.Content != nil:
+		issues = api.handler.ValidateContent(request.Content, request.Type == "template")
+	case request.Name != "":
+		if request.Type != "profile" {
+			api.sendError(w, "Validation by name is only supported for profiles", http.StatusBadRequest)
+			return
+		}
+		var err error
+		issues, err = api.handler.ValidateConfig(request.Name)
+		if err != nil {
+			api.sendError(w, fmt.Sprintf("Failed to validate profile: %v", err), http.StatusNotFound)
+			return
+		}
+	default:
+		api.sendError(w, "Either 'content' or 'name' is required", http.StatusBadRequest)
+		return
+	}
+
+	valid := true
+	for _, issue := range issues {
+		if issue.Severity == config.SeverityError {
+			valid = false
+			break
+		}
+	}
+
+	api.sendSuccess(w, map[string]interface{}{
+		"valid":  valid,
+		"issues": issues,
+	})
+}
+
 // Helper methods
 
 func (api *APIHandler) listProfiles(w http.ResponseWriter, r *http.Request) {
+	var query profileQuery
+	paged := hasProfileQuery(r.URL.Query())
+	if paged {
+		var err error
+		if query, err = parseProfileQuery(r.URL.Query()); err != nil {
+			api.sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
 	profiles, err := api.handler.ListConfigs()
 	if err != nil {
 		api.sendError(w, fmt.Sprintf("Failed to list profiles: %v", err), http.StatusInternalServerError)
 		return
 	}
+	// The badge is informational; an unreadable activity log only hides it
+	_ = api.handler.AttachLastTests(profiles)
 
-	api.sendSuccess(w, map[string]interface{}{
-		"profiles": profiles,
-	})
+	if !paged {
+		// Sort by name so the list does not reorder between fetches
+		sortProfiles(profiles, nil)
+		api.sendSuccess(w, map[string]interface{}{
+			"profiles": profiles,
+		})
+		return
+	}
+
+	response := map[string]interface{}{}
+
+	profiles = filterProfiles(profiles, query.Search)
+
+	var lastUsed map[string]time.Time
+	if query.Sort == sortByLastUsed {
+		entries, err := api.handler.GetHistory()
+		if err == nil && len(entries) > 0 {
+			lastUsed = lastUsedTimes(entries)
+		} else {
+			// Without switch history there is nothing to order by
+			query.Sort = sortByName
+			response["note"] = "switch history unavailable, sorted by name instead of last_used"
+		}
+	}
+	sortProfiles(profiles, lastUsed)
+
+	response["profiles"] = pageProfiles(profiles, query.Offset, query.Limit)
+	response["total"] = len(profiles)
+	response["offset"] = query.Offset
+	response["limit"] = query.Limit
+	response["sort"] = query.Sort
+
+	api.sendSuccess(w, response)
 }
 
 func (api *APIHandler) createProfile(w http.ResponseWriter, r *http.Request) {
@@ -389,31 +748,8 @@ func (api *APIHandler) createProfile(w http.ResponseWriter, r *http.Request) {
 
 		message = fmt.Sprintf("Profile '%s' created successfully with custom content", request.Name)
 	} else {
-		// Create from template
-		template := request.Template
-		if template == "" {
-			template = "default"
-		}
-
-		// Check if template exists
-		templates, err := api.handler.ListTemplates()
-		if err != nil {
-			api.sendError(w, "Failed to list templates", http.StatusInternalServerError)
-			return
-		}
-
-		templateExists := false
-		for _, t := range templates {
-			if t == template {
-				templateExists = true
-				break
-			}
-		}
-
-		if !templateExists && template != "default" {
-			// Fallback to default template
-			template = "default"
-		}
+		// Create from template, resolved the same way as "cc-switch new"
+		template, warning := api.handler.ResolveTemplate(request.Template)
 
 		err = api.handler.CreateConfig(request.Name, template)
 		if err != nil {
@@ -422,12 +758,12 @@ func (api *APIHandler) createProfile(w http.ResponseWriter, r *http.Request) {
 		}
 
 		message = fmt.Sprintf("Profile '%s' created successfully from template '%s'", request.Name, template)
+		if warning != "" {
+			message += " (" + warning + ")"
+		}
 	}
 
-	api.sendSuccess(w, map[string]interface{}{
-		"message": message,
-		"name":    request.Name,
-	})
+	api.sendProfileWriteSuccess(w, request.Name, message)
 }
 
 func (api *APIHandler) getProfile(w http.ResponseWriter, r *http.Request, profileName string) {
@@ -437,6 +773,11 @@ func (api *APIHandler) getProfile(w http.ResponseWriter, r *http.Request, profil
 		return
 	}
 
+	// ?compact=true returns only the content, as single-line JSON without the response envelope
+	if r.URL.Query().Get("compact") == "true" {
+		api.sendJSON(w, view.Content, http.StatusOK)
+		return
+	}
 	api.sendSuccess(w, view)
 }
 
@@ -478,10 +819,7 @@ func (api *APIHandler) updateProfile(w http.ResponseWriter, r *http.Request, pro
 					return
 				}
 
-				api.sendSuccess(w, map[string]interface{}{
-					"message": fmt.Sprintf("Profile '%s' updated successfully", profileName),
-					"name":    profileName,
-				})
+				api.sendProfileWriteSuccess(w, profileName, fmt.Sprintf("Profile '%s' updated successfully", profileName))
 				return
 			}
 		}
@@ -493,10 +831,41 @@ func (api *APIHandler) updateProfile(w http.ResponseWriter, r *http.Request, pro
 		return
 	}
 
-	api.sendSuccess(w, map[string]interface{}{
-		"message": fmt.Sprintf("Profile '%s' updated successfully", profileName),
-		"name":    profileName,
-	})
+	api.sendProfileWriteSuccess(w, profileName, fmt.Sprintf("Profile '%s' updated successfully", profileName))
+}
+
+// readJSONPatch reads an application/json-patch+json request body
+func (api *APIHandler) readJSONPatch(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	contentType := r.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "application/json-patch+json") {
+		api.sendError(w, "Content-Type must be application/json-patch+json", http.StatusUnsupportedMediaType)
+		return nil, false
+	}
+
+	// Limit request body size to 1MB
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		api.sendError(w, "Failed to read request body", http.StatusBadRequest)
+		return nil, false
+	}
+
+	return patch, true
+}
+
+func (api *APIHandler) patchProfile(w http.ResponseWriter, r *http.Request, profileName string) {
+	patch, ok := api.readJSONPatch(w, r)
+	if !ok {
+		return
+	}
+
+	if err := api.handler.PatchConfig(profileName, patch); err != nil {
+		api.sendError(w, fmt.Sprintf("Failed to patch profile: %v", err), http.StatusUnprocessableEntity)
+		return
+	}
+
+	api.sendProfileWriteSuccess(w, profileName, fmt.Sprintf("Profile '%s' patched successfully", profileName))
 }
 
 func (api *APIHandler) deleteProfile(w http.ResponseWriter, r *http.Request, profileName string) {
@@ -506,6 +875,38 @@ func (api *APIHandler) deleteProfile(w http.ResponseWriter, r *http.Request, pro
 
 	json.NewDecoder(r.Body).Decode(&request) // Ignore errors for optional body
 
+	if err := api.handler.ValidateConfigExists(profileName); err != nil {
+		api.sendError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// The active profile can only be deleted by forcing empty mode
+	if api.handler.IsCurrentConfig(profileName) && !api.handler.IsEmptyMode() {
+		if !request.Force {
+			api.sendJSON(w, APIResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Profile '%s' is the current configuration", profileName),
+				Code:    codeProfileIsCurrent,
+				Data: map[string]interface{}{
+					"hint": "Switch to another configuration first, or delete with {\"force\": true} to enter empty mode",
+				},
+			}, http.StatusConflict)
+			return
+		}
+
+		if err := api.handler.DeleteCurrentConfig(); err != nil {
+			api.sendError(w, fmt.Sprintf("Failed to delete profile: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		api.sendSuccess(w, map[string]interface{}{
+			"message":    fmt.Sprintf("Profile '%s' deleted, empty mode enabled", profileName),
+			"name":       profileName,
+			"empty_mode": true,
+		})
+		return
+	}
+
 	err := api.handler.DeleteConfig(profileName, request.Force)
 	if err != nil {
 		api.sendError(w, fmt.Sprintf("Failed to delete profile: %v", err), http.StatusInternalServerError)
@@ -543,15 +944,28 @@ func (api *APIHandler) sendJSON(w http.ResponseWriter, data interface{}, statusC
 // Template helper methods
 
 func (api *APIHandler) listTemplates(w http.ResponseWriter, r *http.Request) {
+	sortBy, err := parseTemplateSort(r.URL.Query())
+	if err != nil {
+		api.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	templates, err := api.handler.ListTemplates()
 	if err != nil {
 		api.sendError(w, fmt.Sprintf("Failed to list templates: %v", err), http.StatusInternalServerError)
 		return
 	}
+	sort.Strings(templates)
 
-	api.sendSuccess(w, map[string]interface{}{
+	response := map[string]interface{}{
 		"templates": templates,
-	})
+		"sort":      sortByName,
+	}
+	if sortBy == sortByCreated {
+		// Templates carry no metadata yet, so there is no creation time to order by
+		response["note"] = "template creation times are not recorded, sorted by name instead of created"
+	}
+	api.sendSuccess(w, response)
 }
 
 func (api *APIHandler) createTemplate(w http.ResponseWriter, r *http.Request) {
@@ -590,10 +1004,7 @@ func (api *APIHandler) createTemplate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	api.sendSuccess(w, map[string]interface{}{
-		"message": fmt.Sprintf("Template '%s' created successfully", request.Name),
-		"name":    request.Name,
-	})
+	api.sendTemplateWriteSuccess(w, request.Name, fmt.Sprintf("Template '%s' created successfully", request.Name))
 }
 
 func (api *APIHandler) getTemplate(w http.ResponseWriter, r *http.Request, templateName string) {
@@ -603,6 +1014,11 @@ func (api *APIHandler) getTemplate(w http.ResponseWriter, r *http.Request, templ
 		return
 	}
 
+	// ?compact=true returns only the content, as single-line JSON without the response envelope
+	if r.URL.Query().Get("compact") == "true" {
+		api.sendJSON(w, view.Content, http.StatusOK)
+		return
+	}
 	api.sendSuccess(w, view)
 }
 
@@ -640,10 +1056,27 @@ func (api *APIHandler) updateTemplate(w http.ResponseWriter, r *http.Request, te
 		return
 	}
 
-	api.sendSuccess(w, map[string]interface{}{
-		"message": fmt.Sprintf("Template '%s' updated successfully", templateName),
-		"name":    templateName,
-	})
+	api.sendTemplateWriteSuccess(w, templateName, fmt.Sprintf("Template '%s' updated successfully", templateName))
+}
+
+func (api *APIHandler) patchTemplate(w http.ResponseWriter, r *http.Request, templateName string) {
+	// Prevent modification of default template
+	if templateName == "default" {
+		api.sendError(w, "Cannot modify the default template", http.StatusForbidden)
+		return
+	}
+
+	patch, ok := api.readJSONPatch(w, r)
+	if !ok {
+		return
+	}
+
+	if err := api.handler.PatchTemplate(templateName, patch); err != nil {
+		api.sendError(w, fmt.Sprintf("Failed to patch template: %v", err), http.StatusUnprocessableEntity)
+		return
+	}
+
+	api.sendTemplateWriteSuccess(w, templateName, fmt.Sprintf("Template '%s' patched successfully", templateName))
 }
 
 func (api *APIHandler) deleteTemplate(w http.ResponseWriter, r *http.Request, templateName string) {
@@ -659,7 +1092,22 @@ func (api *APIHandler) deleteTemplate(w http.ResponseWriter, r *http.Request, te
 
 	json.NewDecoder(r.Body).Decode(&request) // Ignore errors for optional body
 
-	if err := api.handler.DeleteTemplate(templateName); err != nil {
+	// Force detaches configurations that were created from this template
+	deleteTemplate := api.handler.DeleteTemplate
+	if request.Force {
+		deleteTemplate = api.handler.DeleteTemplateDetached
+	}
+
+	if err := deleteTemplate(templateName); err != nil {
+		var inUseErr *config.TemplateInUseError
+		if errors.As(err, &inUseErr) {
+			api.sendJSON(w, APIResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Failed to delete template: %v", err),
+				Data:    map[string]interface{}{"dependents": inUseErr.Dependents},
+			}, http.StatusConflict)
+			return
+		}
 		api.sendError(w, fmt.Sprintf("Failed to delete template: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -789,6 +1237,9 @@ func (api *APIHandler) moveProfile(w http.ResponseWriter, r *http.Request, oldNa
 		return
 	}
 
+	// Renaming the active profile also rewrites settings.json from the renamed file
+	resynced := api.handler.IsCurrentConfig(oldName) && !api.handler.IsEmptyMode()
+
 	// Call the handler to move the profile
 	if err := api.handler.MoveConfig(oldName, request.NewName); err != nil {
 		api.sendError(w, fmt.Sprintf("Failed to move profile: %v", err), http.StatusInternalServerError)
@@ -799,6 +1250,7 @@ func (api *APIHandler) moveProfile(w http.ResponseWriter, r *http.Request, oldNa
 		"message":  fmt.Sprintf("Profile moved from '%s' to '%s' successfully", oldName, request.NewName),
 		"old_name": oldName,
 		"new_name": request.NewName,
+		"resynced": resynced,
 	})
 }
 
@@ -857,15 +1309,13 @@ func (api *APIHandler) copyProfile(w http.ResponseWriter, r *http.Request, sourc
 		return
 	}
 
-	// Get the source profile content
-	sourceView, err := api.handler.ViewConfig(sourceName, false)
-	if err != nil {
+	if err := api.handler.ValidateConfigExists(sourceName); err != nil {
 		api.sendError(w, fmt.Sprintf("Failed to read source profile: %v", err), http.StatusNotFound)
 		return
 	}
 
-	// Create the new profile with the same content
-	if err := api.handler.CreateConfigWithContent(newName, sourceView.Content); err != nil {
+	// Copy the profile so its origin records the source
+	if err := api.handler.CopyConfig(sourceName, newName); err != nil {
 		api.sendError(w, fmt.Sprintf("Failed to create profile copy: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -877,6 +1327,65 @@ func (api *APIHandler) copyProfile(w http.ResponseWriter, r *http.Request, sourc
 	})
 }
 
+// profilePermissions handles GET, POST and DELETE /api/profiles/{name}/permissions.
+// POST adds {"list": "allow"|"deny", "rule": "..."} or replaces both lists with
+// {"from_template": "<name>"}; DELETE removes {"rule": "..."} from both lists.
+func (api *APIHandler) profilePermissions(w http.ResponseWriter, r *http.Request, profileName string) {
+	var request struct {
+		List         string `json:"list"`
+		Rule         string `json:"rule"`
+		FromTemplate string `json:"from_template"`
+	}
+	if r.Method == http.MethodPost || r.Method == http.MethodDelete {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			api.sendError(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	response := map[string]interface{}{"name": profileName}
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		if request.FromTemplate != "" {
+			if request.Rule != "" {
+				api.sendError(w, "from_template cannot be combined with rule", http.StatusBadRequest)
+				return
+			}
+			if _, err := api.handler.CopyTemplatePermissions(profileName, request.FromTemplate); err != nil {
+				api.sendError(w, fmt.Sprintf("Failed to copy permissions: %v", err), errorStatus(err))
+				return
+			}
+			response["message"] = fmt.Sprintf("Permissions copied from template '%s'", request.FromTemplate)
+			break
+		}
+		added, err := api.handler.AddConfigPermission(profileName, request.List, request.Rule)
+		if err != nil {
+			api.sendError(w, fmt.Sprintf("Failed to add permission: %v", err), errorStatus(err))
+			return
+		}
+		response["added"] = added
+	case http.MethodDelete:
+		removedFrom, err := api.handler.RemoveConfigPermission(profileName, request.Rule)
+		if err != nil {
+			api.sendError(w, fmt.Sprintf("Failed to remove permission: %v", err), errorStatus(err))
+			return
+		}
+		response["removed_from"] = removedFrom
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	permissions, err := api.handler.GetConfigPermissions(profileName)
+	if err != nil {
+		api.sendError(w, fmt.Sprintf("Failed to read permissions: %v", err), errorStatus(err))
+		return
+	}
+	response["permissions"] = permissions
+	api.sendSuccess(w, response)
+}
+
 // HandleExport handles /api/export requests
 func (api *APIHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -930,6 +1439,28 @@ func (api *APIHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
 	defer os.Remove(tempFile.Name()) // Clean up temp file
 	defer tempFile.Close()
 
+	// Stream progress as NDJSON when requested; the final line carries the file
+	streaming := r.URL.Query().Get("stream") == "true" || strings.Contains(r.Header.Get("Accept"), "application/x-ndjson")
+	var writeLine func(line interface{})
+	if streaming {
+		writeLine = api.startNDJSON(w)
+		lastPercent := -1
+		exporter.SetProgress(func(progress export.Progress) {
+			// One line per percent keeps the stream short for very large exports
+			if progress.Percent != lastPercent {
+				lastPercent = progress.Percent
+				writeLine(progress)
+			}
+		})
+	}
+	fail := func(message string, status int) {
+		if streaming {
+			writeLine(map[string]interface{}{"done": true, "success": false, "error": message})
+			return
+		}
+		api.sendError(w, message, status)
+	}
+
 	// Perform export based on type
 	var exportErr error
 	var profileCount int
@@ -938,12 +1469,16 @@ func (api *APIHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
 	case "all":
 		profiles, err := cm.ListProfiles()
 		if err != nil {
-			api.sendError(w, fmt.Sprintf("Failed to list profiles: %v", err), http.StatusInternalServerError)
+			fail(fmt.Sprintf("Failed to list profiles: %v", err), http.StatusInternalServerError)
 			return
 		}
-		profileCount = len(profiles)
+		for _, profile := range profiles {
+			if profile.Error == "" {
+				profileCount++
+			}
+		}
 		if profileCount == 0 {
-			api.sendError(w, "No profiles found to export", http.StatusBadRequest)
+			fail("No profiles found to export", http.StatusBadRequest)
 			return
 		}
 		exportErr = exporter.ExportAll(request.Password, tempFile.Name())
@@ -951,11 +1486,11 @@ func (api *APIHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
 	case "current":
 		current, err := cm.GetCurrentProfile()
 		if err != nil {
-			api.sendError(w, fmt.Sprintf("Failed to get current profile: %v", err), http.StatusInternalServerError)
+			fail(fmt.Sprintf("Failed to get current profile: %v", err), http.StatusInternalServerError)
 			return
 		}
 		if current == "" {
-			api.sendError(w, "No current profile set", http.StatusBadRequest)
+			fail("No current profile set", http.StatusBadRequest)
 			return
 		}
 		profileCount = 1
@@ -963,7 +1498,7 @@ func (api *APIHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
 
 	case "single":
 		if !cm.ProfileExists(request.ProfileName) {
-			api.sendError(w, fmt.Sprintf("Profile '%s' does not exist", request.ProfileName), http.StatusNotFound)
+			fail(fmt.Sprintf("Profile '%s' does not exist", request.ProfileName), http.StatusNotFound)
 			return
 		}
 		profileCount = 1
@@ -971,28 +1506,46 @@ func (api *APIHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if exportErr != nil {
-		api.sendError(w, fmt.Sprintf("Export failed: %v", exportErr), http.StatusInternalServerError)
+		fail(fmt.Sprintf("Export failed: %v", exportErr), http.StatusInternalServerError)
 		return
 	}
 
-	// Read the exported file
-	fileData, err := os.ReadFile(tempFile.Name())
+	// Generate filename
+	timestamp := time.Now().Format("20060102_150405")
+	filename := fmt.Sprintf("cc-switch-%s-%s.ccx", request.Type, timestamp)
+
+	if streaming {
+		fileData, err := os.ReadFile(tempFile.Name())
+		if err != nil {
+			fail(fmt.Sprintf("Failed to read export file: %v", err), http.StatusInternalServerError)
+			return
+		}
+		writeLine(map[string]interface{}{
+			"done":    true,
+			"success": true,
+			"data": map[string]interface{}{
+				"filename":      filename,
+				"profile_count": profileCount,
+				"size":          len(fileData),
+				"content":       fileData, // base64-encoded by encoding/json
+			},
+		})
+		return
+	}
+
+	info, err := tempFile.Stat()
 	if err != nil {
 		api.sendError(w, fmt.Sprintf("Failed to read export file: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	// Generate filename
-	timestamp := time.Now().Format("20060102_150405")
-	filename := fmt.Sprintf("cc-switch-%s-%s.ccx", request.Type, timestamp)
-
 	// Set headers for file download
 	w.Header().Set("Content-Type", "application/octet-stream")
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
-	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(fileData)))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", info.Size()))
 
-	// Write file data
-	if _, err := w.Write(fileData); err != nil {
+	// Copy the file without loading it into memory
+	if _, err := io.Copy(w, tempFile); err != nil {
 		// Log the error, but we can't send an API error response at this point
 		fmt.Printf("Failed to write export data: %v\n", err)
 	}
@@ -1078,15 +1631,100 @@ func (api *APIHandler) HandleImport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// List the file's contents without importing anything; options.only filters the profiles
+	if r.URL.Query().Get("list") == "true" {
+		listing, err := importer.List(tempFile.Name(), password, options.Only)
+		if err != nil {
+			api.sendError(w, fmt.Sprintf("Cannot list import file: %v", err), errorStatus(err))
+			return
+		}
+		api.sendSuccess(w, listing)
+		return
+	}
+
+	// Stream per-profile progress as NDJSON when requested, otherwise reply once when done
+	if r.URL.Query().Get("stream") == "true" || strings.Contains(r.Header.Get("Accept"), "application/x-ndjson") {
+		api.streamImport(w, importer, tempFile.Name(), password, options, metadata)
+		return
+	}
+
 	// Perform import
 	result, err := importer.Import(tempFile.Name(), password, options)
 	if err != nil {
-		api.sendError(w, fmt.Sprintf("Import failed: %v", err), http.StatusInternalServerError)
+		api.sendError(w, fmt.Sprintf("Import failed: %v", err), errorStatus(err))
 		return
 	}
 
-	// Prepare response data
-	responseData := map[string]interface{}{
+	api.sendSuccess(w, importResponseData(result, options, metadata))
+}
+
+// streamImport performs an import and writes one NDJSON line per processed profile.
+// The final line is {"done": true, "success": ..., "data"|"error": ...} so clients
+// can use the same result rendering as the non-streaming response.
+func (api *APIHandler) streamImport(w http.ResponseWriter, importer *importpkg.ImporterImpl, path, password string, options importpkg.ImportOptions, metadata *export.CCXMetadata) {
+	writeLine := api.startNDJSON(w)
+
+	result, err := importer.ImportWithProgress(path, password, options, func(progress importpkg.ProfileProgress) {
+		writeLine(progress)
+	})
+	if err != nil {
+		writeLine(map[string]interface{}{
+			"done":    true,
+			"success": false,
+			"error":   fmt.Sprintf("Import failed: %v", err),
+		})
+		return
+	}
+
+	writeLine(map[string]interface{}{
+		"done":    true,
+		"success": true,
+		"data":    importResponseData(result, options, metadata),
+	})
+}
+
+// startNDJSON starts an NDJSON response and returns a function that writes and
+// flushes one line per call
+func (api *APIHandler) startNDJSON(w http.ResponseWriter) func(line interface{}) {
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	return func(line interface{}) {
+		encoder.Encode(line)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// startSSE starts a server-sent events response and returns a function that writes
+// and flushes one event per call. The write deadline is lifted because the stream
+// stays open for as long as the operation runs.
+func (api *APIHandler) startSSE(w http.ResponseWriter) func(event string, data interface{}) {
+	controller := http.NewResponseController(w)
+	controller.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	return func(event string, data interface{}) {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+		controller.Flush()
+	}
+}
+
+// importResponseData builds the import summary returned to web clients
+func importResponseData(result *importpkg.ImportResult, options importpkg.ImportOptions, metadata *export.CCXMetadata) map[string]interface{} {
+	return map[string]interface{}{
 		"total_profiles":    result.Summary.TotalProfiles,
 		"imported_count":    result.Summary.ImportedCount,
 		"skipped_count":     result.Summary.SkippedCount,
@@ -1097,34 +1735,40 @@ func (api *APIHandler) HandleImport(w http.ResponseWriter, r *http.Request) {
 		"errors":            result.Errors,
 		"dry_run":           options.DryRun,
 		"metadata":          metadata,
+		"current_restored":  result.CurrentRestored,
+		"current_notice":    result.CurrentNotice,
 	}
-
-	api.sendSuccess(w, responseData)
 }
 
 // HandleVersion handles /api/version requests
+// It returns {current, latest, has_update} from the update check cache;
+// pass ?check=true to force a synchronous check against GitHub first
 func (api *APIHandler) HandleVersion(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Get current version
-	currentVersion := common.Version
+	if r.URL.Query().Get("check") == "true" {
+		result := common.CheckUpdateNow()
+		if result.Error != nil {
+			api.sendError(w, fmt.Sprintf("Failed to check for updates: %v", result.Error), http.StatusBadGateway)
+			return
+		}
+	}
 
 	// Get cached update info (don't make network request)
 	updateInfo := common.GetCachedUpdateInfo()
 
 	response := map[string]interface{}{
-		"current_version": currentVersion,
+		"current":    common.Version,
+		"latest":     common.Version,
+		"has_update": false,
 	}
 
 	if updateInfo != nil {
-		response["latest_version"] = updateInfo.LatestVersion
+		response["latest"] = updateInfo.LatestVersion
 		response["has_update"] = updateInfo.HasUpdate
-	} else {
-		response["latest_version"] = currentVersion
-		response["has_update"] = false
 	}
 
 	api.sendSuccess(w, response)
@@ -0,0 +1,592 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/config/configtest"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
+	"github.com/HoBeedzc/cc-switch/internal/testutil"
+)
+
+// newTestAPI creates an API handler on a configuration manager in a temporary home
+func newTestAPI(t *testing.T) (*APIHandler, *config.ConfigManager) {
+	t.Helper()
+	home := testutil.SetupHome(t)
+	if err := os.MkdirAll(filepath.Join(home, ".claude"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cm := configtest.NewManagerInCurrentHome(t, config.Options{})
+	return &APIHandler{handler: handler.NewConfigHandler(cm)}, cm
+}
+
+// serve sends a JSON request to an API handler and decodes the response envelope
+func serve(t *testing.T, handle http.HandlerFunc, method, path string, body interface{}) (*httptest.ResponseRecorder, APIResponse) {
+	t.Helper()
+	var reader bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reader).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	request := httptest.NewRequest(method, path, &reader)
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	handle(recorder, request)
+
+	var response APIResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("%s %s: response is not JSON: %s", method, path, recorder.Body)
+	}
+	return recorder, response
+}
+
+// dataObject returns a member of the response data as an object
+func dataObject(t *testing.T, response APIResponse, key string) map[string]interface{} {
+	t.Helper()
+	data, ok := response.Data.(map[string]interface{})
+	if !ok {
+		t.Fatalf("response data is %T, want an object", response.Data)
+	}
+	object, ok := data[key].(map[string]interface{})
+	if !ok {
+		t.Fatalf("response data has no %q object: %v", key, data)
+	}
+	return object
+}
+
+const testToken = "sk-ant-REDACTED"
+
+func TestCreateProfileReturnsStoredProfile(t *testing.T) {
+	api, cm := newTestAPI(t)
+
+	recorder, response := serve(t, api.HandleProfiles, http.MethodPost, "/api/profiles", map[string]interface{}{
+		"name": "work",
+		"content": map[string]interface{}{
+			"env": map[string]string{
+				"ANTHROPIC_AUTH_TOKEN": testToken,
+				"ANTHROPIC_BASE_URL":   "https://api.example.com",
+			},
+		},
+	})
+	if recorder.Code != http.StatusOK || !response.Success {
+		t.Fatalf("create: status %d, response %+v", recorder.Code, response)
+	}
+
+	profile := dataObject(t, response, "profile")
+	if profile["name"] != "work" || profile["is_current"] != false {
+		t.Errorf("profile = %v, want work and not current", profile)
+	}
+	if path, _ := profile["path"].(string); filepath.Base(path) != "work.json" {
+		t.Errorf("path = %v, want the stored work.json", profile["path"])
+	}
+
+	env := profile["content"].(map[string]interface{})["env"].(map[string]interface{})
+	if env["ANTHROPIC_AUTH_TOKEN"] != maskSecret(testToken) {
+		t.Errorf("token = %v, want it masked", env["ANTHROPIC_AUTH_TOKEN"])
+	}
+	if env["ANTHROPIC_BASE_URL"] != "https://api.example.com" {
+		t.Errorf("base URL = %v, want it unmasked", env["ANTHROPIC_BASE_URL"])
+	}
+
+	// The etag identifies the stored, unmasked content
+	stored, _, err := cm.GetProfileContent("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile["etag"] != contentETag(stored) {
+		t.Errorf("etag = %v, want %s", profile["etag"], contentETag(stored))
+	}
+	if recorder.Header().Get("ETag") != profile["etag"] {
+		t.Errorf("ETag header = %q, want %v", recorder.Header().Get("ETag"), profile["etag"])
+	}
+}
+
+func TestUpdateProfileReturnsStoredProfile(t *testing.T) {
+	api, cm := newTestAPI(t)
+	if err := cm.CreateProfileWithContent("work", map[string]interface{}{
+		"env": map[string]interface{}{"ANTHROPIC_AUTH_TOKEN": testToken},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	before, _, _ := cm.GetProfileContent("work")
+
+	recorder, response := serve(t, api.HandleProfile, http.MethodPut, "/api/profiles/work", map[string]interface{}{
+		"env": map[string]interface{}{
+			"ANTHROPIC_AUTH_TOKEN": testToken,
+			"ANTHROPIC_BASE_URL":   "https://proxy.example.com",
+		},
+	})
+	if recorder.Code != http.StatusOK || !response.Success {
+		t.Fatalf("update: status %d, response %+v", recorder.Code, response)
+	}
+
+	profile := dataObject(t, response, "profile")
+	env := profile["content"].(map[string]interface{})["env"].(map[string]interface{})
+	if env["ANTHROPIC_BASE_URL"] != "https://proxy.example.com" {
+		t.Errorf("response does not contain the update: %v", env)
+	}
+	if env["ANTHROPIC_AUTH_TOKEN"] == testToken {
+		t.Error("response contains the unmasked token")
+	}
+	if profile["etag"] == contentETag(before) {
+		t.Error("etag did not change with the content")
+	}
+}
+
+func TestTemplateWritesReturnStoredTemplate(t *testing.T) {
+	api, _ := newTestAPI(t)
+
+	recorder, response := serve(t, api.HandleTemplates, http.MethodPost, "/api/templates", map[string]string{"name": "team"})
+	if recorder.Code != http.StatusOK || !response.Success {
+		t.Fatalf("create: status %d, response %+v", recorder.Code, response)
+	}
+	created := dataObject(t, response, "template")
+	if created["name"] != "team" || created["etag"] == "" || created["content"] == nil {
+		t.Fatalf("template = %v, want name, content and etag", created)
+	}
+
+	recorder, response = serve(t, api.HandleTemplateRoutes, http.MethodPut, "/api/templates/team", map[string]interface{}{
+		"env": map[string]interface{}{"ANTHROPIC_AUTH_TOKEN": testToken},
+	})
+	if recorder.Code != http.StatusOK || !response.Success {
+		t.Fatalf("update: status %d, response %+v", recorder.Code, response)
+	}
+	updated := dataObject(t, response, "template")
+	env := updated["content"].(map[string]interface{})["env"].(map[string]interface{})
+	if env["ANTHROPIC_AUTH_TOKEN"] != maskSecret(testToken) {
+		t.Errorf("token = %v, want it masked", env["ANTHROPIC_AUTH_TOKEN"])
+	}
+	if updated["etag"] == created["etag"] {
+		t.Error("etag did not change with the content")
+	}
+	if recorder.Header().Get("ETag") != updated["etag"] {
+		t.Errorf("ETag header = %q, want %v", recorder.Header().Get("ETag"), updated["etag"])
+	}
+}
+
+func TestMaskContent(t *testing.T) {
+	content := map[string]interface{}{
+		"env": map[string]interface{}{
+			"ANTHROPIC_AUTH_TOKEN": testToken,
+			"ANTHROPIC_API_KEY":    "short",
+			"ANTHROPIC_BASE_URL":   "https://api.example.com",
+		},
+		"model": "opus",
+	}
+
+	masked := maskContent(content)
+	env := masked["env"].(map[string]interface{})
+	tests := []struct {
+		key  string
+		want interface{}
+	}{
+		{key: "ANTHROPIC_AUTH_TOKEN", want: "********cdef"},
+		{key: "ANTHROPIC_API_KEY", want: "*****"},
+		{key: "ANTHROPIC_BASE_URL", want: "https://api.example.com"},
+	}
+	for _, tt := range tests {
+		if env[tt.key] != tt.want {
+			t.Errorf("%s = %v, want %v", tt.key, env[tt.key], tt.want)
+		}
+	}
+	if masked["model"] != "opus" {
+		t.Errorf("model = %v, want it unchanged", masked["model"])
+	}
+
+	// The original content is left untouched
+	if content["env"].(map[string]interface{})["ANTHROPIC_AUTH_TOKEN"] != testToken {
+		t.Error("maskContent modified its input")
+	}
+}
+
+// setupCurrentProfile creates the work and home profiles and switches to work
+func setupCurrentProfile(t *testing.T, cm *config.ConfigManager) {
+	t.Helper()
+	for _, name := range []string{"work", "home"} {
+		if err := cm.CreateProfileWithContent(name, map[string]interface{}{"model": name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cm.UseProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readSettings decodes the active settings.json
+func readSettings(t *testing.T, cm *config.ConfigManager) map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(cm.GetSettingsFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var settings map[string]interface{}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatal(err)
+	}
+	return settings
+}
+
+func TestDeleteCurrentProfileIsRejected(t *testing.T) {
+	api, cm := newTestAPI(t)
+	setupCurrentProfile(t, cm)
+
+	recorder, response := serve(t, api.HandleProfile, http.MethodDelete, "/api/profiles/work", nil)
+	if recorder.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusConflict)
+	}
+	if response.Code != codeProfileIsCurrent {
+		t.Errorf("code = %q, want %q", response.Code, codeProfileIsCurrent)
+	}
+	if data, _ := response.Data.(map[string]interface{}); data["hint"] == nil {
+		t.Errorf("data = %v, want a hint", response.Data)
+	}
+	if !cm.ProfileExists("work") || cm.IsEmptyMode() {
+		t.Error("rejected delete changed the profiles")
+	}
+}
+
+func TestForceDeleteCurrentProfileEntersEmptyMode(t *testing.T) {
+	api, cm := newTestAPI(t)
+	setupCurrentProfile(t, cm)
+
+	recorder, response := serve(t, api.HandleProfile, http.MethodDelete, "/api/profiles/work", map[string]bool{"force": true})
+	if recorder.Code != http.StatusOK || !response.Success {
+		t.Fatalf("status %d, response %+v", recorder.Code, response)
+	}
+	if data, _ := response.Data.(map[string]interface{}); data["empty_mode"] != true {
+		t.Errorf("data = %v, want empty_mode", response.Data)
+	}
+	if cm.ProfileExists("work") {
+		t.Error("work still exists")
+	}
+	if !cm.IsEmptyMode() {
+		t.Error("empty mode is not enabled")
+	}
+	if current, _ := cm.GetCurrentProfile(); current != "" {
+		t.Errorf("current = %q, want none", current)
+	}
+}
+
+func TestDeleteOtherProfile(t *testing.T) {
+	api, cm := newTestAPI(t)
+	setupCurrentProfile(t, cm)
+
+	recorder, response := serve(t, api.HandleProfile, http.MethodDelete, "/api/profiles/home", nil)
+	if recorder.Code != http.StatusOK || !response.Success {
+		t.Fatalf("status %d, response %+v", recorder.Code, response)
+	}
+	if cm.ProfileExists("home") {
+		t.Error("home still exists")
+	}
+	if current, _ := cm.GetCurrentProfile(); current != "work" {
+		t.Errorf("current = %q, want work", current)
+	}
+}
+
+func TestMoveProfileResyncsCurrent(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		resynced bool
+		current  string
+	}{
+		{name: "current profile", from: "work", resynced: true, current: "job"},
+		{name: "other profile", from: "home", resynced: false, current: "work"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, cm := newTestAPI(t)
+			setupCurrentProfile(t, cm)
+
+			recorder, response := serve(t, api.HandleProfile, http.MethodPost, "/api/profiles/"+tt.from+"/move", map[string]string{"new_name": "job"})
+			if recorder.Code != http.StatusOK || !response.Success {
+				t.Fatalf("status %d, response %+v", recorder.Code, response)
+			}
+			if data, _ := response.Data.(map[string]interface{}); data["resynced"] != tt.resynced {
+				t.Errorf("resynced = %v, want %v", data["resynced"], tt.resynced)
+			}
+			if current, _ := cm.GetCurrentProfile(); current != tt.current {
+				t.Errorf("current = %q, want %q", current, tt.current)
+			}
+			if settings := readSettings(t, cm); settings["model"] != "work" {
+				t.Errorf("settings model = %v, want work", settings["model"])
+			}
+		})
+	}
+}
+
+func TestUpdateCurrentProfileSyncsSettings(t *testing.T) {
+	api, cm := newTestAPI(t)
+	setupCurrentProfile(t, cm)
+
+	recorder, response := serve(t, api.HandleProfile, http.MethodPut, "/api/profiles/work", map[string]interface{}{"model": "updated"})
+	if recorder.Code != http.StatusOK || !response.Success {
+		t.Fatalf("status %d, response %+v", recorder.Code, response)
+	}
+	if profile := dataObject(t, response, "profile"); profile["is_current"] != true {
+		t.Errorf("is_current = %v, want true", profile["is_current"])
+	}
+	if settings := readSettings(t, cm); settings["model"] != "updated" {
+		t.Errorf("settings model = %v, want the update", settings["model"])
+	}
+}
+
+func TestCreateProfileResolvesTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		warning  bool
+	}{
+		{name: "setting", want: "team"},
+		{name: "explicit", template: "default", want: "default"},
+		{name: "missing explicit", template: "gone", want: "team", warning: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, cm := newTestAPI(t)
+			if err := cm.CreateTemplate("team"); err != nil {
+				t.Fatal(err)
+			}
+			if err := cm.SetDefaultTemplate("team"); err != nil {
+				t.Fatal(err)
+			}
+
+			recorder, response := serve(t, api.HandleProfiles, http.MethodPost, "/api/profiles", map[string]string{"name": "work", "template": tt.template})
+			if recorder.Code != http.StatusOK || !response.Success {
+				t.Fatalf("status %d, response %+v", recorder.Code, response)
+			}
+
+			meta, err := cm.GetProfileMetadata("work")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if meta.Template != tt.want {
+				t.Errorf("template = %q, want %q", meta.Template, tt.want)
+			}
+			message, _ := response.Data.(map[string]interface{})["message"].(string)
+			if strings.Contains(message, "not found") != tt.warning {
+				t.Errorf("message = %q, warning expected: %v", message, tt.warning)
+			}
+		})
+	}
+}
+
+func TestEmptyModeTransitions(t *testing.T) {
+	api, cm := newTestAPI(t)
+	setupCurrentProfile(t, cm)
+
+	status := func() map[string]interface{} {
+		t.Helper()
+		recorder, response := serve(t, api.HandleEmptyMode, http.MethodGet, "/api/empty-mode", nil)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("GET: status %d, response %+v", recorder.Code, response)
+		}
+		return response.Data.(map[string]interface{})
+	}
+
+	if got := status(); got["enabled"] != false {
+		t.Fatalf("status = %v, want disabled", got)
+	}
+
+	// Leaving empty mode when it is not active is a conflict
+	recorder, response := serve(t, api.HandleEmptyMode, http.MethodDelete, "/api/empty-mode", nil)
+	if recorder.Code != http.StatusConflict || response.Code != codeEmptyModeInactive {
+		t.Errorf("DELETE while inactive: status %d, code %q", recorder.Code, response.Code)
+	}
+
+	recorder, response = serve(t, api.HandleEmptyMode, http.MethodPost, "/api/empty-mode", nil)
+	if recorder.Code != http.StatusOK || !response.Success {
+		t.Fatalf("POST: status %d, response %+v", recorder.Code, response)
+	}
+	if got := dataObject(t, response, "status"); got["enabled"] != true || got["previous_profile"] != "work" {
+		t.Errorf("status after POST = %v, want enabled with work to restore", got)
+	}
+	if _, err := os.Stat(cm.GetSettingsFile()); !os.IsNotExist(err) {
+		t.Errorf("settings.json still exists in empty mode: %v", err)
+	}
+	if got := status(); got["enabled"] != true || got["can_restore"] != true {
+		t.Errorf("status = %v, want enabled and restorable", got)
+	}
+
+	// Entering again is a conflict and changes nothing
+	recorder, response = serve(t, api.HandleEmptyMode, http.MethodPost, "/api/empty-mode", nil)
+	if recorder.Code != http.StatusConflict || response.Code != codeEmptyModeActive {
+		t.Errorf("POST while active: status %d, code %q", recorder.Code, response.Code)
+	}
+
+	recorder, response = serve(t, api.HandleEmptyMode, http.MethodDelete, "/api/empty-mode", nil)
+	if recorder.Code != http.StatusOK || !response.Success {
+		t.Fatalf("DELETE: status %d, response %+v", recorder.Code, response)
+	}
+	if got := dataObject(t, response, "status"); got["enabled"] != false {
+		t.Errorf("status after DELETE = %v, want disabled", got)
+	}
+	if settings := readSettings(t, cm); settings["model"] != "work" {
+		t.Errorf("settings model = %v, want work restored", settings["model"])
+	}
+
+	recorder = httptest.NewRecorder()
+	api.HandleEmptyMode(recorder, httptest.NewRequest(http.MethodPut, "/api/empty-mode", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT: status %d, want %d", recorder.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestSwitchRejectsEmptyProfile(t *testing.T) {
+	for _, profile := range []string{"", "  "} {
+		t.Run(fmt.Sprintf("%q", profile), func(t *testing.T) {
+			api, cm := newTestAPI(t)
+			setupCurrentProfile(t, cm)
+
+			recorder, response := serve(t, api.HandleSwitch, http.MethodPost, "/api/switch", map[string]interface{}{"profile": profile})
+			if recorder.Code != http.StatusUnprocessableEntity || response.Code != codeEmptyProfileName {
+				t.Fatalf("status %d, code %q, want %d %s", recorder.Code, response.Code, http.StatusUnprocessableEntity, codeEmptyProfileName)
+			}
+			if data, _ := response.Data.(map[string]interface{}); data["hint"] != emptyModeEndpointHint {
+				t.Errorf("data = %v, want the empty mode endpoint hint", response.Data)
+			}
+			if settings := readSettings(t, cm); settings["model"] != "work" {
+				t.Errorf("settings model = %v, want settings.json untouched", settings["model"])
+			}
+		})
+	}
+}
+
+func TestSwitchErrorStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		want    int
+	}{
+		{"unknown profile", "missing", http.StatusNotFound},
+		{"locked secrets store", "vault", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, cm := newTestAPI(t)
+			setupCurrentProfile(t, cm)
+			// A profile whose token lives in a secrets store this server has no passphrase for
+			writer := configtest.NewManagerInCurrentHome(t, config.Options{})
+			writer.SetSecretsPassphrase("passphrase")
+			if err := writer.SetSecret("vault-token", "sk-vault"); err != nil {
+				t.Fatal(err)
+			}
+			if err := cm.CreateProfileWithContent("vault", map[string]interface{}{
+				"env": map[string]interface{}{"ANTHROPIC_AUTH_TOKEN": "@secret:vault-token"},
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			recorder, response := serve(t, api.HandleSwitch, http.MethodPost, "/api/switch", map[string]interface{}{"profile": tt.profile})
+			if recorder.Code != tt.want || response.Success {
+				t.Fatalf("status %d, response %+v, want %d", recorder.Code, response, tt.want)
+			}
+			if current, _ := cm.GetCurrentProfile(); current != "work" {
+				t.Errorf("current = %q, want work", current)
+			}
+		})
+	}
+}
+
+func TestSwitchRestoreIsDeprecated(t *testing.T) {
+	api, cm := newTestAPI(t)
+	setupCurrentProfile(t, cm)
+	if _, response := serve(t, api.HandleEmptyMode, http.MethodPost, "/api/empty-mode", nil); !response.Success {
+		t.Fatalf("POST /api/empty-mode: %+v", response)
+	}
+
+	recorder, response := serve(t, api.HandleSwitch, http.MethodPost, "/api/switch", map[string]interface{}{"restore": true})
+	if recorder.Code != http.StatusOK || !response.Success {
+		t.Fatalf("restore: status %d, response %+v", recorder.Code, response)
+	}
+	if recorder.Header().Get("Deprecation") != "true" || !strings.Contains(recorder.Header().Get("Warning"), "/api/empty-mode") {
+		t.Errorf("headers = %v, want the deprecation warning", recorder.Header())
+	}
+	if settings := readSettings(t, cm); settings["model"] != "work" {
+		t.Errorf("settings model = %v, want work restored", settings["model"])
+	}
+}
+
+func TestProfilePermissionsEndpoint(t *testing.T) {
+	api, cm := newTestAPI(t)
+	setupCurrentProfile(t, cm)
+	const path = "/api/profiles/work/permissions"
+
+	permissionList := func(response APIResponse, list string) []interface{} {
+		t.Helper()
+		values, _ := dataObject(t, response, "permissions")[list].([]interface{})
+		return values
+	}
+
+	recorder, response := serve(t, api.HandleProfile, http.MethodPost, path, map[string]interface{}{"list": "allow", "rule": "Bash(git *)"})
+	if recorder.Code != http.StatusOK || !response.Success {
+		t.Fatalf("POST: status %d, response %+v", recorder.Code, response)
+	}
+	if added := response.Data.(map[string]interface{})["added"]; added != true {
+		t.Errorf("added = %v, want true", added)
+	}
+
+	// Adding the same rule again succeeds without duplicating it
+	_, response = serve(t, api.HandleProfile, http.MethodPost, path, map[string]interface{}{"list": "allow", "rule": "Bash(git *)"})
+	if added := response.Data.(map[string]interface{})["added"]; added != false {
+		t.Errorf("duplicate added = %v, want false", added)
+	}
+	if allow := permissionList(response, "allow"); len(allow) != 1 {
+		t.Errorf("allow = %v, want one rule", allow)
+	}
+
+	// The current profile is synced to settings.json
+	allow, _ := readSettings(t, cm)["permissions"].(map[string]interface{})["allow"].([]interface{})
+	if len(allow) != 1 || allow[0] != "Bash(git *)" {
+		t.Errorf("settings allow = %v, want the new rule", allow)
+	}
+
+	_, response = serve(t, api.HandleProfile, http.MethodGet, path, nil)
+	if allow := permissionList(response, "allow"); len(allow) != 1 || allow[0] != "Bash(git *)" {
+		t.Errorf("GET allow = %v, want the new rule", allow)
+	}
+
+	recorder, response = serve(t, api.HandleProfile, http.MethodDelete, path, map[string]interface{}{"rule": "Bash(git *)"})
+	if recorder.Code != http.StatusOK || !response.Success {
+		t.Fatalf("DELETE: status %d, response %+v", recorder.Code, response)
+	}
+	if removed := response.Data.(map[string]interface{})["removed_from"]; fmt.Sprint(removed) != "[allow]" {
+		t.Errorf("removed_from = %v, want [allow]", removed)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		status int
+	}{
+		{"remove missing rule", http.MethodDelete, path, map[string]interface{}{"rule": "Bash(git *)"}, http.StatusNotFound},
+		{"invalid rule", http.MethodPost, path, map[string]interface{}{"list": "allow", "rule": "Bash(git *"}, http.StatusBadRequest},
+		{"unknown list", http.MethodPost, path, map[string]interface{}{"list": "ask", "rule": "Read"}, http.StatusBadRequest},
+		{"template with rule", http.MethodPost, path, map[string]interface{}{"from_template": "default", "rule": "Read"}, http.StatusBadRequest},
+		{"missing template", http.MethodPost, path, map[string]interface{}{"from_template": "missing"}, http.StatusNotFound},
+		{"missing profile", http.MethodGet, "/api/profiles/missing/permissions", nil, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder, response := serve(t, api.HandleProfile, tt.method, tt.path, tt.body)
+			if recorder.Code != tt.status || response.Success {
+				t.Errorf("status %d, response %+v, want %d", recorder.Code, response, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package web
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+)
+
+// Supported sort orders for the profile and template listings
+const (
+	sortByName     = "name"
+	sortByLastUsed = "last_used"
+	sortByCreated  = "created"
+)
+
+// profileQuery holds the optional filtering and paging parameters of GET /api/profiles
+type profileQuery struct {
+	Search string
+	Sort   string
+	Offset int
+	Limit  int // 0 means no limit
+}
+
+// hasProfileQuery reports whether any listing parameter was supplied.
+// Without parameters the endpoint keeps returning the full, unpaged list.
+func hasProfileQuery(values url.Values) bool {
+	for _, key := range []string{"q", "sort", "offset", "limit"} {
+		if _, ok := values[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// parseProfileQuery validates the listing parameters
+func parseProfileQuery(values url.Values) (profileQuery, error) {
+	query := profileQuery{
+		Search: strings.TrimSpace(values.Get("q")),
+		Sort:   sortByName,
+	}
+
+	if s := values.Get("sort"); s != "" {
+		if s != sortByName && s != sortByLastUsed {
+			return query, fmt.Errorf("invalid sort '%s' (expected '%s' or '%s')", s, sortByName, sortByLastUsed)
+		}
+		query.Sort = s
+	}
+
+	var err error
+	if query.Offset, err = parseNonNegative(values, "offset"); err != nil {
+		return query, err
+	}
+	if query.Limit, err = parseNonNegative(values, "limit"); err != nil {
+		return query, err
+	}
+
+	return query, nil
+}
+
+// parseNonNegative reads an optional non-negative integer parameter
+func parseNonNegative(values url.Values, key string) (int, error) {
+	raw := values.Get(key)
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s '%s' (expected a non-negative integer)", key, raw)
+	}
+	return n, nil
+}
+
+// filterProfiles keeps profiles whose name contains the search text (case-insensitive)
+func filterProfiles(profiles []config.Profile, search string) []config.Profile {
+	if search == "" {
+		return profiles
+	}
+
+	needle := strings.ToLower(search)
+	filtered := make([]config.Profile, 0, len(profiles))
+	for _, profile := range profiles {
+		if strings.Contains(strings.ToLower(profile.Name), needle) || strings.Contains(strings.ToLower(profile.DisplayName), needle) {
+			filtered = append(filtered, profile)
+		}
+	}
+	return filtered
+}
+
+// lastUsedTimes maps each profile to its most recent switch time (history is newest first)
+func lastUsedTimes(entries []config.HistoryEntry) map[string]time.Time {
+	lastUsed := make(map[string]time.Time, len(entries))
+	for _, entry := range entries {
+		if _, seen := lastUsed[entry.Profile]; !seen {
+			lastUsed[entry.Profile] = entry.SwitchedAt
+		}
+	}
+	return lastUsed
+}
+
+// sortProfiles orders profiles by name, or by most recent use with never-used profiles last
+func sortProfiles(profiles []config.Profile, lastUsed map[string]time.Time) {
+	sort.SliceStable(profiles, func(i, j int) bool {
+		if lastUsed != nil {
+			ti, oki := lastUsed[profiles[i].Name]
+			tj, okj := lastUsed[profiles[j].Name]
+			if oki != okj {
+				return oki
+			}
+			if oki && !ti.Equal(tj) {
+				return ti.After(tj)
+			}
+		}
+		return profiles[i].Name < profiles[j].Name
+	})
+}
+
+// parseTemplateSort validates the sort parameter of GET /api/templates
+func parseTemplateSort(values url.Values) (string, error) {
+	s := values.Get("sort")
+	switch s {
+	case "":
+		return sortByName, nil
+	case sortByName, sortByCreated:
+		return s, nil
+	default:
+		return "", fmt.Errorf("invalid sort '%s' (expected '%s' or '%s')", s, sortByName, sortByCreated)
+	}
+}
+
+// pageProfiles returns the requested window of profiles
+func pageProfiles(profiles []config.Profile, offset, limit int) []config.Profile {
+	if offset >= len(profiles) {
+		return []config.Profile{}
+	}
+	end := len(profiles)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return profiles[offset:end]
+}
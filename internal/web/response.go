@@ -0,0 +1,153 @@
+package web
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+)
+
+// errorStatus maps the category of a config error to an HTTP status
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, config.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, config.ErrInvalid):
+		return http.StatusBadRequest
+	case errors.Is(err, config.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, config.ErrLocked):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// isSensitiveKey reports whether a configuration key holds a secret, including
+// the token keys configured in auth.token_keys
+func isSensitiveKey(key string) bool {
+	return config.IsSecretKey(key)
+}
+
+// maskSecret hides all but the last four characters of a secret value
+func maskSecret(value string) string {
+	if len(value) <= 8 {
+		return strings.Repeat("*", len(value))
+	}
+	return strings.Repeat("*", 8) + value[len(value)-4:]
+}
+
+// maskContent returns a copy of the configuration with secret values masked
+func maskContent(content map[string]interface{}) map[string]interface{} {
+	masked := make(map[string]interface{}, len(content))
+	for key, value := range content {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			masked[key] = maskContent(v)
+		case string:
+			if isSensitiveKey(key) {
+				masked[key] = maskSecret(v)
+			} else {
+				masked[key] = v
+			}
+		default:
+			masked[key] = v
+		}
+	}
+	return masked
+}
+
+// maskDiffValue masks a diff value whose field path ends in a secret key;
+// objects are masked recursively like maskContent
+func maskDiffValue(path string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return maskContent(v)
+	case string:
+		key := path
+		if i := strings.LastIndex(path, "."); i >= 0 {
+			key = path[i+1:]
+		}
+		if isSensitiveKey(key) {
+			return maskSecret(v)
+		}
+	}
+	return value
+}
+
+// contentETag computes a stable hash of the stored (unmasked) configuration
+func contentETag(content map[string]interface{}) string {
+	data, err := json.Marshal(content) // map keys are marshalled in sorted order
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// profileObject reads a profile back after a write and builds its response object
+func (api *APIHandler) profileObject(name string) (map[string]interface{}, error) {
+	view, err := api.handler.ViewConfig(name, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"name":         view.Name,
+		"display_name": view.DisplayName,
+		"path":         view.Path,
+		"is_current":   view.IsCurrent,
+		"content":      maskContent(view.Content),
+		"etag":         contentETag(view.Content),
+	}, nil
+}
+
+// templateObject reads a template back after a write and builds its response object
+func (api *APIHandler) templateObject(name string) (map[string]interface{}, error) {
+	view, err := api.handler.ViewTemplate(name, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"name":    view.Name,
+		"path":    view.Path,
+		"content": maskContent(view.Content),
+		"etag":    contentETag(view.Content),
+	}, nil
+}
+
+// sendProfileWriteSuccess responds to a profile create/update with the stored profile
+// If the read-back fails the write still succeeded, so only the message is returned
+func (api *APIHandler) sendProfileWriteSuccess(w http.ResponseWriter, name, message string) {
+	data := map[string]interface{}{
+		"message": message,
+		"name":    name,
+	}
+
+	if profile, err := api.profileObject(name); err == nil {
+		data["profile"] = profile
+		w.Header().Set("ETag", profile["etag"].(string))
+	}
+
+	api.sendSuccess(w, data)
+}
+
+// sendTemplateWriteSuccess responds to a template create/update with the stored template
+func (api *APIHandler) sendTemplateWriteSuccess(w http.ResponseWriter, name, message string) {
+	data := map[string]interface{}{
+		"message": message,
+		"name":    name,
+	}
+
+	if template, err := api.templateObject(name); err == nil {
+		data["template"] = template
+		w.Header().Set("ETag", template["etag"].(string))
+	}
+
+	api.sendSuccess(w, data)
+}
@@ -4,12 +4,13 @@ import (
 	"context"
 	"embed"
 	"fmt"
+	"net"
 	"net/http"
 	"slices"
 	"time"
 
-	"cc-switch/internal/common"
-	"cc-switch/internal/handler"
+	"github.com/HoBeedzc/cc-switch/internal/common"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
 )
 
 //go:embed all:assets/*
@@ -21,19 +22,44 @@ type Server struct {
 	server  *http.Server
 	host    string
 	port    int
+
+	updateInterval time.Duration
+	// updateCtx is set by EnablePeriodicUpdateCheck before the server starts, so
+	// Shutdown can cancel it without racing with Start
+	updateCtx       context.Context
+	stopUpdateCheck context.CancelFunc
+
+	// baseCtx is the parent of every request context; cancelRequests cancels it on shutdown
+	// to stop in-flight requests (e.g. running API tests). Both are set in NewServer.
+	baseCtx        context.Context
+	cancelRequests context.CancelFunc
 }
 
 // NewServer creates a new web server instance
 func NewServer(configHandler handler.ConfigHandler, host string, port int) *Server {
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
 	return &Server{
-		handler: configHandler,
-		host:    host,
-		port:    port,
+		handler:        configHandler,
+		host:           host,
+		port:           port,
+		baseCtx:        baseCtx,
+		cancelRequests: cancelRequests,
 	}
 }
 
+// EnablePeriodicUpdateCheck makes the server refresh the update check cache
+// every interval while it is running (0 uses the configured check interval)
+func (s *Server) EnablePeriodicUpdateCheck(interval time.Duration) {
+	s.updateInterval = interval
+	s.updateCtx, s.stopUpdateCheck = context.WithCancel(context.Background())
+}
+
 // Start starts the web server
 func (s *Server) Start() error {
+	if s.updateCtx != nil {
+		common.StartPeriodicCheck(s.updateCtx, s.updateInterval)
+	}
+
 	mux := http.NewServeMux()
 
 	// API routes
@@ -42,14 +68,25 @@ func (s *Server) Start() error {
 	mux.HandleFunc("/api/profiles/", api.HandleProfile)
 	mux.HandleFunc("/api/current", api.HandleCurrent)
 	mux.HandleFunc("/api/switch", api.HandleSwitch)
+	mux.HandleFunc("/api/empty-mode", api.HandleEmptyMode)
 	mux.HandleFunc("/api/test", api.HandleTest)
+	mux.HandleFunc("/api/test/all", api.HandleTestAll)
 	mux.HandleFunc("/api/templates", api.HandleTemplates)
 	mux.HandleFunc("/api/templates/", api.HandleTemplateRoutes)
 	mux.HandleFunc("/api/health", api.HandleHealth)
+	mux.HandleFunc("/api/validate", api.HandleValidate)
+	mux.HandleFunc("/api/diff", api.HandleDiff)
 	mux.HandleFunc("/api/export", api.HandleExport)
 	mux.HandleFunc("/api/import", api.HandleImport)
 	mux.HandleFunc("/api/version", api.HandleVersion)
 
+	// Bookmarkable switch links with a confirmation page
+	switchHandler, err := newSwitchPage(s.handler)
+	if err != nil {
+		return err
+	}
+	mux.Handle("/switch/", switchHandler)
+
 	// Static file server
 	staticHandler := http.FileServer(http.FS(assets))
 	mux.Handle("/assets/", staticHandler)
@@ -63,6 +100,7 @@ func (s *Server) Start() error {
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
+		BaseContext:  func(net.Listener) context.Context { return s.baseCtx },
 	}
 
 	return s.server.ListenAndServe()
@@ -70,6 +108,11 @@ func (s *Server) Start() error {
 
 // Shutdown gracefully shuts down the server
 func (s *Server) Shutdown(ctx context.Context) error {
+	if s.stopUpdateCheck != nil {
+		s.stopUpdateCheck()
+	}
+	// Abort long-running requests first so Shutdown does not wait for them to finish
+	s.cancelRequests()
 	return s.server.Shutdown(ctx)
 }
 
@@ -252,7 +295,7 @@ func corsMiddleware(port int, next http.Handler) http.Handler {
 			return
 		}
 
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 		if r.Method == "OPTIONS" {
@@ -290,3 +333,15 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Flush lets streaming responses (NDJSON, server-sent events) reach the client as they are written
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,124 @@
+package web
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
+)
+
+//go:embed templates/switch.html
+var templateFiles embed.FS
+
+var switchPageTemplate = template.Must(template.ParseFS(templateFiles, "templates/switch.html"))
+
+// switchPage serves /switch/{profile}: GET shows a confirmation page, POST from
+// that page's form performs the switch. GET never changes any state.
+type switchPage struct {
+	handler   handler.ConfigHandler
+	csrfToken string
+}
+
+// switchPageData is the data rendered by templates/switch.html
+type switchPageData struct {
+	Profile   string
+	Current   string
+	IsCurrent bool
+	Exists    bool
+	Changes   []config.DiffEntry
+	Action    string
+	CSRFToken string
+	Error     string
+}
+
+// newSwitchPage creates the deep-link handler with a fresh CSRF token for this server run
+func newSwitchPage(configHandler handler.ConfigHandler) (*switchPage, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("failed to generate CSRF token: %w", err)
+	}
+	return &switchPage{handler: configHandler, csrfToken: hex.EncodeToString(token)}, nil
+}
+
+// ServeHTTP handles /switch/{profile} requests
+func (p *switchPage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/switch/")
+	if name == "" || strings.Contains(name, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		p.render(w, name, "", http.StatusOK)
+	case http.MethodPost:
+		p.confirm(w, r, name)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// confirm performs the switch after checking the form's CSRF token, then redirects to the main page
+func (p *switchPage) confirm(w http.ResponseWriter, r *http.Request, name string) {
+	r.Body = http.MaxBytesReader(w, r.Body, 4096)
+	if err := r.ParseForm(); err != nil {
+		p.render(w, name, "Invalid form submission", http.StatusBadRequest)
+		return
+	}
+
+	token := r.PostForm.Get("csrf_token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(p.csrfToken)) != 1 {
+		p.render(w, name, "This link has expired. Review the change and confirm again.", http.StatusForbidden)
+		return
+	}
+
+	if err := p.handler.UseConfig(name); err != nil {
+		p.render(w, name, fmt.Sprintf("Failed to switch configuration: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/?switched="+url.QueryEscape(name), http.StatusSeeOther)
+}
+
+// render writes the confirmation page: target and current profile plus a summary of changed settings
+func (p *switchPage) render(w http.ResponseWriter, name, errMessage string, status int) {
+	data := switchPageData{
+		Profile:   name,
+		Action:    "/switch/" + url.PathEscape(name),
+		CSRFToken: p.csrfToken,
+		Error:     errMessage,
+	}
+
+	if err := p.handler.ValidateConfigExists(name); err != nil {
+		data.Error = err.Error()
+		if status == http.StatusOK {
+			status = http.StatusNotFound
+		}
+	} else {
+		data.Exists = true
+		data.Current, _ = p.handler.GetCurrentConfig()
+		data.IsCurrent = data.Current == name
+		if !data.IsCurrent {
+			changes, err := p.handler.PlanSwitch(name)
+			if err != nil && data.Error == "" {
+				data.Error = err.Error()
+			}
+			data.Changes = changes
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := switchPageTemplate.Execute(w, data); err != nil {
+		fmt.Fprintf(w, "failed to render page: %v", err)
+	}
+}
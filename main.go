@@ -3,11 +3,11 @@ package main
 import (
 	"os"
 
-	"cc-switch/cmd"
+	"github.com/HoBeedzc/cc-switch/cmd"
 )
 
 func main() {
 	if err := cmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
@@ -0,0 +1,382 @@
+// Package ccswitch exposes cc-switch's profile management as a Go API.
+//
+// It covers the operations behind the CLI commands (list, use, new, rm, test,
+// export and import) without any terminal output, prompts or colors, so other
+// tools can embed them. State is shared with the CLI: switching through a
+// Manager records history and honors empty mode exactly like "cc-switch use".
+// Warnings and the secrets passphrase go through Options instead of the terminal.
+//
+//	m, err := ccswitch.New()
+//	if err != nil {
+//		return err
+//	}
+//	result, err := m.Use(ctx, "work", ccswitch.UseOptions{Note: "release"})
+//	if errors.Is(err, ccswitch.ErrAlreadyActive) {
+//		// nothing to do
+//	}
+package ccswitch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/export"
+	"github.com/HoBeedzc/cc-switch/internal/handler"
+	importpkg "github.com/HoBeedzc/cc-switch/internal/import"
+)
+
+// Result and option types shared with the CLI
+type (
+	// Profile is a stored configuration as returned by List
+	Profile = config.Profile
+	// HistoryEntry is a single switch recorded in the history
+	HistoryEntry = config.HistoryEntry
+	// ValidationIssue is a problem found in a configuration
+	ValidationIssue = config.ValidationIssue
+	// TestOptions controls connectivity tests
+	TestOptions = handler.TestOptions
+	// TestResult is the outcome of testing one configuration
+	TestResult = handler.APITestResult
+	// ImportResult is the outcome of an import
+	ImportResult = importpkg.ImportResult
+)
+
+// Errors returned by Manager; test them with errors.Is. ErrNotFound, ErrExists and
+// ErrNoPrevious also match the error categories the CLI maps to exit codes.
+var (
+	ErrNotFound      = config.NotFoundf("configuration does not exist")
+	ErrExists        = config.Conflictf("configuration already exists")
+	ErrAlreadyActive = errors.New("configuration is already active")
+	ErrIsCurrent     = errors.New("configuration is currently active")
+	ErrNoPrevious    = config.ErrNoPrevious
+	// ErrLocked is returned when the secrets store is needed and no passphrase is available
+	ErrLocked = config.ErrLocked
+)
+
+// profileError reports one of the errors above for a named configuration
+type profileError struct {
+	msg string
+	err error
+}
+
+func (e *profileError) Error() string { return e.msg }
+
+func (e *profileError) Unwrap() error { return e.err }
+
+// errProfile wraps err with a message naming the configuration, e.g.
+// "configuration 'work' does not exist"
+func errProfile(err error, name string) error {
+	return &profileError{
+		msg: strings.Replace(err.Error(), "configuration", fmt.Sprintf("configuration '%s'", name), 1),
+		err: err,
+	}
+}
+
+// Options configures a Manager
+type Options struct {
+	// Warnings receives non-fatal warnings, such as a profile that could not be
+	// read while listing. They are discarded when nil.
+	Warnings io.Writer
+	// Passphrase supplies the secrets store passphrase when profiles reference
+	// secrets and CC_SWITCH_SECRETS_PASSPHRASE is not set. When nil, such
+	// operations fail with ErrLocked instead of prompting.
+	Passphrase func() (string, error)
+	// DryRun describes writes on Warnings instead of performing them
+	DryRun bool
+}
+
+// Manager performs cc-switch operations on the profiles of the current user
+type Manager struct {
+	configManager *config.ConfigManager
+	handler       handler.ConfigHandler
+}
+
+// New creates a Manager for ~/.claude with default Options, initializing the
+// profile directory the same way the CLI does on first run
+func New() (*Manager, error) {
+	return NewWithOptions(Options{})
+}
+
+// NewWithOptions creates a Manager for ~/.claude
+func NewWithOptions(options Options) (*Manager, error) {
+	if options.Warnings == nil {
+		options.Warnings = io.Discard
+	}
+	if options.Passphrase == nil {
+		options.Passphrase = func() (string, error) {
+			return "", config.Lockedf("secrets store is locked: set %s or Options.Passphrase", config.SecretsPassphraseEnv)
+		}
+	}
+
+	configOptions := config.DefaultOptions()
+	configOptions.DryRun = options.DryRun
+	configOptions.Warnings = options.Warnings
+	configOptions.Passphrase = options.Passphrase
+	configManager, err := config.NewConfigManagerWithOptions(configOptions)
+	if err != nil {
+		return nil, err
+	}
+	return FromConfigManager(configManager), nil
+}
+
+// FromConfigManager creates a Manager on a configuration manager the caller has
+// already set up. The cc-switch command uses it to share one manager between
+// this API and its interactive code paths.
+func FromConfigManager(configManager *config.ConfigManager) *Manager {
+	return &Manager{
+		configManager: configManager,
+		handler:       handler.NewConfigHandler(configManager),
+	}
+}
+
+// List returns all configurations, including read-only system profiles
+func (m *Manager) List(ctx context.Context) ([]Profile, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return m.handler.ListConfigs()
+}
+
+// ListFiltered returns the configurations whose name matches a glob pattern, or
+// with a "tag:" prefix those carrying a tag (e.g. "tag:work"). Configurations
+// that cannot be read are skipped.
+func (m *Manager) ListFiltered(ctx context.Context, filter string) ([]Profile, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return m.configManager.ListProfilesFiltered(filter)
+}
+
+// Current returns the name of the active configuration, or "" in empty mode
+func (m *Manager) Current(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if m.handler.IsEmptyMode() {
+		return "", nil
+	}
+	return m.handler.GetCurrentConfig()
+}
+
+// History returns the switch history, newest first
+func (m *Manager) History(ctx context.Context) ([]HistoryEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return m.handler.GetHistory()
+}
+
+// UseOptions controls a switch
+type UseOptions struct {
+	Note string // recorded with the history entry
+	// Verify validates the written settings.json and rolls the switch back if it has
+	// errors; the verify_switch setting turns this on for every switch
+	Verify bool
+}
+
+// SwitchResult describes a completed switch
+type SwitchResult struct {
+	Name                  string // configuration now active
+	Previous              string // configuration active before the switch, "" if none
+	RestoredFromEmptyMode bool   // empty mode was left before switching
+}
+
+// Use switches to the named configuration. In empty mode the backed-up
+// settings are restored first, as with "cc-switch use".
+func (m *Manager) Use(ctx context.Context, name string, options UseOptions) (*SwitchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !m.configManager.ProfileExists(name) {
+		return nil, errProfile(ErrNotFound, name)
+	}
+
+	result := &SwitchResult{Name: name}
+	if m.handler.IsEmptyMode() {
+		if err := m.handler.RestoreFromEmptyMode(); err != nil {
+			return nil, fmt.Errorf("failed to restore from empty mode: %w", err)
+		}
+		result.RestoredFromEmptyMode = true
+	}
+
+	result.Previous, _ = m.handler.GetCurrentConfig()
+	if m.handler.IsCurrentConfig(name) {
+		return result, errProfile(ErrAlreadyActive, name)
+	}
+
+	if err := m.handler.UseConfigWithOptions(name, config.SwitchOptions{Note: options.Note, Verify: options.Verify}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UsePrevious switches back to the previously active configuration. In empty
+// mode it restores the configuration that was active before, like "use -p".
+func (m *Manager) UsePrevious(ctx context.Context, options UseOptions) (*SwitchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if m.handler.IsEmptyMode() {
+		if err := m.handler.RestoreToPreviousFromEmptyMode(); err != nil {
+			return nil, err
+		}
+		current, _ := m.handler.GetCurrentConfig()
+		return &SwitchResult{Name: current, RestoredFromEmptyMode: true}, nil
+	}
+
+	previous, err := m.handler.GetPreviousConfig()
+	if err != nil {
+		return nil, err
+	}
+	if previous == "empty_mode" {
+		current, _ := m.handler.GetCurrentConfig()
+		if err := m.handler.UseEmptyMode(); err != nil {
+			return nil, err
+		}
+		return &SwitchResult{Previous: current}, nil
+	}
+	return m.Use(ctx, previous, options)
+}
+
+// EnterEmptyMode backs up the live settings and removes them
+func (m *Manager) EnterEmptyMode(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.handler.UseEmptyMode()
+}
+
+// RestoreFromEmptyMode restores the settings backed up by EnterEmptyMode
+func (m *Manager) RestoreFromEmptyMode(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.handler.RestoreFromEmptyMode()
+}
+
+// CreateOptions controls how a configuration is created
+type CreateOptions struct {
+	Template string                 // template to copy; the default_template setting when empty
+	Content  map[string]interface{} // explicit content; overrides Template when set
+}
+
+// Create creates a new configuration from a template or explicit content
+func (m *Manager) Create(ctx context.Context, name string, options CreateOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if m.configManager.ProfileExists(name) {
+		return errProfile(ErrExists, name)
+	}
+	if options.Content != nil {
+		return m.handler.CreateConfigWithContent(name, options.Content)
+	}
+	return m.handler.CreateConfig(name, options.Template)
+}
+
+// Delete removes a configuration. The active configuration cannot be deleted.
+func (m *Manager) Delete(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !m.configManager.ProfileExists(name) {
+		return errProfile(ErrNotFound, name)
+	}
+	if m.handler.IsCurrentConfig(name) {
+		return &profileError{
+			msg: fmt.Sprintf("cannot delete current configuration '%s'. Switch to another configuration first", name),
+			err: ErrIsCurrent,
+		}
+	}
+	return m.handler.DeleteConfig(name, true)
+}
+
+// Validate checks a stored configuration and returns the issues found
+func (m *Manager) Validate(ctx context.Context, name string) ([]ValidationIssue, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !m.configManager.ProfileExists(name) {
+		return nil, errProfile(ErrNotFound, name)
+	}
+	return m.handler.ValidateConfig(name)
+}
+
+// Test checks API connectivity of the named configuration. Cancelling ctx
+// stops running requests and the Claude CLI chat test.
+func (m *Manager) Test(ctx context.Context, name string, options TestOptions) (*TestResult, error) {
+	if !m.configManager.ProfileExists(name) {
+		return nil, errProfile(ErrNotFound, name)
+	}
+	return m.handler.TestAPIConnectivity(ctx, name, options)
+}
+
+// TestCurrent checks API connectivity of the active configuration
+func (m *Manager) TestCurrent(ctx context.Context, options TestOptions) (*TestResult, error) {
+	return m.handler.TestCurrentConfiguration(ctx, options)
+}
+
+// TestAll checks API connectivity of every configuration
+func (m *Manager) TestAll(ctx context.Context, options TestOptions) ([]TestResult, error) {
+	return m.handler.TestAllConfigurations(ctx, options)
+}
+
+// ExportOptions selects what to export and where
+type ExportOptions struct {
+	Names    []string // configurations to export; all when empty
+	Password string   // encrypts the file when set
+	Output   string   // destination .ccx path
+}
+
+// Export writes configurations to a .ccx file
+func (m *Manager) Export(ctx context.Context, options ExportOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if options.Output == "" {
+		return fmt.Errorf("output path is required")
+	}
+	exporter := export.NewExporter(m.configManager)
+	if len(options.Names) == 0 {
+		return exporter.ExportAll(options.Password, options.Output)
+	}
+	for _, name := range options.Names {
+		if !m.configManager.ProfileExists(name) {
+			return errProfile(ErrNotFound, name)
+		}
+	}
+	return exporter.ExportProfiles(options.Names, options.Password, options.Output)
+}
+
+// ImportOptions controls an import
+type ImportOptions struct {
+	Password     string // password of an encrypted file
+	ConflictMode string // "skip" (default), "overwrite" or "both"
+	DryRun       bool   // report what would happen without writing
+	// RestoreCurrent switches to the profile that was current when the file was
+	// exported (following conflict renames); see ImportResult.CurrentRestored
+	RestoreCurrent bool
+}
+
+// Import reads configurations from a .ccx file
+func (m *Manager) Import(ctx context.Context, path string, options ImportOptions) (*ImportResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	mode := options.ConflictMode
+	if mode == "" {
+		mode = "skip"
+	}
+	if mode != "skip" && mode != "overwrite" && mode != "both" {
+		return nil, fmt.Errorf("invalid conflict mode '%s', valid values: skip, overwrite, both", mode)
+	}
+	return importpkg.NewImporter(m.configManager).Import(path, options.Password, importpkg.ImportOptions{
+		ConflictMode:   mode,
+		DryRun:         options.DryRun,
+		RestoreCurrent: options.RestoreCurrent,
+	})
+}
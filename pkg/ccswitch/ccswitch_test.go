@@ -0,0 +1,447 @@
+package ccswitch_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/HoBeedzc/cc-switch/internal/config"
+	"github.com/HoBeedzc/cc-switch/internal/config/configtest"
+	"github.com/HoBeedzc/cc-switch/internal/testutil"
+	"github.com/HoBeedzc/cc-switch/pkg/ccswitch"
+)
+
+// setupHome points HOME at a temporary directory with an empty ~/.claude
+func setupHome(t *testing.T) string {
+	t.Helper()
+	home := testutil.SetupHome(t)
+	configtest.RestoreGlobalConfig(t)
+	if err := os.MkdirAll(filepath.Join(home, ".claude"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return home
+}
+
+// newManager creates a Manager in a temporary home with the given profiles
+func newManager(t *testing.T, options ccswitch.Options, names ...string) *ccswitch.Manager {
+	t.Helper()
+	setupHome(t)
+	m, err := ccswitch.NewWithOptions(options)
+	if err != nil {
+		t.Fatalf("NewWithOptions: %v", err)
+	}
+	for _, name := range names {
+		if err := m.Create(context.Background(), name, ccswitch.CreateOptions{Content: profileContent(name)}); err != nil {
+			t.Fatalf("Create %s: %v", name, err)
+		}
+	}
+	return m
+}
+
+func profileContent(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"env": map[string]interface{}{
+			"ANTHROPIC_AUTH_TOKEN": "token-" + name,
+			"ANTHROPIC_BASE_URL":   "https://" + name + ".example.com",
+		},
+	}
+}
+
+// captureStderr runs fn and returns what it wrote to os.Stderr
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestUseRecordsHistory(t *testing.T) {
+	ctx := context.Background()
+	m := newManager(t, ccswitch.Options{}, "work", "personal")
+
+	profiles, err := m.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(profiles) != 2 {
+		t.Fatalf("List returned %d profiles, want 2", len(profiles))
+	}
+
+	result, err := m.Use(ctx, "work", ccswitch.UseOptions{})
+	if err != nil {
+		t.Fatalf("Use work: %v", err)
+	}
+	if result.Name != "work" || result.Previous != "" {
+		t.Fatalf("Use work = %+v", result)
+	}
+
+	result, err = m.Use(ctx, "personal", ccswitch.UseOptions{Note: "weekend"})
+	if err != nil {
+		t.Fatalf("Use personal: %v", err)
+	}
+	if result.Previous != "work" {
+		t.Fatalf("Use personal: previous = %q, want work", result.Previous)
+	}
+
+	current, err := m.Current(ctx)
+	if err != nil || current != "personal" {
+		t.Fatalf("Current = %q, %v; want personal", current, err)
+	}
+
+	history, err := m.History(ctx)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(history) == 0 || history[0].Profile != "personal" || history[0].Note != "weekend" {
+		t.Fatalf("History = %+v, want personal with its note first", history)
+	}
+
+	result, err = m.UsePrevious(ctx, ccswitch.UseOptions{})
+	if err != nil {
+		t.Fatalf("UsePrevious: %v", err)
+	}
+	if result.Name != "work" || result.Previous != "personal" {
+		t.Fatalf("UsePrevious = %+v, want work after personal", result)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	ctx := context.Background()
+	m := newManager(t, ccswitch.Options{}, "work", "personal")
+
+	if _, err := m.UsePrevious(ctx, ccswitch.UseOptions{}); !errors.Is(err, ccswitch.ErrNoPrevious) {
+		t.Errorf("UsePrevious without history: %v, want ErrNoPrevious", err)
+	}
+	if _, err := m.Use(ctx, "work", ccswitch.UseOptions{}); err != nil {
+		t.Fatalf("Use work: %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	tests := []struct {
+		name    string
+		run     func() error
+		want    error
+		kind    error // the config error category the CLI maps to an exit code
+		message string
+	}{
+		{
+			name:    "use missing",
+			run:     func() error { _, err := m.Use(ctx, "missing", ccswitch.UseOptions{}); return err },
+			want:    ccswitch.ErrNotFound,
+			kind:    config.ErrNotFound,
+			message: "configuration 'missing' does not exist",
+		},
+		{
+			name:    "use active",
+			run:     func() error { _, err := m.Use(ctx, "work", ccswitch.UseOptions{}); return err },
+			want:    ccswitch.ErrAlreadyActive,
+			message: "configuration 'work' is already active",
+		},
+		{
+			name:    "create existing",
+			run:     func() error { return m.Create(ctx, "personal", ccswitch.CreateOptions{}) },
+			want:    ccswitch.ErrExists,
+			kind:    config.ErrConflict,
+			message: "configuration 'personal' already exists",
+		},
+		{
+			name:    "delete current",
+			run:     func() error { return m.Delete(ctx, "work") },
+			want:    ccswitch.ErrIsCurrent,
+			message: "cannot delete current configuration 'work'. Switch to another configuration first",
+		},
+		{
+			name: "delete missing",
+			run:  func() error { return m.Delete(ctx, "missing") },
+			want: ccswitch.ErrNotFound,
+			kind: config.ErrNotFound,
+		},
+		{
+			name: "cancelled context",
+			run:  func() error { _, err := m.List(cancelled); return err },
+			want: context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error = %v, want %v", err, tt.want)
+			}
+			if tt.kind != nil && !errors.Is(err, tt.kind) {
+				t.Errorf("error %v does not match category %v", err, tt.kind)
+			}
+			if tt.message != "" && err.Error() != tt.message {
+				t.Errorf("message = %q, want %q", err.Error(), tt.message)
+			}
+		})
+	}
+}
+
+func TestEmptyMode(t *testing.T) {
+	ctx := context.Background()
+	m := newManager(t, ccswitch.Options{}, "work", "personal")
+
+	if _, err := m.Use(ctx, "work", ccswitch.UseOptions{}); err != nil {
+		t.Fatalf("Use work: %v", err)
+	}
+	if err := m.EnterEmptyMode(ctx); err != nil {
+		t.Fatalf("EnterEmptyMode: %v", err)
+	}
+	if current, _ := m.Current(ctx); current != "" {
+		t.Fatalf("Current in empty mode = %q, want none", current)
+	}
+
+	// Switching leaves empty mode first, like 'cc-switch use'
+	result, err := m.Use(ctx, "personal", ccswitch.UseOptions{})
+	if err != nil {
+		t.Fatalf("Use personal: %v", err)
+	}
+	if !result.RestoredFromEmptyMode || result.Name != "personal" {
+		t.Fatalf("Use personal = %+v, want a switch that left empty mode", result)
+	}
+
+	// UsePrevious in empty mode restores what was active before
+	if err := m.EnterEmptyMode(ctx); err != nil {
+		t.Fatalf("EnterEmptyMode: %v", err)
+	}
+	result, err = m.UsePrevious(ctx, ccswitch.UseOptions{})
+	if err != nil {
+		t.Fatalf("UsePrevious: %v", err)
+	}
+	if !result.RestoredFromEmptyMode || result.Name != "personal" {
+		t.Fatalf("UsePrevious = %+v, want personal restored from empty mode", result)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+	m := newManager(t, ccswitch.Options{}, "work", "personal")
+
+	if err := m.Delete(ctx, "personal"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	profiles, err := m.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 1 || profiles[0].Name != "work" {
+		t.Fatalf("List after Delete = %+v, want only work", profiles)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	m := newManager(t, ccswitch.Options{}, "work", "personal")
+
+	output := filepath.Join(t.TempDir(), "profiles.ccx")
+	if err := m.Export(ctx, ccswitch.ExportOptions{Names: []string{"work"}, Password: "secret", Output: output}); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if err := m.Delete(ctx, "work"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if _, err := m.Import(ctx, output, ccswitch.ImportOptions{Password: "secret", ConflictMode: "replace"}); err == nil {
+		t.Fatal("Import accepted an unknown conflict mode")
+	}
+	result, err := m.Import(ctx, output, ccswitch.ImportOptions{Password: "secret"})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(result.ProfilesImported) != 1 || result.ProfilesImported[0] != "work" {
+		t.Fatalf("Import = %+v, want work imported", result)
+	}
+}
+
+func TestNoTerminalOutput(t *testing.T) {
+	ctx := context.Background()
+	var warnings bytes.Buffer
+
+	stderr := captureStderr(t, func() {
+		m := newManager(t, ccswitch.Options{Warnings: &warnings, DryRun: true})
+		if err := m.Create(ctx, "work", ccswitch.CreateOptions{Content: profileContent("work")}); err != nil {
+			t.Errorf("Create: %v", err)
+		}
+	})
+
+	if stderr != "" {
+		t.Errorf("Manager wrote to stderr: %q", stderr)
+	}
+	if !strings.Contains(warnings.String(), "[dry-run]") {
+		t.Errorf("dry-run report was not sent to Options.Warnings: %q", warnings.String())
+	}
+}
+
+func TestSecretsPassphrase(t *testing.T) {
+	ctx := context.Background()
+	setupHome(t)
+
+	// Store a secret the way 'cc-switch secret set' does
+	options := config.DefaultOptions()
+	options.Passphrase = func() (string, error) { return "correct horse", nil }
+	cm, err := config.NewConfigManagerWithOptions(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.SetSecret("work-token", "sk-live"); err != nil {
+		t.Fatalf("SetSecret: %v", err)
+	}
+	content := map[string]interface{}{"env": map[string]interface{}{"ANTHROPIC_AUTH_TOKEN": config.SecretRefPrefix + "work-token"}}
+
+	// Without a passphrase source the switch fails instead of prompting
+	locked, err := ccswitch.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := locked.Create(ctx, "work", ccswitch.CreateOptions{Content: content}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	stderr := captureStderr(t, func() {
+		if _, err := locked.Use(ctx, "work", ccswitch.UseOptions{}); !errors.Is(err, ccswitch.ErrLocked) {
+			t.Errorf("Use without a passphrase: %v, want ErrLocked", err)
+		}
+	})
+	if stderr != "" {
+		t.Errorf("Use wrote to stderr: %q", stderr)
+	}
+
+	asked := 0
+	m, err := ccswitch.NewWithOptions(ccswitch.Options{Passphrase: func() (string, error) {
+		asked++
+		return "correct horse", nil
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Use(ctx, "work", ccswitch.UseOptions{}); err != nil {
+		t.Fatalf("Use with a passphrase: %v", err)
+	}
+	if asked != 1 {
+		t.Errorf("passphrase source called %d times, want 1", asked)
+	}
+
+	settings, err := os.ReadFile(filepath.Join(os.Getenv("HOME"), ".claude", "settings.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(settings), "sk-live") {
+		t.Errorf("settings.json does not contain the resolved secret: %s", settings)
+	}
+}
+
+// cancellingStub serves the connectivity test endpoints and cancels the test run when the
+// cancelAt-th request arrives, holding that request until the client gives up
+func cancellingStub(t *testing.T, cancelAt int32, cancel context.CancelFunc) *httptest.Server {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == cancelAt {
+			cancel()
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": []}`))
+	}))
+	t.Cleanup(server.Close)
+	for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy"} {
+		t.Setenv(name, "")
+	}
+	return server
+}
+
+func TestTestAllCancelledMidRun(t *testing.T) {
+	m := newManager(t, ccswitch.Options{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// Each configuration makes two requests; cancel during the second configuration
+	stub := cancellingStub(t, 3, cancel)
+
+	for _, name := range []string{"a-first", "b-second", "c-third"} {
+		content := map[string]interface{}{"env": map[string]interface{}{
+			"ANTHROPIC_AUTH_TOKEN": "token-" + name,
+			"ANTHROPIC_BASE_URL":   stub.URL,
+		}}
+		if err := m.Create(context.Background(), name, ccswitch.CreateOptions{Content: content}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	start := time.Now()
+	results, err := m.TestAll(ctx, ccswitch.TestOptions{Endpoints: []string{"auth", "models"}, Timeout: 10 * time.Second})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("TestAll error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("TestAll took %v after cancellation, want it to stop promptly", elapsed)
+	}
+
+	byName := make(map[string]ccswitch.TestResult)
+	for _, result := range results {
+		byName[result.ProfileName] = result
+	}
+	first, ok := byName["a-first"]
+	if !ok || !first.IsConnectable || len(first.Tests) != 2 {
+		t.Errorf("a-first = %+v, want its complete result from before the cancellation", first)
+	}
+	if second, ok := byName["b-second"]; ok && second.IsConnectable {
+		t.Errorf("b-second = %+v, want it reported as cancelled", second)
+	}
+	if _, ok := byName["c-third"]; ok {
+		t.Error("c-third was tested after the run was cancelled")
+	}
+}
+
+func TestTestCancelledBetweenEndpoints(t *testing.T) {
+	m := newManager(t, ccswitch.Options{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stub := cancellingStub(t, 2, cancel)
+	content := map[string]interface{}{"env": map[string]interface{}{
+		"ANTHROPIC_AUTH_TOKEN": "token-work",
+		"ANTHROPIC_BASE_URL":   stub.URL,
+	}}
+	if err := m.Create(context.Background(), "work", ccswitch.CreateOptions{Content: content}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := m.Test(ctx, "work", ccswitch.TestOptions{Endpoints: []string{"auth", "models", "basic"}, Timeout: 10 * time.Second})
+	if err != nil {
+		t.Fatalf("Test: %v", err)
+	}
+	if result.IsConnectable || result.Error != "test cancelled" {
+		t.Errorf("result = connectable %v, error %q, want a cancelled test", result.IsConnectable, result.Error)
+	}
+	if len(result.Tests) != 2 || result.Tests[0].Status != "success" {
+		t.Errorf("tests = %+v, want auth completed, models cancelled and basic never run", result.Tests)
+	}
+}
@@ -0,0 +1,94 @@
+package ccswitch_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/HoBeedzc/cc-switch/pkg/ccswitch"
+)
+
+func ExampleManager_Use() {
+	m, err := ccswitch.New()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	result, err := m.Use(context.Background(), "work", ccswitch.UseOptions{Note: "release"})
+	switch {
+	case errors.Is(err, ccswitch.ErrAlreadyActive):
+		fmt.Println("already on work")
+	case errors.Is(err, ccswitch.ErrNotFound):
+		fmt.Println("create it first with Manager.Create")
+	case err != nil:
+		fmt.Println(err)
+	default:
+		fmt.Printf("switched from %q to %q\n", result.Previous, result.Name)
+	}
+}
+
+func ExampleNewWithOptions() {
+	// Report warnings on stderr and read the secrets passphrase from a file
+	// instead of failing with ErrLocked
+	m, err := ccswitch.NewWithOptions(ccswitch.Options{
+		Warnings: os.Stderr,
+		Passphrase: func() (string, error) {
+			data, err := os.ReadFile("/run/secrets/cc-switch")
+			return string(data), err
+		},
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	profiles, err := m.List(context.Background())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, profile := range profiles {
+		fmt.Println(profile.Name)
+	}
+}
+
+func ExampleManager_TestAll() {
+	m, err := ccswitch.New()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// Cancelling the context stops the remaining requests
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	results, err := m.TestAll(ctx, ccswitch.TestOptions{Quick: true})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, result := range results {
+		fmt.Printf("%s reachable: %v\n", result.ProfileName, result.IsConnectable)
+	}
+}
+
+func ExampleManager_Export() {
+	m, err := ccswitch.New()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	err = m.Export(context.Background(), ccswitch.ExportOptions{
+		Names:    []string{"work"},
+		Password: "correct horse",
+		Output:   "work.ccx",
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+}